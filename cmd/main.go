@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"log"
 	"os"
 	"os/signal"
 	"syscall"
@@ -15,6 +16,57 @@ func main() {
 
 	flag.StringVar(&cfg.Host, "host", cfg.Host, "host")
 	flag.IntVar(&cfg.Port, "port", cfg.Port, "port")
+	flag.Func("databases", "number of databases (default 16)", func(value string) error {
+		count, err := config.ParseDatabases(value)
+		if err != nil {
+			return err
+		}
+		cfg.Databases = count
+		return nil
+	})
+	flag.StringVar(&cfg.Dir, "dir", cfg.Dir, "directory of the snapshot file")
+	flag.StringVar(&cfg.DBFilename, "dbfilename", cfg.DBFilename, "name of the snapshot file")
+	flag.Func("save", `snapshot rules as "<seconds> <changes> ..." (default "3600 1 300 100 60 10000"), "" disables them`, func(value string) error {
+		rules, err := config.ParseSaveRules(value)
+		if err != nil {
+			return err
+		}
+		cfg.SaveRules = rules
+		return nil
+	})
+	flag.BoolVar(&cfg.AppendOnly, "appendonly", cfg.AppendOnly, "log every write to the append only file")
+	flag.StringVar(&cfg.AppendFilename, "appendfilename", cfg.AppendFilename, "name of the append only file")
+	flag.Func("appendfsync", `fsync policy of the append only file: always, everysec or no (default "everysec")`, func(value string) error {
+		policy, err := config.ParseAppendFsync(value)
+		if err != nil {
+			return err
+		}
+		cfg.AppendFsync = policy
+		return nil
+	})
+	flag.BoolVar(&cfg.AOFLoadTruncated, "aof-load-truncated", cfg.AOFLoadTruncated, "repair an append only file that ends with an incomplete command")
+	flag.Func("list-max-listpack-size", "entries per list node when positive, -1 to -5 for 4KiB to 64KiB nodes (default -2)", func(value string) error {
+		fill, err := config.ParseListMaxListpackSize(value)
+		if err != nil {
+			return err
+		}
+		cfg.ListMaxListpackSize = fill
+		return nil
+	})
+	flag.Func("list-compress-depth", "list nodes left uncompressed at each end, 0 disables compression (default 0)", func(value string) error {
+		depth, err := config.ParseListCompressDepth(value)
+		if err != nil {
+			return err
+		}
+		cfg.ListCompressDepth = depth
+		return nil
+	})
+	flag.IntVar(&cfg.HashMaxListpackEntries, "hash-max-listpack-entries", cfg.HashMaxListpackEntries, "fields of a hash kept in the compact listpack encoding")
+	flag.IntVar(&cfg.HashMaxListpackValue, "hash-max-listpack-value", cfg.HashMaxListpackValue, "bytes of the longest field or value of a hash kept in the compact listpack encoding")
+	flag.IntVar(&cfg.SetMaxListpackEntries, "set-max-listpack-entries", cfg.SetMaxListpackEntries, "members of a set kept in the compact listpack encoding")
+	flag.IntVar(&cfg.SetMaxListpackValue, "set-max-listpack-value", cfg.SetMaxListpackValue, "bytes of the longest member of a set kept in the compact listpack encoding")
+	flag.IntVar(&cfg.ZSetMaxListpackEntries, "zset-max-listpack-entries", cfg.ZSetMaxListpackEntries, "members of a sorted set kept in the compact listpack encoding")
+	flag.IntVar(&cfg.ZSetMaxListpackValue, "zset-max-listpack-value", cfg.ZSetMaxListpackValue, "bytes of the longest member of a sorted set kept in the compact listpack encoding")
 	flag.Parse()
 
 	server, err := wiring.InitializeServer(cfg)
@@ -22,9 +74,15 @@ func main() {
 		panic(err)
 	}
 
+	if err := server.LoadData(); err != nil {
+		log.Fatal(err)
+	}
+
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
 
 	go server.WaitingForSignals(sigCh)
-	server.Start(sigCh)
+	if err := server.Start(sigCh); err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,323 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/manhhung2111/go-redis/internal/errors"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+	"github.com/manhhung2111/go-redis/internal/storage"
+	"github.com/manhhung2111/go-redis/internal/storage/types"
+)
+
+/* Support SETBIT key offset value */
+func (redis *redis) SetBit(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) != 3 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	offset, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return protocol.RespBitOffsetOutOfRange
+	}
+
+	if args[2] != "0" && args[2] != "1" {
+		return protocol.RespBitNotIntegerOrOutOfRange
+	}
+
+	old, err := redis.Store.SetBit(args[0], offset, int(args[2][0]-'0'))
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(old, false)
+}
+
+/* Support GETBIT key offset */
+func (redis *redis) GetBit(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) != 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	offset, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return protocol.RespBitOffsetOutOfRange
+	}
+
+	bit, err := redis.Store.GetBit(args[0], offset)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(bit, false)
+}
+
+/* Support BITCOUNT key [start end [BYTE | BIT]] */
+func (redis *redis) BitCount(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	if len(args) == 2 || len(args) > 4 {
+		return protocol.RespSyntaxError
+	}
+
+	rng := storage.BitRange{Start: 0, End: -1}
+	if len(args) > 1 {
+		var errReply []byte
+		if rng, errReply = parseBitRange(args[1], args[2], args[3:]); errReply != nil {
+			return errReply
+		}
+	}
+
+	count, err := redis.Store.BitCount(args[0], rng)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(count, false)
+}
+
+/* Support BITPOS key bit [start [end [BYTE | BIT]]] */
+func (redis *redis) BitPos(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	if len(args) > 5 {
+		return protocol.RespSyntaxError
+	}
+
+	bit, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return protocol.RespValueNotIntegerOrOutOfRange
+	}
+	if bit != 0 && bit != 1 {
+		return protocol.RespBitArgumentNotBinary
+	}
+
+	rng := storage.BitRange{Start: 0, End: -1}
+	endGiven := len(args) > 3
+	switch len(args) {
+	case 3:
+		if rng.Start, err = strconv.ParseInt(args[2], 10, 64); err != nil {
+			return protocol.RespValueNotIntegerOrOutOfRange
+		}
+	case 4, 5:
+		var errReply []byte
+		if rng, errReply = parseBitRange(args[2], args[3], args[4:]); errReply != nil {
+			return errReply
+		}
+	}
+
+	pos, err := redis.Store.BitPos(args[0], int(bit), rng, endGiven)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(pos, false)
+}
+
+/* Support BITOP <AND | OR | XOR | NOT> destkey key [key ...] */
+func (redis *redis) BitOp(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 3 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	var op types.BitOp
+	switch strings.ToUpper(args[0]) {
+	case "AND":
+		op = types.BitOpAnd
+	case "OR":
+		op = types.BitOpOr
+	case "XOR":
+		op = types.BitOpXor
+	case "NOT":
+		op = types.BitOpNot
+		if len(args) != 3 {
+			return protocol.RespBitOpNotSingleSource
+		}
+	default:
+		return protocol.RespSyntaxError
+	}
+
+	length, err := redis.Store.BitOp(op, args[1], args[2:]...)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(length, false)
+}
+
+/* Support BITFIELD key [GET encoding offset | [OVERFLOW <WRAP | SAT | FAIL>] <SET encoding offset value | INCRBY encoding offset increment> ...] */
+func (redis *redis) BitField(cmd protocol.RedisCmd) []byte {
+	return redis.bitField(cmd, false)
+}
+
+/* Support BITFIELD_RO key [GET encoding offset ...] */
+func (redis *redis) BitFieldRO(cmd protocol.RedisCmd) []byte {
+	return redis.bitField(cmd, true)
+}
+
+func (redis *redis) bitField(cmd protocol.RedisCmd, readOnly bool) []byte {
+	args := cmd.Args
+	if len(args) < 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	ops, errReply := parseBitFieldOps(args[1:], readOnly)
+	if errReply != nil {
+		return errReply
+	}
+
+	results, err := redis.Store.BitField(args[0], ops)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	reply := make([]any, len(results))
+	for i, result := range results {
+		if result != nil {
+			reply[i] = *result
+		}
+	}
+
+	return protocol.EncodeResp(reply, false)
+}
+
+// parseBitRange parses the start and end of BITCOUNT and BITPOS, followed by an optional
+// BYTE or BIT unit.
+func parseBitRange(startArg, endArg string, unit []string) (storage.BitRange, []byte) {
+	var rng storage.BitRange
+	var err1, err2 error
+	rng.Start, err1 = strconv.ParseInt(startArg, 10, 64)
+	rng.End, err2 = strconv.ParseInt(endArg, 10, 64)
+	if err1 != nil || err2 != nil {
+		return rng, protocol.RespValueNotIntegerOrOutOfRange
+	}
+
+	if len(unit) == 1 {
+		switch strings.ToUpper(unit[0]) {
+		case "BYTE":
+		case "BIT":
+			rng.Bits = true
+		default:
+			return rng, protocol.RespSyntaxError
+		}
+	}
+
+	return rng, nil
+}
+
+// parseBitFieldOps parses the subcommands of BITFIELD. An OVERFLOW mode applies to the
+// SET and INCRBY subcommands that follow it.
+func parseBitFieldOps(args []string, readOnly bool) ([]types.BitFieldOp, []byte) {
+	ops := make([]types.BitFieldOp, 0)
+	overflow := types.BitFieldWrap
+
+	for i := 0; i < len(args); i++ {
+		subcommand := strings.ToUpper(args[i])
+
+		if subcommand == "OVERFLOW" {
+			if readOnly {
+				return nil, protocol.RespBitFieldROOnlyGet
+			}
+			if i+1 >= len(args) {
+				return nil, protocol.RespSyntaxError
+			}
+
+			switch strings.ToUpper(args[i+1]) {
+			case "WRAP":
+				overflow = types.BitFieldWrap
+			case "SAT":
+				overflow = types.BitFieldSat
+			case "FAIL":
+				overflow = types.BitFieldFail
+			default:
+				return nil, protocol.RespInvalidOverflowType
+			}
+			i++
+			continue
+		}
+
+		op := types.BitFieldOp{Overflow: overflow}
+		argc := 2
+		switch subcommand {
+		case "GET":
+			op.Type = types.BitFieldGet
+		case "SET":
+			op.Type = types.BitFieldSet
+			argc = 3
+		case "INCRBY":
+			op.Type = types.BitFieldIncrBy
+			argc = 3
+		default:
+			return nil, protocol.RespSyntaxError
+		}
+
+		if readOnly && op.Type != types.BitFieldGet {
+			return nil, protocol.RespBitFieldROOnlyGet
+		}
+		if i+argc >= len(args) {
+			return nil, protocol.RespSyntaxError
+		}
+
+		var errReply []byte
+		if op.Signed, op.Bits, errReply = parseBitFieldType(args[i+1]); errReply != nil {
+			return nil, errReply
+		}
+		if op.Offset, errReply = parseBitFieldOffset(args[i+2], op.Bits); errReply != nil {
+			return nil, errReply
+		}
+		if argc == 3 {
+			value, err := strconv.ParseInt(args[i+3], 10, 64)
+			if err != nil {
+				return nil, protocol.RespValueNotIntegerOrOutOfRange
+			}
+			op.Value = value
+		}
+
+		ops = append(ops, op)
+		i += argc
+	}
+
+	return ops, nil
+}
+
+// parseBitFieldType parses an integer type like i16 or u8. Signed integers have up to 64
+// bits, unsigned ones up to 63.
+func parseBitFieldType(arg string) (bool, int, []byte) {
+	if len(arg) < 2 || (arg[0] != 'i' && arg[0] != 'I' && arg[0] != 'u' && arg[0] != 'U') {
+		return false, 0, protocol.RespInvalidBitFieldType
+	}
+
+	signed := arg[0] == 'i' || arg[0] == 'I'
+	bits, err := strconv.Atoi(arg[1:])
+	if err != nil || bits < 1 || (signed && bits > 64) || (!signed && bits > 63) {
+		return false, 0, protocol.RespInvalidBitFieldType
+	}
+
+	return signed, bits, nil
+}
+
+// parseBitFieldOffset parses a bit offset, or a multiple of the integer size when prefixed
+// with #.
+func parseBitFieldOffset(arg string, bits int) (uint64, []byte) {
+	multiplier := uint64(1)
+	if strings.HasPrefix(arg, "#") {
+		arg = arg[1:]
+		multiplier = uint64(bits)
+	}
+
+	offset, err := strconv.ParseUint(arg, 10, 64)
+	if err != nil || offset > (1<<63)/multiplier {
+		return 0, protocol.RespBitOffsetOutOfRange
+	}
+
+	return offset * multiplier, nil
+}
@@ -0,0 +1,160 @@
+package command
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/protocol"
+)
+
+// blockedCommand is a blocking command waiting for one of its keys to receive data.
+// The keys belong to the database selected by the client when it blocked.
+type blockedCommand struct {
+	cmd          protocol.RedisCmd
+	keys         []string
+	deadline     time.Time     // zero when the command waits forever
+	serve        func() []byte // retries the command, nil while none of the keys can serve it
+	timeoutReply []byte
+}
+
+// UnblockedClient is a client whose blocking command got its reply, either because
+// one of its keys received data or because its timeout expired.
+type UnblockedClient struct {
+	Client *Client
+	Reply  []byte
+}
+
+// IsBlocked reports whether the client waits for a blocking command to be served.
+// Its reply is handed out by UnblockedClients, and the client must not run other
+// commands until then.
+func (c *Client) IsBlocked() bool {
+	return c.blocked != nil
+}
+
+// parseBlockingTimeout parses the timeout in seconds of a blocking command, zero waits forever.
+func parseBlockingTimeout(arg string) (time.Duration, []byte) {
+	seconds, err := strconv.ParseFloat(arg, 64)
+	if err != nil || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return 0, protocol.RespTimeoutNotFloat
+	}
+
+	if seconds < 0 {
+		return 0, protocol.RespTimeoutNegative
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// block parks c until one of keys can serve the command. Nothing may block inside a
+// transaction or while loading, the command then answers as if its timeout expired.
+func (redis *redis) block(c *Client, cmd protocol.RedisCmd, keys []string, timeout time.Duration,
+	timeoutReply []byte, serve func() []byte) []byte {
+	if redis.inExec || redis.loading {
+		return timeoutReply
+	}
+
+	blocked := &blockedCommand{
+		cmd:          cmd,
+		serve:        serve,
+		timeoutReply: timeoutReply,
+	}
+	if timeout > 0 {
+		blocked.deadline = time.Now().Add(timeout)
+	}
+
+	db := redis.databases.DB(c.db)
+	for _, key := range keys {
+		if slices.Contains(blocked.keys, key) {
+			continue
+		}
+		blocked.keys = append(blocked.keys, key)
+		redis.blockedKeys[dbKey{c.db, key}] = append(redis.blockedKeys[dbKey{c.db, key}], c)
+		db.Block(key)
+	}
+
+	c.blocked = blocked
+	redis.blockedClients[c] = struct{}{}
+	return nil
+}
+
+// unblock releases the keys of a blocked client and, unless reply is nil because the
+// client went away, queues the reply for UnblockedClients.
+func (redis *redis) unblock(c *Client, reply []byte) {
+	db := redis.databases.DB(c.db)
+	for _, key := range c.blocked.keys {
+		k := dbKey{c.db, key}
+		waiters := slices.DeleteFunc(redis.blockedKeys[k], func(waiter *Client) bool { return waiter == c })
+		if len(waiters) == 0 {
+			delete(redis.blockedKeys, k)
+		} else {
+			redis.blockedKeys[k] = waiters
+		}
+		db.Unblock(key)
+	}
+
+	c.blocked = nil
+	delete(redis.blockedClients, c)
+	if reply != nil {
+		redis.unblocked = append(redis.unblocked, UnblockedClient{Client: c, Reply: reply})
+	}
+}
+
+// serveReadyKeys retries the clients blocked on the keys written by the last command,
+// in the order they blocked. Serving a client may write other keys, such as the
+// destination of BLMOVE, so this goes on until a pass serves nobody.
+func (redis *redis) serveReadyKeys() {
+	for served := true; served; {
+		served = false
+		for index := range redis.databases.Len() {
+			for _, key := range redis.databases.DB(index).ReadyKeys() {
+				served = redis.serveReadyKey(dbKey{index, key}) || served
+			}
+		}
+	}
+}
+
+// serveReadyKey retries the clients blocked on k, and reports whether it served any of them.
+func (redis *redis) serveReadyKey(k dbKey) bool {
+	served := false
+	for _, c := range slices.Clone(redis.blockedKeys[k]) {
+		// Served through another key earlier in this pass
+		if c.blocked == nil {
+			continue
+		}
+
+		// The command is retried and logged in the database the client blocked in
+		redis.selectDB(k.db)
+		reply := c.blocked.serve()
+		if reply == nil {
+			continue
+		}
+
+		spec := redis.handlers[c.blocked.cmd.Cmd]
+		if spec.flags&cmdWrite != 0 && !redis.loading && reply[0] != '-' {
+			redis.propagate(c.blocked.cmd, reply)
+		}
+		redis.unblock(c, reply)
+		served = true
+	}
+	return served
+}
+
+// TimeoutBlockedClients answers the blocked clients whose timeout expired. It is driven
+// by the server timer, so timeouts have the resolution of the timer period.
+func (redis *redis) TimeoutBlockedClients() {
+	now := time.Now()
+	for c := range redis.blockedClients {
+		if deadline := c.blocked.deadline; !deadline.IsZero() && !now.Before(deadline) {
+			redis.unblock(c, c.blocked.timeoutReply)
+		}
+	}
+}
+
+// UnblockedClients returns the clients unblocked since the last call with their replies.
+func (redis *redis) UnblockedClients() []UnblockedClient {
+	unblocked := redis.unblocked
+	redis.unblocked = nil
+	return unblocked
+}
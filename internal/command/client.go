@@ -0,0 +1,35 @@
+package command
+
+import "github.com/manhhung2111/go-redis/internal/protocol"
+
+// Client holds the state the command layer keeps for each connection.
+type Client struct {
+	db          int                 // index of the selected database
+	multi       bool                // between MULTI and EXEC or DISCARD
+	queue       []protocol.RedisCmd // commands queued since MULTI
+	queueFailed bool                // a command was rejected while queueing, EXEC must abort
+	watched     map[dbKey]uint64    // watched keys and their versions when WATCH was called
+	blocked     *blockedCommand     // blocking command waiting for data, nil when not blocked
+}
+
+// dbKey is a key of one of the databases.
+type dbKey struct {
+	db  int
+	key string
+}
+
+func NewClient() *Client {
+	return &Client{}
+}
+
+// InMulti reports whether the client is queueing commands for EXEC.
+func (c *Client) InMulti() bool {
+	return c.multi
+}
+
+// FlagTransactionError makes the open transaction, if any, abort on EXEC.
+func (c *Client) FlagTransactionError() {
+	if c.multi {
+		c.queueFailed = true
+	}
+}
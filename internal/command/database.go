@@ -0,0 +1,127 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/manhhung2111/go-redis/internal/errors"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+)
+
+/* Support SELECT index */
+func (redis *redis) Select(c *Client, cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	index, errReply := redis.parseDBIndex(cmd.Args[0])
+	if errReply != nil {
+		return errReply
+	}
+
+	c.db = index
+	redis.selectDB(index)
+	return protocol.RespOK
+}
+
+/* Support MOVE key db */
+func (redis *redis) Move(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	dstIndex, errReply := redis.parseDBIndex(cmd.Args[1])
+	if errReply != nil {
+		return errReply
+	}
+
+	if dstIndex == redis.db {
+		return protocol.RespSameObject
+	}
+
+	var result int64 = 0
+	if redis.databases.Move(redis.db, cmd.Args[0], dstIndex) {
+		result = 1
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
+/* Support SWAPDB index1 index2 */
+func (redis *redis) SwapDB(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	index1, err1 := strconv.Atoi(cmd.Args[0])
+	if err1 != nil {
+		return protocol.RespInvalidFirstDBIndex
+	}
+
+	index2, err2 := strconv.Atoi(cmd.Args[1])
+	if err2 != nil {
+		return protocol.RespInvalidSecondDBIndex
+	}
+
+	if !redis.validDBIndex(index1) || !redis.validDBIndex(index2) {
+		return protocol.RespDBIndexOutOfRange
+	}
+
+	if index1 != index2 {
+		redis.databases.SwapDB(index1, index2)
+	}
+
+	return protocol.RespOK
+}
+
+/* Support FLUSHDB [ASYNC | SYNC] */
+func (redis *redis) FlushDB(cmd protocol.RedisCmd) []byte {
+	if errReply := parseFlushArgs(cmd); errReply != nil {
+		return errReply
+	}
+
+	redis.Store.FlushDB()
+	return protocol.RespOK
+}
+
+/* Support FLUSHALL [ASYNC | SYNC] */
+func (redis *redis) FlushAll(cmd protocol.RedisCmd) []byte {
+	if errReply := parseFlushArgs(cmd); errReply != nil {
+		return errReply
+	}
+
+	redis.databases.FlushAll()
+	return protocol.RespOK
+}
+
+// parseFlushArgs checks the mode of FLUSHDB and FLUSHALL. Deleted values are freed by
+// the garbage collector either way, so both modes flush the same way.
+func parseFlushArgs(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) > 1 {
+		return protocol.RespSyntaxError
+	}
+
+	if len(cmd.Args) == 1 && !strings.EqualFold(cmd.Args[0], "ASYNC") && !strings.EqualFold(cmd.Args[0], "SYNC") {
+		return protocol.RespSyntaxError
+	}
+
+	return nil
+}
+
+// parseDBIndex parses the index of one of the databases.
+func (redis *redis) parseDBIndex(arg string) (int, []byte) {
+	index, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, protocol.RespValueNotIntegerOrOutOfRange
+	}
+
+	if !redis.validDBIndex(index) {
+		return 0, protocol.RespDBIndexOutOfRange
+	}
+
+	return index, nil
+}
+
+func (redis *redis) validDBIndex(index int) bool {
+	return index >= 0 && index < redis.databases.Len()
+}
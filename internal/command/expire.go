@@ -1,8 +1,10 @@
 package command
 
 import (
+	"math"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/manhhung2111/go-redis/internal/protocol"
 	"github.com/manhhung2111/go-redis/internal/storage"
@@ -15,38 +17,143 @@ func (redis *redis) TTL(cmd protocol.RedisCmd) []byte {
 		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
 	}
 
-	ttl := redis.Store.TTL(cmd.Args[0])
+	return ttlReply(redis.Store.TTL(cmd.Args[0]))
+}
 
-	if ttl == protocol.KeyNotExists {
-		return protocol.RespTTLKeyNotExist
+/* Supports `PTTL key` */
+func (redis *redis) PTTL(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
 	}
 
-	if ttl == protocol.NoExpire {
-		return protocol.RespTTLKeyExistNoExpire
+	return ttlReply(redis.Store.PTTL(cmd.Args[0]))
+}
+
+/* Supports `EXPIRETIME key` */
+func (redis *redis) ExpireTime(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	return ttlReply(redis.Store.ExpireTime(cmd.Args[0]))
+}
+
+/* Supports `PEXPIRETIME key` */
+func (redis *redis) PExpireTime(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
 	}
 
-	return protocol.EncodeResp(ttl, false)
+	return ttlReply(redis.Store.PExpireTime(cmd.Args[0]))
 }
 
 /* Supports EXPIRE key seconds [NX | XX | GT | LT] */
 func (redis *redis) Expire(cmd protocol.RedisCmd) []byte {
+	return redis.expireIn(cmd, 1000)
+}
+
+/* Supports PEXPIRE key milliseconds [NX | XX | GT | LT] */
+func (redis *redis) PExpire(cmd protocol.RedisCmd) []byte {
+	return redis.expireIn(cmd, 1)
+}
+
+/* Supports EXPIREAT key unix-time-seconds [NX | XX | GT | LT] */
+func (redis *redis) ExpireAt(cmd protocol.RedisCmd) []byte {
+	return redis.expireAt(cmd, 1000)
+}
+
+/* Supports PEXPIREAT key unix-time-milliseconds [NX | XX | GT | LT] */
+func (redis *redis) PExpireAt(cmd protocol.RedisCmd) []byte {
+	return redis.expireAt(cmd, 1)
+}
+
+/* Supports PERSIST key */
+func (redis *redis) Persist(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	var result int64 = 0
+	if redis.Store.Persist(cmd.Args[0]) {
+		result = 1
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
+// expireIn sets a time to live relative to now, given in units of unitMs milliseconds.
+// Like Redis, a time to live that is not positive deletes the key.
+func (redis *redis) expireIn(cmd protocol.RedisCmd, unitMs int64) []byte {
 	args := cmd.Args
-	argsLen := len(args)
+	if len(args) < 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	ttl, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return protocol.RespValueNotIntegerOrOutOfRange
+	}
+
+	now := time.Now().UnixMilli()
+	if ttl > (math.MaxInt64-now)/unitMs || ttl < (math.MinInt64+now)/unitMs {
+		return protocol.EncodeResp(errors.InvalidExpireTime(cmd.Cmd), false)
+	}
+
+	return redis.setExpireTime(cmd, now+ttl*unitMs)
+}
 
-	if argsLen < 2 {
+// expireAt sets an absolute expiration time, given as a unix time in units of unitMs
+// milliseconds. A time in the past deletes the key.
+func (redis *redis) expireAt(cmd protocol.RedisCmd, unitMs int64) []byte {
+	args := cmd.Args
+	if len(args) < 2 {
 		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
 	}
 
-	key := args[0]
-	ttlSeconds, err := strconv.ParseInt(args[1], 10, 64)
-	if err != nil || ttlSeconds <= 0 {
+	when, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return protocol.RespValueNotIntegerOrOutOfRange
+	}
+
+	if when > math.MaxInt64/unitMs || when < math.MinInt64/unitMs {
 		return protocol.EncodeResp(errors.InvalidExpireTime(cmd.Cmd), false)
 	}
 
+	return redis.setExpireTime(cmd, when*unitMs)
+}
+
+func (redis *redis) setExpireTime(cmd protocol.RedisCmd, expireAtMs int64) []byte {
+	opt, errReply := parseExpireOptions(cmd, 2, len(cmd.Args))
+	if errReply != nil {
+		return errReply
+	}
+
+	if !redis.Store.ExpireAt(cmd.Args[0], expireAtMs, opt) {
+		return protocol.RespExpireTimeoutNotSet
+	}
+
+	return protocol.RespExpireTimeoutSet
+}
+
+// ttlReply encodes a time to live or an expiration time, or the reason there is none.
+func ttlReply(ttl int64) []byte {
+	switch ttl {
+	case protocol.KeyNotExists:
+		return protocol.RespTTLKeyNotExist
+	case protocol.NoExpire:
+		return protocol.RespTTLKeyExistNoExpire
+	default:
+		return protocol.EncodeResp(ttl, false)
+	}
+}
+
+// parseExpireOptions parses the NX | XX | GT | LT flags in args[from:to].
+// On failure the error reply is returned instead.
+func parseExpireOptions(cmd protocol.RedisCmd, from, to int) (storage.ExpireOptions, []byte) {
 	var opt storage.ExpireOptions
 
-	for i := 2; i < argsLen; i++ {
-		cmdOpt := strings.ToUpper(args[i])
+	for i := from; i < to; i++ {
+		cmdOpt := strings.ToUpper(cmd.Args[i])
 		switch cmdOpt {
 		case "NX":
 			opt.NX = true
@@ -57,19 +164,14 @@ func (redis *redis) Expire(cmd protocol.RedisCmd) []byte {
 		case "LT":
 			opt.LT = true
 		default:
-			return protocol.EncodeResp(errors.InvalidCommandOption(cmdOpt, cmd.Cmd), false)
+			return opt, protocol.EncodeResp(errors.InvalidCommandOption(cmdOpt, cmd.Cmd), false)
 		}
 	}
 
 	// The GT, LT and NX options are mutually exclusive.
 	if (opt.NX && opt.XX) || (opt.GT && opt.LT) || (opt.NX && (opt.GT || opt.LT)) {
-		return protocol.RespExpireOptionsNotCompatible
+		return opt, protocol.RespExpireOptionsNotCompatible
 	}
 
-	ok := redis.Store.Expire(key, ttlSeconds, opt)
-	if !ok {
-		return protocol.RespExpireTimeoutNotSet
-	}
-
-	return protocol.RespExpireTimeoutSet
+	return opt, nil
 }
@@ -1,10 +1,14 @@
 package command
 
 import (
+	"math"
 	"strconv"
+	"strings"
 
 	"github.com/manhhung2111/go-redis/internal/protocol"
 	"github.com/manhhung2111/go-redis/internal/errors"
+	"github.com/manhhung2111/go-redis/internal/storage"
+	"github.com/manhhung2111/go-redis/internal/storage/types"
 )
 
 /* Support HGET key field */
@@ -41,6 +45,25 @@ func (redis *redis) HGetAll(cmd protocol.RedisCmd) []byte {
 	return protocol.EncodeResp(result, false)
 }
 
+/* Support HSCAN key cursor [MATCH pattern] [COUNT count] [NOVALUES] */
+func (redis *redis) HScan(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) < 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	args, errResp := parseScanArgs(cmd.Args[1:], false, true)
+	if errResp != nil {
+		return errResp
+	}
+
+	next, fields, err := redis.Store.HScan(cmd.Args[0], args.cursor, args.pattern, args.count, !args.noValues)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return encodeScanReply(next, fields)
+}
+
 /* Support HMGET key field [field ...] */
 func (redis *redis) HMGet(cmd protocol.RedisCmd) []byte {
 	args := cmd.Args
@@ -76,6 +99,26 @@ func (redis *redis) HIncrBy(cmd protocol.RedisCmd) []byte {
 	return protocol.EncodeResp(result, false)
 }
 
+/* Support HINCRBYFLOAT key field increment */
+func (redis *redis) HIncrByFloat(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) != 3 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	increment, ok := types.ParseLongDouble(args[2])
+	if !ok {
+		return protocol.RespValueNotValidFloat
+	}
+
+	result, err := redis.Store.HIncrByFloat(args[0], args[1], increment)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
 /* HKEYS key */
 func (redis *redis) HKeys(cmd protocol.RedisCmd) []byte {
 	args := cmd.Args
@@ -185,3 +228,217 @@ func (redis *redis) HExists(cmd protocol.RedisCmd) []byte {
 
 	return protocol.EncodeResp(result, false)
 }
+
+/* Support HSTRLEN key field */
+func (redis *redis) HStrLen(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) != 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	result, err := redis.Store.HStrLen(args[0], args[1])
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
+/* Support HRANDFIELD key [count [WITHVALUES]] */
+func (redis *redis) HRandField(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 1 || len(args) > 3 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	count := 1
+	if len(args) >= 2 {
+		newCount, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return protocol.RespValueNotIntegerOrOutOfRange
+		}
+		// Like Redis, so that negating the count cannot overflow
+		if newCount < -math.MaxInt64/2 {
+			return protocol.RespValueOutOfRange
+		}
+		count = int(newCount)
+	}
+
+	withValues := false
+	if len(args) == 3 {
+		if !strings.EqualFold(args[2], "WITHVALUES") {
+			return protocol.RespSyntaxError
+		}
+		withValues = true
+	}
+
+	result, err := redis.Store.HRandField(args[0], count, withValues)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	if len(args) == 1 {
+		if len(result) == 0 {
+			return protocol.RespNilBulkString
+		}
+		return protocol.EncodeResp(result[0], false)
+	}
+	return protocol.EncodeResp(result, false)
+}
+
+/* Support HGETDEL key FIELDS numfields field [field ...] */
+func (redis *redis) HGetDel(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 4 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	fields, errResp := parseHashFields(args[1:], false)
+	if errResp != nil {
+		return errResp
+	}
+
+	result, err := redis.Store.HGetDel(args[0], fields)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
+/* Support HGETEX key [EX seconds | PX milliseconds | EXAT unix-time-seconds | PXAT unix-time-milliseconds | PERSIST] FIELDS numfields field [field ...] */
+func (redis *redis) HGetEx(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 4 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	var (
+		persist    bool
+		expireAtMs int64
+	)
+
+	i := 1
+	for ; i < len(args) && !strings.EqualFold(args[i], "FIELDS"); i++ {
+		opt := strings.ToUpper(args[i])
+		switch opt {
+		case "EX", "PX", "EXAT", "PXAT":
+			if i+1 >= len(args) {
+				return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+			}
+			if persist || expireAtMs != 0 {
+				return protocol.RespSyntaxError
+			}
+
+			var errReply []byte
+			expireAtMs, errReply = parseFieldExpireTime(cmd, opt, args[i+1])
+			if errReply != nil {
+				return errReply
+			}
+			i++
+		case "PERSIST":
+			if persist || expireAtMs != 0 {
+				return protocol.RespSyntaxError
+			}
+			persist = true
+		default:
+			return protocol.EncodeResp(errors.InvalidCommandOption(opt, cmd.Cmd), false)
+		}
+	}
+
+	fields, errResp := parseHashFields(args[i:], false)
+	if errResp != nil {
+		return errResp
+	}
+
+	result, err := redis.Store.HGetEx(args[0], fields, expireAtMs, persist)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
+/* Support HSETEX key [FNX | FXX] [EX seconds | PX milliseconds | EXAT unix-time-seconds | PXAT unix-time-milliseconds | KEEPTTL] FIELDS numfields field value [field value ...] */
+func (redis *redis) HSetEx(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 5 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	var options storage.HSetExOptions
+	i := 1
+	for ; i < len(args) && !strings.EqualFold(args[i], "FIELDS"); i++ {
+		opt := strings.ToUpper(args[i])
+		switch opt {
+		case "FNX", "FXX":
+			if options.FNX || options.FXX {
+				return protocol.RespSyntaxError
+			}
+			options.FNX = opt == "FNX"
+			options.FXX = opt == "FXX"
+		case "KEEPTTL":
+			if options.KeepTTL || options.ExpireAtMs != 0 {
+				return protocol.RespSyntaxError
+			}
+			options.KeepTTL = true
+		case "EX", "PX", "EXAT", "PXAT":
+			if i+1 >= len(args) {
+				return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+			}
+			if options.KeepTTL || options.ExpireAtMs != 0 {
+				return protocol.RespSyntaxError
+			}
+
+			var errReply []byte
+			options.ExpireAtMs, errReply = parseFieldExpireTime(cmd, opt, args[i+1])
+			if errReply != nil {
+				return errReply
+			}
+			i++
+		default:
+			return protocol.EncodeResp(errors.InvalidCommandOption(opt, cmd.Cmd), false)
+		}
+	}
+
+	fieldValues, errResp := parseHashFields(args[i:], true)
+	if errResp != nil {
+		return errResp
+	}
+
+	fieldValue := make(map[string]string, len(fieldValues)/2)
+	for j := 0; j < len(fieldValues); j += 2 {
+		fieldValue[fieldValues[j]] = fieldValues[j+1]
+	}
+
+	result, err := redis.Store.HSetEx(args[0], fieldValue, options)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
+// parseHashFields parses the FIELDS numfields field [field ...] block that ends the arguments
+// of the hash field commands, with a value after every field when withValues is set. On
+// failure the error reply is returned instead.
+func parseHashFields(args []string, withValues bool) ([]string, []byte) {
+	if len(args) < 2 || !strings.EqualFold(args[0], "FIELDS") {
+		return nil, protocol.RespHashFieldsMissing
+	}
+
+	numFields, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || numFields <= 0 {
+		return nil, protocol.RespHashNumFieldsNotPositive
+	}
+
+	perField := int64(1)
+	if withValues {
+		perField = 2
+	}
+	if numFields > int64(len(args)) || numFields*perField != int64(len(args)-2) {
+		return nil, protocol.RespHashNumFieldsMismatch
+	}
+
+	return args[2:], nil
+}
@@ -0,0 +1,167 @@
+package command
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/errors"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+)
+
+// maxFieldExpireTimeMs is the latest expiration time of a hash field in unix milliseconds,
+// the same as in Redis.
+const maxFieldExpireTimeMs = 1<<48 - 1
+
+/* Supports `HEXPIRE key seconds [NX | XX | GT | LT] FIELDS numfields field [field ...]` */
+func (redis *redis) HExpire(cmd protocol.RedisCmd) []byte {
+	return redis.hExpireIn(cmd, 1000)
+}
+
+/* Supports `HPEXPIRE key milliseconds [NX | XX | GT | LT] FIELDS numfields field [field ...]` */
+func (redis *redis) HPExpire(cmd protocol.RedisCmd) []byte {
+	return redis.hExpireIn(cmd, 1)
+}
+
+/* Supports `HEXPIREAT key unix-time-seconds [NX | XX | GT | LT] FIELDS numfields field [field ...]` */
+func (redis *redis) HExpireAt(cmd protocol.RedisCmd) []byte {
+	return redis.hExpireAt(cmd, 1000)
+}
+
+/* Supports `HPEXPIREAT key unix-time-milliseconds [NX | XX | GT | LT] FIELDS numfields field [field ...]` */
+func (redis *redis) HPExpireAt(cmd protocol.RedisCmd) []byte {
+	return redis.hExpireAt(cmd, 1)
+}
+
+/* Supports `HTTL key FIELDS numfields field [field ...]` */
+func (redis *redis) HTTL(cmd protocol.RedisCmd) []byte {
+	return redis.hFieldTimes(cmd, redis.Store.HTTL)
+}
+
+/* Supports `HPTTL key FIELDS numfields field [field ...]` */
+func (redis *redis) HPTTL(cmd protocol.RedisCmd) []byte {
+	return redis.hFieldTimes(cmd, redis.Store.HPTTL)
+}
+
+/* Supports `HEXPIRETIME key FIELDS numfields field [field ...]` */
+func (redis *redis) HExpireTime(cmd protocol.RedisCmd) []byte {
+	return redis.hFieldTimes(cmd, redis.Store.HExpireTime)
+}
+
+/* Supports `HPEXPIRETIME key FIELDS numfields field [field ...]` */
+func (redis *redis) HPExpireTime(cmd protocol.RedisCmd) []byte {
+	return redis.hFieldTimes(cmd, redis.Store.HPExpireTime)
+}
+
+/* Supports `HPERSIST key FIELDS numfields field [field ...]` */
+func (redis *redis) HPersist(cmd protocol.RedisCmd) []byte {
+	return redis.hFieldTimes(cmd, redis.Store.HPersist)
+}
+
+// hExpireIn sets the time to live of hash fields relative to now, given in units of unitMs
+// milliseconds. A time to live of 0 deletes the fields.
+func (redis *redis) hExpireIn(cmd protocol.RedisCmd, unitMs int64) []byte {
+	args := cmd.Args
+	if len(args) < 5 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	ttl, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return protocol.RespValueNotIntegerOrOutOfRange
+	}
+
+	now := time.Now().UnixMilli()
+	if ttl < 0 || ttl > (math.MaxInt64-now)/unitMs {
+		return protocol.EncodeResp(errors.InvalidExpireTime(cmd.Cmd), false)
+	}
+
+	return redis.setFieldExpireTime(cmd, now+ttl*unitMs)
+}
+
+// hExpireAt sets an absolute expiration time of hash fields, given as a unix time in units
+// of unitMs milliseconds. A time in the past deletes the fields.
+func (redis *redis) hExpireAt(cmd protocol.RedisCmd, unitMs int64) []byte {
+	args := cmd.Args
+	if len(args) < 5 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	when, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return protocol.RespValueNotIntegerOrOutOfRange
+	}
+
+	if when < 0 || when > math.MaxInt64/unitMs {
+		return protocol.EncodeResp(errors.InvalidExpireTime(cmd.Cmd), false)
+	}
+
+	return redis.setFieldExpireTime(cmd, when*unitMs)
+}
+
+func (redis *redis) setFieldExpireTime(cmd protocol.RedisCmd, expireAtMs int64) []byte {
+	if expireAtMs > maxFieldExpireTimeMs {
+		return protocol.EncodeResp(errors.InvalidExpireTime(cmd.Cmd), false)
+	}
+
+	fieldsAt := fieldsKeywordIndex(cmd.Args, 2)
+	opt, errReply := parseExpireOptions(cmd, 2, fieldsAt)
+	if errReply != nil {
+		return errReply
+	}
+
+	fields, errReply := parseHashFields(cmd.Args[fieldsAt:], false)
+	if errReply != nil {
+		return errReply
+	}
+
+	result, err := redis.Store.HExpireAt(cmd.Args[0], fields, expireAtMs, opt)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
+// parseFieldExpireTime is parseExpireTime for the expiration time of hash fields, which is
+// at most maxFieldExpireTimeMs.
+func parseFieldExpireTime(cmd protocol.RedisCmd, opt string, arg string) (int64, []byte) {
+	expireAtMs, errReply := parseExpireTime(cmd, opt, arg)
+	if errReply == nil && expireAtMs > maxFieldExpireTimeMs {
+		return 0, protocol.EncodeResp(errors.InvalidExpireTime(cmd.Cmd), false)
+	}
+	return expireAtMs, errReply
+}
+
+// hFieldTimes replies with the result of fn for the fields of a `key FIELDS numfields
+// field [field ...]` command.
+func (redis *redis) hFieldTimes(cmd protocol.RedisCmd, fn func(key string, fields []string) ([]int64, error)) []byte {
+	args := cmd.Args
+	if len(args) < 4 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	fields, errReply := parseHashFields(args[1:], false)
+	if errReply != nil {
+		return errReply
+	}
+
+	result, err := fn(args[0], fields)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
+// fieldsKeywordIndex returns the position of the FIELDS keyword in args, starting at from,
+// or len(args) when it is missing.
+func fieldsKeywordIndex(args []string, from int) int {
+	for i := from; i < len(args); i++ {
+		if strings.EqualFold(args[i], "FIELDS") {
+			return i
+		}
+	}
+	return len(args)
+}
@@ -5,18 +5,71 @@ import "github.com/manhhung2111/go-redis/internal/protocol"
 type StringCommands interface {
 	Get(cmd protocol.RedisCmd) []byte
 	Set(cmd protocol.RedisCmd) []byte
-	Del(cmd protocol.RedisCmd) []byte
+	GetEx(cmd protocol.RedisCmd) []byte
+	GetDel(cmd protocol.RedisCmd) []byte
+	GetSet(cmd protocol.RedisCmd) []byte
+	SetNX(cmd protocol.RedisCmd) []byte
+	SetEx(cmd protocol.RedisCmd) []byte
+	PSetEx(cmd protocol.RedisCmd) []byte
 	Incr(cmd protocol.RedisCmd) []byte
 	IncrBy(cmd protocol.RedisCmd) []byte
 	Decr(cmd protocol.RedisCmd) []byte
 	DecrBy(cmd protocol.RedisCmd) []byte
 	MGet(cmd protocol.RedisCmd) []byte
 	MSet(cmd protocol.RedisCmd) []byte
+	MSetNX(cmd protocol.RedisCmd) []byte
+	IncrByFloat(cmd protocol.RedisCmd) []byte
+	Append(cmd protocol.RedisCmd) []byte
+	StrLen(cmd protocol.RedisCmd) []byte
+	GetRange(cmd protocol.RedisCmd) []byte
+	SetRange(cmd protocol.RedisCmd) []byte
+	LCS(cmd protocol.RedisCmd) []byte
+}
+
+type BitmapCommands interface {
+	SetBit(cmd protocol.RedisCmd) []byte
+	GetBit(cmd protocol.RedisCmd) []byte
+	BitCount(cmd protocol.RedisCmd) []byte
+	BitPos(cmd protocol.RedisCmd) []byte
+	BitOp(cmd protocol.RedisCmd) []byte
+	BitField(cmd protocol.RedisCmd) []byte
+	BitFieldRO(cmd protocol.RedisCmd) []byte
+}
+
+type KeyspaceCommands interface {
+	Del(cmd protocol.RedisCmd) []byte
+	Unlink(cmd protocol.RedisCmd) []byte
+	Exists(cmd protocol.RedisCmd) []byte
+	Touch(cmd protocol.RedisCmd) []byte
+	Keys(cmd protocol.RedisCmd) []byte
+	Type(cmd protocol.RedisCmd) []byte
+	Object(cmd protocol.RedisCmd) []byte
+	Rename(cmd protocol.RedisCmd) []byte
+	RenameNX(cmd protocol.RedisCmd) []byte
+	Copy(cmd protocol.RedisCmd) []byte
+	RandomKey(cmd protocol.RedisCmd) []byte
+	DBSize(cmd protocol.RedisCmd) []byte
+	Scan(cmd protocol.RedisCmd) []byte
+}
+
+type DatabaseCommands interface {
+	Select(c *Client, cmd protocol.RedisCmd) []byte
+	Move(cmd protocol.RedisCmd) []byte
+	SwapDB(cmd protocol.RedisCmd) []byte
+	FlushDB(cmd protocol.RedisCmd) []byte
+	FlushAll(cmd protocol.RedisCmd) []byte
 }
 
 type ExpireCommands interface {
 	TTL(cmd protocol.RedisCmd) []byte
+	PTTL(cmd protocol.RedisCmd) []byte
+	ExpireTime(cmd protocol.RedisCmd) []byte
+	PExpireTime(cmd protocol.RedisCmd) []byte
 	Expire(cmd protocol.RedisCmd) []byte
+	PExpire(cmd protocol.RedisCmd) []byte
+	ExpireAt(cmd protocol.RedisCmd) []byte
+	PExpireAt(cmd protocol.RedisCmd) []byte
+	Persist(cmd protocol.RedisCmd) []byte
 	ActiveExpireCycle() int
 }
 
@@ -29,6 +82,15 @@ type SetCommands interface {
 	SRem(cmd protocol.RedisCmd) []byte
 	SPop(cmd protocol.RedisCmd) []byte
 	SRandMember(cmd protocol.RedisCmd) []byte
+	SScan(cmd protocol.RedisCmd) []byte
+	SMove(cmd protocol.RedisCmd) []byte
+	SInter(cmd protocol.RedisCmd) []byte
+	SInterCard(cmd protocol.RedisCmd) []byte
+	SInterStore(cmd protocol.RedisCmd) []byte
+	SUnion(cmd protocol.RedisCmd) []byte
+	SUnionStore(cmd protocol.RedisCmd) []byte
+	SDiff(cmd protocol.RedisCmd) []byte
+	SDiffStore(cmd protocol.RedisCmd) []byte
 }
 
 type ListCommands interface {
@@ -44,6 +106,11 @@ type ListCommands interface {
 	LTrim(cmd protocol.RedisCmd) []byte
 	LPushX(cmd protocol.RedisCmd) []byte
 	RPushX(cmd protocol.RedisCmd) []byte
+	LInsert(cmd protocol.RedisCmd) []byte
+	LPos(cmd protocol.RedisCmd) []byte
+	LMove(cmd protocol.RedisCmd) []byte
+	RPopLPush(cmd protocol.RedisCmd) []byte
+	LMPop(cmd protocol.RedisCmd) []byte
 }
 
 type HashCommands interface {
@@ -58,6 +125,22 @@ type HashCommands interface {
 	HSetNx(cmd protocol.RedisCmd) []byte
 	HDel(cmd protocol.RedisCmd) []byte
 	HExists(cmd protocol.RedisCmd) []byte
+	HScan(cmd protocol.RedisCmd) []byte
+	HIncrByFloat(cmd protocol.RedisCmd) []byte
+	HStrLen(cmd protocol.RedisCmd) []byte
+	HRandField(cmd protocol.RedisCmd) []byte
+	HGetDel(cmd protocol.RedisCmd) []byte
+	HGetEx(cmd protocol.RedisCmd) []byte
+	HSetEx(cmd protocol.RedisCmd) []byte
+	HExpire(cmd protocol.RedisCmd) []byte
+	HPExpire(cmd protocol.RedisCmd) []byte
+	HExpireAt(cmd protocol.RedisCmd) []byte
+	HPExpireAt(cmd protocol.RedisCmd) []byte
+	HTTL(cmd protocol.RedisCmd) []byte
+	HPTTL(cmd protocol.RedisCmd) []byte
+	HExpireTime(cmd protocol.RedisCmd) []byte
+	HPExpireTime(cmd protocol.RedisCmd) []byte
+	HPersist(cmd protocol.RedisCmd) []byte
 }
 
 type ZSetCommands interface {
@@ -69,12 +152,43 @@ type ZSetCommands interface {
 	ZMScore(cmd protocol.RedisCmd) []byte
 	ZPopMax(cmd protocol.RedisCmd) []byte
 	ZPopMin(cmd protocol.RedisCmd) []byte
+	ZMPop(cmd protocol.RedisCmd) []byte
 	ZRandMember(cmd protocol.RedisCmd) []byte
 	ZRange(cmd protocol.RedisCmd) []byte
+	ZRangeByScore(cmd protocol.RedisCmd) []byte
+	ZRevRangeByScore(cmd protocol.RedisCmd) []byte
+	ZRevRange(cmd protocol.RedisCmd) []byte
+	ZRangeStore(cmd protocol.RedisCmd) []byte
 	ZRank(cmd protocol.RedisCmd) []byte
 	ZRem(cmd protocol.RedisCmd) []byte
+	ZRemRangeByRank(cmd protocol.RedisCmd) []byte
+	ZRemRangeByScore(cmd protocol.RedisCmd) []byte
+	ZRemRangeByLex(cmd protocol.RedisCmd) []byte
 	ZRevRank(cmd protocol.RedisCmd) []byte
 	ZScore(cmd protocol.RedisCmd) []byte
+	ZScan(cmd protocol.RedisCmd) []byte
+	ZUnion(cmd protocol.RedisCmd) []byte
+	ZInter(cmd protocol.RedisCmd) []byte
+	ZDiff(cmd protocol.RedisCmd) []byte
+	ZUnionStore(cmd protocol.RedisCmd) []byte
+	ZInterStore(cmd protocol.RedisCmd) []byte
+	ZDiffStore(cmd protocol.RedisCmd) []byte
+	ZInterCard(cmd protocol.RedisCmd) []byte
+}
+
+type StreamCommands interface {
+	XAdd(cmd protocol.RedisCmd) []byte
+	XLen(cmd protocol.RedisCmd) []byte
+	XRange(cmd protocol.RedisCmd) []byte
+	XRevRange(cmd protocol.RedisCmd) []byte
+	XDel(cmd protocol.RedisCmd) []byte
+	XTrim(cmd protocol.RedisCmd) []byte
+	XGroup(cmd protocol.RedisCmd) []byte
+	XAck(cmd protocol.RedisCmd) []byte
+	XPending(cmd protocol.RedisCmd) []byte
+	XClaim(cmd protocol.RedisCmd) []byte
+	XAutoClaim(cmd protocol.RedisCmd) []byte
+	XInfo(cmd protocol.RedisCmd) []byte
 }
 
 type GeoCommands interface {
@@ -120,18 +234,55 @@ type CMSCommands interface {
 	CMSQuery(cmd protocol.RedisCmd) []byte
 }
 
+type PersistenceCommands interface {
+	Save(cmd protocol.RedisCmd) []byte
+	BGSave(cmd protocol.RedisCmd) []byte
+	LastSave(cmd protocol.RedisCmd) []byte
+	BGRewriteAOF(cmd protocol.RedisCmd) []byte
+}
+
+type TransactionCommands interface {
+	Multi(c *Client, cmd protocol.RedisCmd) []byte
+	Exec(c *Client, cmd protocol.RedisCmd) []byte
+	Discard(c *Client, cmd protocol.RedisCmd) []byte
+	Watch(c *Client, cmd protocol.RedisCmd) []byte
+	Unwatch(c *Client, cmd protocol.RedisCmd) []byte
+}
+
+type BlockingCommands interface {
+	BLPop(c *Client, cmd protocol.RedisCmd) []byte
+	BRPop(c *Client, cmd protocol.RedisCmd) []byte
+	BLMove(c *Client, cmd protocol.RedisCmd) []byte
+	BZPopMax(c *Client, cmd protocol.RedisCmd) []byte
+	BZPopMin(c *Client, cmd protocol.RedisCmd) []byte
+	XRead(c *Client, cmd protocol.RedisCmd) []byte
+	XReadGroup(c *Client, cmd protocol.RedisCmd) []byte
+	TimeoutBlockedClients()
+	UnblockedClients() []UnblockedClient
+}
+
 type Redis interface {
-	HandleCommand(cmd protocol.RedisCmd) []byte
+	HandleCommand(c *Client, cmd protocol.RedisCmd) []byte
+	ReplayCommand(cmd protocol.RedisCmd) error
+	FreeClient(c *Client)
+	RegisterCommand(name string, arity int, handler CommandHandler)
 	Ping(cmd protocol.RedisCmd) []byte
 	StringCommands
+	KeyspaceCommands
+	DatabaseCommands
+	BitmapCommands
 	ExpireCommands
 	SetCommands
 	ListCommands
 	HashCommands
 	ZSetCommands
+	StreamCommands
 	GeoCommands
 	BloomFilterCommands
 	CuckooFilterCommands
 	HyperLogLogCommands
 	CMSCommands
+	PersistenceCommands
+	TransactionCommands
+	BlockingCommands
 }
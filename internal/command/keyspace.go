@@ -0,0 +1,281 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/manhhung2111/go-redis/internal/errors"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+)
+
+const defaultScanCount = 10
+
+// objectHelp is the reply to OBJECT HELP, listing the supported subcommands like Redis.
+var objectHelp = []string{
+	"OBJECT <subcommand> [<arg> [value] [opt] ...]. Subcommands are:",
+	"ENCODING <key>",
+	"    Return the kind of internal representation used in order to store the value",
+	"    associated with a <key>.",
+	"HELP",
+	"    Print this help.",
+}
+
+// scanArgs holds the cursor and the options shared by SCAN, SSCAN, HSCAN and ZSCAN
+type scanArgs struct {
+	cursor   uint64
+	pattern  string
+	count    int
+	typeName string
+	noValues bool
+}
+
+/* Supports `DEL key [key...]` */
+func (redis *redis) Del(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) < 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	return protocol.EncodeResp(redis.Store.Del(cmd.Args...), false)
+}
+
+/* Support UNLINK key [key ...] */
+func (redis *redis) Unlink(cmd protocol.RedisCmd) []byte {
+	// Values are freed by the garbage collector, so unlinking is the same as deleting
+	return redis.Del(cmd)
+}
+
+/* Support EXISTS key [key ...] */
+func (redis *redis) Exists(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) < 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	// A key given several times is counted several times
+	var count int64
+	for _, key := range cmd.Args {
+		if redis.Store.Exists(key) {
+			count++
+		}
+	}
+
+	return protocol.EncodeResp(count, false)
+}
+
+/* Support TOUCH key [key ...] */
+func (redis *redis) Touch(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) < 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	return protocol.EncodeResp(redis.Store.Touch(cmd.Args...), false)
+}
+
+/* Support KEYS pattern */
+func (redis *redis) Keys(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	return protocol.EncodeResp(redis.Store.Keys(cmd.Args[0]), false)
+}
+
+/* Support TYPE key */
+func (redis *redis) Type(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	return protocol.EncodeResp(redis.Store.Type(cmd.Args[0]), true)
+}
+
+/* Support OBJECT ENCODING key | OBJECT HELP */
+func (redis *redis) Object(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) == 0 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	subcommand := strings.ToUpper(cmd.Args[0])
+	switch subcommand {
+	case "ENCODING":
+		if len(cmd.Args) != 2 {
+			return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd+"|"+subcommand), false)
+		}
+		return protocol.EncodeResp(redis.Store.Encoding(cmd.Args[1]), false)
+
+	case "HELP":
+		if len(cmd.Args) != 1 {
+			return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd+"|"+subcommand), false)
+		}
+		return protocol.EncodeResp(objectHelp, false)
+
+	default:
+		return protocol.EncodeResp(errors.UnknownSubcommand(cmd.Args[0], cmd.Cmd), false)
+	}
+}
+
+/* Support RENAME key newkey */
+func (redis *redis) Rename(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	if err := redis.Store.Rename(cmd.Args[0], cmd.Args[1]); err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.RespOK
+}
+
+/* Support RENAMENX key newkey */
+func (redis *redis) RenameNX(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	var result int64 = 0
+	renamed, err := redis.Store.RenameNX(cmd.Args[0], cmd.Args[1])
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	if renamed {
+		result = 1
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
+/* Support COPY source destination [DB destination-db] [REPLACE] */
+func (redis *redis) Copy(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	dstIndex := redis.db
+	replace := false
+	for i := 2; i < len(args); i++ {
+		switch {
+		case strings.EqualFold(args[i], "REPLACE"):
+			replace = true
+		case strings.EqualFold(args[i], "DB") && i+1 < len(args):
+			i++
+			index, errReply := redis.parseDBIndex(args[i])
+			if errReply != nil {
+				return errReply
+			}
+			dstIndex = index
+		default:
+			return protocol.RespSyntaxError
+		}
+	}
+
+	if args[0] == args[1] && dstIndex == redis.db {
+		return protocol.RespSameObject
+	}
+
+	var result int64 = 0
+	copied, err := redis.databases.Copy(redis.db, args[0], dstIndex, args[1], replace)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	if copied {
+		result = 1
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
+/* Support RANDOMKEY */
+func (redis *redis) RandomKey(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 0 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	return protocol.EncodeResp(redis.Store.RandomKey(), false)
+}
+
+/* Support DBSIZE */
+func (redis *redis) DBSize(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 0 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	return protocol.EncodeResp(redis.Store.DBSize(), false)
+}
+
+/* Support SCAN cursor [MATCH pattern] [COUNT count] [TYPE type] */
+func (redis *redis) Scan(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) < 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	args, errResp := parseScanArgs(cmd.Args, true, false)
+	if errResp != nil {
+		return errResp
+	}
+
+	next, keys := redis.Store.Scan(args.cursor, args.pattern, args.count, args.typeName)
+	return encodeScanReply(next, keys)
+}
+
+// parseScanArgs parses the cursor at args[0] and the options after it. TYPE is only
+// accepted with allowType and NOVALUES with allowNoValues.
+func parseScanArgs(args []string, allowType, allowNoValues bool) (scanArgs, []byte) {
+	result := scanArgs{count: defaultScanCount}
+
+	cursor, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return result, protocol.RespInvalidCursor
+	}
+	result.cursor = cursor
+
+	for i := 1; i < len(args); i++ {
+		option := strings.ToUpper(args[i])
+		hasValue := i+1 < len(args)
+
+		switch {
+		case option == "MATCH" && hasValue:
+			i++
+			result.pattern = args[i]
+		case option == "COUNT" && hasValue:
+			i++
+			count, err := strconv.Atoi(args[i])
+			if err != nil {
+				return result, protocol.RespValueNotIntegerOrOutOfRange
+			}
+			if count < 1 {
+				return result, protocol.RespSyntaxError
+			}
+			result.count = count
+		case option == "TYPE" && hasValue && allowType:
+			i++
+			result.typeName = args[i]
+		case option == "NOVALUES" && allowNoValues:
+			result.noValues = true
+		default:
+			return result, protocol.RespSyntaxError
+		}
+	}
+
+	return result, nil
+}
+
+func encodeScanReply(next uint64, elements []string) []byte {
+	return protocol.EncodeResp([]any{strconv.FormatUint(next, 10), elements}, false)
+}
+
+// parseNumKeys parses the numkeys argument at args[0] of the commands taking a number of
+// keys followed by options, and returns the keys and the arguments after them.
+func parseNumKeys(args []string) ([]string, []string, []byte) {
+	numKeys, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || numKeys <= 0 {
+		return nil, nil, protocol.RespNumKeysNotPositive
+	}
+	if numKeys > int64(len(args)-1) {
+		return nil, nil, protocol.RespNumKeysExceedArgs
+	}
+
+	return args[1 : 1+numKeys], args[1+numKeys:], nil
+}
@@ -1,7 +1,9 @@
 package command
 
 import (
+	"math"
 	"strconv"
+	"strings"
 
 	"github.com/manhhung2111/go-redis/internal/protocol"
 	"github.com/manhhung2111/go-redis/internal/errors"
@@ -270,3 +272,290 @@ func (redis *redis) RPushX(cmd protocol.RedisCmd) []byte {
 
 	return protocol.EncodeResp(result, false)
 }
+
+/* Support LINSERT key <BEFORE | AFTER> pivot element */
+func (redis *redis) LInsert(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) != 4 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	var before bool
+	switch strings.ToUpper(args[1]) {
+	case "BEFORE":
+		before = true
+	case "AFTER":
+		before = false
+	default:
+		return protocol.RespSyntaxError
+	}
+
+	result, err := redis.Store.LInsert(args[0], before, args[2], args[3])
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
+/* Support LPOS key element [RANK rank] [COUNT num-matches] [MAXLEN len] */
+func (redis *redis) LPos(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	var rank int64 = 1
+	var count, maxLen uint32
+	withCount := false
+	for i := 2; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			return protocol.RespSyntaxError
+		}
+
+		value, err := strconv.ParseInt(args[i+1], 10, 64)
+		if err != nil {
+			return protocol.RespValueNotIntegerOrOutOfRange
+		}
+
+		switch strings.ToUpper(args[i]) {
+		case "RANK":
+			if value == 0 {
+				return protocol.RespLPosRankZero
+			}
+			rank = value
+		case "COUNT":
+			if value < 0 {
+				return protocol.RespLPosCountNegative
+			}
+			count = uint32(min(value, math.MaxUint32))
+			withCount = true
+		case "MAXLEN":
+			if value < 0 {
+				return protocol.RespLPosMaxLenNegative
+			}
+			maxLen = uint32(min(value, math.MaxUint32))
+		default:
+			return protocol.RespSyntaxError
+		}
+	}
+
+	if !withCount {
+		count = 1
+	}
+
+	positions, err := redis.Store.LPos(args[0], args[1], rank, count, maxLen)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	if !withCount {
+		if len(positions) == 0 {
+			return protocol.RespNilBulkString
+		}
+		return protocol.EncodeResp(positions[0], false)
+	}
+
+	result := make([]int64, len(positions))
+	for i, pos := range positions {
+		result[i] = int64(pos)
+	}
+	return protocol.EncodeResp(result, false)
+}
+
+/* Support LMOVE source destination <LEFT | RIGHT> <LEFT | RIGHT> */
+func (redis *redis) LMove(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) != 4 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	fromLeft, ok := parseListEnd(args[2])
+	if !ok {
+		return protocol.RespSyntaxError
+	}
+
+	toLeft, ok := parseListEnd(args[3])
+	if !ok {
+		return protocol.RespSyntaxError
+	}
+
+	return redis.listMove(args[0], args[1], fromLeft, toLeft)
+}
+
+/* Support RPOPLPUSH source destination */
+func (redis *redis) RPopLPush(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) != 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	return redis.listMove(args[0], args[1], false, true)
+}
+
+func (redis *redis) listMove(source, destination string, fromLeft, toLeft bool) []byte {
+	moved, err := redis.Store.LMove(source, destination, fromLeft, toLeft)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(moved, false)
+}
+
+/* Support LMPOP numkeys key [key ...] <LEFT | RIGHT> [COUNT count] */
+func (redis *redis) LMPop(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 3 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	keys, rest, errReply := parseNumKeys(args)
+	if errReply != nil {
+		return errReply
+	}
+	if len(rest) == 0 {
+		return protocol.RespNumKeysExceedArgs
+	}
+
+	fromLeft, ok := parseListEnd(rest[0])
+	if !ok {
+		return protocol.RespSyntaxError
+	}
+
+	var count uint32 = 1
+	switch len(rest) {
+	case 1:
+	case 3:
+		if !strings.EqualFold(rest[1], "COUNT") {
+			return protocol.RespSyntaxError
+		}
+		value, err := strconv.ParseInt(rest[2], 10, 64)
+		if err != nil || value <= 0 {
+			return protocol.RespCountNotPositive
+		}
+		count = uint32(min(value, math.MaxUint32))
+	default:
+		return protocol.RespSyntaxError
+	}
+
+	key, popped, err := redis.Store.LMPop(keys, fromLeft, count)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	if popped == nil {
+		return protocol.RespNilArray
+	}
+
+	return protocol.EncodeResp([]any{key, popped}, false)
+}
+
+/* Support BLPOP key [key ...] timeout */
+func (redis *redis) BLPop(c *Client, cmd protocol.RedisCmd) []byte {
+	return redis.blockingListPop(c, cmd, redis.Store.LPop)
+}
+
+/* Support BRPOP key [key ...] timeout */
+func (redis *redis) BRPop(c *Client, cmd protocol.RedisCmd) []byte {
+	return redis.blockingListPop(c, cmd, redis.Store.RPop)
+}
+
+// blockingListPop pops an element of the first non-empty list among the keys, or blocks
+// until one of them gets an element. Once blocked, keys holding another type are skipped.
+func (redis *redis) blockingListPop(c *Client, cmd protocol.RedisCmd,
+	pop func(key string, count uint32) ([]string, error)) []byte {
+	args := cmd.Args
+	if len(args) < 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	timeout, errResp := parseBlockingTimeout(args[len(args)-1])
+	if errResp != nil {
+		return errResp
+	}
+
+	keys := args[:len(args)-1]
+	tryPop := func(blocked bool) []byte {
+		for _, key := range keys {
+			length, err := redis.Store.LLen(key)
+			if err == nil && length == 0 {
+				continue
+			}
+
+			var popped []string
+			if err == nil {
+				popped, err = pop(key, 1)
+			}
+			if err != nil {
+				if blocked {
+					continue
+				}
+				return protocol.EncodeResp(err, false)
+			}
+
+			return protocol.EncodeResp([]string{key, popped[0]}, false)
+		}
+		return nil
+	}
+
+	if reply := tryPop(false); reply != nil {
+		return reply
+	}
+	return redis.block(c, cmd, keys, timeout, protocol.RespNilArray, func() []byte { return tryPop(true) })
+}
+
+/* Support BLMOVE source destination <LEFT | RIGHT> <LEFT | RIGHT> timeout */
+func (redis *redis) BLMove(c *Client, cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) != 5 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	fromLeft, ok := parseListEnd(args[2])
+	if !ok {
+		return protocol.RespSyntaxError
+	}
+
+	toLeft, ok := parseListEnd(args[3])
+	if !ok {
+		return protocol.RespSyntaxError
+	}
+
+	timeout, errResp := parseBlockingTimeout(args[4])
+	if errResp != nil {
+		return errResp
+	}
+
+	source, destination := args[0], args[1]
+	tryMove := func(blocked bool) []byte {
+		moved, err := redis.Store.LMove(source, destination, fromLeft, toLeft)
+		if err != nil {
+			if blocked {
+				return nil
+			}
+			return protocol.EncodeResp(err, false)
+		}
+
+		if moved == nil {
+			return nil
+		}
+		return protocol.EncodeResp(*moved, false)
+	}
+
+	if reply := tryMove(false); reply != nil {
+		return reply
+	}
+	return redis.block(c, cmd, []string{source}, timeout, protocol.RespNilBulkString, func() []byte { return tryMove(true) })
+}
+
+// parseListEnd parses the LEFT or RIGHT argument of the move commands, true meaning LEFT.
+func parseListEnd(arg string) (bool, bool) {
+	switch strings.ToUpper(arg) {
+	case "LEFT":
+		return true, true
+	case "RIGHT":
+		return false, true
+	default:
+		return false, false
+	}
+}
@@ -0,0 +1,54 @@
+package command
+
+import (
+	"github.com/manhhung2111/go-redis/internal/errors"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+)
+
+/* Supports `SAVE` */
+func (redis *redis) Save(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 0 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	if err := redis.rdb.Save(); err != nil {
+		return protocol.EncodeResp(errors.PersistenceFailed(err), false)
+	}
+
+	return protocol.RespOK
+}
+
+/* Supports `BGSAVE` */
+func (redis *redis) BGSave(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 0 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	if err := redis.rdb.BGSave(); err != nil {
+		return protocol.EncodeResp(errors.PersistenceFailed(err), false)
+	}
+
+	return protocol.RespBgsaveStarted
+}
+
+/* Supports `LASTSAVE` */
+func (redis *redis) LastSave(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 0 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	return protocol.EncodeResp(redis.rdb.LastSave(), false)
+}
+
+/* Supports `BGREWRITEAOF` */
+func (redis *redis) BGRewriteAOF(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 0 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	if err := redis.aof.BGRewrite(); err != nil {
+		return protocol.EncodeResp(errors.PersistenceFailed(err), false)
+	}
+
+	return protocol.RespBgrewriteaofStarted
+}
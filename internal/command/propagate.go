@@ -0,0 +1,332 @@
+package command
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/protocol"
+	"github.com/manhhung2111/go-redis/internal/storage"
+	"github.com/manhhung2111/go-redis/internal/storage/types"
+)
+
+// propagate records a successful write for the save rules and the append-only file.
+// Writes that depend on the current time or on randomness are logged as deterministic
+// equivalents, so that replaying the log rebuilds the same dataset at any later time.
+func (redis *redis) propagate(cmd protocol.RedisCmd, reply []byte) {
+	redis.rdb.AddDirty(1)
+
+	switch cmd.Cmd {
+	case "EXPIRE", "PEXPIRE", "EXPIREAT":
+		// Relative TTLs become absolute, replaying must not extend the key lifetime
+		if reply[1] == '1' {
+			redis.propagateExpireTime(cmd.Args[0])
+		}
+
+	case "SET":
+		// Nothing was set because of NX or XX. With GET, the old value tells whether the key existed
+		opts, _ := parseSetArgs(cmd)
+		isNil := bytes.Equal(reply, protocol.RespNilBulkString)
+		if (!opts.get && isNil) || (opts.get && ((opts.nx && !isNil) || (opts.xx && isNil))) {
+			return
+		}
+		redis.feed("SET", cmd.Args[0], cmd.Args[1])
+		redis.propagateExpireTime(cmd.Args[0])
+
+	case "SETEX", "PSETEX":
+		redis.feed("SET", cmd.Args[0], cmd.Args[2])
+		redis.propagateExpireTime(cmd.Args[0])
+
+	case "INCRBYFLOAT":
+		// Set the result, float arithmetic must not be redone on replay
+		value, _, _ := protocol.DecodeResp(reply)
+		redis.feed("SET", cmd.Args[0], value.(string), "KEEPTTL")
+
+	case "GETEX":
+		// Only the expiration time changed, if the key exists and any option was given
+		if len(cmd.Args) == 1 || bytes.Equal(reply, protocol.RespNilBulkString) {
+			return
+		}
+		if redis.Store.PExpireTime(cmd.Args[0]) == protocol.NoExpire {
+			redis.feed("PERSIST", cmd.Args[0])
+		} else {
+			redis.propagateExpireTime(cmd.Args[0])
+		}
+
+	case "HINCRBYFLOAT":
+		value, _, _ := protocol.DecodeResp(reply)
+		redis.feed("HSETEX", cmd.Args[0], "KEEPTTL", "FIELDS", "1", cmd.Args[1], value.(string))
+
+	case "HGETEX", "HSETEX":
+		// Nothing was set because of FNX or FXX
+		if cmd.Cmd == "HSETEX" && reply[1] == '0' {
+			return
+		}
+		opt, fieldsAt := hashExpireOption(cmd.Args)
+		switch {
+		case fieldsAt == 1 && cmd.Cmd == "HGETEX":
+			// HGETEX without options only reads
+		case opt != 0 && (strings.EqualFold(cmd.Args[opt], "EX") || strings.EqualFold(cmd.Args[opt], "PX")):
+			redis.propagateFieldExpireTime(cmd, opt, fieldsAt)
+		default:
+			redis.feed(append([]string{cmd.Cmd}, cmd.Args...)...)
+		}
+
+	case "HEXPIRE", "HPEXPIRE":
+		// Like EXPIRE, log the absolute expiration time of the fields that got one
+		decoded, _, _ := protocol.DecodeResp(reply)
+		fields, _ := parseHashFields(cmd.Args[fieldsKeywordIndex(cmd.Args, 2):], false)
+		var set, deleted []string
+		for i, outcome := range decoded.([]interface{}) {
+			switch outcome.(int64) {
+			case storage.FieldExpireSet:
+				set = append(set, fields[i])
+			case storage.FieldExpireDelete:
+				deleted = append(deleted, fields[i])
+			}
+		}
+		if len(set) > 0 {
+			// The fields may have expired already, if their time to live was very short
+			if expireTimes, _ := redis.Store.HPExpireTime(cmd.Args[0], set[:1]); expireTimes[0] > 0 {
+				args := []string{"HPEXPIREAT", cmd.Args[0], strconv.FormatInt(expireTimes[0], 10), "FIELDS", strconv.Itoa(len(set))}
+				redis.feed(append(args, set...)...)
+			} else {
+				deleted = append(deleted, set...)
+			}
+		}
+		if len(deleted) > 0 {
+			redis.feed(append([]string{"HDEL", cmd.Args[0]}, deleted...)...)
+		}
+
+	case "SPOP":
+		// Pop the members that were actually chosen instead of new random ones
+		popped, _, _ := protocol.DecodeResp(reply)
+		switch v := popped.(type) {
+		case string:
+			redis.feed("SREM", cmd.Args[0], v)
+		case []interface{}:
+			if len(v) == 0 {
+				return
+			}
+			args := []string{"SREM", cmd.Args[0]}
+			for _, member := range v {
+				args = append(args, member.(string))
+			}
+			redis.feed(args...)
+		}
+
+	case "BLPOP", "BRPOP", "BZPOPMAX", "BZPOPMIN":
+		// Pop from the key that served the client, replaying must neither block nor pick another key
+		popped, _, _ := protocol.DecodeResp(reply)
+		if v, ok := popped.([]interface{}); ok {
+			redis.feed(cmd.Cmd[1:], v[0].(string))
+		}
+
+	case "BLMOVE":
+		moved, _, _ := protocol.DecodeResp(reply)
+		if element, ok := moved.(string); ok {
+			pop, push := "RPOP", "RPUSH"
+			if strings.EqualFold(cmd.Args[2], "LEFT") {
+				pop = "LPOP"
+			}
+			if strings.EqualFold(cmd.Args[3], "LEFT") {
+				push = "LPUSH"
+			}
+			redis.feedAtomic([]string{pop, cmd.Args[0]}, []string{push, cmd.Args[1], element})
+		}
+
+	case "XADD":
+		// Log the generated ID, and trimming as the exact length it left
+		if reply[0] != '$' || bytes.Equal(reply, protocol.RespNilBulkString) {
+			return
+		}
+		id, _, _ := protocol.DecodeResp(reply)
+		options, idIndex, _ := parseXAddArgs(cmd.Args)
+		args := []string{"XADD", cmd.Args[0]}
+		if options.Trim.Strategy != types.StreamTrimNone {
+			length, _ := redis.Store.XLen(cmd.Args[0])
+			args = append(args, "MAXLEN", "=", strconv.FormatUint(uint64(length), 10))
+		}
+		args = append(args, id.(string))
+		redis.feed(append(args, cmd.Args[idIndex+1:]...)...)
+
+	case "XTRIM":
+		// Approximate trimming depends on node boundaries, which a replay may not rebuild
+		if reply[1] == '0' {
+			return
+		}
+		length, _ := redis.Store.XLen(cmd.Args[0])
+		redis.feed("XTRIM", cmd.Args[0], "MAXLEN", "=", strconv.FormatUint(uint64(length), 10))
+
+	case "XREADGROUP":
+		// Reading is deterministic given the same group state, only blocking is left out
+		if bytes.Equal(reply, protocol.RespNilArray) {
+			return
+		}
+		args := []string{"XREADGROUP"}
+		for i := 0; i < len(cmd.Args); i++ {
+			if strings.EqualFold(cmd.Args[i], "STREAMS") {
+				args = append(args, cmd.Args[i:]...)
+				break
+			}
+			if strings.EqualFold(cmd.Args[i], "BLOCK") {
+				i++
+				continue
+			}
+			args = append(args, cmd.Args[i])
+		}
+		redis.feed(args...)
+
+	case "XCLAIM":
+		// Claim the entries that were idle long enough, whatever their idle time at replay
+		decoded, _, _ := protocol.DecodeResp(reply)
+		claimed := claimedStreamIDs(decoded.([]interface{}))
+		if len(claimed) == 0 {
+			return
+		}
+		_, options, _ := parseXClaimArgs(cmd.Args, time.Now().UnixMilli())
+		redis.feed(xClaimArgs(cmd.Args[:3], claimed, options)...)
+
+	case "XAUTOCLAIM":
+		decoded, _, _ := protocol.DecodeResp(reply)
+		parts := decoded.([]interface{})
+		if claimed := claimedStreamIDs(parts[1].([]interface{})); len(claimed) > 0 {
+			options := types.StreamClaimOptions{DeliveryTime: time.Now().UnixMilli()}
+			for _, arg := range cmd.Args[5:] {
+				options.JustID = options.JustID || strings.EqualFold(arg, "JUSTID")
+			}
+			redis.feed(xClaimArgs(cmd.Args[:3], claimed, options)...)
+		}
+		// Pending entries deleted from the stream were dropped from the group
+		if deleted := parts[2].([]interface{}); len(deleted) > 0 {
+			args := []string{"XACK", cmd.Args[0], cmd.Args[1]}
+			for _, id := range deleted {
+				args = append(args, id.(string))
+			}
+			redis.feed(args...)
+		}
+
+	default:
+		redis.feed(append([]string{cmd.Cmd}, cmd.Args...)...)
+	}
+}
+
+// propagateExpireTime logs the absolute expiration time of key, or its deletion
+// when the new expiration time was already in the past.
+func (redis *redis) propagateExpireTime(key string) {
+	switch expireAt := redis.Store.PExpireTime(key); expireAt {
+	case protocol.KeyNotExists:
+		redis.feed("DEL", key)
+	case protocol.NoExpire:
+	default:
+		redis.feed("PEXPIREAT", key, strconv.FormatInt(expireAt, 10))
+	}
+}
+
+// propagateFieldExpireTime logs a HGETEX or HSETEX with the relative expiration time at
+// opt replaced by the absolute one it set, or the deletion of the fields when they expired
+// right away.
+func (redis *redis) propagateFieldExpireTime(cmd protocol.RedisCmd, opt, fieldsAt int) {
+	step := 1
+	if cmd.Cmd == "HSETEX" {
+		step = 2
+	}
+	fields := make([]string, 0, len(cmd.Args)-fieldsAt-2)
+	for i := fieldsAt + 2; i < len(cmd.Args); i += step {
+		fields = append(fields, cmd.Args[i])
+	}
+
+	expireTimes, _ := redis.Store.HPExpireTime(cmd.Args[0], fields)
+	for _, expireAt := range expireTimes {
+		if expireAt > 0 {
+			args := append([]string{cmd.Cmd}, cmd.Args...)
+			args[opt+1] = "PXAT"
+			args[opt+2] = strconv.FormatInt(expireAt, 10)
+			redis.feed(args...)
+			return
+		}
+	}
+
+	redis.feed(append([]string{"HDEL", cmd.Args[0]}, fields...)...)
+}
+
+// hashExpireOption returns the positions of the expiration option, 0 when there is none,
+// and of the FIELDS keyword in the arguments of a valid HGETEX or HSETEX.
+func hashExpireOption(args []string) (int, int) {
+	opt := 0
+	i := 1
+	for ; !strings.EqualFold(args[i], "FIELDS"); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "EX", "PX", "EXAT", "PXAT":
+			opt = i
+			i++
+		case "PERSIST", "KEEPTTL":
+			opt = i
+		}
+	}
+	return opt, i
+}
+
+// feed logs a command to the append-only file, opening the MULTI block of a running EXEC first.
+func (redis *redis) feed(args ...string) {
+	redis.aof.Select(redis.db)
+	if redis.inExec && !redis.execLogged {
+		redis.aof.Feed("MULTI")
+		redis.execLogged = true
+	}
+	redis.aof.Feed(args...)
+}
+
+// feedAtomic logs commands that must be replayed together, wrapping them in MULTI and
+// EXEC unless they are already part of a running EXEC.
+func (redis *redis) feedAtomic(cmds ...[]string) {
+	if redis.inExec {
+		for _, args := range cmds {
+			redis.feed(args...)
+		}
+		return
+	}
+
+	redis.aof.Select(redis.db)
+	redis.aof.Feed("MULTI")
+	for _, args := range cmds {
+		redis.aof.Feed(args...)
+	}
+	redis.aof.Feed("EXEC")
+}
+
+// claimedStreamIDs returns the IDs of claimed entries, replied as either entries or IDs.
+func claimedStreamIDs(items []interface{}) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			ids = append(ids, v)
+		case []interface{}:
+			ids = append(ids, v[0].(string))
+		}
+	}
+	return ids
+}
+
+// xClaimArgs builds an XCLAIM of ids that replays without checking idle times.
+func xClaimArgs(keyGroupConsumer []string, ids []string, options types.StreamClaimOptions) []string {
+	args := append([]string{"XCLAIM"}, keyGroupConsumer...)
+	args = append(args, "0")
+	args = append(args, ids...)
+	args = append(args, "TIME", strconv.FormatInt(options.DeliveryTime, 10))
+	if options.RetryCount != nil {
+		args = append(args, "RETRYCOUNT", strconv.FormatInt(*options.RetryCount, 10))
+	}
+	if options.Force {
+		args = append(args, "FORCE")
+	}
+	if options.JustID {
+		args = append(args, "JUSTID")
+	}
+	if options.LastID != nil {
+		args = append(args, "LASTID", options.LastID.String())
+	}
+	return args
+}
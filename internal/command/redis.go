@@ -1,6 +1,10 @@
 package command
 
 import (
+	"bytes"
+	"fmt"
+
+	"github.com/manhhung2111/go-redis/internal/persistence"
 	"github.com/manhhung2111/go-redis/internal/protocol"
 	"github.com/manhhung2111/go-redis/internal/storage"
 	"github.com/manhhung2111/go-redis/internal/errors"
@@ -8,124 +12,387 @@ import (
 
 type CommandHandler func(cmd protocol.RedisCmd) []byte
 
+// ClientCommandHandler handles a command that reads or changes the state of the calling client.
+type ClientCommandHandler func(c *Client, cmd protocol.RedisCmd) []byte
+
+type commandFlags uint8
+
+const (
+	// The command may modify the keyspace. A successful call counts as a change for the
+	// save rules and is logged to the append-only file.
+	cmdWrite commandFlags = 1 << iota
+	// The command runs right away inside MULTI instead of being queued.
+	cmdNoQueue
+)
+
+// commandSpec describes an entry of the handlers table. The arity counts the command name,
+// a positive arity is the exact number of arguments and -N means at least N.
+type commandSpec struct {
+	handler CommandHandler // nil for the commands of clientHandlers
+	arity   int
+	flags   commandFlags
+}
+
+func (spec commandSpec) acceptsArgs(n int) bool {
+	if spec.arity < 0 {
+		return n+1 >= -spec.arity
+	}
+	return n+1 == spec.arity
+}
+
 type redis struct {
-	Store    storage.Store
-	handlers map[string]CommandHandler
+	Store     storage.Store // database selected by the running command
+	db        int           // index of Store
+	databases storage.Databases
+	rdb       *persistence.RDB
+	aof       *persistence.AOF
+	handlers  map[string]commandSpec
+
+	// Handlers of the commands that use the state of the calling client
+	clientHandlers map[string]ClientCommandHandler
+
+	loading      bool    // commands are replayed from the append-only file
+	replayClient *Client // client that replays the append-only file
+	inExec       bool    // EXEC is running queued commands
+	execLogged   bool    // the running EXEC already logged MULTI to the append-only file
+
+	blockedKeys    map[dbKey][]*Client // clients blocked on each key, oldest first
+	blockedClients map[*Client]struct{}
+	unblocked      []UnblockedClient // served or timed out since the last UnblockedClients
 }
 
 func NewRedis(
-	store storage.Store,
+	databases storage.Databases,
+	rdb *persistence.RDB,
+	aof *persistence.AOF,
 ) Redis {
-	redis := &redis{Store: store}
-	redis.handlers = map[string]CommandHandler{
-		"PING": redis.Ping,
-
-		"SET":    redis.Set,
-		"GET":    redis.Get,
-		"DEL":    redis.Del,
-		"TTL":    redis.TTL,
-		"EXPIRE": redis.Expire,
-		"INCR":   redis.Incr,
-		"INCRBY": redis.IncrBy,
-		"DECR":   redis.Decr,
-		"DECRBY": redis.DecrBy,
-		"MGET":   redis.MGet,
-		"MSET":   redis.MSet,
-
-		"SADD":        redis.SAdd,
-		"SCARD":       redis.SCard,
-		"SISMEMBER":   redis.SIsMember,
-		"SMEMBERS":    redis.SMembers,
-		"SMISMEMBER":  redis.SMIsMember,
-		"SREM":        redis.SRem,
-		"SPOP":        redis.SPop,
-		"SRANDMEMBER": redis.SRandMember,
-
-		"LPUSH":  redis.LPush,
-		"LPOP":   redis.LPop,
-		"RPUSH":  redis.RPush,
-		"RPOP":   redis.RPop,
-		"LRANGE": redis.LRange,
-		"LINDEX": redis.LIndex,
-		"LLEN":   redis.LLen,
-		"LREM":   redis.LRem,
-		"LSET":   redis.LSet,
-		"LTRIM":  redis.LTrim,
-		"LPUSHX": redis.LPushX,
-		"RPUSHX": redis.RPushX,
-
-		"HGET":    redis.HGet,
-		"HGETALL": redis.HGetAll,
-		"HMGET":   redis.HMGet,
-		"HINCRBY": redis.HIncrBy,
-		"HKEYS":   redis.HKeys,
-		"HVALS":   redis.HVals,
-		"HLEN":    redis.HLen,
-		"HSET":    redis.HSet,
-		"HSETNX":  redis.HSetNx,
-		"HDEL":    redis.HDel,
-		"HEXISTS": redis.HExists,
-
-		"ZADD":        redis.ZAdd,
-		"ZCARD":       redis.ZCard,
-		"ZCOUNT":      redis.ZCount,
-		"ZINCRBY":     redis.ZIncrBy,
-		"ZLEXCOUNT":   redis.ZLexCount,
-		"ZMSCORE":     redis.ZMScore,
-		"ZPOPMAX":     redis.ZPopMax,
-		"ZPOPMIN":     redis.ZPopMin,
-		"ZRANDMEMBER": redis.ZRandMember,
-		"ZRANGE":      redis.ZRange,
-		"ZRANK":       redis.ZRank,
-		"ZREM":        redis.ZRem,
-		"ZREVRANK":    redis.ZRevRank,
-		"ZSCORE":      redis.ZScore,
-
-		"GEOADD":    redis.GeoAdd,
-		"GEODIST":   redis.GeoDist,
-		"GEOHASH":   redis.GeoHash,
-		"GEOPOS":    redis.GeoPos,
-		"GEOSEARCH": redis.GeoSearch,
-
-		"BF.ADD":     redis.BFAdd,
-		"BF.CARD":    redis.BFCard,
-		"BF.EXISTS":  redis.BFExists,
-		"BF.INFO":    redis.BFInfo,
-		"BF.MADD":    redis.BFMAdd,
-		"BF.MEXISTS": redis.BFMExists,
-		"BF.RESERVE": redis.BFReserve,
-
-		"CF.ADD":     redis.CFAdd,
-		"CF.ADDNX":   redis.CFAddNx,
-		"CF.COUNT":   redis.CFCount,
-		"CF.DEL":     redis.CFDel,
-		"CF.EXISTS":  redis.CFExists,
-		"CF.INFO":    redis.CFInfo,
-		"CF.MEXISTS": redis.CFMExists,
-		"CF.RESERVE": redis.CFReserve,
-
-		"PFADD":   redis.PFAdd,
-		"PFCOUNT": redis.PFCount,
-		"PFMERGE": redis.PFMerge,
-
-		"CMS.INCRBY":     redis.CMSIncrBy,
-		"CMS.INFO":       redis.CMSInfo,
-		"CMS.INITBYDIM":  redis.CMSInitByDim,
-		"CMS.INITBYPROB": redis.CMSInitByProb,
-		"CMS.QUERY":      redis.CMSQuery,
+	redis := &redis{
+		databases:      databases,
+		rdb:            rdb,
+		aof:            aof,
+		replayClient:   NewClient(),
+		blockedKeys:    make(map[dbKey][]*Client),
+		blockedClients: make(map[*Client]struct{}),
+	}
+	redis.selectDB(0)
+	redis.handlers = map[string]commandSpec{
+		"PING": {redis.Ping, -1, 0},
+
+		"DEL":       {redis.Del, -2, cmdWrite},
+		"UNLINK":    {redis.Unlink, -2, cmdWrite},
+		"EXISTS":    {redis.Exists, -2, 0},
+		"TOUCH":     {redis.Touch, -2, 0},
+		"KEYS":      {redis.Keys, 2, 0},
+		"TYPE":      {redis.Type, 2, 0},
+		"OBJECT":    {redis.Object, -2, 0},
+		"RENAME":    {redis.Rename, 3, cmdWrite},
+		"RENAMENX":  {redis.RenameNX, 3, cmdWrite},
+		"COPY":      {redis.Copy, -3, cmdWrite},
+		"RANDOMKEY": {redis.RandomKey, 1, 0},
+		"DBSIZE":    {redis.DBSize, 1, 0},
+		"SCAN":      {redis.Scan, -2, 0},
+
+		"SELECT":   {nil, 2, 0},
+		"MOVE":     {redis.Move, 3, cmdWrite},
+		"SWAPDB":   {redis.SwapDB, 3, cmdWrite},
+		"FLUSHDB":  {redis.FlushDB, -1, cmdWrite},
+		"FLUSHALL": {redis.FlushAll, -1, cmdWrite},
+
+		"SET":    {redis.Set, -3, cmdWrite},
+		"GET":    {redis.Get, 2, 0},
+		"TTL":    {redis.TTL, 2, 0},
+		"EXPIRE": {redis.Expire, -3, cmdWrite},
+
+		"PTTL":        {redis.PTTL, 2, 0},
+		"EXPIRETIME":  {redis.ExpireTime, 2, 0},
+		"PEXPIRETIME": {redis.PExpireTime, 2, 0},
+		"PEXPIRE":     {redis.PExpire, -3, cmdWrite},
+		"EXPIREAT":    {redis.ExpireAt, -3, cmdWrite},
+		"PEXPIREAT":   {redis.PExpireAt, -3, cmdWrite},
+		"PERSIST":     {redis.Persist, 2, cmdWrite},
+
+		"INCR":   {redis.Incr, 2, cmdWrite},
+		"INCRBY": {redis.IncrBy, 3, cmdWrite},
+		"DECR":   {redis.Decr, 2, cmdWrite},
+		"DECRBY": {redis.DecrBy, 3, cmdWrite},
+		"MGET":   {redis.MGet, -2, 0},
+		"MSET":   {redis.MSet, -3, cmdWrite},
+		"MSETNX": {redis.MSetNX, -3, cmdWrite},
+
+		"GETEX":  {redis.GetEx, -2, cmdWrite},
+		"GETDEL": {redis.GetDel, 2, cmdWrite},
+		"GETSET": {redis.GetSet, 3, cmdWrite},
+		"SETNX":  {redis.SetNX, 3, cmdWrite},
+		"SETEX":  {redis.SetEx, 4, cmdWrite},
+		"PSETEX": {redis.PSetEx, 4, cmdWrite},
+
+		"INCRBYFLOAT": {redis.IncrByFloat, 3, cmdWrite},
+		"APPEND":      {redis.Append, 3, cmdWrite},
+		"STRLEN":      {redis.StrLen, 2, 0},
+		"GETRANGE":    {redis.GetRange, 4, 0},
+		"SETRANGE":    {redis.SetRange, 4, cmdWrite},
+		"LCS":         {redis.LCS, -3, 0},
+
+		"SETBIT":      {redis.SetBit, 4, cmdWrite},
+		"GETBIT":      {redis.GetBit, 3, 0},
+		"BITCOUNT":    {redis.BitCount, -2, 0},
+		"BITPOS":      {redis.BitPos, -3, 0},
+		"BITOP":       {redis.BitOp, -4, cmdWrite},
+		"BITFIELD":    {redis.BitField, -2, cmdWrite},
+		"BITFIELD_RO": {redis.BitFieldRO, -2, 0},
+
+		"SADD":        {redis.SAdd, -3, cmdWrite},
+		"SCARD":       {redis.SCard, 2, 0},
+		"SISMEMBER":   {redis.SIsMember, 3, 0},
+		"SMEMBERS":    {redis.SMembers, 2, 0},
+		"SMISMEMBER":  {redis.SMIsMember, -3, 0},
+		"SREM":        {redis.SRem, -3, cmdWrite},
+		"SPOP":        {redis.SPop, -2, cmdWrite},
+		"SRANDMEMBER": {redis.SRandMember, -2, 0},
+		"SSCAN":       {redis.SScan, -3, 0},
+		"SMOVE":       {redis.SMove, 4, cmdWrite},
+		"SINTER":      {redis.SInter, -2, 0},
+		"SINTERCARD":  {redis.SInterCard, -3, 0},
+		"SINTERSTORE": {redis.SInterStore, -3, cmdWrite},
+		"SUNION":      {redis.SUnion, -2, 0},
+		"SUNIONSTORE": {redis.SUnionStore, -3, cmdWrite},
+		"SDIFF":       {redis.SDiff, -2, 0},
+		"SDIFFSTORE":  {redis.SDiffStore, -3, cmdWrite},
+
+		"LPUSH":     {redis.LPush, -3, cmdWrite},
+		"LPOP":      {redis.LPop, -2, cmdWrite},
+		"RPUSH":     {redis.RPush, -3, cmdWrite},
+		"RPOP":      {redis.RPop, -2, cmdWrite},
+		"LRANGE":    {redis.LRange, 4, 0},
+		"LINDEX":    {redis.LIndex, 3, 0},
+		"LLEN":      {redis.LLen, 2, 0},
+		"LREM":      {redis.LRem, 4, cmdWrite},
+		"LSET":      {redis.LSet, 4, cmdWrite},
+		"LTRIM":     {redis.LTrim, 4, cmdWrite},
+		"LPUSHX":    {redis.LPushX, -3, cmdWrite},
+		"RPUSHX":    {redis.RPushX, -3, cmdWrite},
+		"LINSERT":   {redis.LInsert, 5, cmdWrite},
+		"LPOS":      {redis.LPos, -3, 0},
+		"LMOVE":     {redis.LMove, 5, cmdWrite},
+		"RPOPLPUSH": {redis.RPopLPush, 3, cmdWrite},
+		"LMPOP":     {redis.LMPop, -4, cmdWrite},
+		"BLPOP":     {nil, -3, cmdWrite},
+		"BRPOP":     {nil, -3, cmdWrite},
+		"BLMOVE":    {nil, 6, cmdWrite},
+
+		"HGET":         {redis.HGet, 3, 0},
+		"HGETALL":      {redis.HGetAll, 2, 0},
+		"HMGET":        {redis.HMGet, -3, 0},
+		"HINCRBY":      {redis.HIncrBy, 4, cmdWrite},
+		"HINCRBYFLOAT": {redis.HIncrByFloat, 4, cmdWrite},
+		"HKEYS":        {redis.HKeys, 2, 0},
+		"HVALS":        {redis.HVals, 2, 0},
+		"HLEN":         {redis.HLen, 2, 0},
+		"HSET":         {redis.HSet, -4, cmdWrite},
+		"HSETNX":       {redis.HSetNx, 4, cmdWrite},
+		"HDEL":         {redis.HDel, -3, cmdWrite},
+		"HEXISTS":      {redis.HExists, 3, 0},
+		"HSCAN":        {redis.HScan, -3, 0},
+		"HSTRLEN":      {redis.HStrLen, 3, 0},
+		"HRANDFIELD":   {redis.HRandField, -2, 0},
+		"HGETDEL":      {redis.HGetDel, -5, cmdWrite},
+		"HGETEX":       {redis.HGetEx, -5, cmdWrite},
+		"HSETEX":       {redis.HSetEx, -6, cmdWrite},
+		"HEXPIRE":      {redis.HExpire, -6, cmdWrite},
+		"HPEXPIRE":     {redis.HPExpire, -6, cmdWrite},
+		"HEXPIREAT":    {redis.HExpireAt, -6, cmdWrite},
+		"HPEXPIREAT":   {redis.HPExpireAt, -6, cmdWrite},
+		"HTTL":         {redis.HTTL, -5, 0},
+		"HPTTL":        {redis.HPTTL, -5, 0},
+		"HEXPIRETIME":  {redis.HExpireTime, -5, 0},
+		"HPEXPIRETIME": {redis.HPExpireTime, -5, 0},
+		"HPERSIST":     {redis.HPersist, -5, cmdWrite},
+
+		"ZADD":             {redis.ZAdd, -4, cmdWrite},
+		"ZCARD":            {redis.ZCard, 2, 0},
+		"ZCOUNT":           {redis.ZCount, 4, 0},
+		"ZINCRBY":          {redis.ZIncrBy, 4, cmdWrite},
+		"ZLEXCOUNT":        {redis.ZLexCount, 4, 0},
+		"ZMSCORE":          {redis.ZMScore, -3, 0},
+		"ZPOPMAX":          {redis.ZPopMax, -2, cmdWrite},
+		"ZPOPMIN":          {redis.ZPopMin, -2, cmdWrite},
+		"ZMPOP":            {redis.ZMPop, -4, cmdWrite},
+		"ZRANDMEMBER":      {redis.ZRandMember, -2, 0},
+		"ZRANGE":           {redis.ZRange, -4, 0},
+		"ZRANGEBYSCORE":    {redis.ZRangeByScore, -4, 0},
+		"ZREVRANGEBYSCORE": {redis.ZRevRangeByScore, -4, 0},
+		"ZREVRANGE":        {redis.ZRevRange, -4, 0},
+		"ZRANGESTORE":      {redis.ZRangeStore, -5, cmdWrite},
+		"ZRANK":            {redis.ZRank, -3, 0},
+		"ZREM":             {redis.ZRem, -3, cmdWrite},
+		"ZREMRANGEBYRANK":  {redis.ZRemRangeByRank, 4, cmdWrite},
+		"ZREMRANGEBYSCORE": {redis.ZRemRangeByScore, 4, cmdWrite},
+		"ZREMRANGEBYLEX":   {redis.ZRemRangeByLex, 4, cmdWrite},
+		"ZREVRANK":         {redis.ZRevRank, -3, 0},
+		"ZSCORE":           {redis.ZScore, 3, 0},
+		"ZSCAN":            {redis.ZScan, -3, 0},
+		"ZUNION":           {redis.ZUnion, -3, 0},
+		"ZINTER":           {redis.ZInter, -3, 0},
+		"ZDIFF":            {redis.ZDiff, -3, 0},
+		"ZUNIONSTORE":      {redis.ZUnionStore, -4, cmdWrite},
+		"ZINTERSTORE":      {redis.ZInterStore, -4, cmdWrite},
+		"ZDIFFSTORE":       {redis.ZDiffStore, -4, cmdWrite},
+		"ZINTERCARD":       {redis.ZInterCard, -3, 0},
+		"BZPOPMAX":         {nil, -3, cmdWrite},
+		"BZPOPMIN":         {nil, -3, cmdWrite},
+
+		"XADD":       {redis.XAdd, -5, cmdWrite},
+		"XLEN":       {redis.XLen, 2, 0},
+		"XRANGE":     {redis.XRange, -4, 0},
+		"XREVRANGE":  {redis.XRevRange, -4, 0},
+		"XDEL":       {redis.XDel, -3, cmdWrite},
+		"XTRIM":      {redis.XTrim, -4, cmdWrite},
+		"XREAD":      {nil, -4, 0},
+		"XGROUP":     {redis.XGroup, -2, cmdWrite},
+		"XREADGROUP": {nil, -7, cmdWrite},
+		"XACK":       {redis.XAck, -4, cmdWrite},
+		"XPENDING":   {redis.XPending, -3, 0},
+		"XCLAIM":     {redis.XClaim, -6, cmdWrite},
+		"XAUTOCLAIM": {redis.XAutoClaim, -6, cmdWrite},
+		"XINFO":      {redis.XInfo, -2, 0},
+
+		"GEOADD":    {redis.GeoAdd, -5, cmdWrite},
+		"GEODIST":   {redis.GeoDist, -4, 0},
+		"GEOHASH":   {redis.GeoHash, -3, 0},
+		"GEOPOS":    {redis.GeoPos, -3, 0},
+		"GEOSEARCH": {redis.GeoSearch, -5, 0},
+
+		"BF.ADD":     {redis.BFAdd, 3, cmdWrite},
+		"BF.CARD":    {redis.BFCard, 2, 0},
+		"BF.EXISTS":  {redis.BFExists, 3, 0},
+		"BF.INFO":    {redis.BFInfo, -2, 0},
+		"BF.MADD":    {redis.BFMAdd, -3, cmdWrite},
+		"BF.MEXISTS": {redis.BFMExists, -3, 0},
+		"BF.RESERVE": {redis.BFReserve, -4, cmdWrite},
+
+		"CF.ADD":     {redis.CFAdd, 3, cmdWrite},
+		"CF.ADDNX":   {redis.CFAddNx, 3, cmdWrite},
+		"CF.COUNT":   {redis.CFCount, 3, 0},
+		"CF.DEL":     {redis.CFDel, 3, cmdWrite},
+		"CF.EXISTS":  {redis.CFExists, 3, 0},
+		"CF.INFO":    {redis.CFInfo, 2, 0},
+		"CF.MEXISTS": {redis.CFMExists, -3, 0},
+		"CF.RESERVE": {redis.CFReserve, -3, cmdWrite},
+
+		"PFADD":   {redis.PFAdd, -2, cmdWrite},
+		"PFCOUNT": {redis.PFCount, -2, 0},
+		"PFMERGE": {redis.PFMerge, -2, cmdWrite},
+
+		"CMS.INCRBY":     {redis.CMSIncrBy, -4, cmdWrite},
+		"CMS.INFO":       {redis.CMSInfo, 2, 0},
+		"CMS.INITBYDIM":  {redis.CMSInitByDim, 4, cmdWrite},
+		"CMS.INITBYPROB": {redis.CMSInitByProb, 4, cmdWrite},
+		"CMS.QUERY":      {redis.CMSQuery, -3, 0},
+
+		"SAVE":     {redis.Save, 1, 0},
+		"BGSAVE":   {redis.BGSave, 1, 0},
+		"LASTSAVE": {redis.LastSave, 1, 0},
+
+		"BGREWRITEAOF": {redis.BGRewriteAOF, 1, 0},
+
+		"MULTI":   {nil, 1, cmdNoQueue},
+		"EXEC":    {nil, 1, cmdNoQueue},
+		"DISCARD": {nil, 1, cmdNoQueue},
+		"WATCH":   {nil, -2, cmdNoQueue},
+		"UNWATCH": {nil, 1, 0},
+	}
+
+	redis.clientHandlers = map[string]ClientCommandHandler{
+		"SELECT": redis.Select,
+
+		"MULTI":   redis.Multi,
+		"EXEC":    redis.Exec,
+		"DISCARD": redis.Discard,
+		"WATCH":   redis.Watch,
+		"UNWATCH": redis.Unwatch,
+
+		"BLPOP":    redis.BLPop,
+		"BRPOP":    redis.BRPop,
+		"BLMOVE":   redis.BLMove,
+		"BZPOPMAX": redis.BZPopMax,
+		"BZPOPMIN": redis.BZPopMin,
+
+		"XREAD":      redis.XRead,
+		"XREADGROUP": redis.XReadGroup,
 	}
 
 	return redis
 }
 
-func (r *redis) HandleCommand(cmd protocol.RedisCmd) []byte {
-	handler, ok := r.handlers[cmd.Cmd]
+// RegisterCommand adds a command implemented outside of this package, such as PUBLISH
+// by the server. It is validated and queued by MULTI like any other command.
+func (redis *redis) RegisterCommand(name string, arity int, handler CommandHandler) {
+	redis.handlers[name] = commandSpec{handler, arity, 0}
+}
+
+// HandleCommand runs a command on behalf of client c. Inside MULTI, commands are
+// validated and queued until EXEC. A nil reply means the command blocked c, its reply
+// comes later from UnblockedClients.
+func (redis *redis) HandleCommand(c *Client, cmd protocol.RedisCmd) []byte {
+	reply := redis.handleCommand(c, cmd)
+	redis.serveReadyKeys()
+	return reply
+}
+
+func (redis *redis) handleCommand(c *Client, cmd protocol.RedisCmd) []byte {
+	spec, ok := redis.handlers[cmd.Cmd]
 	if !ok {
+		c.FlagTransactionError()
 		return protocol.EncodeResp(errors.InvalidCommand(cmd.Cmd), false)
 	}
-	return handler(cmd)
+
+	if !spec.acceptsArgs(len(cmd.Args)) {
+		c.FlagTransactionError()
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	if c.multi && spec.flags&cmdNoQueue == 0 {
+		c.queue = append(c.queue, cmd)
+		return protocol.RespQueued
+	}
+
+	return redis.call(c, spec, cmd)
+}
+
+func (redis *redis) call(c *Client, spec commandSpec, cmd protocol.RedisCmd) []byte {
+	redis.selectDB(c.db)
+
+	var reply []byte
+	if spec.handler != nil {
+		reply = spec.handler(cmd)
+	} else {
+		reply = redis.clientHandlers[cmd.Cmd](c, cmd)
+	}
+
+	if spec.flags&cmdWrite != 0 && !redis.loading && len(reply) > 0 && reply[0] != '-' {
+		redis.propagate(cmd, reply)
+	}
+	return reply
+}
+
+// ReplayCommand executes a command read back from the append-only file.
+// Replayed writes are neither counted as changes nor logged again.
+func (redis *redis) ReplayCommand(cmd protocol.RedisCmd) error {
+	redis.loading = true
+	defer func() { redis.loading = false }()
+
+	if reply := redis.HandleCommand(redis.replayClient, cmd); reply[0] == '-' {
+		return fmt.Errorf("%s", bytes.TrimSpace(reply[1:]))
+	}
+	return nil
+}
+
+// selectDB makes the database at index the one the handlers run against.
+func (redis *redis) selectDB(index int) {
+	redis.db = index
+	redis.Store = redis.databases.DB(index)
 }
 
-func (r *redis) ActiveExpireCycle() int {
-	return r.Store.ActiveExpireCycle()
+func (redis *redis) ActiveExpireCycle() int {
+	return redis.databases.ActiveExpireCycle()
 }
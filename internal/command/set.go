@@ -2,6 +2,7 @@ package command
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/manhhung2111/go-redis/internal/protocol"
 	"github.com/manhhung2111/go-redis/internal/errors"
@@ -72,6 +73,25 @@ func (redis *redis) SMembers(cmd protocol.RedisCmd) []byte {
 	return protocol.EncodeResp(members, false)
 }
 
+/* Support SSCAN key cursor [MATCH pattern] [COUNT count] */
+func (redis *redis) SScan(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) < 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	args, errResp := parseScanArgs(cmd.Args[1:], false, false)
+	if errResp != nil {
+		return errResp
+	}
+
+	next, members, err := redis.Store.SScan(cmd.Args[0], args.cursor, args.pattern, args.count)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return encodeScanReply(next, members)
+}
+
 /* Support SMISMEMBER key member [member ...] */
 func (redis *redis) SMIsMember(cmd protocol.RedisCmd) []byte {
 	args := cmd.Args
@@ -170,3 +190,125 @@ func (redis *redis) SRandMember(cmd protocol.RedisCmd) []byte {
 
 	return protocol.EncodeResp(randMembers, false)
 }
+
+/* Support SMOVE source destination member */
+func (redis *redis) SMove(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) != 3 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	moved, err := redis.Store.SMove(args[0], args[1], args[2])
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	var result int64 = 0
+	if moved {
+		result = 1
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
+/* Support SINTER key [key ...] */
+func (redis *redis) SInter(cmd protocol.RedisCmd) []byte {
+	return redis.setAlgebra(cmd, redis.Store.SInter)
+}
+
+/* Support SUNION key [key ...] */
+func (redis *redis) SUnion(cmd protocol.RedisCmd) []byte {
+	return redis.setAlgebra(cmd, redis.Store.SUnion)
+}
+
+/* Support SDIFF key [key ...] */
+func (redis *redis) SDiff(cmd protocol.RedisCmd) []byte {
+	return redis.setAlgebra(cmd, redis.Store.SDiff)
+}
+
+func (redis *redis) setAlgebra(cmd protocol.RedisCmd, op func(keys ...string) ([]string, error)) []byte {
+	args := cmd.Args
+	if len(args) < 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	members, err := op(args...)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(members, false)
+}
+
+/* Support SINTERSTORE destination key [key ...] */
+func (redis *redis) SInterStore(cmd protocol.RedisCmd) []byte {
+	return redis.setAlgebraStore(cmd, redis.Store.SInterStore)
+}
+
+/* Support SUNIONSTORE destination key [key ...] */
+func (redis *redis) SUnionStore(cmd protocol.RedisCmd) []byte {
+	return redis.setAlgebraStore(cmd, redis.Store.SUnionStore)
+}
+
+/* Support SDIFFSTORE destination key [key ...] */
+func (redis *redis) SDiffStore(cmd protocol.RedisCmd) []byte {
+	return redis.setAlgebraStore(cmd, redis.Store.SDiffStore)
+}
+
+func (redis *redis) setAlgebraStore(cmd protocol.RedisCmd, op func(dst string, keys ...string) (int64, error)) []byte {
+	args := cmd.Args
+	if len(args) < 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	size, err := op(args[0], args[1:]...)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(size, false)
+}
+
+/* Support SINTERCARD numkeys key [key ...] [LIMIT limit] */
+func (redis *redis) SInterCard(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	keys, rest, errReply := parseNumKeys(args)
+	if errReply != nil {
+		return errReply
+	}
+
+	limit, errReply := parseCardLimit(rest)
+	if errReply != nil {
+		return errReply
+	}
+
+	size, err := redis.Store.SInterCard(limit, keys...)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(size, false)
+}
+
+// parseCardLimit parses the `[LIMIT limit]` option of SINTERCARD and ZINTERCARD, 0 when
+// it is missing.
+func parseCardLimit(args []string) (int, []byte) {
+	limit := 0
+	for i := 0; i < len(args); i += 2 {
+		if !strings.EqualFold(args[i], "LIMIT") || i+1 >= len(args) {
+			return 0, protocol.RespSyntaxError
+		}
+
+		value, err := strconv.ParseInt(args[i+1], 10, 64)
+		if err != nil || value < 0 {
+			return 0, protocol.RespLimitNegative
+		}
+		limit = int(value)
+	}
+
+	return limit, nil
+}
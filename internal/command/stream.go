@@ -0,0 +1,487 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/errors"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+	"github.com/manhhung2111/go-redis/internal/storage/types"
+)
+
+/* Support XADD key [NOMKSTREAM] [<MAXLEN | MINID> [= | ~] threshold [LIMIT count]] <* | id> field value [field value ...] */
+func (redis *redis) XAdd(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 4 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	options, idIndex, errResp := parseXAddArgs(args)
+	if errResp != nil {
+		return errResp
+	}
+
+	fields := args[idIndex+1:]
+	if len(fields) == 0 || len(fields)%2 != 0 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	id, err := redis.Store.XAdd(args[0], fields, options)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	if id == nil {
+		return protocol.RespNilBulkString
+	}
+
+	return protocol.EncodeResp(id.String(), false)
+}
+
+// parseXAddArgs parses the options and the ID of XADD, and returns the index of the ID argument.
+func parseXAddArgs(args []string) (types.StreamAddOptions, int, []byte) {
+	var options types.StreamAddOptions
+
+	i := 1
+	for ; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NOMKSTREAM":
+			options.NoMkStream = true
+			continue
+
+		case "MAXLEN", "MINID":
+			trim, next, errResp := parseStreamTrimArgs(args, i)
+			if errResp != nil {
+				return options, 0, errResp
+			}
+			options.Trim = trim
+			i = next - 1
+			continue
+		}
+		break
+	}
+
+	if i >= len(args) {
+		return options, 0, protocol.RespSyntaxError
+	}
+
+	switch id := args[i]; {
+	case id == "*":
+		options.AutoID = true
+
+	case strings.HasSuffix(id, "-*"):
+		ms, err := strconv.ParseUint(strings.TrimSuffix(id, "-*"), 10, 64)
+		if err != nil {
+			return options, 0, protocol.EncodeResp(types.ErrInvalidStreamID, false)
+		}
+		options.ID = types.StreamID{Ms: ms}
+		options.AutoSeq = true
+
+	default:
+		parsed, err := types.ParseStreamID(id, 0)
+		if err != nil {
+			return options, 0, protocol.EncodeResp(err, false)
+		}
+		options.ID = parsed
+	}
+
+	return options, i, nil
+}
+
+// parseStreamTrimArgs parses `<MAXLEN | MINID> [= | ~] threshold [LIMIT count]` starting at
+// args[i], and returns the index of the first argument after it.
+func parseStreamTrimArgs(args []string, i int) (types.StreamTrimOptions, int, []byte) {
+	var options types.StreamTrimOptions
+	if strings.EqualFold(args[i], "MAXLEN") {
+		options.Strategy = types.StreamTrimMaxLen
+	} else {
+		options.Strategy = types.StreamTrimMinID
+	}
+	i++
+
+	if i < len(args) && (args[i] == "=" || args[i] == "~") {
+		options.Approx = args[i] == "~"
+		i++
+	}
+
+	if i >= len(args) {
+		return options, 0, protocol.RespSyntaxError
+	}
+
+	if options.Strategy == types.StreamTrimMaxLen {
+		maxLen, err := strconv.ParseInt(args[i], 10, 64)
+		if err != nil {
+			return options, 0, protocol.RespValueNotIntegerOrOutOfRange
+		}
+		if maxLen < 0 {
+			return options, 0, protocol.RespStreamMaxLenNegative
+		}
+		options.MaxLen = uint64(maxLen)
+	} else {
+		minID, err := types.ParseStreamID(args[i], 0)
+		if err != nil {
+			return options, 0, protocol.EncodeResp(err, false)
+		}
+		options.MinID = minID
+	}
+	i++
+
+	if i+1 < len(args) && strings.EqualFold(args[i], "LIMIT") {
+		limit, err := strconv.ParseInt(args[i+1], 10, 64)
+		if err != nil {
+			return options, 0, protocol.RespValueNotIntegerOrOutOfRange
+		}
+		if limit < 0 {
+			return options, 0, protocol.RespStreamLimitNegative
+		}
+		if !options.Approx {
+			return options, 0, protocol.RespStreamLimitWithoutApprox
+		}
+		options.Limit = uint64(limit)
+		i += 2
+	}
+
+	return options, i, nil
+}
+
+/* Support XLEN key */
+func (redis *redis) XLen(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) != 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	length, err := redis.Store.XLen(args[0])
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(length, false)
+}
+
+/* Support XRANGE key start end [COUNT count] */
+func (redis *redis) XRange(cmd protocol.RedisCmd) []byte {
+	return redis.xRange(cmd, false)
+}
+
+/* Support XREVRANGE key end start [COUNT count] */
+func (redis *redis) XRevRange(cmd protocol.RedisCmd) []byte {
+	return redis.xRange(cmd, true)
+}
+
+func (redis *redis) xRange(cmd protocol.RedisCmd, rev bool) []byte {
+	args := cmd.Args
+	if len(args) < 3 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	if len(args) != 3 && len(args) != 5 {
+		return protocol.RespSyntaxError
+	}
+
+	startArg, endArg := args[1], args[2]
+	if rev {
+		startArg, endArg = endArg, startArg
+	}
+
+	start, errResp := parseRangeStreamID(startArg, false)
+	if errResp != nil {
+		return errResp
+	}
+
+	end, errResp := parseRangeStreamID(endArg, true)
+	if errResp != nil {
+		return errResp
+	}
+
+	count := -1
+	if len(args) == 5 {
+		if !strings.EqualFold(args[3], "COUNT") {
+			return protocol.RespSyntaxError
+		}
+		newCount, err := strconv.ParseInt(args[4], 10, 64)
+		if err != nil {
+			return protocol.RespValueNotIntegerOrOutOfRange
+		}
+		if newCount <= 0 {
+			return protocol.EncodeResp([]string{}, false)
+		}
+		count = int(min(newCount, int64(^uint32(0)>>1)))
+	}
+
+	entries, err := redis.Store.XRange(args[0], start, end, count, rev)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return encodeStreamEntries(entries)
+}
+
+// parseRangeStreamID parses a bound of XRANGE: `-`, `+`, an ID, an ID without its sequence
+// number, or any of the latter prefixed with `(` to exclude it.
+func parseRangeStreamID(arg string, isEnd bool) (types.StreamID, []byte) {
+	switch arg {
+	case "-":
+		return types.MinStreamID, nil
+	case "+":
+		return types.MaxStreamID, nil
+	}
+
+	exclusive := strings.HasPrefix(arg, "(")
+	if exclusive {
+		arg = arg[1:]
+	}
+
+	var missingSeq uint64
+	if isEnd {
+		missingSeq = types.MaxStreamID.Seq
+	}
+
+	id, err := types.ParseStreamID(arg, missingSeq)
+	if err != nil {
+		return id, protocol.EncodeResp(err, false)
+	}
+
+	if !exclusive {
+		return id, nil
+	}
+
+	var ok bool
+	if isEnd {
+		if id, ok = id.Decr(); !ok {
+			return id, protocol.RespStreamInvalidEndID
+		}
+	} else if id, ok = id.Incr(); !ok {
+		return id, protocol.RespStreamInvalidStartID
+	}
+	return id, nil
+}
+
+// encodeStreamEntries encodes entries as `[id, [field, value, ...]]` pairs. Entries with
+// nil fields were deleted while pending and have a nil array instead.
+func encodeStreamEntries(entries []types.StreamEntry) []byte {
+	buf := []byte("*" + strconv.Itoa(len(entries)) + "\r\n")
+	for _, entry := range entries {
+		buf = append(buf, "*2\r\n"...)
+		buf = append(buf, protocol.EncodeResp(entry.ID.String(), false)...)
+		if entry.Fields == nil {
+			buf = append(buf, protocol.RespNilArray...)
+		} else {
+			buf = append(buf, protocol.EncodeResp(entry.Fields, false)...)
+		}
+	}
+	return buf
+}
+
+// encodeStreamReads encodes the reply of XREAD and XREADGROUP, `[key, entries]` for each
+// stream that was read.
+func encodeStreamReads(keys []string, entries [][]types.StreamEntry) []byte {
+	buf := []byte("*" + strconv.Itoa(len(keys)) + "\r\n")
+	for i, key := range keys {
+		buf = append(buf, "*2\r\n"...)
+		buf = append(buf, protocol.EncodeResp(key, false)...)
+		buf = append(buf, encodeStreamEntries(entries[i])...)
+	}
+	return buf
+}
+
+/* Support XDEL key id [id ...] */
+func (redis *redis) XDel(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	ids, err := parseStreamIDs(args[1:])
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	deleted, err := redis.Store.XDel(args[0], ids)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(deleted, false)
+}
+
+/* Support XTRIM key <MAXLEN | MINID> [= | ~] threshold [LIMIT count] */
+func (redis *redis) XTrim(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 3 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	strategy := strings.ToUpper(args[1])
+	if strategy != "MAXLEN" && strategy != "MINID" {
+		return protocol.RespSyntaxError
+	}
+
+	options, next, errResp := parseStreamTrimArgs(args, 1)
+	if errResp != nil {
+		return errResp
+	}
+
+	if next != len(args) {
+		return protocol.RespSyntaxError
+	}
+
+	removed, err := redis.Store.XTrim(args[0], options)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(removed, false)
+}
+
+// streamReadArgs holds the arguments shared by XREAD and XREADGROUP.
+type streamReadArgs struct {
+	group    string
+	consumer string
+	count    int // zero reads every entry
+	noAck    bool
+	block    bool
+	timeout  time.Duration // with block, zero waits forever
+	keys     []string
+	ids      []string
+}
+
+// parseStreamReadArgs parses `[GROUP group consumer] [COUNT count] [BLOCK milliseconds] [NOACK]
+// STREAMS key [key ...] id [id ...]`, GROUP and NOACK being only accepted by XREADGROUP.
+func parseStreamReadArgs(args []string, isGroup bool) (streamReadArgs, []byte) {
+	var parsed streamReadArgs
+	hasGroup := false
+
+	i := 0
+	for ; i < len(args); i++ {
+		option := strings.ToUpper(args[i])
+		if option == "STREAMS" {
+			break
+		}
+
+		switch {
+		case option == "COUNT" && i+1 < len(args):
+			count, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil {
+				return parsed, protocol.RespValueNotIntegerOrOutOfRange
+			}
+			parsed.count = int(min(max(count, 0), int64(^uint32(0)>>1)))
+			i++
+
+		case option == "BLOCK" && i+1 < len(args):
+			ms, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil {
+				return parsed, protocol.RespStreamTimeoutNotInteger
+			}
+			if ms < 0 {
+				return parsed, protocol.RespTimeoutNegative
+			}
+			parsed.block = true
+			parsed.timeout = time.Duration(ms) * time.Millisecond
+			i++
+
+		case option == "GROUP" && isGroup && i+2 < len(args):
+			parsed.group, parsed.consumer = args[i+1], args[i+2]
+			hasGroup = true
+			i += 2
+
+		case option == "NOACK" && isGroup:
+			parsed.noAck = true
+
+		default:
+			return parsed, protocol.RespSyntaxError
+		}
+	}
+
+	streams := len(args) - i - 1
+	if streams <= 0 {
+		return parsed, protocol.RespSyntaxError
+	}
+
+	if streams%2 != 0 {
+		if isGroup {
+			return parsed, protocol.RespStreamUnbalancedXGroup
+		}
+		return parsed, protocol.RespStreamUnbalancedXRead
+	}
+
+	if isGroup && !hasGroup {
+		return parsed, protocol.RespStreamMissingGroup
+	}
+
+	parsed.keys = args[i+1 : i+1+streams/2]
+	parsed.ids = args[i+1+streams/2:]
+	return parsed, nil
+}
+
+/* Support XREAD [COUNT count] [BLOCK milliseconds] STREAMS key [key ...] id [id ...] */
+func (redis *redis) XRead(c *Client, cmd protocol.RedisCmd) []byte {
+	args, errResp := parseStreamReadArgs(cmd.Args, false)
+	if errResp != nil {
+		return errResp
+	}
+
+	// `$` means the entries added from now on, it is resolved before blocking
+	ids := make([]types.StreamID, len(args.ids))
+	for i, arg := range args.ids {
+		switch arg {
+		case "$":
+			lastID, err := redis.Store.XLastID(args.keys[i])
+			if err != nil {
+				return protocol.EncodeResp(err, false)
+			}
+			ids[i] = lastID
+		case ">":
+			return protocol.RespStreamGreaterNeedsGroup
+		default:
+			id, err := types.ParseStreamID(arg, 0)
+			if err != nil {
+				return protocol.EncodeResp(err, false)
+			}
+			ids[i] = id
+		}
+	}
+
+	// Once blocked, a key that now holds another type is skipped like a key without new entries
+	read := func(blocked bool) ([]byte, error) {
+		var keys []string
+		var entries [][]types.StreamEntry
+		for i, key := range args.keys {
+			read, err := redis.Store.XRead(key, ids[i], args.count)
+			if err != nil && blocked {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if len(read) > 0 {
+				keys = append(keys, key)
+				entries = append(entries, read)
+			}
+		}
+
+		if len(keys) == 0 {
+			return nil, nil
+		}
+		return encodeStreamReads(keys, entries), nil
+	}
+
+	reply, err := read(false)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	if reply != nil {
+		return reply
+	}
+
+	if !args.block {
+		return protocol.RespNilArray
+	}
+
+	return redis.block(c, cmd, args.keys, args.timeout, protocol.RespNilArray, func() []byte {
+		reply, _ := read(true)
+		return reply
+	})
+}
@@ -0,0 +1,697 @@
+package command
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/errors"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+	"github.com/manhhung2111/go-redis/internal/storage"
+	"github.com/manhhung2111/go-redis/internal/storage/types"
+)
+
+// Maximum COUNT of XAUTOCLAIM, which scans up to ten times as many pending entries
+const maxAutoClaimCount = int64(^uint32(0)>>1) / 10
+
+/*
+Support
+
+	XGROUP CREATE key group <id | $> [MKSTREAM] [ENTRIESREAD entries-read]
+	XGROUP SETID key group <id | $> [ENTRIESREAD entries-read]
+	XGROUP DESTROY key group
+	XGROUP CREATECONSUMER key group consumer
+	XGROUP DELCONSUMER key group consumer
+*/
+func (redis *redis) XGroup(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) == 0 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	subcommand := strings.ToUpper(cmd.Args[0])
+	args := cmd.Args[1:]
+
+	var arityOk bool
+	switch subcommand {
+	case "CREATE":
+		arityOk = len(args) >= 3 && len(args) <= 6
+	case "SETID":
+		arityOk = len(args) == 3 || len(args) == 5
+	case "DESTROY":
+		arityOk = len(args) == 2
+	case "CREATECONSUMER", "DELCONSUMER":
+		arityOk = len(args) == 3
+	default:
+		return protocol.EncodeResp(errors.UnknownSubcommand(cmd.Args[0], cmd.Cmd), false)
+	}
+
+	if !arityOk {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd+"|"+subcommand), false)
+	}
+
+	key, group := args[0], args[1]
+	var err error
+	var reply []byte
+
+	switch subcommand {
+	case "CREATE", "SETID":
+		// nil for `$`, the last ID of the stream
+		var id *types.StreamID
+		if args[2] != "$" {
+			parsed, parseErr := types.ParseStreamID(args[2], 0)
+			if parseErr != nil {
+				return protocol.EncodeResp(parseErr, false)
+			}
+			id = &parsed
+		}
+
+		mkStream := false
+		entriesRead := int64(-1)
+		for i := 3; i < len(args); i++ {
+			switch option := strings.ToUpper(args[i]); {
+			case option == "MKSTREAM" && subcommand == "CREATE":
+				mkStream = true
+			case option == "ENTRIESREAD" && i+1 < len(args):
+				n, parseErr := strconv.ParseInt(args[i+1], 10, 64)
+				if parseErr != nil {
+					return protocol.RespValueNotIntegerOrOutOfRange
+				}
+				if n < -1 {
+					return protocol.RespStreamEntriesReadInvalid
+				}
+				entriesRead = n
+				i++
+			default:
+				return protocol.RespSyntaxError
+			}
+		}
+
+		if subcommand == "CREATE" {
+			err = redis.Store.XGroupCreate(key, group, id, mkStream, entriesRead)
+		} else {
+			err = redis.Store.XGroupSetID(key, group, id, entriesRead)
+		}
+		reply = protocol.RespOK
+
+	case "DESTROY":
+		var destroyed int64
+		destroyed, err = redis.Store.XGroupDestroy(key, group)
+		reply = protocol.EncodeResp(destroyed, false)
+
+	case "CREATECONSUMER":
+		var created int64
+		created, err = redis.Store.XGroupCreateConsumer(key, group, args[2])
+		reply = protocol.EncodeResp(created, false)
+
+	case "DELCONSUMER":
+		var pending int64
+		pending, err = redis.Store.XGroupDelConsumer(key, group, args[2])
+		reply = protocol.EncodeResp(pending, false)
+	}
+
+	switch err {
+	case nil:
+		return reply
+	case storage.ErrKeyNotFoundError:
+		return protocol.RespStreamKeyRequired
+	case storage.ErrNoGroupError:
+		return protocol.EncodeResp(errors.NoSuchGroupForKey(group, key), false)
+	default:
+		return protocol.EncodeResp(err, false)
+	}
+}
+
+/* Support XREADGROUP GROUP group consumer [COUNT count] [BLOCK milliseconds] [NOACK] STREAMS key [key ...] id [id ...] */
+func (redis *redis) XReadGroup(c *Client, cmd protocol.RedisCmd) []byte {
+	args, errResp := parseStreamReadArgs(cmd.Args, true)
+	if errResp != nil {
+		return errResp
+	}
+
+	// nil for `>`, the entries never delivered to the group
+	ids := make([]*types.StreamID, len(args.ids))
+	for i, arg := range args.ids {
+		switch arg {
+		case ">":
+		case "$":
+			return protocol.RespStreamDollarInGroup
+		default:
+			id, err := types.ParseStreamID(arg, 0)
+			if err != nil {
+				return protocol.EncodeResp(err, false)
+			}
+			ids[i] = &id
+		}
+	}
+
+	read := func() []byte {
+		// Nothing is read unless every group exists
+		for _, key := range args.keys {
+			if _, err := redis.Store.XPending(key, args.group, types.MaxStreamID, types.MinStreamID, 1, "", 0); err != nil {
+				return encodeNoGroupError(err, fmt.Errorf("%w in XREADGROUP with GROUP option",
+					errors.NoSuchKeyOrGroup(key, args.group)))
+			}
+		}
+
+		var keys []string
+		var entries [][]types.StreamEntry
+		for i, key := range args.keys {
+			read, err := redis.Store.XReadGroup(key, args.group, args.consumer, ids[i], args.count, args.noAck)
+			if err != nil {
+				return protocol.EncodeResp(err, false)
+			}
+
+			// Pending entries are reported for each key, even when there are none
+			if len(read) > 0 || ids[i] != nil {
+				keys = append(keys, key)
+				entries = append(entries, read)
+			}
+		}
+
+		if len(keys) == 0 {
+			return nil
+		}
+		return encodeStreamReads(keys, entries)
+	}
+
+	if reply := read(); reply != nil {
+		return reply
+	}
+
+	if !args.block {
+		return protocol.RespNilArray
+	}
+
+	// Only `>` gets here, reading pending entries always replies. A group destroyed
+	// meanwhile answers the blocked client with an error.
+	return redis.block(c, cmd, args.keys, args.timeout, protocol.RespNilArray, read)
+}
+
+/* Support XACK key group id [id ...] */
+func (redis *redis) XAck(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 3 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	ids, err := parseStreamIDs(args[2:])
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	acked, err := redis.Store.XAck(args[0], args[1], ids)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(acked, false)
+}
+
+/* Support XPENDING key group [[IDLE min-idle-time] start end count [consumer]] */
+func (redis *redis) XPending(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	key, group := args[0], args[1]
+	if len(args) == 2 {
+		return redis.xPendingSummary(key, group)
+	}
+
+	i := 2
+	var minIdle int64
+	if strings.EqualFold(args[i], "IDLE") && i+1 < len(args) {
+		idle, err := strconv.ParseInt(args[i+1], 10, 64)
+		if err != nil {
+			return protocol.RespValueNotIntegerOrOutOfRange
+		}
+		minIdle = idle
+		i += 2
+	}
+
+	if rest := len(args) - i; rest != 3 && rest != 4 {
+		return protocol.RespSyntaxError
+	}
+
+	start, errResp := parseRangeStreamID(args[i], false)
+	if errResp != nil {
+		return errResp
+	}
+
+	end, errResp := parseRangeStreamID(args[i+1], true)
+	if errResp != nil {
+		return errResp
+	}
+
+	count, err := strconv.ParseInt(args[i+2], 10, 64)
+	if err != nil {
+		return protocol.RespValueNotIntegerOrOutOfRange
+	}
+
+	consumer := ""
+	if i+3 < len(args) {
+		consumer = args[i+3]
+	}
+
+	pending, err := redis.Store.XPending(key, group, start, end, int(min(max(count, 1), int64(^uint32(0)>>1))), consumer, minIdle)
+	if err != nil {
+		return encodeNoGroupError(err, errors.NoSuchKeyOrGroup(key, group))
+	}
+
+	if count <= 0 {
+		return protocol.EncodeResp([]any{}, false)
+	}
+
+	now := time.Now().UnixMilli()
+	result := make([]any, len(pending))
+	for j, pe := range pending {
+		result[j] = []any{pe.ID.String(), pe.Consumer.Name, pe.Idle(now), pe.DeliveryCount}
+	}
+	return protocol.EncodeResp(result, false)
+}
+
+// xPendingSummary replies with the number of pending entries of a group, their smallest
+// and greatest IDs, and how many each consumer has.
+func (redis *redis) xPendingSummary(key, group string) []byte {
+	pending, err := redis.Store.XPending(key, group, types.MinStreamID, types.MaxStreamID, 0, "", 0)
+	if err != nil {
+		return encodeNoGroupError(err, errors.NoSuchKeyOrGroup(key, group))
+	}
+
+	if len(pending) == 0 {
+		return protocol.RespStreamEmptyPending
+	}
+
+	var names []string
+	counts := make(map[string]int)
+	for _, pe := range pending {
+		if counts[pe.Consumer.Name] == 0 {
+			names = append(names, pe.Consumer.Name)
+		}
+		counts[pe.Consumer.Name]++
+	}
+	slices.Sort(names)
+
+	consumers := make([]any, len(names))
+	for i, name := range names {
+		consumers[i] = []any{name, strconv.Itoa(counts[name])}
+	}
+
+	return protocol.EncodeResp([]any{
+		len(pending),
+		pending[0].ID.String(),
+		pending[len(pending)-1].ID.String(),
+		consumers,
+	}, false)
+}
+
+/*
+Support XCLAIM key group consumer min-idle-time id [id ...] [IDLE ms] [TIME unix-time-milliseconds]
+[RETRYCOUNT count] [FORCE] [JUSTID] [LASTID lastid]
+*/
+func (redis *redis) XClaim(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 5 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	ids, options, errResp := parseXClaimArgs(args, time.Now().UnixMilli())
+	if errResp != nil {
+		return errResp
+	}
+
+	claimed, err := redis.Store.XClaim(args[0], args[1], args[2], ids, options)
+	if err != nil {
+		return encodeNoGroupError(err, errors.NoSuchKeyOrGroup(args[0], args[1]))
+	}
+
+	if options.JustID {
+		return protocol.EncodeResp(streamEntryIDs(claimed), false)
+	}
+	return encodeStreamEntries(claimed)
+}
+
+// parseXClaimArgs parses the IDs and the options of XCLAIM. The IDs end at the first
+// argument that is not one, and the delivery time is resolved against nowMs.
+func parseXClaimArgs(args []string, nowMs int64) ([]types.StreamID, types.StreamClaimOptions, []byte) {
+	var options types.StreamClaimOptions
+
+	minIdle, err := strconv.ParseInt(args[3], 10, 64)
+	if err != nil {
+		return nil, options, protocol.EncodeResp(errors.InvalidMinIdleTime("XCLAIM"), false)
+	}
+	options.MinIdle = max(minIdle, 0)
+
+	i := 4
+	var ids []types.StreamID
+	for ; i < len(args); i++ {
+		id, err := types.ParseStreamID(args[i], 0)
+		if err != nil {
+			break
+		}
+		ids = append(ids, id)
+	}
+
+	if len(ids) == 0 {
+		return nil, options, protocol.EncodeResp(types.ErrInvalidStreamID, false)
+	}
+
+	deliveryTime := int64(-1)
+	for ; i < len(args); i++ {
+		option := strings.ToUpper(args[i])
+		switch {
+		case option == "FORCE":
+			options.Force = true
+
+		case option == "JUSTID":
+			options.JustID = true
+
+		case (option == "IDLE" || option == "TIME" || option == "RETRYCOUNT") && i+1 < len(args):
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || (option == "RETRYCOUNT" && n < 0) {
+				return nil, options, protocol.EncodeResp(errors.InvalidOptionArgument(option, "XCLAIM"), false)
+			}
+			switch option {
+			case "IDLE":
+				deliveryTime = nowMs - n
+			case "TIME":
+				deliveryTime = n
+			default:
+				options.RetryCount = &n
+			}
+			i++
+
+		case option == "LASTID" && i+1 < len(args):
+			lastID, err := types.ParseStreamID(args[i+1], 0)
+			if err != nil {
+				return nil, options, protocol.EncodeResp(err, false)
+			}
+			options.LastID = &lastID
+			i++
+
+		default:
+			return nil, options, protocol.EncodeResp(errors.UnrecognizedOption(args[i], "XCLAIM"), false)
+		}
+	}
+
+	// Delivery times in the future or before the epoch become the current time
+	if deliveryTime < 0 || deliveryTime > nowMs {
+		deliveryTime = nowMs
+	}
+	options.DeliveryTime = deliveryTime
+
+	return ids, options, nil
+}
+
+/* Support XAUTOCLAIM key group consumer min-idle-time start [COUNT count] [JUSTID] */
+func (redis *redis) XAutoClaim(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 5 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	var options types.StreamClaimOptions
+	minIdle, err := strconv.ParseInt(args[3], 10, 64)
+	if err != nil {
+		return protocol.EncodeResp(errors.InvalidMinIdleTime("XAUTOCLAIM"), false)
+	}
+	options.MinIdle = max(minIdle, 0)
+	options.DeliveryTime = time.Now().UnixMilli()
+
+	start, errResp := parseRangeStreamID(args[4], false)
+	if errResp != nil {
+		return errResp
+	}
+
+	count := int64(100)
+	for i := 5; i < len(args); i++ {
+		option := strings.ToUpper(args[i])
+		switch {
+		case option == "COUNT" && i+1 < len(args):
+			count, err = strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil {
+				return protocol.RespValueNotIntegerOrOutOfRange
+			}
+			if count < 1 || count > maxAutoClaimCount {
+				return protocol.RespStreamCountNotPositive
+			}
+			i++
+
+		case option == "JUSTID":
+			options.JustID = true
+
+		default:
+			return protocol.RespSyntaxError
+		}
+	}
+
+	next, claimed, deleted, err := redis.Store.XAutoClaim(args[0], args[1], args[2], start, int(count), options)
+	if err != nil {
+		return encodeNoGroupError(err, errors.NoSuchKeyOrGroup(args[0], args[1]))
+	}
+
+	reply := []byte("*3\r\n")
+	reply = append(reply, protocol.EncodeResp(next.String(), false)...)
+	if options.JustID {
+		reply = append(reply, protocol.EncodeResp(streamEntryIDs(claimed), false)...)
+	} else {
+		reply = append(reply, encodeStreamEntries(claimed)...)
+	}
+
+	deletedIDs := make([]string, len(deleted))
+	for i, id := range deleted {
+		deletedIDs[i] = id.String()
+	}
+	return append(reply, protocol.EncodeResp(deletedIDs, false)...)
+}
+
+/*
+Support
+
+	XINFO STREAM key [FULL [COUNT count]]
+	XINFO GROUPS key
+	XINFO CONSUMERS key group
+*/
+func (redis *redis) XInfo(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) == 0 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	subcommand := strings.ToUpper(cmd.Args[0])
+	args := cmd.Args[1:]
+
+	switch subcommand {
+	case "STREAM":
+		if len(args) < 1 {
+			return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd+"|"+subcommand), false)
+		}
+		return redis.xInfoStream(args)
+
+	case "GROUPS":
+		if len(args) != 1 {
+			return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd+"|"+subcommand), false)
+		}
+
+		groups, err := redis.Store.XInfoGroups(args[0])
+		if err != nil {
+			return protocol.EncodeResp(err, false)
+		}
+
+		result := make([]any, len(groups))
+		for i, g := range groups {
+			result[i] = []any{
+				"name", g.Name,
+				"consumers", g.ConsumerCount(),
+				"pending", g.PendingCount(),
+				"last-delivered-id", g.LastID.String(),
+				"entries-read", entriesReadReply(g.EntriesRead),
+				"lag", lagReply(g),
+			}
+		}
+		return protocol.EncodeResp(result, false)
+
+	case "CONSUMERS":
+		if len(args) != 2 {
+			return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd+"|"+subcommand), false)
+		}
+
+		consumers, err := redis.Store.XInfoConsumers(args[0], args[1])
+		if err == storage.ErrNoGroupError {
+			return protocol.EncodeResp(errors.NoSuchGroupForKey(args[1], args[0]), false)
+		}
+		if err != nil {
+			return protocol.EncodeResp(err, false)
+		}
+
+		now := time.Now().UnixMilli()
+		result := make([]any, len(consumers))
+		for i, c := range consumers {
+			inactive := int64(-1)
+			if c.ActiveTime != -1 {
+				inactive = max(now-c.ActiveTime, 0)
+			}
+			result[i] = []any{
+				"name", c.Name,
+				"pending", len(c.Pending()),
+				"idle", max(now-c.SeenTime, 0),
+				"inactive", inactive,
+			}
+		}
+		return protocol.EncodeResp(result, false)
+
+	default:
+		return protocol.EncodeResp(errors.UnknownSubcommand(cmd.Args[0], cmd.Cmd), false)
+	}
+}
+
+// xInfoStream describes a stream. FULL adds its entries and the state of its groups,
+// COUNT limits each list to that many elements, 10 by default and 0 for all of them.
+func (redis *redis) xInfoStream(args []string) []byte {
+	full := false
+	count := int64(10)
+	switch {
+	case len(args) == 1:
+	case len(args) == 2 && strings.EqualFold(args[1], "FULL"):
+		full = true
+	case len(args) == 4 && strings.EqualFold(args[1], "FULL") && strings.EqualFold(args[2], "COUNT"):
+		full = true
+		n, err := strconv.ParseInt(args[3], 10, 64)
+		if err != nil {
+			return protocol.RespValueNotIntegerOrOutOfRange
+		}
+		count = max(n, 0)
+	default:
+		return protocol.RespSyntaxError
+	}
+
+	info, err := redis.Store.XInfoStream(args[0])
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	// Nodes are kept in a sorted slice rather than a radix tree, both report the node count
+	result := []any{
+		"length", info.Length,
+		"radix-tree-keys", info.Nodes,
+		"radix-tree-nodes", info.Nodes,
+		"last-generated-id", info.LastID.String(),
+		"max-deleted-entry-id", info.MaxDeletedID.String(),
+		"entries-added", info.EntriesAdded,
+		"recorded-first-entry-id", info.FirstID.String(),
+	}
+
+	if !full {
+		result = append(result,
+			"groups", len(info.Groups),
+			"first-entry", streamEntryReply(info.FirstEntry),
+			"last-entry", streamEntryReply(info.LastEntry),
+		)
+		return protocol.EncodeResp(result, false)
+	}
+
+	limit := int(min(count, int64(^uint32(0)>>1)))
+	entries, err := redis.Store.XRange(args[0], types.MinStreamID, types.MaxStreamID, limit, false)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	entryReplies := make([]any, len(entries))
+	for i := range entries {
+		entryReplies[i] = streamEntryReply(&entries[i])
+	}
+
+	groups := make([]any, len(info.Groups))
+	for i, g := range info.Groups {
+		pending := g.Pending(types.MinStreamID, types.MaxStreamID, limit, nil, 0, 0)
+		pendingReplies := make([]any, len(pending))
+		for j, pe := range pending {
+			pendingReplies[j] = []any{pe.ID.String(), pe.Consumer.Name, pe.DeliveryTime, pe.DeliveryCount}
+		}
+
+		consumers := g.Consumers()
+		consumerReplies := make([]any, len(consumers))
+		for j, c := range consumers {
+			consumerPending := c.Pending()
+			if limit > 0 && len(consumerPending) > limit {
+				consumerPending = consumerPending[:limit]
+			}
+			consumerPendingReplies := make([]any, len(consumerPending))
+			for k, pe := range consumerPending {
+				consumerPendingReplies[k] = []any{pe.ID.String(), pe.DeliveryTime, pe.DeliveryCount}
+			}
+
+			consumerReplies[j] = []any{
+				"name", c.Name,
+				"seen-time", c.SeenTime,
+				"active-time", c.ActiveTime,
+				"pel-count", len(c.Pending()),
+				"pending", consumerPendingReplies,
+			}
+		}
+
+		groups[i] = []any{
+			"name", g.Name,
+			"last-delivered-id", g.LastID.String(),
+			"entries-read", entriesReadReply(g.EntriesRead),
+			"lag", lagReply(g),
+			"pel-count", g.PendingCount(),
+			"pending", pendingReplies,
+			"consumers", consumerReplies,
+		}
+	}
+
+	result = append(result, "entries", entryReplies, "groups", groups)
+	return protocol.EncodeResp(result, false)
+}
+
+// encodeNoGroupError encodes noGroup when the stream or the group is missing, other errors as they are.
+func encodeNoGroupError(err error, noGroup error) []byte {
+	if err == storage.ErrKeyNotFoundError || err == storage.ErrNoGroupError {
+		return protocol.EncodeResp(noGroup, false)
+	}
+	return protocol.EncodeResp(err, false)
+}
+
+func parseStreamIDs(args []string) ([]types.StreamID, error) {
+	ids := make([]types.StreamID, len(args))
+	for i, arg := range args {
+		id, err := types.ParseStreamID(arg, 0)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+func streamEntryIDs(entries []types.StreamEntry) []string {
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID.String()
+	}
+	return ids
+}
+
+func streamEntryReply(entry *types.StreamEntry) any {
+	if entry == nil {
+		return nil
+	}
+	return []any{entry.ID.String(), entry.Fields}
+}
+
+func entriesReadReply(entriesRead int64) any {
+	if entriesRead == -1 {
+		return nil
+	}
+	return entriesRead
+}
+
+func lagReply(g types.StreamGroupInfo) any {
+	if !g.LagKnown {
+		return nil
+	}
+	return g.Lag
+}
@@ -1,10 +1,14 @@
 package command
 
 import (
+	"math"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/manhhung2111/go-redis/internal/protocol"
+	"github.com/manhhung2111/go-redis/internal/storage"
+	"github.com/manhhung2111/go-redis/internal/storage/types"
 	"github.com/manhhung2111/go-redis/internal/errors"
 )
 
@@ -23,84 +27,168 @@ func (redis *redis) Get(cmd protocol.RedisCmd) []byte {
 	return protocol.EncodeResp(value, false)
 }
 
-/* Supports `SET key value [NX | XX] [EX seconds]` */
+/* Supports `SET key value [NX | XX] [GET] [EX seconds | PX milliseconds | EXAT unix-time-seconds | PXAT unix-time-milliseconds | KEEPTTL]` */
 func (redis *redis) Set(cmd protocol.RedisCmd) []byte {
-	argsLen := len(cmd.Args)
-	if argsLen < 2 {
+	if len(cmd.Args) < 2 {
 		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
 	}
 
+	key, value := cmd.Args[0], cmd.Args[1]
+	opts, errReply := parseSetArgs(cmd)
+	if errReply != nil {
+		return errReply
+	}
+
+	var old *string
+	if opts.get {
+		current, err := redis.Store.Get(key)
+		if err != nil {
+			return protocol.EncodeResp(err, false)
+		}
+		old = current
+	}
+
+	exists := redis.Store.Exists(key)
+	if (opts.nx && exists) || (opts.xx && !exists) {
+		return protocol.EncodeResp(old, false)
+	}
+
+	switch {
+	case opts.keepTTL:
+		redis.Store.SetKeepTTL(key, value)
+	case opts.expireAtMs != 0:
+		redis.Store.SetExAt(key, value, opts.expireAtMs)
+	default:
+		redis.Store.Set(key, value)
+	}
+
+	if opts.get {
+		return protocol.EncodeResp(old, false)
+	}
+
+	return protocol.RespOK
+}
+
+/* Supports `GETEX key [EX seconds | PX milliseconds | EXAT unix-time-seconds | PXAT unix-time-milliseconds | PERSIST]` */
+func (redis *redis) GetEx(cmd protocol.RedisCmd) []byte {
 	args := cmd.Args
-	key, value := args[0], args[1]
+	if len(args) < 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
 
 	var (
-		nx        bool // SET if Not eXists
-		xx        bool // SET if eXists
-		expireSec uint64
+		persist    bool
+		expireAtMs int64
 	)
 
-	for i := 2; i < argsLen; i++ {
+	for i := 1; i < len(args); i++ {
 		opt := strings.ToUpper(args[i])
 		switch opt {
-		case "NX":
-			nx = true
-		case "XX":
-			xx = true
-		case "EX":
-			if i+1 >= argsLen {
+		case "EX", "PX", "EXAT", "PXAT":
+			if i+1 >= len(args) {
 				return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
 			}
-
-			ttl, err := strconv.ParseUint(args[i+1], 10, 64)
-			if err != nil || ttl <= 0 {
-				return protocol.EncodeResp(errors.InvalidExpireTime(cmd.Cmd), false)
+			if persist || expireAtMs != 0 {
+				return protocol.RespSyntaxError
 			}
 
-			expireSec = ttl
+			var errReply []byte
+			expireAtMs, errReply = parseExpireTime(cmd, opt, args[i+1])
+			if errReply != nil {
+				return errReply
+			}
 			i++
+		case "PERSIST":
+			if persist || expireAtMs != 0 {
+				return protocol.RespSyntaxError
+			}
+			persist = true
 		default:
 			return protocol.EncodeResp(errors.InvalidCommandOption(opt, cmd.Cmd), false)
 		}
 	}
 
-	if nx && xx {
-		return protocol.EncodeResp(errors.InvalidCommandOption("NX|XX", cmd.Cmd), false)
+	value, err := redis.Store.Get(args[0])
+	if err != nil {
+		return protocol.EncodeResp(err, false)
 	}
 
-	exists := redis.Store.Exists(key)
+	if value != nil {
+		if persist {
+			redis.Store.Persist(args[0])
+		} else if expireAtMs != 0 {
+			redis.Store.ExpireAt(args[0], expireAtMs, storage.ExpireOptions{})
+		}
+	}
+
+	return protocol.EncodeResp(value, false)
+}
 
-	if nx && exists {
-		return protocol.RespNilBulkString
+/* Supports `GETDEL key` */
+func (redis *redis) GetDel(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
 	}
 
-	if xx && !exists {
-		return protocol.RespNilBulkString
+	value, err := redis.Store.GetDel(cmd.Args[0])
+	if err != nil {
+		return protocol.EncodeResp(err, false)
 	}
 
-	if expireSec > 0 {
-		redis.Store.SetEx(key, value, expireSec)
-	} else {
-		redis.Store.Set(key, value)
+	return protocol.EncodeResp(value, false)
+}
+
+/* Supports `GETSET key value` */
+func (redis *redis) GetSet(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
 	}
 
-	return protocol.RespOK
+	old, err := redis.Store.Get(cmd.Args[0])
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	redis.Store.Set(cmd.Args[0], cmd.Args[1])
+	return protocol.EncodeResp(old, false)
 }
 
-/* Supports `DEL key [key...]` */
-func (redis *redis) Del(cmd protocol.RedisCmd) []byte {
-	argsLen := len(cmd.Args)
-	if argsLen < 1 {
+/* Supports `SETNX key value` */
+func (redis *redis) SetNX(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 2 {
 		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
 	}
 
-	var deletedKeys int64 = 0
-	for i := range argsLen {
-		if isDeleted := redis.Store.Del(cmd.Args[i]); isDeleted {
-			deletedKeys++
-		}
+	if redis.Store.Exists(cmd.Args[0]) {
+		return protocol.EncodeResp(int64(0), false)
 	}
 
-	return protocol.EncodeResp(deletedKeys, false)
+	redis.Store.Set(cmd.Args[0], cmd.Args[1])
+	return protocol.EncodeResp(int64(1), false)
+}
+
+/* Supports `SETEX key seconds value` */
+func (redis *redis) SetEx(cmd protocol.RedisCmd) []byte {
+	return redis.setWithExpire(cmd, "EX")
+}
+
+/* Supports `PSETEX key milliseconds value` */
+func (redis *redis) PSetEx(cmd protocol.RedisCmd) []byte {
+	return redis.setWithExpire(cmd, "PX")
+}
+
+func (redis *redis) setWithExpire(cmd protocol.RedisCmd, unit string) []byte {
+	if len(cmd.Args) != 3 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	expireAtMs, errReply := parseExpireTime(cmd, unit, cmd.Args[1])
+	if errReply != nil {
+		return errReply
+	}
+
+	redis.Store.SetExAt(cmd.Args[0], cmd.Args[2], expireAtMs)
+	return protocol.RespOK
 }
 
 /* Support MGET key [key ...] */
@@ -134,6 +222,27 @@ func (redis *redis) MSet(cmd protocol.RedisCmd) []byte {
 	return protocol.RespOK
 }
 
+/* Support MSETNX key value [key value ...] */
+func (redis *redis) MSetNX(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) == 0 || len(args)&1 == 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	// Nothing is set as soon as one of the keys exists
+	for i := 0; i < len(args); i += 2 {
+		if redis.Store.Exists(args[i]) {
+			return protocol.EncodeResp(int64(0), false)
+		}
+	}
+
+	for i := 0; i < len(args); i += 2 {
+		redis.Store.Set(args[i], args[i+1])
+	}
+
+	return protocol.EncodeResp(int64(1), false)
+}
+
 /* Support INCR key */
 func (redis *redis) Incr(cmd protocol.RedisCmd) []byte {
 	args := cmd.Args
@@ -203,3 +312,240 @@ func (redis *redis) DecrBy(cmd protocol.RedisCmd) []byte {
 
 	return protocol.EncodeResp(result, false)
 }
+
+/* Support INCRBYFLOAT key increment */
+func (redis *redis) IncrByFloat(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) != 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	increment, ok := types.ParseLongDouble(args[1])
+	if !ok {
+		return protocol.RespValueNotValidFloat
+	}
+
+	result, err := redis.Store.IncrByFloat(args[0], increment)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
+/* Support APPEND key value */
+func (redis *redis) Append(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) != 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	length, err := redis.Store.Append(args[0], args[1])
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(length, false)
+}
+
+/* Support STRLEN key */
+func (redis *redis) StrLen(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) != 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	length, err := redis.Store.StrLen(args[0])
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(length, false)
+}
+
+/* Support GETRANGE key start end */
+func (redis *redis) GetRange(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) != 3 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	start, err1 := strconv.ParseInt(args[1], 10, 64)
+	end, err2 := strconv.ParseInt(args[2], 10, 64)
+	if err1 != nil || err2 != nil {
+		return protocol.RespValueNotIntegerOrOutOfRange
+	}
+
+	result, err := redis.Store.GetRange(args[0], start, end)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
+/* Support SETRANGE key offset value */
+func (redis *redis) SetRange(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) != 3 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	offset, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return protocol.RespValueNotIntegerOrOutOfRange
+	}
+	if offset < 0 {
+		return protocol.RespOffsetOutOfRange
+	}
+
+	length, err := redis.Store.SetRange(args[0], offset, args[2])
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(length, false)
+}
+
+/* Support LCS key1 key2 [LEN] [IDX] [MINMATCHLEN min-match-len] [WITHMATCHLEN] */
+func (redis *redis) LCS(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	var (
+		getLen       bool
+		getIdx       bool
+		withMatchLen bool
+		minMatchLen  int64
+	)
+
+	for i := 2; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+		switch opt {
+		case "LEN":
+			getLen = true
+		case "IDX":
+			getIdx = true
+		case "WITHMATCHLEN":
+			withMatchLen = true
+		case "MINMATCHLEN":
+			if i+1 >= len(args) {
+				return protocol.RespSyntaxError
+			}
+			value, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil {
+				return protocol.RespValueNotIntegerOrOutOfRange
+			}
+			minMatchLen = max(value, 0)
+			i++
+		default:
+			return protocol.RespSyntaxError
+		}
+	}
+
+	if getLen && getIdx {
+		return protocol.RespLCSLenAndIdx
+	}
+
+	lcs, matches, err := redis.Store.LCS(args[0], args[1])
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	if getLen {
+		return protocol.EncodeResp(len(lcs), false)
+	}
+
+	if !getIdx {
+		return protocol.EncodeResp(lcs, false)
+	}
+
+	reply := make([]any, 0, len(matches))
+	for _, m := range matches {
+		if int64(m.Len()) < minMatchLen {
+			continue
+		}
+		match := []any{[]any{m.AStart, m.AEnd}, []any{m.BStart, m.BEnd}}
+		if withMatchLen {
+			match = append(match, m.Len())
+		}
+		reply = append(reply, match)
+	}
+
+	return protocol.EncodeResp([]any{"matches", reply, "len", len(lcs)}, false)
+}
+
+type setOptions struct {
+	nx         bool  // SET if Not eXists
+	xx         bool  // SET if eXists
+	get        bool  // reply with the old value
+	keepTTL    bool  // keep the expiration time of the old value
+	expireAtMs int64 // absolute expiration time, 0 for none
+}
+
+// parseSetArgs parses the options of SET following the key and the value.
+// On failure the error reply is returned instead.
+func parseSetArgs(cmd protocol.RedisCmd) (setOptions, []byte) {
+	var opts setOptions
+	args := cmd.Args
+
+	for i := 2; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+		switch opt {
+		case "NX":
+			opts.nx = true
+		case "XX":
+			opts.xx = true
+		case "GET":
+			opts.get = true
+		case "KEEPTTL":
+			if opts.expireAtMs != 0 {
+				return opts, protocol.RespSyntaxError
+			}
+			opts.keepTTL = true
+		case "EX", "PX", "EXAT", "PXAT":
+			if i+1 >= len(args) {
+				return opts, protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+			}
+			if opts.keepTTL || opts.expireAtMs != 0 {
+				return opts, protocol.RespSyntaxError
+			}
+
+			var errReply []byte
+			opts.expireAtMs, errReply = parseExpireTime(cmd, opt, args[i+1])
+			if errReply != nil {
+				return opts, errReply
+			}
+			i++
+		default:
+			return opts, protocol.EncodeResp(errors.InvalidCommandOption(opt, cmd.Cmd), false)
+		}
+	}
+
+	if opts.nx && opts.xx {
+		return opts, protocol.EncodeResp(errors.InvalidCommandOption("NX|XX", cmd.Cmd), false)
+	}
+
+	return opts, nil
+}
+
+// parseExpireTime converts the value of an EX, PX, EXAT or PXAT option to an absolute
+// expiration time in unix milliseconds. The value must be positive.
+func parseExpireTime(cmd protocol.RedisCmd, opt string, arg string) (int64, []byte) {
+	var unitMs, baseMs int64 = 1, 0
+	if opt == "EX" || opt == "EXAT" {
+		unitMs = 1000
+	}
+	if opt == "EX" || opt == "PX" {
+		baseMs = time.Now().UnixMilli()
+	}
+
+	value, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || value <= 0 || value > (math.MaxInt64-baseMs)/unitMs {
+		return 0, protocol.EncodeResp(errors.InvalidExpireTime(cmd.Cmd), false)
+	}
+
+	return baseMs + value*unitMs, nil
+}
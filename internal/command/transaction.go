@@ -0,0 +1,143 @@
+package command
+
+import (
+	"strconv"
+
+	"github.com/manhhung2111/go-redis/internal/errors"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+)
+
+/* Supports `MULTI` */
+func (redis *redis) Multi(c *Client, cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 0 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	if c.multi {
+		return protocol.RespMultiNested
+	}
+
+	c.multi = true
+	return protocol.RespOK
+}
+
+/* Supports `EXEC` */
+func (redis *redis) Exec(c *Client, cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 0 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	if !c.multi {
+		return protocol.RespExecWithoutMulti
+	}
+
+	queue, queueFailed := c.queue, c.queueFailed
+	watchedChanged := redis.watchedKeysChanged(c)
+	redis.resetTransaction(c)
+
+	if queueFailed {
+		return protocol.RespExecAbort
+	}
+
+	if watchedChanged {
+		return protocol.RespNilArray
+	}
+
+	// Writes of the transaction are logged between MULTI and EXEC, so that loading
+	// the append-only file never applies only part of them
+	redis.inExec = true
+	defer func() {
+		if redis.execLogged {
+			redis.aof.Feed("EXEC")
+		}
+		redis.inExec = false
+		redis.execLogged = false
+	}()
+
+	reply := []byte("*" + strconv.Itoa(len(queue)) + "\r\n")
+	for _, queued := range queue {
+		reply = append(reply, redis.call(c, redis.handlers[queued.Cmd], queued)...)
+	}
+	return reply
+}
+
+/* Supports `DISCARD` */
+func (redis *redis) Discard(c *Client, cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 0 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	if !c.multi {
+		return protocol.RespDiscardWithoutMulti
+	}
+
+	redis.resetTransaction(c)
+	return protocol.RespOK
+}
+
+/* Supports `WATCH key [key ...]` */
+func (redis *redis) Watch(c *Client, cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) == 0 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	if c.multi {
+		return protocol.RespWatchInsideMulti
+	}
+
+	if c.watched == nil {
+		c.watched = make(map[dbKey]uint64, len(cmd.Args))
+	}
+
+	for _, key := range cmd.Args {
+		if _, ok := c.watched[dbKey{c.db, key}]; !ok {
+			c.watched[dbKey{c.db, key}] = redis.Store.Watch(key)
+		}
+	}
+
+	return protocol.RespOK
+}
+
+/* Supports `UNWATCH` */
+func (redis *redis) Unwatch(c *Client, cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 0 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	redis.unwatchAll(c)
+	return protocol.RespOK
+}
+
+// FreeClient releases what a closed connection still holds, such as its watched keys
+// or the keys it is blocked on.
+func (redis *redis) FreeClient(c *Client) {
+	redis.resetTransaction(c)
+	if c.blocked != nil {
+		redis.unblock(c, nil)
+	}
+}
+
+// resetTransaction drops the queued commands and the watched keys of c.
+func (redis *redis) resetTransaction(c *Client) {
+	c.multi = false
+	c.queue = nil
+	c.queueFailed = false
+	redis.unwatchAll(c)
+}
+
+func (redis *redis) unwatchAll(c *Client) {
+	for k := range c.watched {
+		redis.databases.DB(k.db).Unwatch(k.key)
+	}
+	c.watched = nil
+}
+
+// watchedKeysChanged reports whether a key watched by c was modified since WATCH.
+func (redis *redis) watchedKeysChanged(c *Client) bool {
+	for k, version := range c.watched {
+		if redis.databases.DB(k.db).WatchedVersion(k.key) != version {
+			return true
+		}
+	}
+	return false
+}
@@ -1,20 +1,16 @@
 package command
 
 import (
-	"errors"
+	"math"
 	"strconv"
 	"strings"
 
 	rerr "github.com/manhhung2111/go-redis/internal/errors"
 	"github.com/manhhung2111/go-redis/internal/protocol"
+	"github.com/manhhung2111/go-redis/internal/storage"
 	"github.com/manhhung2111/go-redis/internal/storage/types"
 )
 
-const (
-	minLexString = ""
-	maxLexString = "~"
-)
-
 /* Support ZADD key [NX | XX] [GT | LT] [CH] score member [score member...] */
 func (redis *redis) ZAdd(cmd protocol.RedisCmd) []byte {
 	args := cmd.Args
@@ -108,17 +104,12 @@ func (redis *redis) ZCount(cmd protocol.RedisCmd) []byte {
 		return protocol.EncodeResp(rerr.InvalidNumberOfArgs(cmd.Cmd), false)
 	}
 
-	minValue, err := strconv.ParseFloat(args[1], 64)
-	if err != nil {
-		return protocol.RespValueNotValidFloat
-	}
-
-	maxValue, err := strconv.ParseFloat(args[2], 64)
-	if err != nil {
+	scoreRange, ok := parseScoreRange(args[1], args[2])
+	if !ok {
 		return protocol.RespValueNotValidFloat
 	}
 
-	result, err := redis.Store.ZCount(args[0], minValue, maxValue)
+	result, err := redis.Store.ZCount(args[0], scoreRange)
 	if err != nil {
 		return protocol.EncodeResp(err, false)
 	}
@@ -153,17 +144,12 @@ func (redis *redis) ZLexCount(cmd protocol.RedisCmd) []byte {
 		return protocol.EncodeResp(rerr.InvalidNumberOfArgs(cmd.Cmd), false)
 	}
 
-	minValue, err := getLexString(args[1])
-	if err != nil {
-		return protocol.EncodeResp(err, false)
-	}
-
-	maxValue, err := getLexString(args[2])
-	if err != nil {
-		return protocol.EncodeResp(err, false)
+	lexRange, ok := parseLexRange(args[1], args[2])
+	if !ok {
+		return protocol.RespMinOrMaxNotValidStringRange
 	}
 
-	result, err := redis.Store.ZLexCount(args[0], minValue, maxValue)
+	result, err := redis.Store.ZLexCount(args[0], lexRange)
 	if err != nil {
 		return protocol.EncodeResp(err, false)
 	}
@@ -234,6 +220,116 @@ func (redis *redis) ZPopMin(cmd protocol.RedisCmd) []byte {
 	return protocol.EncodeResp(result, false)
 }
 
+/* Support ZMPOP numkeys key [key ...] <MIN | MAX> [COUNT count] */
+func (redis *redis) ZMPop(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 3 {
+		return protocol.EncodeResp(rerr.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	keys, rest, errReply := parseNumKeys(args)
+	if errReply != nil {
+		return errReply
+	}
+	if len(rest) == 0 {
+		return protocol.RespNumKeysExceedArgs
+	}
+
+	var fromMin bool
+	switch strings.ToUpper(rest[0]) {
+	case "MIN":
+		fromMin = true
+	case "MAX":
+	default:
+		return protocol.RespSyntaxError
+	}
+
+	count := 1
+	switch len(rest) {
+	case 1:
+	case 3:
+		if !strings.EqualFold(rest[1], "COUNT") {
+			return protocol.RespSyntaxError
+		}
+		value, err := strconv.ParseInt(rest[2], 10, 64)
+		if err != nil || value <= 0 {
+			return protocol.RespCountNotPositive
+		}
+		count = int(min(value, math.MaxInt32))
+	default:
+		return protocol.RespSyntaxError
+	}
+
+	key, popped, err := redis.Store.ZMPop(keys, fromMin, count)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	if popped == nil {
+		return protocol.RespNilArray
+	}
+
+	pairs := make([]any, 0, len(popped)/2)
+	for i := 0; i+1 < len(popped); i += 2 {
+		pairs = append(pairs, popped[i:i+2])
+	}
+	return protocol.EncodeResp([]any{key, pairs}, false)
+}
+
+/* Support BZPOPMAX key [key ...] timeout */
+func (redis *redis) BZPopMax(c *Client, cmd protocol.RedisCmd) []byte {
+	return redis.blockingZSetPop(c, cmd, redis.Store.ZPopMax)
+}
+
+/* Support BZPOPMIN key [key ...] timeout */
+func (redis *redis) BZPopMin(c *Client, cmd protocol.RedisCmd) []byte {
+	return redis.blockingZSetPop(c, cmd, redis.Store.ZPopMin)
+}
+
+// blockingZSetPop pops a member of the first non-empty sorted set among the keys, or blocks
+// until one of them gets a member. Once blocked, keys holding another type are skipped.
+func (redis *redis) blockingZSetPop(c *Client, cmd protocol.RedisCmd,
+	pop func(key string, count int) ([]string, error)) []byte {
+	args := cmd.Args
+	if len(args) < 2 {
+		return protocol.EncodeResp(rerr.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	timeout, errResp := parseBlockingTimeout(args[len(args)-1])
+	if errResp != nil {
+		return errResp
+	}
+
+	keys := args[:len(args)-1]
+	tryPop := func(blocked bool) []byte {
+		for _, key := range keys {
+			card, err := redis.Store.ZCard(key)
+			if err == nil && card == 0 {
+				continue
+			}
+
+			var popped []string
+			if err == nil {
+				popped, err = pop(key, 1)
+			}
+			if err != nil {
+				if blocked {
+					continue
+				}
+				return protocol.EncodeResp(err, false)
+			}
+
+			return protocol.EncodeResp([]string{key, popped[0], popped[1]}, false)
+		}
+		return nil
+	}
+
+	if reply := tryPop(false); reply != nil {
+		return reply
+	}
+	return redis.block(c, cmd, keys, timeout, protocol.RespNilArray, func() []byte { return tryPop(true) })
+}
+
 /* Support ZRANDMEMBER key [count [WITHSCORES]] */
 func (redis *redis) ZRandMember(cmd protocol.RedisCmd) []byte {
 	args := cmd.Args
@@ -273,121 +369,161 @@ func (redis *redis) ZRandMember(cmd protocol.RedisCmd) []byte {
 	return protocol.EncodeResp(result, false)
 }
 
-/* Support ZRANGE key start stop [BYSCORE | BYLEX] [REV] [WITHSCORES] */
+/* Support ZRANGE key start stop [BYSCORE | BYLEX] [REV] [LIMIT offset count] [WITHSCORES] */
 func (redis *redis) ZRange(cmd protocol.RedisCmd) []byte {
+	return redis.zrange(cmd, zrangeOptions{})
+}
+
+/* Support ZRANGEBYSCORE key min max [WITHSCORES] [LIMIT offset count] */
+func (redis *redis) ZRangeByScore(cmd protocol.RedisCmd) []byte {
+	return redis.zrange(cmd, zrangeOptions{legacy: true, by: storage.ZRangeScore})
+}
+
+/* Support ZREVRANGEBYSCORE key max min [WITHSCORES] [LIMIT offset count] */
+func (redis *redis) ZRevRangeByScore(cmd protocol.RedisCmd) []byte {
+	return redis.zrange(cmd, zrangeOptions{legacy: true, by: storage.ZRangeScore, rev: true})
+}
+
+/* Support ZREVRANGE key start stop [WITHSCORES] */
+func (redis *redis) ZRevRange(cmd protocol.RedisCmd) []byte {
+	return redis.zrange(cmd, zrangeOptions{legacy: true, by: storage.ZRangeRank, rev: true})
+}
+
+func (redis *redis) zrange(cmd protocol.RedisCmd, options zrangeOptions) []byte {
 	args := cmd.Args
-	if len(args) < 3 || len(args) > 6 {
+	if len(args) < 3 {
 		return protocol.EncodeResp(rerr.InvalidNumberOfArgs(cmd.Cmd), false)
 	}
 
+	spec, withScores, errReply := parseZRangeSpec(args[1:], options)
+	if errReply != nil {
+		return errReply
+	}
+
+	result, err := redis.Store.ZRange(args[0], spec, withScores)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
+/* Support ZRANGESTORE dst src min max [BYSCORE | BYLEX] [REV] [LIMIT offset count] */
+func (redis *redis) ZRangeStore(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 4 {
+		return protocol.EncodeResp(rerr.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	spec, _, errReply := parseZRangeSpec(args[2:], zrangeOptions{store: true})
+	if errReply != nil {
+		return errReply
+	}
+
+	size, err := redis.Store.ZRangeStore(args[0], args[1], spec)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(size, false)
+}
+
+// zrangeOptions tells which command of the ZRANGE family parseZRangeSpec parses. The legacy
+// commands select their order and direction by name instead of options.
+type zrangeOptions struct {
+	legacy bool
+	by     storage.ZRangeBy
+	rev    bool
+	store  bool // no WITHSCORES
+}
+
+// parseZRangeSpec parses the `start stop [options]` arguments of the ZRANGE family, and
+// returns the selected range with whether WITHSCORES was given.
+func parseZRangeSpec(args []string, options zrangeOptions) (storage.ZRangeSpec, bool, []byte) {
+	spec := storage.ZRangeSpec{By: options.by, Rev: options.rev, Count: -1}
 	var (
 		byScore    bool
 		byLex      bool
-		rev        bool
+		limit      bool
 		withScores bool
 	)
 
-	for i := 3; i < len(args); i++ {
-		option := strings.ToUpper(args[i])
-		switch option {
-		case "BYSCORE":
+	for i := 2; i < len(args); i++ {
+		switch option := strings.ToUpper(args[i]); {
+		case option == "BYSCORE" && !options.legacy:
 			byScore = true
-		case "BYLEX":
+			spec.By = storage.ZRangeScore
+		case option == "BYLEX" && !options.legacy:
 			byLex = true
-		case "REV":
-			rev = true
-		case "WITHSCORES":
+			spec.By = storage.ZRangeLex
+		case option == "REV" && !options.legacy:
+			spec.Rev = true
+		case option == "WITHSCORES" && !options.store:
 			withScores = true
+		case option == "LIMIT" && i+2 < len(args) && !(options.legacy && options.by == storage.ZRangeRank):
+			offset, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil {
+				return spec, false, protocol.RespValueNotIntegerOrOutOfRange
+			}
+
+			count, err := strconv.ParseInt(args[i+2], 10, 64)
+			if err != nil {
+				return spec, false, protocol.RespValueNotIntegerOrOutOfRange
+			}
+
+			limit = true
+			spec.Offset, spec.Count = int(offset), int(count)
+			i += 2
 		default:
-			return protocol.RespSyntaxError
+			return spec, false, protocol.RespSyntaxError
 		}
 	}
 
 	if byScore && byLex {
-		return protocol.RespSyntaxError
+		return spec, false, protocol.RespSyntaxError
 	}
 
-	if byLex && withScores {
-		return protocol.RespWithScoresNotSupportedByLex
+	if limit && spec.By == storage.ZRangeRank {
+		return spec, false, protocol.RespLimitNotSupportedByRank
 	}
 
-	if !byScore && !byLex {
-		start, err := strconv.ParseInt(args[1], 10, 64)
-		if err != nil {
-			return protocol.RespValueNotIntegerOrOutOfRange
-		}
-
-		stop, err := strconv.ParseInt(args[2], 10, 64)
-		if err != nil {
-			return protocol.RespValueNotIntegerOrOutOfRange
-		}
-
-		result := []string{}
-		if rev {
-			result, err = redis.Store.ZRevRangeByRank(args[0], int(start), int(stop), withScores)
-		} else {
-			result, err = redis.Store.ZRangeByRank(args[0], int(start), int(stop), withScores)
-		}
-
-		if err != nil {
-			return protocol.EncodeResp(err, false)
-		}
-
-		return protocol.EncodeResp(result, false)
+	// The reversed ranges by score and lex take their maximum first
+	lower, upper := args[0], args[1]
+	if spec.Rev && spec.By != storage.ZRangeRank {
+		lower, upper = upper, lower
 	}
 
-	if byScore {
-		start, err := strconv.ParseFloat(args[1], 64)
-		if err != nil {
-			return protocol.RespMinOrMaxNotFloat
-		}
-
-		stop, err := strconv.ParseFloat(args[2], 64)
-		if err != nil {
-			return protocol.RespMinOrMaxNotFloat
+	switch spec.By {
+	case storage.ZRangeScore:
+		scoreRange, ok := parseScoreRange(lower, upper)
+		if !ok {
+			return spec, false, protocol.RespMinOrMaxNotFloat
 		}
-
-		result := []string{}
-		if rev {
-			result, err = redis.Store.ZRevRangeByScore(args[0], start, stop, withScores)
-		} else {
-			result, err = redis.Store.ZRangeByScore(args[0], start, stop, withScores)
+		spec.Score = scoreRange
+	case storage.ZRangeLex:
+		if withScores {
+			return spec, false, protocol.RespWithScoresNotSupportedByLex
 		}
 
-		if err != nil {
-			return protocol.EncodeResp(err, false)
+		lexRange, ok := parseLexRange(lower, upper)
+		if !ok {
+			return spec, false, protocol.RespMinOrMaxNotValidStringRange
 		}
-
-		return protocol.EncodeResp(result, false)
-	}
-
-	if byLex {
-		start, err := getLexString(args[1])
+		spec.Lex = lexRange
+	default:
+		start, err := strconv.ParseInt(lower, 10, 64)
 		if err != nil {
-			return protocol.EncodeResp(err, false)
+			return spec, false, protocol.RespValueNotIntegerOrOutOfRange
 		}
 
-		stop, err := getLexString(args[2])
+		stop, err := strconv.ParseInt(upper, 10, 64)
 		if err != nil {
-			return protocol.EncodeResp(err, false)
+			return spec, false, protocol.RespValueNotIntegerOrOutOfRange
 		}
-
-		result := []string{}
-		if rev {
-			result, err = redis.Store.ZRevRangeByLex(args[0], start, stop)
-		} else {
-			result, err = redis.Store.ZRangeByLex(args[0], start, stop)
-		}
-
-		if err != nil {
-			return protocol.EncodeResp(err, false)
-		}
-
-		return protocol.EncodeResp(result, false)
+		spec.Start, spec.Stop = int(start), int(stop)
 	}
 
-	// This line should never be reached
-	return protocol.RespSyntaxError
+	return spec, withScores, nil
 }
 
 /* Support ZRANK key member [WITHSCORE] */
@@ -435,6 +571,71 @@ func (redis *redis) ZRem(cmd protocol.RedisCmd) []byte {
 	return protocol.EncodeResp(result, false)
 }
 
+/* Support ZREMRANGEBYRANK key start stop */
+func (redis *redis) ZRemRangeByRank(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) != 3 {
+		return protocol.EncodeResp(rerr.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	start, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return protocol.RespValueNotIntegerOrOutOfRange
+	}
+
+	stop, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		return protocol.RespValueNotIntegerOrOutOfRange
+	}
+
+	result, err := redis.Store.ZRemRangeByRank(args[0], int(start), int(stop))
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
+/* Support ZREMRANGEBYSCORE key min max */
+func (redis *redis) ZRemRangeByScore(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) != 3 {
+		return protocol.EncodeResp(rerr.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	scoreRange, ok := parseScoreRange(args[1], args[2])
+	if !ok {
+		return protocol.RespMinOrMaxNotFloat
+	}
+
+	result, err := redis.Store.ZRemRangeByScore(args[0], scoreRange)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
+/* Support ZREMRANGEBYLEX key min max */
+func (redis *redis) ZRemRangeByLex(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) != 3 {
+		return protocol.EncodeResp(rerr.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	lexRange, ok := parseLexRange(args[1], args[2])
+	if !ok {
+		return protocol.RespMinOrMaxNotValidStringRange
+	}
+
+	result, err := redis.Store.ZRemRangeByLex(args[0], lexRange)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
 /* Support ZREVRANK key member [WITHSCORE] */
 func (redis *redis) ZRevRank(cmd protocol.RedisCmd) []byte {
 	args := cmd.Args
@@ -480,19 +681,236 @@ func (redis *redis) ZScore(cmd protocol.RedisCmd) []byte {
 	return protocol.EncodeResp(result, false)
 }
 
-func getLexString(str string) (string, error) {
-	if str == "-" {
-		return minLexString, nil
+/* Support ZSCAN key cursor [MATCH pattern] [COUNT count] */
+func (redis *redis) ZScan(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) < 2 {
+		return protocol.EncodeResp(rerr.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	args, errResp := parseScanArgs(cmd.Args[1:], false, false)
+	if errResp != nil {
+		return errResp
+	}
+
+	next, members, err := redis.Store.ZScan(cmd.Args[0], args.cursor, args.pattern, args.count)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return encodeScanReply(next, members)
+}
+
+/* Support ZUNION numkeys key [key ...] [WEIGHTS weight [weight ...]] [AGGREGATE <SUM | MIN | MAX>] [WITHSCORES] */
+func (redis *redis) ZUnion(cmd protocol.RedisCmd) []byte {
+	return redis.zsetAlgebra(cmd, true, func(a zsetAlgebraArgs) ([]string, error) {
+		return redis.Store.ZUnion(a.keys, a.weights, a.aggregate, a.withScores)
+	})
+}
+
+/* Support ZINTER numkeys key [key ...] [WEIGHTS weight [weight ...]] [AGGREGATE <SUM | MIN | MAX>] [WITHSCORES] */
+func (redis *redis) ZInter(cmd protocol.RedisCmd) []byte {
+	return redis.zsetAlgebra(cmd, true, func(a zsetAlgebraArgs) ([]string, error) {
+		return redis.Store.ZInter(a.keys, a.weights, a.aggregate, a.withScores)
+	})
+}
+
+/* Support ZDIFF numkeys key [key ...] [WITHSCORES] */
+func (redis *redis) ZDiff(cmd protocol.RedisCmd) []byte {
+	return redis.zsetAlgebra(cmd, false, func(a zsetAlgebraArgs) ([]string, error) {
+		return redis.Store.ZDiff(a.keys, a.withScores)
+	})
+}
+
+func (redis *redis) zsetAlgebra(cmd protocol.RedisCmd, weighted bool, op func(a zsetAlgebraArgs) ([]string, error)) []byte {
+	args := cmd.Args
+	if len(args) < 2 {
+		return protocol.EncodeResp(rerr.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	parsed, errReply := parseZSetAlgebraArgs(cmd.Cmd, args, weighted, true)
+	if errReply != nil {
+		return errReply
+	}
+
+	result, err := op(parsed)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(result, false)
+}
+
+/* Support ZUNIONSTORE destination numkeys key [key ...] [WEIGHTS weight [weight ...]] [AGGREGATE <SUM | MIN | MAX>] */
+func (redis *redis) ZUnionStore(cmd protocol.RedisCmd) []byte {
+	return redis.zsetAlgebraStore(cmd, true, func(dst string, a zsetAlgebraArgs) (uint32, error) {
+		return redis.Store.ZUnionStore(dst, a.keys, a.weights, a.aggregate)
+	})
+}
+
+/* Support ZINTERSTORE destination numkeys key [key ...] [WEIGHTS weight [weight ...]] [AGGREGATE <SUM | MIN | MAX>] */
+func (redis *redis) ZInterStore(cmd protocol.RedisCmd) []byte {
+	return redis.zsetAlgebraStore(cmd, true, func(dst string, a zsetAlgebraArgs) (uint32, error) {
+		return redis.Store.ZInterStore(dst, a.keys, a.weights, a.aggregate)
+	})
+}
+
+/* Support ZDIFFSTORE destination numkeys key [key ...] */
+func (redis *redis) ZDiffStore(cmd protocol.RedisCmd) []byte {
+	return redis.zsetAlgebraStore(cmd, false, func(dst string, a zsetAlgebraArgs) (uint32, error) {
+		return redis.Store.ZDiffStore(dst, a.keys)
+	})
+}
+
+func (redis *redis) zsetAlgebraStore(cmd protocol.RedisCmd, weighted bool,
+	op func(dst string, a zsetAlgebraArgs) (uint32, error)) []byte {
+	args := cmd.Args
+	if len(args) < 3 {
+		return protocol.EncodeResp(rerr.InvalidNumberOfArgs(cmd.Cmd), false)
 	}
 
-	if str == "+" {
-		return maxLexString, nil
+	parsed, errReply := parseZSetAlgebraArgs(cmd.Cmd, args[1:], weighted, false)
+	if errReply != nil {
+		return errReply
 	}
 
-	// Validate no special prefixes
-	if len(str) > 0 && (str[0] == '[' || str[0] == '(') {
-		return "", errors.New("range syntax not supported")
+	size, err := op(args[0], parsed)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
 	}
 
-	return str, nil
+	return protocol.EncodeResp(size, false)
+}
+
+/* Support ZINTERCARD numkeys key [key ...] [LIMIT limit] */
+func (redis *redis) ZInterCard(cmd protocol.RedisCmd) []byte {
+	args := cmd.Args
+	if len(args) < 2 {
+		return protocol.EncodeResp(rerr.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	keys, rest, errReply := parseNumKeys(args)
+	if errReply != nil {
+		return errReply
+	}
+
+	limit, errReply := parseCardLimit(rest)
+	if errReply != nil {
+		return errReply
+	}
+
+	size, err := redis.Store.ZInterCard(limit, keys...)
+	if err != nil {
+		return protocol.EncodeResp(err, false)
+	}
+
+	return protocol.EncodeResp(size, false)
+}
+
+type zsetAlgebraArgs struct {
+	keys       []string
+	weights    []float64 // nil without WEIGHTS
+	aggregate  types.ZAggregate
+	withScores bool
+}
+
+// parseZSetAlgebraArgs parses `numkeys key [key ...]` followed by the options of the sorted
+// set algebra: WEIGHTS and AGGREGATE when weighted, WITHSCORES when withScores.
+func parseZSetAlgebraArgs(command string, args []string, weighted, withScores bool) (zsetAlgebraArgs, []byte) {
+	var parsed zsetAlgebraArgs
+
+	// Unlike the other commands taking numkeys, Redis words the error after the input keys
+	if numKeys, err := strconv.ParseInt(args[0], 10, 64); err == nil && numKeys <= 0 {
+		return parsed, protocol.EncodeResp(rerr.NoInputKeys(command), false)
+	}
+
+	keys, rest, errReply := parseNumKeys(args)
+	if errReply != nil {
+		return parsed, errReply
+	}
+	parsed.keys = keys
+
+	for i := 0; i < len(rest); i++ {
+		switch option := strings.ToUpper(rest[i]); {
+		case option == "WEIGHTS" && weighted:
+			if i+len(keys) >= len(rest) {
+				return parsed, protocol.RespSyntaxError
+			}
+
+			parsed.weights = make([]float64, len(keys))
+			for j := range keys {
+				weight, err := strconv.ParseFloat(rest[i+1+j], 64)
+				if err != nil || math.IsNaN(weight) {
+					return parsed, protocol.RespWeightNotFloat
+				}
+				parsed.weights[j] = weight
+			}
+			i += len(keys)
+		case option == "AGGREGATE" && weighted:
+			if i+1 >= len(rest) {
+				return parsed, protocol.RespSyntaxError
+			}
+
+			switch strings.ToUpper(rest[i+1]) {
+			case "SUM":
+				parsed.aggregate = types.ZAggregateSum
+			case "MIN":
+				parsed.aggregate = types.ZAggregateMin
+			case "MAX":
+				parsed.aggregate = types.ZAggregateMax
+			default:
+				return parsed, protocol.RespSyntaxError
+			}
+			i++
+		case option == "WITHSCORES" && withScores:
+			parsed.withScores = true
+		default:
+			return parsed, protocol.RespSyntaxError
+		}
+	}
+
+	return parsed, nil
+}
+
+// parseScoreRange parses the min and max bounds of a score range, each a float, -inf or
+// +inf, excluded when prefixed with "(".
+func parseScoreRange(minArg, maxArg string) (types.ZScoreRange, bool) {
+	minScore, minEx, minOk := parseScoreBound(minArg)
+	maxScore, maxEx, maxOk := parseScoreBound(maxArg)
+	return types.ZScoreRange{Min: minScore, Max: maxScore, MinEx: minEx, MaxEx: maxEx}, minOk && maxOk
+}
+
+func parseScoreBound(arg string) (float64, bool, bool) {
+	exclusive := strings.HasPrefix(arg, "(")
+	if exclusive {
+		arg = arg[1:]
+	}
+
+	score, err := strconv.ParseFloat(arg, 64)
+	if err != nil || math.IsNaN(score) {
+		return 0, false, false
+	}
+	return score, exclusive, true
+}
+
+// parseLexRange parses the min and max bounds of a lex range, see parseLexBound.
+func parseLexRange(minArg, maxArg string) (types.ZLexRange, bool) {
+	minBound, minOk := parseLexBound(minArg)
+	maxBound, maxOk := parseLexBound(maxArg)
+	return types.ZLexRange{Min: minBound, Max: maxBound}, minOk && maxOk
+}
+
+// parseLexBound parses "-" and "+" for the bounds below and above every member, or a member
+// prefixed with "[" when included and "(" when excluded.
+func parseLexBound(arg string) (types.ZLexBound, bool) {
+	switch {
+	case arg == "-":
+		return types.ZLexBound{Inf: -1}, true
+	case arg == "+":
+		return types.ZLexBound{Inf: 1}, true
+	case strings.HasPrefix(arg, "["):
+		return types.ZLexBound{Value: arg[1:]}, true
+	case strings.HasPrefix(arg, "("):
+		return types.ZLexBound{Value: arg[1:], Exclusive: true}, true
+	}
+	return types.ZLexBound{}, false
 }
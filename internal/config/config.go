@@ -1,5 +1,11 @@
 package config
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 type EvictionPolicy string
 
 const (
@@ -13,6 +19,14 @@ const (
 	VolatileTTL    EvictionPolicy = "volatile-ttl"
 )
 
+type AppendFsync string
+
+const (
+	AppendFsyncAlways   AppendFsync = "always"
+	AppendFsyncEverySec AppendFsync = "everysec"
+	AppendFsyncNo       AppendFsync = "no"
+)
+
 // Bloom filter validation constants
 const (
 	BFMinCapacity  = 1
@@ -32,18 +46,53 @@ const (
 	CFMaxExpansionFactor = 32768
 )
 
+// SaveRule triggers a background save once at least Changes writes
+// happened and Seconds elapsed since the last successful save.
+type SaveRule struct {
+	Seconds int
+	Changes int
+}
+
 // Config holds all configuration values for the Redis server.
 type Config struct {
 	// Server settings
 	Host          string
 	Port          int
 	MaxConnection int
+	Databases     int // number of logical databases, selected with SELECT
+
+	ClientQueryBufferLimit int64 // unparsed bytes a client may accumulate before it is disconnected
+
+	// Snapshot settings
+	Dir        string
+	DBFilename string
+	SaveRules  []SaveRule
+
+	// Append-only file settings
+	AppendOnly       bool
+	AppendFilename   string
+	AppendFsync      AppendFsync
+	AOFLoadTruncated bool // repair an AOF whose last command was only partially written instead of refusing to start
+
+	// String settings
+	ProtoMaxBulkLen int64 // maximum size of a string value in bytes
 
 	// List settings
-	ListMaxListpackSize string
+	ListMaxListpackSize int // entries per quicklist node when positive, -1 to -5 for 4KiB to 64KiB nodes
+	ListCompressDepth   int // quicklist nodes at each end left uncompressed, 0 disables compression
+
+	// Hash settings
+	HashMaxListpackEntries int // fields of a hash kept in a listpack
+	HashMaxListpackValue   int // bytes of the longest field or value kept in a listpack
 
 	// Set settings
-	SetMaxIntsetEntries int
+	SetMaxIntsetEntries   int
+	SetMaxListpackEntries int // members of a set kept in a listpack
+	SetMaxListpackValue   int // bytes of the longest member kept in a listpack
+
+	// Sorted set settings
+	ZSetMaxListpackEntries int // members of a sorted set kept in a listpack
+	ZSetMaxListpackValue   int // bytes of the longest member kept in a listpack
 
 	// Bloom filter settings
 	BFDefaultErrorRate float64
@@ -92,10 +141,37 @@ func NewConfig() *Config {
 		Host:          "0.0.0.0",
 		Port:          6379,
 		MaxConnection: 10000,
+		Databases:     16,
+
+		ClientQueryBufferLimit: 1024 * 1024 * 1024,
+
+		Dir:        ".",
+		DBFilename: "dump.rdb",
+		SaveRules: []SaveRule{
+			{Seconds: 3600, Changes: 1},
+			{Seconds: 300, Changes: 100},
+			{Seconds: 60, Changes: 10000},
+		},
+
+		AppendOnly:       false,
+		AppendFilename:   "appendonly.aof",
+		AppendFsync:      AppendFsyncEverySec,
+		AOFLoadTruncated: true,
+
+		ProtoMaxBulkLen: 512 * 1024 * 1024,
+
+		ListMaxListpackSize: -2,
+		ListCompressDepth:   0,
 
-		ListMaxListpackSize: "8KiB",
+		HashMaxListpackEntries: 128,
+		HashMaxListpackValue:   64,
 
-		SetMaxIntsetEntries: 512,
+		SetMaxIntsetEntries:   512,
+		SetMaxListpackEntries: 128,
+		SetMaxListpackValue:   64,
+
+		ZSetMaxListpackEntries: 128,
+		ZSetMaxListpackValue:   64,
 
 		BFDefaultErrorRate: 0.01,
 		BFDefaultCapacity:  100,
@@ -133,3 +209,62 @@ func NewConfig() *Config {
 		LFUDecayTime: 1,
 	}
 }
+
+// ParseSaveRules parses the "<seconds> <changes> [<seconds> <changes> ...]" format of
+// the save directive. An empty string disables automatic snapshots.
+func ParseSaveRules(value string) ([]SaveRule, error) {
+	fields := strings.Fields(value)
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("invalid save rules %q: expected pairs of <seconds> <changes>", value)
+	}
+
+	rules := make([]SaveRule, 0, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		seconds, err1 := strconv.Atoi(fields[i])
+		changes, err2 := strconv.Atoi(fields[i+1])
+		if err1 != nil || err2 != nil || seconds < 1 || changes < 1 {
+			return nil, fmt.Errorf("invalid save rule %q: values must be positive integers", fields[i]+" "+fields[i+1])
+		}
+		rules = append(rules, SaveRule{Seconds: seconds, Changes: changes})
+	}
+
+	return rules, nil
+}
+
+// ParseDatabases parses the number of logical databases, which must be at least one.
+func ParseDatabases(value string) (int, error) {
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 1 {
+		return 0, fmt.Errorf("invalid number of databases %q: expected a positive integer", value)
+	}
+	return count, nil
+}
+
+// ParseListMaxListpackSize parses the node size limit of lists: a positive number of
+// entries, or -1 to -5 for 4KiB to 64KiB.
+func ParseListMaxListpackSize(value string) (int, error) {
+	fill, err := strconv.Atoi(value)
+	if err != nil || fill == 0 || fill < -5 {
+		return 0, fmt.Errorf("invalid list-max-listpack-size %q: expected a positive number of entries or -1 to -5", value)
+	}
+	return fill, nil
+}
+
+// ParseListCompressDepth parses the number of list nodes left uncompressed at each end,
+// 0 disabling compression.
+func ParseListCompressDepth(value string) (int, error) {
+	depth, err := strconv.Atoi(value)
+	if err != nil || depth < 0 {
+		return 0, fmt.Errorf("invalid list-compress-depth %q: expected a non-negative integer", value)
+	}
+	return depth, nil
+}
+
+func ParseAppendFsync(value string) (AppendFsync, error) {
+	switch policy := AppendFsync(strings.ToLower(value)); policy {
+	case AppendFsyncAlways, AppendFsyncEverySec, AppendFsyncNo:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid appendfsync policy %q: expected always, everysec or no", value)
+	}
+}
@@ -19,3 +19,39 @@ func InvalidCommand(command string) error {
 func InvalidExpireTime(command string) error {
 	return fmt.Errorf("ERR invalid expire time in '%s' command", command)
 }
+
+func PersistenceFailed(err error) error {
+	return fmt.Errorf("ERR %v", err)
+}
+
+func NotAllowedWhileSubscribed(command string) error {
+	return fmt.Errorf("ERR Can't execute '%s': only (P)SUBSCRIBE / (P)UNSUBSCRIBE / PING are allowed in this context", command)
+}
+
+func NoInputKeys(command string) error {
+	return fmt.Errorf("ERR at least 1 input key is needed for '%s' command", command)
+}
+
+func UnknownSubcommand(subcommand, command string) error {
+	return fmt.Errorf("ERR unknown subcommand '%s'. Try %s HELP.", subcommand, command)
+}
+
+func NoSuchKeyOrGroup(key, group string) error {
+	return fmt.Errorf("NOGROUP No such key '%s' or consumer group '%s'", key, group)
+}
+
+func NoSuchGroupForKey(group, key string) error {
+	return fmt.Errorf("NOGROUP No such consumer group '%s' for key name '%s'", group, key)
+}
+
+func InvalidOptionArgument(option, command string) error {
+	return fmt.Errorf("ERR Invalid %s option argument for %s", option, command)
+}
+
+func UnrecognizedOption(option, command string) error {
+	return fmt.Errorf("ERR Unrecognized %s option '%s'", command, option)
+}
+
+func InvalidMinIdleTime(command string) error {
+	return fmt.Errorf("ERR Invalid min-idle-time argument for %s", command)
+}
@@ -0,0 +1,118 @@
+// Package glob implements the glob-style patterns used by Redis, for example by
+// PSUBSCRIBE, KEYS and the MATCH option of SCAN.
+//
+// Supported syntax:
+//   - `*` matches any sequence of bytes, including an empty one
+//   - `?` matches a single byte
+//   - `[abc]`, `[a-z]` and `[^abc]` match a byte in, or not in, a set
+//   - `\x` matches x literally
+package glob
+
+// Match reports whether str matches pattern. Unlike path.Match, `*` also matches `/`
+// and a malformed pattern never fails, it simply matches what it can.
+func Match(pattern, str string) bool {
+	var skipLonger bool
+	return match(pattern, str, &skipLonger)
+}
+
+// match follows stringmatchlen of Redis. Once a `*` failed to match any suffix of str,
+// no longer match can succeed either, so skipLonger stops the enclosing `*` from retrying.
+func match(pattern, str string, skipLonger *bool) bool {
+	for len(pattern) > 0 && len(str) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for len(str) > 0 {
+				if match(pattern[1:], str, skipLonger) {
+					return true
+				}
+				if *skipLonger {
+					return false
+				}
+				str = str[1:]
+			}
+			*skipLonger = true
+			return false
+
+		case '?':
+			str = str[1:]
+
+		case '[':
+			var matched bool
+			matched, pattern = matchClass(pattern[1:], str[0])
+			if !matched {
+				return false
+			}
+			str = str[1:]
+
+		case '\\':
+			if len(pattern) >= 2 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+
+		default:
+			if pattern[0] != str[0] {
+				return false
+			}
+			str = str[1:]
+		}
+
+		pattern = pattern[1:]
+	}
+
+	// Trailing stars also match the empty rest of str
+	for len(str) == 0 && len(pattern) > 0 && pattern[0] == '*' {
+		pattern = pattern[1:]
+	}
+
+	return len(pattern) == 0 && len(str) == 0
+}
+
+// matchClass matches c against the class that starts right after `[`. It returns the
+// pattern positioned on the closing `]`, or on its last byte when the class is unterminated.
+func matchClass(pattern string, c byte) (bool, string) {
+	not := len(pattern) > 0 && pattern[0] == '^'
+	if not {
+		pattern = pattern[1:]
+	}
+
+	matched := false
+	for {
+		if len(pattern) == 0 {
+			// Unterminated class, leave a byte for the caller to consume
+			return matched != not, "]"
+		}
+
+		switch {
+		case pattern[0] == '\\' && len(pattern) >= 2:
+			pattern = pattern[1:]
+			if pattern[0] == c {
+				matched = true
+			}
+
+		case pattern[0] == ']':
+			return matched != not, pattern
+
+		case len(pattern) >= 3 && pattern[1] == '-':
+			start, end := pattern[0], pattern[2]
+			if start > end {
+				start, end = end, start
+			}
+			if c >= start && c <= end {
+				matched = true
+			}
+			pattern = pattern[2:]
+
+		case pattern[0] == c:
+			matched = true
+		}
+
+		pattern = pattern[1:]
+	}
+}
@@ -0,0 +1,55 @@
+package glob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		str     string
+		want    bool
+	}{
+		{"*", "", true},
+		{"*", "anything/with/slashes", true},
+		{"", "", true},
+		{"", "a", false},
+		{"news.*", "news.sport", true},
+		{"news.*", "news.", true},
+		{"news.*", "news", false},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h*llo", "hllo", true},
+		{"h*llo", "heeeello", true},
+		{"h**llo", "heeeello", true},
+		{"*o*o*", "foobar", true},
+		{"*o*o*x", "foobar", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-b]llo", "hbllo", true},
+		{"h[b-a]llo", "hallo", true},
+		{"h[a-b]llo", "hcllo", false},
+		{"h[\\]]llo", "h]llo", true},
+		{"h\\*llo", "h*llo", true},
+		{"h\\*llo", "hello", false},
+		{"abc\\", "abc\\", true},
+		{"h[ab", "ha", true},
+		{"a*", "b", false},
+		{"*a", "bbbb", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Match(tt.pattern, tt.str), "Match(%q, %q)", tt.pattern, tt.str)
+	}
+}
+
+func TestMatch_ManyStars(t *testing.T) {
+	// Must finish quickly thanks to the skip of longer matches
+	pattern := "a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*b"
+	str := "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	assert.False(t, Match(pattern, str))
+}
@@ -0,0 +1,335 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+	"github.com/manhhung2111/go-redis/internal/storage"
+)
+
+var (
+	ErrRewriteInProgress = errors.New("Background append only file rewriting already in progress")
+	ErrTruncatedAOF      = errors.New("truncated append only file")
+)
+
+type aofRewriteResult struct {
+	file *os.File
+	err  error
+}
+
+// AOF logs every write command so the dataset can be rebuilt by replaying them.
+// Commands are buffered by Feed and written by Flush, which the server calls before
+// replying to clients. Like RDB, all methods must be called from the event loop goroutine.
+//
+// A rewrite replaces the log with an RDB preamble of the current dataset followed by
+// the commands that arrived while the preamble was being written.
+//
+// Replaying starts in the first database, and the log switches databases with SELECT.
+type AOF struct {
+	config    *config.Config
+	databases storage.Databases
+
+	file       *os.File
+	buf        []byte    // commands not written to file yet
+	selectedDB int       // database of the last logged command, -1 when the next one must log a SELECT
+	unsynced   bool      // file has writes that have not been fsynced
+	lastSync   time.Time // start of the last fsync for the everysec policy
+	syncing    atomic.Bool
+
+	rewriteDone chan aofRewriteResult
+	rewriteBuf  []byte // commands fed since the running rewrite took its snapshot
+}
+
+func NewAOF(cfg *config.Config, databases storage.Databases) *AOF {
+	return &AOF{
+		config:     cfg,
+		databases:  databases,
+		selectedDB: -1,
+	}
+}
+
+func (aof *AOF) path() string {
+	return filepath.Join(aof.config.Dir, aof.config.AppendFilename)
+}
+
+// Load replays the append-only file through replay. It reports whether the file existed.
+// A command cut short at the end of the file, as left behind by a crash in the middle of
+// a write, is dropped from the file when AOFLoadTruncated is set and is an error otherwise.
+// The same goes for a MULTI block that is missing its EXEC.
+func (aof *AOF) Load(replay func(cmd protocol.RedisCmd) error) (bool, error) {
+	start := time.Now()
+	data, err := os.ReadFile(aof.path())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	pos := 0
+	if hasRDBHeader(data) {
+		pos, err = loadRDBPrefix(data, aof.databases)
+		if err != nil {
+			return true, fmt.Errorf("failed to load the RDB preamble of %s: %w", aof.path(), err)
+		}
+	}
+
+	// Offset of the MULTI that opened the transaction being read, or -1
+	multiStart := -1
+	for pos < len(data) {
+		cmd, consumed, err := protocol.ReadCmd(data[pos:])
+		if errors.Is(err, protocol.ErrIncomplete) {
+			break
+		}
+		if err != nil {
+			return true, fmt.Errorf("bad file format reading %s at offset %d: %w", aof.path(), pos, err)
+		}
+
+		switch cmd.Cmd {
+		case "MULTI":
+			multiStart = pos
+		case "EXEC":
+			multiStart = -1
+		}
+
+		if err := replay(*cmd); err != nil {
+			return true, fmt.Errorf("failed to replay %s at offset %d: %w", aof.path(), pos, err)
+		}
+		pos += consumed
+	}
+
+	// A transaction without its EXEC was never applied, it is dropped like a partial command
+	if multiStart >= 0 {
+		pos = multiStart
+	}
+	if pos < len(data) {
+		if err := aof.repairTruncatedTail(pos); err != nil {
+			return true, err
+		}
+	}
+
+	log.Printf("DB loaded from append only file: %.3f seconds", time.Since(start).Seconds())
+	return true, nil
+}
+
+func (aof *AOF) repairTruncatedTail(validSize int) error {
+	if !aof.config.AOFLoadTruncated {
+		return fmt.Errorf("%w %s: the last command ends at offset %d, start with -aof-load-truncated to repair it",
+			ErrTruncatedAOF, aof.path(), validSize)
+	}
+
+	log.Printf("!!! Warning: short read while loading the AOF file %s !!!", aof.path())
+	if err := os.Truncate(aof.path(), int64(validSize)); err != nil {
+		return fmt.Errorf("failed to truncate %s: %w", aof.path(), err)
+	}
+
+	log.Printf("AOF %s truncated to its last complete command at offset %d", aof.path(), validSize)
+	return nil
+}
+
+// Open starts logging to the append-only file. When there is no file yet, one is created
+// from the current dataset so that it holds everything loaded from an RDB snapshot.
+func (aof *AOF) Open() error {
+	if !aof.config.AppendOnly {
+		return nil
+	}
+
+	if _, err := os.Stat(aof.path()); errors.Is(err, os.ErrNotExist) {
+		data, err := encodeRDB(aof.databases)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(aof.path(), data); err != nil {
+			return fmt.Errorf("failed to create %s: %w", aof.path(), err)
+		}
+	}
+
+	file, err := os.OpenFile(aof.path(), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	aof.file = file
+	aof.lastSync = time.Now()
+	return nil
+}
+
+// Feed appends a command to the log buffer.
+func (aof *AOF) Feed(args ...string) {
+	if aof.file == nil {
+		return
+	}
+
+	encoded := protocol.EncodeResp(args, false)
+	aof.buf = append(aof.buf, encoded...)
+	if aof.rewriteDone != nil {
+		aof.rewriteBuf = append(aof.rewriteBuf, encoded...)
+	}
+}
+
+// Select makes the following commands run in database db, logging a SELECT when the
+// previous ones ran in another database.
+func (aof *AOF) Select(db int) {
+	if db == aof.selectedDB {
+		return
+	}
+
+	aof.Feed("SELECT", strconv.Itoa(db))
+	aof.selectedDB = db
+}
+
+// Flush writes the buffered commands, and fsyncs them right away under the always policy.
+func (aof *AOF) Flush() error {
+	if len(aof.buf) == 0 {
+		return nil
+	}
+
+	n, err := aof.file.Write(aof.buf)
+	aof.unsynced = aof.unsynced || n > 0
+	if err != nil {
+		// Keep what was not written, the next flush retries it
+		aof.buf = aof.buf[:copy(aof.buf, aof.buf[n:])]
+		return fmt.Errorf("failed to write to %s: %w", aof.path(), err)
+	}
+	aof.buf = aof.buf[:0]
+
+	if aof.config.AppendFsync == config.AppendFsyncAlways {
+		if err := aof.file.Sync(); err != nil {
+			return fmt.Errorf("failed to fsync %s: %w", aof.path(), err)
+		}
+		aof.unsynced = false
+	}
+
+	return nil
+}
+
+// Cron is driven by the server timer. It fsyncs in the background once per second under
+// the everysec policy and finishes a background rewrite once its preamble is on disk.
+func (aof *AOF) Cron() {
+	if aof.rewriteDone != nil {
+		select {
+		case result := <-aof.rewriteDone:
+			aof.finishRewrite(result)
+		default:
+		}
+	}
+
+	if aof.file == nil || aof.config.AppendFsync != config.AppendFsyncEverySec {
+		return
+	}
+
+	if !aof.unsynced || aof.syncing.Load() || time.Since(aof.lastSync) < time.Second {
+		return
+	}
+
+	aof.unsynced = false
+	aof.lastSync = time.Now()
+	aof.syncing.Store(true)
+	go func(file *os.File) {
+		defer aof.syncing.Store(false)
+		if err := file.Sync(); err != nil {
+			log.Printf("failed to fsync the append only file: %v", err)
+		}
+	}(aof.file)
+}
+
+// BGRewrite snapshots the dataset right away and writes the new log in the background.
+func (aof *AOF) BGRewrite() error {
+	if aof.rewriteDone != nil {
+		return ErrRewriteInProgress
+	}
+
+	data, err := encodeRDB(aof.databases)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(aof.config.Dir, "temp-rewriteaof-*.aof")
+	if err != nil {
+		return err
+	}
+
+	done := make(chan aofRewriteResult, 1)
+	go func() {
+		_, err := tmp.Write(data)
+		if err == nil {
+			err = tmp.Sync()
+		}
+		done <- aofRewriteResult{file: tmp, err: err}
+	}()
+
+	aof.rewriteDone = done
+	aof.rewriteBuf = aof.rewriteBuf[:0]
+	// Replaying the commands that follow the preamble starts in the first database again
+	aof.selectedDB = -1
+	return nil
+}
+
+// finishRewrite appends the commands that arrived during the rewrite and swaps the new file in.
+func (aof *AOF) finishRewrite(result aofRewriteResult) {
+	aof.rewriteDone = nil
+	rewriteBuf := aof.rewriteBuf
+	aof.rewriteBuf = nil
+
+	tmp := result.file
+	err := result.err
+	if err == nil {
+		_, err = tmp.Write(rewriteBuf)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err == nil {
+		err = tmp.Chmod(0o644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), aof.path())
+	}
+
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		log.Printf("background AOF rewrite failed: %v", err)
+		return
+	}
+
+	if aof.file == nil {
+		tmp.Close()
+	} else {
+		// Everything still buffered for the old file was also fed to rewriteBuf
+		aof.file.Close()
+		aof.file = tmp
+		aof.buf = aof.buf[:0]
+		aof.unsynced = false
+	}
+	log.Println("background AOF rewrite terminated with success")
+}
+
+// Shutdown completes a running rewrite and makes sure every logged command is on disk.
+func (aof *AOF) Shutdown() error {
+	if aof.rewriteDone != nil {
+		aof.finishRewrite(<-aof.rewriteDone)
+	}
+
+	if aof.file == nil {
+		return nil
+	}
+
+	err := aof.Flush()
+	if err == nil {
+		err = aof.file.Sync()
+	}
+	if closeErr := aof.file.Close(); err == nil {
+		err = closeErr
+	}
+	aof.file = nil
+	return err
+}
@@ -0,0 +1,227 @@
+package persistence
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+	"github.com/manhhung2111/go-redis/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAOF(t *testing.T, dir string) (*AOF, storage.Store) {
+	t.Helper()
+	cfg := config.NewConfig()
+	cfg.Dir = dir
+	cfg.AppendOnly = true
+	databases := storage.NewDatabases(cfg)
+	return NewAOF(cfg, databases), databases.DB(0)
+}
+
+// loadCommands loads the AOF and returns the commands it replayed.
+func loadCommands(t *testing.T, aof *AOF) []protocol.RedisCmd {
+	t.Helper()
+	var cmds []protocol.RedisCmd
+	loaded, err := aof.Load(func(cmd protocol.RedisCmd) error {
+		cmds = append(cmds, cmd)
+		return nil
+	})
+	require.NoError(t, err)
+	require.True(t, loaded)
+	return cmds
+}
+
+func TestAOF_FeedFlushAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	aof, _ := newTestAOF(t, dir)
+	require.NoError(t, aof.Open())
+
+	aof.Feed("SET", "key", "value")
+	aof.Feed("RPUSH", "list", "a", "b")
+	require.NoError(t, aof.Flush())
+	require.NoError(t, aof.Shutdown())
+
+	loaded, _ := newTestAOF(t, dir)
+	cmds := loadCommands(t, loaded)
+	assert.Equal(t, []protocol.RedisCmd{
+		{Cmd: "SET", Args: []string{"key", "value"}},
+		{Cmd: "RPUSH", Args: []string{"list", "a", "b"}},
+	}, cmds)
+}
+
+func TestAOF_FeedIsIgnoredWhenDisabled(t *testing.T) {
+	dir := t.TempDir()
+	aof, _ := newTestAOF(t, dir)
+	aof.config.AppendOnly = false
+	require.NoError(t, aof.Open())
+
+	aof.Feed("SET", "key", "value")
+	require.NoError(t, aof.Flush())
+	assert.NoFileExists(t, filepath.Join(dir, "appendonly.aof"))
+}
+
+func TestAOF_LoadMissingFile(t *testing.T) {
+	aof, _ := newTestAOF(t, t.TempDir())
+	loaded, err := aof.Load(func(cmd protocol.RedisCmd) error { return nil })
+	require.NoError(t, err)
+	assert.False(t, loaded)
+}
+
+func TestAOF_OpenCreatesBaseFromDataset(t *testing.T) {
+	dir := t.TempDir()
+	aof, store := newTestAOF(t, dir)
+	store.Set("key", "value")
+	require.NoError(t, aof.Open())
+	aof.Feed("SET", "other", "value")
+	require.NoError(t, aof.Shutdown())
+
+	loaded, loadedStore := newTestAOF(t, dir)
+	cmds := loadCommands(t, loaded)
+	assert.Equal(t, []protocol.RedisCmd{{Cmd: "SET", Args: []string{"other", "value"}}}, cmds)
+
+	value, _ := loadedStore.Get("key")
+	require.NotNil(t, value)
+	assert.Equal(t, "value", *value)
+}
+
+func TestAOF_TruncatedTail(t *testing.T) {
+	dir := t.TempDir()
+	aof, _ := newTestAOF(t, dir)
+	require.NoError(t, aof.Open())
+	aof.Feed("SET", "a", "1")
+	require.NoError(t, aof.Shutdown())
+
+	path := filepath.Join(dir, "appendonly.aof")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	validSize := info.Size()
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	_, err = file.WriteString("*3\r\n$3\r\nSET\r\n$1\r\nb")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	strict, _ := newTestAOF(t, dir)
+	strict.config.AOFLoadTruncated = false
+	_, err = strict.Load(func(cmd protocol.RedisCmd) error { return nil })
+	assert.ErrorIs(t, err, ErrTruncatedAOF)
+
+	repairing, _ := newTestAOF(t, dir)
+	cmds := loadCommands(t, repairing)
+	assert.Equal(t, []protocol.RedisCmd{{Cmd: "SET", Args: []string{"a", "1"}}}, cmds)
+
+	info, err = os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, validSize, info.Size())
+}
+
+func TestAOF_CorruptCommand(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "appendonly.aof"), []byte("+OK\r\n*1\r\n$4\r\nPING\r\n"), 0o644))
+
+	aof, _ := newTestAOF(t, dir)
+	_, err := aof.Load(func(cmd protocol.RedisCmd) error { return nil })
+	assert.ErrorIs(t, err, protocol.ErrInvalidRESP)
+}
+
+func TestAOF_BGRewrite(t *testing.T) {
+	dir := t.TempDir()
+	aof, store := newTestAOF(t, dir)
+	require.NoError(t, aof.Open())
+
+	for i := 0; i < 10; i++ {
+		store.Set("counter", "value")
+		aof.Feed("SET", "counter", "value")
+	}
+	require.NoError(t, aof.Flush())
+
+	require.NoError(t, aof.BGRewrite())
+	assert.ErrorIs(t, aof.BGRewrite(), ErrRewriteInProgress)
+
+	// Writes during the rewrite must survive it
+	store.Set("late", "value")
+	aof.Feed("SET", "late", "value")
+	require.NoError(t, aof.Flush())
+
+	require.Eventually(t, func() bool {
+		aof.Cron()
+		return aof.rewriteDone == nil
+	}, time.Second, time.Millisecond)
+
+	aof.Feed("SET", "after", "value")
+	require.NoError(t, aof.Shutdown())
+
+	loaded, loadedStore := newTestAOF(t, dir)
+	cmds := loadCommands(t, loaded)
+	assert.Equal(t, []protocol.RedisCmd{
+		{Cmd: "SET", Args: []string{"late", "value"}},
+		{Cmd: "SET", Args: []string{"after", "value"}},
+	}, cmds)
+	assert.True(t, loadedStore.Exists("counter"))
+}
+
+func TestAOF_EverySecFsyncsInBackground(t *testing.T) {
+	aof, _ := newTestAOF(t, t.TempDir())
+	require.NoError(t, aof.Open())
+	defer aof.Shutdown()
+
+	aof.Feed("SET", "key", "value")
+	require.NoError(t, aof.Flush())
+	assert.True(t, aof.unsynced)
+
+	aof.Cron()
+	assert.True(t, aof.unsynced, "fsync waits for a second to pass")
+
+	aof.lastSync = time.Now().Add(-time.Second)
+	aof.Cron()
+	assert.False(t, aof.unsynced)
+	require.Eventually(t, func() bool { return !aof.syncing.Load() }, time.Second, time.Millisecond)
+}
+
+func TestAOF_AlwaysFsyncsOnFlush(t *testing.T) {
+	aof, _ := newTestAOF(t, t.TempDir())
+	aof.config.AppendFsync = config.AppendFsyncAlways
+	require.NoError(t, aof.Open())
+	defer aof.Shutdown()
+
+	aof.Feed("SET", "key", "value")
+	require.NoError(t, aof.Flush())
+	assert.False(t, aof.unsynced)
+}
+
+func TestAOF_UnterminatedTransaction(t *testing.T) {
+	dir := t.TempDir()
+	aof, _ := newTestAOF(t, dir)
+	require.NoError(t, aof.Open())
+	aof.Feed("SET", "a", "1")
+	aof.Feed("MULTI")
+	aof.Feed("SET", "a", "2")
+	aof.Feed("EXEC")
+	require.NoError(t, aof.Flush())
+
+	path := filepath.Join(dir, "appendonly.aof")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	validSize := info.Size()
+
+	aof.Feed("MULTI")
+	aof.Feed("SET", "a", "3")
+	require.NoError(t, aof.Shutdown())
+
+	strict, _ := newTestAOF(t, dir)
+	strict.config.AOFLoadTruncated = false
+	_, err = strict.Load(func(cmd protocol.RedisCmd) error { return nil })
+	assert.ErrorIs(t, err, ErrTruncatedAOF)
+
+	repairing, _ := newTestAOF(t, dir)
+	loadCommands(t, repairing)
+
+	info, err = os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, validSize, info.Size())
+}
@@ -0,0 +1,291 @@
+package persistence
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc64"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/storage"
+)
+
+// Snapshot file layout: magic, version, the body written by storage.SnapshotStore,
+// and a CRC64 of everything before it.
+const (
+	rdbMagic    = "GOREDIS"
+	rdbVersion  = "0001"
+	rdbCRCSize  = 8
+	rdbHeadSize = len(rdbMagic) + len(rdbVersion)
+)
+
+// After a failed background save, automatic saves are retried no sooner than this
+const bgsaveRetryDelay = 5 * time.Second
+
+var crcTable = crc64.MakeTable(crc64.ECMA)
+
+var (
+	ErrBgsaveInProgress = errors.New("Background save already in progress")
+	ErrBadRDBFormat     = errors.New("bad RDB file format")
+	ErrBadRDBVersion    = errors.New("unsupported RDB version")
+	ErrBadRDBChecksum   = errors.New("RDB checksum mismatch")
+)
+
+// RDB takes point-in-time snapshots of the databases and restores them at startup.
+// All methods must be called from the event loop goroutine; only the file write
+// of a background save runs concurrently.
+type RDB struct {
+	config    *config.Config
+	databases storage.Databases
+
+	lastSave       time.Time
+	dirty          int64 // writes since the last successful save
+	dirtyAtBgsave  int64 // value of dirty when the running background save started
+	bgsaveDone     chan error
+	lastBgsaveErr  error
+	lastBgsaveTime time.Time
+}
+
+func NewRDB(cfg *config.Config, databases storage.Databases) *RDB {
+	return &RDB{
+		config:    cfg,
+		databases: databases,
+		lastSave:  time.Now(),
+	}
+}
+
+func (rdb *RDB) path() string {
+	return filepath.Join(rdb.config.Dir, rdb.config.DBFilename)
+}
+
+// Load restores the snapshot file into the databases. A missing file is not an error.
+func (rdb *RDB) Load() error {
+	start := time.Now()
+	data, err := os.ReadFile(rdb.path())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	body, err := decodeRDB(data)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", rdb.path(), err)
+	}
+
+	if err := rdb.databases.ReadSnapshot(bytes.NewReader(body)); err != nil {
+		return fmt.Errorf("failed to load %s: %w", rdb.path(), err)
+	}
+
+	log.Printf("DB loaded from disk: %.3f seconds", time.Since(start).Seconds())
+	return nil
+}
+
+// Save writes a snapshot synchronously, blocking the event loop until the file is on disk.
+func (rdb *RDB) Save() error {
+	if rdb.bgsaveDone != nil {
+		return ErrBgsaveInProgress
+	}
+
+	data, err := encodeRDB(rdb.databases)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(rdb.path(), data); err != nil {
+		return err
+	}
+
+	rdb.lastSave = time.Now()
+	rdb.dirty = 0
+	return nil
+}
+
+// BGSave serializes the databases right away, which fixes the point in time of the snapshot,
+// and writes the file in the background. Cron picks up the result.
+func (rdb *RDB) BGSave() error {
+	if rdb.bgsaveDone != nil {
+		return ErrBgsaveInProgress
+	}
+
+	rdb.lastBgsaveTime = time.Now()
+	data, err := encodeRDB(rdb.databases)
+	if err != nil {
+		rdb.lastBgsaveErr = err
+		return err
+	}
+
+	done := make(chan error, 1)
+	path := rdb.path()
+	go func() {
+		done <- writeFileAtomic(path, data)
+	}()
+
+	rdb.bgsaveDone = done
+	rdb.dirtyAtBgsave = rdb.dirty
+	return nil
+}
+
+func (rdb *RDB) LastSave() int64 {
+	return rdb.lastSave.Unix()
+}
+
+// AddDirty records writes that are not covered by the last snapshot yet.
+func (rdb *RDB) AddDirty(changes int64) {
+	rdb.dirty += changes
+}
+
+// Cron is driven by the server timer. It collects the result of a finished
+// background save and starts a new one when a save rule is satisfied.
+func (rdb *RDB) Cron() {
+	if rdb.bgsaveDone != nil {
+		select {
+		case err := <-rdb.bgsaveDone:
+			rdb.finishBGSave(err)
+		default:
+		}
+		return
+	}
+
+	now := time.Now()
+	if rdb.lastBgsaveErr != nil && now.Sub(rdb.lastBgsaveTime) < bgsaveRetryDelay {
+		return
+	}
+
+	for _, rule := range rdb.config.SaveRules {
+		if rdb.dirty >= int64(rule.Changes) && now.Sub(rdb.lastSave) >= time.Duration(rule.Seconds)*time.Second {
+			log.Printf("%d changes in %d seconds. Saving...", rule.Changes, rule.Seconds)
+			if err := rdb.BGSave(); err != nil {
+				log.Printf("background saving failed: %v", err)
+			}
+			return
+		}
+	}
+}
+
+func (rdb *RDB) finishBGSave(err error) {
+	rdb.bgsaveDone = nil
+	rdb.lastBgsaveErr = err
+	if err != nil {
+		log.Printf("background saving failed: %v", err)
+		return
+	}
+
+	rdb.lastSave = time.Now()
+	rdb.dirty -= rdb.dirtyAtBgsave
+	log.Println("background saving terminated with success")
+}
+
+// Shutdown waits for a running background save and, when save rules are
+// configured, takes a final snapshot so no acknowledged write is lost.
+func (rdb *RDB) Shutdown() error {
+	if rdb.bgsaveDone != nil {
+		rdb.finishBGSave(<-rdb.bgsaveDone)
+	}
+
+	if len(rdb.config.SaveRules) == 0 {
+		return nil
+	}
+
+	log.Println("saving the final RDB snapshot before exiting")
+	return rdb.Save()
+}
+
+func encodeRDB(databases storage.Databases) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(rdbMagic)
+	buf.WriteString(rdbVersion)
+	if err := databases.WriteSnapshot(&buf); err != nil {
+		return nil, err
+	}
+
+	return binary.LittleEndian.AppendUint64(buf.Bytes(), crc64.Checksum(buf.Bytes(), crcTable)), nil
+}
+
+func hasRDBHeader(data []byte) bool {
+	return len(data) >= len(rdbMagic) && string(data[:len(rdbMagic)]) == rdbMagic
+}
+
+func checkRDBHeader(data []byte) error {
+	if len(data) < rdbHeadSize+rdbCRCSize || !hasRDBHeader(data) {
+		return ErrBadRDBFormat
+	}
+
+	if version := string(data[len(rdbMagic):rdbHeadSize]); version != rdbVersion {
+		return fmt.Errorf("%w %s", ErrBadRDBVersion, version)
+	}
+
+	return nil
+}
+
+// decodeRDB validates the header and checksum and returns the snapshot body.
+func decodeRDB(data []byte) ([]byte, error) {
+	if err := checkRDBHeader(data); err != nil {
+		return nil, err
+	}
+
+	crcOffset := len(data) - rdbCRCSize
+	if crc64.Checksum(data[:crcOffset], crcTable) != binary.LittleEndian.Uint64(data[crcOffset:]) {
+		return nil, ErrBadRDBChecksum
+	}
+
+	return data[rdbHeadSize:crcOffset], nil
+}
+
+// loadRDBPrefix loads a snapshot that is followed by other data, as in an append-only
+// file with an RDB preamble, and returns the number of bytes the snapshot occupies.
+func loadRDBPrefix(data []byte, databases storage.Databases) (int, error) {
+	if err := checkRDBHeader(data); err != nil {
+		return 0, err
+	}
+
+	body := bytes.NewReader(data[rdbHeadSize:])
+	if err := databases.ReadSnapshot(body); err != nil {
+		return 0, err
+	}
+
+	crcOffset := len(data) - body.Len()
+	if crcOffset+rdbCRCSize > len(data) {
+		return 0, ErrBadRDBFormat
+	}
+
+	if crc64.Checksum(data[:crcOffset], crcTable) != binary.LittleEndian.Uint64(data[crcOffset:]) {
+		return 0, ErrBadRDBChecksum
+	}
+
+	return crcOffset + rdbCRCSize, nil
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory and renames it
+// over path, so a crash never leaves a partially written snapshot behind.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "temp-*.rdb")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(0o644)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
@@ -0,0 +1,168 @@
+package persistence
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRDB(t *testing.T, dir string) (*RDB, storage.Store) {
+	t.Helper()
+	cfg := config.NewConfig()
+	cfg.Dir = dir
+	databases := storage.NewDatabases(cfg)
+	return NewRDB(cfg, databases), databases.DB(0)
+}
+
+func TestRDB_SaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	rdb, store := newTestRDB(t, dir)
+	store.Set("key", "value")
+	store.RPush("list", "a", "b")
+	rdb.AddDirty(2)
+
+	require.NoError(t, rdb.Save())
+	assert.Equal(t, int64(0), rdb.dirty)
+
+	loaded, loadedStore := newTestRDB(t, dir)
+	require.NoError(t, loaded.Load())
+
+	value, err := loadedStore.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", *value)
+
+	list, err := loadedStore.LRange("list", 0, -1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, list)
+}
+
+func TestRDB_LoadMissingFile(t *testing.T) {
+	rdb, store := newTestRDB(t, t.TempDir())
+	require.NoError(t, rdb.Load())
+	assert.False(t, store.Exists("key"))
+}
+
+func TestRDB_LoadRejectsCorruptFiles(t *testing.T) {
+	dir := t.TempDir()
+	rdb, store := newTestRDB(t, dir)
+	store.Set("key", "value")
+	require.NoError(t, rdb.Save())
+
+	path := filepath.Join(dir, "dump.rdb")
+	original, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		mutate func(data []byte) []byte
+		err    error
+	}{
+		{"flipped body byte", func(data []byte) []byte { data[rdbHeadSize+2] ^= 0xFF; return data }, ErrBadRDBChecksum},
+		{"truncated", func(data []byte) []byte { return data[:len(data)-3] }, ErrBadRDBChecksum},
+		{"bad magic", func(data []byte) []byte { data[0] = 'X'; return data }, ErrBadRDBFormat},
+		{"too short", func(data []byte) []byte { return data[:4] }, ErrBadRDBFormat},
+		{"unknown version", func(data []byte) []byte { copy(data[len(rdbMagic):], "9999"); return data }, ErrBadRDBVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.mutate(append([]byte(nil), original...))
+			require.NoError(t, os.WriteFile(path, data, 0o644))
+
+			loaded, _ := newTestRDB(t, dir)
+			assert.ErrorIs(t, loaded.Load(), tt.err)
+		})
+	}
+}
+
+func TestRDB_BGSave(t *testing.T) {
+	dir := t.TempDir()
+	rdb, store := newTestRDB(t, dir)
+	store.Set("key", "before")
+	rdb.AddDirty(1)
+
+	require.NoError(t, rdb.BGSave())
+	assert.ErrorIs(t, rdb.BGSave(), ErrBgsaveInProgress)
+	assert.ErrorIs(t, rdb.Save(), ErrBgsaveInProgress)
+
+	// Writes after BGSAVE started are not part of the snapshot
+	store.Set("key", "after")
+	rdb.AddDirty(1)
+
+	rdb.finishBGSave(<-rdb.bgsaveDone)
+	assert.Nil(t, rdb.bgsaveDone)
+	assert.Equal(t, int64(1), rdb.dirty)
+
+	loaded, loadedStore := newTestRDB(t, dir)
+	require.NoError(t, loaded.Load())
+	value, _ := loadedStore.Get("key")
+	assert.Equal(t, "before", *value)
+}
+
+func TestRDB_CronAppliesSaveRules(t *testing.T) {
+	dir := t.TempDir()
+	rdb, store := newTestRDB(t, dir)
+	rdb.config.SaveRules = []config.SaveRule{{Seconds: 60, Changes: 2}}
+	store.Set("key", "value")
+
+	rdb.AddDirty(1)
+	rdb.lastSave = time.Now().Add(-time.Hour)
+	rdb.Cron()
+	assert.Nil(t, rdb.bgsaveDone, "not enough changes")
+
+	rdb.AddDirty(1)
+	rdb.lastSave = time.Now()
+	rdb.Cron()
+	assert.Nil(t, rdb.bgsaveDone, "not enough time elapsed")
+
+	rdb.lastSave = time.Now().Add(-time.Minute)
+	rdb.Cron()
+	require.NotNil(t, rdb.bgsaveDone)
+
+	require.Eventually(t, func() bool {
+		rdb.Cron()
+		return rdb.bgsaveDone == nil
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int64(0), rdb.dirty)
+	assert.FileExists(t, filepath.Join(dir, "dump.rdb"))
+}
+
+func TestRDB_CronDelaysRetryAfterFailure(t *testing.T) {
+	rdb, _ := newTestRDB(t, filepath.Join(t.TempDir(), "missing"))
+	rdb.config.SaveRules = []config.SaveRule{{Seconds: 1, Changes: 1}}
+	rdb.AddDirty(1)
+	rdb.lastSave = time.Now().Add(-time.Minute)
+
+	rdb.Cron()
+	require.NotNil(t, rdb.bgsaveDone)
+	rdb.finishBGSave(<-rdb.bgsaveDone)
+	require.Error(t, rdb.lastBgsaveErr)
+
+	rdb.Cron()
+	assert.Nil(t, rdb.bgsaveDone, "retry must wait")
+
+	rdb.lastBgsaveTime = time.Now().Add(-bgsaveRetryDelay)
+	rdb.Cron()
+	assert.NotNil(t, rdb.bgsaveDone)
+	rdb.finishBGSave(<-rdb.bgsaveDone)
+}
+
+func TestRDB_ShutdownSavesWhenRulesConfigured(t *testing.T) {
+	dir := t.TempDir()
+	rdb, store := newTestRDB(t, dir)
+	store.Set("key", "value")
+	require.NoError(t, rdb.Shutdown())
+	assert.FileExists(t, filepath.Join(dir, "dump.rdb"))
+
+	dir = t.TempDir()
+	rdb, _ = newTestRDB(t, dir)
+	rdb.config.SaveRules = nil
+	require.NoError(t, rdb.Shutdown())
+	assert.NoFileExists(t, filepath.Join(dir, "dump.rdb"))
+}
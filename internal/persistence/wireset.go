@@ -0,0 +1,8 @@
+package persistence
+
+import "github.com/google/wire"
+
+var WireSet = wire.NewSet(
+	NewRDB,
+	NewAOF,
+)
@@ -14,8 +14,13 @@ var (
 
 // Error responses
 var (
-	RespWrongTypeOperation = []byte("-WRONGTYPE Operation against a key holding the wrong kind of value\r\n")
-	RespSyntaxError        = []byte("-syntax error\r\n")
+	RespWrongTypeOperation   = []byte("-WRONGTYPE Operation against a key holding the wrong kind of value\r\n")
+	RespSyntaxError          = []byte("-syntax error\r\n")
+	RespInvalidCursor        = []byte("-ERR invalid cursor\r\n")
+	RespSameObject           = []byte("-ERR source and destination objects are the same\r\n")
+	RespDBIndexOutOfRange    = []byte("-ERR DB index is out of range\r\n")
+	RespInvalidFirstDBIndex  = []byte("-ERR invalid first DB index\r\n")
+	RespInvalidSecondDBIndex = []byte("-ERR invalid second DB index\r\n")
 )
 
 // Expire command responses
@@ -29,8 +34,10 @@ var (
 var (
 	RespValueNotIntegerOrOutOfRange = []byte("-value is not an integer or out of range\r\n")
 	RespValueOutOfRangeMustPositive = []byte("-value is out of range, must be positive\r\n")
+	RespValueOutOfRange             = []byte("-ERR value is out of range\r\n")
 	RespValueNotValidFloat          = []byte("-value is not a valid float\r\n")
 	RespMinOrMaxNotFloat            = []byte("-min or max is not a float\r\n")
+	RespMinOrMaxNotValidStringRange = []byte("-ERR min or max not valid string range item\r\n")
 )
 
 // ZSet errors
@@ -38,6 +45,8 @@ var (
 	RespXXNXNotCompatible           = []byte("-XX and NX options at the same time are not compatible\r\n")
 	RespGTLTNXNotCompatible         = []byte("-GT, LT, and/or NX options at the same time are not compatible\r\n")
 	RespWithScoresNotSupportedByLex = []byte("-syntax error, WITHSCORES not supported in combination with BYLEX\r\n")
+	RespWeightNotFloat              = []byte("-ERR weight value is not a float\r\n")
+	RespLimitNotSupportedByRank     = []byte("-ERR syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX\r\n")
 )
 
 // Geo errors
@@ -83,6 +92,88 @@ var (
 	RespErrNoSuchKey = []byte("-ERR no such key\r\n")
 )
 
+// String errors
+var (
+	RespOffsetOutOfRange = []byte("-ERR offset is out of range\r\n")
+	RespLCSLenAndIdx     = []byte("-ERR If you want both the length and indexes, please just use IDX.\r\n")
+)
+
+// List errors
+var (
+	RespLPosRankZero       = []byte("-ERR RANK can't be zero: use 1 to start from the first match, 2 from the second ... or use negative to start from the end of the list\r\n")
+	RespLPosCountNegative  = []byte("-ERR COUNT can't be negative\r\n")
+	RespLPosMaxLenNegative = []byte("-ERR MAXLEN can't be negative\r\n")
+	RespNumKeysNotPositive = []byte("-ERR numkeys should be greater than 0\r\n")
+	RespNumKeysExceedArgs  = []byte("-ERR Number of keys can't be greater than number of args\r\n")
+	RespCountNotPositive   = []byte("-ERR count should be greater than 0\r\n")
+)
+
+// Set errors
+var (
+	RespLimitNegative = []byte("-ERR LIMIT can't be negative\r\n")
+)
+
+// Bitmap errors
+var (
+	RespBitOffsetOutOfRange       = []byte("-ERR bit offset is not an integer or out of range\r\n")
+	RespBitNotIntegerOrOutOfRange = []byte("-ERR bit is not an integer or out of range\r\n")
+	RespBitArgumentNotBinary      = []byte("-ERR The bit argument must be 1 or 0.\r\n")
+	RespBitOpNotSingleSource      = []byte("-ERR BITOP NOT must be called with a single source key.\r\n")
+	RespInvalidBitFieldType       = []byte("-ERR Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is.\r\n")
+	RespInvalidOverflowType       = []byte("-ERR Invalid OVERFLOW type specified\r\n")
+	RespBitFieldROOnlyGet         = []byte("-ERR BITFIELD_RO only supports the GET subcommand\r\n")
+)
+
+// Hash errors
+var (
+	RespHashFieldsMissing        = []byte("-ERR Mandatory argument FIELDS is missing or not at the right position\r\n")
+	RespHashNumFieldsNotPositive = []byte("-ERR Number of fields must be a positive integer\r\n")
+	RespHashNumFieldsMismatch    = []byte("-ERR The `numfields` parameter must match the number of arguments\r\n")
+)
+
+// Persistence responses
+var (
+	RespBgsaveStarted       = []byte("+Background saving started\r\n")
+	RespBgrewriteaofStarted = []byte("+Background append only file rewriting started\r\n")
+)
+
+// Transaction responses
+var (
+	RespQueued              = []byte("+QUEUED\r\n")
+	RespNilArray            = []byte("*-1\r\n")
+	RespExecAbort           = []byte("-EXECABORT Transaction discarded because of previous errors.\r\n")
+	RespMultiNested         = []byte("-ERR MULTI calls can not be nested\r\n")
+	RespExecWithoutMulti    = []byte("-ERR EXEC without MULTI\r\n")
+	RespDiscardWithoutMulti = []byte("-ERR DISCARD without MULTI\r\n")
+	RespWatchInsideMulti    = []byte("-ERR WATCH inside MULTI is not allowed\r\n")
+	RespNotAllowedInMulti   = []byte("-ERR Command not allowed inside a transaction\r\n")
+)
+
+// Blocking command errors
+var (
+	RespTimeoutNotFloat = []byte("-ERR timeout is not a float or out of range\r\n")
+	RespTimeoutNegative = []byte("-ERR timeout is negative\r\n")
+)
+
+// Stream errors
+var (
+	RespStreamMaxLenNegative     = []byte("-ERR The MAXLEN argument must be >= 0.\r\n")
+	RespStreamLimitNegative      = []byte("-ERR The LIMIT argument must be >= 0.\r\n")
+	RespStreamLimitWithoutApprox = []byte("-ERR syntax error, LIMIT cannot be used without the special ~ option\r\n")
+	RespStreamInvalidStartID     = []byte("-ERR invalid start ID for the interval\r\n")
+	RespStreamInvalidEndID       = []byte("-ERR invalid end ID for the interval\r\n")
+	RespStreamTimeoutNotInteger  = []byte("-ERR timeout is not an integer or out of range\r\n")
+	RespStreamUnbalancedXRead    = []byte("-ERR Unbalanced 'xread' list of streams: for each stream key an ID or '$' must be specified.\r\n")
+	RespStreamUnbalancedXGroup   = []byte("-ERR Unbalanced 'xreadgroup' list of streams: for each stream key an ID or '>' must be specified.\r\n")
+	RespStreamDollarInGroup      = []byte("-ERR The $ ID is meaningful only in the context of XREAD command\r\n")
+	RespStreamGreaterNeedsGroup  = []byte("-ERR The > ID can be specified only when calling XREADGROUP using the GROUP <group> <consumer> option.\r\n")
+	RespStreamMissingGroup       = []byte("-ERR Missing GROUP option for XREADGROUP\r\n")
+	RespStreamKeyRequired        = []byte("-ERR The XGROUP subcommand requires the key to exist. Note that for CREATE you may want to use the MKSTREAM option to create an empty stream automatically.\r\n")
+	RespStreamEntriesReadInvalid = []byte("-ERR value for ENTRIESREAD must be positive or -1\r\n")
+	RespStreamCountNotPositive   = []byte("-ERR COUNT must be > 0\r\n")
+	RespStreamEmptyPending       = []byte("*4\r\n:0\r\n$-1\r\n$-1\r\n*-1\r\n")
+)
+
 // TTL constants
 const (
 	NoExpire      int64 = -1
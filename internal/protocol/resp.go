@@ -28,8 +28,14 @@ var CRLF = []byte{CR, LF}
 var (
 	ErrInvalidRESP = errors.New("invalid RESP")
 	ErrIncomplete  = errors.New("incomplete RESP frame")
+
+	ErrInvalidBulkLength      = errors.New("ERR Protocol error: invalid bulk length")
+	ErrInvalidMultibulkLength = errors.New("ERR Protocol error: invalid multibulk length")
 )
 
+// MaxMultibulkLen is the largest number of arguments a command frame may announce.
+const MaxMultibulkLen = math.MaxInt32
+
 var NilBulkString = struct{}{}
 
 /** +OK\r\n -> OK, 5 */
@@ -49,7 +55,7 @@ func readInt64(data []byte) (int64, int, error) {
 	sign := int64(1)
 
 	if idx >= len(data) {
-		return 0, 0, fmt.Errorf("unexpected end")
+		return 0, 0, ErrIncomplete
 	}
 
 	switch data[idx] {
@@ -60,7 +66,11 @@ func readInt64(data []byte) (int64, int, error) {
 		idx++
 	}
 
-	if idx >= len(data) || data[idx] < '0' || data[idx] > '9' {
+	if idx >= len(data) {
+		return 0, 0, ErrIncomplete
+	}
+
+	if data[idx] < '0' || data[idx] > '9' {
 		return 0, 0, fmt.Errorf("invalid integer format")
 	}
 
@@ -79,7 +89,12 @@ func readInt64(data []byte) (int64, int, error) {
 		idx++
 	}
 
-	if idx+1 >= len(data) || data[idx] != '\r' || data[idx+1] != '\n' {
+	// The terminator has not arrived yet
+	if idx+1 >= len(data) {
+		return 0, 0, ErrIncomplete
+	}
+
+	if data[idx] != '\r' || data[idx+1] != '\n' {
 		return 0, 0, fmt.Errorf("missing CRLF")
 	}
 
@@ -97,11 +112,17 @@ func readBulkString(data []byte) (interface{}, int, error) {
 		return nil, idx, nil // NULL bulk string
 	}
 
-	strLen := int(strLen64)
-	if idx+strLen+2 > len(data) {
+	if strLen64 < -1 {
+		return nil, 0, ErrInvalidRESP
+	}
+
+	// Compared before converting so that a huge length cannot overflow the sum
+	if strLen64 > int64(len(data)-idx-2) {
 		return nil, 0, ErrIncomplete
 	}
 
+	strLen := int(strLen64)
+
 	if data[idx+strLen] != CR || data[idx+strLen+1] != LF {
 		return nil, 0, ErrInvalidRESP
 	}
@@ -120,8 +141,14 @@ func readArray(data []byte) (interface{}, int, error) {
 		return nil, idx, nil // NULL array
 	}
 
+	if count64 < -1 {
+		return nil, 0, ErrInvalidRESP
+	}
+
+	// Every element takes at least one byte, so the announced count cannot be
+	// trusted for more than what has arrived
 	count := int(count64)
-	res := make([]interface{}, count)
+	res := make([]interface{}, 0, min(count64, int64(len(data)-idx)))
 
 	for i := 0; i < count; i++ {
 		if idx >= len(data) {
@@ -133,7 +160,7 @@ func readArray(data []byte) (interface{}, int, error) {
 			return nil, 0, err
 		}
 
-		res[i] = val
+		res = append(res, val)
 		idx += consumed
 	}
 
@@ -264,26 +291,34 @@ func encodeArray[T any](arr []T) []byte {
 }
 
 func ParseCmd(data []byte) (*RedisCmd, error) {
-	val, _, err := DecodeResp(data)
+	cmd, _, err := ReadCmd(data)
+	return cmd, err
+}
+
+// ReadCmd parses the first command frame in data and returns the number of bytes it occupies.
+// ErrIncomplete is returned when data only holds a prefix of the frame, so the caller can
+// keep the bytes and retry once more input has arrived.
+func ReadCmd(data []byte) (*RedisCmd, int, error) {
+	val, consumed, err := DecodeResp(data)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	arr, ok := val.([]interface{})
 	if !ok || len(arr) == 0 {
-		return nil, ErrInvalidRESP
+		return nil, 0, ErrInvalidRESP
 	}
 
 	cmd, ok := arr[0].(string)
 	if !ok {
-		return nil, ErrInvalidRESP
+		return nil, 0, ErrInvalidRESP
 	}
 
 	args := make([]string, len(arr)-1)
 	for i := 1; i < len(arr); i++ {
 		s, ok := arr[i].(string)
 		if !ok {
-			return nil, ErrInvalidRESP
+			return nil, 0, ErrInvalidRESP
 		}
 		args[i-1] = s
 	}
@@ -291,5 +326,5 @@ func ParseCmd(data []byte) (*RedisCmd, error) {
 	return &RedisCmd{
 		Cmd:  strings.ToUpper(cmd),
 		Args: args,
-	}, nil
+	}, consumed, nil
 }
@@ -349,3 +349,115 @@ func TestParseCmd_NonStringArg(t *testing.T) {
 	_, err := ParseCmd([]byte("*2\r\n$3\r\nGET\r\n:1\r\n"))
 	assert.Error(t, err)
 }
+
+func TestReadCmd_ReturnsConsumedBytes(t *testing.T) {
+	input := "*2\r\n$3\r\nGET\r\n$1\r\na\r\n"
+	cmd, consumed, err := ReadCmd([]byte(input + "*1\r\n$4\r\nPING\r\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "GET", cmd.Cmd)
+	assert.Equal(t, []string{"a"}, cmd.Args)
+	assert.Equal(t, len(input), consumed)
+}
+
+func TestReadCmd_Pipelined(t *testing.T) {
+	data := []byte("*1\r\n$4\r\nPING\r\n*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$1\r\nv\r\n*2\r\n$3\r\nGET\r\n$1\r\nk\r\n")
+
+	var names []string
+	for len(data) > 0 {
+		cmd, consumed, err := ReadCmd(data)
+		require.NoError(t, err)
+		names = append(names, cmd.Cmd)
+		data = data[consumed:]
+	}
+
+	assert.Equal(t, []string{"PING", "SET", "GET"}, names)
+}
+
+func TestReadCmd_IncompleteAtEveryPrefix(t *testing.T) {
+	frame := []byte("*3\r\n$3\r\nSET\r\n$5\r\nhello\r\n$10\r\n0123456789\r\n")
+
+	for i := 1; i < len(frame); i++ {
+		_, _, err := ReadCmd(frame[:i])
+		assert.ErrorIs(t, err, ErrIncomplete, "prefix of length %d", i)
+	}
+
+	cmd, consumed, err := ReadCmd(frame)
+	require.NoError(t, err)
+	assert.Equal(t, len(frame), consumed)
+	assert.Equal(t, []string{"hello", "0123456789"}, cmd.Args)
+}
+
+func TestReadCmd_NegativeLength(t *testing.T) {
+	_, _, err := ReadCmd([]byte("*1\r\n$-5\r\n"))
+	assert.ErrorIs(t, err, ErrInvalidRESP)
+
+	_, _, err = ReadCmd([]byte("*-3\r\n"))
+	assert.ErrorIs(t, err, ErrInvalidRESP)
+}
+
+func TestReadInt64_Incomplete(t *testing.T) {
+	for _, input := range []string{":", ":-", ":12", ":12\r"} {
+		_, _, err := readInt64([]byte(input))
+		assert.ErrorIs(t, err, ErrIncomplete, input)
+	}
+}
+
+func TestFrameScanner_GrowingFrame(t *testing.T) {
+	frame := []byte("*3\r\n$3\r\nSET\r\n$5\r\nhello\r\n$10\r\n0123456789\r\n")
+
+	var fs FrameScanner
+	for i := 1; i < len(frame); i++ {
+		complete, err := fs.Complete(frame[:i])
+		require.NoError(t, err)
+		assert.False(t, complete, "prefix of length %d", i)
+	}
+	complete, err := fs.Complete(frame)
+	require.NoError(t, err)
+	assert.True(t, complete)
+
+	fs.Reset()
+	complete, err = fs.Complete(frame)
+	require.NoError(t, err)
+	assert.True(t, complete)
+}
+
+func TestFrameScanner_DefersToParser(t *testing.T) {
+	cases := []string{
+		"+OK\r\n",
+		"*1\r\n:1\r\n",
+		"*1\r\n$-1\r\n",
+		"*x\r\n",
+	}
+
+	for _, input := range cases {
+		var fs FrameScanner
+		complete, err := fs.Complete([]byte(input))
+		require.NoError(t, err)
+		assert.True(t, complete, input)
+	}
+}
+
+func TestFrameScanner_RejectsOversizedLengths(t *testing.T) {
+	fs := FrameScanner{MaxBulkLen: 512 * 1024 * 1024}
+	_, err := fs.Complete([]byte("*2\r\n$3\r\nGET\r\n$9999999999\r\n"))
+	assert.ErrorIs(t, err, ErrInvalidBulkLength)
+
+	fs.Reset()
+	_, err = fs.Complete([]byte("*9999999999\r\n"))
+	assert.ErrorIs(t, err, ErrInvalidMultibulkLength)
+
+	// Without a limit a huge length must not overflow into a complete frame
+	fs = FrameScanner{}
+	complete, err := fs.Complete([]byte("*1\r\n$9223372036854775807\r\nab\r\n"))
+	require.NoError(t, err)
+	assert.False(t, complete)
+}
+
+func TestReadCmd_HugeLengths(t *testing.T) {
+	_, _, err := ReadCmd([]byte("*1\r\n$9223372036854775807\r\nab\r\n"))
+	assert.ErrorIs(t, err, ErrIncomplete)
+
+	_, _, err = ReadCmd([]byte("*1\r\n*2147483647\r\n"))
+	assert.ErrorIs(t, err, ErrIncomplete)
+}
@@ -0,0 +1,88 @@
+package protocol
+
+import "errors"
+
+// FrameScanner incrementally checks whether a command frame has fully arrived.
+// Large frames reach the server over many reads; remembering how far the frame has
+// been validated avoids decoding it from the start after every read.
+//
+// The scanner only understands arrays of bulk strings, which is how clients send
+// commands. Anything else is reported as complete so that ReadCmd can decode it
+// (or reject it) as usual.
+//
+// Like Redis, the scanner rejects an announced bulk length above MaxBulkLen or an
+// argument count above MaxMultibulkLen as soon as it is read, so that a client cannot
+// make the server buffer a frame that would never be accepted.
+type FrameScanner struct {
+	MaxBulkLen int64 // largest accepted bulk string length, 0 for no limit
+
+	offset  int // bytes at the start of the frame that have been validated
+	pending int // array elements that still have to be validated
+}
+
+// Complete reports whether data, which must begin at the start of the frame, holds the
+// whole frame. data may only grow between calls until Reset is invoked. An error is
+// returned when the frame announces a length the server refuses to read.
+func (fs *FrameScanner) Complete(data []byte) (bool, error) {
+	if fs.offset == 0 {
+		if len(data) == 0 {
+			return false, nil
+		}
+
+		if RespType(data[0]) != RespArray {
+			return true, nil
+		}
+
+		count, consumed, err := readInt64(data)
+		if err != nil {
+			return !errors.Is(err, ErrIncomplete), nil
+		}
+
+		if count > MaxMultibulkLen {
+			return false, ErrInvalidMultibulkLength
+		}
+
+		fs.offset = consumed
+		fs.pending = int(count)
+	}
+
+	for fs.pending > 0 {
+		rest := data[fs.offset:]
+		if len(rest) == 0 {
+			return false, nil
+		}
+
+		if RespType(rest[0]) != RespBulkString {
+			return true, nil
+		}
+
+		strLen, consumed, err := readInt64(rest)
+		if err != nil {
+			return !errors.Is(err, ErrIncomplete), nil
+		}
+
+		if strLen < 0 {
+			return true, nil
+		}
+
+		if fs.MaxBulkLen > 0 && strLen > fs.MaxBulkLen {
+			return false, ErrInvalidBulkLength
+		}
+
+		// Compared before converting so that a huge length cannot overflow the sum
+		if strLen > int64(len(rest)-consumed-2) {
+			return false, nil
+		}
+
+		fs.offset += consumed + int(strLen) + 2
+		fs.pending--
+	}
+
+	return true, nil
+}
+
+// Reset prepares the scanner for the next frame.
+func (fs *FrameScanner) Reset() {
+	fs.offset = 0
+	fs.pending = 0
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/manhhung2111/go-redis/internal/protocol"
+)
+
+func queueCommands(c *client, cmds ...protocol.RedisCmd) {
+	for i := range cmds {
+		c.pendingCmds = append(c.pendingCmds, &cmds[i])
+	}
+}
+
+func TestBlockedClientHoldsPipelinedCommands(t *testing.T) {
+	s, loop, _, _ := newTestServer(t)
+	waiter := addTestClient(s, 100)
+	pusher := addTestClient(s, 101)
+
+	queueCommands(waiter, cmd("BLPOP", "q", "0"), cmd("LLEN", "q"), cmd("PING"))
+	s.processCommands(waiter)
+	assert.Empty(t, waiter.replyBuf)
+	assert.Len(t, waiter.pendingCmds, 2)
+	assert.Contains(t, s.blockedClients, waiter.state)
+
+	queueCommands(pusher, cmd("RPUSH", "q", "x", "y"))
+	s.processCommands(pusher)
+	s.handleUnblockedClients()
+
+	assert.Equal(t, ":2\r\n", string(pusher.replyBuf))
+	assert.Equal(t, "*2\r\n$1\r\nq\r\n$1\r\nx\r\n:1\r\n+PONG\r\n", string(waiter.replyBuf))
+	assert.Empty(t, waiter.pendingCmds)
+	assert.NotContains(t, s.blockedClients, waiter.state)
+	assert.True(t, loop.writable[100])
+}
+
+func TestBlockedClientTimesOutOnTimer(t *testing.T) {
+	s, loop, _, _ := newTestServer(t)
+	c := addTestClient(s, 100)
+
+	queueCommands(c, cmd("BRPOP", "q", "0.01"))
+	s.processCommands(c)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.NoError(t, s.handleEvent(Event{IsTimer: true}))
+
+	assert.Equal(t, protocol.RespNilArray, c.replyBuf)
+	assert.NotContains(t, s.blockedClients, c.state)
+	assert.True(t, loop.writable[100])
+}
+
+func TestClosedBlockedClientIsForgotten(t *testing.T) {
+	s, _, _, _ := newTestServer(t)
+	waiter := addTestClient(s, 100)
+	pusher := addTestClient(s, 101)
+
+	queueCommands(waiter, cmd("BLPOP", "q", "0"))
+	s.processCommands(waiter)
+	s.closeClient(waiter)
+	assert.Empty(t, s.blockedClients)
+
+	queueCommands(pusher, cmd("RPUSH", "q", "x"), cmd("LLEN", "q"))
+	s.processCommands(pusher)
+	s.handleUnblockedClients()
+
+	assert.Equal(t, ":1\r\n:1\r\n", string(pusher.replyBuf))
+	assert.Empty(t, waiter.replyBuf)
+}
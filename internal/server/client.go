@@ -0,0 +1,126 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/manhhung2111/go-redis/internal/command"
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+)
+
+// Buffers that grew beyond this size are released once fully consumed,
+// so a single large request or reply does not pin memory for the lifetime of the connection.
+const bufferShrinkThreshold = 64 * 1024
+
+type client struct {
+	fd       int
+	queryBuf []byte                // bytes received from the socket that have not been parsed yet
+	scanner  protocol.FrameScanner // progress on the partial frame at the head of queryBuf
+
+	replyBuf        []byte // encoded replies, replyBuf[replyPos:] has not been written to the socket yet
+	replyPos        int
+	writable        bool // whether the event loop is watching the socket for writability
+	closeAfterReply bool // close the connection once replyBuf is drained
+
+	state       *command.Client      // transaction and other per-connection state of the command layer
+	pendingCmds []*protocol.RedisCmd // parsed commands waiting for a blocking command to be served
+	protocolErr error                // malformed frame that follows pendingCmds
+
+	channels map[string]struct{} // channels subscribed with SUBSCRIBE
+	patterns map[string]struct{} // patterns subscribed with PSUBSCRIBE
+}
+
+func newClient(fd int, cfg *config.Config) *client {
+	return &client{
+		fd:      fd,
+		scanner: protocol.FrameScanner{MaxBulkLen: cfg.ProtoMaxBulkLen},
+		state:   command.NewClient(),
+	}
+}
+
+// readCommands parses every complete command frame in the query buffer and keeps
+// a trailing partial frame for the next read. A non-nil error means the buffer holds
+// a malformed frame and the connection can no longer be trusted.
+func (c *client) readCommands() ([]*protocol.RedisCmd, error) {
+	cmds := make([]*protocol.RedisCmd, 0)
+	pos := 0
+
+	for pos < len(c.queryBuf) {
+		complete, err := c.scanner.Complete(c.queryBuf[pos:])
+		if err != nil {
+			c.scanner.Reset()
+			c.queryBuf = nil
+			return cmds, err
+		}
+		if !complete {
+			break
+		}
+
+		cmd, consumed, err := protocol.ReadCmd(c.queryBuf[pos:])
+		if errors.Is(err, protocol.ErrIncomplete) {
+			break
+		}
+		c.scanner.Reset()
+		if err != nil {
+			c.queryBuf = nil
+			return cmds, err
+		}
+
+		cmds = append(cmds, cmd)
+		pos += consumed
+	}
+
+	c.consumeQueryBuffer(pos)
+	return cmds, nil
+}
+
+func (c *client) consumeQueryBuffer(n int) {
+	if n == 0 {
+		return
+	}
+
+	if n == len(c.queryBuf) {
+		if cap(c.queryBuf) > bufferShrinkThreshold {
+			c.queryBuf = nil
+		} else {
+			c.queryBuf = c.queryBuf[:0]
+		}
+		return
+	}
+
+	// Move the partial frame to the front so the backing array can be reused
+	remaining := copy(c.queryBuf, c.queryBuf[n:])
+	c.queryBuf = c.queryBuf[:remaining]
+}
+
+// subscriptionCount returns the number of channels and patterns the client subscribed to.
+// While it is not zero, the client is in subscribed mode and only accepts a few commands.
+func (c *client) subscriptionCount() int {
+	return len(c.channels) + len(c.patterns)
+}
+
+func (c *client) addReply(reply []byte) {
+	c.replyBuf = append(c.replyBuf, reply...)
+}
+
+func (c *client) hasPendingReplies() bool {
+	return c.replyPos < len(c.replyBuf)
+}
+
+func (c *client) pendingReplies() []byte {
+	return c.replyBuf[c.replyPos:]
+}
+
+func (c *client) consumeReplyBuffer(n int) {
+	c.replyPos += n
+	if c.replyPos < len(c.replyBuf) {
+		return
+	}
+
+	c.replyPos = 0
+	if cap(c.replyBuf) > bufferShrinkThreshold {
+		c.replyBuf = nil
+	} else {
+		c.replyBuf = c.replyBuf[:0]
+	}
+}
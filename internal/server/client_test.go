@@ -0,0 +1,96 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/manhhung2111/go-redis/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCommands_KeepsPartialFrame(t *testing.T) {
+	c := newClient(0, config.NewConfig())
+
+	c.queryBuf = append(c.queryBuf, "*1\r\n$4\r\nPING\r\n*2\r\n$3\r\nGET"...)
+	cmds, err := c.readCommands()
+	require.NoError(t, err)
+	require.Len(t, cmds, 1)
+	assert.Equal(t, "PING", cmds[0].Cmd)
+	assert.Equal(t, "*2\r\n$3\r\nGET", string(c.queryBuf))
+
+	c.queryBuf = append(c.queryBuf, "\r\n$1\r\nk\r\n"...)
+	cmds, err = c.readCommands()
+	require.NoError(t, err)
+	require.Len(t, cmds, 1)
+	assert.Equal(t, "GET", cmds[0].Cmd)
+	assert.Equal(t, []string{"k"}, cmds[0].Args)
+	assert.Empty(t, c.queryBuf)
+}
+
+func TestReadCommands_Pipeline(t *testing.T) {
+	c := newClient(0, config.NewConfig())
+
+	for i := 0; i < 1000; i++ {
+		c.queryBuf = append(c.queryBuf, "*4\r\n$4\r\nHSET\r\n$1\r\nh\r\n$1\r\nf\r\n$1\r\nv\r\n"...)
+	}
+
+	cmds, err := c.readCommands()
+	require.NoError(t, err)
+	assert.Len(t, cmds, 1000)
+	assert.Empty(t, c.queryBuf)
+}
+
+func TestReadCommands_LargeBulkString(t *testing.T) {
+	c := newClient(0, config.NewConfig())
+	value := make([]byte, 100_000)
+	for i := range value {
+		value[i] = 'x'
+	}
+
+	frame := append([]byte("*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$100000\r\n"), value...)
+	frame = append(frame, "\r\n"...)
+
+	// Deliver the frame in socket-sized chunks
+	var cmds int
+	for start := 0; start < len(frame); start += readBufferSize {
+		end := min(start+readBufferSize, len(frame))
+		c.queryBuf = append(c.queryBuf, frame[start:end]...)
+
+		parsed, err := c.readCommands()
+		require.NoError(t, err)
+		for _, cmd := range parsed {
+			assert.Equal(t, "SET", cmd.Cmd)
+			assert.Len(t, cmd.Args[1], 100_000)
+		}
+		cmds += len(parsed)
+	}
+
+	assert.Equal(t, 1, cmds)
+	assert.Empty(t, c.queryBuf)
+}
+
+func TestReadCommands_ProtocolError(t *testing.T) {
+	c := newClient(0, config.NewConfig())
+	c.queryBuf = append(c.queryBuf, "*1\r\n$4\r\nPING\r\n?garbage\r\n"...)
+
+	cmds, err := c.readCommands()
+	assert.Error(t, err)
+	assert.Len(t, cmds, 1)
+	assert.Empty(t, c.queryBuf)
+}
+
+func TestReadCommands_OversizedLengths(t *testing.T) {
+	for _, frame := range []string{
+		"*1\r\n$4\r\nPING\r\n*2\r\n$3\r\nGET\r\n$9999999999\r\n",
+		"*1\r\n$4\r\nPING\r\n*9999999999\r\n",
+	} {
+		c := newClient(0, config.NewConfig())
+		c.queryBuf = append(c.queryBuf, frame...)
+
+		cmds, err := c.readCommands()
+		assert.Error(t, err, frame)
+		assert.Len(t, cmds, 1)
+		assert.Empty(t, c.queryBuf)
+	}
+}
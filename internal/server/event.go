@@ -4,6 +4,7 @@ type Event struct {
 	Fd      int
 	IsTimer bool
 	IsRead  bool
+	IsWrite bool
 	IsError bool
 }
 
@@ -16,6 +17,10 @@ type EventLoop interface {
 
 	RegisterClientSocket(fd int) error
 
+	// ModifyClientSocket toggles interest in writability for a registered client socket.
+	// Read interest is always kept.
+	ModifyClientSocket(fd int, writable bool) error
+
 	RegisterTimer(intervalMs int) error
 
 	Wait(maxEvents int) ([]Event, error)
@@ -55,6 +55,23 @@ func (e *EpollEventLoop) RegisterClientSocket(fd int) error {
 	return nil
 }
 
+func (e *EpollEventLoop) ModifyClientSocket(fd int, writable bool) error {
+	events := uint32(unix.EPOLLIN)
+	if writable {
+		events |= unix.EPOLLOUT
+	}
+
+	event := unix.EpollEvent{
+		Events: events,
+		Fd:     int32(fd),
+	}
+
+	if err := unix.EpollCtl(e.epollFd, unix.EPOLL_CTL_MOD, fd, &event); err != nil {
+		return fmt.Errorf("failed to modify client socket with epoll: %w", err)
+	}
+	return nil
+}
+
 func (e *EpollEventLoop) RegisterTimer(intervalMs int) error {
 	timerFd, err := unix.TimerfdCreate(unix.CLOCK_MONOTONIC, unix.TFD_NONBLOCK|unix.TFD_CLOEXEC)
 	if err != nil {
@@ -125,6 +142,7 @@ func (e *EpollEventLoop) Wait(maxEvents int) ([]Event, error) {
 			Fd:      fd,
 			IsTimer: isTimer,
 			IsRead:  ev.Events&unix.EPOLLIN != 0,
+			IsWrite: ev.Events&unix.EPOLLOUT != 0,
 			IsError: ev.Events&(unix.EPOLLERR|unix.EPOLLHUP) != 0,
 		}
 	}
@@ -56,6 +56,25 @@ func (k *KqueueEventLoop) RegisterClientSocket(fd int) error {
 	return nil
 }
 
+func (k *KqueueEventLoop) ModifyClientSocket(fd int, writable bool) error {
+	var flags uint16 = syscall.EV_DELETE
+	if writable {
+		flags = syscall.EV_ADD
+	}
+
+	event := syscall.Kevent_t{
+		Ident:  uint64(fd),
+		Filter: syscall.EVFILT_WRITE,
+		Flags:  flags,
+	}
+
+	_, err := syscall.Kevent(k.kqueueFd, []syscall.Kevent_t{event}, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to modify client socket with kqueue: %w", err)
+	}
+	return nil
+}
+
 func (k *KqueueEventLoop) RegisterTimer(intervalMs int) error {
 	timerEvent := syscall.Kevent_t{
 		Ident:  kqueueTimerIdent,
@@ -97,6 +116,7 @@ func (k *KqueueEventLoop) Wait(maxEvents int) ([]Event, error) {
 			Fd:      fd,
 			IsTimer: isTimer,
 			IsRead:  ev.Filter == syscall.EVFILT_READ,
+			IsWrite: ev.Filter == syscall.EVFILT_WRITE,
 			IsError: ev.Flags&syscall.EV_ERROR != 0,
 		}
 	}
@@ -0,0 +1,267 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/manhhung2111/go-redis/internal/errors"
+	"github.com/manhhung2111/go-redis/internal/glob"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+)
+
+// pubSub indexes the subscribers of every channel and pattern. Subscriptions belong to
+// connections, so unlike the rest of the commands they are handled by the server.
+type pubSub struct {
+	channels map[string]map[*client]struct{}
+	patterns map[string]map[*client]struct{}
+}
+
+func newPubSub() *pubSub {
+	return &pubSub{
+		channels: make(map[string]map[*client]struct{}),
+		patterns: make(map[string]map[*client]struct{}),
+	}
+}
+
+// Commands a client may still send once it subscribed to a channel or a pattern
+var subscribedModeCommands = map[string]struct{}{
+	"SUBSCRIBE": {}, "UNSUBSCRIBE": {}, "PSUBSCRIBE": {}, "PUNSUBSCRIBE": {}, "PING": {},
+}
+
+type subscriptionHandler func(c *client, cmd protocol.RedisCmd) []byte
+
+// registerPubSubCommands sets up the subscription handlers of the server and adds the
+// commands that do not depend on the calling connection to the command layer.
+func (s *Server) registerPubSubCommands() {
+	s.subscriptionHandlers = map[string]subscriptionHandler{
+		"SUBSCRIBE":    s.subscribe,
+		"UNSUBSCRIBE":  s.unsubscribe,
+		"PSUBSCRIBE":   s.psubscribe,
+		"PUNSUBSCRIBE": s.punsubscribe,
+	}
+
+	s.redis.RegisterCommand("PUBLISH", 3, s.publish)
+	s.redis.RegisterCommand("PUBSUB", -2, s.pubSubCommand)
+}
+
+// execute runs a command on behalf of c. Subscriptions are handled here, everything
+// else, including PUBLISH and PUBSUB, goes through the command layer.
+func (s *Server) execute(c *client, cmd protocol.RedisCmd) []byte {
+	if c.subscriptionCount() > 0 {
+		if _, ok := subscribedModeCommands[cmd.Cmd]; !ok {
+			return protocol.EncodeResp(errors.NotAllowedWhileSubscribed(cmd.Cmd), false)
+		}
+		if cmd.Cmd == "PING" {
+			return subscribedPing(cmd)
+		}
+	}
+
+	handler, ok := s.subscriptionHandlers[cmd.Cmd]
+	if !ok {
+		return s.redis.HandleCommand(c.state, cmd)
+	}
+
+	if c.state.InMulti() {
+		c.state.FlagTransactionError()
+		return protocol.RespNotAllowedInMulti
+	}
+
+	return handler(c, cmd)
+}
+
+/* Supports `PING [message]` in subscribed mode */
+func subscribedPing(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) > 1 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	message := ""
+	if len(cmd.Args) == 1 {
+		message = cmd.Args[0]
+	}
+	return protocol.EncodeResp([]string{"pong", message}, false)
+}
+
+/* Supports `SUBSCRIBE channel [channel ...]` */
+func (s *Server) subscribe(c *client, cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) == 0 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	var reply []byte
+	for _, channel := range cmd.Args {
+		if c.channels == nil {
+			c.channels = make(map[string]struct{})
+		}
+		if _, ok := c.channels[channel]; !ok {
+			c.channels[channel] = struct{}{}
+			addSubscriber(s.pubsub.channels, channel, c)
+		}
+		reply = append(reply, subscriptionReply("subscribe", &channel, c.subscriptionCount())...)
+	}
+	return reply
+}
+
+/* Supports `UNSUBSCRIBE [channel [channel ...]]` */
+func (s *Server) unsubscribe(c *client, cmd protocol.RedisCmd) []byte {
+	return s.removeSubscriptions(c, cmd.Args, c.channels, s.pubsub.channels, "unsubscribe")
+}
+
+/* Supports `PSUBSCRIBE pattern [pattern ...]` */
+func (s *Server) psubscribe(c *client, cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) == 0 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	var reply []byte
+	for _, pattern := range cmd.Args {
+		if c.patterns == nil {
+			c.patterns = make(map[string]struct{})
+		}
+		if _, ok := c.patterns[pattern]; !ok {
+			c.patterns[pattern] = struct{}{}
+			addSubscriber(s.pubsub.patterns, pattern, c)
+		}
+		reply = append(reply, subscriptionReply("psubscribe", &pattern, c.subscriptionCount())...)
+	}
+	return reply
+}
+
+/* Supports `PUNSUBSCRIBE [pattern [pattern ...]]` */
+func (s *Server) punsubscribe(c *client, cmd protocol.RedisCmd) []byte {
+	return s.removeSubscriptions(c, cmd.Args, c.patterns, s.pubsub.patterns, "punsubscribe")
+}
+
+// removeSubscriptions drops the given subscriptions of c, or all of them when names is
+// empty, and confirms each one with a reply of the given kind.
+func (s *Server) removeSubscriptions(c *client, names []string, subscribed map[string]struct{},
+	index map[string]map[*client]struct{}, kind string) []byte {
+	if len(names) == 0 {
+		if len(subscribed) == 0 {
+			return subscriptionReply(kind, nil, c.subscriptionCount())
+		}
+		for name := range subscribed {
+			names = append(names, name)
+		}
+	}
+
+	var reply []byte
+	for _, name := range names {
+		if _, ok := subscribed[name]; ok {
+			delete(subscribed, name)
+			removeSubscriber(index, name, c)
+		}
+		reply = append(reply, subscriptionReply(kind, &name, c.subscriptionCount())...)
+	}
+	return reply
+}
+
+// unsubscribeAll drops every subscription of a client that is going away.
+func (s *Server) unsubscribeAll(c *client) {
+	for channel := range c.channels {
+		removeSubscriber(s.pubsub.channels, channel, c)
+	}
+	for pattern := range c.patterns {
+		removeSubscriber(s.pubsub.patterns, pattern, c)
+	}
+	c.channels = nil
+	c.patterns = nil
+}
+
+/* Supports `PUBLISH channel message` */
+func (s *Server) publish(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) != 2 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	channel, message := cmd.Args[0], cmd.Args[1]
+	receivers := 0
+
+	if subscribers, ok := s.pubsub.channels[channel]; ok {
+		encoded := protocol.EncodeResp([]string{"message", channel, message}, false)
+		for subscriber := range subscribers {
+			s.deliver(subscriber, encoded)
+			receivers++
+		}
+	}
+
+	for pattern, subscribers := range s.pubsub.patterns {
+		if !glob.Match(pattern, channel) {
+			continue
+		}
+		encoded := protocol.EncodeResp([]string{"pmessage", pattern, channel, message}, false)
+		for subscriber := range subscribers {
+			s.deliver(subscriber, encoded)
+			receivers++
+		}
+	}
+
+	return protocol.EncodeResp(receivers, false)
+}
+
+/* Supports `PUBSUB CHANNELS [pattern]`, `PUBSUB NUMSUB [channel [channel ...]]` and `PUBSUB NUMPAT` */
+func (s *Server) pubSubCommand(cmd protocol.RedisCmd) []byte {
+	if len(cmd.Args) == 0 {
+		return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd), false)
+	}
+
+	subcommand := strings.ToUpper(cmd.Args[0])
+	args := cmd.Args[1:]
+
+	switch subcommand {
+	case "CHANNELS":
+		if len(args) > 1 {
+			return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd+"|"+subcommand), false)
+		}
+		channels := make([]string, 0)
+		for channel := range s.pubsub.channels {
+			if len(args) == 0 || glob.Match(args[0], channel) {
+				channels = append(channels, channel)
+			}
+		}
+		return protocol.EncodeResp(channels, false)
+
+	case "NUMSUB":
+		result := make([]any, 0, 2*len(args))
+		for _, channel := range args {
+			result = append(result, channel, len(s.pubsub.channels[channel]))
+		}
+		return protocol.EncodeResp(result, false)
+
+	case "NUMPAT":
+		if len(args) != 0 {
+			return protocol.EncodeResp(errors.InvalidNumberOfArgs(cmd.Cmd+"|"+subcommand), false)
+		}
+		return protocol.EncodeResp(len(s.pubsub.patterns), false)
+
+	default:
+		return protocol.EncodeResp(errors.UnknownSubcommand(cmd.Args[0], cmd.Cmd), false)
+	}
+}
+
+// deliver queues a message in the output buffer of a subscriber. It is written once the
+// event loop reports the socket writable, after the publisher got its own reply.
+func (s *Server) deliver(c *client, message []byte) {
+	c.addReply(message)
+	s.watchWritable(c)
+}
+
+func subscriptionReply(kind string, name *string, count int) []byte {
+	return protocol.EncodeResp([]any{kind, name, count}, false)
+}
+
+func addSubscriber(index map[string]map[*client]struct{}, name string, c *client) {
+	subscribers, ok := index[name]
+	if !ok {
+		subscribers = make(map[*client]struct{})
+		index[name] = subscribers
+	}
+	subscribers[c] = struct{}{}
+}
+
+func removeSubscriber(index map[string]map[*client]struct{}, name string, c *client) {
+	subscribers := index[name]
+	delete(subscribers, c)
+	if len(subscribers) == 0 {
+		delete(index, name)
+	}
+}
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manhhung2111/go-redis/internal/protocol"
+)
+
+func newPubSubTestServer(t *testing.T) (*Server, *recordingEventLoop) {
+	s, loop, _, _ := newTestServer(t)
+	return s, loop
+}
+
+func addTestClient(s *Server, fd int) *client {
+	c := newClient(fd, s.config)
+	s.clients[fd] = c
+	return c
+}
+
+func cmd(name string, args ...string) protocol.RedisCmd {
+	return protocol.RedisCmd{Cmd: name, Args: args}
+}
+
+func TestSubscribe(t *testing.T) {
+	s, _ := newPubSubTestServer(t)
+	c := addTestClient(s, 100)
+
+	resp := s.execute(c, cmd("SUBSCRIBE", "a", "b", "a"))
+	assert.Equal(t, "*3\r\n$9\r\nsubscribe\r\n$1\r\na\r\n:1\r\n"+
+		"*3\r\n$9\r\nsubscribe\r\n$1\r\nb\r\n:2\r\n"+
+		"*3\r\n$9\r\nsubscribe\r\n$1\r\na\r\n:2\r\n", string(resp))
+
+	resp = s.execute(c, cmd("PSUBSCRIBE", "news.*"))
+	assert.Equal(t, "*3\r\n$10\r\npsubscribe\r\n$6\r\nnews.*\r\n:3\r\n", string(resp))
+}
+
+func TestSubscribe_WrongArgs(t *testing.T) {
+	s, _ := newPubSubTestServer(t)
+	c := addTestClient(s, 100)
+
+	assert.Equal(t, "-ERR wrong number of arguments for 'SUBSCRIBE' command\r\n", string(s.execute(c, cmd("SUBSCRIBE"))))
+	assert.Equal(t, "-ERR wrong number of arguments for 'PSUBSCRIBE' command\r\n", string(s.execute(c, cmd("PSUBSCRIBE"))))
+}
+
+func TestSubscribedModeRestrictsCommands(t *testing.T) {
+	s, _ := newPubSubTestServer(t)
+	c := addTestClient(s, 100)
+	s.execute(c, cmd("SUBSCRIBE", "a"))
+
+	resp := s.execute(c, cmd("GET", "a"))
+	assert.Equal(t, "-ERR Can't execute 'GET': only (P)SUBSCRIBE / (P)UNSUBSCRIBE / PING are allowed in this context\r\n", string(resp))
+
+	assert.Equal(t, "*2\r\n$4\r\npong\r\n$0\r\n\r\n", string(s.execute(c, cmd("PING"))))
+	assert.Equal(t, "*2\r\n$4\r\npong\r\n$2\r\nhi\r\n", string(s.execute(c, cmd("PING", "hi"))))
+
+	// Leaving the last subscription leaves subscribed mode
+	s.execute(c, cmd("UNSUBSCRIBE", "a"))
+	assert.Equal(t, protocol.RespNilBulkString, s.execute(c, cmd("GET", "a")))
+	assert.Equal(t, "+PONG\r\n", string(s.execute(c, cmd("PING"))))
+}
+
+func TestUnsubscribe(t *testing.T) {
+	s, _ := newPubSubTestServer(t)
+	c := addTestClient(s, 100)
+
+	resp := s.execute(c, cmd("UNSUBSCRIBE"))
+	assert.Equal(t, "*3\r\n$11\r\nunsubscribe\r\n$-1\r\n:0\r\n", string(resp))
+
+	s.execute(c, cmd("SUBSCRIBE", "a", "b"))
+	s.execute(c, cmd("PSUBSCRIBE", "p*"))
+
+	resp = s.execute(c, cmd("UNSUBSCRIBE", "a", "missing"))
+	assert.Equal(t, "*3\r\n$11\r\nunsubscribe\r\n$1\r\na\r\n:2\r\n"+
+		"*3\r\n$11\r\nunsubscribe\r\n$7\r\nmissing\r\n:2\r\n", string(resp))
+
+	resp = s.execute(c, cmd("UNSUBSCRIBE"))
+	assert.Equal(t, "*3\r\n$11\r\nunsubscribe\r\n$1\r\nb\r\n:1\r\n", string(resp))
+
+	resp = s.execute(c, cmd("PUNSUBSCRIBE"))
+	assert.Equal(t, "*3\r\n$12\r\npunsubscribe\r\n$2\r\np*\r\n:0\r\n", string(resp))
+
+	assert.Empty(t, s.pubsub.channels)
+	assert.Empty(t, s.pubsub.patterns)
+}
+
+func TestPublish(t *testing.T) {
+	s, loop := newPubSubTestServer(t)
+	publisher := addTestClient(s, 100)
+	channelSubscriber := addTestClient(s, 101)
+	patternSubscriber := addTestClient(s, 102)
+	bothSubscriber := addTestClient(s, 103)
+
+	s.execute(channelSubscriber, cmd("SUBSCRIBE", "news.sport"))
+	s.execute(patternSubscriber, cmd("PSUBSCRIBE", "news.*"))
+	s.execute(bothSubscriber, cmd("SUBSCRIBE", "news.sport"))
+	s.execute(bothSubscriber, cmd("PSUBSCRIBE", "news.*"))
+	for _, c := range []*client{channelSubscriber, patternSubscriber, bothSubscriber} {
+		c.consumeReplyBuffer(len(c.replyBuf))
+	}
+
+	resp := s.execute(publisher, cmd("PUBLISH", "news.sport", "goal"))
+	assert.Equal(t, ":4\r\n", string(resp))
+
+	message := "*3\r\n$7\r\nmessage\r\n$10\r\nnews.sport\r\n$4\r\ngoal\r\n"
+	pmessage := "*4\r\n$8\r\npmessage\r\n$6\r\nnews.*\r\n$10\r\nnews.sport\r\n$4\r\ngoal\r\n"
+	assert.Equal(t, message, string(channelSubscriber.pendingReplies()))
+	assert.Equal(t, pmessage, string(patternSubscriber.pendingReplies()))
+	assert.Equal(t, message+pmessage, string(bothSubscriber.pendingReplies()))
+
+	// Messages are written once the event loop reports the subscribers writable
+	for _, c := range []*client{channelSubscriber, patternSubscriber, bothSubscriber} {
+		assert.True(t, c.writable)
+		assert.True(t, loop.writable[c.fd])
+	}
+	assert.False(t, publisher.hasPendingReplies())
+
+	assert.Equal(t, ":0\r\n", string(s.execute(publisher, cmd("PUBLISH", "weather", "rain"))))
+}
+
+func TestPublish_InsideTransaction(t *testing.T) {
+	s, _ := newPubSubTestServer(t)
+	publisher := addTestClient(s, 100)
+	subscriber := addTestClient(s, 101)
+	s.execute(subscriber, cmd("SUBSCRIBE", "a"))
+	subscriber.consumeReplyBuffer(len(subscriber.replyBuf))
+
+	s.execute(publisher, cmd("MULTI"))
+	assert.Equal(t, protocol.RespQueued, s.execute(publisher, cmd("PUBLISH", "a", "hello")))
+	assert.False(t, subscriber.hasPendingReplies())
+
+	assert.Equal(t, "*1\r\n:1\r\n", string(s.execute(publisher, cmd("EXEC"))))
+	assert.True(t, subscriber.hasPendingReplies())
+}
+
+func TestSubscribe_InsideTransaction(t *testing.T) {
+	s, _ := newPubSubTestServer(t)
+	c := addTestClient(s, 100)
+
+	s.execute(c, cmd("MULTI"))
+	assert.Equal(t, protocol.RespNotAllowedInMulti, s.execute(c, cmd("SUBSCRIBE", "a")))
+	assert.Equal(t, protocol.RespExecAbort, s.execute(c, cmd("EXEC")))
+	assert.Zero(t, c.subscriptionCount())
+}
+
+func TestPubSubIntrospection(t *testing.T) {
+	s, _ := newPubSubTestServer(t)
+	first := addTestClient(s, 100)
+	second := addTestClient(s, 101)
+
+	s.execute(first, cmd("SUBSCRIBE", "news.sport", "news.tech", "weather"))
+	s.execute(second, cmd("SUBSCRIBE", "news.sport"))
+	s.execute(first, cmd("PSUBSCRIBE", "news.*", "weather.*"))
+	s.execute(second, cmd("PSUBSCRIBE", "news.*"))
+
+	other := addTestClient(s, 102)
+	channels, _, err := protocol.DecodeResp(s.execute(other, cmd("PUBSUB", "CHANNELS")))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []any{"news.sport", "news.tech", "weather"}, channels)
+
+	channels, _, err = protocol.DecodeResp(s.execute(other, cmd("PUBSUB", "channels", "news.*")))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []any{"news.sport", "news.tech"}, channels)
+
+	resp := s.execute(other, cmd("PUBSUB", "NUMSUB", "news.sport", "weather", "missing"))
+	assert.Equal(t, "*6\r\n$10\r\nnews.sport\r\n:2\r\n$7\r\nweather\r\n:1\r\n$7\r\nmissing\r\n:0\r\n", string(resp))
+	assert.Equal(t, "*0\r\n", string(s.execute(other, cmd("PUBSUB", "NUMSUB"))))
+
+	assert.Equal(t, ":2\r\n", string(s.execute(other, cmd("PUBSUB", "NUMPAT"))))
+
+	assert.Equal(t, "-ERR unknown subcommand 'nope'. Try PUBSUB HELP.\r\n", string(s.execute(other, cmd("PUBSUB", "nope"))))
+	assert.Equal(t, "-ERR wrong number of arguments for 'PUBSUB' command\r\n", string(s.execute(other, cmd("PUBSUB"))))
+}
+
+func TestCloseClientDropsSubscriptions(t *testing.T) {
+	s, _, serverSide, _ := newTestServer(t)
+	c := s.clients[serverSide]
+	s.execute(c, cmd("SUBSCRIBE", "a"))
+	s.execute(c, cmd("PSUBSCRIBE", "p*"))
+
+	s.closeClient(c)
+
+	assert.Empty(t, s.pubsub.channels)
+	assert.Empty(t, s.pubsub.patterns)
+}
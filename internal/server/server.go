@@ -6,25 +6,74 @@ import (
 	"log"
 	"net"
 	"os"
+	"sync/atomic"
 	"syscall"
 
 	"github.com/manhhung2111/go-redis/internal/command"
 	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/persistence"
 	"github.com/manhhung2111/go-redis/internal/protocol"
 )
 
+// Size of a single socket read, matching Redis' PROTO_IOBUF_LEN
+const readBufferSize = 16 * 1024
+
+var errQueryBufferLimit = errors.New("ERR Protocol error: query buffer limit exceeded")
+
 type Server struct {
 	config    *config.Config
 	redis     command.Redis
+	rdb       *persistence.RDB
+	aof       *persistence.AOF
 	eventLoop EventLoop
 	serverFd  int
+	clients   map[int]*client
+	readBuf   []byte      // scratch buffer shared by all socket reads
+	shutdown  atomic.Bool // set by WaitingForSignals, observed by the event loop on the next timer tick
+
+	pubsub               *pubSub
+	subscriptionHandlers map[string]subscriptionHandler
+
+	blockedClients map[*command.Client]*client // clients waiting for a blocking command
 }
 
-func NewServer(cfg *config.Config, redis command.Redis) *Server {
-	return &Server{
-		config: cfg,
-		redis:  redis,
+func NewServer(cfg *config.Config, redis command.Redis, rdb *persistence.RDB, aof *persistence.AOF) *Server {
+	s := &Server{
+		config:  cfg,
+		redis:   redis,
+		rdb:     rdb,
+		aof:     aof,
+		clients: make(map[int]*client),
+		readBuf: make([]byte, readBufferSize),
+		pubsub:  newPubSub(),
+
+		blockedClients: make(map[*command.Client]*client),
 	}
+	s.registerPubSubCommands()
+
+	return s
+}
+
+// LoadData restores the dataset before the server starts accepting clients. With the
+// append-only file enabled it takes precedence over the snapshot, which is only loaded
+// when no log exists yet.
+func (s *Server) LoadData() error {
+	if !s.config.AppendOnly {
+		return s.rdb.Load()
+	}
+
+	loaded, err := s.aof.Load(s.redis.ReplayCommand)
+	if err != nil {
+		return err
+	}
+
+	if !loaded {
+		if err := s.rdb.Load(); err != nil {
+			return err
+		}
+	}
+
+	return s.aof.Open()
 }
 
 func (s *Server) Start(sigCh chan os.Signal) error {
@@ -51,7 +100,19 @@ func (s *Server) Start(sigCh chan os.Signal) error {
 		return fmt.Errorf("failed to register active expire cycle event: %w", err)
 	}
 
-	return s.runEventLoop()
+	if err := s.runEventLoop(); err != nil {
+		return err
+	}
+
+	if err := s.aof.Shutdown(); err != nil {
+		return fmt.Errorf("failed to sync append only file on shutdown: %w", err)
+	}
+
+	if err := s.rdb.Shutdown(); err != nil {
+		return fmt.Errorf("failed to save snapshot on shutdown: %w", err)
+	}
+
+	return nil
 }
 
 func (s *Server) createServerSocket() (int, error) {
@@ -96,7 +157,7 @@ func (s *Server) createServerSocket() (int, error) {
 }
 
 func (s *Server) runEventLoop() error {
-	for {
+	for !s.shutdown.Load() {
 		events, err := s.eventLoop.Wait(s.config.MaxConnection)
 		if err != nil {
 			if errors.Is(err, syscall.EBADF) {
@@ -121,12 +182,37 @@ func (s *Server) runEventLoop() error {
 func (s *Server) handleEvent(event Event) error {
 	if event.IsTimer {
 		s.redis.ActiveExpireCycle()
+		s.redis.TimeoutBlockedClients()
+		s.handleUnblockedClients()
+		if err := s.aof.Flush(); err != nil {
+			log.Printf("append only file error: %v", err)
+		}
+		s.rdb.Cron()
+		s.aof.Cron()
 		return nil
 	}
 
 	if event.Fd == s.serverFd {
 		return s.acceptConnection()
 	}
+
+	if event.IsWrite {
+		c, ok := s.clients[event.Fd]
+		if !ok {
+			return nil
+		}
+		if err := s.flushReplies(c); err != nil {
+			return err
+		}
+		if !event.IsRead && !event.IsError {
+			return nil
+		}
+		// The connection may have been closed while flushing
+		if _, ok := s.clients[event.Fd]; !ok {
+			return nil
+		}
+	}
+
 	return s.handleClientRequest(event.Fd)
 }
 
@@ -146,40 +232,164 @@ func (s *Server) acceptConnection() error {
 		return fmt.Errorf("failed to register client socket: %w", err)
 	}
 
+	s.clients[connFD] = newClient(connFD, s.config)
 	return nil
 }
 
 func (s *Server) handleClientRequest(clientFD int) error {
-	buf := make([]byte, 512)
-	n, err := syscall.Read(clientFD, buf)
+	c, ok := s.clients[clientFD]
+	if !ok {
+		c = newClient(clientFD, s.config)
+		s.clients[clientFD] = c
+	}
+
+	n, err := syscall.Read(clientFD, s.readBuf)
 	if err != nil {
-		syscall.Close(clientFD)
+		if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EINTR) {
+			return nil
+		}
+		s.closeClient(c)
 		return fmt.Errorf("read from client failed: %w", err)
 	}
 
 	if n == 0 {
-		syscall.Close(clientFD)
+		s.closeClient(c)
 		return nil
 	}
 
-	cmd, err := protocol.ParseCmd(buf[:n])
-	var response []byte
-	if err != nil {
-		response = protocol.EncodeResp(err, false)
-	} else {
-		response = s.redis.HandleCommand(*cmd)
+	c.queryBuf = append(c.queryBuf, s.readBuf[:n]...)
+
+	// Execute every complete command of the batch, then flush the replies together
+	cmds, parseErr := c.readCommands()
+	c.pendingCmds = append(c.pendingCmds, cmds...)
+	if parseErr == nil && int64(len(c.queryBuf)) > s.config.ClientQueryBufferLimit {
+		// The partial frame keeps growing without ever completing
+		parseErr = errQueryBufferLimit
+		c.queryBuf = nil
+		c.scanner.Reset()
 	}
+	if parseErr != nil {
+		c.protocolErr = parseErr
+	}
+	s.processCommands(c)
+	s.handleUnblockedClients()
 
-	if _, err := syscall.Write(clientFD, response); err != nil {
-		syscall.Close(clientFD)
-		return fmt.Errorf("write to client failed: %w", err)
+	// Writes reach the append-only file before their replies reach the client
+	if err := s.aof.Flush(); err != nil {
+		log.Printf("append only file error: %v", err)
+	}
+
+	return s.flushReplies(c)
+}
+
+// processCommands runs the pending commands of c in order. A blocking command parks the
+// client, and the commands pipelined after it wait until handleUnblockedClients resumes it.
+func (s *Server) processCommands(c *client) {
+	for len(c.pendingCmds) > 0 {
+		if c.state.IsBlocked() {
+			return
+		}
+
+		cmd := c.pendingCmds[0]
+		c.pendingCmds = c.pendingCmds[1:]
+
+		reply := s.execute(c, *cmd)
+		if c.state.IsBlocked() {
+			s.blockedClients[c.state] = c
+			return
+		}
+		c.addReply(reply)
+	}
+	c.pendingCmds = nil
+
+	if c.protocolErr != nil && !c.state.IsBlocked() {
+		c.addReply(protocol.EncodeResp(c.protocolErr, false))
+		c.closeAfterReply = true
+		log.Printf("protocol error from client %d: %v", c.fd, c.protocolErr)
+		c.protocolErr = nil
+	}
+}
+
+// handleUnblockedClients hands the replies of served or timed out blocking commands to
+// their clients and resumes the commands they pipelined, which may unblock others in turn.
+func (s *Server) handleUnblockedClients() {
+	for unblocked := s.redis.UnblockedClients(); len(unblocked) > 0; unblocked = s.redis.UnblockedClients() {
+		for _, u := range unblocked {
+			c, ok := s.blockedClients[u.Client]
+			if !ok {
+				continue
+			}
+			delete(s.blockedClients, u.Client)
+
+			c.addReply(u.Reply)
+			s.processCommands(c)
+			s.watchWritable(c)
+		}
+	}
+}
+
+// flushReplies writes as much of the client's output buffer as the socket accepts.
+// Leftover bytes are written when the event loop reports the socket writable again.
+func (s *Server) flushReplies(c *client) error {
+	for c.hasPendingReplies() {
+		n, err := syscall.Write(c.fd, c.pendingReplies())
+		if err != nil {
+			if errors.Is(err, syscall.EINTR) {
+				continue
+			}
+			if errors.Is(err, syscall.EAGAIN) {
+				break
+			}
+			s.closeClient(c)
+			return fmt.Errorf("write to client failed: %w", err)
+		}
+		c.consumeReplyBuffer(n)
+	}
+
+	if !c.hasPendingReplies() && c.closeAfterReply {
+		s.closeClient(c)
+		return nil
+	}
+
+	// Only watch for writability while there is something left to send
+	if wantWritable := c.hasPendingReplies(); wantWritable != c.writable {
+		if err := s.eventLoop.ModifyClientSocket(c.fd, wantWritable); err != nil {
+			s.closeClient(c)
+			return fmt.Errorf("failed to update client socket events: %w", err)
+		}
+		c.writable = wantWritable
 	}
 
 	return nil
 }
 
+// watchWritable has the event loop report the socket of c writable, for replies that are
+// produced outside of the client's own request and flushed by the next write event.
+func (s *Server) watchWritable(c *client) {
+	if c.writable || !c.hasPendingReplies() {
+		return
+	}
+
+	if err := s.eventLoop.ModifyClientSocket(c.fd, true); err != nil {
+		log.Printf("failed to watch client %d for writability: %v", c.fd, err)
+		s.closeClient(c)
+		return
+	}
+	c.writable = true
+}
+
+func (s *Server) closeClient(c *client) {
+	s.redis.FreeClient(c.state)
+	delete(s.blockedClients, c.state)
+	s.unsubscribeAll(c)
+	delete(s.clients, c.fd)
+	syscall.Close(c.fd)
+}
+
+// WaitingForSignals asks the event loop to stop once a signal arrives. The loop itself
+// closes its resources, since closing the poller from here would not wake a blocked wait.
 func (s *Server) WaitingForSignals(sigCh chan os.Signal) {
 	<-sigCh
 	log.Println("shutdown signal received")
-	s.eventLoop.Close()
+	s.shutdown.Store(true)
 }
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"bytes"
+	"syscall"
+	"testing"
+
+	"github.com/manhhung2111/go-redis/internal/command"
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/persistence"
+	"github.com/manhhung2111/go-redis/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingEventLoop struct {
+	writable map[int]bool
+}
+
+func (r *recordingEventLoop) Init() error                    { return nil }
+func (r *recordingEventLoop) RegisterServerSocket(int) error { return nil }
+func (r *recordingEventLoop) RegisterClientSocket(int) error { return nil }
+func (r *recordingEventLoop) RegisterTimer(int) error        { return nil }
+func (r *recordingEventLoop) Wait(int) ([]Event, error)      { return nil, nil }
+func (r *recordingEventLoop) Close() error                   { return nil }
+func (r *recordingEventLoop) ModifyClientSocket(fd int, writable bool) error {
+	r.writable[fd] = writable
+	return nil
+}
+
+func newTestServer(t *testing.T) (*Server, *recordingEventLoop, int, int) {
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		syscall.Close(fds[0])
+		syscall.Close(fds[1])
+	})
+
+	require.NoError(t, syscall.SetNonblock(fds[0], true))
+	require.NoError(t, syscall.SetsockoptInt(fds[0], syscall.SOL_SOCKET, syscall.SO_SNDBUF, 4096))
+
+	loop := &recordingEventLoop{writable: make(map[int]bool)}
+	cfg := config.NewConfig()
+	databases := storage.NewDatabases(cfg)
+	rdb := persistence.NewRDB(cfg, databases)
+	aof := persistence.NewAOF(cfg, databases)
+	s := NewServer(cfg, command.NewRedis(databases, rdb, aof), rdb, aof)
+	s.eventLoop = loop
+	s.clients[fds[0]] = newClient(fds[0], cfg)
+
+	return s, loop, fds[0], fds[1]
+}
+
+func drain(t *testing.T, fd int, buf *bytes.Buffer) {
+	require.NoError(t, syscall.SetNonblock(fd, true))
+	chunk := make([]byte, 64*1024)
+	for {
+		n, err := syscall.Read(fd, chunk)
+		if n <= 0 || err != nil {
+			return
+		}
+		buf.Write(chunk[:n])
+	}
+}
+
+func TestFlushReplies_ShortWriteKeepsRemainder(t *testing.T) {
+	s, loop, serverSide, peer := newTestServer(t)
+	c := s.clients[serverSide]
+
+	reply := bytes.Repeat([]byte("x"), 1<<20)
+	c.addReply(reply)
+
+	require.NoError(t, s.flushReplies(c))
+	require.True(t, c.hasPendingReplies(), "a 1MiB reply should not fit into the socket buffer")
+	assert.True(t, loop.writable[serverSide])
+	assert.True(t, c.writable)
+
+	var received bytes.Buffer
+	for c.hasPendingReplies() {
+		drain(t, peer, &received)
+		require.NoError(t, s.flushReplies(c))
+	}
+	drain(t, peer, &received)
+
+	assert.Equal(t, reply, received.Bytes())
+	assert.False(t, loop.writable[serverSide])
+	assert.False(t, c.writable)
+}
+
+func TestFlushReplies_CloseAfterReply(t *testing.T) {
+	s, _, serverSide, peer := newTestServer(t)
+	c := s.clients[serverSide]
+
+	c.addReply([]byte("-ERR Protocol error\r\n"))
+	c.closeAfterReply = true
+
+	require.NoError(t, s.flushReplies(c))
+	_, ok := s.clients[serverSide]
+	assert.False(t, ok)
+
+	var received bytes.Buffer
+	drain(t, peer, &received)
+	assert.Equal(t, "-ERR Protocol error\r\n", received.String())
+}
+
+func TestHandleClientRequest_QueryBufferLimit(t *testing.T) {
+	s, _, serverSide, peer := newTestServer(t)
+	s.config.ClientQueryBufferLimit = 64
+
+	_, err := syscall.Write(peer, append([]byte("*2\r\n$3\r\nGET\r\n$1000\r\n"), bytes.Repeat([]byte("x"), 100)...))
+	require.NoError(t, err)
+
+	require.NoError(t, s.handleClientRequest(serverSide))
+	_, ok := s.clients[serverSide]
+	assert.False(t, ok)
+
+	var received bytes.Buffer
+	drain(t, peer, &received)
+	assert.Equal(t, "-ERR Protocol error: query buffer limit exceeded\r\n", received.String())
+}
+
+func TestConsumeReplyBuffer(t *testing.T) {
+	c := newClient(0, config.NewConfig())
+	c.addReply([]byte("+OK\r\n:1\r\n"))
+
+	c.consumeReplyBuffer(5)
+	assert.Equal(t, ":1\r\n", string(c.pendingReplies()))
+
+	c.addReply([]byte(":2\r\n"))
+	assert.Equal(t, ":1\r\n:2\r\n", string(c.pendingReplies()))
+
+	c.consumeReplyBuffer(8)
+	assert.False(t, c.hasPendingReplies())
+	assert.Equal(t, 0, c.replyPos)
+}
@@ -2,12 +2,25 @@ package storage
 
 import (
 	"time"
+
+	"github.com/manhhung2111/go-redis/internal/storage/types"
 )
 
 // ActiveExpireCycle runs one bounded expiration cycle
-// Returns number of expired keys
+// Returns number of expired keys and hash fields
 func (s *store) ActiveExpireCycle() int {
-	deadlineUs := time.Now().UnixMicro() + int64(s.config.ActiveExpireCycleTimeLimitUsage)
+	return s.activeExpireCycle(time.Now().UnixMicro() + int64(s.config.ActiveExpireCycleTimeLimitUsage))
+}
+
+// activeExpireCycle expires keys of s, then fields of its hashes, until deadlineUs or until
+// few sampled keys are expired.
+func (s *store) activeExpireCycle(deadlineUs int64) int {
+	return s.activeExpireLoop(deadlineUs, s.sampleAndExpire) +
+		s.activeExpireLoop(deadlineUs, s.sampleAndExpireFields)
+}
+
+// activeExpireLoop calls sample until deadlineUs or until few sampled keys are expired.
+func (s *store) activeExpireLoop(deadlineUs int64, sample func(sampleSize int) (int, int)) int {
 	totalExpired := 0
 
 	for {
@@ -15,7 +28,7 @@ func (s *store) ActiveExpireCycle() int {
 			break
 		}
 
-		sampled, expired := s.sampleAndExpire(s.config.ActiveExpireCycleKeysPerLoop)
+		sampled, expired := sample(s.config.ActiveExpireCycleKeysPerLoop)
 		totalExpired += expired
 
 		if sampled == 0 || expired*100/sampled < s.config.ActiveExpireCycleThresholdPercent {
@@ -47,3 +60,27 @@ func (s *store) sampleAndExpire(sampleSize int) (int, int) {
 
 	return sampled, expired
 }
+
+// sampleAndExpireFields samples up to N hashes with volatile fields and deletes their
+// expired fields, and the hashes left without fields
+// Returns (sampled count, expired field count)
+func (s *store) sampleAndExpireFields(sampleSize int) (int, int) {
+	expired := 0
+	sampled := 0
+
+	for sampled < sampleSize && s.volatileHashes.Len() > 0 {
+		key := s.volatileHashes.GetRandomKey()
+		sampled++
+
+		obj, exists := s.data.Get(key)
+		if !exists || obj.objType != ObjHash || obj.value.(types.Hash).VolatileSize() == 0 {
+			s.untrackVolatileHash(key)
+			continue
+		}
+
+		count, _ := s.expireFields(key, obj)
+		expired += int(count)
+	}
+
+	return sampled, expired
+}
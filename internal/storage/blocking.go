@@ -0,0 +1,32 @@
+package storage
+
+// Block registers a client that waits for key to receive data.
+func (s *store) Block(key string) {
+	s.blocked[key]++
+}
+
+// Unblock releases a client registered by Block.
+func (s *store) Unblock(key string) {
+	s.blocked[key]--
+	if s.blocked[key] <= 0 {
+		delete(s.blocked, key)
+	}
+}
+
+// ReadyKeys returns the keys with blocked clients that were written since the last call,
+// in the order of their first write. A ready key is only a hint: the write may have
+// emptied it or replaced it with a value of another type.
+func (s *store) ReadyKeys() []string {
+	keys := s.readyKeys
+	s.readyKeys = nil
+	clear(s.readySet)
+	return keys
+}
+
+func (s *store) markReady(key string) {
+	if _, ok := s.readySet[key]; ok {
+		return
+	}
+	s.readySet[key] = struct{}{}
+	s.readyKeys = append(s.readyKeys, key)
+}
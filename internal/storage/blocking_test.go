@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/storage/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStoreBlocking() *store {
+	return NewStore(config.NewConfig()).(*store)
+}
+
+func TestReadyKeys_OnlyBlockedKeys(t *testing.T) {
+	s := newTestStoreBlocking()
+	s.Block("queue")
+	s.Block("zqueue")
+
+	s.RPush("other", "a")
+	assert.Empty(t, s.ReadyKeys())
+
+	s.ZAdd("zqueue", map[string]float64{"a": 1}, types.ZAddOptions{})
+	s.RPush("queue", "a")
+	s.RPush("queue", "b")
+	assert.Equal(t, []string{"zqueue", "queue"}, s.ReadyKeys())
+
+	// Reading the keys resets them
+	assert.Empty(t, s.ReadyKeys())
+}
+
+func TestReadyKeys_ReadsDoNotMarkReady(t *testing.T) {
+	s := newTestStoreBlocking()
+	s.RPush("queue", "a")
+	s.Block("queue")
+
+	s.LRange("queue", 0, -1)
+	s.LLen("queue")
+	assert.Empty(t, s.ReadyKeys())
+}
+
+func TestUnblock(t *testing.T) {
+	s := newTestStoreBlocking()
+	s.Block("queue")
+	s.Block("queue")
+
+	s.Unblock("queue")
+	s.RPush("queue", "a")
+	assert.Equal(t, []string{"queue"}, s.ReadyKeys())
+
+	s.Unblock("queue")
+	s.RPush("queue", "b")
+	assert.Empty(t, s.ReadyKeys())
+	assert.Empty(t, s.blocked)
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"encoding"
+	"fmt"
+	"slices"
+
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/storage/types"
+)
+
+// cloneObject returns a deep copy of obj that shares no mutable state with it. Collections
+// are rebuilt member by member, the other types through their binary encoding like in
+// snapshots. New lists get the node settings of cfg, and new sets, hashes and sorted sets
+// the most compact encoding that fits them.
+func cloneObject(obj *RObj, cfg *config.Config) (*RObj, error) {
+	clone := &RObj{objType: obj.objType, encoding: obj.encoding}
+
+	switch obj.objType {
+	case ObjString:
+		clone.value = obj.value
+		if obj.encoding == EncRawBytes {
+			clone.value = slices.Clone(obj.value.([]byte))
+		}
+
+	case ObjList:
+		list := newQuickList(cfg)
+		list.RPush(obj.value.(types.QuickList).LRange(0, -1))
+		clone.value = list
+
+	case ObjSet:
+		return newSetObject(cfg, obj.value.(types.Set).Members()), nil
+
+	case ObjHash:
+		hash := obj.value.(types.Hash)
+		rObj := newHashObjectOf(cfg, hash.GetAll())
+		setFieldExpireTimes(rObj.value.(types.Hash), fieldExpireTimes(hash))
+		return rObj, nil
+
+	case ObjZSet:
+		return newZSetObjectOf(cfg, zsetScoreMember(obj.value.(types.ZSet))), nil
+
+	case ObjBloomFilter:
+		sbf := types.NewScalableBloomFilter(0.01, 1, 1)
+		clone.value = sbf
+		return clone, cloneBinary(obj.value.(types.ScalableBloomFilter), sbf)
+
+	case ObjCuckooFilter:
+		cf := types.NewCuckooFilter(1, 1, 1, 1)
+		clone.value = cf
+		return clone, cloneBinary(obj.value.(types.CuckooFilter), cf)
+
+	case ObjHyperLogLog:
+		hll := types.NewHyperLogLog()
+		clone.value = hll
+		return clone, cloneBinary(obj.value.(types.HyperLogLog), hll)
+
+	case ObjCountMinSketch:
+		cms := types.NewCountMinSketchByDim(1, 1)
+		clone.value = cms
+		return clone, cloneBinary(obj.value.(types.CountMinSketch), cms)
+
+	case ObjStream:
+		stream := types.NewStream()
+		clone.value = stream
+		return clone, cloneBinary(obj.value.(types.Stream), stream)
+
+	default:
+		return nil, fmt.Errorf("cannot copy object of type %d", obj.objType)
+	}
+
+	return clone, nil
+}
+
+// cloneBinary overwrites dst with the binary encoding of src.
+func cloneBinary(src encoding.BinaryMarshaler, dst encoding.BinaryUnmarshaler) error {
+	data, err := src.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return dst.UnmarshalBinary(data)
+}
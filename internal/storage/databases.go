@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/config"
+)
+
+// databases are the logical databases of the server. Each of them has its own keyspace,
+// but they share the memory limit: memory is accounted across all of them, and eviction
+// picks keys from every database.
+type databases struct {
+	config       *config.Config
+	dbs          []*store
+	evictionPool []*evictionPoolEntry
+	nextEvictDB  int // database the random eviction policies pick from next
+	nextExpireDB int // database the next active expire cycle starts with
+}
+
+// NewDatabases returns the cfg.Databases logical databases of the server.
+func NewDatabases(cfg *config.Config) Databases {
+	return newDatabases(cfg, cfg.Databases)
+}
+
+func newDatabases(cfg *config.Config, count int) *databases {
+	dbs := &databases{
+		config:       cfg,
+		dbs:          make([]*store, count),
+		evictionPool: make([]*evictionPoolEntry, 0, cfg.EvictionPoolSize),
+	}
+	for i := range dbs.dbs {
+		dbs.dbs[i] = newStore(cfg, dbs)
+	}
+	return dbs
+}
+
+func (dbs *databases) DB(index int) Store {
+	return dbs.dbs[index]
+}
+
+func (dbs *databases) Len() int {
+	return len(dbs.dbs)
+}
+
+// usedMemory returns the memory usage of all databases together.
+func (dbs *databases) usedMemory() int64 {
+	var total int64
+	for _, db := range dbs.dbs {
+		total += db.usedMemory
+	}
+	return total
+}
+
+// Move moves key with its expiration time from the database at index to the one at
+// dstIndex, and reports whether it did. Nothing moves when key already exists in the
+// destination database.
+func (dbs *databases) Move(index int, key string, dstIndex int) bool {
+	src, dst := dbs.dbs[index], dbs.dbs[dstIndex]
+	result := src.access(key, ObjAny, false)
+	if !result.exists || dst.Exists(key) {
+		return false
+	}
+
+	expireAt, hasExpire := src.expires.Get(key)
+	src.delete(key)
+	dst.setObject(key, result.object, expireAt, hasExpire)
+	return true
+}
+
+// Copy stores a deep copy of the value and the expiration time of src in the database
+// at index at dst in the database at dstIndex, like Store.Copy.
+func (dbs *databases) Copy(index int, src string, dstIndex int, dst string, replace bool) (bool, error) {
+	return dbs.dbs[index].copyTo(dbs.dbs[dstIndex], src, dst, replace)
+}
+
+// SwapDB exchanges the keys of two databases. Clients stay on the same database index, so
+// they see the keys of the other database from now on: the keys they watch or wait on are
+// touched when they exist on either side.
+func (dbs *databases) SwapDB(index1, index2 int) {
+	db1, db2 := dbs.dbs[index1], dbs.dbs[index2]
+	db1.touchUsedKeys(db2)
+	db2.touchUsedKeys(db1)
+
+	db1.data, db2.data = db2.data, db1.data
+	db1.expires, db2.expires = db2.expires, db1.expires
+	db1.volatileHashes, db2.volatileHashes = db2.volatileHashes, db1.volatileHashes
+	db1.usedMemory, db2.usedMemory = db2.usedMemory, db1.usedMemory
+}
+
+// FlushAll deletes every key of every database.
+func (dbs *databases) FlushAll() {
+	for _, db := range dbs.dbs {
+		db.FlushDB()
+	}
+	dbs.evictionPool = dbs.evictionPool[:0]
+}
+
+// ActiveExpireCycle runs one bounded expiration cycle over every database. The databases
+// share the time limit of the cycle, and each cycle starts with the next database so that
+// none of them is left behind when the time runs out.
+// Returns number of expired keys
+func (dbs *databases) ActiveExpireCycle() int {
+	deadlineUs := time.Now().UnixMicro() + int64(dbs.config.ActiveExpireCycleTimeLimitUsage)
+	totalExpired := 0
+
+	for i := range dbs.dbs {
+		db := dbs.dbs[(dbs.nextExpireDB+i)%len(dbs.dbs)]
+		totalExpired += db.activeExpireCycle(deadlineUs)
+	}
+
+	dbs.nextExpireDB = (dbs.nextExpireDB + 1) % len(dbs.dbs)
+	return totalExpired
+}
+
+// FlushDB deletes every key of the database.
+func (s *store) FlushDB() {
+	s.touchUsedKeys(s)
+	s.clear()
+}
+
+// touchUsedKeys touches the keys of s that clients watch or wait on and that exist in s or
+// in other, before the keys of s are replaced as a whole.
+func (s *store) touchUsedKeys(other *store) {
+	exists := func(key string) bool {
+		_, ok1 := s.data.Get(key)
+		_, ok2 := other.data.Get(key)
+		return ok1 || ok2
+	}
+
+	for key := range s.watched {
+		if exists(key) {
+			s.touch(key)
+		}
+	}
+	for key := range s.blocked {
+		if exists(key) {
+			s.touch(key)
+		}
+	}
+}
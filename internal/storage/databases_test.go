@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDatabases() *databases {
+	return NewDatabases(config.NewConfig()).(*databases)
+}
+
+func TestDatabases_KeysAreSeparate(t *testing.T) {
+	dbs := newTestDatabases()
+	require.Equal(t, 16, dbs.Len())
+
+	dbs.DB(0).Set("key", "zero")
+	dbs.DB(1).Set("key", "one")
+
+	value, _ := dbs.DB(0).Get("key")
+	assert.Equal(t, "zero", *value)
+	value, _ = dbs.DB(1).Get("key")
+	assert.Equal(t, "one", *value)
+	assert.False(t, dbs.DB(2).Exists("key"))
+}
+
+func TestDatabases_MemoryLimitIsShared(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.EvictionPolicy = config.AllKeysRandom
+	dbs := NewDatabases(cfg).(*databases)
+
+	for i := 0; i < 100; i++ {
+		dbs.DB(0).Set("key:"+strconv.Itoa(i), "value")
+	}
+	cfg.MaxmemoryLimit = dbs.usedMemory()
+
+	// Writing to another database evicts keys of the first one
+	for i := 0; i < 10; i++ {
+		_, err := dbs.DB(1).RPush("other:"+strconv.Itoa(i), "value")
+		require.NoError(t, err)
+	}
+	assert.Less(t, dbs.DB(0).DBSize(), int64(100))
+
+	cfg.EvictionPolicy = config.NoEviction
+	cfg.MaxmemoryLimit = dbs.usedMemory() - 1
+	_, err := dbs.DB(2).SAdd("set", "a")
+	assert.Equal(t, ErrOutOfMemoryError, err)
+}
+
+func TestDatabases_RandomEvictionTakesTurns(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.EvictionPolicy = config.AllKeysRandom
+	dbs := NewDatabases(cfg).(*databases)
+
+	for i := 0; i < 10; i++ {
+		dbs.DB(0).Set("a:"+strconv.Itoa(i), "value")
+		dbs.DB(3).Set("b:"+strconv.Itoa(i), "value")
+	}
+
+	evicted := map[*store]int{}
+	for i := 0; i < 6; i++ {
+		db, key := dbs.selectKeyToEvict()
+		require.NotNil(t, db)
+		db.delete(key)
+		evicted[db]++
+	}
+	assert.Equal(t, 3, evicted[dbs.dbs[0]])
+	assert.Equal(t, 3, evicted[dbs.dbs[3]])
+}
+
+func TestDatabases_Move(t *testing.T) {
+	dbs := newTestDatabases()
+	dbs.DB(0).Set("key", "value")
+	dbs.DB(0).Expire("key", 100, ExpireOptions{})
+	version := dbs.DB(1).Watch("key")
+
+	assert.True(t, dbs.Move(0, "key", 1))
+	assert.False(t, dbs.DB(0).Exists("key"))
+	value, _ := dbs.DB(1).Get("key")
+	assert.Equal(t, "value", *value)
+	assert.Greater(t, dbs.DB(1).TTL("key"), int64(0))
+	assert.NotEqual(t, version, dbs.DB(1).WatchedVersion("key"))
+
+	// Missing in the source or existing in the destination
+	assert.False(t, dbs.Move(0, "key", 1))
+	dbs.DB(0).Set("key", "other")
+	assert.False(t, dbs.Move(0, "key", 1))
+	value, _ = dbs.DB(0).Get("key")
+	assert.Equal(t, "other", *value)
+}
+
+func TestDatabases_Copy(t *testing.T) {
+	dbs := newTestDatabases()
+	dbs.DB(0).SAdd("src", "a", "b")
+
+	copied, err := dbs.Copy(0, "src", 2, "dst", false)
+	require.NoError(t, err)
+	assert.True(t, copied)
+
+	members, _ := dbs.DB(2).SMembers("dst")
+	assert.ElementsMatch(t, []string{"a", "b"}, members)
+	assert.False(t, dbs.DB(0).Exists("dst"))
+
+	// The same key name in another database
+	copied, _ = dbs.Copy(0, "src", 2, "src", false)
+	assert.True(t, copied)
+	copied, _ = dbs.Copy(0, "src", 2, "src", false)
+	assert.False(t, copied)
+}
+
+func TestDatabases_SwapDB(t *testing.T) {
+	dbs := newTestDatabases()
+	dbs.DB(0).Set("a", "zero")
+	dbs.DB(1).RPush("b", "one")
+	memory0, memory1 := dbs.dbs[0].usedMemory, dbs.dbs[1].usedMemory
+	watchA := dbs.DB(0).Watch("a")
+	watchC := dbs.DB(0).Watch("c")
+	dbs.DB(0).Block("b")
+
+	dbs.SwapDB(0, 1)
+
+	assert.False(t, dbs.DB(0).Exists("a"))
+	assert.Equal(t, "list", dbs.DB(0).Type("b"))
+	assert.Equal(t, "string", dbs.DB(1).Type("a"))
+	assert.Equal(t, memory1, dbs.dbs[0].usedMemory)
+	assert.Equal(t, memory0, dbs.dbs[1].usedMemory)
+
+	// Watched and blocked keys stay with the database index
+	assert.NotEqual(t, watchA, dbs.DB(0).WatchedVersion("a"))
+	assert.Equal(t, watchC, dbs.DB(0).WatchedVersion("c"))
+	assert.Equal(t, []string{"b"}, dbs.DB(0).ReadyKeys())
+	assert.Empty(t, dbs.DB(1).ReadyKeys())
+}
+
+func TestDatabases_FlushDB(t *testing.T) {
+	dbs := newTestDatabases()
+	dbs.DB(0).Set("a", "v")
+	dbs.DB(0).Set("b", "v")
+	dbs.DB(0).Expire("b", 100, ExpireOptions{})
+	dbs.DB(1).Set("a", "v")
+	empty := newTestDatabases().dbs[0].usedMemory
+	version := dbs.DB(0).Watch("a")
+
+	dbs.DB(0).FlushDB()
+
+	assert.Equal(t, int64(0), dbs.DB(0).DBSize())
+	assert.Equal(t, empty, dbs.dbs[0].usedMemory)
+	assert.NotEqual(t, version, dbs.DB(0).WatchedVersion("a"))
+	assert.Equal(t, int64(1), dbs.DB(1).DBSize())
+
+	dbs.FlushAll()
+	for i := range dbs.Len() {
+		assert.Equal(t, int64(0), dbs.DB(i).DBSize())
+	}
+	assert.Equal(t, empty*int64(dbs.Len()), dbs.usedMemory())
+}
+
+func TestDatabases_ActiveExpireCycle(t *testing.T) {
+	dbs := newTestDatabases()
+	past := uint64(time.Now().Add(-time.Second).UnixMilli())
+	for _, index := range []int{0, 5, 15} {
+		dbs.DB(index).Set("key", "v")
+		dbs.dbs[index].expires.Set("key", past)
+	}
+
+	assert.Equal(t, 3, dbs.ActiveExpireCycle())
+	for _, index := range []int{0, 5, 15} {
+		assert.Equal(t, int64(0), dbs.DB(index).DBSize())
+	}
+}
+
+func TestDatabases_SnapshotRoundTrip(t *testing.T) {
+	src := newTestDatabases()
+	src.DB(0).Set("a", "zero")
+	src.DB(3).Set("a", "three")
+	src.DB(3).RPush("list", "x")
+	src.DB(15).SAdd("set", "m")
+
+	var buf bytes.Buffer
+	require.NoError(t, src.WriteSnapshot(&buf))
+	dst := newTestDatabases()
+	require.NoError(t, dst.ReadSnapshot(&buf))
+
+	value, _ := dst.DB(0).Get("a")
+	assert.Equal(t, "zero", *value)
+	value, _ = dst.DB(3).Get("a")
+	assert.Equal(t, "three", *value)
+	assert.Equal(t, int64(2), dst.DB(3).DBSize())
+	assert.True(t, dst.DB(15).Exists("set"))
+	assert.Equal(t, int64(0), dst.DB(1).DBSize())
+}
+
+func TestDatabases_SnapshotOfSingleDatabase(t *testing.T) {
+	// A snapshot without database numbers loads into the first database
+	src := newTestStoreSnapshot()
+	src.Set("a", "v")
+
+	var buf bytes.Buffer
+	require.NoError(t, src.WriteSnapshot(&buf))
+	dst := newTestDatabases()
+	require.NoError(t, dst.ReadSnapshot(&buf))
+	assert.True(t, dst.DB(0).Exists("a"))
+}
+
+func TestDatabases_SnapshotWithTooManyDatabases(t *testing.T) {
+	src := newTestDatabases()
+	src.DB(10).Set("a", "v")
+
+	var buf bytes.Buffer
+	require.NoError(t, src.WriteSnapshot(&buf))
+
+	cfg := config.NewConfig()
+	cfg.Databases = 4
+	err := NewDatabases(cfg).ReadSnapshot(&buf)
+	assert.ErrorIs(t, err, ErrCorruptSnapshot)
+}
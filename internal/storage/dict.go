@@ -14,6 +14,8 @@ type Dict[K comparable, V any] interface {
 	Len() int
 	GetRandomKey() K
 	Empty() bool
+	ForEach(fn func(key K, value V) bool)
+	Scan(cursor uint64, count int, fn func(key K, value V)) uint64
 }
 
 type dict[K comparable, V any] struct {
@@ -121,3 +123,21 @@ func (d *dict[K, V]) GetRandomKey() K {
 func (d *dict[K, V]) Empty() bool {
 	return len(d.contents) == 0
 }
+
+// ForEach calls fn for every entry until fn returns false. fn must not modify the dict.
+func (d *dict[K, V]) ForEach(fn func(key K, value V) bool) {
+	for _, key := range d.keys {
+		if !fn(key, d.contents[key]) {
+			return
+		}
+	}
+}
+
+// Scan calls fn for up to count entries and returns the cursor of the next call, 0 once
+// the scan is complete. Entries present during the whole scan are visited at least once,
+// see types.ScanSlice. fn must not modify the dict.
+func (d *dict[K, V]) Scan(cursor uint64, count int, fn func(key K, value V)) uint64 {
+	return types.ScanSlice(d.keys, cursor, count, func(key K) {
+		fn(key, d.contents[key])
+	})
+}
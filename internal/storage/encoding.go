@@ -0,0 +1,38 @@
+package storage
+
+// Small collections are kept in compact encodings, which are converted to the regular ones
+// once a collection outgrows the limits of the configuration:
+//
+//	set:        intset -> listpack -> hashtable
+//	hash:       listpack -> hashtable
+//	sorted set: listpack -> skiplist
+//
+// Like in Redis, conversions only go one way while a collection lives. Collections rebuilt
+// by snapshots and COPY start over in the most compact encoding that fits them.
+
+// memoryUser is implemented by the values of the collections.
+type memoryUser interface {
+	MemoryUsage() int64
+}
+
+// listPackFits reports whether a collection of entries entries, among them values, stays
+// within maxEntries entries of at most maxValue bytes.
+func listPackFits(entries, maxEntries, maxValue int, values ...string) bool {
+	if entries > maxEntries {
+		return false
+	}
+
+	for _, value := range values {
+		if len(value) > maxValue {
+			return false
+		}
+	}
+	return true
+}
+
+// convertObject replaces the value of obj, which is stored in s, with value in encoding.
+func (s *store) convertObject(obj *RObj, encoding ObjectEncoding, value memoryUser) {
+	s.usedMemory += value.MemoryUsage() - obj.value.(memoryUser).MemoryUsage()
+	obj.encoding = encoding
+	obj.value = value
+}
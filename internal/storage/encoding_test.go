@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/storage/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStoreEncoding returns a store whose collections leave their listpack after 4
+// entries or 8 bytes.
+func newTestStoreEncoding() *store {
+	cfg := config.NewConfig()
+	cfg.SetMaxIntsetEntries = 6
+	cfg.SetMaxListpackEntries = 4
+	cfg.SetMaxListpackValue = 8
+	cfg.HashMaxListpackEntries = 4
+	cfg.HashMaxListpackValue = 8
+	cfg.ZSetMaxListpackEntries = 4
+	cfg.ZSetMaxListpackValue = 8
+	return NewStore(cfg).(*store)
+}
+
+func TestEncoding_Set(t *testing.T) {
+	s := newTestStoreEncoding()
+
+	s.SAdd("set", "1", "2")
+	assertEncoding(t, s, "set", EncIntSet)
+	s.SAdd("set", "a")
+	assertEncoding(t, s, "set", EncListPack)
+	s.SAdd("set", "b", "c")
+	assertEncoding(t, s, "set", EncHashTable)
+	members, _ := s.SMembers("set")
+	assert.ElementsMatch(t, []string{"1", "2", "a", "b", "c"}, members)
+
+	// Too many integers for the intset, but not for the listpack
+	s.SAdd("ints", "1", "2", "3", "4")
+	s.SAdd("ints", "5", "6", "7")
+	assertEncoding(t, s, "ints", EncHashTable)
+
+	s.SAdd("long", "a")
+	s.SAdd("long", strings.Repeat("x", 9))
+	assertEncoding(t, s, "long", EncHashTable)
+
+	s.SAdd("new", "a", "b", "c", "d", "e")
+	assertEncoding(t, s, "new", EncHashTable)
+}
+
+func TestEncoding_Hash(t *testing.T) {
+	s := newTestStoreEncoding()
+
+	s.HSet("hash", map[string]string{"a": "1", "b": "2"})
+	assertEncoding(t, s, "hash", EncListPack)
+	s.HIncrBy("hash", "c", 5)
+	s.HSetNx("hash", "d", "4")
+	assertEncoding(t, s, "hash", EncListPack)
+
+	s.HSet("hash", map[string]string{"e": "5"})
+	assertEncoding(t, s, "hash", EncHashTable)
+	fields, _ := s.HGetAll("hash")
+	assert.ElementsMatch(t, []string{"a", "1", "b", "2", "c", "5", "d", "4", "e", "5"}, fields)
+
+	s.HSet("long", map[string]string{"a": "1"})
+	s.HSetNx("long", "b", strings.Repeat("x", 9))
+	assertEncoding(t, s, "long", EncHashTable)
+
+	s.HSet("field", map[string]string{strings.Repeat("x", 9): "1"})
+	assertEncoding(t, s, "field", EncHashTable)
+}
+
+func TestEncoding_ZSet(t *testing.T) {
+	s := newTestStoreEncoding()
+
+	s.ZAdd("zset", map[string]float64{"a": 1, "b": 2}, types.ZAddOptions{})
+	s.ZIncrBy("zset", "c", 3)
+	assertEncoding(t, s, "zset", EncListPack)
+
+	s.ZAdd("zset", map[string]float64{"d": 4, "e": 5}, types.ZAddOptions{})
+	assertEncoding(t, s, "zset", EncSortedSet)
+	members, _ := s.ZRangeByRank("zset", 0, -1, true)
+	assert.Equal(t, []string{"a", "1", "b", "2", "c", "3", "d", "4", "e", "5"}, members)
+
+	s.ZIncrBy("long", strings.Repeat("x", 9), 1)
+	assertEncoding(t, s, "long", EncSortedSet)
+
+	s.GeoAdd("geo", []types.GeoPoint{{Longitude: 13.361389, Latitude: 38.115556, Member: "Palermo centro"}}, types.ZAddOptions{})
+	assertEncoding(t, s, "geo", EncSortedSet)
+	s.GeoAdd("geo2", []types.GeoPoint{{Longitude: 13.361389, Latitude: 38.115556, Member: "a"}}, types.ZAddOptions{})
+	assertEncoding(t, s, "geo2", EncListPack)
+}
+
+func TestEncoding_ConversionAccountsMemory(t *testing.T) {
+	s := newTestStoreEncoding()
+	s.HSet("hash", map[string]string{"a": "1"})
+	before := s.usedMemory
+
+	s.HSet("hash", map[string]string{"b": strings.Repeat("x", 9)})
+	assertEncoding(t, s, "hash", EncHashTable)
+	assert.Greater(t, s.usedMemory, before)
+
+	s.Del("hash")
+	assert.Less(t, s.usedMemory, before)
+}
+
+func TestEncoding_RebuiltCollectionsAreCompact(t *testing.T) {
+	s := newTestStoreEncoding()
+	for i := 0; i < 5; i++ {
+		s.HSet("hash", map[string]string{strconv.Itoa(i): "v"})
+	}
+	assertEncoding(t, s, "hash", EncHashTable)
+	s.HDel("hash", []string{"0", "1"})
+
+	copied, err := s.Copy("hash", "copy", false)
+	require.NoError(t, err)
+	require.True(t, copied)
+	assertEncoding(t, s, "copy", EncListPack)
+	fields, _ := s.HGetAll("copy")
+	assert.ElementsMatch(t, []string{"2", "v", "3", "v", "4", "v"}, fields)
+}
+
+func TestEncoding_Names(t *testing.T) {
+	s := newTestStoreEncoding()
+	s.Set("int", "12")
+	s.Set("short", "hello")
+	s.Set("long", strings.Repeat("x", 45))
+	s.RPush("list", "a")
+	s.SAdd("set", "a")
+	s.ZAdd("zset", map[string]float64{"a": 1}, types.ZAddOptions{})
+	s.HSet("hash", map[string]string{strings.Repeat("x", 9): "1"})
+
+	expected := map[string]string{
+		"int":   "int",
+		"short": "embstr",
+		"long":  "raw",
+		"list":  "quicklist",
+		"set":   "listpack",
+		"zset":  "listpack",
+		"hash":  "hashtable",
+	}
+	for key, name := range expected {
+		encoding := s.Encoding(key)
+		require.NotNil(t, encoding, key)
+		assert.Equal(t, name, *encoding, key)
+	}
+	assert.Nil(t, s.Encoding("missing"))
+}
@@ -12,6 +12,14 @@ const (
 	ErrCmSKeyAlreadyExists
 	ErrCmSKeyDoesNotExist
 	ErrOutOfMemory
+	ErrNoGroup
+	ErrBusyGroup
+	ErrStringExceedsMaximumSize
+	ErrIncrementProducesNaNOrInfinity
+	ErrLCSKeysNotStrings
+	ErrLCSInsufficientMemory
+	ErrBitOffsetOutOfRange
+	ErrHashValueIsNotValidFloat
 )
 
 // StorageError represents a typed error from the storage layer
@@ -32,4 +40,12 @@ var (
 	ErrCmSKeyAlreadyExistsError           = &StorageError{Code: ErrCmSKeyAlreadyExists, Message: "CMS: key already exists"}
 	ErrCmSKeyDoesNotExistError            = &StorageError{Code: ErrCmSKeyDoesNotExist, Message: "CMS: key does not exist"}
 	ErrOutOfMemoryError            = &StorageError{Code: ErrOutOfMemory, Message: "Out of memory"}
+	ErrNoGroupError                = &StorageError{Code: ErrNoGroup, Message: "NOGROUP No such key or consumer group"}
+	ErrBusyGroupError              = &StorageError{Code: ErrBusyGroup, Message: "BUSYGROUP Consumer Group name already exists"}
+	ErrStringExceedsMaximumSizeError       = &StorageError{Code: ErrStringExceedsMaximumSize, Message: "ERR string exceeds maximum allowed size (proto-max-bulk-len)"}
+	ErrIncrementProducesNaNOrInfinityError = &StorageError{Code: ErrIncrementProducesNaNOrInfinity, Message: "ERR increment would produce NaN or Infinity"}
+	ErrLCSKeysNotStringsError              = &StorageError{Code: ErrLCSKeysNotStrings, Message: "ERR The specified keys must contain string values"}
+	ErrLCSInsufficientMemoryError          = &StorageError{Code: ErrLCSInsufficientMemory, Message: "ERR Insufficient memory, transient memory for LCS exceeds proto-max-bulk-len"}
+	ErrBitOffsetOutOfRangeError            = &StorageError{Code: ErrBitOffsetOutOfRange, Message: "ERR bit offset is not an integer or out of range"}
+	ErrHashValueIsNotValidFloatError       = &StorageError{Code: ErrHashValueIsNotValidFloat, Message: "ERR hash value is not a float"}
 )
@@ -7,6 +7,16 @@ import (
 	"github.com/manhhung2111/go-redis/internal/config"
 )
 
+// evictionPoolPopulate samples random keys of every database and inserts them into the
+// eviction pool. Returns the number of inserted keys.
+func (dbs *databases) evictionPoolPopulate() int {
+	inserted := 0
+	for _, db := range dbs.dbs {
+		inserted += db.evictionPoolPopulate()
+	}
+	return inserted
+}
+
 // evictionPoolPopulate samples random keys and inserts them into the eviction pool
 // sorted by idle time (ascending). The last entry has the highest idle time and is
 // the best candidate for eviction.
@@ -75,18 +85,18 @@ func (s *store) evictionPoolPopulate() int {
 
 		// Skip if this key is already in the pool with same or higher idle time
 		// or if it's not better than the worst candidate when pool is full
-		if len(s.evictionPool) > 0 && len(s.evictionPool) >= s.config.EvictionPoolSize {
+		if len(s.dbs.evictionPool) > 0 && len(s.dbs.evictionPool) >= s.config.EvictionPoolSize {
 			// Pool is full - only insert if this key has higher idle time than the worst (first) entry
-			if idleTime <= s.evictionPool[0].idle {
+			if idleTime <= s.dbs.evictionPool[0].idle {
 				continue
 			}
 		}
 
 		// Find insertion position using binary search (ascending order by idle time)
-		low, high := 0, len(s.evictionPool)
+		low, high := 0, len(s.dbs.evictionPool)
 		for low < high {
 			mid := low + (high-low)/2
-			if s.evictionPool[mid].idle < idleTime {
+			if s.dbs.evictionPool[mid].idle < idleTime {
 				low = mid + 1
 			} else {
 				high = mid
@@ -94,8 +104,8 @@ func (s *store) evictionPoolPopulate() int {
 		}
 
 		// If pool is full, remove the first element (lowest idle time = worst candidate)
-		if len(s.evictionPool) >= s.config.EvictionPoolSize {
-			s.evictionPool = s.evictionPool[1:]
+		if len(s.dbs.evictionPool) >= s.config.EvictionPoolSize {
+			s.dbs.evictionPool = s.dbs.evictionPool[1:]
 			low-- // Adjust position after removing first element
 			if low < 0 {
 				low = 0
@@ -103,9 +113,10 @@ func (s *store) evictionPoolPopulate() int {
 		}
 
 		// Insert at the found position
-		s.evictionPool = append(s.evictionPool, nil) // Extend slice
-		copy(s.evictionPool[low+1:], s.evictionPool[low:len(s.evictionPool)-1])
-		s.evictionPool[low] = &evictionPoolEntry{
+		s.dbs.evictionPool = append(s.dbs.evictionPool, nil) // Extend slice
+		copy(s.dbs.evictionPool[low+1:], s.dbs.evictionPool[low:len(s.dbs.evictionPool)-1])
+		s.dbs.evictionPool[low] = &evictionPoolEntry{
+			db:   s,
 			key:  key,
 			idle: idleTime,
 		}
@@ -115,64 +126,65 @@ func (s *store) evictionPoolPopulate() int {
 	return inserted
 }
 
-// performEvictions evicts keys until memory usage is below MaxmemoryLimit.
-func (s *store) performEvictions() {
-	for s.usedMemory > s.config.MaxmemoryLimit {
+// performEvictions evicts keys of any database until memory usage is below MaxmemoryLimit.
+func (dbs *databases) performEvictions() {
+	for dbs.usedMemory() > dbs.config.MaxmemoryLimit {
 		// Try to find a key to evict
-		key := s.selectKeyToEvict()
-		if key == nil {
+		db, key := dbs.selectKeyToEvict()
+		if db == nil {
 			// No more keys can be evicted
 			break
 		}
-		s.delete(*key)
+		db.delete(key)
 	}
 }
 
-// selectKeyToEvict selects the best key to evict from the eviction pool.
-// Returns nil if no suitable key is found.
-func (s *store) selectKeyToEvict() *string {
-	switch s.config.EvictionPolicy {
-	case config.AllKeysRandom:
-		if s.data.Empty() {
-			return nil
-		}
-		key := s.data.GetRandomKey()
-		return &key
-	case config.VolatileRandom:
-		if s.expires.Empty() {
-			return nil
+// selectKeyToEvict selects the best key to evict and its database.
+// Returns a nil database if no suitable key is found.
+func (dbs *databases) selectKeyToEvict() (*store, string) {
+	switch dbs.config.EvictionPolicy {
+	case config.AllKeysRandom, config.VolatileRandom:
+		// Databases take turns, so that the first ones are not emptied before the others
+		for range dbs.dbs {
+			db := dbs.dbs[dbs.nextEvictDB]
+			dbs.nextEvictDB = (dbs.nextEvictDB + 1) % len(dbs.dbs)
+			if dbs.config.EvictionPolicy == config.AllKeysRandom && !db.data.Empty() {
+				return db, db.data.GetRandomKey()
+			}
+			if dbs.config.EvictionPolicy == config.VolatileRandom && !db.expires.Empty() {
+				return db, db.expires.GetRandomKey()
+			}
 		}
-		key := s.expires.GetRandomKey()
-		return &key
+		return nil, ""
 	}
 
 	// Keep trying until we find a valid key or exhaust options
 	for {
 		// If pool is empty or low, populate it
-		if len(s.evictionPool) == 0 {
-			populated := s.evictionPoolPopulate()
+		if len(dbs.evictionPool) == 0 {
+			populated := dbs.evictionPoolPopulate()
 			if populated == 0 {
-				return nil
+				return nil, ""
 			}
 		}
 
 		// Find the best candidate (last element has highest idle time)
-		for len(s.evictionPool) > 0 {
-			idx := len(s.evictionPool) - 1
-			entry := s.evictionPool[idx]
-			s.evictionPool = s.evictionPool[:idx] // Remove from pool
+		for len(dbs.evictionPool) > 0 {
+			idx := len(dbs.evictionPool) - 1
+			entry := dbs.evictionPool[idx]
+			dbs.evictionPool = dbs.evictionPool[:idx] // Remove from pool
 
 			// Verify the key still exists
-			if _, exists := s.data.Get(entry.key); exists {
-				return &entry.key
+			if _, exists := entry.db.data.Get(entry.key); exists {
+				return entry.db, entry.key
 			}
 			// Key was already deleted, try next
 		}
 
 		// Pool exhausted, try to populate again
-		populated := s.evictionPoolPopulate()
+		populated := dbs.evictionPoolPopulate()
 		if populated == 0 {
-			return nil
+			return nil, ""
 		}
 	}
 }
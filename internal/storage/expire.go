@@ -13,53 +13,106 @@ type ExpireOptions struct {
 	LT bool
 }
 
+// TTL returns the remaining time to live of key in seconds, rounded to the nearest second,
+// protocol.NoExpire if it has none, or protocol.KeyNotExists.
 func (s *store) TTL(key string) int64 {
-	result := s.access(key, ObjAny, false)
-	if result.expired || !result.exists {
-		return protocol.KeyNotExists
+	ttl := s.PTTL(key)
+	if ttl < 0 {
+		return ttl
 	}
+	return (ttl + 500) / 1000
+}
 
-	if expireAt, ok := s.expires.Get(key); ok {
-		now := uint64(time.Now().UnixMilli())
-		return int64((expireAt - now) / 1000)
+// PTTL returns the remaining time to live of key in milliseconds, protocol.NoExpire if it
+// has none, or protocol.KeyNotExists.
+func (s *store) PTTL(key string) int64 {
+	expireAt := s.PExpireTime(key)
+	if expireAt < 0 {
+		return expireAt
 	}
-
-	return protocol.NoExpire
+	return max(expireAt-time.Now().UnixMilli(), 0)
 }
 
 func (s *store) Expire(key string, ttlSeconds int64, opt ExpireOptions) bool {
+	return s.ExpireAt(key, time.Now().UnixMilli()+ttlSeconds*1000, opt)
+}
+
+// ExpireAt sets an absolute expiration time in unix milliseconds.
+// A time in the past deletes the key right away.
+func (s *store) ExpireAt(key string, expireAtMs int64, opt ExpireOptions) bool {
 	result := s.access(key, ObjAny, true)
 	if result.expired || !result.exists {
 		return false
 	}
 
-	now := time.Now().UnixMilli()
-	newExpireAt := uint64(now + ttlSeconds*1000)
-
 	oldExpireAt, hasExpire := s.expires.Get(key)
-
-	// NX: set only if key has no expire
-	if opt.NX && hasExpire {
+	if !expireAllowed(opt, int64(oldExpireAt), hasExpire, expireAtMs) {
 		return false
 	}
 
-	// XX: set only if key has expire
-	if opt.XX && !hasExpire {
+	if expireAtMs <= time.Now().UnixMilli() {
+		s.delete(key)
+		return true
+	}
+
+	s.usedMemory += s.expires.Set(key, uint64(expireAtMs))
+	return true
+}
+
+// expireAllowed reports whether opt allows replacing the expiration time of a key or a
+// hash field, oldExpireAt if hasExpire, with expireAtMs. For GT and LT, a missing
+// expiration time counts as infinite.
+func expireAllowed(opt ExpireOptions, oldExpireAt int64, hasExpire bool, expireAtMs int64) bool {
+	if (opt.NX && hasExpire) || (opt.XX && !hasExpire) {
 		return false
 	}
+	if opt.GT && (!hasExpire || expireAtMs <= oldExpireAt) {
+		return false
+	}
+	if opt.LT && hasExpire && expireAtMs >= oldExpireAt {
+		return false
+	}
+	return true
+}
+
+// PExpireTime returns the absolute expiration time of key in unix milliseconds,
+// protocol.NoExpire if it has none, or protocol.KeyNotExists.
+func (s *store) PExpireTime(key string) int64 {
+	result := s.access(key, ObjAny, false)
+	if result.expired || !result.exists {
+		return protocol.KeyNotExists
+	}
+
+	if expireAt, ok := s.expires.Get(key); ok {
+		return int64(expireAt)
+	}
+
+	return protocol.NoExpire
+}
+
+// ExpireTime returns the absolute expiration time of key in unix seconds, rounded to the
+// nearest second, protocol.NoExpire if it has none, or protocol.KeyNotExists.
+func (s *store) ExpireTime(key string) int64 {
+	expireAt := s.PExpireTime(key)
+	if expireAt < 0 {
+		return expireAt
+	}
+	return (expireAt + 500) / 1000
+}
 
-	// GT / LT only apply if key already has expire
-	if hasExpire {
-		oldTTL := int64((oldExpireAt - uint64(now)) / 1000)
+// Persist removes the expiration time of key, and reports whether it had one.
+func (s *store) Persist(key string) bool {
+	result := s.access(key, ObjAny, false)
+	if result.expired || !result.exists {
+		return false
+	}
 
-		if opt.GT && oldTTL >= ttlSeconds {
-			return false
-		}
-		if opt.LT && oldTTL <= ttlSeconds {
-			return false
-		}
+	ok, delta := s.expires.Delete(key)
+	if !ok {
+		return false
 	}
 
-	s.expires.Set(key, newExpireAt)
+	s.usedMemory += delta
+	s.touch(key)
 	return true
 }
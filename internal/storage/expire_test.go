@@ -65,6 +65,65 @@ func TestTTL_AfterNaturalExpiration(t *testing.T) {
 	assert.Equal(t, int64(-2), s.TTL("mykey"))
 }
 
+func TestTTL_RoundsToNearestSecond(t *testing.T) {
+	s := newTestStoreExpire().(*store)
+	s.Set("up", "value")
+	s.Set("down", "value")
+	s.expires.Set("up", uint64(time.Now().UnixMilli()+1700))
+	s.expires.Set("down", uint64(time.Now().UnixMilli()+1300))
+
+	assert.Equal(t, int64(2), s.TTL("up"))
+	assert.Equal(t, int64(1), s.TTL("down"))
+}
+
+func TestPTTL_RemainingMilliseconds(t *testing.T) {
+	s := newTestStoreExpire()
+	s.Set("mykey", "value")
+
+	assert.Equal(t, int64(-2), s.PTTL("missing"))
+	assert.Equal(t, int64(-1), s.PTTL("mykey"))
+
+	s.Expire("mykey", 10, ExpireOptions{})
+	assert.InDelta(t, 10000, s.PTTL("mykey"), 100)
+}
+
+func TestExpireTime_AbsoluteTime(t *testing.T) {
+	s := newTestStoreExpire()
+	s.Set("mykey", "value")
+	expireAt := time.Now().Add(time.Hour).UnixMilli()/1000*1000 + 600
+
+	require.True(t, s.ExpireAt("mykey", expireAt, ExpireOptions{}))
+
+	assert.Equal(t, expireAt, s.PExpireTime("mykey"))
+	assert.Equal(t, expireAt/1000+1, s.ExpireTime("mykey"))
+	assert.Equal(t, int64(-2), s.ExpireTime("missing"))
+}
+
+func TestPersist(t *testing.T) {
+	s := newTestStoreExpire().(*store)
+	s.Set("mykey", "value")
+	s.Expire("mykey", 10, ExpireOptions{})
+	memory := s.usedMemory
+	version := s.Watch("mykey")
+
+	assert.True(t, s.Persist("mykey"))
+	assert.Equal(t, int64(-1), s.TTL("mykey"))
+	assert.Less(t, s.usedMemory, memory)
+	assert.NotEqual(t, version, s.WatchedVersion("mykey"))
+
+	assert.False(t, s.Persist("mykey"))
+	assert.False(t, s.Persist("missing"))
+}
+
+func TestPersist_ExpiredKey(t *testing.T) {
+	s := newTestStoreExpire().(*store)
+	s.Set("mykey", "value")
+	s.expires.Set("mykey", uint64(time.Now().UnixMilli()-1000))
+
+	assert.False(t, s.Persist("mykey"))
+	assert.False(t, s.Exists("mykey"))
+}
+
 func TestExpire_NonExistentKey(t *testing.T) {
 	s := newTestStoreExpire()
 
@@ -169,6 +228,22 @@ func TestExpireLT_NewTTLLess(t *testing.T) {
 	assert.InDelta(t, 10, s.TTL("mykey"), 1)
 }
 
+func TestExpireGTAndLT_KeyWithoutTTL(t *testing.T) {
+	s := newTestStoreExpire()
+	s.Set("mykey", "value")
+
+	assert.False(t, s.Expire("mykey", -1, ExpireOptions{GT: true}))
+	assert.False(t, s.Expire("mykey", 10, ExpireOptions{GT: true}))
+	assert.Equal(t, int64(-1), s.TTL("mykey"))
+
+	assert.True(t, s.Expire("mykey", 10, ExpireOptions{LT: true}))
+	assert.InDelta(t, 10, s.TTL("mykey"), 1)
+
+	s.Set("other", "value")
+	assert.True(t, s.Expire("other", -1, ExpireOptions{LT: true}))
+	assert.Equal(t, int64(-2), s.TTL("other"))
+}
+
 func TestExpire_AlreadyExpiredKey(t *testing.T) {
 	s := newTestStoreExpire().(*store)
 	s.Set("mykey", "value")
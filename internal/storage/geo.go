@@ -8,20 +8,12 @@ func (s *store) GeoAdd(key string, items []types.GeoPoint, options types.ZAddOpt
 		return nil, result.err
 	}
 
-	if !result.exists {
-		zset := types.NewZSet()
-		added, _ := zset.GeoAdd(items, options)
-		delta := s.data.Set(key, &RObj{
-			objType:  ObjZSet,
-			encoding: EncSortedSet,
-			value:    zset,
-		})
-
-		s.usedMemory += delta
-		return added, nil
+	members := make([]string, len(items))
+	for i, item := range items {
+		members[i] = item.Member
 	}
 
-	zset := result.object.value.(types.ZSet)
+	zset := s.zsetForWrite(key, result, members)
 	added, delta := zset.GeoAdd(items, options)
 	s.usedMemory += delta
 	return added, nil
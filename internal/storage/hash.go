@@ -1,6 +1,19 @@
 package storage
 
-import "github.com/manhhung2111/go-redis/internal/storage/types"
+import (
+	"math/big"
+
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/storage/types"
+)
+
+// HSetExOptions are the options of HSETEX.
+type HSetExOptions struct {
+	FNX        bool  // set only if none of the fields exists
+	FXX        bool  // set only if every field exists
+	KeepTTL    bool  // keep the expiration time of the fields
+	ExpireAtMs int64 // absolute expiration time of the fields, 0 for none
+}
 
 func (s *store) HGet(key string, field string) (*string, error) {
 	result := s.access(key, ObjHash, false)
@@ -35,6 +48,22 @@ func (s *store) HGetAll(key string) ([]string, error) {
 	return hash.GetAll(), nil
 }
 
+// HScan returns the next cursor and up to count field-value pairs whose field matches
+// pattern, or the fields alone without withValues, see Scan.
+func (s *store) HScan(key string, cursor uint64, pattern string, count int, withValues bool) (uint64, []string, error) {
+	result := s.access(key, ObjHash, false)
+	if result.err != nil {
+		return 0, nil, result.err
+	}
+
+	if result.expired || !result.exists {
+		return 0, []string{}, nil
+	}
+
+	next, pairs := result.object.value.(types.Hash).Scan(cursor, count)
+	return next, scanMatchPairs(pattern, pairs, withValues), nil
+}
+
 func (s *store) HMGet(key string, fields []string) ([]*string, error) {
 	nilResult := make([]*string, len(fields))
 
@@ -57,27 +86,49 @@ func (s *store) HIncrBy(key string, field string, increment int64) (int64, error
 		return 0, result.err
 	}
 
-	if !result.exists {
-		hash := types.NewHash()
-		res, _, err := hash.IncBy(field, increment)
-		if err != nil {
-			return 0, err
+	hash := s.hashForWrite(key, result, 1, field)
+	res, delta, err := hash.IncBy(field, increment)
+	s.usedMemory += delta
+	return res, err
+}
+
+// HIncrByFloat adds increment to the value of field, computing with the precision of a long
+// double, and returns the new value as it is stored. The expiration time of the field is kept.
+func (s *store) HIncrByFloat(key string, field string, increment *big.Float) (string, error) {
+	result := s.access(key, ObjHash, true)
+	if result.err != nil {
+		return "", result.err
+	}
+
+	value := new(big.Float).SetPrec(increment.Prec())
+	if result.exists {
+		if current, exists := result.object.value.(types.Hash).Get(field); exists {
+			parsed, ok := types.ParseLongDouble(current)
+			if !ok {
+				return "", ErrHashValueIsNotValidFloatError
+			}
+			value.Set(parsed)
 		}
+	}
 
-		delta := s.data.Set(key, &RObj{
-			objType:  ObjHash,
-			encoding: EncHashTable,
-			value:    hash,
-		})
+	if value.IsInf() || increment.IsInf() {
+		return "", ErrIncrementProducesNaNOrInfinityError
+	}
 
-		s.usedMemory += delta
-		return res, nil
+	// A sum beyond LDBL_MAX would be infinite as a long double
+	if !types.InLongDoubleRange(value.Add(value, increment)) {
+		return "", ErrIncrementProducesNaNOrInfinityError
 	}
 
-	hash := result.object.value.(types.Hash)
-	res, delta, err := hash.IncBy(field, increment)
+	formatted := types.FormatLongDouble(value)
+	hash := s.hashForWrite(key, result, 1, field, formatted)
+	expireAt, volatile := hash.FieldExpireTime(field)
+	_, delta := hash.Set(map[string]string{field: formatted})
+	if volatile {
+		delta += hash.SetFieldExpire(field, expireAt)
+	}
 	s.usedMemory += delta
-	return res, err
+	return formatted, nil
 }
 
 func (s *store) HKeys(key string) ([]string, error) {
@@ -122,26 +173,48 @@ func (s *store) HLen(key string) (uint32, error) {
 	return hash.Size(), nil
 }
 
+// HStrLen returns the length of the value of field, 0 when it does not exist.
+func (s *store) HStrLen(key string, field string) (int64, error) {
+	result := s.access(key, ObjHash, false)
+	if result.err != nil {
+		return 0, result.err
+	}
+
+	if result.expired || !result.exists {
+		return 0, nil
+	}
+
+	value, _ := result.object.value.(types.Hash).Get(field)
+	return int64(len(value)), nil
+}
+
+// HRandField returns count random fields, each followed by its value with withValues. A
+// negative count allows the same field to be returned several times.
+func (s *store) HRandField(key string, count int, withValues bool) ([]string, error) {
+	result := s.access(key, ObjHash, false)
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	if result.expired || !result.exists {
+		return []string{}, nil
+	}
+
+	return result.object.value.(types.Hash).RandField(count, withValues), nil
+}
+
 func (s *store) HSet(key string, fieldValue map[string]string) (int64, error) {
 	result := s.access(key, ObjHash, true)
 	if result.err != nil {
 		return 0, result.err
 	}
 
-	if !result.exists {
-		hash := types.NewHash()
-		added, _ := hash.Set(fieldValue)
-		delta := s.data.Set(key, &RObj{
-			objType:  ObjHash,
-			encoding: EncHashTable,
-			value:    hash,
-		})
-
-		s.usedMemory += delta
-		return added, nil
+	values := make([]string, 0, len(fieldValue)*2)
+	for field, value := range fieldValue {
+		values = append(values, field, value)
 	}
 
-	hash := result.object.value.(types.Hash)
+	hash := s.hashForWrite(key, result, len(fieldValue), values...)
 	added, delta := hash.Set(fieldValue)
 	s.usedMemory += delta
 	return added, nil
@@ -153,20 +226,7 @@ func (s *store) HSetNx(key string, field string, value string) (int64, error) {
 		return 0, result.err
 	}
 
-	if !result.exists {
-		hash := types.NewHash()
-		hash.SetNX(field, value)
-		delta := s.data.Set(key, &RObj{
-			objType:  ObjHash,
-			encoding: EncHashTable,
-			value:    hash,
-		})
-
-		s.usedMemory += delta
-		return 1, nil
-	}
-
-	hash := result.object.value.(types.Hash)
+	hash := s.hashForWrite(key, result, 1, field, value)
 	canSet, delta := hash.SetNX(field, value)
 	if canSet {
 		s.usedMemory += delta
@@ -194,6 +254,106 @@ func (s *store) HDel(key string, fields []string) (int64, error) {
 	return deleted, nil
 }
 
+// HGetDel returns the values of fields and deletes them, and the key once no field is left.
+func (s *store) HGetDel(key string, fields []string) ([]*string, error) {
+	result := s.access(key, ObjHash, true)
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	if result.expired || !result.exists {
+		return make([]*string, len(fields)), nil
+	}
+
+	hash := result.object.value.(types.Hash)
+	values := hash.MGet(fields...)
+	_, delta := hash.Delete(fields...)
+	s.usedMemory += delta
+	if hash.Size() == 0 {
+		s.delete(key)
+	}
+	return values, nil
+}
+
+// HGetEx returns the values of fields, then sets the expiration time of the existing ones to
+// expireAtMs, or removes it with persist. Fields set to expire in the past are deleted, and
+// the key once no field is left.
+func (s *store) HGetEx(key string, fields []string, expireAtMs int64, persist bool) ([]*string, error) {
+	result := s.access(key, ObjHash, expireAtMs != 0 || persist)
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	if result.expired || !result.exists {
+		return make([]*string, len(fields)), nil
+	}
+
+	hash := result.object.value.(types.Hash)
+	values := hash.MGet(fields...)
+	if persist {
+		for _, field := range fields {
+			_, delta := hash.PersistField(field)
+			s.usedMemory += delta
+		}
+	} else if expireAtMs != 0 {
+		s.expireFieldsAt(key, hash, fields, expireAtMs, ExpireOptions{})
+	}
+	return values, nil
+}
+
+// HSetEx sets the values of fields under the conditions of options, and returns 1 when
+// they were set, 0 otherwise. Without KeepTTL or an expiration time, the fields lose their
+// expiration time like with HSet.
+func (s *store) HSetEx(key string, fieldValue map[string]string, options HSetExOptions) (int64, error) {
+	result := s.access(key, ObjHash, true)
+	if result.err != nil {
+		return 0, result.err
+	}
+
+	if options.FNX || options.FXX {
+		var hash types.Hash
+		if result.exists {
+			hash = result.object.value.(types.Hash)
+		}
+		for field := range fieldValue {
+			exists := hash != nil && hash.Exists(field)
+			if (options.FNX && exists) || (options.FXX && !exists) {
+				return 0, nil
+			}
+		}
+	}
+
+	values := make([]string, 0, len(fieldValue)*2)
+	for field, value := range fieldValue {
+		values = append(values, field, value)
+	}
+
+	hash := s.hashForWrite(key, result, len(fieldValue), values...)
+	expireTimes := make(map[string]int64)
+	if options.KeepTTL {
+		for field := range fieldValue {
+			if expireAt, volatile := hash.FieldExpireTime(field); volatile {
+				expireTimes[field] = expireAt
+			}
+		}
+	}
+
+	_, delta := hash.Set(fieldValue)
+	s.usedMemory += delta
+	for field, expireAt := range expireTimes {
+		s.usedMemory += hash.SetFieldExpire(field, expireAt)
+	}
+
+	if options.ExpireAtMs != 0 {
+		fields := make([]string, 0, len(fieldValue))
+		for field := range fieldValue {
+			fields = append(fields, field)
+		}
+		s.expireFieldsAt(key, hash, fields, options.ExpireAtMs, ExpireOptions{})
+	}
+	return 1, nil
+}
+
 func (s *store) HExists(key, field string) (int64, error) {
 	result := s.access(key, ObjHash, false)
 	if result.err != nil {
@@ -210,3 +370,77 @@ func (s *store) HExists(key, field string) (int64, error) {
 	}
 	return 0, nil
 }
+
+// newHashObject returns an empty hash in the encoding that fits entries fields, among them
+// values.
+func newHashObject(cfg *config.Config, entries int, values ...string) *RObj {
+	if listPackFits(entries, cfg.HashMaxListpackEntries, cfg.HashMaxListpackValue, values...) {
+		return &RObj{objType: ObjHash, encoding: EncListPack, value: types.NewListPackHash()}
+	}
+	return &RObj{objType: ObjHash, encoding: EncHashTable, value: types.NewHash()}
+}
+
+// newHashObjectOf returns a hash of fieldValues, fields followed by their values, in the
+// encoding that fits them.
+func newHashObjectOf(cfg *config.Config, fieldValues []string) *RObj {
+	fieldValueMap := make(map[string]string, len(fieldValues)/2)
+	for i := 0; i+1 < len(fieldValues); i += 2 {
+		fieldValueMap[fieldValues[i]] = fieldValues[i+1]
+	}
+
+	rObj := newHashObject(cfg, len(fieldValueMap), fieldValues...)
+	rObj.value.(types.Hash).Set(fieldValueMap)
+	return rObj
+}
+
+// fieldExpireTimes returns the expiration times of the fields of hash that have one.
+func fieldExpireTimes(hash types.Hash) map[string]int64 {
+	expireTimes := make(map[string]int64, hash.VolatileSize())
+	if hash.VolatileSize() == 0 {
+		return expireTimes
+	}
+
+	for _, field := range hash.GetKeys() {
+		if expireAt, volatile := hash.FieldExpireTime(field); volatile {
+			expireTimes[field] = expireAt
+		}
+	}
+	return expireTimes
+}
+
+// setFieldExpireTimes sets the expiration times of the fields of hash and returns the
+// memory delta.
+func setFieldExpireTimes(hash types.Hash, expireTimes map[string]int64) int64 {
+	delta := int64(0)
+	for field, expireAt := range expireTimes {
+		delta += hash.SetFieldExpire(field, expireAt)
+	}
+	return delta
+}
+
+// hashForWrite returns the hash at key to write up to entries new fields to, among them
+// values. The hash is created when key does not exist, and converted to a hash table first
+// when the fields would not fit its listpack.
+func (s *store) hashForWrite(key string, result storageAccessResult, entries int, values ...string) types.Hash {
+	if !result.exists {
+		rObj := newHashObject(s.config, entries, values...)
+		s.usedMemory += s.data.Set(key, rObj)
+		return rObj.value.(types.Hash)
+	}
+
+	rObj := result.object
+	hash := rObj.value.(types.Hash)
+	if rObj.encoding == EncListPack &&
+		!listPackFits(int(hash.Size())+entries, s.config.HashMaxListpackEntries, s.config.HashMaxListpackValue, values...) {
+		converted := types.NewHash()
+		fieldValues := hash.GetAll()
+		for i := 0; i < len(fieldValues); i += 2 {
+			converted.SetNX(fieldValues[i], fieldValues[i+1])
+		}
+		setFieldExpireTimes(converted, fieldExpireTimes(hash))
+		s.convertObject(rObj, EncHashTable, converted)
+		return converted
+	}
+
+	return hash
+}
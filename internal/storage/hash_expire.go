@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/protocol"
+	"github.com/manhhung2111/go-redis/internal/storage/types"
+)
+
+// Replies of HExpireAt for each field, besides protocol.KeyNotExists for a missing field
+const (
+	FieldExpireNotSet int64 = 0 // a condition was not met
+	FieldExpireSet    int64 = 1
+	FieldExpireDelete int64 = 2 // the expiration time was not in the future
+)
+
+// HExpireAt sets the expiration time of fields to expireAtMs, in unix milliseconds, under
+// the conditions of opt, and returns the outcome for each field. Fields set to expire in
+// the past are deleted right away, and the key once no field is left.
+func (s *store) HExpireAt(key string, fields []string, expireAtMs int64, opt ExpireOptions) ([]int64, error) {
+	result := s.access(key, ObjHash, true)
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	if result.expired || !result.exists {
+		return missingFields(len(fields)), nil
+	}
+
+	return s.expireFieldsAt(key, result.object.value.(types.Hash), fields, expireAtMs, opt), nil
+}
+
+// HPersist removes the expiration time of fields, and returns 1 for each field that had
+// one, protocol.NoExpire when it had none or protocol.KeyNotExists.
+func (s *store) HPersist(key string, fields []string) ([]int64, error) {
+	result := s.access(key, ObjHash, true)
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	replies := missingFields(len(fields))
+	if result.expired || !result.exists {
+		return replies, nil
+	}
+
+	hash := result.object.value.(types.Hash)
+	for i, field := range fields {
+		if !hash.Exists(field) {
+			continue
+		}
+
+		persisted, delta := hash.PersistField(field)
+		s.usedMemory += delta
+		if persisted {
+			replies[i] = 1
+		} else {
+			replies[i] = protocol.NoExpire
+		}
+	}
+	return replies, nil
+}
+
+// HTTL returns the remaining time to live of each field in seconds, rounded to the nearest
+// second, protocol.NoExpire if it has none, or protocol.KeyNotExists.
+func (s *store) HTTL(key string, fields []string) ([]int64, error) {
+	ttls, err := s.HPTTL(key, fields)
+	for i, ttl := range ttls {
+		if ttl >= 0 {
+			ttls[i] = (ttl + 500) / 1000
+		}
+	}
+	return ttls, err
+}
+
+// HPTTL returns the remaining time to live of each field in milliseconds,
+// protocol.NoExpire if it has none, or protocol.KeyNotExists.
+func (s *store) HPTTL(key string, fields []string) ([]int64, error) {
+	ttls, err := s.HPExpireTime(key, fields)
+	now := time.Now().UnixMilli()
+	for i, expireAt := range ttls {
+		if expireAt >= 0 {
+			ttls[i] = max(expireAt-now, 0)
+		}
+	}
+	return ttls, err
+}
+
+// HExpireTime returns the absolute expiration time of each field in unix seconds, rounded
+// to the nearest second, protocol.NoExpire if it has none, or protocol.KeyNotExists.
+func (s *store) HExpireTime(key string, fields []string) ([]int64, error) {
+	expireTimes, err := s.HPExpireTime(key, fields)
+	for i, expireAt := range expireTimes {
+		if expireAt >= 0 {
+			expireTimes[i] = (expireAt + 500) / 1000
+		}
+	}
+	return expireTimes, err
+}
+
+// HPExpireTime returns the absolute expiration time of each field in unix milliseconds,
+// protocol.NoExpire if it has none, or protocol.KeyNotExists if the field does not exist.
+func (s *store) HPExpireTime(key string, fields []string) ([]int64, error) {
+	result := s.access(key, ObjHash, false)
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	expireTimes := missingFields(len(fields))
+	if result.expired || !result.exists {
+		return expireTimes, nil
+	}
+
+	hash := result.object.value.(types.Hash)
+	for i, field := range fields {
+		if expireAt, volatile := hash.FieldExpireTime(field); volatile {
+			expireTimes[i] = expireAt
+		} else if hash.Exists(field) {
+			expireTimes[i] = protocol.NoExpire
+		}
+	}
+	return expireTimes, nil
+}
+
+// missingFields returns the replies of n fields that do not exist.
+func missingFields(n int) []int64 {
+	replies := make([]int64, n)
+	for i := range replies {
+		replies[i] = protocol.KeyNotExists
+	}
+	return replies
+}
+
+// expireFieldsAt sets the expiration time of the existing fields of the hash at key under
+// the conditions of opt, like HExpireAt.
+func (s *store) expireFieldsAt(key string, hash types.Hash, fields []string, expireAtMs int64, opt ExpireOptions) []int64 {
+	replies := missingFields(len(fields))
+	past := expireAtMs <= time.Now().UnixMilli()
+
+	for i, field := range fields {
+		if !hash.Exists(field) {
+			continue
+		}
+
+		oldExpireAt, hasExpire := hash.FieldExpireTime(field)
+		if !expireAllowed(opt, oldExpireAt, hasExpire, expireAtMs) {
+			replies[i] = FieldExpireNotSet
+			continue
+		}
+
+		if past {
+			_, delta := hash.Delete(field)
+			s.usedMemory += delta
+			replies[i] = FieldExpireDelete
+			continue
+		}
+
+		s.usedMemory += hash.SetFieldExpire(field, expireAtMs)
+		replies[i] = FieldExpireSet
+	}
+
+	if hash.Size() == 0 {
+		s.delete(key)
+	} else if hash.VolatileSize() > 0 {
+		s.trackVolatileHash(key)
+	}
+	return replies
+}
+
+// expireFields deletes the expired fields of the hash in obj, stored at key, and the key
+// once no field is left. It returns the number of expired fields and whether the key was
+// deleted.
+func (s *store) expireFields(key string, obj *RObj) (int64, bool) {
+	hash := obj.value.(types.Hash)
+	if hash.VolatileSize() == 0 {
+		return 0, false
+	}
+
+	expired, delta := hash.ExpireFields(time.Now().UnixMilli())
+	s.usedMemory += delta
+	if expired > 0 && hash.Size() == 0 {
+		s.delete(key)
+		return expired, true
+	}
+	return expired, false
+}
+
+// trackVolatileHash registers key, which holds a hash with volatile fields, for the
+// active expiration cycle. Keys are unregistered when deleted, or once the cycle samples
+// them and finds no volatile fields.
+func (s *store) trackVolatileHash(key string) {
+	if _, tracked := s.volatileHashes.Get(key); !tracked {
+		s.usedMemory += s.volatileHashes.Set(key, struct{}{})
+	}
+}
+
+// untrackVolatileHash unregisters key from the active expiration cycle.
+func (s *store) untrackVolatileHash(key string) {
+	_, delta := s.volatileHashes.Delete(key)
+	s.usedMemory += delta
+}
+
+// trackObject registers key for the active expiration cycle when obj, newly stored at
+// key, is a hash with volatile fields.
+func (s *store) trackObject(key string, obj *RObj) {
+	if hash, ok := obj.value.(types.Hash); ok && hash.VolatileSize() > 0 {
+		s.trackVolatileHash(key)
+	}
+}
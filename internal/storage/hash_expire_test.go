@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStoreHashExpire() *store {
+	return NewStore(config.NewConfig()).(*store)
+}
+
+func TestHExpireAt(t *testing.T) {
+	s := newTestStoreHashExpire()
+	s.HSet("session", map[string]string{"token": "t", "user": "u", "theme": "dark"})
+	expireAt := time.Now().Add(time.Hour).UnixMilli()
+
+	replies, err := s.HExpireAt("session", []string{"token", "missing"}, expireAt, ExpireOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{FieldExpireSet, -2}, replies)
+
+	replies, _ = s.HExpireAt("missing", []string{"token"}, expireAt, ExpireOptions{})
+	assert.Equal(t, []int64{-2}, replies)
+
+	expireTimes, _ := s.HPExpireTime("session", []string{"token", "user"})
+	assert.Equal(t, []int64{expireAt, -1}, expireTimes)
+	_, tracked := s.volatileHashes.Get("session")
+	assert.True(t, tracked)
+
+	s.data.Set("str", &RObj{objType: ObjString, encoding: EncRaw, value: "string"})
+	_, err = s.HExpireAt("str", []string{"a"}, expireAt, ExpireOptions{})
+	assert.Equal(t, ErrWrongTypeError, err)
+}
+
+func TestHExpireAt_Options(t *testing.T) {
+	s := newTestStoreHashExpire()
+	s.HSet("session", map[string]string{"token": "t", "user": "u"})
+	now := time.Now().UnixMilli()
+	s.HExpireAt("session", []string{"token"}, now+10_000, ExpireOptions{})
+
+	fields := []string{"token", "user"}
+	replies, _ := s.HExpireAt("session", fields, now+20_000, ExpireOptions{NX: true})
+	assert.Equal(t, []int64{FieldExpireNotSet, FieldExpireSet}, replies)
+
+	s.HPersist("session", []string{"user"})
+	replies, _ = s.HExpireAt("session", fields, now+20_000, ExpireOptions{XX: true})
+	assert.Equal(t, []int64{FieldExpireSet, FieldExpireNotSet}, replies)
+
+	// Fields without an expiration time never expire for GT and LT
+	replies, _ = s.HExpireAt("session", fields, now+15_000, ExpireOptions{GT: true})
+	assert.Equal(t, []int64{FieldExpireNotSet, FieldExpireNotSet}, replies)
+	replies, _ = s.HExpireAt("session", fields, now+15_000, ExpireOptions{LT: true})
+	assert.Equal(t, []int64{FieldExpireSet, FieldExpireSet}, replies)
+}
+
+func TestHExpireAt_PastDeletesFields(t *testing.T) {
+	s := newTestStoreHashExpire()
+	s.HSet("session", map[string]string{"token": "t", "user": "u"})
+
+	replies, _ := s.HExpireAt("session", []string{"token", "token"}, time.Now().UnixMilli(), ExpireOptions{})
+	assert.Equal(t, []int64{FieldExpireDelete, -2}, replies)
+	length, _ := s.HLen("session")
+	assert.Equal(t, uint32(1), length)
+
+	s.HExpireAt("session", []string{"user"}, 0, ExpireOptions{})
+	assert.False(t, s.Exists("session"))
+}
+
+func TestHPersist(t *testing.T) {
+	s := newTestStoreHashExpire()
+	s.HSet("session", map[string]string{"token": "t", "user": "u"})
+	s.HExpireAt("session", []string{"token"}, time.Now().Add(time.Hour).UnixMilli(), ExpireOptions{})
+
+	replies, err := s.HPersist("session", []string{"token", "user", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, -1, -2}, replies)
+
+	expireTimes, _ := s.HPExpireTime("session", []string{"token"})
+	assert.Equal(t, []int64{-1}, expireTimes)
+}
+
+func TestHTTL(t *testing.T) {
+	s := newTestStoreHashExpire()
+	s.HSet("session", map[string]string{"token": "t", "user": "u"})
+	expireAt := time.Now().UnixMilli() + 100_000
+	s.HExpireAt("session", []string{"token"}, expireAt, ExpireOptions{})
+
+	fields := []string{"token", "user", "missing"}
+	ttls, err := s.HTTL("session", fields)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{100, -1, -2}, ttls)
+
+	ttls, _ = s.HPTTL("session", fields)
+	assert.InDelta(t, 100_000, ttls[0], 1000)
+	assert.Equal(t, []int64{-1, -2}, ttls[1:])
+
+	expireTimes, _ := s.HExpireTime("session", fields)
+	assert.Equal(t, []int64{(expireAt + 500) / 1000, -1, -2}, expireTimes)
+
+	ttls, _ = s.HTTL("missing", fields)
+	assert.Equal(t, []int64{-2, -2, -2}, ttls)
+}
+
+func TestHash_ExpiredFieldsAreNotCounted(t *testing.T) {
+	s := newTestStoreHashExpire()
+	s.HSet("session", map[string]string{"token": "t", "user": "u"})
+	s.HExpireAt("session", []string{"token"}, time.Now().UnixMilli()+5, ExpireOptions{})
+	time.Sleep(10 * time.Millisecond)
+
+	length, _ := s.HLen("session")
+	assert.Equal(t, uint32(1), length)
+	all, _ := s.HGetAll("session")
+	assert.Equal(t, []string{"user", "u"}, all)
+}
+
+func TestActiveExpireCycle_ExpiresHashFields(t *testing.T) {
+	s := newTestStoreHashExpire()
+	expireAt := time.Now().UnixMilli() + 5
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("session%d", i)
+		s.HSet(key, map[string]string{"token": "t", "user": "u"})
+		s.HExpireAt(key, []string{"token"}, expireAt, ExpireOptions{})
+	}
+	s.HSet("short", map[string]string{"token": "t"})
+	s.HExpireAt("short", []string{"token"}, expireAt, ExpireOptions{})
+	time.Sleep(10 * time.Millisecond)
+
+	expired := 0
+	for i := 0; i < 10; i++ {
+		expired += s.ActiveExpireCycle()
+	}
+
+	assert.Equal(t, 11, expired)
+	for i := 0; i < 10; i++ {
+		hash := storedHash(t, s, fmt.Sprintf("session%d", i))
+		assert.Equal(t, uint32(1), hash.Size())
+		assert.Equal(t, 0, hash.VolatileSize())
+	}
+
+	// The hash left without fields is deleted
+	_, exists := s.data.Get("short")
+	assert.False(t, exists)
+}
+
+func TestActiveExpireCycle_UntracksHashesWithoutVolatileFields(t *testing.T) {
+	s := newTestStoreHashExpire()
+	expireAt := time.Now().Add(time.Hour).UnixMilli()
+	for _, key := range []string{"persisted", "overwritten", "deleted"} {
+		s.HSet(key, map[string]string{"token": "t", "user": "u"})
+		s.HExpireAt(key, []string{"token"}, expireAt, ExpireOptions{})
+	}
+	s.HPersist("persisted", []string{"token"})
+	s.Set("overwritten", "value")
+	s.Del("deleted")
+	assert.Equal(t, 1, s.volatileHashes.Len())
+
+	s.ActiveExpireCycle()
+	assert.Equal(t, 0, s.volatileHashes.Len())
+}
+
+func TestHash_VolatileFieldsFollowTheirKey(t *testing.T) {
+	dbs := newDatabases(config.NewConfig(), 2)
+	s := dbs.dbs[0]
+	s.HSet("session", map[string]string{"token": "t", "user": "u"})
+	s.HExpireAt("session", []string{"token"}, time.Now().UnixMilli()+5, ExpireOptions{})
+
+	require.NoError(t, s.Rename("session", "renamed"))
+	copied, _ := s.Copy("renamed", "copied", false)
+	require.True(t, copied)
+	dbs.SwapDB(0, 1)
+	time.Sleep(10 * time.Millisecond)
+
+	assert.Equal(t, 2, dbs.ActiveExpireCycle())
+	for _, key := range []string{"renamed", "copied"} {
+		assert.Equal(t, uint32(1), storedHash(t, dbs.dbs[1], key).Size())
+	}
+}
@@ -1,7 +1,10 @@
 package storage
 
 import (
+	"bytes"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/manhhung2111/go-redis/internal/config"
 	"github.com/manhhung2111/go-redis/internal/storage/types"
@@ -461,3 +464,237 @@ func TestHExists_WrongType(t *testing.T) {
 	result, _ := s.HExists("key1", "field1")
 	assert.Equal(t, int64(0), result)
 }
+
+func TestHIncrByFloat(t *testing.T) {
+	s := newTestStoreHash().(*store)
+
+	result, err := s.HIncrByFloat("key1", "weight", big.NewFloat(10.5))
+	require.NoError(t, err)
+	assert.Equal(t, "10.5", result)
+
+	increment, _ := types.ParseLongDouble("0.1")
+	result, err = s.HIncrByFloat("key1", "weight", increment)
+	require.NoError(t, err)
+	assert.Equal(t, "10.6", result)
+
+	result, err = s.HIncrByFloat("key1", "weight", big.NewFloat(-10.6))
+	require.NoError(t, err)
+	assert.Equal(t, "0", result)
+
+	s.HSet("key1", map[string]string{"name": "flag"})
+	_, err = s.HIncrByFloat("key1", "name", big.NewFloat(1))
+	assert.Equal(t, ErrHashValueIsNotValidFloatError, err)
+
+	s.data.Set("str", &RObj{objType: ObjString, encoding: EncRaw, value: "string"})
+	_, err = s.HIncrByFloat("str", "field", big.NewFloat(1))
+	assert.Equal(t, ErrWrongTypeError, err)
+
+	s.HSet("key1", map[string]string{"huge": "1e4932", "beyond": "1e5000"})
+	huge, _ := types.ParseLongDouble("1e4932")
+	_, err = s.HIncrByFloat("key1", "huge", huge)
+	assert.Equal(t, ErrIncrementProducesNaNOrInfinityError, err)
+	_, err = s.HIncrByFloat("key1", "beyond", big.NewFloat(1))
+	assert.Equal(t, ErrHashValueIsNotValidFloatError, err)
+}
+
+func TestHIncrByFloat_KeepsFieldExpireTime(t *testing.T) {
+	s := newTestStoreHash().(*store)
+	expireAt := time.Now().Add(time.Hour).UnixMilli()
+	s.HSetEx("key1", map[string]string{"weight": "1"}, HSetExOptions{ExpireAtMs: expireAt})
+
+	_, err := s.HIncrByFloat("key1", "weight", big.NewFloat(0.5))
+	require.NoError(t, err)
+
+	expireTimes, _ := s.HPExpireTime("key1", []string{"weight"})
+	assert.Equal(t, []int64{expireAt}, expireTimes)
+}
+
+func TestHStrLen(t *testing.T) {
+	s := newTestStoreHash().(*store)
+	s.HSet("key1", map[string]string{"field1": "hello"})
+
+	length, err := s.HStrLen("key1", "field1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), length)
+
+	length, _ = s.HStrLen("key1", "missing")
+	assert.Equal(t, int64(0), length)
+	length, _ = s.HStrLen("missing", "field1")
+	assert.Equal(t, int64(0), length)
+}
+
+func TestHRandField(t *testing.T) {
+	s := newTestStoreHash().(*store)
+
+	fields, err := s.HRandField("missing", 3, true)
+	require.NoError(t, err)
+	assert.Empty(t, fields)
+
+	s.HSet("key1", map[string]string{"a": "1", "b": "2", "c": "3"})
+	fields, _ = s.HRandField("key1", 2, false)
+	assert.Len(t, fields, 2)
+	fields, _ = s.HRandField("key1", -5, true)
+	assert.Len(t, fields, 10)
+	fields, _ = s.HRandField("key1", 5, true)
+	assert.Len(t, fields, 6)
+}
+
+func TestHGetDel(t *testing.T) {
+	s := newTestStoreHash().(*store)
+	s.HSet("key1", map[string]string{"a": "1", "b": "2"})
+
+	values, err := s.HGetDel("key1", []string{"a", "missing"})
+	require.NoError(t, err)
+	require.Len(t, values, 2)
+	assert.Equal(t, "1", *values[0])
+	assert.Nil(t, values[1])
+
+	length, _ := s.HLen("key1")
+	assert.Equal(t, uint32(1), length)
+
+	// Deleting the last field deletes the key
+	values, _ = s.HGetDel("key1", []string{"b"})
+	assert.Equal(t, "2", *values[0])
+	_, exists := s.data.Get("key1")
+	assert.False(t, exists)
+
+	values, _ = s.HGetDel("key1", []string{"a"})
+	assert.Equal(t, []*string{nil}, values)
+}
+
+func TestHGetEx(t *testing.T) {
+	s := newTestStoreHash().(*store)
+	s.HSet("key1", map[string]string{"a": "1", "b": "2"})
+	expireAt := time.Now().Add(time.Hour).UnixMilli()
+
+	values, err := s.HGetEx("key1", []string{"a", "missing"}, expireAt, false)
+	require.NoError(t, err)
+	assert.Equal(t, "1", *values[0])
+	assert.Nil(t, values[1])
+
+	expireTimes, _ := s.HPExpireTime("key1", []string{"a", "b", "missing"})
+	assert.Equal(t, []int64{expireAt, -1, -2}, expireTimes)
+
+	s.HGetEx("key1", []string{"a"}, 0, true)
+	expireTimes, _ = s.HPExpireTime("key1", []string{"a"})
+	assert.Equal(t, []int64{-1}, expireTimes)
+
+	// A time in the past deletes the fields, and the key with the last one
+	values, _ = s.HGetEx("key1", []string{"a"}, 1, false)
+	assert.Equal(t, "1", *values[0])
+	_, exists := s.data.Get("key1")
+	assert.True(t, exists)
+	values, _ = s.HGetEx("key1", []string{"b"}, 1, false)
+	assert.Equal(t, "2", *values[0])
+	_, exists = s.data.Get("key1")
+	assert.False(t, exists)
+}
+
+func TestHSetEx(t *testing.T) {
+	s := newTestStoreHash().(*store)
+	expireAt := time.Now().Add(time.Hour).UnixMilli()
+
+	result, err := s.HSetEx("key1", map[string]string{"a": "1"}, HSetExOptions{FXX: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), result)
+	_, exists := s.data.Get("key1")
+	assert.False(t, exists)
+
+	result, _ = s.HSetEx("key1", map[string]string{"a": "1", "b": "2"}, HSetExOptions{FNX: true, ExpireAtMs: expireAt})
+	assert.Equal(t, int64(1), result)
+	expireTimes, _ := s.HPExpireTime("key1", []string{"a", "b"})
+	assert.Equal(t, []int64{expireAt, expireAt}, expireTimes)
+
+	// FNX fails as soon as one field exists, FXX as soon as one is missing
+	result, _ = s.HSetEx("key1", map[string]string{"a": "x", "c": "3"}, HSetExOptions{FNX: true})
+	assert.Equal(t, int64(0), result)
+	result, _ = s.HSetEx("key1", map[string]string{"a": "x", "c": "3"}, HSetExOptions{FXX: true})
+	assert.Equal(t, int64(0), result)
+
+	result, _ = s.HSetEx("key1", map[string]string{"a": "x"}, HSetExOptions{FXX: true, KeepTTL: true})
+	assert.Equal(t, int64(1), result)
+	value, _ := s.HGet("key1", "a")
+	assert.Equal(t, "x", *value)
+	expireTimes, _ = s.HPExpireTime("key1", []string{"a"})
+	assert.Equal(t, []int64{expireAt}, expireTimes)
+
+	// Without KEEPTTL the expiration time is dropped, like with HSET
+	s.HSetEx("key1", map[string]string{"a": "y"}, HSetExOptions{})
+	expireTimes, _ = s.HPExpireTime("key1", []string{"a"})
+	assert.Equal(t, []int64{-1}, expireTimes)
+
+	s.HSetEx("key1", map[string]string{"a": "z", "b": "z"}, HSetExOptions{ExpireAtMs: 1})
+	_, exists = s.data.Get("key1")
+	assert.False(t, exists)
+}
+
+func TestHash_FieldsExpireLazily(t *testing.T) {
+	s := newTestStoreHash().(*store)
+	s.HSet("key1", map[string]string{"a": "1", "b": "2"})
+	hash := storedHash(t, s, "key1")
+	hash.SetFieldExpire("a", time.Now().Add(-time.Second).UnixMilli())
+
+	length, _ := s.HLen("key1")
+	assert.Equal(t, uint32(1), length)
+	value, _ := s.HGet("key1", "a")
+	assert.Nil(t, value)
+
+	hash.SetFieldExpire("b", time.Now().Add(-time.Second).UnixMilli())
+	assert.False(t, s.Exists("key1"))
+	_, exists := s.data.Get("key1")
+	assert.False(t, exists)
+}
+
+func TestHash_FieldExpiresSurviveConversionCopyAndSnapshot(t *testing.T) {
+	s := newTestStoreEncoding()
+	expireAt := time.Now().Add(time.Hour).UnixMilli()
+	s.HSetEx("key1", map[string]string{"a": "1"}, HSetExOptions{ExpireAtMs: expireAt})
+	s.HSet("key1", map[string]string{"b": "2"})
+	assertEncoding(t, s, "key1", EncListPack)
+
+	s.HSet("key1", map[string]string{"c": "a long value"})
+	assertEncoding(t, s, "key1", EncHashTable)
+	expireTimes, _ := s.HPExpireTime("key1", []string{"a", "b"})
+	assert.Equal(t, []int64{expireAt, -1}, expireTimes)
+
+	copied, err := s.Copy("key1", "key2", false)
+	require.NoError(t, err)
+	require.True(t, copied)
+	expireTimes, _ = s.HPExpireTime("key2", []string{"a", "b"})
+	assert.Equal(t, []int64{expireAt, -1}, expireTimes)
+
+	var buf bytes.Buffer
+	require.NoError(t, s.WriteSnapshot(&buf))
+	dst := newTestStoreEncoding()
+	require.NoError(t, dst.ReadSnapshot(&buf))
+	expireTimes, _ = dst.HPExpireTime("key1", []string{"a", "b", "c"})
+	assert.Equal(t, []int64{expireAt, -1, -1}, expireTimes)
+}
+
+func TestSnapshot_DropsExpiredFields(t *testing.T) {
+	s := newTestStoreHash().(*store)
+	s.HSet("key1", map[string]string{"a": "1", "b": "2"})
+	s.HSet("key2", map[string]string{"a": "1"})
+	past := time.Now().Add(-time.Second).UnixMilli()
+	storedHash(t, s, "key1").SetFieldExpire("a", past)
+	storedHash(t, s, "key2").SetFieldExpire("a", past)
+
+	var buf bytes.Buffer
+	require.NoError(t, s.WriteSnapshot(&buf))
+	dst := newTestStoreHash().(*store)
+	require.NoError(t, dst.ReadSnapshot(&buf))
+
+	fields, _ := dst.HKeys("key1")
+	assert.Equal(t, []string{"b"}, fields)
+	_, exists := dst.data.Get("key2")
+	assert.False(t, exists)
+}
+
+// storedHash returns the hash stored at key, bypassing the expiration of its fields.
+func storedHash(t *testing.T, s *store, key string) types.Hash {
+	t.Helper()
+
+	rObj, exists := s.data.Get(key)
+	require.True(t, exists, "key %s does not exist", key)
+	return rObj.value.(types.Hash)
+}
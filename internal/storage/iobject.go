@@ -1,19 +1,59 @@
 package storage
 
-import "github.com/manhhung2111/go-redis/internal/storage/types"
+import (
+	"io"
+	"math/big"
+
+	"github.com/manhhung2111/go-redis/internal/storage/types"
+)
 
 type StringStore interface {
 	Get(key string) (*string, error)
 	Set(key string, value string)
 	SetEx(key string, value string, ttlSeconds uint64)
-	Del(key string) bool
+	SetExAt(key string, value string, expireAtMs int64)
+	SetKeepTTL(key string, value string)
+	GetDel(key string) (*string, error)
 	IncrBy(key string, increment int64) (*int64, error)
+	IncrByFloat(key string, increment *big.Float) (string, error)
+	Append(key string, value string) (int64, error)
+	StrLen(key string) (int64, error)
+	GetRange(key string, start, end int64) (string, error)
+	SetRange(key string, offset int64, value string) (int64, error)
+	LCS(key1, key2 string) (string, []types.LCSMatch, error)
+	SetBit(key string, offset uint64, bit int) (int, error)
+	GetBit(key string, offset uint64) (int, error)
+	BitCount(key string, rng BitRange) (int64, error)
+	BitPos(key string, bit int, rng BitRange, endGiven bool) (int64, error)
+	BitOp(op types.BitOp, dst string, keys ...string) (int64, error)
+	BitField(key string, ops []types.BitFieldOp) ([]*int64, error)
+}
+
+// KeyspaceStore holds the commands that operate on keys regardless of their type
+type KeyspaceStore interface {
 	Exists(key string) bool
+	Del(keys ...string) int64
+	Touch(keys ...string) int64
+	Keys(pattern string) []string
+	Type(key string) string
+	Encoding(key string) *string
+	Rename(src, dst string) error
+	RenameNX(src, dst string) (bool, error)
+	Copy(src, dst string, replace bool) (bool, error)
+	RandomKey() *string
+	DBSize() int64
+	FlushDB()
+	Scan(cursor uint64, pattern string, count int, typeName string) (uint64, []string)
 }
 
 type ExpireStore interface {
 	TTL(key string) int64
+	PTTL(key string) int64
 	Expire(key string, ttlSeconds int64, opt ExpireOptions) bool
+	ExpireAt(key string, expireAtMs int64, opt ExpireOptions) bool
+	ExpireTime(key string) int64
+	PExpireTime(key string) int64
+	Persist(key string) bool
 	ActiveExpireCycle() int
 }
 
@@ -26,6 +66,15 @@ type SetStore interface {
 	SRem(key string, members ...string) (int64, error)
 	SPop(key string, count int) ([]string, error)
 	SRandMember(key string, count int) ([]string, error)
+	SScan(key string, cursor uint64, pattern string, count int) (uint64, []string, error)
+	SMove(source, destination, member string) (bool, error)
+	SInter(keys ...string) ([]string, error)
+	SInterCard(limit int, keys ...string) (int64, error)
+	SUnion(keys ...string) ([]string, error)
+	SDiff(keys ...string) ([]string, error)
+	SInterStore(dst string, keys ...string) (int64, error)
+	SUnionStore(dst string, keys ...string) (int64, error)
+	SDiffStore(dst string, keys ...string) (int64, error)
 }
 
 type ListStore interface {
@@ -39,6 +88,10 @@ type ListStore interface {
 	LRem(key string, count int32, element string) (uint32, error)
 	LSet(key string, index int32, element string) error
 	LTrim(key string, start, end int32) error
+	LInsert(key string, before bool, pivot, element string) (int64, error)
+	LPos(key, element string, rank int64, count, maxLen uint32) ([]uint32, error)
+	LMove(source, destination string, fromLeft, toLeft bool) (*string, error)
+	LMPop(keys []string, fromLeft bool, count uint32) (string, []string, error)
 }
 
 type HashStore interface {
@@ -53,28 +106,55 @@ type HashStore interface {
 	HSetNx(key, field, value string) (int64, error)
 	HDel(key string, fields []string) (int64, error)
 	HExists(key, field string) (int64, error)
+	HScan(key string, cursor uint64, pattern string, count int, withValues bool) (uint64, []string, error)
+	HIncrByFloat(key string, field string, increment *big.Float) (string, error)
+	HStrLen(key string, field string) (int64, error)
+	HRandField(key string, count int, withValues bool) ([]string, error)
+	HGetDel(key string, fields []string) ([]*string, error)
+	HGetEx(key string, fields []string, expireAtMs int64, persist bool) ([]*string, error)
+	HSetEx(key string, fieldValue map[string]string, options HSetExOptions) (int64, error)
+	HExpireAt(key string, fields []string, expireAtMs int64, opt ExpireOptions) ([]int64, error)
+	HPersist(key string, fields []string) ([]int64, error)
+	HTTL(key string, fields []string) ([]int64, error)
+	HPTTL(key string, fields []string) ([]int64, error)
+	HExpireTime(key string, fields []string) ([]int64, error)
+	HPExpireTime(key string, fields []string) ([]int64, error)
 }
 
 type ZSetStore interface {
 	ZAdd(key string, scoreMember map[string]float64, options types.ZAddOptions) (*uint32, error)
 	ZCard(key string) (uint32, error)
-	ZCount(key string, minScore, maxScore float64) (uint32, error)
+	ZCount(key string, r types.ZScoreRange) (uint32, error)
 	ZIncrBy(key string, member string, increment float64) (float64, error)
-	ZLexCount(key, minValue, maxValue string) (uint32, error)
+	ZLexCount(key string, r types.ZLexRange) (uint32, error)
 	ZMScore(key string, members []string) ([]*float64, error)
 	ZPopMax(key string, count int) ([]string, error)
 	ZPopMin(key string, count int) ([]string, error)
+	ZMPop(keys []string, fromMin bool, count int) (string, []string, error)
 	ZRandMember(key string, count int, withScores bool) ([]string, error)
 	ZRangeByRank(key string, start, stop int, withScores bool) ([]string, error)
-	ZRangeByLex(key string, start, stop string) ([]string, error)
-	ZRangeByScore(key string, start, stop float64, withScores bool) ([]string, error)
+	ZRangeByLex(key string, r types.ZLexRange, offset, count int) ([]string, error)
+	ZRangeByScore(key string, r types.ZScoreRange, offset, count int, withScores bool) ([]string, error)
 	ZRevRangeByRank(key string, start, stop int, withScores bool) ([]string, error)
-	ZRevRangeByLex(key string, start, stop string) ([]string, error)
-	ZRevRangeByScore(key string, start, stop float64, withScores bool) ([]string, error)
+	ZRevRangeByLex(key string, r types.ZLexRange, offset, count int) ([]string, error)
+	ZRevRangeByScore(key string, r types.ZScoreRange, offset, count int, withScores bool) ([]string, error)
 	ZRank(key string, member string, withScore bool) ([]any, error)
 	ZRem(key string, members []string) (uint32, error)
+	ZRemRangeByRank(key string, start, stop int) (uint32, error)
+	ZRemRangeByScore(key string, r types.ZScoreRange) (uint32, error)
+	ZRemRangeByLex(key string, r types.ZLexRange) (uint32, error)
 	ZRevRank(key, member string, withScore bool) ([]any, error)
 	ZScore(key, member string) (*float64, error)
+	ZScan(key string, cursor uint64, pattern string, count int) (uint64, []string, error)
+	ZUnion(keys []string, weights []float64, aggregate types.ZAggregate, withScores bool) ([]string, error)
+	ZInter(keys []string, weights []float64, aggregate types.ZAggregate, withScores bool) ([]string, error)
+	ZInterCard(limit int, keys ...string) (uint32, error)
+	ZDiff(keys []string, withScores bool) ([]string, error)
+	ZUnionStore(dst string, keys []string, weights []float64, aggregate types.ZAggregate) (uint32, error)
+	ZInterStore(dst string, keys []string, weights []float64, aggregate types.ZAggregate) (uint32, error)
+	ZDiffStore(dst string, keys []string) (uint32, error)
+	ZRange(key string, spec ZRangeSpec, withScores bool) ([]string, error)
+	ZRangeStore(dst, src string, spec ZRangeSpec) (uint32, error)
 }
 
 type GeoStore interface {
@@ -120,9 +200,53 @@ type CMSStore interface {
 	CMSQuery(key string, items []string) ([]uint64, error)
 }
 
+type StreamStore interface {
+	XAdd(key string, fields []string, options types.StreamAddOptions) (*types.StreamID, error)
+	XLen(key string) (uint32, error)
+	XRange(key string, start, end types.StreamID, count int, rev bool) ([]types.StreamEntry, error)
+	XDel(key string, ids []types.StreamID) (int64, error)
+	XTrim(key string, options types.StreamTrimOptions) (int64, error)
+	XRead(key string, after types.StreamID, count int) ([]types.StreamEntry, error)
+	XLastID(key string) (types.StreamID, error)
+	XGroupCreate(key, group string, id *types.StreamID, mkStream bool, entriesRead int64) error
+	XGroupSetID(key, group string, id *types.StreamID, entriesRead int64) error
+	XGroupDestroy(key, group string) (int64, error)
+	XGroupCreateConsumer(key, group, consumer string) (int64, error)
+	XGroupDelConsumer(key, group, consumer string) (int64, error)
+	XReadGroup(key, group, consumer string, after *types.StreamID, count int, noAck bool) ([]types.StreamEntry, error)
+	XAck(key, group string, ids []types.StreamID) (int64, error)
+	XPending(key, group string, start, end types.StreamID, count int, consumer string, minIdle int64) ([]*types.StreamPendingEntry, error)
+	XClaim(key, group, consumer string, ids []types.StreamID, options types.StreamClaimOptions) ([]types.StreamEntry, error)
+	XAutoClaim(key, group, consumer string, start types.StreamID, count int,
+		options types.StreamClaimOptions) (types.StreamID, []types.StreamEntry, []types.StreamID, error)
+	XInfoStream(key string) (*types.StreamInfo, error)
+	XInfoGroups(key string) ([]types.StreamGroupInfo, error)
+	XInfoConsumers(key, group string) ([]*types.StreamConsumer, error)
+}
+
+// WatchStore tracks modifications of the keys used by WATCH
+type WatchStore interface {
+	Watch(key string) uint64
+	Unwatch(key string)
+	WatchedVersion(key string) uint64
+}
+
+// BlockingStore tracks the keys that clients of blocking commands wait on
+type BlockingStore interface {
+	Block(key string)
+	Unblock(key string)
+	ReadyKeys() []string
+}
+
+type SnapshotStore interface {
+	WriteSnapshot(w io.Writer) error
+	ReadSnapshot(r io.Reader) error
+}
+
 // Store combines all storage interfaces
 type Store interface {
 	StringStore
+	KeyspaceStore
 	ExpireStore
 	SetStore
 	ListStore
@@ -133,4 +257,20 @@ type Store interface {
 	CuckooFilterStore
 	HyperLogLogStore
 	CMSStore
+	StreamStore
+	WatchStore
+	BlockingStore
+}
+
+// Databases holds the logical databases of the server, numbered from 0 to Len()-1.
+// Indexes passed to its methods must be in that range.
+type Databases interface {
+	SnapshotStore
+	DB(index int) Store
+	Len() int
+	Move(index int, key string, dstIndex int) bool
+	Copy(index int, src string, dstIndex int, dst string, replace bool) (bool, error)
+	SwapDB(index1, index2 int)
+	FlushAll()
+	ActiveExpireCycle() int
 }
@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"strings"
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/glob"
+)
+
+// Exists reports whether key exists, deleting it first if it has expired.
+func (s *store) Exists(key string) bool {
+	result := s.access(key, ObjAny, false)
+	return result.object != nil
+}
+
+// Del deletes keys and returns the number of them that existed. Expired keys do not count.
+func (s *store) Del(keys ...string) int64 {
+	var deleted int64
+	for _, key := range keys {
+		if s.Exists(key) && s.delete(key) {
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// Touch updates the access time used by eviction of keys and returns the number of them
+// that exist.
+func (s *store) Touch(keys ...string) int64 {
+	var touched int64
+	for _, key := range keys {
+		if s.Exists(key) {
+			touched++
+		}
+	}
+	return touched
+}
+
+// Keys returns every key matching pattern. Expired keys are deleted instead of being returned.
+func (s *store) Keys(pattern string) []string {
+	nowMs := uint64(time.Now().UnixMilli())
+	keys := make([]string, 0)
+	var expired []string
+	s.data.ForEach(func(key string, _ *RObj) bool {
+		if expireAt, ok := s.expires.Get(key); ok && expireAt <= nowMs {
+			expired = append(expired, key)
+		} else if scanMatch(pattern, key) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+
+	for _, key := range expired {
+		s.delete(key)
+	}
+	return keys
+}
+
+// Type returns the name of the type of the value at key, "none" for a missing key.
+func (s *store) Type(key string) string {
+	result := s.access(key, ObjAny, false)
+	if !result.exists {
+		return "none"
+	}
+	return result.object.objType.String()
+}
+
+// Encoding returns the name of the encoding of the value at key, nil for a missing key.
+func (s *store) Encoding(key string) *string {
+	result := s.access(key, ObjAny, false)
+	if !result.exists {
+		return nil
+	}
+
+	name := result.object.encodingName()
+	return &name
+}
+
+// Rename moves the value and the expiration time of src to dst, replacing dst.
+func (s *store) Rename(src, dst string) error {
+	_, err := s.rename(src, dst, false)
+	return err
+}
+
+// RenameNX renames src to dst only if dst does not exist, and reports whether it did.
+func (s *store) RenameNX(src, dst string) (bool, error) {
+	return s.rename(src, dst, true)
+}
+
+func (s *store) rename(src, dst string, nx bool) (bool, error) {
+	result := s.access(src, ObjAny, false)
+	if !result.exists {
+		return false, ErrKeyNotFoundError
+	}
+
+	if nx && s.Exists(dst) {
+		return false, nil
+	}
+	if src == dst {
+		return !nx, nil
+	}
+
+	expireAt, hasExpire := s.expires.Get(src)
+	s.delete(src)
+	s.setObject(dst, result.object, expireAt, hasExpire)
+	return true, nil
+}
+
+// Copy stores a deep copy of the value and the expiration time of src at dst, and reports
+// whether it did. An existing dst is only replaced with replace.
+func (s *store) Copy(src, dst string, replace bool) (bool, error) {
+	return s.copyTo(s, src, dst, replace)
+}
+
+// copyTo copies src of s to dst of the database dstDB.
+func (s *store) copyTo(dstDB *store, src, dst string, replace bool) (bool, error) {
+	result := s.access(src, ObjAny, false)
+	if !result.exists {
+		return false, nil
+	}
+
+	if !replace && dstDB.Exists(dst) {
+		return false, nil
+	}
+
+	clone, err := cloneObject(result.object, dstDB.config)
+	if err != nil {
+		return false, err
+	}
+
+	expireAt, hasExpire := s.expires.Get(src)
+	dstDB.setObject(dst, clone, expireAt, hasExpire)
+	return true, nil
+}
+
+// RandomKey returns a random key, nil when the keyspace is empty. Expired keys that are
+// picked are deleted and another key is picked.
+func (s *store) RandomKey() *string {
+	nowMs := uint64(time.Now().UnixMilli())
+	for s.data.Len() > 0 {
+		key := s.data.GetRandomKey()
+		if expireAt, ok := s.expires.Get(key); ok && expireAt <= nowMs {
+			s.delete(key)
+			continue
+		}
+		return &key
+	}
+	return nil
+}
+
+// DBSize returns the number of keys, including the expired ones not deleted yet.
+func (s *store) DBSize() int64 {
+	return int64(s.data.Len())
+}
+
+// setObject replaces the value of key with obj, with an expiration time if hasExpire.
+func (s *store) setObject(key string, obj *RObj, expireAt uint64, hasExpire bool) {
+	s.delete(key)
+	s.usedMemory += s.data.Set(key, obj)
+	if hasExpire {
+		s.usedMemory += s.expires.Set(key, expireAt)
+	}
+	s.trackObject(key, obj)
+	s.touch(key)
+}
+
+// Scan visits up to count keys from cursor and returns the next cursor with the keys that
+// match pattern and, unless typeName is empty, are of that type. Expired keys are deleted
+// instead of being returned.
+func (s *store) Scan(cursor uint64, pattern string, count int, typeName string) (uint64, []string) {
+	var keys []string
+	next := s.data.Scan(cursor, count, func(key string, obj *RObj) {
+		if typeName != "" && !strings.EqualFold(obj.objType.String(), typeName) {
+			return
+		}
+		if scanMatch(pattern, key) {
+			keys = append(keys, key)
+		}
+	})
+
+	nowMs := uint64(time.Now().UnixMilli())
+	result := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if expireAt, ok := s.expires.Get(key); ok && expireAt <= nowMs {
+			s.delete(key)
+			continue
+		}
+		result = append(result, key)
+	}
+
+	return next, result
+}
+
+// scanMatch reports whether str matches the MATCH pattern of a scan, if any.
+func scanMatch(pattern, str string) bool {
+	return pattern == "" || pattern == "*" || glob.Match(pattern, str)
+}
+
+// scanMatchPairs keeps the pairs whose first element matches pattern, and only that first
+// element without withValues.
+func scanMatchPairs(pattern string, pairs []string, withValues bool) []string {
+	result := make([]string, 0, len(pairs))
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if !scanMatch(pattern, pairs[i]) {
+			continue
+		}
+		result = append(result, pairs[i])
+		if withValues {
+			result = append(result, pairs[i+1])
+		}
+	}
+	return result
+}
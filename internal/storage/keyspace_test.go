@@ -0,0 +1,286 @@
+package storage
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/storage/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStoreKeyspace() *store {
+	return NewStore(config.NewConfig()).(*store)
+}
+
+// scanAll runs a whole scan with the given options and returns every key it returned.
+func scanAll(s *store, pattern string, count int, typeName string) []string {
+	var all []string
+	var cursor uint64
+	for {
+		var keys []string
+		cursor, keys = s.Scan(cursor, pattern, count, typeName)
+		all = append(all, keys...)
+		if cursor == 0 {
+			return all
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	s := newTestStoreKeyspace()
+	for i := 0; i < 25; i++ {
+		s.Set("key:"+strconv.Itoa(i), "v")
+	}
+	s.SAdd("set", "a")
+	s.HSet("hash", map[string]string{"f": "v"})
+	s.XAdd("stream", []string{"f", "v"}, types.StreamAddOptions{AutoID: true})
+
+	assert.Len(t, scanAll(s, "", 10, ""), 28)
+	assert.Len(t, scanAll(s, "key:*", 3, ""), 25)
+	assert.ElementsMatch(t, []string{"key:1", "key:10", "key:11", "key:12", "key:13", "key:14", "key:15", "key:16", "key:17", "key:18", "key:19"},
+		scanAll(s, "key:1*", 10, ""))
+
+	assert.Equal(t, []string{"set"}, scanAll(s, "", 10, "set"))
+	assert.Equal(t, []string{"stream"}, scanAll(s, "", 10, "STREAM"))
+	assert.Empty(t, scanAll(s, "", 10, "list"))
+}
+
+func TestScan_DeletesExpiredKeys(t *testing.T) {
+	s := newTestStoreKeyspace()
+	s.Set("live", "v")
+	s.Set("expired", "v")
+	s.expires.Set("expired", uint64(time.Now().UnixMilli()-1000))
+
+	assert.Equal(t, []string{"live"}, scanAll(s, "", 10, ""))
+	assert.Equal(t, 1, s.data.Len())
+}
+
+func TestScan_ReturnsKeysPresentDuringWholeScan(t *testing.T) {
+	s := newTestStoreKeyspace()
+	for i := 0; i < 100; i++ {
+		s.Set("stable:"+strconv.Itoa(i), "v")
+	}
+
+	seen := make(map[string]bool)
+	var cursor uint64
+	round := 0
+	for {
+		var keys []string
+		cursor, keys = s.Scan(cursor, "", 5, "")
+		for _, key := range keys {
+			seen[key] = true
+		}
+		if cursor == 0 {
+			break
+		}
+
+		// Churn the keyspace between calls
+		s.Set("new:"+strconv.Itoa(round), "v")
+		s.Del("new:" + strconv.Itoa(round-1))
+		round++
+	}
+
+	for i := 0; i < 100; i++ {
+		assert.True(t, seen["stable:"+strconv.Itoa(i)])
+	}
+}
+
+func TestCollectionScans(t *testing.T) {
+	s := newTestStoreKeyspace()
+	s.SAdd("set", "apple", "avocado", "banana")
+	s.HSet("hash", map[string]string{"name": "n", "nick": "k", "age": "1"})
+	s.ZAdd("zset", map[string]float64{"one": 1, "two": 2}, types.ZAddOptions{})
+
+	cursor, members, err := s.SScan("set", 0, "a*", 10)
+	require.NoError(t, err)
+	assert.Zero(t, cursor)
+	assert.ElementsMatch(t, []string{"apple", "avocado"}, members)
+
+	_, fields, err := s.HScan("hash", 0, "n*", 10, true)
+	require.NoError(t, err)
+	assert.Len(t, fields, 4)
+	_, fields, _ = s.HScan("hash", 0, "", 10, false)
+	assert.ElementsMatch(t, []string{"name", "nick", "age"}, fields)
+
+	_, pairs, err := s.ZScan("zset", 0, "t*", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"two", "2"}, pairs)
+
+	cursor, members, err = s.SScan("missing", 0, "", 10)
+	require.NoError(t, err)
+	assert.Zero(t, cursor)
+	assert.Empty(t, members)
+
+	_, _, err = s.ZScan("set", 0, "", 10)
+	assert.Equal(t, ErrWrongTypeError, err)
+}
+
+func TestDel(t *testing.T) {
+	s := newTestStoreKeyspace()
+	s.Set("a", "1")
+	s.Set("b", "2")
+	s.Set("expired", "3")
+	s.expires.Set("expired", uint64(time.Now().UnixMilli()-1000))
+
+	assert.Equal(t, int64(2), s.Del("a", "b", "missing", "expired", "a"))
+	assert.Zero(t, s.DBSize())
+}
+
+func TestDel_FreesMemory(t *testing.T) {
+	s := newTestStoreKeyspace()
+	s.Set("key", "value")
+	s.Expire("key", 100, ExpireOptions{})
+	before := s.usedMemory
+
+	s.Del("key")
+	assert.Less(t, s.usedMemory, before)
+}
+
+func TestKeysAndType(t *testing.T) {
+	s := newTestStoreKeyspace()
+	s.Set("str", "v")
+	s.RPush("list", "x")
+	s.SAdd("set", "m")
+	s.PFAdd("hll", []string{"x"})
+	s.BFAdd("bf", "x")
+	s.Set("expired", "v")
+	s.expires.Set("expired", uint64(time.Now().UnixMilli()-1000))
+
+	assert.ElementsMatch(t, []string{"str", "list", "set", "hll", "bf"}, s.Keys("*"))
+	assert.ElementsMatch(t, []string{"str", "set"}, s.Keys("s*"))
+	assert.Equal(t, int64(5), s.DBSize())
+
+	assert.Equal(t, "string", s.Type("str"))
+	assert.Equal(t, "list", s.Type("list"))
+	assert.Equal(t, "string", s.Type("hll"))
+	assert.Equal(t, "MBbloom--", s.Type("bf"))
+	assert.Equal(t, "none", s.Type("missing"))
+}
+
+func TestRename(t *testing.T) {
+	s := newTestStoreKeyspace()
+	s.SetEx("src", "v", 100)
+	s.Set("dst", "old")
+
+	require.NoError(t, s.Rename("src", "dst"))
+	assert.False(t, s.Exists("src"))
+	value, _ := s.Get("dst")
+	assert.Equal(t, "v", *value)
+	assert.Greater(t, s.TTL("dst"), int64(90))
+	assert.Equal(t, 1, s.expires.Len())
+
+	assert.Equal(t, ErrKeyNotFoundError, s.Rename("src", "dst"))
+	require.NoError(t, s.Rename("dst", "dst"))
+
+	s.Set("other", "v")
+	renamed, err := s.RenameNX("dst", "other")
+	require.NoError(t, err)
+	assert.False(t, renamed)
+	renamed, _ = s.RenameNX("dst", "fresh")
+	assert.True(t, renamed)
+}
+
+func TestRename_WakesWatchersAndBlockedClients(t *testing.T) {
+	s := newTestStoreKeyspace()
+	s.RPush("src", "x")
+	version := s.Watch("src")
+	s.Block("dst")
+
+	require.NoError(t, s.Rename("src", "dst"))
+	assert.Greater(t, s.WatchedVersion("src"), version)
+	assert.Equal(t, []string{"dst"}, s.ReadyKeys())
+}
+
+func TestCopy_IsDeep(t *testing.T) {
+	s := newTestStoreKeyspace()
+	s.RPush("list", "a")
+	s.SAdd("ints", "1")
+	s.SAdd("set", "a")
+	s.HSet("hash", map[string]string{"f": "v"})
+	s.ZAdd("zset", map[string]float64{"m": 1.5}, types.ZAddOptions{})
+	s.PFAdd("hll", []string{"a"})
+	s.BFAdd("bf", "a")
+	s.CFAdd("cf", "a")
+	s.CMSInitByDim("cms", 10, 2)
+	s.XAdd("stream", []string{"f", "v"}, types.StreamAddOptions{ID: types.StreamID{Ms: 1}})
+	s.SetBit("bits", 0, 1)
+
+	for _, key := range []string{"list", "ints", "set", "hash", "zset", "hll", "bf", "cf", "cms", "stream", "bits"} {
+		copied, err := s.Copy(key, key+":copy", false)
+		require.NoError(t, err)
+		assert.True(t, copied, key)
+		assert.Equal(t, s.Type(key), s.Type(key+":copy"))
+	}
+
+	s.RPush("list:copy", "b")
+	s.SAdd("ints:copy", "2")
+	s.SAdd("set:copy", "b")
+	s.HSet("hash:copy", map[string]string{"g": "w"})
+	s.ZAdd("zset:copy", map[string]float64{"n": 2}, types.ZAddOptions{})
+	s.PFAdd("hll:copy", []string{"b", "c", "d"})
+	s.BFAdd("bf:copy", "b")
+	s.CFAdd("cf:copy", "b")
+	s.CMSIncrBy("cms:copy", map[string]uint64{"a": 1})
+	s.XAdd("stream:copy", []string{"f", "v"}, types.StreamAddOptions{ID: types.StreamID{Ms: 2}})
+	s.SetBit("bits:copy", 1, 1)
+
+	list, _ := s.LRange("list", 0, -1)
+	assert.Equal(t, []string{"a"}, list)
+	card, _ := s.SCard("ints")
+	assert.Equal(t, int64(1), card)
+	assertEncoding(t, s, "ints:copy", EncIntSet)
+	card, _ = s.SCard("set")
+	assert.Equal(t, int64(1), card)
+	hlen, _ := s.HLen("hash")
+	assert.Equal(t, uint32(1), hlen)
+	zcard, _ := s.ZCard("zset")
+	assert.Equal(t, uint32(1), zcard)
+	count, _ := s.PFCount([]string{"hll"})
+	assert.Equal(t, 1, count)
+	exists, _ := s.BFExists("bf", "b")
+	assert.Zero(t, exists)
+	exists, _ = s.CFExists("cf", "b")
+	assert.Zero(t, exists)
+	counts, _ := s.CMSQuery("cms", []string{"a"})
+	assert.Equal(t, []uint64{0}, counts)
+	length, _ := s.XLen("stream")
+	assert.Equal(t, uint32(1), length)
+	bit, _ := s.GetBit("bits", 1)
+	assert.Zero(t, bit)
+}
+
+func TestCopy_Options(t *testing.T) {
+	s := newTestStoreKeyspace()
+	s.SetEx("src", "v", 100)
+	s.Set("dst", "old")
+
+	copied, err := s.Copy("missing", "dst", true)
+	require.NoError(t, err)
+	assert.False(t, copied)
+
+	copied, _ = s.Copy("src", "dst", false)
+	assert.False(t, copied)
+
+	copied, _ = s.Copy("src", "dst", true)
+	assert.True(t, copied)
+	value, _ := s.Get("dst")
+	assert.Equal(t, "v", *value)
+	assert.Greater(t, s.TTL("dst"), int64(90))
+	assert.True(t, s.Exists("src"))
+}
+
+func TestRandomKey(t *testing.T) {
+	s := newTestStoreKeyspace()
+	assert.Nil(t, s.RandomKey())
+
+	s.Set("expired", "v")
+	s.expires.Set("expired", uint64(time.Now().UnixMilli()-1000))
+	assert.Nil(t, s.RandomKey())
+	assert.Zero(t, s.DBSize())
+
+	s.Set("key", "v")
+	assert.Equal(t, "key", *s.RandomKey())
+}
@@ -1,9 +1,15 @@
 package storage
 
 import (
+	"github.com/manhhung2111/go-redis/internal/config"
 	"github.com/manhhung2111/go-redis/internal/storage/types"
 )
 
+// newQuickList returns an empty list with the node size and compression settings of cfg.
+func newQuickList(cfg *config.Config) types.QuickList {
+	return types.NewQuickList(cfg.ListMaxListpackSize, cfg.ListCompressDepth)
+}
+
 func (s *store) LPush(key string, elements ...string) (uint32, error) {
 	result := s.access(key, ObjList, true)
 	if result.err != nil {
@@ -17,7 +23,7 @@ func (s *store) LPush(key string, elements ...string) (uint32, error) {
 		return res, nil
 	}
 
-	quicklist := types.NewQuickList()
+	quicklist := newQuickList(s.config)
 	res, _ := quicklist.LPush(elements)
 
 	delta := s.data.Set(key, &RObj{
@@ -42,7 +48,7 @@ func (s *store) LPop(key string, count uint32) ([]string, error) {
 
 	quickList := result.object.value.(types.QuickList)
 	poppedElements, delta := quickList.LPop(count)
-	s.usedMemory -= delta
+	s.usedMemory += delta
 	if quickList.Size() == 0 {
 		s.delete(key)
 	}
@@ -63,7 +69,7 @@ func (s *store) RPush(key string, elements ...string) (uint32, error) {
 		return res, nil
 	}
 
-	quicklist := types.NewQuickList()
+	quicklist := newQuickList(s.config)
 	res, _ := quicklist.RPush(elements)
 
 	delta := s.data.Set(key, &RObj{
@@ -88,7 +94,7 @@ func (s *store) RPop(key string, count uint32) ([]string, error) {
 
 	quickList := result.object.value.(types.QuickList)
 	poppedElements, delta := quickList.RPop(count)
-	s.usedMemory -= delta
+	s.usedMemory += delta
 	if quickList.Size() == 0 {
 		s.delete(key)
 	}
@@ -198,3 +204,123 @@ func (s *store) LTrim(key string, start, end int32) error {
 
 	return nil
 }
+
+// LInsert inserts element before or after pivot and returns the new length of the list,
+// -1 when pivot is not found, or 0 when the key does not exist.
+func (s *store) LInsert(key string, before bool, pivot, element string) (int64, error) {
+	result := s.access(key, ObjList, true)
+	if result.err != nil {
+		return 0, result.err
+	}
+
+	if result.expired || !result.exists {
+		return 0, nil
+	}
+
+	quickList := result.object.value.(types.QuickList)
+	inserted, delta := quickList.LInsert(pivot, element, before)
+	if !inserted {
+		return -1, nil
+	}
+
+	s.usedMemory += delta
+	return int64(quickList.Size()), nil
+}
+
+// LPos returns the indexes of the elements of the list equal to element, as described
+// by types.QuickList.LPos.
+func (s *store) LPos(key, element string, rank int64, count, maxLen uint32) ([]uint32, error) {
+	result := s.access(key, ObjList, false)
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	if result.expired || !result.exists {
+		return []uint32{}, nil
+	}
+
+	quickList := result.object.value.(types.QuickList)
+	return quickList.LPos(element, rank, count, maxLen), nil
+}
+
+// LMove pops an element from one end of source and pushes it to one end of destination,
+// which may be the same list. Nothing is popped when destination holds another type, and
+// a list rotated onto itself is never deleted in between, so it keeps its expiration time.
+func (s *store) LMove(source, destination string, fromLeft, toLeft bool) (*string, error) {
+	result := s.access(source, ObjList, true)
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	if result.expired || !result.exists {
+		return nil, nil
+	}
+
+	destResult := s.access(destination, ObjList, true)
+	if destResult.err != nil {
+		return nil, destResult.err
+	}
+
+	srcList := result.object.value.(types.QuickList)
+	var popped []string
+	var delta int64
+	if fromLeft {
+		popped, delta = srcList.LPop(1)
+	} else {
+		popped, delta = srcList.RPop(1)
+	}
+	s.usedMemory += delta
+
+	var dstList types.QuickList
+	if destResult.exists {
+		dstList = destResult.object.value.(types.QuickList)
+	} else {
+		dstList = newQuickList(s.config)
+	}
+
+	if toLeft {
+		_, delta = dstList.LPush(popped)
+	} else {
+		_, delta = dstList.RPush(popped)
+	}
+
+	if destResult.exists {
+		s.usedMemory += delta
+	} else {
+		s.usedMemory += s.data.Set(destination, &RObj{
+			objType:  ObjList,
+			encoding: EncQuickList,
+			value:    dstList,
+		})
+	}
+
+	if srcList.Size() == 0 {
+		s.delete(source)
+	}
+
+	return &popped[0], nil
+}
+
+// LMPop pops up to count elements from the first non-empty list among keys, and returns
+// its key with the elements. The key is empty when every list is empty.
+func (s *store) LMPop(keys []string, fromLeft bool, count uint32) (string, []string, error) {
+	for _, key := range keys {
+		length, err := s.LLen(key)
+		if err != nil {
+			return "", nil, err
+		}
+		if length == 0 {
+			continue
+		}
+
+		var popped []string
+		if fromLeft {
+			popped, err = s.LPop(key, count)
+		} else {
+			popped, err = s.RPop(key, count)
+		}
+		return key, popped, err
+	}
+
+	return "", nil, nil
+}
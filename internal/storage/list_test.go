@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"bytes"
+	"fmt"
 	"testing"
 
 	"github.com/manhhung2111/go-redis/internal/config"
@@ -109,6 +111,17 @@ func TestLPop_DeleteKeyWhenEmpty(t *testing.T) {
 	assert.False(t, exists)
 }
 
+func TestLPop_FreesMemory(t *testing.T) {
+	s := newTestStoreList().(*store)
+	s.RPush("mylist", "one")
+	before := s.usedMemory
+	s.RPush("mylist", "two", "three")
+
+	s.LPop("mylist", 1)
+	s.RPop("mylist", 1)
+	assert.Equal(t, before, s.usedMemory)
+}
+
 func TestLPop_NonExistentKey(t *testing.T) {
 	s := newTestStoreList()
 
@@ -369,3 +382,190 @@ func TestListOperationsIntegration(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "new", *val)
 }
+
+func TestLMove(t *testing.T) {
+	s := newTestStoreList()
+	s.RPush("src", "a", "b", "c")
+
+	moved, err := s.LMove("src", "dst", true, false)
+	require.NoError(t, err)
+	assert.Equal(t, "a", *moved)
+
+	moved, err = s.LMove("src", "dst", false, true)
+	require.NoError(t, err)
+	assert.Equal(t, "c", *moved)
+
+	src, _ := s.LRange("src", 0, -1)
+	assert.Equal(t, []string{"b"}, src)
+	dst, _ := s.LRange("dst", 0, -1)
+	assert.Equal(t, []string{"c", "a"}, dst)
+}
+
+func TestLMove_SameListRotates(t *testing.T) {
+	s := newTestStoreList()
+	s.RPush("list", "a", "b", "c")
+
+	moved, err := s.LMove("list", "list", true, false)
+	require.NoError(t, err)
+	assert.Equal(t, "a", *moved)
+
+	result, _ := s.LRange("list", 0, -1)
+	assert.Equal(t, []string{"b", "c", "a"}, result)
+
+	s.Del("list")
+	s.RPush("single", "x")
+	moved, _ = s.LMove("single", "single", false, true)
+	assert.Equal(t, "x", *moved)
+	result, _ = s.LRange("single", 0, -1)
+	assert.Equal(t, []string{"x"}, result)
+}
+
+func TestLMove_SameListKeepsExpiration(t *testing.T) {
+	s := newTestStoreList()
+	s.RPush("single", "x")
+	s.Expire("single", 100, ExpireOptions{})
+
+	moved, err := s.LMove("single", "single", true, false)
+	require.NoError(t, err)
+	assert.Equal(t, "x", *moved)
+	assert.Greater(t, s.TTL("single"), int64(0))
+}
+
+func TestLMove_DeletesEmptySource(t *testing.T) {
+	s := newTestStoreList()
+	s.RPush("src", "a")
+	s.LMove("src", "dst", true, true)
+
+	assert.False(t, s.Exists("src"))
+	dst, _ := s.LRange("dst", 0, -1)
+	assert.Equal(t, []string{"a"}, dst)
+}
+
+func TestLMove_MissingSource(t *testing.T) {
+	s := newTestStoreList()
+
+	moved, err := s.LMove("missing", "dst", true, true)
+	require.NoError(t, err)
+	assert.Nil(t, moved)
+	assert.False(t, s.Exists("dst"))
+}
+
+func TestLMove_WrongType(t *testing.T) {
+	s := newTestStoreList()
+	s.RPush("src", "a")
+	s.Set("str", "value")
+
+	_, err := s.LMove("src", "str", true, true)
+	assert.ErrorIs(t, err, ErrWrongTypeError)
+	length, _ := s.LLen("src")
+	assert.Equal(t, uint32(1), length)
+
+	_, err = s.LMove("str", "dst", true, true)
+	assert.ErrorIs(t, err, ErrWrongTypeError)
+}
+
+func TestLInsert(t *testing.T) {
+	s := newTestStoreList()
+	s.RPush("list", "Hello", "World")
+
+	length, err := s.LInsert("list", true, "World", "There")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), length)
+
+	length, _ = s.LInsert("list", false, "World", "!")
+	assert.Equal(t, int64(4), length)
+
+	result, _ := s.LRange("list", 0, -1)
+	assert.Equal(t, []string{"Hello", "There", "World", "!"}, result)
+
+	length, _ = s.LInsert("list", true, "missing", "x")
+	assert.Equal(t, int64(-1), length)
+
+	length, _ = s.LInsert("nokey", true, "World", "x")
+	assert.Equal(t, int64(0), length)
+	assert.False(t, s.Exists("nokey"))
+
+	s.Set("str", "value")
+	_, err = s.LInsert("str", true, "a", "b")
+	assert.ErrorIs(t, err, ErrWrongTypeError)
+}
+
+func TestLPos(t *testing.T) {
+	s := newTestStoreList()
+	s.RPush("list", "a", "b", "c", "1", "2", "3", "c", "c")
+
+	positions, err := s.LPos("list", "c", -1, 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{7, 6}, positions)
+
+	positions, _ = s.LPos("nokey", "c", 1, 0, 0)
+	assert.Empty(t, positions)
+
+	s.Set("str", "value")
+	_, err = s.LPos("str", "c", 1, 0, 0)
+	assert.ErrorIs(t, err, ErrWrongTypeError)
+}
+
+func TestLMPop(t *testing.T) {
+	s := newTestStoreList()
+	s.RPush("list2", "a", "b", "c")
+
+	key, popped, err := s.LMPop([]string{"list1", "list2"}, false, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "list2", key)
+	assert.Equal(t, []string{"c", "b"}, popped)
+
+	key, popped, _ = s.LMPop([]string{"list1", "list2"}, true, 10)
+	assert.Equal(t, "list2", key)
+	assert.Equal(t, []string{"a"}, popped)
+	assert.False(t, s.Exists("list2"))
+
+	key, popped, _ = s.LMPop([]string{"list1", "list2"}, true, 1)
+	assert.Equal(t, "", key)
+	assert.Nil(t, popped)
+
+	s.Set("str", "value")
+	s.RPush("list3", "a")
+	_, _, err = s.LMPop([]string{"str", "list3"}, true, 1)
+	assert.ErrorIs(t, err, ErrWrongTypeError)
+}
+
+func TestList_CompressedNodes(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.ListMaxListpackSize = 16
+	cfg.ListCompressDepth = 1
+	s := NewStore(cfg).(*store)
+	plain := newTestStoreList().(*store)
+
+	elements := make([]string, 500)
+	for i := range elements {
+		elements[i] = fmt.Sprintf("audit entry %05d: user logged in from the usual address", i)
+	}
+	s.RPush("audit", elements...)
+	s.RPush("audit", "last")
+	plain.RPush("audit", elements...)
+	plain.RPush("audit", "last")
+	assert.Less(t, s.usedMemory, plain.usedMemory)
+
+	value, _ := s.LIndex("audit", 250)
+	assert.Equal(t, elements[250], *value)
+	require.NoError(t, s.LSet("audit", 250, "changed"))
+	length, _ := s.LInsert("audit", true, elements[300], "inserted")
+	assert.Equal(t, int64(502), length)
+
+	copied, err := s.Copy("audit", "copy", false)
+	require.NoError(t, err)
+	assert.True(t, copied)
+	original, _ := s.LRange("audit", 0, -1)
+	clone, _ := s.LRange("copy", 0, -1)
+	assert.Equal(t, original, clone)
+	assert.Equal(t, "changed", original[250])
+	assert.Equal(t, "inserted", original[300])
+
+	var buf bytes.Buffer
+	require.NoError(t, s.WriteSnapshot(&buf))
+	loaded := NewStore(cfg).(*store)
+	require.NoError(t, loaded.ReadSnapshot(&buf))
+	restored, _ := loaded.LRange("audit", 0, -1)
+	assert.Equal(t, original, restored)
+}
@@ -15,6 +15,7 @@ const (
 	ObjCuckooFilter
 	ObjHyperLogLog
 	ObjCountMinSketch
+	ObjStream
 
 	// ObjAny is a sentinel value to skip type checking in access()
 	ObjAny ObjectType = 255
@@ -31,8 +32,59 @@ const (
 	EncCuckooFilter
 	EncHyperLogLog
 	EncCountMinSketch
+	EncStream
+	EncListPack
+	EncRawBytes // []byte, a string changed in place by APPEND, SETRANGE, SETBIT and BITFIELD
 )
 
+// typeNames are the names of the types as reported by Redis, modules included.
+var typeNames = map[ObjectType]string{
+	ObjString:         "string",
+	ObjSet:            "set",
+	ObjList:           "list",
+	ObjHash:           "hash",
+	ObjZSet:           "zset",
+	ObjBloomFilter:    "MBbloom--",
+	ObjCuckooFilter:   "MBbloomCF",
+	ObjHyperLogLog:    "string",
+	ObjCountMinSketch: "CMSk-TYPE",
+	ObjStream:         "stream",
+}
+
+func (t ObjectType) String() string {
+	return typeNames[t]
+}
+
+// encodingNames are the names of the encodings as reported by OBJECT ENCODING. Module types
+// and HyperLogLogs are plain strings to Redis.
+var encodingNames = map[ObjectEncoding]string{
+	EncRaw:            "raw",
+	EncInt:            "int",
+	EncIntSet:         "intset",
+	EncHashTable:      "hashtable",
+	EncQuickList:      "quicklist",
+	EncSortedSet:      "skiplist",
+	EncBloomFilter:    "raw",
+	EncCuckooFilter:   "raw",
+	EncHyperLogLog:    "raw",
+	EncCountMinSketch: "raw",
+	EncStream:         "stream",
+	EncListPack:       "listpack",
+	EncRawBytes:       "raw",
+}
+
+// embstrSizeLimit is the length up to which Redis embeds a string in its object header.
+const embstrSizeLimit = 44
+
+// encodingName returns the name of the encoding of obj. Raw strings short enough to be
+// embedded by Redis are reported as embstr.
+func (obj *RObj) encodingName() string {
+	if obj.encoding == EncRaw && obj.objType == ObjString && len(obj.value.(string)) <= embstrSizeLimit {
+		return "embstr"
+	}
+	return encodingNames[obj.encoding]
+}
+
 type RObj struct {
 	objType  ObjectType
 	encoding ObjectEncoding
@@ -41,31 +93,49 @@ type RObj struct {
 }
 
 type evictionPoolEntry struct {
+	db   *store
 	key  string
 	idle uint32
 }
 
+// store is one logical database.
 type store struct {
-	config       *config.Config
-	data         Dict[string, *RObj]
-	expires      Dict[string, uint64]
-	evictionPool []*evictionPoolEntry
-	usedMemory   int64 // Memory usage in bytes, accounting only for data and expires dictionaries (excludes eviction pool)
+	config         *config.Config
+	dbs            *databases // databases the store belongs to, which share the memory limit
+	data           Dict[string, *RObj]
+	expires        Dict[string, uint64]
+	volatileHashes Dict[string, struct{}] // keys of the hashes with volatile fields, see trackVolatileHash
+	usedMemory     int64                  // Memory usage in bytes, accounting only for data and expires dictionaries (excludes eviction pool)
+	watched        map[string]*watchedKey
+	blocked        map[string]int      // number of clients blocked on each key
+	readyKeys      []string            // keys with blocked clients written since the last ReadyKeys call
+	readySet       map[string]struct{} // set of readyKeys
 }
 
+// NewStore returns a standalone database, which has the memory limit to itself.
 func NewStore(cfg *config.Config) Store {
-	data, delta1 := newDict[string, *RObj]()
-	expires, delta2 := newDict[string, uint64]()
-	return &store{
-		config:       cfg,
-		data:         data,
-		expires:      expires,
-		evictionPool: make([]*evictionPoolEntry, 0, cfg.EvictionPoolSize),
-		usedMemory:   delta1 + delta2,
+	return newDatabases(cfg, 1).dbs[0]
+}
+
+func newStore(cfg *config.Config, dbs *databases) *store {
+	s := &store{
+		config:   cfg,
+		dbs:      dbs,
+		watched:  make(map[string]*watchedKey),
+		blocked:  make(map[string]int),
+		readySet: make(map[string]struct{}),
 	}
+	s.clear()
+	return s
 }
 
-func (s *store) Exists(key string) bool {
-	result := s.access(key, ObjAny, false)
-	return result.object != nil
+// clear replaces the keys of s with empty dictionaries.
+func (s *store) clear() {
+	data, delta1 := newDict[string, *RObj]()
+	expires, delta2 := newDict[string, uint64]()
+	volatileHashes, delta3 := newDict[string, struct{}]()
+	s.data = data
+	s.expires = expires
+	s.volatileHashes = volatileHashes
+	s.usedMemory = delta1 + delta2 + delta3
 }
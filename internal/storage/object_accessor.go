@@ -19,14 +19,14 @@ func (s *store) access(key string, expectedType ObjectType, isWrite bool) storag
 	result := storageAccessResult{}
 
 	// Check memory limit for write operations before proceeding
-	if isWrite && s.usedMemory > s.config.MaxmemoryLimit {
+	if isWrite && s.dbs.usedMemory() > s.config.MaxmemoryLimit {
 		if s.config.EvictionPolicy == config.NoEviction {
 			result.err = ErrOutOfMemoryError
 			return result
 		}
-		s.performEvictions()
+		s.dbs.performEvictions()
 		// Check again after eviction
-		if s.usedMemory > s.config.MaxmemoryLimit {
+		if s.dbs.usedMemory() > s.config.MaxmemoryLimit {
 			result.err = ErrOutOfMemoryError
 			return result
 		}
@@ -41,6 +41,13 @@ func (s *store) access(key string, expectedType ObjectType, isWrite bool) storag
 	}
 
 	obj, exists := s.data.Get(key)
+	if exists && obj.objType == ObjHash {
+		if _, deleted := s.expireFields(key, obj); deleted {
+			result.expired = true
+			return result
+		}
+	}
+
 	if exists {
 		switch s.config.EvictionPolicy {
 		case config.AllKeysLRU, config.VolatileLRU:
@@ -61,6 +68,8 @@ func (s *store) access(key string, expectedType ObjectType, isWrite bool) storag
 	// Skip type check if expectedType is ObjAny
 	if exists && expectedType != ObjAny && obj.objType != expectedType {
 		result.err = ErrWrongTypeError
+	} else if isWrite {
+		s.touch(key)
 	}
 
 	return result
@@ -74,6 +83,19 @@ func (s *store) delete(key string) bool {
 	}
 
 	_, delta2 := s.expires.Delete(key)
-	s.usedMemory -= delta1 + delta2
+	_, delta3 := s.volatileHashes.Delete(key)
+	s.usedMemory += delta1 + delta2 + delta3
+	s.touch(key)
 	return true
 }
+
+// touch marks a modification of key for the clients watching it or blocked on it.
+func (s *store) touch(key string) {
+	if w, ok := s.watched[key]; ok {
+		w.version++
+	}
+
+	if _, ok := s.blocked[key]; ok {
+		s.markReady(key)
+	}
+}
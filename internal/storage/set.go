@@ -4,6 +4,7 @@ import (
 	"math/rand"
 	"strconv"
 
+	"github.com/manhhung2111/go-redis/internal/config"
 	"github.com/manhhung2111/go-redis/internal/storage/types"
 )
 
@@ -13,65 +14,74 @@ func (s *store) SAdd(key string, members ...string) (int64, error) {
 		return 0, result.err
 	}
 
-	if result.exists {
-		rObj := result.object
-		if rObj.encoding == EncIntSet {
-			// Upgrade to SimpleSet if one of the following condition holds:
-			// members contain an element can not be converted to int64
-			// adding members resulting in exceeding the config.SET_MAX_INTSET_ENTRIES
-			intset := rObj.value.(types.Set)
-			added, succeeded, delta := intset.Add(members...)
-			if !succeeded {
-				// Upgrade to SimpleSet
-				simpleSet := types.NewSimpleSet()
-				simpleSet.Add(intset.Members()...)
-				added, _, delta := simpleSet.Add(members...)
-
-				// Update existing RObj
-				rObj.encoding = EncHashTable
-				rObj.value = simpleSet
-				s.usedMemory += delta
-
-				return added, nil
-			} else {
-				s.usedMemory += delta
-				return added, nil
-			}
-		}
+	if !result.exists {
+		rObj := newSetObject(s.config, members)
+		s.usedMemory += s.data.Set(key, rObj)
+		return rObj.value.(types.Set).Size(), nil
+	}
 
-		simpleSet := rObj.value.(types.Set)
-		added, _, delta := simpleSet.Add(members...)
-		s.usedMemory += delta
-		return added, nil
+	set := s.setForAdd(result.object, members)
+	added, succeeded, delta := set.Add(members...)
+	if !succeeded {
+		// The intset is full
+		s.convertSet(result.object, false)
+		added, _, delta = result.object.value.(types.Set).Add(members...)
 	}
 
-	// Key doesn't exist - create new set
-	if s.canBeConvertedToInt64(members...) {
+	s.usedMemory += delta
+	return added, nil
+}
+
+// newSetObject returns a set of members in the most compact encoding that fits them: an
+// intset when all of them are integers, a listpack when they are few and short.
+func newSetObject(cfg *config.Config, members []string) *RObj {
+	if canBeConvertedToInt64(cfg, members...) {
 		intset := types.NewIntSet()
-		added, succeeded, _ := intset.Add(members...)
-		if succeeded {
-			delta := s.data.Set(key, &RObj{
-				objType:  ObjSet,
-				encoding: EncIntSet,
-				value:    intset,
-			})
-			s.usedMemory += delta
-			return added, nil
+		if _, succeeded, _ := intset.Add(members...); succeeded {
+			return &RObj{objType: ObjSet, encoding: EncIntSet, value: intset}
 		}
-		// If IntSet failed (capacity), fall through to SimpleSet
+		// If IntSet failed (capacity), fall through to the next encoding
 	}
 
-	simpleSet := types.NewSimpleSet()
-	added, _, _ := simpleSet.Add(members...)
+	rObj := &RObj{objType: ObjSet, encoding: EncHashTable, value: types.NewSimpleSet()}
+	if listPackFits(len(members), cfg.SetMaxListpackEntries, cfg.SetMaxListpackValue, members...) {
+		rObj.encoding, rObj.value = EncListPack, types.NewListPackSet()
+	}
 
-	delta := s.data.Set(key, &RObj{
-		objType:  ObjSet,
-		encoding: EncHashTable,
-		value:    simpleSet,
-	})
-	s.usedMemory += delta
+	rObj.value.(types.Set).Add(members...)
+	return rObj
+}
 
-	return added, nil
+// setForAdd returns the set of rObj to add members to, converted first when the members
+// would not fit its encoding.
+func (s *store) setForAdd(rObj *RObj, members []string) types.Set {
+	set := rObj.value.(types.Set)
+	size := int(set.Size()) + len(members)
+
+	switch rObj.encoding {
+	case EncIntSet:
+		if !canBeConvertedToInt64(s.config, members...) || size > s.config.SetMaxIntsetEntries {
+			s.convertSet(rObj, listPackFits(size, s.config.SetMaxListpackEntries, s.config.SetMaxListpackValue, members...))
+		}
+	case EncListPack:
+		if !listPackFits(size, s.config.SetMaxListpackEntries, s.config.SetMaxListpackValue, members...) {
+			s.convertSet(rObj, false)
+		}
+	}
+
+	return rObj.value.(types.Set)
+}
+
+// convertSet converts the set of rObj to a listpack when toListPack, to a hash table
+// otherwise.
+func (s *store) convertSet(rObj *RObj, toListPack bool) {
+	set, encoding := types.NewSimpleSet(), EncHashTable
+	if toListPack {
+		set, encoding = types.NewListPackSet(), EncListPack
+	}
+
+	set.Add(rObj.value.(types.Set).Members()...)
+	s.convertObject(rObj, encoding, set)
 }
 
 func (s *store) SCard(key string) (int64, error) {
@@ -116,6 +126,27 @@ func (s *store) SMembers(key string) ([]string, error) {
 	return set.Members(), nil
 }
 
+// SScan returns the next cursor and up to count members matching pattern, see Scan.
+func (s *store) SScan(key string, cursor uint64, pattern string, count int) (uint64, []string, error) {
+	result := s.access(key, ObjSet, false)
+	if result.err != nil {
+		return 0, nil, result.err
+	}
+
+	if result.expired || !result.exists {
+		return 0, []string{}, nil
+	}
+
+	next, members := result.object.value.(types.Set).Scan(cursor, count)
+	matched := members[:0]
+	for _, member := range members {
+		if scanMatch(pattern, member) {
+			matched = append(matched, member)
+		}
+	}
+	return next, matched, nil
+}
+
 func (s *store) SMIsMember(key string, members ...string) ([]bool, error) {
 	defaultResult := make([]bool, len(members))
 
@@ -238,8 +269,150 @@ func (s *store) SRandMember(key string, count int) ([]string, error) {
 	return selected, nil
 }
 
-func (s *store) canBeConvertedToInt64(members ...string) bool {
-	if len(members) == 0 || len(members) > s.config.SetMaxIntsetEntries {
+// SMove moves member from the set at source to the set at destination, and reports
+// whether source held it. The source set is deleted once empty.
+func (s *store) SMove(source, destination, member string) (bool, error) {
+	result := s.access(source, ObjSet, true)
+	if result.err != nil {
+		return false, result.err
+	}
+
+	if result.expired || !result.exists {
+		return false, nil
+	}
+
+	if destResult := s.access(destination, ObjSet, false); destResult.err != nil {
+		return false, destResult.err
+	}
+
+	set := result.object.value.(types.Set)
+	if !set.IsMember(member) {
+		return false, nil
+	}
+
+	if source == destination {
+		return true, nil
+	}
+
+	_, delta := set.Delete(member)
+	s.usedMemory += delta
+	if set.Size() == 0 {
+		s.delete(source)
+	}
+
+	if _, err := s.SAdd(destination, member); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SInter returns the members of the intersection of the sets at keys. Missing keys are
+// empty sets.
+func (s *store) SInter(keys ...string) ([]string, error) {
+	sets, err := s.sets(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.SetIntersection(sets, 0), nil
+}
+
+// SInterCard returns the size of the intersection of the sets at keys, counting at most
+// limit members when limit is positive.
+func (s *store) SInterCard(limit int, keys ...string) (int64, error) {
+	sets, err := s.sets(keys)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(types.SetIntersection(sets, limit))), nil
+}
+
+// SUnion returns the members of the union of the sets at keys.
+func (s *store) SUnion(keys ...string) ([]string, error) {
+	sets, err := s.sets(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.SetUnion(sets), nil
+}
+
+// SDiff returns the members of the first set at keys that are in none of the others.
+func (s *store) SDiff(keys ...string) ([]string, error) {
+	sets, err := s.sets(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.SetDifference(sets), nil
+}
+
+// SInterStore stores the intersection of the sets at keys at dst, see storeSet.
+func (s *store) SInterStore(dst string, keys ...string) (int64, error) {
+	members, err := s.SInter(keys...)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.storeSet(dst, members)
+}
+
+// SUnionStore stores the union of the sets at keys at dst, see storeSet.
+func (s *store) SUnionStore(dst string, keys ...string) (int64, error) {
+	members, err := s.SUnion(keys...)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.storeSet(dst, members)
+}
+
+// SDiffStore stores the difference of the sets at keys at dst, see storeSet.
+func (s *store) SDiffStore(dst string, keys ...string) (int64, error) {
+	members, err := s.SDiff(keys...)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.storeSet(dst, members)
+}
+
+// storeSet replaces dst, whatever its type, with a set of members in the encoding that
+// fits them, and returns the size of the set. An empty set deletes dst.
+func (s *store) storeSet(dst string, members []string) (int64, error) {
+	if result := s.access(dst, ObjAny, true); result.err != nil {
+		return 0, result.err
+	}
+
+	if len(members) == 0 {
+		s.delete(dst)
+		return 0, nil
+	}
+
+	rObj := newSetObject(s.config, members)
+	s.setObject(dst, rObj, 0, false)
+	return rObj.value.(types.Set).Size(), nil
+}
+
+// sets returns the sets at keys, nil for the keys that do not exist.
+func (s *store) sets(keys []string) ([]types.Set, error) {
+	sets := make([]types.Set, len(keys))
+	for i, key := range keys {
+		result := s.access(key, ObjSet, false)
+		if result.err != nil {
+			return nil, result.err
+		}
+		if result.exists {
+			sets[i] = result.object.value.(types.Set)
+		}
+	}
+
+	return sets, nil
+}
+
+func canBeConvertedToInt64(cfg *config.Config, members ...string) bool {
+	if len(members) == 0 || len(members) > cfg.SetMaxIntsetEntries {
 		return false
 	}
 
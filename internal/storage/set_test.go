@@ -33,7 +33,7 @@ func TestSAdd_SimpleSet_NonInteger(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.Equal(t, added, int64(2), "members added")
-	assertEncoding(t, s, "myset", EncHashTable)
+	assertEncoding(t, s, "myset", EncListPack)
 }
 
 func TestSAdd_SimpleSet_MixedValues(t *testing.T) {
@@ -42,7 +42,7 @@ func TestSAdd_SimpleSet_MixedValues(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.Equal(t, added, int64(3), "members added")
-	assertEncoding(t, s, "myset", EncHashTable)
+	assertEncoding(t, s, "myset", EncListPack)
 }
 
 func TestSAdd_UpgradeToSimpleSet_NonInteger(t *testing.T) {
@@ -53,7 +53,7 @@ func TestSAdd_UpgradeToSimpleSet_NonInteger(t *testing.T) {
 	added, err := s.SAdd("myset", "hello")
 	assert.NoError(t, err)
 	assert.Equal(t, added, int64(1), "members added")
-	assertEncoding(t, s, "myset", EncHashTable)
+	assertEncoding(t, s, "myset", EncListPack)
 
 	size, err := s.SCard("myset")
 	assert.Equal(t, size, int64(4), "set size after upgrade")
@@ -501,7 +501,7 @@ func TestIntegration_UpgradePreservesAllOperations(t *testing.T) {
 
 	// Upgrade
 	s.SAdd("myset", "hello")
-	assertEncoding(t, s, "myset", EncHashTable)
+	assertEncoding(t, s, "myset", EncListPack)
 
 	// All operations work
 	isMember, _ = s.SIsMember("myset", "3")
@@ -540,3 +540,106 @@ func assertEncoding(t *testing.T, s *store, key string, expected ObjectEncoding)
 
 	assert.Equal(t, ObjectEncoding(expected), rObj.encoding)
 }
+
+func TestSMove(t *testing.T) {
+	s := newTestStoreSet()
+	s.SAdd("src", "a", "b")
+	s.SAdd("dst", "1")
+
+	moved, err := s.SMove("src", "dst", "a")
+	require.NoError(t, err)
+	assert.True(t, moved)
+	members, _ := s.SMembers("dst")
+	assert.ElementsMatch(t, []string{"1", "a"}, members)
+	assertEncoding(t, s.(*store), "dst", EncListPack)
+
+	moved, _ = s.SMove("src", "dst", "missing")
+	assert.False(t, moved)
+
+	moved, _ = s.SMove("src", "src", "b")
+	assert.True(t, moved)
+
+	// The last member deletes the source and creates the destination
+	moved, _ = s.SMove("src", "new", "b")
+	assert.True(t, moved)
+	assert.False(t, s.Exists("src"))
+	members, _ = s.SMembers("new")
+	assert.Equal(t, []string{"b"}, members)
+
+	moved, _ = s.SMove("missing", "dst", "a")
+	assert.False(t, moved)
+}
+
+func TestSMove_WrongType(t *testing.T) {
+	s := newTestStoreSet()
+	s.SAdd("src", "a")
+	s.Set("str", "value")
+
+	_, err := s.SMove("src", "str", "a")
+	assert.ErrorIs(t, err, ErrWrongTypeError)
+	isMember, _ := s.SIsMember("src", "a")
+	assert.True(t, isMember)
+
+	_, err = s.SMove("str", "src", "a")
+	assert.ErrorIs(t, err, ErrWrongTypeError)
+}
+
+func TestSetAlgebra(t *testing.T) {
+	s := newTestStoreSet()
+	s.SAdd("key1", "a", "b", "c", "d")
+	s.SAdd("key2", "c")
+	s.SAdd("key3", "a", "c", "e")
+
+	members, err := s.SInter("key1", "key2", "key3")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, members)
+
+	members, _ = s.SInter("key1", "missing")
+	assert.Empty(t, members)
+
+	members, _ = s.SUnion("key1", "key2", "key3", "missing")
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d", "e"}, members)
+
+	members, _ = s.SDiff("key1", "key2", "key3")
+	assert.ElementsMatch(t, []string{"b", "d"}, members)
+
+	count, _ := s.SInterCard(0, "key1", "key3")
+	assert.Equal(t, int64(2), count)
+	count, _ = s.SInterCard(1, "key1", "key3")
+	assert.Equal(t, int64(1), count)
+
+	s.Set("str", "value")
+	_, err = s.SUnion("key1", "str")
+	assert.ErrorIs(t, err, ErrWrongTypeError)
+}
+
+func TestSetAlgebraStore(t *testing.T) {
+	s := newTestStoreSet().(*store)
+	s.SAdd("ints", "1", "2", "3")
+	s.SAdd("mixed", "2", "3", "x")
+	s.Set("dst", "value")
+	s.Expire("dst", 100, ExpireOptions{})
+
+	size, err := s.SInterStore("dst", "ints", "mixed")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), size)
+	assertEncoding(t, s, "dst", EncIntSet)
+	assert.Equal(t, int64(-1), s.TTL("dst"))
+
+	size, _ = s.SUnionStore("dst", "ints", "mixed")
+	assert.Equal(t, int64(4), size)
+	assertEncoding(t, s, "dst", EncListPack)
+
+	size, _ = s.SDiffStore("dst", "mixed", "ints")
+	assert.Equal(t, int64(1), size)
+	members, _ := s.SMembers("dst")
+	assert.Equal(t, []string{"x"}, members)
+
+	// The destination may be one of the sources
+	size, _ = s.SUnionStore("ints", "ints", "dst")
+	assert.Equal(t, int64(4), size)
+
+	size, _ = s.SInterStore("dst", "ints", "missing")
+	assert.Equal(t, int64(0), size)
+	assert.False(t, s.Exists("dst"))
+}
@@ -0,0 +1,515 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/storage/types"
+)
+
+// Opcodes and value types of the snapshot body. Every key is written as
+// [rdbOpExpireMs <unix ms>] <type> <key> <value>, the keys of each database follow an
+// rdbOpSelectDB <index>, and the body ends with rdbOpEOF. Keys before the first
+// rdbOpSelectDB belong to the first database. Hashes with volatile fields are written as
+// rdbTypeHashWithFieldExpires, followed by the expiration times of those fields.
+const (
+	rdbTypeStringRaw byte = iota
+	rdbTypeStringInt
+	rdbTypeList
+	rdbTypeSetIntSet
+	rdbTypeSet
+	rdbTypeHash
+	rdbTypeZSet
+	rdbTypeBloomFilter
+	rdbTypeCuckooFilter
+	rdbTypeHyperLogLog
+	rdbTypeCountMinSketch
+	rdbTypeStream
+	rdbTypeHashWithFieldExpires
+
+	rdbOpExpireMs byte = 0xFC
+	rdbOpSelectDB byte = 0xFE
+	rdbOpEOF      byte = 0xFF
+)
+
+// Upper bound for a single string in a snapshot, matching the protocol bulk string limit
+const maxSnapshotStringLen = 512 * 1024 * 1024
+
+var ErrCorruptSnapshot = errors.New("corrupt snapshot")
+
+// WriteSnapshot serializes every database that has keys.
+func (dbs *databases) WriteSnapshot(w io.Writer) error {
+	sw := &snapshotWriter{w: bufio.NewWriter(w)}
+	now := uint64(time.Now().UnixMilli())
+
+	for i, db := range dbs.dbs {
+		if db.data.Empty() {
+			continue
+		}
+		sw.byte(rdbOpSelectDB)
+		sw.uvarint(uint64(i))
+		db.writeKeys(sw, now)
+	}
+
+	return sw.end()
+}
+
+// ReadSnapshot loads the keys written by WriteSnapshot into their databases, like
+// Store.ReadSnapshot.
+func (dbs *databases) ReadSnapshot(r io.Reader) error {
+	return readSnapshot(r, dbs.dbs)
+}
+
+// WriteSnapshot serializes every key that has not expired yet, together with its absolute expiration time.
+func (s *store) WriteSnapshot(w io.Writer) error {
+	sw := &snapshotWriter{w: bufio.NewWriter(w)}
+	s.writeKeys(sw, uint64(time.Now().UnixMilli()))
+	return sw.end()
+}
+
+// ReadSnapshot loads the keys written by WriteSnapshot, replacing existing keys with the same name.
+// Keys whose expiration time has already passed are skipped. When r is an io.ByteReader,
+// nothing past the end of the snapshot is consumed, so it can be followed by other data.
+func (s *store) ReadSnapshot(r io.Reader) error {
+	return readSnapshot(r, []*store{s})
+}
+
+func (s *store) writeKeys(sw *snapshotWriter, now uint64) {
+	s.data.ForEach(func(key string, obj *RObj) bool {
+		expireAt, hasExpire := s.expires.Get(key)
+		if hasExpire && expireAt <= now {
+			return true
+		}
+
+		if hasExpire {
+			sw.byte(rdbOpExpireMs)
+			sw.uvarint(expireAt)
+		}
+
+		sw.object(key, obj)
+		return sw.err == nil
+	})
+}
+
+// readSnapshot loads a snapshot body into dbs, starting with the first database.
+func readSnapshot(r io.Reader, dbs []*store) error {
+	br, ok := r.(snapshotSource)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	sr := &snapshotReader{r: br, config: dbs[0].config}
+	now := uint64(time.Now().UnixMilli())
+	s := dbs[0]
+
+	for {
+		var expireAt uint64
+		opcode := sr.byte()
+		if opcode == rdbOpSelectDB {
+			index := sr.uvarint()
+			if sr.err != nil {
+				return sr.err
+			}
+			if index >= uint64(len(dbs)) {
+				return fmt.Errorf("%w: the snapshot has database %d, only %d databases are configured",
+					ErrCorruptSnapshot, index, len(dbs))
+			}
+			s = dbs[index]
+			continue
+		}
+
+		if opcode == rdbOpExpireMs {
+			expireAt = sr.uvarint()
+			opcode = sr.byte()
+		}
+
+		if sr.err != nil {
+			return sr.err
+		}
+
+		if opcode == rdbOpEOF {
+			return nil
+		}
+
+		key := sr.string()
+		obj := sr.object(opcode)
+		if sr.err != nil {
+			return sr.err
+		}
+
+		if expireAt != 0 && expireAt <= now {
+			continue
+		}
+
+		// Fields that expired since the snapshot was written are dropped, with their hash
+		// once no field is left
+		if hash, ok := obj.value.(types.Hash); ok {
+			if hash.ExpireFields(int64(now)); hash.Size() == 0 {
+				continue
+			}
+		}
+
+		s.delete(key)
+		s.usedMemory += s.data.Set(key, obj)
+		if expireAt != 0 {
+			s.usedMemory += s.expires.Set(key, expireAt)
+		}
+		s.trackObject(key, obj)
+	}
+}
+
+type snapshotWriter struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+// end writes rdbOpEOF and flushes the snapshot.
+func (sw *snapshotWriter) end() error {
+	sw.byte(rdbOpEOF)
+	if sw.err != nil {
+		return sw.err
+	}
+
+	return sw.w.Flush()
+}
+
+func (sw *snapshotWriter) byte(b byte) {
+	if sw.err == nil {
+		sw.err = sw.w.WriteByte(b)
+	}
+}
+
+func (sw *snapshotWriter) uvarint(v uint64) {
+	if sw.err == nil {
+		n := binary.PutUvarint(sw.buf[:], v)
+		_, sw.err = sw.w.Write(sw.buf[:n])
+	}
+}
+
+func (sw *snapshotWriter) varint(v int64) {
+	if sw.err == nil {
+		n := binary.PutVarint(sw.buf[:], v)
+		_, sw.err = sw.w.Write(sw.buf[:n])
+	}
+}
+
+func (sw *snapshotWriter) float64(f float64) {
+	if sw.err == nil {
+		binary.LittleEndian.PutUint64(sw.buf[:8], math.Float64bits(f))
+		_, sw.err = sw.w.Write(sw.buf[:8])
+	}
+}
+
+func (sw *snapshotWriter) string(str string) {
+	sw.uvarint(uint64(len(str)))
+	if sw.err == nil {
+		_, sw.err = sw.w.WriteString(str)
+	}
+}
+
+func (sw *snapshotWriter) strings(strs []string) {
+	sw.uvarint(uint64(len(strs)))
+	for _, str := range strs {
+		sw.string(str)
+	}
+}
+
+func (sw *snapshotWriter) blob(m interface{ MarshalBinary() ([]byte, error) }) {
+	if sw.err != nil {
+		return
+	}
+
+	data, err := m.MarshalBinary()
+	if err != nil {
+		sw.err = err
+		return
+	}
+
+	sw.string(string(data))
+}
+
+func (sw *snapshotWriter) object(key string, obj *RObj) {
+	switch obj.objType {
+	case ObjString:
+		if obj.encoding == EncInt {
+			sw.byte(rdbTypeStringInt)
+			sw.string(key)
+			sw.varint(obj.value.(int64))
+			return
+		}
+		sw.byte(rdbTypeStringRaw)
+		sw.string(key)
+		sw.string(stringValue(obj))
+
+	case ObjList:
+		sw.byte(rdbTypeList)
+		sw.string(key)
+		sw.strings(obj.value.(types.QuickList).LRange(0, -1))
+
+	case ObjSet:
+		if obj.encoding == EncIntSet {
+			sw.byte(rdbTypeSetIntSet)
+		} else {
+			sw.byte(rdbTypeSet)
+		}
+		sw.string(key)
+		sw.strings(obj.value.(types.Set).Members())
+
+	case ObjHash:
+		hash := obj.value.(types.Hash)
+		if hash.VolatileSize() == 0 {
+			sw.byte(rdbTypeHash)
+			sw.string(key)
+			sw.strings(hash.GetAll())
+			return
+		}
+
+		sw.byte(rdbTypeHashWithFieldExpires)
+		sw.string(key)
+		sw.strings(hash.GetAll())
+		expireTimes := fieldExpireTimes(hash)
+		sw.uvarint(uint64(len(expireTimes)))
+		for field, expireAt := range expireTimes {
+			sw.string(field)
+			sw.varint(expireAt)
+		}
+
+	case ObjZSet:
+		sw.byte(rdbTypeZSet)
+		sw.string(key)
+		memberScores := obj.value.(types.ZSet).ZRangeByRank(0, -1, true)
+		sw.uvarint(uint64(len(memberScores) / 2))
+		for i := 0; i < len(memberScores); i += 2 {
+			score, _ := strconv.ParseFloat(memberScores[i+1], 64)
+			sw.string(memberScores[i])
+			sw.float64(score)
+		}
+
+	case ObjBloomFilter:
+		sw.byte(rdbTypeBloomFilter)
+		sw.string(key)
+		sw.blob(obj.value.(types.ScalableBloomFilter))
+
+	case ObjCuckooFilter:
+		sw.byte(rdbTypeCuckooFilter)
+		sw.string(key)
+		sw.blob(obj.value.(types.CuckooFilter))
+
+	case ObjHyperLogLog:
+		sw.byte(rdbTypeHyperLogLog)
+		sw.string(key)
+		sw.blob(obj.value.(types.HyperLogLog))
+
+	case ObjCountMinSketch:
+		sw.byte(rdbTypeCountMinSketch)
+		sw.string(key)
+		sw.blob(obj.value.(types.CountMinSketch))
+
+	case ObjStream:
+		sw.byte(rdbTypeStream)
+		sw.string(key)
+		sw.blob(obj.value.(types.Stream))
+
+	default:
+		sw.err = fmt.Errorf("cannot snapshot object of type %d", obj.objType)
+	}
+}
+
+type snapshotSource interface {
+	io.Reader
+	io.ByteReader
+}
+
+type snapshotReader struct {
+	r      snapshotSource
+	config *config.Config // node settings of the lists read
+	err    error
+}
+
+func (sr *snapshotReader) fail(err error) {
+	if sr.err != nil {
+		return
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		err = ErrCorruptSnapshot
+	}
+	sr.err = err
+}
+
+func (sr *snapshotReader) byte() byte {
+	if sr.err != nil {
+		return 0
+	}
+
+	b, err := sr.r.ReadByte()
+	if err != nil {
+		sr.fail(err)
+	}
+	return b
+}
+
+func (sr *snapshotReader) uvarint() uint64 {
+	if sr.err != nil {
+		return 0
+	}
+
+	v, err := binary.ReadUvarint(sr.r)
+	if err != nil {
+		sr.fail(err)
+	}
+	return v
+}
+
+func (sr *snapshotReader) varint() int64 {
+	if sr.err != nil {
+		return 0
+	}
+
+	v, err := binary.ReadVarint(sr.r)
+	if err != nil {
+		sr.fail(err)
+	}
+	return v
+}
+
+func (sr *snapshotReader) float64() float64 {
+	if sr.err != nil {
+		return 0
+	}
+
+	var buf [8]byte
+	if _, err := io.ReadFull(sr.r, buf[:]); err != nil {
+		sr.fail(err)
+		return 0
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(buf[:]))
+}
+
+func (sr *snapshotReader) string() string {
+	n := sr.uvarint()
+	if sr.err != nil {
+		return ""
+	}
+
+	if n > maxSnapshotStringLen {
+		sr.fail(ErrCorruptSnapshot)
+		return ""
+	}
+
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(sr.r, buf); err != nil {
+		sr.fail(err)
+		return ""
+	}
+	return string(buf)
+}
+
+// strings reads a counted list of strings. The count is not trusted for preallocation
+// since every element is length-checked on its own.
+func (sr *snapshotReader) strings() []string {
+	n := sr.uvarint()
+	result := make([]string, 0, min(n, 1024))
+	for i := uint64(0); i < n && sr.err == nil; i++ {
+		result = append(result, sr.string())
+	}
+	return result
+}
+
+func (sr *snapshotReader) blob(u interface{ UnmarshalBinary([]byte) error }) {
+	data := sr.string()
+	if sr.err != nil {
+		return
+	}
+
+	if err := u.UnmarshalBinary([]byte(data)); err != nil {
+		sr.fail(ErrCorruptSnapshot)
+	}
+}
+
+func (sr *snapshotReader) object(valueType byte) *RObj {
+	switch valueType {
+	case rdbTypeStringRaw:
+		return &RObj{objType: ObjString, encoding: EncRaw, value: sr.string()}
+
+	case rdbTypeStringInt:
+		return &RObj{objType: ObjString, encoding: EncInt, value: sr.varint()}
+
+	case rdbTypeList:
+		list := newQuickList(sr.config)
+		list.RPush(sr.strings())
+		return &RObj{objType: ObjList, encoding: EncQuickList, value: list}
+
+	case rdbTypeSetIntSet, rdbTypeSet:
+		return newSetObject(sr.config, sr.strings())
+
+	case rdbTypeHash, rdbTypeHashWithFieldExpires:
+		fieldValues := sr.strings()
+		if len(fieldValues)%2 != 0 {
+			sr.fail(ErrCorruptSnapshot)
+			return nil
+		}
+		rObj := newHashObjectOf(sr.config, fieldValues)
+		if valueType == rdbTypeHash {
+			return rObj
+		}
+
+		hash := rObj.value.(types.Hash)
+		n := sr.uvarint()
+		for i := uint64(0); i < n && sr.err == nil; i++ {
+			field := sr.string()
+			expireAt := sr.varint()
+			if !hash.Exists(field) {
+				sr.fail(ErrCorruptSnapshot)
+				return nil
+			}
+			hash.SetFieldExpire(field, expireAt)
+		}
+		return rObj
+
+	case rdbTypeZSet:
+		n := sr.uvarint()
+		scoreMember := make(map[string]float64, min(n, 1024))
+		for i := uint64(0); i < n && sr.err == nil; i++ {
+			member := sr.string()
+			scoreMember[member] = sr.float64()
+		}
+		return newZSetObjectOf(sr.config, scoreMember)
+
+	case rdbTypeBloomFilter:
+		sbf := types.NewScalableBloomFilter(0.01, 1, 1)
+		sr.blob(sbf)
+		return &RObj{objType: ObjBloomFilter, encoding: EncBloomFilter, value: sbf}
+
+	case rdbTypeCuckooFilter:
+		cf := types.NewCuckooFilter(1, 1, 1, 1)
+		sr.blob(cf)
+		return &RObj{objType: ObjCuckooFilter, encoding: EncCuckooFilter, value: cf}
+
+	case rdbTypeHyperLogLog:
+		hll := types.NewHyperLogLog()
+		sr.blob(hll)
+		return &RObj{objType: ObjHyperLogLog, encoding: EncHyperLogLog, value: hll}
+
+	case rdbTypeCountMinSketch:
+		cms := types.NewCountMinSketchByDim(1, 1)
+		sr.blob(cms)
+		return &RObj{objType: ObjCountMinSketch, encoding: EncCountMinSketch, value: cms}
+
+	case rdbTypeStream:
+		stream := types.NewStream()
+		sr.blob(stream)
+		return &RObj{objType: ObjStream, encoding: EncStream, value: stream}
+
+	default:
+		sr.fail(ErrCorruptSnapshot)
+		return nil
+	}
+}
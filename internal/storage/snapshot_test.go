@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/storage/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStoreSnapshot() *store {
+	return NewStore(config.NewConfig()).(*store)
+}
+
+func snapshotRoundTrip(t *testing.T, src *store) *store {
+	var buf bytes.Buffer
+	require.NoError(t, src.WriteSnapshot(&buf))
+
+	dst := newTestStoreSnapshot()
+	require.NoError(t, dst.ReadSnapshot(&buf))
+	return dst
+}
+
+func TestSnapshot_RoundTripAllTypes(t *testing.T) {
+	s := newTestStoreSnapshot()
+	s.Set("str", "hello")
+	s.Set("int", "-42")
+	s.Append("appended", "hello")
+	s.Append("appended", " world")
+	s.RPush("list", "a", "b", "c")
+	s.SAdd("intset", "3", "1", "2")
+	s.SAdd("set", "x", "y")
+	s.HSet("hash", map[string]string{"f1": "v1", "f2": "v2"})
+	s.ZAdd("zset", map[string]float64{"one": 1, "half": 0.5, "inf": math.Inf(1)}, types.ZAddOptions{})
+	s.BFAdd("bf", "item")
+	s.CFAdd("cf", "item")
+	s.PFAdd("hll", []string{"a", "b", "c"})
+	s.CMSInitByDim("cms", 10, 2)
+	s.CMSIncrBy("cms", map[string]uint64{"item": 5})
+	s.XAdd("stream", []string{"f", "v"}, types.StreamAddOptions{ID: types.StreamID{Ms: 1, Seq: 1}})
+	s.XAdd("stream", []string{"f", "w"}, types.StreamAddOptions{ID: types.StreamID{Ms: 2, Seq: 1}})
+	s.XDel("stream", []types.StreamID{{Ms: 2, Seq: 1}})
+
+	loaded := snapshotRoundTrip(t, s)
+	assert.Equal(t, s.data.Len(), loaded.data.Len())
+
+	str, _ := loaded.Get("str")
+	assert.Equal(t, "hello", *str)
+	str, _ = loaded.Get("appended")
+	assert.Equal(t, "hello world", *str)
+
+	obj, _ := loaded.data.Get("int")
+	assert.Equal(t, EncInt, obj.encoding)
+	assert.Equal(t, int64(-42), obj.value.(int64))
+
+	list, _ := loaded.LRange("list", 0, -1)
+	assert.Equal(t, []string{"a", "b", "c"}, list)
+
+	obj, _ = loaded.data.Get("intset")
+	assert.Equal(t, EncIntSet, obj.encoding)
+	members, _ := loaded.SMembers("intset")
+	assert.ElementsMatch(t, []string{"1", "2", "3"}, members)
+
+	obj, _ = loaded.data.Get("set")
+	assert.Equal(t, EncListPack, obj.encoding)
+	members, _ = loaded.SMembers("set")
+	assert.ElementsMatch(t, []string{"x", "y"}, members)
+
+	fields, _ := loaded.HGetAll("hash")
+	assert.ElementsMatch(t, []string{"f1", "v1", "f2", "v2"}, fields)
+
+	zrange, _ := loaded.ZRangeByRank("zset", 0, -1, true)
+	assert.Equal(t, []string{"half", "0.5", "one", "1", "inf", "+Inf"}, zrange)
+
+	exists, _ := loaded.BFExists("bf", "item")
+	assert.Equal(t, 1, exists)
+
+	exists, _ = loaded.CFExists("cf", "item")
+	assert.Equal(t, 1, exists)
+
+	count, _ := loaded.PFCount([]string{"hll"})
+	assert.Equal(t, 3, count)
+
+	counts, _ := loaded.CMSQuery("cms", []string{"item"})
+	assert.Equal(t, []uint64{5}, counts)
+
+	entries, _ := loaded.XRange("stream", types.MinStreamID, types.MaxStreamID, 0, false)
+	assert.Equal(t, []types.StreamEntry{{ID: types.StreamID{Ms: 1, Seq: 1}, Fields: []string{"f", "v"}}}, entries)
+	_, err := loaded.XAdd("stream", []string{"f", "x"}, types.StreamAddOptions{ID: types.StreamID{Ms: 2, Seq: 1}})
+	assert.ErrorIs(t, err, types.ErrStreamIDTooSmall)
+}
+
+func TestSnapshot_LargeIntSetFallsBackToHashTable(t *testing.T) {
+	s := newTestStoreSnapshot()
+	members := make([]string, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		members = append(members, strconv.Itoa(i))
+	}
+	s.SAdd("set", members...)
+
+	loaded := snapshotRoundTrip(t, s)
+	card, _ := loaded.SCard("set")
+	assert.Equal(t, int64(1000), card)
+}
+
+func TestSnapshot_PreservesExpiration(t *testing.T) {
+	s := newTestStoreSnapshot()
+	s.SetEx("volatile", "v", 100)
+	s.Set("persistent", "v")
+
+	loaded := snapshotRoundTrip(t, s)
+
+	expireAt, ok := loaded.expires.Get("volatile")
+	require.True(t, ok)
+	original, _ := s.expires.Get("volatile")
+	assert.Equal(t, original, expireAt)
+
+	_, ok = loaded.expires.Get("persistent")
+	assert.False(t, ok)
+}
+
+func TestSnapshot_SkipsExpiredKeys(t *testing.T) {
+	s := newTestStoreSnapshot()
+	s.Set("expired", "v")
+	s.expires.Set("expired", uint64(time.Now().UnixMilli())-1)
+	s.Set("alive", "v")
+
+	loaded := snapshotRoundTrip(t, s)
+	assert.Equal(t, 1, loaded.data.Len())
+	assert.True(t, loaded.Exists("alive"))
+}
+
+func TestSnapshot_EmptyStore(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, newTestStoreSnapshot().WriteSnapshot(&buf))
+	assert.Equal(t, []byte{rdbOpEOF}, buf.Bytes())
+}
+
+func TestSnapshot_TruncatedInput(t *testing.T) {
+	s := newTestStoreSnapshot()
+	s.Set("key", "value")
+	s.RPush("list", "a", "b")
+
+	var buf bytes.Buffer
+	require.NoError(t, s.WriteSnapshot(&buf))
+	data := buf.Bytes()
+
+	for i := 0; i < len(data); i++ {
+		err := newTestStoreSnapshot().ReadSnapshot(bytes.NewReader(data[:i]))
+		assert.ErrorIs(t, err, ErrCorruptSnapshot, "prefix of length %d", i)
+	}
+}
+
+func TestSnapshot_UnknownType(t *testing.T) {
+	err := newTestStoreSnapshot().ReadSnapshot(bytes.NewReader([]byte{0x7F, 1, 'k'}))
+	assert.ErrorIs(t, err, ErrCorruptSnapshot)
+}
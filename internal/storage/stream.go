@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/storage/types"
+)
+
+// XAdd appends an entry to the stream at key, creating it unless NoMkStream is set, and
+// trims the stream afterwards. It returns nil when the stream does not exist and was not created.
+func (s *store) XAdd(key string, fields []string, options types.StreamAddOptions) (*types.StreamID, error) {
+	result := s.access(key, ObjStream, true)
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	if !result.exists && options.NoMkStream {
+		return nil, nil
+	}
+
+	var stream types.Stream
+	if result.exists {
+		stream = result.object.value.(types.Stream)
+	} else {
+		stream = types.NewStream()
+	}
+
+	id, err := stream.NextID(options, uint64(time.Now().UnixMilli()))
+	if err != nil {
+		return nil, err
+	}
+
+	delta := stream.Add(id, fields)
+	if !result.exists {
+		delta = s.data.Set(key, &RObj{
+			objType:  ObjStream,
+			encoding: EncStream,
+			value:    stream,
+		})
+	}
+	s.usedMemory += delta
+
+	_, delta = stream.Trim(options.Trim)
+	s.usedMemory += delta
+	return &id, nil
+}
+
+func (s *store) XLen(key string) (uint32, error) {
+	stream, err := s.getStream(key, false)
+	if err != nil {
+		return 0, err
+	}
+
+	if stream == nil {
+		return 0, nil
+	}
+
+	return stream.Len(), nil
+}
+
+// XRange returns up to count entries between start and end inclusive, all of them when
+// count is zero or less. With rev the entries are returned from end to start.
+func (s *store) XRange(key string, start, end types.StreamID, count int, rev bool) ([]types.StreamEntry, error) {
+	stream, err := s.getStream(key, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if stream == nil {
+		return []types.StreamEntry{}, nil
+	}
+
+	return stream.Range(start, end, count, rev), nil
+}
+
+// XDel removes entries by ID. Like in Redis, a stream is not deleted once it is empty.
+func (s *store) XDel(key string, ids []types.StreamID) (int64, error) {
+	stream, err := s.getStream(key, true)
+	if err != nil {
+		return 0, err
+	}
+
+	if stream == nil {
+		return 0, nil
+	}
+
+	deleted, delta := stream.Delete(ids)
+	s.usedMemory += delta
+	return deleted, nil
+}
+
+func (s *store) XTrim(key string, options types.StreamTrimOptions) (int64, error) {
+	stream, err := s.getStream(key, true)
+	if err != nil {
+		return 0, err
+	}
+
+	if stream == nil {
+		return 0, nil
+	}
+
+	removed, delta := stream.Trim(options)
+	s.usedMemory += delta
+	return removed, nil
+}
+
+func (s *store) getStream(key string, isWrite bool) (types.Stream, error) {
+	result := s.access(key, ObjStream, isWrite)
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	if result.expired || !result.exists {
+		return nil, nil
+	}
+
+	return result.object.value.(types.Stream), nil
+}
@@ -0,0 +1,319 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/storage/types"
+)
+
+// XGroupCreate creates a consumer group whose last delivered ID is id, or the last ID of
+// the stream when id is nil. A missing stream is created only with mkStream.
+func (s *store) XGroupCreate(key, group string, id *types.StreamID, mkStream bool, entriesRead int64) error {
+	result := s.access(key, ObjStream, true)
+	if result.err != nil {
+		return result.err
+	}
+
+	if !result.exists && !mkStream {
+		return ErrKeyNotFoundError
+	}
+
+	var stream types.Stream
+	if result.exists {
+		stream = result.object.value.(types.Stream)
+	} else {
+		stream = types.NewStream()
+		s.usedMemory += s.data.Set(key, &RObj{
+			objType:  ObjStream,
+			encoding: EncStream,
+			value:    stream,
+		})
+	}
+
+	lastID := stream.LastID()
+	if id != nil {
+		lastID = *id
+	} else if entriesRead == -1 {
+		entriesRead = int64(stream.EntriesAdded())
+	}
+
+	g, delta := stream.CreateGroup(group, lastID, entriesRead)
+	if g == nil {
+		return ErrBusyGroupError
+	}
+
+	s.usedMemory += delta
+	return nil
+}
+
+// XGroupSetID sets the last delivered ID of a group, the last ID of the stream when id is nil.
+func (s *store) XGroupSetID(key, group string, id *types.StreamID, entriesRead int64) error {
+	stream, g, err := s.getStreamGroup(key, group, true)
+	if err != nil {
+		return err
+	}
+
+	g.LastID = stream.LastID()
+	if id != nil {
+		g.LastID = *id
+	} else if entriesRead == -1 {
+		entriesRead = int64(stream.EntriesAdded())
+	}
+	g.EntriesRead = entriesRead
+	return nil
+}
+
+func (s *store) XGroupDestroy(key, group string) (int64, error) {
+	stream, err := s.getStream(key, true)
+	if err != nil {
+		return 0, err
+	}
+
+	if stream == nil {
+		return 0, ErrKeyNotFoundError
+	}
+
+	destroyed, delta := stream.DestroyGroup(group)
+	if !destroyed {
+		return 0, nil
+	}
+
+	s.usedMemory += delta
+	return 1, nil
+}
+
+func (s *store) XGroupCreateConsumer(key, group, consumer string) (int64, error) {
+	_, g, err := s.getStreamGroup(key, group, true)
+	if err != nil {
+		return 0, err
+	}
+
+	_, created, delta := g.CreateConsumer(consumer, time.Now().UnixMilli())
+	if !created {
+		return 0, nil
+	}
+
+	s.usedMemory += delta
+	return 1, nil
+}
+
+// XGroupDelConsumer deletes a consumer and returns the number of entries it had pending.
+func (s *store) XGroupDelConsumer(key, group, consumer string) (int64, error) {
+	_, g, err := s.getStreamGroup(key, group, true)
+	if err != nil {
+		return 0, err
+	}
+
+	pending, _, delta := g.DeleteConsumer(consumer)
+	s.usedMemory += delta
+	return pending, nil
+}
+
+// XRead returns up to count entries with IDs greater than after, all of them when count is
+// zero or less. A missing key reads as an empty stream.
+func (s *store) XRead(key string, after types.StreamID, count int) ([]types.StreamEntry, error) {
+	stream, err := s.getStream(key, false)
+	if err != nil {
+		return nil, err
+	}
+
+	start, ok := after.Incr()
+	if stream == nil || !ok {
+		return []types.StreamEntry{}, nil
+	}
+
+	return stream.Range(start, types.MaxStreamID, count, false), nil
+}
+
+// XLastID returns the greatest ID ever added to the stream, 0-0 for a missing key.
+func (s *store) XLastID(key string) (types.StreamID, error) {
+	stream, err := s.getStream(key, false)
+	if err != nil || stream == nil {
+		return types.MinStreamID, err
+	}
+
+	return stream.LastID(), nil
+}
+
+// XReadGroup reads entries for consumer, creating it if needed. With a nil after, up to
+// count entries never delivered to the group are delivered to consumer. Otherwise its
+// pending entries with IDs greater than after are delivered again.
+//
+// The key is only touched when the group changed, so that blocked readers can retry
+// without waking up each other or the clients watching the key.
+func (s *store) XReadGroup(key, group, consumer string, after *types.StreamID, count int, noAck bool) ([]types.StreamEntry, error) {
+	stream, g, err := s.getStreamGroup(key, group, false)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	c, created, delta := g.CreateConsumer(consumer, now)
+	s.usedMemory += delta
+
+	var entries []types.StreamEntry
+	if after == nil {
+		entries, delta = stream.ReadGroup(g, c, count, noAck, now)
+		s.usedMemory += delta
+	} else {
+		entries = stream.ReadPending(c, *after, count, now)
+	}
+
+	if created || len(entries) > 0 {
+		s.touch(key)
+	}
+	return entries, nil
+}
+
+// XAck acknowledges pending entries of a group. A missing key or group acknowledges nothing.
+func (s *store) XAck(key, group string, ids []types.StreamID) (int64, error) {
+	_, g, err := s.getStreamGroup(key, group, true)
+	if err == ErrKeyNotFoundError || err == ErrNoGroupError {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	acked, delta := g.Ack(ids)
+	s.usedMemory += delta
+	return acked, nil
+}
+
+// XPending returns up to count pending entries of a group with IDs between start and end
+// inclusive, idle for at least minIdle milliseconds, and delivered to consumer unless it
+// is empty. A count of zero or less returns every such entry.
+func (s *store) XPending(key, group string, start, end types.StreamID, count int, consumer string,
+	minIdle int64) ([]*types.StreamPendingEntry, error) {
+	_, g, err := s.getStreamGroup(key, group, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var c *types.StreamConsumer
+	if consumer != "" {
+		if c = g.Consumer(consumer); c == nil {
+			return []*types.StreamPendingEntry{}, nil
+		}
+	}
+
+	return g.Pending(start, end, count, c, minIdle, time.Now().UnixMilli()), nil
+}
+
+// XClaim transfers pending entries of a group to consumer, creating it if needed.
+func (s *store) XClaim(key, group, consumer string, ids []types.StreamID, options types.StreamClaimOptions) ([]types.StreamEntry, error) {
+	stream, g, err := s.getStreamGroup(key, group, true)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	c, _, delta := g.CreateConsumer(consumer, now)
+	s.usedMemory += delta
+
+	claimed, delta := stream.Claim(g, c, ids, options, now)
+	s.usedMemory += delta
+	return claimed, nil
+}
+
+// XAutoClaim transfers to consumer up to count pending entries of a group that are idle for
+// long enough, scanning from start. It returns the ID to resume the scan from, the claimed
+// entries and the IDs of the pending entries that no longer exist in the stream.
+func (s *store) XAutoClaim(key, group, consumer string, start types.StreamID, count int,
+	options types.StreamClaimOptions) (types.StreamID, []types.StreamEntry, []types.StreamID, error) {
+	stream, g, err := s.getStreamGroup(key, group, true)
+	if err != nil {
+		return types.MinStreamID, nil, nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	c, _, delta := g.CreateConsumer(consumer, now)
+	s.usedMemory += delta
+
+	next, claimed, deleted, delta := stream.AutoClaim(g, c, start, count, options, now)
+	s.usedMemory += delta
+	return next, claimed, deleted, nil
+}
+
+func (s *store) XInfoStream(key string) (*types.StreamInfo, error) {
+	stream, err := s.getStream(key, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if stream == nil {
+		return nil, ErrKeyNotFoundError
+	}
+
+	info := &types.StreamInfo{
+		Length:       stream.Len(),
+		Nodes:        stream.NodeCount(),
+		LastID:       stream.LastID(),
+		MaxDeletedID: stream.MaxDeletedID(),
+		EntriesAdded: stream.EntriesAdded(),
+		Groups:       groupInfos(stream),
+	}
+
+	if first := stream.Range(types.MinStreamID, types.MaxStreamID, 1, false); len(first) > 0 {
+		info.FirstID = first[0].ID
+		info.FirstEntry = &first[0]
+	}
+	if last := stream.Range(types.MinStreamID, types.MaxStreamID, 1, true); len(last) > 0 {
+		info.LastEntry = &last[0]
+	}
+
+	return info, nil
+}
+
+func (s *store) XInfoGroups(key string) ([]types.StreamGroupInfo, error) {
+	stream, err := s.getStream(key, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if stream == nil {
+		return nil, ErrKeyNotFoundError
+	}
+
+	return groupInfos(stream), nil
+}
+
+// XInfoConsumers returns the consumers of a group ordered by name.
+func (s *store) XInfoConsumers(key, group string) ([]*types.StreamConsumer, error) {
+	_, g, err := s.getStreamGroup(key, group, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.Consumers(), nil
+}
+
+func groupInfos(stream types.Stream) []types.StreamGroupInfo {
+	groups := stream.Groups()
+	infos := make([]types.StreamGroupInfo, len(groups))
+	for i, g := range groups {
+		lag, known := stream.Lag(g)
+		infos[i] = types.StreamGroupInfo{StreamGroup: g, Lag: lag, LagKnown: known}
+	}
+	return infos
+}
+
+// getStreamGroup returns a stream and one of its consumer groups. It fails with
+// ErrKeyNotFoundError for a missing key and with ErrNoGroupError for a missing group.
+func (s *store) getStreamGroup(key, group string, isWrite bool) (types.Stream, *types.StreamGroup, error) {
+	stream, err := s.getStream(key, isWrite)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if stream == nil {
+		return nil, nil, ErrKeyNotFoundError
+	}
+
+	g := stream.Group(group)
+	if g == nil {
+		return nil, nil, ErrNoGroupError
+	}
+
+	return stream, g, nil
+}
@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/storage/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStoreStream() *store {
+	return NewStore(config.NewConfig()).(*store)
+}
+
+func TestXAdd_CreatesStream(t *testing.T) {
+	s := newTestStoreStream()
+
+	id, err := s.XAdd("s", []string{"f", "v"}, types.StreamAddOptions{AutoID: true})
+	require.NoError(t, err)
+	require.NotNil(t, id)
+	assert.NotZero(t, id.Ms)
+
+	length, err := s.XLen("s")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), length)
+}
+
+func TestXAdd_NoMkStream(t *testing.T) {
+	s := newTestStoreStream()
+
+	id, err := s.XAdd("s", []string{"f", "v"}, types.StreamAddOptions{AutoID: true, NoMkStream: true})
+	assert.NoError(t, err)
+	assert.Nil(t, id)
+	assert.False(t, s.Exists("s"))
+}
+
+func TestXAdd_Trims(t *testing.T) {
+	s := newTestStoreStream()
+
+	for i := 0; i < 5; i++ {
+		_, err := s.XAdd("s", []string{"f", "v"}, types.StreamAddOptions{
+			AutoID: true,
+			Trim:   types.StreamTrimOptions{Strategy: types.StreamTrimMaxLen, MaxLen: 3},
+		})
+		require.NoError(t, err)
+	}
+
+	length, _ := s.XLen("s")
+	assert.Equal(t, uint32(3), length)
+}
+
+func TestXAdd_WrongType(t *testing.T) {
+	s := newTestStoreStream()
+	s.Set("str", "v")
+
+	_, err := s.XAdd("str", []string{"f", "v"}, types.StreamAddOptions{AutoID: true})
+	assert.ErrorIs(t, err, ErrWrongTypeError)
+
+	_, err = s.XLen("str")
+	assert.ErrorIs(t, err, ErrWrongTypeError)
+
+	_, err = s.XRange("str", types.MinStreamID, types.MaxStreamID, 0, false)
+	assert.ErrorIs(t, err, ErrWrongTypeError)
+}
+
+func TestXDel_KeepsEmptyStream(t *testing.T) {
+	s := newTestStoreStream()
+	id, _ := s.XAdd("s", []string{"f", "v"}, types.StreamAddOptions{AutoID: true})
+
+	deleted, err := s.XDel("s", []types.StreamID{*id})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.True(t, s.Exists("s"))
+
+	deleted, err = s.XDel("missing", []types.StreamID{*id})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), deleted)
+}
+
+func TestXTrim(t *testing.T) {
+	s := newTestStoreStream()
+	for i := uint64(1); i <= 5; i++ {
+		s.XAdd("s", []string{"f", "v"}, types.StreamAddOptions{ID: types.StreamID{Ms: i}})
+	}
+
+	removed, err := s.XTrim("s", types.StreamTrimOptions{Strategy: types.StreamTrimMinID, MinID: types.StreamID{Ms: 3}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), removed)
+
+	entries, _ := s.XRange("s", types.MinStreamID, types.MaxStreamID, 0, true)
+	require.Len(t, entries, 3)
+	assert.Equal(t, types.StreamID{Ms: 5}, entries[0].ID)
+}
+
+func TestStream_MemoryAccounting(t *testing.T) {
+	s := newTestStoreStream()
+	base := s.usedMemory
+
+	s.XAdd("s", []string{"field", "value"}, types.StreamAddOptions{ID: types.StreamID{Ms: 1}})
+	afterFirst := s.usedMemory
+	assert.Greater(t, afterFirst, base)
+
+	s.XAdd("s", []string{"field", "value"}, types.StreamAddOptions{ID: types.StreamID{Ms: 2}})
+	assert.Greater(t, s.usedMemory, afterFirst)
+
+	s.XDel("s", []types.StreamID{{Ms: 2}})
+	assert.Equal(t, afterFirst, s.usedMemory)
+}
+
+func TestXGroupCreate(t *testing.T) {
+	s := newTestStoreStream()
+
+	assert.Equal(t, ErrKeyNotFoundError, s.XGroupCreate("s", "g", nil, false, -1))
+	require.NoError(t, s.XGroupCreate("s", "g", nil, true, -1))
+	assert.Equal(t, ErrBusyGroupError, s.XGroupCreate("s", "g", nil, false, -1))
+
+	s.Set("str", "v")
+	assert.Equal(t, ErrWrongTypeError, s.XGroupCreate("str", "g", nil, true, -1))
+
+	assert.Equal(t, ErrNoGroupError, s.XGroupSetID("s", "missing", nil, -1))
+	_, err := s.XGroupCreateConsumer("missing", "g", "c")
+	assert.Equal(t, ErrKeyNotFoundError, err)
+}
+
+func TestXReadGroup(t *testing.T) {
+	s := newTestStoreStream()
+	s.XAdd("s", []string{"f", "v"}, types.StreamAddOptions{ID: types.StreamID{Ms: 1}})
+	s.XAdd("s", []string{"f", "v"}, types.StreamAddOptions{ID: types.StreamID{Ms: 2}})
+	require.NoError(t, s.XGroupCreate("s", "g", &types.MinStreamID, false, 0))
+
+	entries, err := s.XReadGroup("s", "g", "c", nil, 1, false)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, types.StreamID{Ms: 1}, entries[0].ID)
+
+	entries, _ = s.XReadGroup("s", "g", "c", &types.MinStreamID, 0, false)
+	require.Len(t, entries, 1)
+
+	pending, _ := s.XPending("s", "g", types.MinStreamID, types.MaxStreamID, 0, "", 0)
+	require.Len(t, pending, 1)
+	assert.Equal(t, uint64(2), pending[0].DeliveryCount)
+
+	_, err = s.XReadGroup("s", "missing", "c", nil, 0, false)
+	assert.Equal(t, ErrNoGroupError, err)
+
+	acked, err := s.XAck("missing", "g", []types.StreamID{{Ms: 1}})
+	assert.NoError(t, err)
+	assert.Zero(t, acked)
+	acked, _ = s.XAck("s", "g", []types.StreamID{{Ms: 1}})
+	assert.Equal(t, int64(1), acked)
+}
+
+func TestXReadGroup_TouchesKeyOnlyWhenGroupChanges(t *testing.T) {
+	s := newTestStoreStream()
+	require.NoError(t, s.XGroupCreate("s", "g", nil, true, -1))
+	s.XReadGroup("s", "g", "c", nil, 0, false)
+
+	version := s.Watch("s")
+	entries, _ := s.XReadGroup("s", "g", "c", nil, 0, false)
+	assert.Empty(t, entries)
+	assert.Equal(t, version, s.WatchedVersion("s"))
+
+	s.XAdd("s", []string{"f", "v"}, types.StreamAddOptions{AutoID: true})
+	version = s.WatchedVersion("s")
+	entries, _ = s.XReadGroup("s", "g", "c", nil, 0, false)
+	assert.Len(t, entries, 1)
+	assert.Greater(t, s.WatchedVersion("s"), version)
+}
+
+func TestXInfoStream(t *testing.T) {
+	s := newTestStoreStream()
+
+	_, err := s.XInfoStream("s")
+	assert.Equal(t, ErrKeyNotFoundError, err)
+
+	s.XAdd("s", []string{"a", "1"}, types.StreamAddOptions{ID: types.StreamID{Ms: 1}})
+	s.XAdd("s", []string{"b", "2"}, types.StreamAddOptions{ID: types.StreamID{Ms: 2}})
+	s.XDel("s", []types.StreamID{{Ms: 1}})
+	require.NoError(t, s.XGroupCreate("s", "g", nil, false, -1))
+
+	info, err := s.XInfoStream("s")
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), info.Length)
+	assert.Equal(t, uint64(2), info.EntriesAdded)
+	assert.Equal(t, types.StreamID{Ms: 1}, info.MaxDeletedID)
+	assert.Equal(t, types.StreamID{Ms: 2}, info.FirstID)
+	assert.Equal(t, []string{"b", "2"}, info.LastEntry.Fields)
+	require.Len(t, info.Groups, 1)
+	assert.True(t, info.Groups[0].LagKnown)
+	assert.Zero(t, info.Groups[0].Lag)
+}
+
+func TestStreamGroup_MemoryAccounting(t *testing.T) {
+	s := newTestStoreStream()
+	s.XAdd("s", []string{"field", "value"}, types.StreamAddOptions{ID: types.StreamID{Ms: 1}})
+	base := s.usedMemory
+
+	require.NoError(t, s.XGroupCreate("s", "g", &types.MinStreamID, false, 0))
+	s.XReadGroup("s", "g", "c", nil, 0, false)
+	assert.Greater(t, s.usedMemory, base)
+
+	s.XGroupDestroy("s", "g")
+	assert.Equal(t, base, s.usedMemory)
+}
@@ -2,8 +2,11 @@ package storage
 
 import (
 	"math"
+	"math/big"
 	"strconv"
 	"time"
+
+	"github.com/manhhung2111/go-redis/internal/storage/types"
 )
 
 func newStringObject(s string) *RObj {
@@ -26,6 +29,7 @@ func (s *store) Set(key string, value string) {
 	s.delete(key)
 	delta := s.data.Set(key, newStringObject(value))
 	s.usedMemory += delta
+	s.touch(key)
 }
 
 func (s *store) SetEx(key string, value string, ttlSeconds uint64) {
@@ -35,6 +39,27 @@ func (s *store) SetEx(key string, value string, ttlSeconds uint64) {
 	delta1 := s.data.Set(key, newStringObject(value))
 	delta2 := s.expires.Set(key, expireAt)
 	s.usedMemory += delta1 + delta2
+	s.touch(key)
+}
+
+// SetExAt sets key to value with an absolute expiration time in unix milliseconds.
+// A time in the past deletes the key right away.
+func (s *store) SetExAt(key string, value string, expireAtMs int64) {
+	if expireAtMs <= time.Now().UnixMilli() {
+		s.delete(key)
+		return
+	}
+
+	s.setObject(key, newStringObject(value), uint64(expireAtMs), true)
+}
+
+// SetKeepTTL sets key to value, keeping the expiration time of the value it replaces.
+func (s *store) SetKeepTTL(key string, value string) {
+	// Delete an expired key first, its expiration time must not be kept
+	s.access(key, ObjAny, false)
+
+	expireAt, hasExpire := s.expires.Get(key)
+	s.setObject(key, newStringObject(value), expireAt, hasExpire)
 }
 
 func (s *store) Get(key string) (*string, error) {
@@ -47,19 +72,18 @@ func (s *store) Get(key string) (*string, error) {
 		return nil, nil
 	}
 
-	// Encoding must be raw or int
-	rObj := result.object
-	if rObj.encoding == EncInt {
-		val := strconv.FormatInt(rObj.value.(int64), 10)
-		return &val, nil
-	}
-
-	val := rObj.value.(string)
+	val := stringValue(result.object)
 	return &val, nil
 }
 
-func (s *store) Del(key string) bool {
-	return s.delete(key)
+// GetDel returns the value of key and deletes it.
+func (s *store) GetDel(key string) (*string, error) {
+	value, err := s.Get(key)
+	if value != nil {
+		s.delete(key)
+	}
+
+	return value, err
 }
 
 func (s *store) IncrBy(key string, increment int64) (*int64, error) {
@@ -73,18 +97,471 @@ func (s *store) IncrBy(key string, increment int64) (*int64, error) {
 		return &increment, nil
 	}
 
-	// Encoding must be raw or int
 	rObj := result.object
-	if rObj.encoding != EncInt {
+	val, ok := intValue(rObj)
+	if !ok {
 		return nil, ErrValueIsNotIntegerOrOutOfRangeError
 	}
 
-	val := rObj.value.(int64)
 	if (increment > 0 && val > math.MaxInt64-increment) || (increment < 0 && val < math.MinInt64-increment) {
 		return nil, ErrValueIsNotIntegerOrOutOfRangeError
 	}
 
 	val += increment
-	rObj.value = val
+	s.usedMemory -= stringValueSize(rObj)
+	rObj.encoding, rObj.value = EncInt, val
+	s.usedMemory += stringValueSize(rObj)
 	return &val, nil
 }
+
+// IncrByFloat adds increment to the value of key, computing with the precision of a long
+// double, and returns the new value as it is stored. The expiration time is kept.
+func (s *store) IncrByFloat(key string, increment *big.Float) (string, error) {
+	result := s.access(key, ObjString, true)
+	if result.err != nil {
+		return "", result.err
+	}
+
+	value := new(big.Float).SetPrec(increment.Prec())
+	if result.exists {
+		current, ok := types.ParseLongDouble(stringValue(result.object))
+		if !ok {
+			return "", ErrValueIsNotValidFloatError
+		}
+		value.Set(current)
+	}
+
+	if value.IsInf() || increment.IsInf() {
+		return "", ErrIncrementProducesNaNOrInfinityError
+	}
+
+	// A sum beyond LDBL_MAX would be infinite as a long double
+	if !types.InLongDoubleRange(value.Add(value, increment)) {
+		return "", ErrIncrementProducesNaNOrInfinityError
+	}
+
+	formatted := types.FormatLongDouble(value)
+	if result.exists {
+		s.setStringValue(result.object, formatted)
+	} else {
+		s.Set(key, formatted)
+	}
+
+	return formatted, nil
+}
+
+// Append appends value to the string at key, creating it when missing, and returns the
+// length of the string after the append.
+func (s *store) Append(key string, value string) (int64, error) {
+	result := s.access(key, ObjString, true)
+	if result.err != nil {
+		return 0, result.err
+	}
+
+	if !result.exists {
+		if err := s.checkStringSize(int64(len(value))); err != nil {
+			return 0, err
+		}
+		s.Set(key, value)
+		return int64(len(value)), nil
+	}
+
+	length := stringLen(result.object)
+	if err := s.checkStringSize(int64(length + len(value))); err != nil {
+		return 0, err
+	}
+
+	buf := s.mutableString(result.object, length+len(value))
+	copy(buf[length:], value)
+	return int64(len(buf)), nil
+}
+
+// StrLen returns the length of the string at key, 0 when missing.
+func (s *store) StrLen(key string) (int64, error) {
+	result := s.access(key, ObjString, false)
+	if result.err != nil {
+		return 0, result.err
+	}
+
+	if !result.exists {
+		return 0, nil
+	}
+
+	return int64(stringLen(result.object)), nil
+}
+
+// GetRange returns the substring of the string at key between start and end, both
+// inclusive. Negative offsets count from the end of the string.
+func (s *store) GetRange(key string, start, end int64) (string, error) {
+	result := s.access(key, ObjString, false)
+	if result.err != nil {
+		return "", result.err
+	}
+
+	if !result.exists || (start < 0 && end < 0 && start > end) {
+		return "", nil
+	}
+
+	length := int64(stringLen(result.object))
+	if start < 0 {
+		start = max(length+start, 0)
+	}
+	if end < 0 {
+		end = max(length+end, 0)
+	}
+	end = min(end, length-1)
+
+	if start > end || length == 0 {
+		return "", nil
+	}
+
+	if buf, ok := result.object.value.([]byte); ok {
+		return string(buf[start : end+1]), nil
+	}
+	return stringValue(result.object)[start : end+1], nil
+}
+
+// SetRange overwrites the string at key with value starting at offset, padding it with
+// zero bytes when it is shorter than offset, and returns the length of the string after the
+// write. A missing key is only created when value is not empty.
+func (s *store) SetRange(key string, offset int64, value string) (int64, error) {
+	result := s.access(key, ObjString, true)
+	if result.err != nil {
+		return 0, result.err
+	}
+
+	if len(value) == 0 {
+		if result.exists {
+			return int64(stringLen(result.object)), nil
+		}
+		return 0, nil
+	}
+
+	// Compared before adding so that a huge offset cannot overflow the size
+	if offset > s.config.ProtoMaxBulkLen-int64(len(value)) {
+		return 0, ErrStringExceedsMaximumSizeError
+	}
+
+	buf := s.mutableString(s.stringForWrite(key, result), int(offset)+len(value))
+	copy(buf[offset:], value)
+	return int64(len(buf)), nil
+}
+
+// LCS returns the longest common subsequence of the strings at key1 and key2, along with
+// its runs that are contiguous in both strings, from the last to the first. Missing keys are
+// empty strings.
+func (s *store) LCS(key1, key2 string) (string, []types.LCSMatch, error) {
+	values := [2]string{}
+	for i, key := range []string{key1, key2} {
+		result := s.access(key, ObjAny, false)
+		if !result.exists {
+			continue
+		}
+		if result.object.objType != ObjString {
+			return "", nil, ErrLCSKeysNotStringsError
+		}
+		values[i] = stringValue(result.object)
+	}
+
+	if types.LCSTableSize(len(values[0]), len(values[1])) > uint64(s.config.ProtoMaxBulkLen) {
+		return "", nil, ErrLCSInsufficientMemoryError
+	}
+
+	lcs, matches := types.LCS(values[0], values[1])
+	return lcs, matches, nil
+}
+
+// BitRange selects part of a string for BITCOUNT and BITPOS, in bytes or in bits. Negative
+// offsets count from the end of the string, and both ends are inclusive.
+type BitRange struct {
+	Start, End int64
+	Bits       bool
+}
+
+// SetBit sets the bit at offset of the string at key, growing it with zero bytes as needed,
+// and returns the previous value of the bit.
+func (s *store) SetBit(key string, offset uint64, bit int) (int, error) {
+	if err := s.checkBitOffset(offset >> 3); err != nil {
+		return 0, err
+	}
+
+	result := s.access(key, ObjString, true)
+	if result.err != nil {
+		return 0, result.err
+	}
+
+	buf := s.mutableString(s.stringForWrite(key, result), int(offset>>3)+1)
+	return types.SetBit(buf, offset, bit), nil
+}
+
+// GetBit returns the bit at offset of the string at key, 0 past its end or when missing.
+func (s *store) GetBit(key string, offset uint64) (int, error) {
+	result := s.access(key, ObjString, false)
+	if result.err != nil {
+		return 0, result.err
+	}
+
+	if !result.exists {
+		return 0, nil
+	}
+
+	return types.GetBit(stringBytes(result.object), offset), nil
+}
+
+// BitCount returns the number of set bits of the string at key within rng.
+func (s *store) BitCount(key string, rng BitRange) (int64, error) {
+	result := s.access(key, ObjString, false)
+	if result.err != nil {
+		return 0, result.err
+	}
+
+	if !result.exists {
+		return 0, nil
+	}
+
+	value := stringBytes(result.object)
+	startBit, endBit, ok := rng.resolve(int64(len(value)))
+	if !ok {
+		return 0, nil
+	}
+
+	return types.BitCount(value, startBit, endBit), nil
+}
+
+// BitPos returns the position of the first bit set to bit of the string at key within
+// rng, or -1 if there is none. Without endGiven, the string is considered padded with zero
+// bits on the right, so clear bits are always found.
+func (s *store) BitPos(key string, bit int, rng BitRange, endGiven bool) (int64, error) {
+	result := s.access(key, ObjString, false)
+	if result.err != nil {
+		return 0, result.err
+	}
+
+	if !result.exists {
+		if bit == 1 {
+			return -1, nil
+		}
+		return 0, nil
+	}
+
+	value := stringBytes(result.object)
+	startBit, endBit, ok := rng.resolve(int64(len(value)))
+	if !ok {
+		return -1, nil
+	}
+
+	pos := types.BitPos(value, bit, startBit, endBit)
+	if pos == -1 && bit == 0 && !endGiven {
+		return endBit + 1, nil
+	}
+
+	return pos, nil
+}
+
+// BitOp stores the result of op over the strings at keys at dst, and returns its length.
+// Missing keys are empty strings, and an empty result deletes dst.
+func (s *store) BitOp(op types.BitOp, dst string, keys ...string) (int64, error) {
+	srcs := make([][]byte, len(keys))
+	for i, key := range keys {
+		result := s.access(key, ObjString, false)
+		if result.err != nil {
+			return 0, result.err
+		}
+		if result.exists {
+			srcs[i] = stringBytes(result.object)
+		}
+	}
+
+	if result := s.access(dst, ObjAny, true); result.err != nil {
+		return 0, result.err
+	}
+
+	value := types.ApplyBitOp(op, srcs)
+	if len(value) == 0 {
+		s.delete(dst)
+	} else {
+		s.Set(dst, string(value))
+	}
+
+	return int64(len(value)), nil
+}
+
+// BitField runs ops in order on the string at key, which is created or grown with zero
+// bytes when any of them writes. The result of an op is nil when the FAIL overflow mode
+// prevented its write.
+func (s *store) BitField(key string, ops []types.BitFieldOp) ([]*int64, error) {
+	var lastByte uint64
+	write := false
+	for _, op := range ops {
+		if err := s.checkBitOffset(op.LastByte()); err != nil {
+			return nil, err
+		}
+		if op.Type != types.BitFieldGet {
+			write = true
+			lastByte = max(lastByte, op.LastByte())
+		}
+	}
+
+	result := s.access(key, ObjString, write)
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	var buf []byte
+	if write {
+		buf = s.mutableString(s.stringForWrite(key, result), int(lastByte)+1)
+	} else if result.exists {
+		buf = stringBytes(result.object)
+	}
+
+	results := make([]*int64, len(ops))
+	for i, op := range ops {
+		if value, ok := types.ApplyBitField(buf, op); ok {
+			results[i] = &value
+		}
+	}
+
+	return results, nil
+}
+
+// resolve converts rng to the first and last bit it selects in a string of length bytes,
+// and reports whether it selects any.
+func (rng BitRange) resolve(length int64) (int64, int64, bool) {
+	start, end := rng.Start, rng.End
+	if start < 0 && end < 0 && start > end {
+		return 0, 0, false
+	}
+
+	size := length
+	if rng.Bits {
+		size *= 8
+	}
+
+	if start < 0 {
+		start = max(size+start, 0)
+	}
+	if end < 0 {
+		end = max(size+end, 0)
+	}
+	end = min(end, size-1)
+
+	if start > end {
+		return 0, 0, false
+	}
+
+	if rng.Bits {
+		return start, end, true
+	}
+	return start * 8, end*8 + 7, true
+}
+
+// checkBitOffset fails when a bit in the byte at index would exceed the maximum string size.
+func (s *store) checkBitOffset(index uint64) error {
+	if index >= uint64(s.config.ProtoMaxBulkLen) {
+		return ErrBitOffsetOutOfRangeError
+	}
+	return nil
+}
+
+// checkStringSize fails when a string of size bytes would exceed the maximum string size.
+func (s *store) checkStringSize(size int64) error {
+	if size > s.config.ProtoMaxBulkLen {
+		return ErrStringExceedsMaximumSizeError
+	}
+	return nil
+}
+
+// setStringValue replaces the value of the string object rObj in place, keeping its
+// expiration time, and accounts for the memory change. The encoding follows the new value.
+func (s *store) setStringValue(rObj *RObj, value string) {
+	s.usedMemory -= stringValueSize(rObj)
+	updated := newStringObject(value)
+	rObj.encoding, rObj.value = updated.encoding, updated.value
+	s.usedMemory += stringValueSize(rObj)
+}
+
+// stringForWrite returns the string object of result, stored at key, creating an empty one
+// when key does not exist.
+func (s *store) stringForWrite(key string, result storageAccessResult) *RObj {
+	if result.exists {
+		return result.object
+	}
+
+	rObj := &RObj{objType: ObjString, encoding: EncRawBytes, value: []byte{}}
+	s.usedMemory += s.data.Set(key, rObj)
+	return rObj
+}
+
+// mutableString converts the string object rObj to raw bytes, grown with zero bytes to at
+// least size, and returns them to be changed in place. Like append, growing reserves spare
+// capacity, so that repeated writes past the end take amortized constant time.
+func (s *store) mutableString(rObj *RObj, size int) []byte {
+	s.usedMemory -= stringValueSize(rObj)
+
+	var buf []byte
+	if rObj.encoding == EncRawBytes {
+		buf = rObj.value.([]byte)
+	} else {
+		buf = []byte(stringValue(rObj))
+	}
+	if len(buf) < size {
+		buf = append(buf, make([]byte, size-len(buf))...)
+	}
+
+	rObj.encoding, rObj.value = EncRawBytes, buf
+	s.usedMemory += stringValueSize(rObj)
+	return buf
+}
+
+// stringValue returns the value of a string object, whichever its encoding.
+func stringValue(rObj *RObj) string {
+	switch rObj.encoding {
+	case EncInt:
+		return strconv.FormatInt(rObj.value.(int64), 10)
+	case EncRawBytes:
+		return string(rObj.value.([]byte))
+	}
+	return rObj.value.(string)
+}
+
+// stringBytes returns the value of a string object as bytes, which must not be modified.
+// Raw bytes are returned without copying them.
+func stringBytes(rObj *RObj) []byte {
+	if rObj.encoding == EncRawBytes {
+		return rObj.value.([]byte)
+	}
+	return []byte(stringValue(rObj))
+}
+
+// stringLen returns the length of the value of a string object.
+func stringLen(rObj *RObj) int {
+	switch rObj.encoding {
+	case EncInt:
+		return len(strconv.FormatInt(rObj.value.(int64), 10))
+	case EncRawBytes:
+		return len(rObj.value.([]byte))
+	}
+	return len(rObj.value.(string))
+}
+
+// intValue returns the value of a string object as an integer, and whether it is one.
+func intValue(rObj *RObj) (int64, bool) {
+	switch rObj.encoding {
+	case EncInt:
+		return rObj.value.(int64), true
+	case EncRawBytes:
+		val, err := strconv.ParseInt(string(rObj.value.([]byte)), 10, 64)
+		return val, err == nil
+	}
+	return 0, false
+}
+
+func stringValueSize(rObj *RObj) int64 {
+	switch rObj.encoding {
+	case EncInt:
+		return types.Int64Size
+	case EncRawBytes:
+		return types.SliceHeaderSize + int64(cap(rObj.value.([]byte)))
+	}
+	return types.StringSize(rObj.value.(string))
+}
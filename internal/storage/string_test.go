@@ -2,10 +2,13 @@ package storage
 
 import (
 	"math"
+	"math/big"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/storage/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -182,6 +185,73 @@ func TestSetExOverwriteExpiration(t *testing.T) {
 	assert.Greater(t, exp2, exp1)
 }
 
+func TestSetExAt(t *testing.T) {
+	s := newTestStore()
+	expireAt := time.Now().Add(time.Minute).UnixMilli()
+
+	s.SetExAt("key1", "value1", expireAt)
+
+	val, err := s.Get("key1")
+	require.Nil(t, err)
+	assert.Equal(t, "value1", *val)
+	assert.Equal(t, expireAt, s.PExpireTime("key1"))
+}
+
+func TestSetExAtPastTimeDeletesKey(t *testing.T) {
+	s := newTestStore()
+	s.Set("key1", "old")
+
+	s.SetExAt("key1", "value1", time.Now().Add(-time.Second).UnixMilli())
+
+	assert.False(t, s.Exists("key1"))
+}
+
+func TestSetKeepTTL(t *testing.T) {
+	s := newTestStore().(*store)
+	s.SetEx("key1", "value1", 10)
+	expireAt, _ := s.expires.Get("key1")
+
+	s.SetKeepTTL("key1", "value2")
+
+	val, _ := s.Get("key1")
+	assert.Equal(t, "value2", *val)
+	assert.Equal(t, int64(expireAt), s.PExpireTime("key1"))
+
+	s.SetKeepTTL("key2", "value")
+	assert.Equal(t, int64(-1), s.TTL("key2"))
+}
+
+func TestSetKeepTTLExpiredKey(t *testing.T) {
+	s := newTestStore().(*store)
+	s.Set("key1", "value1")
+	s.expires.Set("key1", uint64(time.Now().UnixMilli()-1000))
+
+	s.SetKeepTTL("key1", "value2")
+
+	val, _ := s.Get("key1")
+	assert.Equal(t, "value2", *val)
+	assert.Equal(t, int64(-1), s.TTL("key1"))
+}
+
+func TestGetDel(t *testing.T) {
+	s := newTestStore()
+	s.Set("key1", "value1")
+
+	val, err := s.GetDel("key1")
+	require.Nil(t, err)
+	assert.Equal(t, "value1", *val)
+	assert.False(t, s.Exists("key1"))
+
+	val, err = s.GetDel("key1")
+	assert.Nil(t, err)
+	assert.Nil(t, val)
+
+	s.RPush("list", "a")
+	_, err = s.GetDel("list")
+	assert.Equal(t, ErrWrongTypeError, err)
+	assert.True(t, s.Exists("list"))
+}
+
 func TestGetExisting(t *testing.T) {
 	s := newTestStore()
 	s.Set("key1", "value1")
@@ -227,13 +297,13 @@ func TestDelExisting(t *testing.T) {
 	s := newTestStore()
 	s.Set("key1", "value1")
 
-	assert.True(t, s.Del("key1"))
+	assert.Equal(t, int64(1), s.Del("key1"))
 }
 
 func TestDelNonExisting(t *testing.T) {
 	s := newTestStore()
 
-	assert.False(t, s.Del("nonexistent"))
+	assert.Zero(t, s.Del("nonexistent"))
 }
 
 func TestIncrByIntEncoding(t *testing.T) {
@@ -262,7 +332,7 @@ func TestSetGetDelFlow(t *testing.T) {
 	require.Nil(t, err)
 	assert.Equal(t, "value1", *val)
 
-	assert.True(t, s.Del("key1"))
+	assert.Equal(t, int64(1), s.Del("key1"))
 
 	val, err = s.Get("key1")
 	assert.Nil(t, err)
@@ -279,3 +349,410 @@ func TestEncodingConversionThroughIncrBy(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "15", *val)
 }
+
+func TestAppendCreatesKey(t *testing.T) {
+	s := newTestStore()
+
+	length, err := s.Append("key1", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), length)
+
+	length, _ = s.Append("key1", " world")
+	assert.Equal(t, int64(11), length)
+
+	val, _ := s.Get("key1")
+	assert.Equal(t, "hello world", *val)
+}
+
+func TestAppendConvertsIntEncoding(t *testing.T) {
+	s := newTestStore().(*store)
+	s.Set("key1", "12")
+
+	memory := s.usedMemory
+	s.Append("key1", "3")
+	obj, _ := s.data.Get("key1")
+	assert.Equal(t, EncRawBytes, obj.encoding)
+	assert.Equal(t, []byte("123"), obj.value)
+	assert.Equal(t, memory-types.Int64Size+types.SliceHeaderSize+int64(cap(obj.value.([]byte))), s.usedMemory)
+
+	// The appended digits are still an integer
+	val, err := s.IncrBy("key1", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(124), *val)
+	assert.Equal(t, EncInt, obj.encoding)
+
+	s.Append("key1", "abc")
+	_, err = s.IncrBy("key1", 1)
+	assert.Equal(t, ErrValueIsNotIntegerOrOutOfRangeError, err)
+}
+
+func TestAppendChangesStringInPlace(t *testing.T) {
+	s := newTestStore().(*store)
+	s.Set("key1", "a")
+
+	for i := 0; i < 1000; i++ {
+		s.Append("key1", "b")
+	}
+	obj, _ := s.data.Get("key1")
+	buf := obj.value.([]byte)
+
+	// Appending within the spare capacity keeps the same buffer
+	for len(buf) < cap(buf) {
+		s.Append("key1", "c")
+		assert.Same(t, &buf[0], &obj.value.([]byte)[0])
+		buf = obj.value.([]byte)
+	}
+
+	val, _ := s.Get("key1")
+	assert.Equal(t, "a"+strings.Repeat("b", 1000)+strings.Repeat("c", len(buf)-1001), *val)
+}
+
+func TestAppendKeepsExpiration(t *testing.T) {
+	s := newTestStore()
+	s.SetEx("key1", "a", 100)
+
+	s.Append("key1", "b")
+	assert.Greater(t, s.TTL("key1"), int64(0))
+}
+
+func TestAppendExceedsMaximumSize(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.ProtoMaxBulkLen = 8
+	s := NewStore(cfg)
+	s.Set("key1", "hello")
+
+	_, err := s.Append("key1", "world")
+	assert.Equal(t, ErrStringExceedsMaximumSizeError, err)
+
+	_, err = s.SetRange("key2", 5, "abcd")
+	assert.Equal(t, ErrStringExceedsMaximumSizeError, err)
+	assert.False(t, s.Exists("key2"))
+
+	length, err := s.SetRange("key2", 4, "abcd")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), length)
+
+	_, err = s.SetRange("key2", math.MaxInt64, "a")
+	assert.Equal(t, ErrStringExceedsMaximumSizeError, err)
+}
+
+func TestStrLen(t *testing.T) {
+	s := newTestStore()
+	s.Set("str", "hello")
+	s.Set("int", "-123")
+	s.RPush("list", "a")
+
+	length, _ := s.StrLen("str")
+	assert.Equal(t, int64(5), length)
+	length, _ = s.StrLen("int")
+	assert.Equal(t, int64(4), length)
+	length, _ = s.StrLen("missing")
+	assert.Equal(t, int64(0), length)
+
+	_, err := s.StrLen("list")
+	assert.Equal(t, ErrWrongTypeError, err)
+}
+
+func TestGetRange(t *testing.T) {
+	s := newTestStore()
+	s.Set("key1", "This is a string")
+
+	tests := []struct {
+		start, end int64
+		expected   string
+	}{
+		{0, 3, "This"},
+		{-3, -1, "ing"},
+		{0, -1, "This is a string"},
+		{10, 100, "string"},
+		{5, 3, ""},
+		{-1, -5, ""},
+		{-100, 1, "Th"},
+	}
+
+	for _, tt := range tests {
+		val, err := s.GetRange("key1", tt.start, tt.end)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, val, "start=%d end=%d", tt.start, tt.end)
+	}
+
+	val, _ := s.GetRange("missing", 0, -1)
+	assert.Equal(t, "", val)
+}
+
+func TestSetRange(t *testing.T) {
+	s := newTestStore()
+	s.Set("key1", "Hello World")
+
+	length, err := s.SetRange("key1", 6, "Redis")
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), length)
+	val, _ := s.Get("key1")
+	assert.Equal(t, "Hello Redis", *val)
+
+	length, _ = s.SetRange("key2", 3, "ab")
+	assert.Equal(t, int64(5), length)
+	val, _ = s.Get("key2")
+	assert.Equal(t, "\x00\x00\x00ab", *val)
+}
+
+func TestSetRangeEmptyValue(t *testing.T) {
+	s := newTestStore()
+	s.Set("key1", "abc")
+
+	length, _ := s.SetRange("key1", 10, "")
+	assert.Equal(t, int64(3), length)
+
+	length, _ = s.SetRange("missing", 10, "")
+	assert.Equal(t, int64(0), length)
+	assert.False(t, s.Exists("missing"))
+}
+
+func TestIncrByFloat(t *testing.T) {
+	s := newTestStore()
+	s.Set("key1", "10.50")
+
+	incr := func(increment string) (string, error) {
+		f, ok := types.ParseLongDouble(increment)
+		require.True(t, ok)
+		return s.IncrByFloat("key1", f)
+	}
+
+	val, err := incr("0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "10.6", val)
+
+	val, _ = incr("-5")
+	assert.Equal(t, "5.6", val)
+
+	s.Set("key1", "5.0e3")
+	val, _ = incr("2.0e2")
+	assert.Equal(t, "5200", val)
+
+	stored, _ := s.Get("key1")
+	assert.Equal(t, "5200", *stored)
+}
+
+func TestIncrByFloatLongDoublePrecision(t *testing.T) {
+	s := newTestStore()
+	s.Set("key1", "0.1")
+
+	f, _ := types.ParseLongDouble("0.2")
+	val, err := s.IncrByFloat("key1", f)
+	require.NoError(t, err)
+	assert.Equal(t, "0.3", val)
+}
+
+func TestIncrByFloatErrors(t *testing.T) {
+	s := newTestStore()
+	s.Set("str", "abc")
+	s.Set("inf", "inf")
+	s.RPush("list", "a")
+	one := big.NewFloat(1).SetPrec(64)
+
+	_, err := s.IncrByFloat("str", one)
+	assert.Equal(t, ErrValueIsNotValidFloatError, err)
+
+	_, err = s.IncrByFloat("list", one)
+	assert.Equal(t, ErrWrongTypeError, err)
+
+	_, err = s.IncrByFloat("inf", one)
+	assert.Equal(t, ErrIncrementProducesNaNOrInfinityError, err)
+
+	inf, _ := types.ParseLongDouble("+inf")
+	_, err = s.IncrByFloat("new", inf)
+	assert.Equal(t, ErrIncrementProducesNaNOrInfinityError, err)
+	assert.False(t, s.Exists("new"))
+
+	s.Set("huge", "1e4932")
+	huge, _ := types.ParseLongDouble("1e4932")
+	_, err = s.IncrByFloat("huge", huge)
+	assert.Equal(t, ErrIncrementProducesNaNOrInfinityError, err)
+
+	s.Set("beyond", "1e5000")
+	_, err = s.IncrByFloat("beyond", one)
+	assert.Equal(t, ErrValueIsNotValidFloatError, err)
+}
+
+func TestLCS(t *testing.T) {
+	s := newTestStore()
+	s.Set("key1", "ohmytext")
+	s.Set("key2", "mynewtext")
+	s.RPush("list", "a")
+
+	lcs, matches, err := s.LCS("key1", "key2")
+	require.NoError(t, err)
+	assert.Equal(t, "mytext", lcs)
+	assert.Len(t, matches, 2)
+
+	lcs, _, _ = s.LCS("key1", "missing")
+	assert.Equal(t, "", lcs)
+
+	_, _, err = s.LCS("key1", "list")
+	assert.Equal(t, ErrLCSKeysNotStringsError, err)
+}
+
+func TestLCSInsufficientMemory(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.ProtoMaxBulkLen = 1024
+	s := NewStore(cfg)
+	s.Set("key1", strings.Repeat("a", 100))
+	s.Set("key2", strings.Repeat("a", 100))
+
+	_, _, err := s.LCS("key1", "key2")
+	assert.Equal(t, ErrLCSInsufficientMemoryError, err)
+}
+
+func TestSetBitAndSetRangeChangeStringInPlace(t *testing.T) {
+	s := newTestStore().(*store)
+	s.SetBit("key1", 8*1024*1024-1, 1)
+	obj, _ := s.data.Get("key1")
+	buf := obj.value.([]byte)
+
+	memory := s.usedMemory
+	for offset := uint64(0); offset < 8*1024*1024; offset += 8 * 1024 {
+		s.SetBit("key1", offset, 1)
+	}
+	length, _ := s.SetRange("key1", 10, "abc")
+	assert.Equal(t, int64(1024*1024), length)
+
+	assert.Same(t, &buf[0], &obj.value.([]byte)[0])
+	assert.Equal(t, memory, s.usedMemory)
+
+	count, _ := s.BitCount("key1", BitRange{Start: 0, End: -1})
+	assert.Equal(t, int64(1024+1+10), count) // a bit per KiB, the last bit and "abc"
+	value, _ := s.GetRange("key1", 10, 12)
+	assert.Equal(t, "abc", value)
+}
+
+func TestSetBitGrowsString(t *testing.T) {
+	s := newTestStore().(*store)
+
+	old, err := s.SetBit("key1", 7, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, old)
+
+	obj, _ := s.data.Get("key1")
+	memory, oldCap := s.usedMemory, cap(obj.value.([]byte))
+	old, _ = s.SetBit("key1", 100, 1)
+	assert.Equal(t, 0, old)
+	assert.Equal(t, memory+int64(cap(obj.value.([]byte))-oldCap), s.usedMemory)
+
+	val, _ := s.Get("key1")
+	assert.Len(t, *val, 13)
+
+	bit, _ := s.GetBit("key1", 100)
+	assert.Equal(t, 1, bit)
+	bit, _ = s.GetBit("key1", 1000)
+	assert.Equal(t, 0, bit)
+	bit, _ = s.GetBit("missing", 0)
+	assert.Equal(t, 0, bit)
+}
+
+func TestSetBitOffsetOutOfRange(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.ProtoMaxBulkLen = 4
+	s := NewStore(cfg)
+
+	_, err := s.SetBit("key1", 32, 1)
+	assert.Equal(t, ErrBitOffsetOutOfRangeError, err)
+	assert.False(t, s.Exists("key1"))
+
+	_, err = s.SetBit("key1", 31, 1)
+	assert.NoError(t, err)
+}
+
+func TestBitCountRanges(t *testing.T) {
+	s := newTestStore()
+	s.Set("key1", "foobar")
+
+	tests := []struct {
+		rng      BitRange
+		expected int64
+	}{
+		{BitRange{Start: 0, End: -1}, 26},
+		{BitRange{Start: 0, End: 0}, 4},
+		{BitRange{Start: 1, End: 1}, 6},
+		{BitRange{Start: 5, End: 30, Bits: true}, 17},
+		{BitRange{Start: -2, End: -1}, 7},
+		{BitRange{Start: -1, End: -2}, 0},
+		{BitRange{Start: 4, End: 2}, 0},
+	}
+
+	for _, tt := range tests {
+		count, err := s.BitCount("key1", tt.rng)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, count, "%+v", tt.rng)
+	}
+
+	count, _ := s.BitCount("missing", BitRange{Start: 0, End: -1})
+	assert.Equal(t, int64(0), count)
+}
+
+func TestBitPosRanges(t *testing.T) {
+	s := newTestStore()
+	s.Set("ones", "\xff\xf0\x00")
+	s.Set("key1", "\x00\xff\xf0")
+	s.Set("full", "\xff\xff")
+
+	pos, _ := s.BitPos("ones", 0, BitRange{Start: 0, End: -1}, false)
+	assert.Equal(t, int64(12), pos)
+	pos, _ = s.BitPos("key1", 1, BitRange{Start: 2, End: -1}, false)
+	assert.Equal(t, int64(16), pos)
+	pos, _ = s.BitPos("key1", 1, BitRange{Start: 7, End: 15, Bits: true}, true)
+	assert.Equal(t, int64(8), pos)
+
+	// Clear bits are found past the end unless the end is given
+	pos, _ = s.BitPos("full", 0, BitRange{Start: 0, End: -1}, false)
+	assert.Equal(t, int64(16), pos)
+	pos, _ = s.BitPos("full", 0, BitRange{Start: 0, End: -1}, true)
+	assert.Equal(t, int64(-1), pos)
+
+	pos, _ = s.BitPos("missing", 0, BitRange{Start: 0, End: -1}, false)
+	assert.Equal(t, int64(0), pos)
+	pos, _ = s.BitPos("missing", 1, BitRange{Start: 0, End: -1}, false)
+	assert.Equal(t, int64(-1), pos)
+}
+
+func TestBitOp(t *testing.T) {
+	s := newTestStore()
+	s.Set("key1", "foobar")
+	s.Set("key2", "abcdef")
+	s.SetEx("dest", "old", 100)
+
+	length, err := s.BitOp(types.BitOpAnd, "dest", "key1", "key2")
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), length)
+	val, _ := s.Get("dest")
+	assert.Equal(t, "`bc`ab", *val)
+	assert.Equal(t, int64(-1), s.TTL("dest"))
+
+	length, _ = s.BitOp(types.BitOpOr, "dest", "missing1", "missing2")
+	assert.Equal(t, int64(0), length)
+	assert.False(t, s.Exists("dest"))
+
+	s.RPush("list", "a")
+	_, err = s.BitOp(types.BitOpNot, "dest", "list")
+	assert.Equal(t, ErrWrongTypeError, err)
+}
+
+func TestBitField(t *testing.T) {
+	s := newTestStore()
+
+	results, err := s.BitField("missing", []types.BitFieldOp{{Type: types.BitFieldGet, Bits: 8}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), *results[0])
+	assert.False(t, s.Exists("missing"))
+
+	results, _ = s.BitField("key1", []types.BitFieldOp{
+		{Type: types.BitFieldIncrBy, Signed: true, Bits: 5, Offset: 100, Value: 1},
+		{Type: types.BitFieldGet, Bits: 4, Offset: 0},
+		{Type: types.BitFieldIncrBy, Bits: 2, Offset: 0, Value: 5, Overflow: types.BitFieldFail},
+	})
+	assert.Equal(t, int64(1), *results[0])
+	assert.Equal(t, int64(0), *results[1])
+	assert.Nil(t, results[2])
+
+	val, _ := s.Get("key1")
+	assert.Len(t, *val, 14)
+}
@@ -0,0 +1,273 @@
+package types
+
+import (
+	"math"
+	"math/bits"
+)
+
+// Bits are numbered from the most significant bit of the first byte, like in Redis.
+
+// GetBit returns the bit at offset, 0 past the end of b.
+func GetBit(b []byte, offset uint64) int {
+	if offset>>3 >= uint64(len(b)) {
+		return 0
+	}
+	return int(b[offset>>3]>>(7-offset&7)) & 1
+}
+
+// SetBit sets the bit at offset, which must be within b, and returns its previous value.
+func SetBit(b []byte, offset uint64, bit int) int {
+	old := GetBit(b, offset)
+	mask := byte(1) << (7 - offset&7)
+	if bit == 1 {
+		b[offset>>3] |= mask
+	} else {
+		b[offset>>3] &^= mask
+	}
+	return old
+}
+
+// BitCount returns the number of set bits from startBit to endBit, both inclusive.
+func BitCount(b []byte, startBit, endBit int64) int64 {
+	var count int64
+	for pos := startBit; pos <= endBit; {
+		// Count whole bytes at once
+		if pos&7 == 0 && pos+7 <= endBit {
+			count += int64(bits.OnesCount8(b[pos>>3]))
+			pos += 8
+			continue
+		}
+		count += int64(GetBit(b, uint64(pos)))
+		pos++
+	}
+	return count
+}
+
+// BitPos returns the position of the first bit set to bit from startBit to endBit, both
+// inclusive, or -1 if there is none.
+func BitPos(b []byte, bit int, startBit, endBit int64) int64 {
+	skip := byte(0x00)
+	if bit == 0 {
+		skip = 0xff
+	}
+
+	for pos := startBit; pos <= endBit; {
+		// Skip whole bytes that cannot hold the bit
+		if pos&7 == 0 && pos+7 <= endBit && b[pos>>3] == skip {
+			pos += 8
+			continue
+		}
+		if GetBit(b, uint64(pos)) == bit {
+			return pos
+		}
+		pos++
+	}
+	return -1
+}
+
+type BitOp uint8
+
+const (
+	BitOpAnd BitOp = iota
+	BitOpOr
+	BitOpXor
+	BitOpNot
+)
+
+// ApplyBitOp combines srcs byte by byte into a result as long as the longest of them.
+// Shorter sources are padded with zero bytes. BitOpNot takes a single source.
+func ApplyBitOp(op BitOp, srcs [][]byte) []byte {
+	length := 0
+	for _, src := range srcs {
+		length = max(length, len(src))
+	}
+
+	result := make([]byte, length)
+	for i := range result {
+		byteAt := func(src []byte) byte {
+			if i < len(src) {
+				return src[i]
+			}
+			return 0
+		}
+
+		value := byteAt(srcs[0])
+		for _, src := range srcs[1:] {
+			switch op {
+			case BitOpAnd:
+				value &= byteAt(src)
+			case BitOpOr:
+				value |= byteAt(src)
+			case BitOpXor:
+				value ^= byteAt(src)
+			}
+		}
+		if op == BitOpNot {
+			value = ^value
+		}
+		result[i] = value
+	}
+	return result
+}
+
+type BitFieldOpType uint8
+
+const (
+	BitFieldGet BitFieldOpType = iota
+	BitFieldSet
+	BitFieldIncrBy
+)
+
+type BitFieldOverflow uint8
+
+const (
+	BitFieldWrap BitFieldOverflow = iota
+	BitFieldSat
+	BitFieldFail
+)
+
+// BitFieldOp is one GET, SET or INCRBY of BITFIELD on an integer of Bits bits at Offset.
+type BitFieldOp struct {
+	Type     BitFieldOpType
+	Signed   bool
+	Bits     int
+	Offset   uint64
+	Value    int64 // value of SET, increment of INCRBY
+	Overflow BitFieldOverflow
+}
+
+// LastByte returns the index of the last byte the integer of op spans.
+func (op BitFieldOp) LastByte() uint64 {
+	return (op.Offset + uint64(op.Bits) - 1) >> 3
+}
+
+// ApplyBitField runs op on b, which must span the integer of op unless op is a GET. It
+// returns the value read, the previous value for SET or the new value for INCRBY, and false
+// instead when the FAIL overflow mode prevented a write.
+func ApplyBitField(b []byte, op BitFieldOp) (int64, bool) {
+	old := getBitField(b, op.Offset, op.Bits, op.Signed)
+	if op.Type == BitFieldGet {
+		return old, true
+	}
+
+	// SET stores the value as an increment of zero applied to it
+	value, incr := op.Value, op.Value
+	if op.Type == BitFieldSet {
+		incr = 0
+	} else {
+		value = old
+	}
+
+	var overflow bool
+	var limit int64
+	if op.Signed {
+		overflow, limit = signedBitFieldOverflow(value, incr, op.Bits, op.Overflow)
+	} else {
+		var ulimit uint64
+		overflow, ulimit = unsignedBitFieldOverflow(uint64(value), incr, op.Bits, op.Overflow)
+		limit = int64(ulimit)
+	}
+
+	result := value + incr
+	if overflow {
+		if op.Overflow == BitFieldFail {
+			return 0, false
+		}
+		result = limit
+	}
+
+	setBitField(b, op.Offset, op.Bits, uint64(result))
+	if op.Type == BitFieldSet {
+		return old, true
+	}
+	return result, true
+}
+
+func getBitField(b []byte, offset uint64, n int, signed bool) int64 {
+	var value uint64
+	for j := 0; j < n; j++ {
+		value = value<<1 | uint64(GetBit(b, offset+uint64(j)))
+	}
+
+	// Extend the sign bit to the higher order bits
+	if signed && n < 64 && value&(1<<(n-1)) != 0 {
+		value |= math.MaxUint64 << n
+	}
+	return int64(value)
+}
+
+func setBitField(b []byte, offset uint64, n int, value uint64) {
+	for j := 0; j < n; j++ {
+		SetBit(b, offset+uint64(j), int(value>>(n-1-j))&1)
+	}
+}
+
+// unsignedBitFieldOverflow reports whether value+incr overflows an unsigned integer of n
+// bits, with the value to store instead in the WRAP and SAT modes.
+func unsignedBitFieldOverflow(value uint64, incr int64, n int, mode BitFieldOverflow) (bool, uint64) {
+	maxValue := uint64(math.MaxUint64)
+	if n < 64 {
+		maxValue = 1<<n - 1
+	}
+	maxIncr := int64(maxValue - value)
+	minIncr := -int64(value)
+
+	wrap := func() uint64 {
+		return (value + uint64(incr)) & maxValue
+	}
+
+	if value > maxValue || (incr > 0 && incr > maxIncr) {
+		if mode == BitFieldWrap {
+			return true, wrap()
+		}
+		return true, maxValue
+	}
+	if incr < 0 && incr < minIncr {
+		if mode == BitFieldWrap {
+			return true, wrap()
+		}
+		return true, 0
+	}
+	return false, 0
+}
+
+// signedBitFieldOverflow reports whether value+incr overflows a signed integer of n bits,
+// with the value to store instead in the WRAP and SAT modes.
+func signedBitFieldOverflow(value, incr int64, n int, mode BitFieldOverflow) (bool, int64) {
+	maxValue := int64(math.MaxInt64)
+	if n < 64 {
+		maxValue = 1<<(n-1) - 1
+	}
+	minValue := -maxValue - 1
+
+	// maxIncr and minIncr may overflow, they are only used once value is known to be in range
+	maxIncr := int64(uint64(maxValue) - uint64(value))
+	minIncr := minValue - value
+
+	wrap := func() int64 {
+		c := uint64(value) + uint64(incr)
+		if n < 64 {
+			mask := uint64(math.MaxUint64) << n
+			if c&(1<<(n-1)) != 0 {
+				c |= mask
+			} else {
+				c &^= mask
+			}
+		}
+		return int64(c)
+	}
+
+	if value > maxValue || (n != 64 && incr > maxIncr) || (value >= 0 && incr > 0 && incr > maxIncr) {
+		if mode == BitFieldWrap {
+			return true, wrap()
+		}
+		return true, maxValue
+	}
+	if value < minValue || (n != 64 && incr < minIncr) || (value < 0 && incr < 0 && incr < minIncr) {
+		if mode == BitFieldWrap {
+			return true, wrap()
+		}
+		return true, minValue
+	}
+	return false, 0
+}
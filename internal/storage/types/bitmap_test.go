@@ -0,0 +1,118 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetBitGetBit(t *testing.T) {
+	b := make([]byte, 2)
+
+	assert.Equal(t, 0, SetBit(b, 7, 1))
+	assert.Equal(t, []byte{0x01, 0x00}, b)
+	assert.Equal(t, 1, SetBit(b, 7, 0))
+	assert.Equal(t, 0, SetBit(b, 8, 1))
+	assert.Equal(t, []byte{0x00, 0x80}, b)
+
+	assert.Equal(t, 1, GetBit(b, 8))
+	assert.Equal(t, 0, GetBit(b, 100))
+}
+
+func TestBitCount(t *testing.T) {
+	b := []byte("foobar")
+
+	assert.Equal(t, int64(26), BitCount(b, 0, 47))
+	assert.Equal(t, int64(4), BitCount(b, 0, 7))
+	assert.Equal(t, int64(17), BitCount(b, 5, 30))
+}
+
+func TestBitPos(t *testing.T) {
+	b := []byte{0xff, 0xf0, 0x00}
+	assert.Equal(t, int64(12), BitPos(b, 0, 0, 23))
+	assert.Equal(t, int64(0), BitPos(b, 1, 0, 23))
+
+	b = []byte{0x00, 0xff, 0xf0}
+	assert.Equal(t, int64(8), BitPos(b, 1, 0, 23))
+	assert.Equal(t, int64(8), BitPos(b, 1, 7, 15))
+	assert.Equal(t, int64(-1), BitPos(b, 1, 20, 23))
+}
+
+func TestApplyBitOp(t *testing.T) {
+	a, b := []byte("foobar"), []byte("abcdef")
+
+	assert.Equal(t, []byte("`bc`ab"), ApplyBitOp(BitOpAnd, [][]byte{a, b}))
+	assert.Equal(t, []byte{0x07, 0x0d, 0x0c, 0x06, 0x04, 0x14}, ApplyBitOp(BitOpXor, [][]byte{a, b}))
+	assert.Equal(t, []byte{0xf0, 0xff}, ApplyBitOp(BitOpOr, [][]byte{{0xf0}, {0x00, 0xff}}))
+	assert.Equal(t, []byte{0xf0, 0x00}, ApplyBitOp(BitOpAnd, [][]byte{{0xf0}, {0xff, 0xff}}))
+	assert.Equal(t, []byte{0x0f}, ApplyBitOp(BitOpNot, [][]byte{{0xf0}}))
+	assert.Empty(t, ApplyBitOp(BitOpOr, [][]byte{nil, nil}))
+}
+
+func TestApplyBitField_GetSet(t *testing.T) {
+	b := make([]byte, 4)
+
+	old, ok := ApplyBitField(b, BitFieldOp{Type: BitFieldSet, Signed: true, Bits: 8, Offset: 0, Value: -100})
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), old)
+
+	value, _ := ApplyBitField(b, BitFieldOp{Type: BitFieldGet, Signed: true, Bits: 8, Offset: 0})
+	assert.Equal(t, int64(-100), value)
+	value, _ = ApplyBitField(b, BitFieldOp{Type: BitFieldGet, Bits: 8, Offset: 0})
+	assert.Equal(t, int64(156), value)
+
+	// Integers may span bytes and start anywhere
+	ApplyBitField(b, BitFieldOp{Type: BitFieldSet, Bits: 12, Offset: 5, Value: 0xabc})
+	value, _ = ApplyBitField(b, BitFieldOp{Type: BitFieldGet, Bits: 12, Offset: 5})
+	assert.Equal(t, int64(0xabc), value)
+}
+
+func TestApplyBitField_Overflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       BitFieldOp
+		start    int64
+		expected int64
+		ok       bool
+	}{
+		{"unsigned wrap", BitFieldOp{Bits: 2, Value: 1}, 3, 0, true},
+		{"unsigned sat", BitFieldOp{Bits: 2, Value: 1, Overflow: BitFieldSat}, 3, 3, true},
+		{"unsigned sat below", BitFieldOp{Bits: 8, Value: -10, Overflow: BitFieldSat}, 5, 0, true},
+		{"unsigned fail", BitFieldOp{Bits: 2, Value: 1, Overflow: BitFieldFail}, 3, 0, false},
+		{"signed wrap", BitFieldOp{Signed: true, Bits: 8, Value: 1}, 127, -128, true},
+		{"signed wrap below", BitFieldOp{Signed: true, Bits: 8, Value: -1}, -128, 127, true},
+		{"signed sat", BitFieldOp{Signed: true, Bits: 8, Value: 100, Overflow: BitFieldSat}, 100, 127, true},
+		{"signed sat below", BitFieldOp{Signed: true, Bits: 8, Value: -100, Overflow: BitFieldSat}, -100, -128, true},
+		{"signed 64 bits", BitFieldOp{Signed: true, Bits: 64, Value: 1, Overflow: BitFieldSat}, 9223372036854775807, 9223372036854775807, true},
+		{"signed fail", BitFieldOp{Signed: true, Bits: 5, Value: 100, Overflow: BitFieldFail}, 0, 0, false},
+		{"in range", BitFieldOp{Signed: true, Bits: 5, Value: 3, Overflow: BitFieldFail}, 10, 13, true},
+	}
+
+	for _, tt := range tests {
+		b := make([]byte, 8)
+		ApplyBitField(b, BitFieldOp{Type: BitFieldSet, Signed: tt.op.Signed, Bits: tt.op.Bits, Value: tt.start})
+
+		tt.op.Type = BitFieldIncrBy
+		value, ok := ApplyBitField(b, tt.op)
+		assert.Equal(t, tt.ok, ok, tt.name)
+		if ok {
+			assert.Equal(t, tt.expected, value, tt.name)
+		}
+	}
+}
+
+func TestApplyBitField_SetOverflow(t *testing.T) {
+	b := make([]byte, 1)
+
+	_, ok := ApplyBitField(b, BitFieldOp{Type: BitFieldSet, Bits: 4, Value: 20, Overflow: BitFieldFail})
+	assert.False(t, ok)
+	assert.Equal(t, byte(0), b[0])
+
+	ApplyBitField(b, BitFieldOp{Type: BitFieldSet, Bits: 4, Value: 20, Overflow: BitFieldSat})
+	value, _ := ApplyBitField(b, BitFieldOp{Type: BitFieldGet, Bits: 4})
+	assert.Equal(t, int64(15), value)
+
+	ApplyBitField(b, BitFieldOp{Type: BitFieldSet, Bits: 4, Value: -1})
+	value, _ = ApplyBitField(b, BitFieldOp{Type: BitFieldGet, Bits: 4})
+	assert.Equal(t, int64(15), value)
+}
@@ -12,6 +12,8 @@ type CountMinSketch interface {
 	Info() []any
 	Query(items []string) []uint64
 	MemoryUsage() int64
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary(data []byte) error
 }
 
 type countMinSketch struct {
@@ -107,3 +109,46 @@ func (cms *countMinSketch) getIndexes(item string) []uint64 {
 func (cms *countMinSketch) MemoryUsage() int64 {
 	return int64(size.Of(cms))
 }
+
+func (cms *countMinSketch) MarshalBinary() ([]byte, error) {
+	depth := len(cms.grid)
+	width := len(cms.grid[0])
+
+	buf := make([]byte, 0, 16+depth*width)
+	buf = appendUvarint(buf, uint64(width))
+	buf = appendUvarint(buf, uint64(depth))
+	buf = appendUvarint(buf, cms.totalCount)
+	for i := range cms.grid {
+		for _, counter := range cms.grid[i] {
+			buf = appendUvarint(buf, counter)
+		}
+	}
+
+	return buf, nil
+}
+
+func (cms *countMinSketch) UnmarshalBinary(data []byte) error {
+	r := &binaryReader{data: data}
+	width := r.length(1)
+	depth := r.length(1)
+	totalCount := r.uvarint()
+	if r.err == nil && (width == 0 || depth == 0 || width*depth > len(r.data)) {
+		return ErrCorruptEncoding
+	}
+
+	grid := make([][]uint64, depth)
+	for i := range grid {
+		grid[i] = make([]uint64, width)
+		for j := range grid[i] {
+			grid[i][j] = r.uvarint()
+		}
+	}
+
+	if err := r.finish(); err != nil {
+		return err
+	}
+
+	cms.grid = grid
+	cms.totalCount = totalCount
+	return nil
+}
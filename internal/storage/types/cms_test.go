@@ -417,3 +417,18 @@ func TestCountMinSketchGetIndexesBounds(t *testing.T) {
 		}
 	}
 }
+
+func TestCountMinSketchMarshalBinaryRoundTrip(t *testing.T) {
+	cms := NewCountMinSketchByDim(50, 4)
+	cms.IncrBy(map[string]uint64{"a": 3, "b": 7})
+
+	data, err := cms.MarshalBinary()
+	require.NoError(t, err)
+
+	loaded := NewCountMinSketchByDim(1, 1)
+	require.NoError(t, loaded.UnmarshalBinary(data))
+	assert.Equal(t, cms.Info(), loaded.Info())
+	assert.Equal(t, []uint64{3, 7, 0}, loaded.Query([]string{"a", "b", "c"}))
+
+	require.Error(t, NewCountMinSketchByDim(1, 1).UnmarshalBinary(data[:len(data)-1]))
+}
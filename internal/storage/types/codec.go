@@ -0,0 +1,122 @@
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// ErrCorruptEncoding is returned by UnmarshalBinary when the input is truncated or malformed.
+var ErrCorruptEncoding = errors.New("corrupt binary encoding")
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	return binary.AppendUvarint(buf, v)
+}
+
+func appendFloat64(buf []byte, f float64) []byte {
+	return binary.LittleEndian.AppendUint64(buf, math.Float64bits(f))
+}
+
+// binaryReader decodes the values written by the append helpers above.
+// The first failure is sticky, so callers only need to check err once at the end.
+type binaryReader struct {
+	data []byte
+	err  error
+}
+
+func (r *binaryReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = ErrCorruptEncoding
+		return 0
+	}
+
+	r.data = r.data[n:]
+	return v
+}
+
+// length reads a count and checks it against the bytes left, where each counted
+// element occupies at least minSize bytes. This rejects absurd allocations on corrupt input.
+func (r *binaryReader) length(minSize int) int {
+	v := r.uvarint()
+	if r.err == nil && v > uint64(len(r.data)/max(minSize, 1)) {
+		r.err = ErrCorruptEncoding
+		return 0
+	}
+	return int(v)
+}
+
+func (r *binaryReader) uint16() uint16 {
+	if r.err != nil {
+		return 0
+	}
+
+	if len(r.data) < 2 {
+		r.err = ErrCorruptEncoding
+		return 0
+	}
+
+	v := binary.LittleEndian.Uint16(r.data)
+	r.data = r.data[2:]
+	return v
+}
+
+func (r *binaryReader) uint64() uint64 {
+	if r.err != nil {
+		return 0
+	}
+
+	if len(r.data) < 8 {
+		r.err = ErrCorruptEncoding
+		return 0
+	}
+
+	v := binary.LittleEndian.Uint64(r.data)
+	r.data = r.data[8:]
+	return v
+}
+
+func (r *binaryReader) float64() float64 {
+	return math.Float64frombits(r.uint64())
+}
+
+func (r *binaryReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+
+	if n < 0 || len(r.data) < n {
+		r.err = ErrCorruptEncoding
+		return nil
+	}
+
+	v := r.data[:n]
+	r.data = r.data[n:]
+	return v
+}
+
+// finish reports the first decoding error, or an error if unread bytes remain.
+func (r *binaryReader) finish() error {
+	if r.err != nil {
+		return r.err
+	}
+
+	if len(r.data) != 0 {
+		return ErrCorruptEncoding
+	}
+
+	return nil
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func (r *binaryReader) string() string {
+	return string(r.bytes(r.length(1)))
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"encoding/binary"
+
 	"github.com/DmitriyVTitov/size"
 	"github.com/spaolacci/murmur3"
 )
@@ -29,6 +31,8 @@ type CuckooFilter interface {
 	Info() []any
 	MExists(items []string) []int
 	MemoryUsage() int64
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary(data []byte) error
 }
 
 type bucket struct {
@@ -430,3 +434,82 @@ func (scf *scalableCuckooFilter) MExists(items []string) []int {
 func (scf *scalableCuckooFilter) MemoryUsage() int64 {
 	return int64(size.Of(scf))
 }
+
+func (scf *scalableCuckooFilter) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, 64)
+	buf = appendUvarint(buf, scf.initialCapacity)
+	buf = appendUvarint(buf, scf.initialBucketSize)
+	buf = appendUvarint(buf, scf.maxIterations)
+	buf = appendUvarint(buf, uint64(scf.expansionRate))
+	buf = appendUvarint(buf, scf.totalItems)
+	buf = appendUvarint(buf, scf.totalDeletes)
+	buf = appendUvarint(buf, uint64(len(scf.filters)))
+
+	for _, f := range scf.filters {
+		buf = appendUvarint(buf, f.bucketSize)
+		buf = appendUvarint(buf, f.numBuckets)
+		buf = appendUvarint(buf, f.fingerprintSize)
+		buf = appendUvarint(buf, f.maxKicks)
+		buf = appendUvarint(buf, f.insertedItems)
+		buf = appendUvarint(buf, f.deletedItems)
+		for _, b := range f.buckets {
+			for _, fp := range b.fingerprints {
+				buf = binary.LittleEndian.AppendUint16(buf, fp)
+			}
+		}
+	}
+
+	return buf, nil
+}
+
+func (scf *scalableCuckooFilter) UnmarshalBinary(data []byte) error {
+	r := &binaryReader{data: data}
+	initialCapacity := r.uvarint()
+	initialBucketSize := r.uvarint()
+	maxIterations := r.uvarint()
+	expansionRate := int(r.uvarint())
+	totalItems := r.uvarint()
+	totalDeletes := r.uvarint()
+
+	filters := make([]*subCuckooFilter, r.length(1))
+	for i := range filters {
+		f := &subCuckooFilter{
+			bucketSize:      r.uvarint(),
+			numBuckets:      r.uvarint(),
+			fingerprintSize: r.uvarint(),
+			maxKicks:        r.uvarint(),
+			insertedItems:   r.uvarint(),
+			deletedItems:    r.uvarint(),
+		}
+
+		if r.err == nil && (f.bucketSize == 0 || f.numBuckets == 0 || f.numBuckets*f.bucketSize*2 > uint64(len(r.data))) {
+			return ErrCorruptEncoding
+		}
+
+		f.buckets = make([]bucket, f.numBuckets)
+		for j := range f.buckets {
+			f.buckets[j].fingerprints = make([]uint16, f.bucketSize)
+			for k := range f.buckets[j].fingerprints {
+				f.buckets[j].fingerprints[k] = r.uint16()
+			}
+		}
+		filters[i] = f
+	}
+
+	if err := r.finish(); err != nil {
+		return err
+	}
+
+	if len(filters) == 0 {
+		return ErrCorruptEncoding
+	}
+
+	scf.filters = filters
+	scf.initialCapacity = initialCapacity
+	scf.initialBucketSize = initialBucketSize
+	scf.maxIterations = maxIterations
+	scf.expansionRate = expansionRate
+	scf.totalItems = totalItems
+	scf.totalDeletes = totalDeletes
+	return nil
+}
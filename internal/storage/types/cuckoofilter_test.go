@@ -811,3 +811,24 @@ func TestCuckooFilterBucketsGrowWithScaling(t *testing.T) {
 
 	assert.Greater(t, newBuckets, initialBuckets)
 }
+
+func TestCuckooFilterMarshalBinaryRoundTrip(t *testing.T) {
+	cf := NewCuckooFilter(16, 2, 20, 1)
+	for i := 0; i < 50; i++ {
+		cf.Add(fmt.Sprintf("item%d", i))
+	}
+	cf.Del("item0")
+
+	data, err := cf.MarshalBinary()
+	require.NoError(t, err)
+
+	loaded := NewCuckooFilter(1, 1, 20, 1)
+	require.NoError(t, loaded.UnmarshalBinary(data))
+	assert.Equal(t, cf.Info(), loaded.Info())
+	assert.Equal(t, 0, loaded.Exists("item0"))
+	for i := 1; i < 50; i++ {
+		assert.Equal(t, 1, loaded.Exists(fmt.Sprintf("item%d", i)))
+	}
+
+	require.Error(t, NewCuckooFilter(1, 1, 20, 1).UnmarshalBinary(data[:len(data)-1]))
+}
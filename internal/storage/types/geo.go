@@ -5,8 +5,35 @@ import (
 	"sort"
 )
 
-// GeoAdd adds geospatial items to the sorted set
+// geoSet is the part of a sorted set the geo commands work with, whatever its encoding.
+type geoSet interface {
+	ZAdd(scoreMember map[string]float64, options ZAddOptions) (*uint32, int64)
+	ZScore(member string) *float64
+	forEach(fn func(member string, score float64))
+}
+
 func (zset *zSet) GeoAdd(items []GeoPoint, options ZAddOptions) (*uint32, int64) {
+	return geoAdd(zset, items, options)
+}
+
+func (zset *zSet) GeoDist(member1, member2 string, unit string) *float64 {
+	return geoDist(zset, member1, member2, unit)
+}
+
+func (zset *zSet) GeoHash(members []string) []*string {
+	return geoHash(zset, members)
+}
+
+func (zset *zSet) GeoPos(members []string) []*GeoPoint {
+	return geoPos(zset, members)
+}
+
+func (zset *zSet) GeoSearch(options GeoSearchOptions) []GeoResult {
+	return geoSearch(zset, options)
+}
+
+// geoAdd adds geospatial items to the sorted set
+func geoAdd(zset geoSet, items []GeoPoint, options ZAddOptions) (*uint32, int64) {
 	scoreMember := make(map[string]float64, len(items))
 	for _, item := range items {
 		hash := GeoHashEncode(item.Longitude, item.Latitude)
@@ -15,17 +42,17 @@ func (zset *zSet) GeoAdd(items []GeoPoint, options ZAddOptions) (*uint32, int64)
 	return zset.ZAdd(scoreMember, options)
 }
 
-// GeoDist returns the distance between two members in the specified unit
-func (zset *zSet) GeoDist(member1, member2 string, unit string) *float64 {
-	score1, exists1 := zset.data[member1]
-	score2, exists2 := zset.data[member2]
+// geoDist returns the distance between two members in the specified unit
+func geoDist(zset geoSet, member1, member2 string, unit string) *float64 {
+	score1 := zset.ZScore(member1)
+	score2 := zset.ZScore(member2)
 
-	if !exists1 || !exists2 {
+	if score1 == nil || score2 == nil {
 		return nil
 	}
 
-	lon1, lat1 := GeoHashDecode(uint64(score1))
-	lon2, lat2 := GeoHashDecode(uint64(score2))
+	lon1, lat1 := GeoHashDecode(uint64(*score1))
+	lon2, lat2 := GeoHashDecode(uint64(*score2))
 
 	distance := HaversineDistance(lon1, lat1, lon2, lat2)
 	converted := ConvertDistance(distance, unit)
@@ -33,36 +60,36 @@ func (zset *zSet) GeoDist(member1, member2 string, unit string) *float64 {
 	return &converted
 }
 
-// GeoHash returns the geohash strings for the specified members
-func (zset *zSet) GeoHash(members []string) []*string {
+// geoHash returns the geohash strings for the specified members
+func geoHash(zset geoSet, members []string) []*string {
 	result := make([]*string, len(members))
 
 	for i, member := range members {
-		score, exists := zset.data[member]
-		if !exists {
+		score := zset.ZScore(member)
+		if score == nil {
 			result[i] = nil
 			continue
 		}
 
-		hashStr := GeoHashToString(uint64(score))
+		hashStr := GeoHashToString(uint64(*score))
 		result[i] = &hashStr
 	}
 
 	return result
 }
 
-// GeoPos returns the longitude and latitude for the specified members
-func (zset *zSet) GeoPos(members []string) []*GeoPoint {
+// geoPos returns the longitude and latitude for the specified members
+func geoPos(zset geoSet, members []string) []*GeoPoint {
 	result := make([]*GeoPoint, len(members))
 
 	for i, member := range members {
-		score, exists := zset.data[member]
-		if !exists {
+		score := zset.ZScore(member)
+		if score == nil {
 			result[i] = nil
 			continue
 		}
 
-		lon, lat := GeoHashDecode(uint64(score))
+		lon, lat := GeoHashDecode(uint64(*score))
 		result[i] = &GeoPoint{
 			Longitude: lon,
 			Latitude:  lat,
@@ -73,8 +100,8 @@ func (zset *zSet) GeoPos(members []string) []*GeoPoint {
 	return result
 }
 
-// GeoSearch searches for members within the specified area
-func (zset *zSet) GeoSearch(options GeoSearchOptions) []GeoResult {
+// geoSearch searches for members within the specified area
+func geoSearch(zset geoSet, options GeoSearchOptions) []GeoResult {
 	var centerLon, centerLat float64
 
 	// Determine center point
@@ -82,11 +109,11 @@ func (zset *zSet) GeoSearch(options GeoSearchOptions) []GeoResult {
 		centerLon = options.FromLonLat.Longitude
 		centerLat = options.FromLonLat.Latitude
 	} else if options.FromMember != "" {
-		score, exists := zset.data[options.FromMember]
-		if !exists {
+		score := zset.ZScore(options.FromMember)
+		if score == nil {
 			return nil
 		}
-		centerLon, centerLat = GeoHashDecode(uint64(score))
+		centerLon, centerLat = GeoHashDecode(uint64(*score))
 	} else {
 		return nil
 	}
@@ -108,7 +135,7 @@ func (zset *zSet) GeoSearch(options GeoSearchOptions) []GeoResult {
 	// Collect all candidates and filter by actual distance
 	var results []GeoResult
 
-	for member, score := range zset.data {
+	zset.forEach(func(member string, score float64) {
 		lon, lat := GeoHashDecode(uint64(score))
 		distance := HaversineDistance(centerLon, centerLat, lon, lat)
 
@@ -133,7 +160,7 @@ func (zset *zSet) GeoSearch(options GeoSearchOptions) []GeoResult {
 				Latitude:  lat,
 			})
 		}
-	}
+	})
 
 	// Sort results
 	if options.Descending {
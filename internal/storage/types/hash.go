@@ -19,16 +19,26 @@ type Hash interface {
 	SetNX(key, value string) (bool, int64)
 	Delete(keys ...string) (int64, int64)
 	Exists(key string) bool
+	Scan(cursor uint64, count int) (uint64, []string)
+	RandField(count int, withValues bool) []string
+	FieldExpireTime(key string) (int64, bool)
+	SetFieldExpire(key string, expireAt int64) int64
+	PersistField(key string) (bool, int64)
+	ExpireFields(now int64) (int64, int64)
+	VolatileSize() int
 	MemoryUsage() int64
 }
 
 type simpleHash struct {
 	contents map[string]string
+	order    scanIndex
+	expires  fieldExpires
 }
 
 func NewHash() Hash {
 	return &simpleHash{
 		contents: make(map[string]string),
+		order:    newScanIndex(),
 	}
 }
 
@@ -85,23 +95,16 @@ func (s *simpleHash) IncBy(key string, increment int64) (int64, int64, error) {
 	if !exists {
 		newValue := strconv.FormatInt(increment, 10)
 		s.contents[key] = newValue
-		delta := StringStringMapEntrySize(key, newValue)
+		delta := StringStringMapEntrySize(key, newValue) + s.order.add(key)
 		return increment, delta, nil
 	}
 
-	valueInt, err := strconv.ParseInt(value, 10, 64)
+	valueInt, err := incrementValue(value, increment)
 	if err != nil {
-		return 0, 0, errors.New("hash value is not an integer")
-	}
-
-	// Check for overflow
-	if (increment > 0 && valueInt > math.MaxInt64-increment) ||
-		(increment < 0 && valueInt < math.MinInt64-increment) {
-		return 0, 0, errors.New("value is not an integer or out of range")
+		return 0, 0, err
 	}
 
 	oldValue := value
-	valueInt += increment
 	newValue := strconv.FormatInt(valueInt, 10)
 	s.contents[key] = newValue
 	// Delta is the difference in value string length
@@ -133,10 +136,13 @@ func (s *simpleHash) Set(fieldValue map[string]string) (int64, int64) {
 	for key, value := range fieldValue {
 		if oldValue, exists := s.contents[key]; !exists {
 			added++
-			delta += StringStringMapEntrySize(key, value)
+			delta += StringStringMapEntrySize(key, value) + s.order.add(key)
 		} else {
 			// Value is being updated, delta is the difference in value length
 			delta += StringSize(value) - StringSize(oldValue)
+			// Like in Redis, a new value does not keep the expiration time of the old one
+			_, expireDelta := s.expires.remove(key)
+			delta += expireDelta
 		}
 		s.contents[key] = value
 	}
@@ -150,7 +156,7 @@ func (s *simpleHash) SetNX(key, value string) (bool, int64) {
 	}
 
 	s.contents[key] = value
-	delta := StringStringMapEntrySize(key, value)
+	delta := StringStringMapEntrySize(key, value) + s.order.add(key)
 	return true, delta
 }
 
@@ -164,6 +170,9 @@ func (s *simpleHash) Delete(keys ...string) (int64, int64) {
 	for _, key := range keys {
 		if value, exists := s.contents[key]; exists {
 			delta -= StringStringMapEntrySize(key, value)
+			delta += s.order.remove(key)
+			_, expireDelta := s.expires.remove(key)
+			delta += expireDelta
 			delete(s.contents, key)
 			deleted++
 		}
@@ -181,6 +190,66 @@ func (s *simpleHash) Exists(key string) bool {
 	return exists
 }
 
+// Scan returns up to count field-value pairs, flattened like GetAll, and the next cursor.
+func (s *simpleHash) Scan(cursor uint64, count int) (uint64, []string) {
+	next, fields := s.order.scan(cursor, count)
+	result := make([]string, 0, len(fields)*2)
+	for _, field := range fields {
+		result = append(result, field, s.contents[field])
+	}
+	return next, result
+}
+
+func (s *simpleHash) RandField(count int, withValues bool) []string {
+	return randomFields(len(s.order.members), count, withValues, func(i int) (string, string) {
+		field := s.order.members[i]
+		return field, s.contents[field]
+	})
+}
+
+// FieldExpireTime returns the expiration time of the field key, false when it has none.
+func (s *simpleHash) FieldExpireTime(key string) (int64, bool) {
+	return s.expires.get(key)
+}
+
+// SetFieldExpire sets the expiration time of the existing field key.
+func (s *simpleHash) SetFieldExpire(key string, expireAt int64) int64 {
+	if _, exists := s.contents[key]; !exists {
+		return 0
+	}
+	return s.expires.set(key, expireAt)
+}
+
+func (s *simpleHash) PersistField(key string) (bool, int64) {
+	return s.expires.remove(key)
+}
+
+// ExpireFields deletes the fields whose expiration time is not after now.
+func (s *simpleHash) ExpireFields(now int64) (int64, int64) {
+	return s.Delete(s.expires.due(now)...)
+}
+
+// VolatileSize returns the number of fields with an expiration time.
+func (s *simpleHash) VolatileSize() int {
+	return s.expires.size()
+}
+
 func (s *simpleHash) MemoryUsage() int64 {
 	return int64(size.Of(s))
+}
+
+// incrementValue returns the integer value of a hash field incremented by increment.
+func incrementValue(value string, increment int64) (int64, error) {
+	valueInt, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, errors.New("hash value is not an integer")
+	}
+
+	// Check for overflow
+	if (increment > 0 && valueInt > math.MaxInt64-increment) ||
+		(increment < 0 && valueInt < math.MinInt64-increment) {
+		return 0, errors.New("value is not an integer or out of range")
+	}
+
+	return valueInt + increment, nil
 }
\ No newline at end of file
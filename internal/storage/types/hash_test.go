@@ -546,4 +546,94 @@ func TestHashMultipleOperations(t *testing.T) {
 	for i := 0; i < 50; i++ {
 		assert.False(t, h.Exists(fmt.Sprintf("key%d", i)))
 	}
-}
\ No newline at end of file
+}
+
+func TestHashRandField(t *testing.T) {
+	for name, h := range map[string]Hash{"hashtable": NewHash(), "listpack": NewListPackHash()} {
+		t.Run(name, func(t *testing.T) {
+			assert.Empty(t, h.RandField(3, false))
+
+			h.Set(map[string]string{"a": "1", "b": "2", "c": "3"})
+			assert.Empty(t, h.RandField(0, false))
+
+			fields := h.RandField(2, false)
+			assert.Len(t, fields, 2)
+			assert.NotEqual(t, fields[0], fields[1])
+			assert.ElementsMatch(t, []string{"a", "b", "c"}, h.RandField(10, false))
+
+			pairs := h.RandField(-7, true)
+			require.Len(t, pairs, 14)
+			for i := 0; i < len(pairs); i += 2 {
+				value, ok := h.Get(pairs[i])
+				assert.True(t, ok)
+				assert.Equal(t, value, pairs[i+1])
+			}
+			assert.Len(t, h.RandField(-randomFieldsMaxPrealloc-5, false), randomFieldsMaxPrealloc+5)
+		})
+	}
+}
+
+func TestHashFieldExpires(t *testing.T) {
+	for name, h := range map[string]Hash{"hashtable": NewHash(), "listpack": NewListPackHash()} {
+		t.Run(name, func(t *testing.T) {
+			h.Set(map[string]string{"a": "1", "b": "2", "c": "3", "d": "4"})
+
+			assert.Equal(t, int64(0), h.SetFieldExpire("missing", 100))
+			assert.Equal(t, fieldExpireEntrySize("a"), h.SetFieldExpire("a", 100))
+			assert.Equal(t, int64(0), h.SetFieldExpire("a", 200))
+			h.SetFieldExpire("b", 300)
+			h.SetFieldExpire("c", 400)
+			h.SetFieldExpire("d", 500)
+			assert.Equal(t, 4, h.VolatileSize())
+
+			expireAt, ok := h.FieldExpireTime("a")
+			assert.True(t, ok)
+			assert.Equal(t, int64(200), expireAt)
+
+			// Overwriting and deleting a field drop its expiration time, incrementing keeps it
+			h.Set(map[string]string{"b": "new"})
+			_, ok = h.FieldExpireTime("b")
+			assert.False(t, ok)
+			h.Delete("c")
+			_, _, err := h.IncBy("d", 1)
+			require.NoError(t, err)
+			_, ok = h.FieldExpireTime("d")
+			assert.True(t, ok)
+			assert.Equal(t, 2, h.VolatileSize())
+
+			persisted, delta := h.PersistField("d")
+			assert.True(t, persisted)
+			assert.Equal(t, -fieldExpireEntrySize("d"), delta)
+			persisted, _ = h.PersistField("d")
+			assert.False(t, persisted)
+
+			expired, _ := h.ExpireFields(199)
+			assert.Equal(t, int64(0), expired)
+			expired, _ = h.ExpireFields(200)
+			assert.Equal(t, int64(1), expired)
+			assert.False(t, h.Exists("a"))
+			assert.Equal(t, 0, h.VolatileSize())
+			assert.ElementsMatch(t, []string{"b", "d"}, h.GetKeys())
+		})
+	}
+}
+
+func TestFieldExpiresDue(t *testing.T) {
+	var fe fieldExpires
+	assert.Nil(t, fe.due(1000))
+
+	fe.set("a", 300)
+	fe.set("b", 100)
+	fe.set("c", 200)
+	assert.Equal(t, int64(100), fe.next)
+
+	assert.Nil(t, fe.due(99))
+	assert.ElementsMatch(t, []string{"b", "c"}, fe.due(250))
+	assert.Equal(t, int64(300), fe.next)
+
+	fe.remove("b")
+	fe.remove("c")
+	fe.remove("a")
+	assert.Nil(t, fe.times)
+	assert.Equal(t, int64(0), fe.next)
+}
@@ -0,0 +1,117 @@
+package types
+
+import "math/rand"
+
+// fieldExpires keeps the expiration times, in unix milliseconds, of the fields of a hash
+// that have one. Hashes without volatile fields do not allocate the map.
+type fieldExpires struct {
+	times map[string]int64
+	next  int64 // lower bound of the earliest expiration time, 0 without volatile fields
+}
+
+func (fe *fieldExpires) get(field string) (int64, bool) {
+	expireAt, ok := fe.times[field]
+	return expireAt, ok
+}
+
+// set sets the expiration time of field and returns the memory delta.
+func (fe *fieldExpires) set(field string, expireAt int64) int64 {
+	if fe.times == nil {
+		fe.times = make(map[string]int64)
+	}
+	if fe.next == 0 || expireAt < fe.next {
+		fe.next = expireAt
+	}
+
+	if _, exists := fe.times[field]; exists {
+		fe.times[field] = expireAt
+		return 0
+	}
+	fe.times[field] = expireAt
+	return fieldExpireEntrySize(field)
+}
+
+// remove removes the expiration time of field and returns the memory delta.
+func (fe *fieldExpires) remove(field string) (bool, int64) {
+	if _, exists := fe.times[field]; !exists {
+		return false, 0
+	}
+
+	delete(fe.times, field)
+	if len(fe.times) == 0 {
+		fe.times = nil
+		fe.next = 0
+	}
+	return true, -fieldExpireEntrySize(field)
+}
+
+// due returns the fields whose expiration time is not after now. The expiration times are
+// only scanned when the earliest one may be due.
+func (fe *fieldExpires) due(now int64) []string {
+	if fe.next == 0 || fe.next > now {
+		return nil
+	}
+
+	var fields []string
+	next := int64(0)
+	for field, expireAt := range fe.times {
+		if expireAt <= now {
+			fields = append(fields, field)
+		} else if next == 0 || expireAt < next {
+			next = expireAt
+		}
+	}
+	fe.next = next
+	return fields
+}
+
+func (fe *fieldExpires) size() int {
+	return len(fe.times)
+}
+
+// fieldExpireEntrySize returns memory for the expiration time of a field
+func fieldExpireEntrySize(field string) int64 {
+	return StringSize(field) + Int64Size + MapOverheadPerKey
+}
+
+// randomFieldsMaxPrealloc bounds the memory reserved upfront for a negative count, which
+// the client chooses freely.
+const randomFieldsMaxPrealloc = 1024
+
+// randomFields returns count fields of a hash of size fields, each followed by its value
+// with withValues, reading them through fieldAt. A negative count allows the same field
+// to be returned several times.
+func randomFields(size, count int, withValues bool, fieldAt func(i int) (string, string)) []string {
+	if count == 0 || size == 0 {
+		return []string{}
+	}
+
+	var indices []int
+	switch {
+	case count < 0:
+		indices = make([]int, 0, min(-count, randomFieldsMaxPrealloc))
+		for i := 0; i < -count; i++ {
+			indices = append(indices, rand.Intn(size))
+		}
+	case count >= size:
+		indices = make([]int, size)
+		for i := range indices {
+			indices[i] = i
+		}
+	default:
+		indices = make([]int, 0, count)
+		for i := range FloydSamplingIndices(size, count) {
+			indices = append(indices, i)
+		}
+	}
+
+	result := make([]string, 0, min(len(indices), randomFieldsMaxPrealloc)*2)
+	for _, i := range indices {
+		field, value := fieldAt(i)
+		result = append(result, field)
+		if withValues {
+			result = append(result, value)
+		}
+	}
+	return result
+}
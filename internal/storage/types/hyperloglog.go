@@ -20,6 +20,8 @@ type HyperLogLog interface {
 	PFCount(hyperLogLogs []HyperLogLog) int
 	PFMerge(hyperLogLogs []HyperLogLog) int64
 	MemoryUsage() int64
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary(data []byte) error
 }
 
 type hyperLogLog struct {
@@ -160,3 +162,28 @@ func (h *hyperLogLog) insert(item string) int {
 func (h *hyperLogLog) MemoryUsage() int64 {
 	return int64(size.Of(h))
 }
+
+// MarshalBinary encodes the registers; the cached count is recomputed after loading.
+func (h *hyperLogLog) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, hllM+4)
+	buf = appendUvarint(buf, hllM)
+	return append(buf, h.registers...), nil
+}
+
+func (h *hyperLogLog) UnmarshalBinary(data []byte) error {
+	r := &binaryReader{data: data}
+	if r.uvarint() != hllM {
+		return ErrCorruptEncoding
+	}
+
+	registers := r.bytes(hllM)
+	if err := r.finish(); err != nil {
+		return err
+	}
+
+	h.registers = make([]uint8, hllM)
+	copy(h.registers, registers)
+	h.cachedCount = 0
+	h.dirty = true
+	return nil
+}
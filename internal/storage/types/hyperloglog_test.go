@@ -564,3 +564,19 @@ func TestHyperLogLogOrderIndependent(t *testing.T) {
 
 	assert.Equal(t, count1, count2, "order of items should not affect count")
 }
+
+func TestHyperLogLogMarshalBinaryRoundTrip(t *testing.T) {
+	hll := NewHyperLogLog()
+	for i := 0; i < 1000; i++ {
+		hll.PFAdd([]string{fmt.Sprintf("item%d", i)})
+	}
+
+	data, err := hll.MarshalBinary()
+	require.NoError(t, err)
+
+	loaded := NewHyperLogLog()
+	require.NoError(t, loaded.UnmarshalBinary(data))
+	assert.Equal(t, hll.PFCount(nil), loaded.PFCount(nil))
+
+	require.Error(t, NewHyperLogLog().UnmarshalBinary(data[:len(data)-1]))
+}
@@ -105,6 +105,11 @@ func (set *intSet) Members() []string {
 	return result
 }
 
+// Scan returns every member at once, intsets are small enough not to need a cursor.
+func (set *intSet) Scan(cursor uint64, count int) (uint64, []string) {
+	return 0, set.Members()
+}
+
 func (set *intSet) Delete(members ...string) (int64, int64) {
 	removed := 0
 	for i := range members {
@@ -0,0 +1,79 @@
+package types
+
+// LCSMatch is a run of the longest common subsequence that is contiguous in both strings,
+// given by its first and last position in each of them.
+type LCSMatch struct {
+	AStart, AEnd int
+	BStart, BEnd int
+}
+
+func (m LCSMatch) Len() int {
+	return m.AEnd - m.AStart + 1
+}
+
+// LCSTableSize returns the number of bytes of the table LCS builds for strings of the given
+// lengths.
+func LCSTableSize(alen, blen int) uint64 {
+	return uint64(alen+1) * uint64(blen+1) * 4
+}
+
+// LCS returns the longest common subsequence of a and b, along with its runs that are
+// contiguous in both strings, from the last to the first.
+func LCS(a, b string) (string, []LCSMatch) {
+	alen, blen := len(a), len(b)
+
+	// table[i*(blen+1)+j] is the length of the longest common subsequence of a[:i] and b[:j]
+	table := make([]uint32, (alen+1)*(blen+1))
+	at := func(i, j int) uint32 { return table[i*(blen+1)+j] }
+	for i := 1; i <= alen; i++ {
+		for j := 1; j <= blen; j++ {
+			if a[i-1] == b[j-1] {
+				table[i*(blen+1)+j] = at(i-1, j-1) + 1
+			} else {
+				table[i*(blen+1)+j] = max(at(i-1, j), at(i, j-1))
+			}
+		}
+	}
+
+	// Walk the table back from the end, collecting the subsequence and its runs
+	idx := at(alen, blen)
+	result := make([]byte, idx)
+	var matches []LCSMatch
+	var current *LCSMatch
+
+	for i, j := alen, blen; i > 0 && j > 0; {
+		emit := false
+		if a[i-1] == b[j-1] {
+			result[idx-1] = a[i-1]
+			switch {
+			case current == nil:
+				current = &LCSMatch{AStart: i - 1, AEnd: i - 1, BStart: j - 1, BEnd: j - 1}
+			case current.AStart == i && current.BStart == j:
+				current.AStart--
+				current.BStart--
+			default:
+				emit = true
+			}
+			if current.AStart == 0 || current.BStart == 0 {
+				emit = true
+			}
+			idx--
+			i--
+			j--
+		} else {
+			if at(i-1, j) > at(i, j-1) {
+				i--
+			} else {
+				j--
+			}
+			emit = current != nil
+		}
+
+		if emit {
+			matches = append(matches, *current)
+			current = nil
+		}
+	}
+
+	return string(result), matches
+}
@@ -0,0 +1,34 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLCS(t *testing.T) {
+	lcs, matches := LCS("ohmytext", "mynewtext")
+
+	assert.Equal(t, "mytext", lcs)
+	assert.Equal(t, []LCSMatch{
+		{AStart: 4, AEnd: 7, BStart: 5, BEnd: 8},
+		{AStart: 2, AEnd: 3, BStart: 0, BEnd: 1},
+	}, matches)
+	assert.Equal(t, 4, matches[0].Len())
+}
+
+func TestLCS_NothingInCommon(t *testing.T) {
+	lcs, matches := LCS("abc", "xyz")
+	assert.Equal(t, "", lcs)
+	assert.Empty(t, matches)
+
+	lcs, matches = LCS("", "xyz")
+	assert.Equal(t, "", lcs)
+	assert.Empty(t, matches)
+}
+
+func TestLCS_IdenticalStrings(t *testing.T) {
+	lcs, matches := LCS("hello", "hello")
+	assert.Equal(t, "hello", lcs)
+	assert.Equal(t, []LCSMatch{{AStart: 0, AEnd: 4, BStart: 0, BEnd: 4}}, matches)
+}
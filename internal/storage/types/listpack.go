@@ -1,9 +1,8 @@
 package types
 
 const (
-	sliceHeaderSize      uint64 = 24
-	stringHeaderSize     uint64 = 16
-	listPackMaxSizeBytes uint64 = 8 * 1024 // 8KiB default
+	sliceHeaderSize  uint64 = 24
+	stringHeaderSize uint64 = 16
 )
 
 type listPack struct {
@@ -108,6 +107,15 @@ func (lp *listPack) approxSizeBytes() uint64 {
 	return totalSize
 }
 
+// elementsSize returns the memory accounted for the elements of the listpack.
+func (lp *listPack) elementsSize() int64 {
+	total := int64(0)
+	for _, s := range lp.data {
+		total += QuickListElementSize(s)
+	}
+	return total
+}
+
 func (lp *listPack) removeAt(index int32) {
 	if index < 0 || index >= int32(len(lp.data)) {
 		panic("removeAt called on invalid index of listPack")
@@ -119,6 +127,16 @@ func (lp *listPack) removeAt(index int32) {
 	lp.data = lp.data[:len(lp.data)-1]
 }
 
+func (lp *listPack) insertAt(index int32, value string) {
+	if index < 0 || index > int32(len(lp.data)) {
+		panic("insertAt called on invalid index of listPack")
+	}
+
+	lp.data = append(lp.data, "")
+	copy(lp.data[index+1:], lp.data[index:])
+	lp.data[index] = value
+}
+
 func (lp *listPack) set(index int32, value string) {
 	if index < 0 || index >= int32(len(lp.data)) {
 		panic("set called on invalid index of listPack")
@@ -0,0 +1,183 @@
+package types
+
+import (
+	"strconv"
+
+	"github.com/DmitriyVTitov/size"
+)
+
+// listPackHash keeps the fields of a small hash in a listpack, each field followed by its
+// value, in insertion order.
+type listPackHash struct {
+	lp      *listPack
+	expires fieldExpires
+}
+
+func NewListPackHash() Hash {
+	return &listPackHash{lp: newListPack()}
+}
+
+func (h *listPackHash) Get(key string) (string, bool) {
+	index := h.indexOf(key)
+	if index == -1 {
+		return "", false
+	}
+	return h.lp.get(index + 1), true
+}
+
+func (h *listPackHash) MGet(keys ...string) []*string {
+	result := make([]*string, len(keys))
+	for i, key := range keys {
+		if value, exists := h.Get(key); exists {
+			result[i] = &value
+		}
+	}
+	return result
+}
+
+func (h *listPackHash) GetAll() []string {
+	result := make([]string, len(h.lp.data))
+	copy(result, h.lp.data)
+	return result
+}
+
+func (h *listPackHash) GetKeys() []string {
+	result := make([]string, 0, h.Size())
+	for i := 0; i < len(h.lp.data); i += 2 {
+		result = append(result, h.lp.data[i])
+	}
+	return result
+}
+
+func (h *listPackHash) GetValues() []string {
+	result := make([]string, 0, h.Size())
+	for i := 1; i < len(h.lp.data); i += 2 {
+		result = append(result, h.lp.data[i])
+	}
+	return result
+}
+
+func (h *listPackHash) Size() uint32 {
+	return h.lp.size() / 2
+}
+
+func (h *listPackHash) IncBy(key string, increment int64) (int64, int64, error) {
+	index := h.indexOf(key)
+	if index == -1 {
+		newValue := strconv.FormatInt(increment, 10)
+		h.lp.rPush([]string{key, newValue})
+		return increment, StringSize(key) + StringSize(newValue), nil
+	}
+
+	oldValue := h.lp.get(index + 1)
+	valueInt, err := incrementValue(oldValue, increment)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	newValue := strconv.FormatInt(valueInt, 10)
+	h.lp.set(index+1, newValue)
+	return valueInt, StringSize(newValue) - StringSize(oldValue), nil
+}
+
+func (h *listPackHash) Set(fieldValue map[string]string) (int64, int64) {
+	added := int64(0)
+	delta := int64(0)
+	for key, value := range fieldValue {
+		if index := h.indexOf(key); index != -1 {
+			delta += StringSize(value) - StringSize(h.lp.get(index+1))
+			h.lp.set(index+1, value)
+			_, expireDelta := h.expires.remove(key)
+			delta += expireDelta
+			continue
+		}
+
+		h.lp.rPush([]string{key, value})
+		added++
+		delta += StringSize(key) + StringSize(value)
+	}
+
+	return added, delta
+}
+
+func (h *listPackHash) SetNX(key, value string) (bool, int64) {
+	if h.indexOf(key) != -1 {
+		return false, 0
+	}
+
+	h.lp.rPush([]string{key, value})
+	return true, StringSize(key) + StringSize(value)
+}
+
+func (h *listPackHash) Delete(keys ...string) (int64, int64) {
+	deleted := int64(0)
+	delta := int64(0)
+	for _, key := range keys {
+		index := h.indexOf(key)
+		if index == -1 {
+			continue
+		}
+
+		delta -= StringSize(key) + StringSize(h.lp.get(index+1))
+		_, expireDelta := h.expires.remove(key)
+		delta += expireDelta
+		h.lp.removeAt(index + 1)
+		h.lp.removeAt(index)
+		deleted++
+	}
+
+	return deleted, delta
+}
+
+func (h *listPackHash) Exists(key string) bool {
+	return h.indexOf(key) != -1
+}
+
+// Scan returns every field-value pair at once, listpacks are small enough not to need a
+// cursor.
+func (h *listPackHash) Scan(cursor uint64, count int) (uint64, []string) {
+	return 0, h.GetAll()
+}
+
+func (h *listPackHash) RandField(count int, withValues bool) []string {
+	return randomFields(int(h.Size()), count, withValues, func(i int) (string, string) {
+		return h.lp.data[2*i], h.lp.data[2*i+1]
+	})
+}
+
+func (h *listPackHash) FieldExpireTime(key string) (int64, bool) {
+	return h.expires.get(key)
+}
+
+func (h *listPackHash) SetFieldExpire(key string, expireAt int64) int64 {
+	if h.indexOf(key) == -1 {
+		return 0
+	}
+	return h.expires.set(key, expireAt)
+}
+
+func (h *listPackHash) PersistField(key string) (bool, int64) {
+	return h.expires.remove(key)
+}
+
+func (h *listPackHash) ExpireFields(now int64) (int64, int64) {
+	return h.Delete(h.expires.due(now)...)
+}
+
+func (h *listPackHash) VolatileSize() int {
+	return h.expires.size()
+}
+
+func (h *listPackHash) MemoryUsage() int64 {
+	return int64(size.Of(h))
+}
+
+// indexOf returns the position of the field key in the listpack, -1 when it is missing.
+func (h *listPackHash) indexOf(key string) int32 {
+	for i := 0; i < len(h.lp.data); i += 2 {
+		if h.lp.data[i] == key {
+			return int32(i)
+		}
+	}
+	return -1
+}
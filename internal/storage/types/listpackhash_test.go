@@ -0,0 +1,83 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListPackHash_SetGet(t *testing.T) {
+	h := NewListPackHash()
+
+	added, delta := h.Set(map[string]string{"name": "Alice"})
+	assert.Equal(t, int64(1), added)
+	assert.Equal(t, StringSize("name")+StringSize("Alice"), delta)
+
+	added, delta = h.Set(map[string]string{"name": "Bob", "age": "30"})
+	assert.Equal(t, int64(1), added)
+	assert.Equal(t, StringSize("Bob")-StringSize("Alice")+StringSize("age")+StringSize("30"), delta)
+
+	value, ok := h.Get("name")
+	assert.True(t, ok)
+	assert.Equal(t, "Bob", value)
+	_, ok = h.Get("missing")
+	assert.False(t, ok)
+
+	assert.Equal(t, uint32(2), h.Size())
+	assert.ElementsMatch(t, []string{"name", "age"}, h.GetKeys())
+	assert.ElementsMatch(t, []string{"Bob", "30"}, h.GetValues())
+	assert.Len(t, h.GetAll(), 4)
+
+	values := h.MGet("age", "missing")
+	require.NotNil(t, values[0])
+	assert.Equal(t, "30", *values[0])
+	assert.Nil(t, values[1])
+}
+
+func TestListPackHash_SetNXDelete(t *testing.T) {
+	h := NewListPackHash()
+
+	set, _ := h.SetNX("a", "1")
+	assert.True(t, set)
+	set, delta := h.SetNX("a", "2")
+	assert.False(t, set)
+	assert.Equal(t, int64(0), delta)
+
+	h.SetNX("b", "2")
+	deleted, delta := h.Delete("a", "missing")
+	assert.Equal(t, int64(1), deleted)
+	assert.Equal(t, -StringSize("a")-StringSize("1"), delta)
+	assert.False(t, h.Exists("a"))
+	assert.Equal(t, []string{"b", "2"}, h.GetAll())
+}
+
+func TestListPackHash_IncBy(t *testing.T) {
+	h := NewListPackHash()
+
+	value, _, err := h.IncBy("counter", 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), value)
+
+	value, delta, err := h.IncBy("counter", 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(15), value)
+	assert.Equal(t, int64(1), delta)
+
+	h.Set(map[string]string{"name": "Alice"})
+	_, _, err = h.IncBy("name", 1)
+	assert.Error(t, err)
+
+	h.Set(map[string]string{"max": "9223372036854775807"})
+	_, _, err = h.IncBy("max", 1)
+	assert.Error(t, err)
+}
+
+func TestListPackHash_Scan(t *testing.T) {
+	h := NewListPackHash()
+	h.Set(map[string]string{"a": "1", "b": "2"})
+
+	cursor, pairs := h.Scan(0, 1)
+	assert.Equal(t, uint64(0), cursor)
+	assert.Len(t, pairs, 4)
+}
@@ -0,0 +1,80 @@
+package types
+
+import "github.com/DmitriyVTitov/size"
+
+// listPackSet keeps the members of a small set in a listpack, in insertion order. Lookups
+// scan the whole listpack, which stays cheap for the few members it is used for and saves
+// the overhead of a hash table per member.
+type listPackSet struct {
+	lp *listPack
+}
+
+func NewListPackSet() Set {
+	return &listPackSet{lp: newListPack()}
+}
+
+func (set *listPackSet) Add(members ...string) (int64, bool, int64) {
+	var added int64 = 0
+	var delta int64 = 0
+	for _, member := range members {
+		if set.indexOf(member) == -1 {
+			set.lp.rPush([]string{member})
+			added++
+			delta += StringSize(member)
+		}
+	}
+	return added, true, delta
+}
+
+func (set *listPackSet) Delete(members ...string) (int64, int64) {
+	var removed int64 = 0
+	var delta int64 = 0
+	for _, member := range members {
+		if index := set.indexOf(member); index != -1 {
+			set.lp.removeAt(index)
+			removed++
+			delta -= StringSize(member)
+		}
+	}
+	return removed, delta
+}
+
+func (set *listPackSet) IsMember(member string) bool {
+	return set.indexOf(member) != -1
+}
+
+func (set *listPackSet) MIsMember(members ...string) []bool {
+	result := make([]bool, len(members))
+	for i, member := range members {
+		result[i] = set.IsMember(member)
+	}
+	return result
+}
+
+func (set *listPackSet) Members() []string {
+	result := make([]string, len(set.lp.data))
+	copy(result, set.lp.data)
+	return result
+}
+
+// Scan returns every member at once, listpacks are small enough not to need a cursor.
+func (set *listPackSet) Scan(cursor uint64, count int) (uint64, []string) {
+	return 0, set.Members()
+}
+
+func (set *listPackSet) Size() int64 {
+	return int64(set.lp.size())
+}
+
+func (set *listPackSet) MemoryUsage() int64 {
+	return int64(size.Of(set))
+}
+
+func (set *listPackSet) indexOf(member string) int32 {
+	for i, m := range set.lp.data {
+		if m == member {
+			return int32(i)
+		}
+	}
+	return -1
+}
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListPackSet_AddDelete(t *testing.T) {
+	s := NewListPackSet()
+
+	added, ok, delta := s.Add("a", "b", "a", "c")
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), added)
+	assert.Equal(t, 3*StringSize("a"), delta)
+	assert.Equal(t, []string{"a", "b", "c"}, s.Members())
+
+	removed, delta := s.Delete("b", "missing", "b")
+	assert.Equal(t, int64(1), removed)
+	assert.Equal(t, -StringSize("b"), delta)
+	assert.Equal(t, []string{"a", "c"}, s.Members())
+	assert.Equal(t, int64(2), s.Size())
+}
+
+func TestListPackSet_Lookups(t *testing.T) {
+	s := NewListPackSet()
+	s.Add("a", "1")
+
+	assert.True(t, s.IsMember("1"))
+	assert.False(t, s.IsMember("b"))
+	assert.Equal(t, []bool{true, false, true}, s.MIsMember("a", "b", "1"))
+
+	cursor, members := s.Scan(0, 1)
+	assert.Equal(t, uint64(0), cursor)
+	assert.ElementsMatch(t, []string{"a", "1"}, members)
+}
+
+func TestListPackSet_MembersIsACopy(t *testing.T) {
+	s := NewListPackSet()
+	s.Add("a")
+
+	members := s.Members()
+	members[0] = "changed"
+	assert.True(t, s.IsMember("a"))
+}
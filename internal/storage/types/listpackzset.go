@@ -0,0 +1,447 @@
+package types
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/DmitriyVTitov/size"
+)
+
+type listPackZSetEntry struct {
+	member string
+	score  float64
+}
+
+// listPackZSet keeps the members of a small sorted set in an array ordered by score, then
+// by member, like the skiplist of zSet. Ranges are binary searched or scanned in order, and
+// lookups by member scan the whole array, which stays cheap for the few members it is used
+// for and saves the skiplist node and the map entry of every member.
+type listPackZSet struct {
+	entries []listPackZSetEntry
+}
+
+func NewListPackZSet() ZSet {
+	return &listPackZSet{entries: make([]listPackZSetEntry, 0)}
+}
+
+func (zset *listPackZSet) ZAdd(scoreMember map[string]float64, options ZAddOptions) (*uint32, int64) {
+	if !options.valid() {
+		return nil, 0
+	}
+
+	result := uint32(0)
+	delta := int64(0)
+	for member, newScore := range scoreMember {
+		index := zset.indexOf(member)
+		exists := index != -1
+
+		if options.NX && exists {
+			continue
+		}
+		if options.XX && !exists {
+			continue
+		}
+
+		if exists {
+			oldScore := zset.entries[index].score
+			if options.GT && newScore <= oldScore {
+				continue
+			}
+			if options.LT && newScore >= oldScore {
+				continue
+			}
+
+			if oldScore != newScore {
+				zset.removeAt(index)
+				zset.insert(member, newScore)
+				if options.CH {
+					result++
+				}
+			}
+			continue
+		}
+
+		zset.insert(member, newScore)
+		delta += listPackZSetEntrySize(member)
+		result++
+	}
+
+	return &result, delta
+}
+
+func (zset *listPackZSet) ZCard() uint32 {
+	return uint32(len(zset.entries))
+}
+
+func (zset *listPackZSet) ZCount(r ZScoreRange) uint32 {
+	from, to := zset.scoreRange(r)
+	return uint32(to - from)
+}
+
+func (zset *listPackZSet) ZIncrBy(member string, increment float64) (float64, bool, int64) {
+	index := zset.indexOf(member)
+	if index == -1 {
+		zset.insert(member, increment)
+		return increment, true, listPackZSetEntrySize(member)
+	}
+
+	newScore := zset.entries[index].score + increment
+	if math.IsInf(newScore, 0) || math.IsNaN(newScore) {
+		return 0, false, 0
+	}
+
+	zset.removeAt(index)
+	zset.insert(member, newScore)
+	return newScore, true, 0
+}
+
+// ZLexCount counts the members within the lex range r. Like the skiplist, it expects every
+// member to have the same score.
+func (zset *listPackZSet) ZLexCount(r ZLexRange) uint32 {
+	from, to := zset.lexRange(r)
+	return uint32(to - from)
+}
+
+func (zset *listPackZSet) ZMScore(members []string) []*float64 {
+	result := make([]*float64, len(members))
+	for i, member := range members {
+		result[i] = zset.ZScore(member)
+	}
+	return result
+}
+
+func (zset *listPackZSet) ZPopMax(count int) ([]string, int64) {
+	count = min(count, len(zset.entries))
+	if count <= 0 {
+		return []string{}, 0
+	}
+
+	result := make([]string, 0, count*2)
+	delta := int64(0)
+	for i := len(zset.entries) - 1; i >= len(zset.entries)-count; i-- {
+		entry := zset.entries[i]
+		result = append(result, entry.member, formatFloat(entry.score))
+		delta -= listPackZSetEntrySize(entry.member)
+	}
+
+	zset.entries = zset.entries[:len(zset.entries)-count]
+	return result, delta
+}
+
+func (zset *listPackZSet) ZPopMin(count int) ([]string, int64) {
+	count = min(count, len(zset.entries))
+	if count <= 0 {
+		return []string{}, 0
+	}
+
+	result := make([]string, 0, count*2)
+	delta := int64(0)
+	for _, entry := range zset.entries[:count] {
+		result = append(result, entry.member, formatFloat(entry.score))
+		delta -= listPackZSetEntrySize(entry.member)
+	}
+
+	zset.entries = append(zset.entries[:0], zset.entries[count:]...)
+	return result, delta
+}
+
+func (zset *listPackZSet) ZRandMember(count int, withScores bool) []string {
+	if count == 0 || len(zset.entries) == 0 {
+		return []string{}
+	}
+
+	if count > 0 {
+		if count >= len(zset.entries) {
+			return entriesToStringSlice(zset.entries, withScores)
+		}
+
+		indices := FloydSamplingIndices(len(zset.entries), count)
+		selected := make([]listPackZSetEntry, 0, count)
+		for i, entry := range zset.entries {
+			if _, ok := indices[i]; ok {
+				selected = append(selected, entry)
+			}
+		}
+		return entriesToStringSlice(selected, withScores)
+	}
+
+	selected := make([]listPackZSetEntry, -count)
+	for i := range selected {
+		selected[i] = zset.entries[rand.Intn(len(zset.entries))]
+	}
+	return entriesToStringSlice(selected, withScores)
+}
+
+func (zset *listPackZSet) ZRangeByRank(start, stop int, withScores bool) []string {
+	length := len(zset.entries)
+	if length == 0 {
+		return []string{}
+	}
+
+	if start < 0 {
+		start = max(length+start, 0)
+	}
+	if stop < 0 {
+		stop = length + stop
+		if stop < 0 {
+			return []string{}
+		}
+	}
+	if start >= length || start > stop {
+		return []string{}
+	}
+
+	stop = min(stop, length-1)
+	return entriesToStringSlice(zset.entries[start:stop+1], withScores)
+}
+
+// ZRangeByLex returns up to count of the members within the lex range r after the first
+// offset. Like the skiplist, it expects every member to have the same score.
+func (zset *listPackZSet) ZRangeByLex(r ZLexRange, offset, count int, withScores bool) []string {
+	from, to := zset.lexRange(r)
+	return entriesToStringSlice(limitEntries(zset.entries[from:to], offset, count, false), withScores)
+}
+
+func (zset *listPackZSet) ZRangeByScore(r ZScoreRange, offset, count int, withScores bool) []string {
+	from, to := zset.scoreRange(r)
+	return entriesToStringSlice(limitEntries(zset.entries[from:to], offset, count, false), withScores)
+}
+
+func (zset *listPackZSet) ZRevRangeByRank(start, stop int, withScores bool) []string {
+	length := len(zset.entries)
+	if length == 0 {
+		return []string{}
+	}
+
+	if start < 0 {
+		start = length + start
+	}
+	if stop < 0 {
+		stop = length + stop
+	}
+	if start < 0 || stop < 0 || start > stop || start >= length {
+		return []string{}
+	}
+
+	stop = min(stop, length-1)
+	return reversedEntriesToStringSlice(zset.entries[length-1-stop:length-start], withScores)
+}
+
+// ZRevRangeByLex returns up to count of the members within the lex range r, from its
+// maximum down after the first offset. Like the skiplist, it expects every member to have
+// the same score.
+func (zset *listPackZSet) ZRevRangeByLex(r ZLexRange, offset, count int, withScores bool) []string {
+	from, to := zset.lexRange(r)
+	return reversedEntriesToStringSlice(limitEntries(zset.entries[from:to], offset, count, true), withScores)
+}
+
+func (zset *listPackZSet) ZRevRangeByScore(r ZScoreRange, offset, count int, withScores bool) []string {
+	from, to := zset.scoreRange(r)
+	return reversedEntriesToStringSlice(limitEntries(zset.entries[from:to], offset, count, true), withScores)
+}
+
+func (zset *listPackZSet) ZRank(member string, withScore bool) []any {
+	index := zset.indexOf(member)
+	if index == -1 {
+		return nil
+	}
+
+	if withScore {
+		return []any{index, formatFloat(zset.entries[index].score)}
+	}
+	return []any{index}
+}
+
+func (zset *listPackZSet) ZRem(members []string) (int, int64) {
+	removed := 0
+	delta := int64(0)
+	for _, member := range members {
+		if index := zset.indexOf(member); index != -1 {
+			zset.removeAt(index)
+			removed++
+			delta -= listPackZSetEntrySize(member)
+		}
+	}
+	return removed, delta
+}
+
+func (zset *listPackZSet) ZRemRangeByRank(start, stop int) (int, int64) {
+	return zset.ZRem(zset.ZRangeByRank(start, stop, false))
+}
+
+func (zset *listPackZSet) ZRemRangeByScore(r ZScoreRange) (int, int64) {
+	return zset.ZRem(zset.ZRangeByScore(r, 0, -1, false))
+}
+
+func (zset *listPackZSet) ZRemRangeByLex(r ZLexRange) (int, int64) {
+	return zset.ZRem(zset.ZRangeByLex(r, 0, -1, false))
+}
+
+func (zset *listPackZSet) ZRevRank(member string, withScore bool) []any {
+	index := zset.indexOf(member)
+	if index == -1 {
+		return nil
+	}
+
+	rank := len(zset.entries) - 1 - index
+	if withScore {
+		return []any{rank, formatFloat(zset.entries[index].score)}
+	}
+	return []any{rank}
+}
+
+func (zset *listPackZSet) ZScore(member string) *float64 {
+	index := zset.indexOf(member)
+	if index == -1 {
+		return nil
+	}
+
+	score := zset.entries[index].score
+	return &score
+}
+
+// ZScan returns every member-score pair at once, listpacks are small enough not to need a
+// cursor.
+func (zset *listPackZSet) ZScan(cursor uint64, count int) (uint64, []string) {
+	return 0, entriesToStringSlice(zset.entries, true)
+}
+
+func (zset *listPackZSet) GeoAdd(items []GeoPoint, options ZAddOptions) (*uint32, int64) {
+	return geoAdd(zset, items, options)
+}
+
+func (zset *listPackZSet) GeoDist(member1, member2 string, unit string) *float64 {
+	return geoDist(zset, member1, member2, unit)
+}
+
+func (zset *listPackZSet) GeoHash(members []string) []*string {
+	return geoHash(zset, members)
+}
+
+func (zset *listPackZSet) GeoPos(members []string) []*GeoPoint {
+	return geoPos(zset, members)
+}
+
+func (zset *listPackZSet) GeoSearch(options GeoSearchOptions) []GeoResult {
+	return geoSearch(zset, options)
+}
+
+func (zset *listPackZSet) MemoryUsage() int64 {
+	return int64(size.Of(zset))
+}
+
+func (zset *listPackZSet) forEach(fn func(member string, score float64)) {
+	for _, entry := range zset.entries {
+		fn(entry.member, entry.score)
+	}
+}
+
+func (zset *listPackZSet) indexOf(member string) int {
+	for i, entry := range zset.entries {
+		if entry.member == member {
+			return i
+		}
+	}
+	return -1
+}
+
+// insert adds a member that is not in the sorted set yet at its position.
+func (zset *listPackZSet) insert(member string, score float64) {
+	index := sort.Search(len(zset.entries), func(i int) bool {
+		entry := zset.entries[i]
+		return entry.score > score || entry.score == score && entry.member > member
+	})
+
+	zset.entries = append(zset.entries, listPackZSetEntry{})
+	copy(zset.entries[index+1:], zset.entries[index:])
+	zset.entries[index] = listPackZSetEntry{member: member, score: score}
+}
+
+func (zset *listPackZSet) removeAt(index int) {
+	copy(zset.entries[index:], zset.entries[index+1:])
+	zset.entries = zset.entries[:len(zset.entries)-1]
+}
+
+// rankByScore returns the number of members with a score lower than score, or lower or
+// equal when inclusive.
+func (zset *listPackZSet) rankByScore(score float64, inclusive bool) int {
+	return sort.Search(len(zset.entries), func(i int) bool {
+		if inclusive {
+			return zset.entries[i].score > score
+		}
+		return zset.entries[i].score >= score
+	})
+}
+
+// scoreRange returns the positions of the first member within r and past the last one.
+func (zset *listPackZSet) scoreRange(r ZScoreRange) (int, int) {
+	if r.empty() {
+		return 0, 0
+	}
+
+	from := zset.rankByScore(r.Min, r.MinEx)
+	to := max(from, zset.rankByScore(r.Max, !r.MaxEx))
+	return from, to
+}
+
+// lexRange returns the positions of the first member within r and past the last one.
+func (zset *listPackZSet) lexRange(r ZLexRange) (int, int) {
+	if r.empty() {
+		return 0, 0
+	}
+
+	from := 0
+	for from < len(zset.entries) && !r.aboveMin(zset.entries[from].member) {
+		from++
+	}
+	to := from
+	for to < len(zset.entries) && r.belowMax(zset.entries[to].member) {
+		to++
+	}
+	return from, to
+}
+
+// listPackZSetEntrySize returns memory for a member and its score in a listpack
+func listPackZSetEntrySize(member string) int64 {
+	return StringSize(member) + Float64Size
+}
+
+// limitEntries returns up to count entries after the first offset, counted from the last
+// entry when rev. A negative count keeps every entry after offset.
+func limitEntries(entries []listPackZSetEntry, offset, count int, rev bool) []listPackZSetEntry {
+	if offset < 0 || offset >= len(entries) {
+		return nil
+	}
+
+	n := len(entries) - offset
+	if count >= 0 {
+		n = min(n, count)
+	}
+	if rev {
+		return entries[len(entries)-offset-n : len(entries)-offset]
+	}
+	return entries[offset : offset+n]
+}
+
+func entriesToStringSlice(entries []listPackZSetEntry, withScores bool) []string {
+	result := make([]string, 0, len(entries)*2)
+	for _, entry := range entries {
+		result = append(result, entry.member)
+		if withScores {
+			result = append(result, formatFloat(entry.score))
+		}
+	}
+	return result
+}
+
+func reversedEntriesToStringSlice(entries []listPackZSetEntry, withScores bool) []string {
+	result := make([]string, 0, len(entries)*2)
+	for i := len(entries) - 1; i >= 0; i-- {
+		result = append(result, entries[i].member)
+		if withScores {
+			result = append(result, formatFloat(entries[i].score))
+		}
+	}
+	return result
+}
@@ -0,0 +1,198 @@
+package types
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newZSetPair returns a skiplist and a listpack encoded sorted set with the same members.
+func newZSetPair(scoreMember map[string]float64) (ZSet, ZSet) {
+	skipList, listPack := NewZSet(), NewListPackZSet()
+	skipList.ZAdd(scoreMember, ZAddOptions{})
+	listPack.ZAdd(scoreMember, ZAddOptions{})
+	return skipList, listPack
+}
+
+func TestListPackZSet_RangesMatchSkipList(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	scoreMember := make(map[string]float64)
+	for i := 0; i < 40; i++ {
+		scoreMember["m"+strconv.Itoa(i)] = float64(rng.Intn(10))
+	}
+	skipList, listPack := newZSetPair(scoreMember)
+
+	for start := -45; start <= 45; start += 5 {
+		for stop := -45; stop <= 45; stop += 5 {
+			assert.Equal(t, skipList.ZRangeByRank(start, stop, true), listPack.ZRangeByRank(start, stop, true), "rank %d %d", start, stop)
+			assert.Equal(t, skipList.ZRevRangeByRank(start, stop, false), listPack.ZRevRangeByRank(start, stop, false), "rev rank %d %d", start, stop)
+		}
+	}
+
+	bounds := []float64{math.Inf(-1), -1, 0, 2.5, 3, 7, 9, 12, math.Inf(1)}
+	for _, min := range bounds {
+		for _, max := range bounds {
+			assert.ElementsMatch(t, skipList.ZRangeByScore(ZScoreRange{Min: min, Max: max}, 0, -1, true), listPack.ZRangeByScore(ZScoreRange{Min: min, Max: max}, 0, -1, true), "score %v %v", min, max)
+			assert.ElementsMatch(t, skipList.ZRevRangeByScore(ZScoreRange{Min: min, Max: max}, 0, -1, true), listPack.ZRevRangeByScore(ZScoreRange{Min: min, Max: max}, 0, -1, true), "rev score %v %v", max, min)
+			assert.Equal(t, skipList.ZCount(ZScoreRange{Min: min, Max: max}), listPack.ZCount(ZScoreRange{Min: min, Max: max}), "count %v %v", min, max)
+		}
+	}
+
+	for member := range scoreMember {
+		assert.Equal(t, skipList.ZRank(member, true), listPack.ZRank(member, true))
+		assert.Equal(t, skipList.ZRevRank(member, false), listPack.ZRevRank(member, false))
+		assert.Equal(t, skipList.ZScore(member), listPack.ZScore(member))
+	}
+	assert.Nil(t, listPack.ZRank("missing", false))
+}
+
+func TestListPackZSet_LexMatchesSkipList(t *testing.T) {
+	skipList, listPack := newZSetPair(map[string]float64{"a": 0, "b": 0, "c": 0, "d": 0, "e": 0, "f": 0, "g": 0})
+
+	bounds := []string{"", "a", "aa", "c", "d", "g", "z", "~"}
+	for _, min := range bounds {
+		for _, max := range bounds {
+			assert.Equal(t, skipList.ZRangeByLex(lexRangeOf(min, max), 0, -1, false), listPack.ZRangeByLex(lexRangeOf(min, max), 0, -1, false), "lex %q %q", min, max)
+			assert.Equal(t, skipList.ZRevRangeByLex(lexRangeOf(min, max), 0, -1, false), listPack.ZRevRangeByLex(lexRangeOf(min, max), 0, -1, false), "rev lex %q %q", max, min)
+			assert.Equal(t, skipList.ZLexCount(lexRangeOf(min, max)), listPack.ZLexCount(lexRangeOf(min, max)), "lex count %q %q", min, max)
+		}
+	}
+}
+
+func TestListPackZSet_LimitAndExclusiveMatchSkipList(t *testing.T) {
+	scoreMember := make(map[string]float64)
+	for i := 0; i < 20; i++ {
+		scoreMember["m"+strconv.Itoa(i)] = float64(i % 5)
+	}
+	skipList, listPack := newZSetPair(scoreMember)
+
+	ranges := []ZScoreRange{
+		{Min: 1, Max: 3, MinEx: true},
+		{Min: 1, Max: 3, MaxEx: true},
+		{Min: 1, Max: 3, MinEx: true, MaxEx: true},
+		{Min: 2, Max: 2, MinEx: true},
+		{Min: math.Inf(-1), Max: math.Inf(1), MinEx: true, MaxEx: true},
+	}
+	for _, r := range ranges {
+		assert.Equal(t, skipList.ZCount(r), listPack.ZCount(r), "count %+v", r)
+		for offset := 0; offset <= 20; offset += 3 {
+			for _, count := range []int{-1, 0, 1, 4, 30} {
+				assert.Equal(t, skipList.ZRangeByScore(r, offset, count, true), listPack.ZRangeByScore(r, offset, count, true), "score %+v %d %d", r, offset, count)
+				assert.Equal(t, skipList.ZRevRangeByScore(r, offset, count, true), listPack.ZRevRangeByScore(r, offset, count, true), "rev score %+v %d %d", r, offset, count)
+			}
+		}
+	}
+
+	skipList, listPack = newZSetPair(map[string]float64{"a": 0, "b": 0, "c": 0, "d": 0, "e": 0})
+	lexRanges := []ZLexRange{
+		{Min: ZLexBound{Inf: -1}, Max: ZLexBound{Inf: 1}},
+		{Min: ZLexBound{Value: "b", Exclusive: true}, Max: ZLexBound{Value: "d"}},
+		{Min: ZLexBound{Value: "b"}, Max: ZLexBound{Value: "d", Exclusive: true}},
+		{Min: ZLexBound{Value: "c", Exclusive: true}, Max: ZLexBound{Value: "c"}},
+		{Min: ZLexBound{Inf: 1}, Max: ZLexBound{Inf: 1}},
+		{Min: ZLexBound{Inf: -1}, Max: ZLexBound{Inf: -1}},
+	}
+	for _, r := range lexRanges {
+		assert.Equal(t, skipList.ZLexCount(r), listPack.ZLexCount(r), "lex count %+v", r)
+		for offset := 0; offset <= 5; offset++ {
+			for _, count := range []int{-1, 0, 2, 30} {
+				assert.Equal(t, skipList.ZRangeByLex(r, offset, count, false), listPack.ZRangeByLex(r, offset, count, false), "lex %+v %d %d", r, offset, count)
+				assert.Equal(t, skipList.ZRevRangeByLex(r, offset, count, false), listPack.ZRevRangeByLex(r, offset, count, false), "rev lex %+v %d %d", r, offset, count)
+			}
+		}
+	}
+}
+
+func TestListPackZSet_ZRemRange(t *testing.T) {
+	for _, z := range []ZSet{NewZSet(), NewListPackZSet()} {
+		z.ZAdd(map[string]float64{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5}, ZAddOptions{})
+
+		removed, _ := z.ZRemRangeByRank(0, 1)
+		assert.Equal(t, 2, removed)
+		removed, _ = z.ZRemRangeByScore(ZScoreRange{Min: 3, Max: 5, MinEx: true})
+		assert.Equal(t, 2, removed)
+		removed, _ = z.ZRemRangeByLex(lexRangeOf("z", "~"))
+		assert.Equal(t, 0, removed)
+		assert.Equal(t, []string{"c"}, z.ZRangeByRank(0, -1, false))
+	}
+}
+
+func TestListPackZSet_ZAddOptions(t *testing.T) {
+	z := NewListPackZSet()
+	z.ZAdd(map[string]float64{"a": 1, "b": 2}, ZAddOptions{})
+
+	result, _ := z.ZAdd(map[string]float64{"a": 5, "c": 3}, ZAddOptions{NX: true})
+	assert.Equal(t, uint32(1), *result)
+	assert.Equal(t, 1.0, *z.ZScore("a"))
+
+	result, _ = z.ZAdd(map[string]float64{"a": 5, "d": 3}, ZAddOptions{XX: true, CH: true})
+	assert.Equal(t, uint32(1), *result)
+	assert.Nil(t, z.ZScore("d"))
+
+	z.ZAdd(map[string]float64{"a": 4, "b": 3}, ZAddOptions{GT: true})
+	assert.Equal(t, 5.0, *z.ZScore("a"))
+	assert.Equal(t, 3.0, *z.ZScore("b"))
+
+	result, _ = z.ZAdd(map[string]float64{"a": 1}, ZAddOptions{NX: true, GT: true})
+	assert.Nil(t, result)
+
+	assert.Equal(t, []string{"b", "c", "a"}, z.ZRangeByRank(0, -1, false))
+}
+
+func TestListPackZSet_IncrByPopRem(t *testing.T) {
+	z := NewListPackZSet()
+	z.ZAdd(map[string]float64{"a": 1, "b": 2, "c": 3, "d": 4}, ZAddOptions{})
+
+	score, ok, _ := z.ZIncrBy("a", 10)
+	require.True(t, ok)
+	assert.Equal(t, 11.0, score)
+	_, ok, _ = z.ZIncrBy("a", math.Inf(1))
+	assert.False(t, ok)
+
+	popped, delta := z.ZPopMin(2)
+	assert.Equal(t, []string{"b", "2", "c", "3"}, popped)
+	assert.Equal(t, -2*listPackZSetEntrySize("b"), delta)
+
+	popped, _ = z.ZPopMax(1)
+	assert.Equal(t, []string{"a", "11"}, popped)
+
+	removed, _ := z.ZRem([]string{"d", "missing"})
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, uint32(0), z.ZCard())
+
+	popped, _ = z.ZPopMax(1)
+	assert.Empty(t, popped)
+}
+
+func TestListPackZSet_RandMemberAndScan(t *testing.T) {
+	z := NewListPackZSet()
+	z.ZAdd(map[string]float64{"a": 1, "b": 2, "c": 3}, ZAddOptions{})
+
+	assert.Len(t, z.ZRandMember(2, false), 2)
+	assert.Len(t, z.ZRandMember(5, true), 6)
+	assert.Len(t, z.ZRandMember(-5, false), 5)
+
+	cursor, pairs := z.ZScan(0, 1)
+	assert.Equal(t, uint64(0), cursor)
+	assert.Equal(t, []string{"a", "1", "b", "2", "c", "3"}, pairs)
+}
+
+func TestListPackZSet_Geo(t *testing.T) {
+	skipList, listPack := NewZSet(), NewListPackZSet()
+	items := []GeoPoint{
+		{Longitude: 13.361389, Latitude: 38.115556, Member: "Palermo"},
+		{Longitude: 15.087269, Latitude: 37.502669, Member: "Catania"},
+	}
+	skipList.GeoAdd(items, ZAddOptions{})
+	listPack.GeoAdd(items, ZAddOptions{})
+
+	assert.Equal(t, *skipList.GeoDist("Palermo", "Catania", "km"), *listPack.GeoDist("Palermo", "Catania", "km"))
+	assert.Equal(t, skipList.GeoHash([]string{"Palermo", "missing"}), listPack.GeoHash([]string{"Palermo", "missing"}))
+
+	options := GeoSearchOptions{FromLonLat: &GeoPoint{Longitude: 15, Latitude: 37}, ByRadius: 200, Unit: "km"}
+	assert.Equal(t, skipList.GeoSearch(options), listPack.GeoSearch(options))
+}
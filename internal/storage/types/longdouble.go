@@ -0,0 +1,46 @@
+package types
+
+import (
+	"math"
+	"math/big"
+	"strings"
+)
+
+// longDoublePrec is the mantissa size of the x87 long double Redis computes INCRBYFLOAT with.
+const longDoublePrec = 64
+
+// longDoubleMax is LDBL_MAX, (2^64 - 1) * 2^16320, the largest finite x87 long double.
+var longDoubleMax = new(big.Float).SetMantExp(new(big.Float).SetUint64(math.MaxUint64), 16320)
+
+// ParseLongDouble parses a decimal floating point number with the precision of a long double.
+// Like strtold, finite numbers beyond the range of a long double are rejected.
+func ParseLongDouble(s string) (*big.Float, bool) {
+	if s == "" || strings.TrimSpace(s) != s {
+		return nil, false
+	}
+
+	f, _, err := big.ParseFloat(s, 10, longDoublePrec, big.ToNearestEven)
+	if err != nil || (!f.IsInf() && !InLongDoubleRange(f)) {
+		return nil, false
+	}
+	return f, true
+}
+
+// InLongDoubleRange reports whether f is finite and no larger in magnitude than LDBL_MAX.
+func InLongDoubleRange(f *big.Float) bool {
+	return !f.IsInf() && new(big.Float).Abs(f).Cmp(longDoubleMax) <= 0
+}
+
+// FormatLongDouble formats f the way Redis replies to INCRBYFLOAT: with 17 decimals,
+// trailing zeros removed, and never in exponent notation.
+func FormatLongDouble(f *big.Float) string {
+	s := f.Text('f', 17)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
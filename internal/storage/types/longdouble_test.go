@@ -0,0 +1,44 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatLongDouble(t *testing.T) {
+	tests := map[string]string{
+		"10.5":   "10.5",
+		"5.0e3":  "5000",
+		"-0":     "0",
+		"3":      "3",
+		"1e-20":  "0",
+		"0.125":  "0.125",
+		"-2.500": "-2.5",
+	}
+
+	for input, expected := range tests {
+		f, ok := ParseLongDouble(input)
+		assert.True(t, ok, input)
+		assert.Equal(t, expected, FormatLongDouble(f), input)
+	}
+}
+
+func TestParseLongDouble_Range(t *testing.T) {
+	for _, input := range []string{"1.1e4932", "-1.1e4932", "1e-5000", "inf", "-inf"} {
+		_, ok := ParseLongDouble(input)
+		assert.True(t, ok, input)
+	}
+
+	for _, input := range []string{"1.2e4932", "-1.2e4932", "1e5000", "1e10000000"} {
+		_, ok := ParseLongDouble(input)
+		assert.False(t, ok, input)
+	}
+}
+
+func TestParseLongDouble_Invalid(t *testing.T) {
+	for _, input := range []string{"", "abc", " 1", "1 ", "0x10", "1_000", "1.2.3"} {
+		_, ok := ParseLongDouble(input)
+		assert.False(t, ok, input)
+	}
+}
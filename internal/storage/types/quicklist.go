@@ -1,11 +1,32 @@
 package types
 
 import (
+	"bytes"
+	"compress/flate"
 	"errors"
+	"io"
+	"sync"
 
 	"github.com/DmitriyVTitov/size"
 )
 
+const (
+	// Nodes smaller than this are not worth compressing
+	minCompressBytes uint64 = 48
+	// Compression must save at least this many bytes to be kept
+	minCompressGain = 8
+)
+
+// listPackSizeLimits are the node sizes of the negative fill values, from -1 to -5.
+var listPackSizeLimits = [...]uint64{4 * 1024, 8 * 1024, 16 * 1024, 32 * 1024, 64 * 1024}
+
+var flateWriters = sync.Pool{
+	New: func() any {
+		w, _ := flate.NewWriter(nil, flate.BestSpeed)
+		return w
+	},
+}
+
 type QuickList interface {
 	Size() uint32
 
@@ -18,22 +39,33 @@ type QuickList interface {
 	LRem(count int32, element string) (uint32, int64)
 	LSet(index int32, element string) (error, int64)
 	LTrim(start, end int32) int64
+	LInsert(pivot, element string, before bool) (bool, int64)
+	LPos(element string, rank int64, count, maxLen uint32) []uint32
 	MemoryUsage() int64
 }
 
 type quickList struct {
-	head *quickListNode // sentinel node
-	tail *quickListNode // sentinel node
-	size uint32
+	head          *quickListNode // sentinel node
+	tail          *quickListNode // sentinel node
+	size          uint32
+	fill          int // node size limit, see NewQuickList
+	compressDepth int // number of nodes at each end that are never compressed, 0 disables compression
 }
 
 type quickListNode struct {
 	next     *quickListNode
 	prev     *quickListNode
-	listPack *listPack
+	listPack *listPack // nil while the node is compressed
+
+	compressed     []byte // entries of a compressed node, see compressNode
+	count          uint32 // number of entries of a compressed node
+	incompressible bool   // compressing the current entries did not pay off
 }
 
-func NewQuickList() QuickList {
+// NewQuickList returns an empty list. A positive fill limits the number of entries of a
+// node, and -1 to -5 limit its size to 4, 8, 16, 32 or 64KiB like list-max-listpack-size.
+// Nodes further than compressDepth nodes from both ends are kept compressed.
+func NewQuickList(fill, compressDepth int) QuickList {
 	head := &quickListNode{}
 	tail := &quickListNode{}
 
@@ -41,9 +73,11 @@ func NewQuickList() QuickList {
 	tail.prev = head
 
 	return &quickList{
-		head: head,
-		tail: tail,
-		size: 0,
+		head:          head,
+		tail:          tail,
+		size:          0,
+		fill:          fill,
+		compressDepth: compressDepth,
 	}
 }
 
@@ -82,7 +116,7 @@ func (q *quickList) LPush(elements []string) (uint32, int64) {
 
 		for canFit < len(remaining) {
 			elemSize := stringHeaderSize + uint64(len(remaining[canFit]))
-			if projectedSize+elemSize > listPackMaxSizeBytes {
+			if !q.nodeFits(first.listPack.size()+uint32(canFit)+1, projectedSize+elemSize) {
 				break
 			}
 			projectedSize += elemSize
@@ -109,6 +143,7 @@ func (q *quickList) LPush(elements []string) (uint32, int64) {
 		}
 	}
 
+	delta += q.compress()
 	return q.size, delta
 }
 
@@ -128,7 +163,7 @@ func (q *quickList) LPop(count uint32) ([]string, int64) {
 		first := q.head.next
 
 		// Skip and remove empty nodes
-		for first != q.tail && first.listPack.empty() {
+		for first != q.tail && first.size() == 0 {
 			q.removeNode(first)
 			first = q.head.next
 		}
@@ -136,6 +171,7 @@ func (q *quickList) LPop(count uint32) ([]string, int64) {
 		if first == q.tail {
 			break
 		}
+		delta += q.decompressNode(first)
 
 		// Batch pop from current node
 		nodeSize := first.listPack.size()
@@ -155,6 +191,7 @@ func (q *quickList) LPop(count uint32) ([]string, int64) {
 		}
 	}
 
+	delta += q.compress()
 	return result, delta
 }
 
@@ -180,7 +217,7 @@ func (q *quickList) RPush(elements []string) (uint32, int64) {
 
 		for canFit < len(remaining) {
 			elemSize := stringHeaderSize + uint64(len(remaining[canFit]))
-			if listPackSize+elemSize > listPackMaxSizeBytes {
+			if !q.nodeFits(last.listPack.size()+uint32(canFit)+1, listPackSize+elemSize) {
 				break
 			}
 			listPackSize += elemSize
@@ -206,6 +243,7 @@ func (q *quickList) RPush(elements []string) (uint32, int64) {
 		}
 	}
 
+	delta += q.compress()
 	return q.size, delta
 }
 
@@ -223,7 +261,7 @@ func (q *quickList) RPop(count uint32) ([]string, int64) {
 	for count > 0 && q.size > 0 {
 		last := q.tail.prev
 
-		for last != q.head && last.listPack.empty() {
+		for last != q.head && last.size() == 0 {
 			q.removeNode(last)
 			last = q.tail.prev
 		}
@@ -231,6 +269,7 @@ func (q *quickList) RPop(count uint32) ([]string, int64) {
 		if last == q.head {
 			break
 		}
+		delta += q.decompressNode(last)
 
 		nodeSize := last.listPack.size()
 		popCount := min(count, nodeSize)
@@ -248,6 +287,7 @@ func (q *quickList) RPop(count uint32) ([]string, int64) {
 		}
 	}
 
+	delta += q.compress()
 	return result, delta
 }
 
@@ -277,15 +317,19 @@ func (q *quickList) LRange(start, end int32) []string {
 	}
 
 	node, index := q.findPosition(uint32(start))
+	entries := node.entries()
 
 	result := make([]string, 0, end-start+1)
 	for i := int32(0); i < end-start+1 && node != q.tail; i++ {
-		result = append(result, node.listPack.get(index))
+		result = append(result, entries.get(index))
 		index++
 
-		if index >= int32(node.listPack.size()) {
+		if index >= int32(entries.size()) {
 			index = 0
 			node = node.next
+			if node != q.tail {
+				entries = node.entries()
+			}
 		}
 	}
 
@@ -307,7 +351,7 @@ func (q *quickList) LIndex(index int32) (string, bool) {
 	}
 
 	node, index := q.findPosition(uint32(index))
-	return node.listPack.get(index), true
+	return node.entries().get(index), true
 }
 
 func (q *quickList) LRem(count int32, element string) (uint32, int64) {
@@ -317,6 +361,7 @@ func (q *quickList) LRem(count int32, element string) (uint32, int64) {
 
 	var removed uint32 = 0
 	delta := int64(0)
+	touched := make([]*quickListNode, 0)
 	elemDelta := QuickListElementSize(element)
 	absCount := count
 	if absCount < 0 {
@@ -328,6 +373,8 @@ func (q *quickList) LRem(count int32, element string) (uint32, int64) {
 		node := q.head.next
 		for node != q.tail {
 			nextNode := node.next
+			delta += q.decompressNode(node)
+			touched = append(touched, node)
 			nodeRemoved := q.removeFromNode(node, element, absCount, removed, count == 0)
 			removed += nodeRemoved
 			delta -= int64(nodeRemoved) * elemDelta
@@ -344,6 +391,8 @@ func (q *quickList) LRem(count int32, element string) (uint32, int64) {
 		node := q.tail.prev
 		for node != q.head {
 			prevNode := node.prev
+			delta += q.decompressNode(node)
+			touched = append(touched, node)
 			nodeRemoved := q.removeFromNodeReverse(node, element, absCount, removed)
 			removed += nodeRemoved
 			delta -= int64(nodeRemoved) * elemDelta
@@ -356,6 +405,7 @@ func (q *quickList) LRem(count int32, element string) (uint32, int64) {
 		}
 	}
 
+	delta += q.compress(touched...)
 	return removed, delta
 }
 
@@ -374,9 +424,23 @@ func (q *quickList) LSet(index int32, element string) (error, int64) {
 	}
 
 	node, localIndex := q.findPosition(uint32(index))
+	delta := q.decompressNode(node)
 	oldElement := node.listPack.get(localIndex)
 	node.listPack.set(localIndex, element)
-	delta := QuickListElementSize(element) - QuickListElementSize(oldElement)
+	delta += QuickListElementSize(element) - QuickListElementSize(oldElement)
+
+	// An element that no longer fits its node is moved into a node of its own
+	touched := []*quickListNode{node}
+	if !q.nodeFits(node.listPack.size(), node.listPack.approxSizeBytes()) {
+		if localIndex > 0 {
+			node = q.splitNode(node, uint32(localIndex))
+			touched = append(touched, node)
+		}
+		if node.listPack.size() > 1 {
+			touched = append(touched, q.splitNode(node, 1))
+		}
+	}
+	delta += q.compress(touched...)
 	return nil, delta
 }
 
@@ -419,71 +483,92 @@ func (q *quickList) LTrim(start, end int32) int64 {
 		delta += q.removeFromTailWithDelta(uint32(toRemove))
 	}
 
+	delta += q.compress()
 	return delta
 }
 
-func (q *quickList) MemoryUsage() int64 {
-	return int64(size.Of(q))
-}
-
-func (q *quickList) removeFromHead(count uint32) {
-	for count > 0 && q.size > 0 {
-		first := q.head.next
-		if first == q.tail {
-			break
-		}
-
-		nodeSize := first.listPack.size()
-		removeCount := min(count, nodeSize)
-
-		for i := uint32(0); i < removeCount; i++ {
-			first.listPack.lPop()
-			q.size--
-			count--
-		}
+// LInsert inserts element before or after the first occurrence of pivot, and reports
+// whether pivot was found. A node that grows past the listpack size limit is split in two.
+func (q *quickList) LInsert(pivot, element string, before bool) (bool, int64) {
+	for node := q.head.next; node != q.tail; node = node.next {
+		entries := node.entries()
+		for i := int32(0); i < int32(entries.size()); i++ {
+			if entries.get(i) != pivot {
+				continue
+			}
 
-		// Remove node if empty
-		if first.listPack.empty() {
-			q.removeNode(first)
+			if !before {
+				i++
+			}
+			delta := q.decompressNode(node)
+			node.listPack.insertAt(i, element)
+			q.size++
+			delta += QuickListElementSize(element)
+
+			touched := []*quickListNode{node}
+			if !q.nodeFits(node.listPack.size(), node.listPack.approxSizeBytes()) {
+				touched = append(touched, q.splitNode(node, node.listPack.size()/2))
+			}
+			return true, delta + q.compress(touched...)
 		}
 	}
+
+	return false, 0
 }
 
-func (q *quickList) removeFromTail(count uint32) {
-	for count > 0 && q.size > 0 {
-		last := q.tail.prev
-		if last == q.head {
-			break
+// LPos returns the indexes of the elements equal to element. A positive rank skips the
+// first rank-1 matches from the head, a negative one searches from the tail and skips
+// the last -rank-1 matches. At most count indexes are returned, and at most maxLen
+// elements are compared; 0 means no limit for both.
+func (q *quickList) LPos(element string, rank int64, count, maxLen uint32) []uint32 {
+	result := make([]uint32, 0)
+	skip := rank - 1
+	if rank < 0 {
+		skip = -rank - 1
+	}
+
+	compared := uint32(0)
+	match := func(index uint32, value string) bool {
+		compared++
+		if value == element {
+			if skip > 0 {
+				skip--
+			} else {
+				result = append(result, index)
+			}
 		}
+		return (count == 0 || uint32(len(result)) < count) && (maxLen == 0 || compared < maxLen)
+	}
 
-		nodeSize := last.listPack.size()
-		removeCount := min(count, nodeSize)
-
-		for i := uint32(0); i < removeCount; i++ {
-			last.listPack.rPop()
-			q.size--
-			count--
+	if rank > 0 {
+		index := uint32(0)
+		for node := q.head.next; node != q.tail; node = node.next {
+			entries := node.entries()
+			for i := int32(0); i < int32(entries.size()); i++ {
+				if !match(index, entries.get(i)) {
+					return result
+				}
+				index++
+			}
 		}
-
-		// Remove node if empty
-		if last.listPack.empty() {
-			q.removeNode(last)
+	} else {
+		index := q.size
+		for node := q.tail.prev; node != q.head; node = node.prev {
+			entries := node.entries()
+			for i := int32(entries.size()) - 1; i >= 0; i-- {
+				index--
+				if !match(index, entries.get(i)) {
+					return result
+				}
+			}
 		}
 	}
-}
 
-func (q *quickList) clear() {
-	node := q.head.next
-	for node != q.tail {
-		next := node.next
-		node.prev = nil
-		node.next = nil
-		node = next
-	}
+	return result
+}
 
-	q.head.next = q.tail
-	q.tail.prev = q.head
-	q.size = 0
+func (q *quickList) MemoryUsage() int64 {
+	return int64(size.Of(q))
 }
 
 func (q *quickList) clearWithDelta() int64 {
@@ -491,8 +576,10 @@ func (q *quickList) clearWithDelta() int64 {
 	node := q.head.next
 	for node != q.tail {
 		// Calculate delta for all elements in this node
-		for i := uint32(0); i < node.listPack.size(); i++ {
-			delta -= QuickListElementSize(node.listPack.get(int32(i)))
+		if node.compressed != nil {
+			delta -= compressedNodeSize(node.compressed)
+		} else {
+			delta -= node.listPack.elementsSize()
 		}
 		next := node.next
 		node.prev = nil
@@ -513,6 +600,7 @@ func (q *quickList) removeFromHeadWithDelta(count uint32) int64 {
 		if first == q.tail {
 			break
 		}
+		delta += q.decompressNode(first)
 
 		nodeSize := first.listPack.size()
 		removeCount := min(count, nodeSize)
@@ -539,6 +627,7 @@ func (q *quickList) removeFromTailWithDelta(count uint32) int64 {
 		if last == q.head {
 			break
 		}
+		delta += q.decompressNode(last)
 
 		nodeSize := last.listPack.size()
 		removeCount := min(count, nodeSize)
@@ -565,7 +654,7 @@ func (q *quickList) findPosition(index uint32) (*quickListNode, int32) {
 
 	if index == q.size-1 {
 		last := q.tail.prev
-		return last, int32(last.listPack.size() - 1)
+		return last, int32(last.size() - 1)
 	}
 
 	leftNode := q.head.next
@@ -574,7 +663,7 @@ func (q *quickList) findPosition(index uint32) (*quickListNode, int32) {
 	rightAccum := q.size - 1
 
 	for leftNode != q.tail {
-		leftSize := leftNode.listPack.size()
+		leftSize := leftNode.size()
 
 		// Check if target is in current left node
 		if index >= leftAccum && index < leftAccum+leftSize {
@@ -583,7 +672,7 @@ func (q *quickList) findPosition(index uint32) (*quickListNode, int32) {
 
 		// Check if target is in current right node
 		if rightNode != leftNode {
-			rightSize := rightNode.listPack.size()
+			rightSize := rightNode.size()
 			if index >= rightAccum-rightSize+1 && index <= rightAccum {
 				localIdx := index - (rightAccum - rightSize + 1)
 				return rightNode, int32(localIdx)
@@ -605,6 +694,173 @@ func (q *quickList) findPosition(index uint32) (*quickListNode, int32) {
 	panic("findPosition: index out of bounds")
 }
 
+// splitNode moves the elements of node from index on into a new node after it, and returns
+// the new node.
+func (q *quickList) splitNode(node *quickListNode, index uint32) *quickListNode {
+	newNode := newQuickListNode()
+	newNode.listPack.rPush(node.listPack.data[index:])
+	node.listPack.data = append([]string(nil), node.listPack.data[:index]...)
+
+	newNode.prev = node
+	newNode.next = node.next
+	node.next.prev = newNode
+	node.next = newNode
+	return newNode
+}
+
+// nodeFits reports whether a node may hold entries elements taking bytes in total. A node
+// always takes its first element, whatever its size.
+func (q *quickList) nodeFits(entries uint32, bytes uint64) bool {
+	if entries <= 1 {
+		return true
+	}
+
+	if q.fill >= 0 {
+		return entries <= uint32(q.fill)
+	}
+
+	level := min(-q.fill, len(listPackSizeLimits)) - 1
+	return bytes <= listPackSizeLimits[level]
+}
+
+// size returns the number of entries of the node, compressed or not.
+func (node *quickListNode) size() uint32 {
+	if node.compressed != nil {
+		return node.count
+	}
+	return node.listPack.size()
+}
+
+// entries returns the entries of the node for reading. A compressed node is decompressed
+// into a copy and stays compressed.
+func (node *quickListNode) entries() *listPack {
+	if node.compressed == nil {
+		return node.listPack
+	}
+
+	r := flate.NewReader(bytes.NewReader(node.compressed))
+	data, err := io.ReadAll(r)
+	if err != nil {
+		panic("entries called on a corrupt compressed node")
+	}
+
+	reader := binaryReader{data: data}
+	lp := &listPack{data: make([]string, node.count)}
+	for i := range lp.data {
+		lp.data[i] = reader.string()
+	}
+	if reader.finish() != nil {
+		panic("entries called on a corrupt compressed node")
+	}
+	return lp
+}
+
+// compress keeps the compressDepth nodes at each end of the list uncompressed and
+// compresses the nodes that moved away from the ends, along with the touched nodes that
+// were decompressed to be modified. It returns the memory delta.
+func (q *quickList) compress(touched ...*quickListNode) int64 {
+	if q.compressDepth <= 0 {
+		return 0
+	}
+
+	delta := int64(0)
+	ends := make(map[*quickListNode]struct{}, 2*q.compressDepth)
+	first, last := q.head.next, q.tail.prev
+	for i := 0; i < q.compressDepth; i++ {
+		if first != q.tail {
+			delta += q.decompressNode(first)
+			ends[first] = struct{}{}
+			first = first.next
+		}
+		if last != q.head {
+			delta += q.decompressNode(last)
+			ends[last] = struct{}{}
+			last = last.prev
+		}
+	}
+
+	// Interior nodes are compressed up to the first one that already was
+	for node := first; node != q.tail && !node.compressedOrTried(); node = node.next {
+		if _, ok := ends[node]; ok {
+			break
+		}
+		delta += q.compressNode(node)
+	}
+	for node := last; node != q.head && !node.compressedOrTried(); node = node.prev {
+		if _, ok := ends[node]; ok {
+			break
+		}
+		delta += q.compressNode(node)
+	}
+
+	for _, node := range touched {
+		if _, ok := ends[node]; !ok && node.prev != nil {
+			delta += q.compressNode(node)
+		}
+	}
+
+	return delta
+}
+
+func (node *quickListNode) compressedOrTried() bool {
+	return node.compressed != nil || node.incompressible
+}
+
+// compressNode compresses the entries of node with flate, unless they are too small or
+// compress too poorly. It returns the memory delta.
+func (q *quickList) compressNode(node *quickListNode) int64 {
+	if node.compressedOrTried() {
+		return 0
+	}
+
+	node.incompressible = true
+	if node.listPack.approxSizeBytes() < minCompressBytes {
+		return 0
+	}
+
+	raw := make([]byte, 0, node.listPack.approxSizeBytes())
+	for _, element := range node.listPack.data {
+		raw = appendString(raw, element)
+	}
+
+	var buf bytes.Buffer
+	w := flateWriters.Get().(*flate.Writer)
+	w.Reset(&buf)
+	w.Write(raw)
+	w.Close()
+	flateWriters.Put(w)
+
+	if buf.Len()+minCompressGain > len(raw) {
+		return 0
+	}
+
+	delta := compressedNodeSize(buf.Bytes()) - node.listPack.elementsSize()
+	node.compressed = bytes.Clone(buf.Bytes())
+	node.count = node.listPack.size()
+	node.listPack = nil
+	return delta
+}
+
+// decompressNode makes the entries of node available for modification. It returns the
+// memory delta.
+func (q *quickList) decompressNode(node *quickListNode) int64 {
+	node.incompressible = false
+	if node.compressed == nil {
+		return 0
+	}
+
+	node.listPack = node.entries()
+	delta := node.listPack.elementsSize() - compressedNodeSize(node.compressed)
+	node.compressed = nil
+	node.count = 0
+	return delta
+}
+
+// compressedNodeSize returns memory for the compressed entries of a node
+func compressedNodeSize(compressed []byte) int64 {
+	return int64(sliceHeaderSize) + int64(len(compressed))
+}
+
 func (q *quickList) removeNode(node *quickListNode) {
 	if node == q.head || node == q.tail {
 		return
@@ -10,7 +10,7 @@ import (
 )
 
 func TestNewQuickList(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	require.NotNil(t, ql)
 
 	q := ql.(*quickList)
@@ -22,7 +22,7 @@ func TestNewQuickList(t *testing.T) {
 }
 
 func TestLPushSingleElement(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 
 	size, _ := ql.LPush([]string{"first"})
 	assert.Equal(t, uint32(1), size)
@@ -32,7 +32,7 @@ func TestLPushSingleElement(t *testing.T) {
 }
 
 func TestLPushMultipleElements(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 
 	ql.LPush([]string{"third", "second", "first"})
 
@@ -41,7 +41,7 @@ func TestLPushMultipleElements(t *testing.T) {
 }
 
 func TestRPushSingleElement(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 
 	size, _ := ql.RPush([]string{"first"})
 	assert.Equal(t, uint32(1), size)
@@ -51,7 +51,7 @@ func TestRPushSingleElement(t *testing.T) {
 }
 
 func TestRPushMultipleElements(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 
 	ql.RPush([]string{"first", "second", "third"})
 
@@ -60,7 +60,7 @@ func TestRPushMultipleElements(t *testing.T) {
 }
 
 func TestLPopSingleElement(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.LPush([]string{"only"})
 
 	result, _ := ql.LPop(1)
@@ -72,7 +72,7 @@ func TestLPopSingleElement(t *testing.T) {
 }
 
 func TestLPopMultipleElements(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.LPush([]string{"5", "4", "3", "2", "1"})
 
 	result, _ := ql.LPop(3)
@@ -83,7 +83,7 @@ func TestLPopMultipleElements(t *testing.T) {
 }
 
 func TestRPopSingleElement(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"only"})
 
 	result, _ := ql.RPop(1)
@@ -94,7 +94,7 @@ func TestRPopSingleElement(t *testing.T) {
 }
 
 func TestRPopMultipleElements(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"1", "2", "3", "4", "5"})
 
 	result, _ := ql.RPop(3)
@@ -105,7 +105,7 @@ func TestRPopMultipleElements(t *testing.T) {
 }
 
 func TestLRangeBasic(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"0", "1", "2", "3", "4"})
 
 	tests := []struct {
@@ -132,7 +132,7 @@ func TestLRangeBasic(t *testing.T) {
 }
 
 func TestLRangeEdgeCases(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"0", "1", "2"})
 
 	tests := []struct {
@@ -156,14 +156,14 @@ func TestLRangeEdgeCases(t *testing.T) {
 }
 
 func TestLRangeEmptyList(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 
 	result := ql.LRange(0, -1)
 	assert.Empty(t, result)
 }
 
 func TestPopFromEmptyList(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 
 	lpopResult, _ := ql.LPop(5)
 	assert.NotNil(t, lpopResult)
@@ -175,7 +175,7 @@ func TestPopFromEmptyList(t *testing.T) {
 }
 
 func TestPopZeroCount(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"1", "2", "3"})
 
 	lpopResult, _ := ql.LPop(0)
@@ -192,7 +192,7 @@ func TestPopZeroCount(t *testing.T) {
 }
 
 func TestPopMoreThanSize(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"1", "2", "3"})
 
 	result, _ := ql.LPop(10)
@@ -204,7 +204,7 @@ func TestPopMoreThanSize(t *testing.T) {
 }
 
 func TestPushEmptySlice(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 
 	sizeL, _ := ql.LPush([]string{})
 	assert.Equal(t, uint32(0), sizeL)
@@ -214,7 +214,7 @@ func TestPushEmptySlice(t *testing.T) {
 }
 
 func TestMixedPushOperations(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 
 	ql.LPush([]string{"2", "1"}) // [1, 2]
 	ql.RPush([]string{"3", "4"}) // [1, 2, 3, 4]
@@ -226,7 +226,7 @@ func TestMixedPushOperations(t *testing.T) {
 }
 
 func TestMixedPopOperations(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"1", "2", "3", "4", "5", "6"})
 
 	lpop, _ := ql.LPop(2) // [3, 4, 5, 6]
@@ -240,7 +240,7 @@ func TestMixedPopOperations(t *testing.T) {
 }
 
 func TestAlternatingPushPop(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 
 	ql.LPush([]string{"1"}) // [1]
 	ql.RPush([]string{"2"}) // [1, 2]
@@ -254,7 +254,7 @@ func TestAlternatingPushPop(t *testing.T) {
 }
 
 func TestLargeDataLPush(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 
 	// Create enough data to span multiple nodes
 	elements := make([]string, 1000)
@@ -277,7 +277,7 @@ func TestLargeDataLPush(t *testing.T) {
 }
 
 func TestLargeDataRPush(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 
 	elements := make([]string, 1000)
 	for i := 0; i < 1000; i++ {
@@ -292,7 +292,7 @@ func TestLargeDataRPush(t *testing.T) {
 }
 
 func TestLargeDataPopOperations(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 
 	// Push 500 elements
 	elements := make([]string, 500)
@@ -321,7 +321,7 @@ func TestLargeDataPopOperations(t *testing.T) {
 }
 
 func TestLRangeAcrossMultipleNodes(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 
 	// Create data that will span multiple nodes
 	elements := make([]string, 500)
@@ -338,7 +338,7 @@ func TestLRangeAcrossMultipleNodes(t *testing.T) {
 }
 
 func TestLIndexBasic(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"a", "b", "c", "d", "e"})
 
 	tests := []struct {
@@ -366,7 +366,7 @@ func TestLIndexBasic(t *testing.T) {
 }
 
 func TestLIndexEmptyList(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 
 	val, ok := ql.LIndex(0)
 	assert.False(t, ok)
@@ -374,7 +374,7 @@ func TestLIndexEmptyList(t *testing.T) {
 }
 
 func TestLRemPositiveCount(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"a", "b", "a", "c", "a", "d", "a"})
 
 	// Remove first 2 occurrences of "a"
@@ -386,7 +386,7 @@ func TestLRemPositiveCount(t *testing.T) {
 }
 
 func TestLRemNegativeCount(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"a", "b", "a", "c", "a", "d", "a"})
 
 	// Remove last 2 occurrences of "a"
@@ -398,7 +398,7 @@ func TestLRemNegativeCount(t *testing.T) {
 }
 
 func TestLRemZeroCount(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"a", "b", "a", "c", "a"})
 
 	// Remove all occurrences of "a"
@@ -410,7 +410,7 @@ func TestLRemZeroCount(t *testing.T) {
 }
 
 func TestLRemNoMatch(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"a", "b", "c"})
 
 	removed, _ := ql.LRem(5, "x")
@@ -422,14 +422,14 @@ func TestLRemNoMatch(t *testing.T) {
 }
 
 func TestLRemEmptyList(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 
 	removed, _ := ql.LRem(5, "a")
 	assert.Equal(t, uint32(0), removed)
 }
 
 func TestLRemAllElements(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"a", "a", "a"})
 
 	removed, _ := ql.LRem(0, "a")
@@ -440,7 +440,7 @@ func TestLRemAllElements(t *testing.T) {
 }
 
 func TestLSetBasic(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"a", "b", "c"})
 
 	// Set first element
@@ -452,7 +452,7 @@ func TestLSetBasic(t *testing.T) {
 }
 
 func TestLSetNegativeIndex(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"a", "b", "c"})
 
 	// Set last element
@@ -464,7 +464,7 @@ func TestLSetNegativeIndex(t *testing.T) {
 }
 
 func TestLSetOutOfBounds(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"a", "b", "c"})
 
 	tests := []int32{10, -10, 3, -4}
@@ -476,7 +476,7 @@ func TestLSetOutOfBounds(t *testing.T) {
 }
 
 func TestLSetMiddle(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"a", "b", "c", "d", "e"})
 
 	err, _ := ql.LSet(2, "X")
@@ -487,7 +487,7 @@ func TestLSetMiddle(t *testing.T) {
 }
 
 func TestLTrimBasic(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"a", "b", "c", "d", "e"})
 
 	// Keep elements from index 1 to 3
@@ -498,7 +498,7 @@ func TestLTrimBasic(t *testing.T) {
 }
 
 func TestLTrimKeepAll(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"a", "b", "c"})
 
 	// Keep all elements
@@ -509,7 +509,7 @@ func TestLTrimKeepAll(t *testing.T) {
 }
 
 func TestLTrimNegativeIndices(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"a", "b", "c", "d", "e"})
 
 	// Keep last 3 elements
@@ -520,7 +520,7 @@ func TestLTrimNegativeIndices(t *testing.T) {
 }
 
 func TestLTrimClearList(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"a", "b", "c"})
 
 	// Invalid range clears the list
@@ -530,7 +530,7 @@ func TestLTrimClearList(t *testing.T) {
 }
 
 func TestLTrimEmptyList(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 
 	// Should not panic
 	ql.LTrim(0, 5)
@@ -539,7 +539,7 @@ func TestLTrimEmptyList(t *testing.T) {
 }
 
 func TestLTrimSingleElement(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"a", "b", "c", "d", "e"})
 
 	// Keep only element at index 2
@@ -550,7 +550,7 @@ func TestLTrimSingleElement(t *testing.T) {
 }
 
 func TestLTrimFromStart(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"a", "b", "c", "d", "e"})
 
 	// Keep first 3 elements
@@ -561,7 +561,7 @@ func TestLTrimFromStart(t *testing.T) {
 }
 
 func TestLTrimToEnd(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"a", "b", "c", "d", "e"})
 
 	// Remove first 2, keep the rest
@@ -572,7 +572,7 @@ func TestLTrimToEnd(t *testing.T) {
 }
 
 func TestLTrimStartGreaterThanEnd(t *testing.T) {
-	ql := NewQuickList()
+	ql := NewQuickList(-2, 0)
 	ql.RPush([]string{"a", "b", "c"})
 
 	// Invalid range
@@ -580,4 +580,269 @@ func TestLTrimStartGreaterThanEnd(t *testing.T) {
 
 	// Should clear the list
 	assert.Equal(t, uint32(0), ql.Size())
-}
\ No newline at end of file
+}
+func TestLInsertBeforeAndAfter(t *testing.T) {
+	ql := NewQuickList(-2, 0)
+	ql.RPush([]string{"a", "c", "c"})
+
+	inserted, delta := ql.LInsert("c", "b", true)
+	assert.True(t, inserted)
+	assert.Equal(t, QuickListElementSize("b"), delta)
+
+	inserted, _ = ql.LInsert("c", "d", false)
+	assert.True(t, inserted)
+	assert.Equal(t, []string{"a", "b", "c", "d", "c"}, ql.LRange(0, -1))
+	assert.Equal(t, uint32(5), ql.Size())
+
+	inserted, delta = ql.LInsert("missing", "x", true)
+	assert.False(t, inserted)
+	assert.Equal(t, int64(0), delta)
+}
+
+func TestLInsertSplitsFullNode(t *testing.T) {
+	ql := NewQuickList(-2, 0)
+	q := ql.(*quickList)
+
+	element := string(make([]byte, 1000))
+	elements := make([]string, 0)
+	for i := 0; i < 7; i++ {
+		elements = append(elements, strconv.Itoa(i)+element)
+	}
+	ql.RPush(elements)
+	require.Equal(t, q.head.next, q.tail.prev, "elements should fit in one node")
+
+	ql.LInsert(elements[3], "new"+element, true)
+	ql.LInsert(elements[3], "newer"+element, true)
+
+	assert.NotEqual(t, q.head.next, q.tail.prev)
+	result := ql.LRange(0, -1)
+	assert.Len(t, result, 9)
+	assert.Equal(t, "new"+element, result[3])
+	assert.Equal(t, "newer"+element, result[4])
+	assert.Equal(t, elements[3], result[5])
+	for node := q.head.next; node != q.tail; node = node.next {
+		assert.LessOrEqual(t, node.listPack.approxSizeBytes(), listPackSizeLimits[1])
+	}
+}
+
+func TestLSetMovesLargeElementToItsOwnNode(t *testing.T) {
+	ql := NewQuickList(-2, 0)
+	q := ql.(*quickList)
+
+	element := string(make([]byte, 1000))
+	elements := make([]string, 0)
+	for i := 0; i < 7; i++ {
+		elements = append(elements, strconv.Itoa(i)+element)
+	}
+	ql.RPush(elements)
+	require.Equal(t, []uint32{7}, nodeSizes(ql), "elements should fit in one node")
+
+	large := string(make([]byte, 7000))
+	err, _ := ql.LSet(3, large)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{3, 1, 3}, nodeSizes(ql))
+
+	err, _ = ql.LSet(0, large)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{1, 2, 1, 3}, nodeSizes(ql))
+
+	expected := append([]string{}, elements...)
+	expected[0], expected[3] = large, large
+	assert.Equal(t, expected, ql.LRange(0, -1))
+	for node := q.head.next; node != q.tail; node = node.next {
+		assert.True(t, q.nodeFits(node.listPack.size(), node.listPack.approxSizeBytes()))
+	}
+}
+
+func TestLPos(t *testing.T) {
+	ql := NewQuickList(-2, 0)
+	ql.RPush([]string{"a", "b", "c", "1", "2", "3", "c", "c"})
+
+	tests := []struct {
+		name     string
+		rank     int64
+		count    uint32
+		maxLen   uint32
+		expected []uint32
+	}{
+		{"first", 1, 1, 0, []uint32{2}},
+		{"rank", 2, 1, 0, []uint32{6}},
+		{"all", 1, 0, 0, []uint32{2, 6, 7}},
+		{"rank and count", 2, 0, 0, []uint32{6, 7}},
+		{"from tail", -1, 0, 0, []uint32{7, 6, 2}},
+		{"from tail with rank", -2, 1, 0, []uint32{6}},
+		{"maxlen", 1, 0, 3, []uint32{2}},
+		{"maxlen from tail", -1, 0, 1, []uint32{7}},
+		{"rank past matches", 4, 0, 0, []uint32{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ql.LPos("c", tt.rank, tt.count, tt.maxLen))
+		})
+	}
+
+	assert.Empty(t, ql.LPos("missing", 1, 0, 0))
+}
+
+// nodeSizes returns the number of elements of every node of ql.
+func nodeSizes(ql QuickList) []uint32 {
+	q := ql.(*quickList)
+	sizes := make([]uint32, 0)
+	for node := q.head.next; node != q.tail; node = node.next {
+		sizes = append(sizes, node.size())
+	}
+	return sizes
+}
+
+// accountedSize returns the memory the deltas of ql should add up to.
+func accountedSize(ql QuickList) int64 {
+	q := ql.(*quickList)
+	total := int64(0)
+	for node := q.head.next; node != q.tail; node = node.next {
+		if node.compressed != nil {
+			total += compressedNodeSize(node.compressed)
+		} else {
+			total += node.listPack.elementsSize()
+		}
+	}
+	return total
+}
+
+func auditElements(count int) []string {
+	elements := make([]string, count)
+	for i := range elements {
+		elements[i] = fmt.Sprintf("audit entry %05d: user logged in from the usual address", i)
+	}
+	return elements
+}
+
+func TestQuickListFillByCount(t *testing.T) {
+	ql := NewQuickList(3, 0)
+
+	ql.RPush([]string{"a", "b", "c", "d", "e"})
+	ql.LPush([]string{"z", "y"})
+	assert.Equal(t, []uint32{2, 3, 2}, nodeSizes(ql))
+
+	ql.LInsert("b", "x", true)
+	assert.Equal(t, []uint32{2, 2, 2, 2}, nodeSizes(ql))
+	assert.Equal(t, []string{"y", "z", "a", "x", "b", "c", "d", "e"}, ql.LRange(0, -1))
+}
+
+func TestQuickListFillBySize(t *testing.T) {
+	element := string(make([]byte, 1000))
+	elements := make([]string, 64)
+	for i := range elements {
+		elements[i] = element
+	}
+
+	small := NewQuickList(-1, 0)
+	small.RPush(elements)
+	large := NewQuickList(-5, 0)
+	large.RPush(elements)
+
+	assert.Len(t, nodeSizes(small), 16)
+	assert.Len(t, nodeSizes(large), 1)
+}
+
+func TestQuickListElementLargerThanNode(t *testing.T) {
+	ql := NewQuickList(-1, 0)
+	element := string(make([]byte, 10000))
+
+	ql.RPush([]string{element, "a"})
+	ql.LPush([]string{element})
+
+	assert.Equal(t, []uint32{1, 1, 1}, nodeSizes(ql))
+	assert.Equal(t, uint32(3), ql.Size())
+}
+
+func TestQuickListCompressesInteriorNodes(t *testing.T) {
+	ql := NewQuickList(8, 1)
+	q := ql.(*quickList)
+	elements := auditElements(80)
+
+	_, delta := ql.RPush(elements)
+	assert.Equal(t, accountedSize(ql), delta)
+
+	assert.Nil(t, q.head.next.compressed)
+	assert.Nil(t, q.tail.prev.compressed)
+	for node := q.head.next.next; node != q.tail.prev; node = node.next {
+		assert.NotNil(t, node.compressed)
+		assert.Nil(t, node.listPack)
+	}
+	assert.Less(t, delta, (&listPack{data: elements}).elementsSize())
+
+	assert.Equal(t, elements, ql.LRange(0, -1))
+	assert.Equal(t, elements[20:45], ql.LRange(20, 44))
+	value, ok := ql.LIndex(37)
+	assert.True(t, ok)
+	assert.Equal(t, elements[37], value)
+	assert.Equal(t, []uint32{41}, ql.LPos(elements[41], 1, 1, 0))
+
+	uncompressed := NewQuickList(8, 0)
+	uncompressed.RPush(elements)
+	assert.Less(t, ql.MemoryUsage(), uncompressed.MemoryUsage())
+}
+
+func TestQuickListCompressionFollowsEnds(t *testing.T) {
+	ql := NewQuickList(4, 2)
+	q := ql.(*quickList)
+	total := int64(0)
+
+	_, delta := ql.LPush(auditElements(40))
+	total += delta
+	_, delta = ql.LPop(10)
+	total += delta
+	_, delta = ql.RPop(6)
+	total += delta
+	_, delta = ql.LPush([]string{"head"})
+	total += delta
+	_, delta = ql.RPush(auditElements(5))
+	total += delta
+	assert.Equal(t, accountedSize(ql), total)
+
+	// Exactly the nodes further than two nodes from both ends are compressed
+	nodes := len(nodeSizes(ql))
+	i := 0
+	for node := q.head.next; node != q.tail; node = node.next {
+		interior := i >= 2 && i < nodes-2
+		assert.Equal(t, interior, node.compressed != nil, "node %d of %d", i, nodes)
+		i++
+	}
+
+	ql.LTrim(0, 0)
+	assert.Equal(t, []string{"head"}, ql.LRange(0, -1))
+}
+
+func TestQuickListModifiesCompressedNodes(t *testing.T) {
+	ql := NewQuickList(8, 1)
+	q := ql.(*quickList)
+	elements := auditElements(40)
+	_, total := ql.RPush(elements)
+
+	err, delta := ql.LSet(20, "changed")
+	require.NoError(t, err)
+	total += delta
+
+	_, delta = ql.LInsert(elements[30], "inserted", false)
+	total += delta
+
+	_, delta = ql.LRem(0, elements[10])
+	total += delta
+
+	assert.Equal(t, accountedSize(ql), total)
+
+	expected := append([]string{}, elements...)
+	expected[20] = "changed"
+	expected = append(expected[:31], append([]string{"inserted"}, expected[31:]...)...)
+	expected = append(expected[:10], expected[11:]...)
+	assert.Equal(t, expected, ql.LRange(0, -1))
+
+	for node := q.head.next.next; node != q.tail.prev; node = node.next {
+		assert.True(t, node.compressed != nil || node.incompressible)
+	}
+
+	total += ql.LTrim(5, 4)
+	assert.Equal(t, uint32(0), ql.Size())
+	assert.Equal(t, int64(0), total)
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/binary"
 	"math"
 
 	"github.com/DmitriyVTitov/size"
@@ -36,6 +37,8 @@ type ScalableBloomFilter interface {
 	MAdd(items []string) ([]int, int64)
 	MExists(items []string) []int
 	MemoryUsage() int64
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary(data []byte) error
 }
 
 // subFilter represents a single bloom filter in the chain
@@ -245,3 +248,74 @@ func (f *subFilter) existsInSubFilter(item string) bool {
 	}
 	return true
 }
+
+func (sbf *scalableBloomFilter) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, 64)
+	buf = appendUvarint(buf, sbf.initialCapacity)
+	buf = appendFloat64(buf, sbf.initialErrRate)
+	buf = appendUvarint(buf, uint64(sbf.expansionRate))
+	buf = appendFloat64(buf, sbf.tighteningRatio)
+	buf = appendUvarint(buf, sbf.totalItems)
+	buf = appendUvarint(buf, uint64(len(sbf.filters)))
+
+	for _, f := range sbf.filters {
+		buf = appendUvarint(buf, f.k)
+		buf = appendUvarint(buf, f.numBits)
+		buf = appendUvarint(buf, f.capacity)
+		buf = appendUvarint(buf, f.insertedItems)
+		buf = appendFloat64(buf, f.errorRate)
+		buf = appendUvarint(buf, uint64(len(f.bits)))
+		for _, word := range f.bits {
+			buf = binary.LittleEndian.AppendUint64(buf, word)
+		}
+	}
+
+	return buf, nil
+}
+
+func (sbf *scalableBloomFilter) UnmarshalBinary(data []byte) error {
+	r := &binaryReader{data: data}
+	initialCapacity := r.uvarint()
+	initialErrRate := r.float64()
+	expansionRate := int(r.uvarint())
+	tighteningRatio := r.float64()
+	totalItems := r.uvarint()
+
+	filters := make([]*subFilter, r.length(1))
+	for i := range filters {
+		f := &subFilter{
+			k:             r.uvarint(),
+			numBits:       r.uvarint(),
+			capacity:      r.uvarint(),
+			insertedItems: r.uvarint(),
+			errorRate:     r.float64(),
+		}
+
+		numWords := r.length(8)
+		if r.err == nil && (f.k == 0 || numWords != int((f.numBits+bitsPerWord-1)/bitsPerWord)) {
+			return ErrCorruptEncoding
+		}
+
+		f.bits = make([]uint64, numWords)
+		for j := range f.bits {
+			f.bits[j] = r.uint64()
+		}
+		filters[i] = f
+	}
+
+	if err := r.finish(); err != nil {
+		return err
+	}
+
+	if len(filters) == 0 {
+		return ErrCorruptEncoding
+	}
+
+	sbf.filters = filters
+	sbf.initialCapacity = initialCapacity
+	sbf.initialErrRate = initialErrRate
+	sbf.expansionRate = expansionRate
+	sbf.tighteningRatio = tighteningRatio
+	sbf.totalItems = totalItems
+	return nil
+}
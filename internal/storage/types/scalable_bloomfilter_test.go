@@ -619,3 +619,22 @@ func TestScalableBloomFilterManyFilters(t *testing.T) {
 		assert.Equal(t, 1, sbf.Exists(fmt.Sprintf("item%d", i)))
 	}
 }
+
+func TestScalableBloomFilterMarshalBinaryRoundTrip(t *testing.T) {
+	sbf := NewScalableBloomFilter(0.01, 10, 2)
+	for i := 0; i < 50; i++ {
+		sbf.Add(fmt.Sprintf("item%d", i))
+	}
+
+	data, err := sbf.MarshalBinary()
+	require.NoError(t, err)
+
+	loaded := NewScalableBloomFilter(0.01, 1, 2)
+	require.NoError(t, loaded.UnmarshalBinary(data))
+	assert.Equal(t, sbf.Info(0), loaded.Info(0))
+	for i := 0; i < 50; i++ {
+		assert.Equal(t, 1, loaded.Exists(fmt.Sprintf("item%d", i)))
+	}
+
+	require.Error(t, NewScalableBloomFilter(0.01, 1, 2).UnmarshalBinary(data[:len(data)-1]))
+}
@@ -0,0 +1,73 @@
+package types
+
+// ScanSlice calls fn for up to count elements of keys and returns the cursor of the next
+// call, 0 once every element was visited. A scan starts with cursor 0.
+//
+// Elements are visited from the end of the slice towards its start, and the cursor is the
+// number of positions left to visit. As long as new elements are only appended and deleted
+// ones are replaced by the last element, elements only ever move to a lower position: every
+// element present during the whole scan is visited, some of them possibly more than once.
+func ScanSlice[K any](keys []K, cursor uint64, count int, fn func(key K)) uint64 {
+	pos := uint64(len(keys))
+	if cursor != 0 && cursor < pos {
+		pos = cursor
+	}
+
+	for ; pos > 0 && count > 0; count-- {
+		pos--
+		fn(keys[pos])
+	}
+	return pos
+}
+
+// scanIndex keeps the members of a hash table encoded collection in a slice, in the order
+// required by ScanSlice, so that they can be iterated incrementally.
+type scanIndex struct {
+	members []string
+	index   map[string]int
+}
+
+func newScanIndex() scanIndex {
+	return scanIndex{
+		members: make([]string, 0),
+		index:   make(map[string]int),
+	}
+}
+
+// add appends a member that is not indexed yet and returns the memory delta.
+func (si *scanIndex) add(member string) int64 {
+	si.index[member] = len(si.members)
+	si.members = append(si.members, member)
+	return scanIndexEntrySize(member)
+}
+
+// remove moves the last member into the position of member and returns the memory delta.
+func (si *scanIndex) remove(member string) int64 {
+	idx, ok := si.index[member]
+	if !ok {
+		return 0
+	}
+
+	last := len(si.members) - 1
+	if idx != last {
+		si.members[idx] = si.members[last]
+		si.index[si.members[idx]] = idx
+	}
+
+	si.members = si.members[:last]
+	delete(si.index, member)
+	return -scanIndexEntrySize(member)
+}
+
+func (si *scanIndex) scan(cursor uint64, count int) (uint64, []string) {
+	members := make([]string, 0, min(count, len(si.members)))
+	next := ScanSlice(si.members, cursor, count, func(member string) {
+		members = append(members, member)
+	})
+	return next, members
+}
+
+// scanIndexEntrySize returns memory for a member in the slice and in the index map
+func scanIndexEntrySize(member string) int64 {
+	return StringHeaderSize + StringSize(member) + Int64Size + MapOverheadPerKey
+}
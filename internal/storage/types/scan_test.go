@@ -0,0 +1,108 @@
+package types
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanSlice(t *testing.T) {
+	keys := []int{0, 1, 2, 3, 4}
+
+	var visited []int
+	visit := func(key int) { visited = append(visited, key) }
+
+	cursor := ScanSlice(keys, 0, 2, visit)
+	assert.Equal(t, uint64(3), cursor)
+	cursor = ScanSlice(keys, cursor, 2, visit)
+	assert.Equal(t, uint64(1), cursor)
+	cursor = ScanSlice(keys, cursor, 2, visit)
+	assert.Zero(t, cursor)
+	assert.Equal(t, []int{4, 3, 2, 1, 0}, visited)
+
+	// A cursor past the end of a shrunk slice resumes from its end
+	visited = nil
+	assert.Zero(t, ScanSlice(keys[:2], 4, 10, visit))
+	assert.Equal(t, []int{1, 0}, visited)
+
+	assert.Zero(t, ScanSlice([]int{}, 0, 10, visit))
+}
+
+func TestScanIndex_ReturnsStableMembersDespiteModifications(t *testing.T) {
+	si := newScanIndex()
+	for i := 0; i < 100; i++ {
+		si.add(strconv.Itoa(i))
+	}
+
+	seen := make(map[string]bool)
+	var cursor uint64
+	added := 100
+	for {
+		var members []string
+		cursor, members = si.scan(cursor, 7)
+		for _, m := range members {
+			seen[m] = true
+		}
+		if cursor == 0 {
+			break
+		}
+
+		// Delete members below 10, already visited or not, and add new ones
+		for i := 0; i < 10; i++ {
+			si.remove(strconv.Itoa(i))
+		}
+		si.add(strconv.Itoa(added))
+		added++
+	}
+
+	for i := 10; i < 100; i++ {
+		assert.True(t, seen[strconv.Itoa(i)], "member %d was not returned", i)
+	}
+}
+
+func TestScanIndex_MemoryDelta(t *testing.T) {
+	si := newScanIndex()
+	delta := si.add("a") + si.add("b")
+	assert.Equal(t, 2*scanIndexEntrySize("a"), delta)
+
+	assert.Equal(t, -scanIndexEntrySize("a"), si.remove("a"))
+	assert.Zero(t, si.remove("a"))
+	assert.Equal(t, []string{"b"}, si.members)
+	assert.Equal(t, 0, si.index["b"])
+}
+
+func TestCollectionScan(t *testing.T) {
+	set := NewSimpleSet()
+	set.Add("a", "b", "c")
+	cursor, members := set.Scan(0, 2)
+	require.Len(t, members, 2)
+	cursor, rest := set.Scan(cursor, 2)
+	assert.Zero(t, cursor)
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, append(members, rest...))
+
+	// Intsets are returned at once
+	ints := NewIntSet()
+	ints.Add("3", "1", "2")
+	cursor, members = ints.Scan(0, 1)
+	assert.Zero(t, cursor)
+	assert.Equal(t, []string{"1", "2", "3"}, members)
+
+	hash := NewHash()
+	hash.Set(map[string]string{"f": "v"})
+	cursor, pairs := hash.Scan(0, 10)
+	assert.Zero(t, cursor)
+	assert.Equal(t, []string{"f", "v"}, pairs)
+
+	zset := NewZSet()
+	zset.ZAdd(map[string]float64{"m": 1.5}, ZAddOptions{})
+	zset.ZRem([]string{"missing"})
+	cursor, pairs = zset.ZScan(0, 10)
+	assert.Zero(t, cursor)
+	assert.Equal(t, []string{"m", "1.5"}, pairs)
+
+	zset.ZPopMin(1)
+	_, pairs = zset.ZScan(0, 10)
+	assert.Empty(t, pairs)
+}
@@ -7,6 +7,89 @@ type Set interface {
 	Members() []string
 	MIsMember(members ...string) []bool
 	Delete(members ...string) (int64, int64)
+	Scan(cursor uint64, count int) (uint64, []string)
 	MemoryUsage() int64
 }
 
+// SetIntersection returns the members found in every set, where a nil set is empty. The
+// members of the smallest set are checked against the others. At most limit members are
+// returned when limit is positive.
+func SetIntersection(sets []Set, limit int) []string {
+	result := make([]string, 0)
+	if len(sets) == 0 {
+		return result
+	}
+
+	smallest := 0
+	for i, set := range sets {
+		if set == nil {
+			return result
+		}
+		if set.Size() < sets[smallest].Size() {
+			smallest = i
+		}
+	}
+
+	for _, member := range sets[smallest].Members() {
+		inAll := true
+		for i, set := range sets {
+			if i != smallest && !set.IsMember(member) {
+				inAll = false
+				break
+			}
+		}
+
+		if inAll {
+			result = append(result, member)
+			if limit > 0 && len(result) >= limit {
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// SetUnion returns the members found in any of the sets, where a nil set is empty.
+func SetUnion(sets []Set) []string {
+	seen := make(map[string]struct{})
+	result := make([]string, 0)
+	for _, set := range sets {
+		if set == nil {
+			continue
+		}
+		for _, member := range set.Members() {
+			if _, ok := seen[member]; !ok {
+				seen[member] = struct{}{}
+				result = append(result, member)
+			}
+		}
+	}
+
+	return result
+}
+
+// SetDifference returns the members of the first set found in none of the others, where a
+// nil set is empty.
+func SetDifference(sets []Set) []string {
+	result := make([]string, 0)
+	if len(sets) == 0 || sets[0] == nil {
+		return result
+	}
+
+	for _, member := range sets[0].Members() {
+		found := false
+		for _, set := range sets[1:] {
+			if set != nil && set.IsMember(member) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			result = append(result, member)
+		}
+	}
+
+	return result
+}
@@ -440,3 +440,40 @@ func TestSimpleSetAlwaysReturnsTrue(t *testing.T) {
 	_, ok, _ = s.Add()
 	assert.True(t, ok, "SimpleSet Add should always return true, even for empty")
 }
+
+func newSetOf(members ...string) Set {
+	set := NewSimpleSet()
+	set.Add(members...)
+	return set
+}
+
+func newIntSetOf(members ...string) Set {
+	set := NewIntSet()
+	set.Add(members...)
+	return set
+}
+
+func TestSetIntersection(t *testing.T) {
+	sets := []Set{
+		newSetOf("a", "b", "c", "1", "2"),
+		newIntSetOf("1", "2", "3"),
+		newSetOf("2", "1", "c"),
+	}
+
+	assert.ElementsMatch(t, []string{"1", "2"}, SetIntersection(sets, 0))
+	assert.Len(t, SetIntersection(sets, 1), 1)
+	assert.Empty(t, SetIntersection(append(sets, nil), 0))
+	assert.Empty(t, SetIntersection(nil, 0))
+}
+
+func TestSetUnion(t *testing.T) {
+	sets := []Set{newSetOf("a", "b"), nil, newIntSetOf("1", "2"), newSetOf("b", "1")}
+	assert.ElementsMatch(t, []string{"a", "b", "1", "2"}, SetUnion(sets))
+	assert.Empty(t, SetUnion([]Set{nil}))
+}
+
+func TestSetDifference(t *testing.T) {
+	sets := []Set{newSetOf("a", "b", "c", "1"), newIntSetOf("1"), nil, newSetOf("c")}
+	assert.ElementsMatch(t, []string{"a", "b"}, SetDifference(sets))
+	assert.Empty(t, SetDifference([]Set{nil, newSetOf("a")}))
+}
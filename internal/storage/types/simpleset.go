@@ -4,11 +4,13 @@ import "github.com/DmitriyVTitov/size"
 
 type simpleSet struct {
 	contents map[string]struct{}
+	order    scanIndex
 }
 
 func NewSimpleSet() Set {
 	return &simpleSet{
 		contents: make(map[string]struct{}),
+		order:    newScanIndex(),
 	}
 }
 
@@ -19,7 +21,7 @@ func (s *simpleSet) Add(members ...string) (int64, bool, int64) {
 		if _, ok := s.contents[m]; !ok {
 			s.contents[m] = struct{}{}
 			added++
-			delta += StringMapEntrySize(m)
+			delta += StringMapEntrySize(m) + s.order.add(m)
 		}
 	}
 	return added, true, delta
@@ -31,6 +33,7 @@ func (s *simpleSet) Delete(members ...string) (int64, int64) {
 	for i := range members {
 		if _, ok := s.contents[members[i]]; ok {
 			delta -= StringMapEntrySize(members[i])
+			delta += s.order.remove(members[i])
 			delete(s.contents, members[i])
 			removedMembers++
 		}
@@ -64,6 +67,10 @@ func (s *simpleSet) Members() []string {
 	return result
 }
 
+func (s *simpleSet) Scan(cursor uint64, count int) (uint64, []string) {
+	return s.order.scan(cursor, count)
+}
+
 func (s *simpleSet) Size() int64 {
 	return int64(len(s.contents))
 }
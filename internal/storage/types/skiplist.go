@@ -219,44 +219,40 @@ func (sl *skipList) getRank(value string, score float64) int {
 	return -1
 }
 
-// Returns nodes within score range [minScore, maxScore]
-func (sl *skipList) getRangeByScore(minScore, maxScore float64) []*skipListNode {
-	current := sl.head
-
-	for i := sl.level - 1; i >= 0; i-- {
-		for current.levels[i].forward != nil && current.levels[i].forward.score < minScore {
-			current = current.levels[i].forward
-		}
-	}
-
-	current = current.levels[0].forward
+// Returns up to count nodes within the score range r, skipping the first offset of them. A
+// negative count returns every node after offset.
+func (sl *skipList) getRangeByScore(r ZScoreRange, offset, count int) []*skipListNode {
 	result := make([]*skipListNode, 0)
+	if r.empty() || offset < 0 {
+		return result
+	}
 
-	for current != nil && current.score <= maxScore {
+	first := sl.rankWhile(func(node *skipListNode) bool { return !r.aboveMin(node.score) })
+	current := sl.nodeAtRank(first + offset)
+	for current != nil && count != 0 && r.belowMax(current.score) {
 		result = append(result, current)
 		current = current.levels[0].forward
+		count--
 	}
 
 	return result
 }
 
-// Returns all nodes whose values are lexicographically between [minValue, maxValue] (inclusive).
+// Returns up to count nodes whose values are within the lex range r, skipping the first
+// offset of them. A negative count returns every node after offset.
 // Preconditions (CRITICAL): ALL elements in the skiplist MUST have the SAME score (Redis ZRANGEBYLEX / ZLEXCOUNT semantics)
-func (sl *skipList) getRangeByLex(minValue, maxValue string) []*skipListNode {
-	current := sl.head
-
-	for i := sl.level - 1; i >= 0; i-- {
-		for current.levels[i].forward != nil && current.levels[i].forward.value < minValue {
-			current = current.levels[i].forward
-		}
-	}
-
-	current = current.levels[0].forward
+func (sl *skipList) getRangeByLex(r ZLexRange, offset, count int) []*skipListNode {
 	result := make([]*skipListNode, 0)
+	if r.empty() || offset < 0 {
+		return result
+	}
 
-	for current != nil && current.value <= maxValue {
+	first := sl.rankWhile(func(node *skipListNode) bool { return !r.aboveMin(node.value) })
+	current := sl.nodeAtRank(first + offset)
+	for current != nil && count != 0 && r.belowMax(current.value) {
 		result = append(result, current)
 		current = current.levels[0].forward
+		count--
 	}
 
 	return result
@@ -392,12 +388,17 @@ func (sl *skipList) randomLevel() int {
 }
 
 func (sl *skipList) rankByScore(score float64) int {
+	return sl.rankWhile(func(node *skipListNode) bool { return node.score < score })
+}
+
+// rankWhile returns the number of leading nodes that satisfy fn, which must hold for a
+// prefix of the skiplist.
+func (sl *skipList) rankWhile(fn func(node *skipListNode) bool) int {
 	rank := 0
 	current := sl.head
 
 	for i := sl.level - 1; i >= 0; i-- {
-		for current.levels[i].forward != nil &&
-			current.levels[i].forward.score < score {
+		for current.levels[i].forward != nil && fn(current.levels[i].forward) {
 			rank += current.levels[i].span
 			current = current.levels[i].forward
 		}
@@ -406,67 +407,42 @@ func (sl *skipList) rankByScore(score float64) int {
 	return rank
 }
 
-func (sl *skipList) countByScore(minScore, maxScore float64) int {
-	if sl.length == 0 || minScore > maxScore {
-		return 0
+// nodeAtRank returns the node at rank (0-indexed), or nil when out of range.
+func (sl *skipList) nodeAtRank(rank int) *skipListNode {
+	if rank < 0 || rank >= sl.length {
+		return nil
 	}
 
-	left := sl.rankByScore(minScore)
-
-	// rank of first element with score > maxScore
-	rank := 0
+	traversed := 0
 	current := sl.head
-
 	for i := sl.level - 1; i >= 0; i-- {
-		for current.levels[i].forward != nil &&
-			current.levels[i].forward.score <= maxScore {
-			rank += current.levels[i].span
+		for current.levels[i].forward != nil && traversed+current.levels[i].span <= rank+1 {
+			traversed += current.levels[i].span
 			current = current.levels[i].forward
 		}
 	}
 
-	return rank - left
+	return current
 }
 
-// Preconditions (CRITICAL): ALL elements in the skiplist have the SAME score
-func (sl *skipList) rankByLex(value string) int {
-	rank := 0
-	current := sl.head
-
-	for i := sl.level - 1; i >= 0; i-- {
-		for current.levels[i].forward != nil &&
-			current.levels[i].forward.value < value {
-
-			rank += current.levels[i].span
-			current = current.levels[i].forward
-		}
+func (sl *skipList) countByScore(r ZScoreRange) int {
+	if sl.length == 0 || r.empty() {
+		return 0
 	}
 
-	return rank
+	left := sl.rankWhile(func(node *skipListNode) bool { return !r.aboveMin(node.score) })
+	right := sl.rankWhile(func(node *skipListNode) bool { return r.belowMax(node.score) })
+	return right - left
 }
 
 // Preconditions (CRITICAL): ALL elements in the skiplist have the SAME score
-func (sl *skipList) countByLex(minValue, maxValue string) int {
-	if minValue > maxValue || sl.length == 0 {
+func (sl *skipList) countByLex(r ZLexRange) int {
+	if sl.length == 0 || r.empty() {
 		return 0
 	}
 
-	left := sl.rankByLex(minValue)
-	right := sl.rankByLex(maxValue)
-
-	// Check if maxValue itself exists
-	current := sl.head
-	for i := sl.level - 1; i >= 0; i-- {
-		for current.levels[i].forward != nil &&
-			current.levels[i].forward.value <= maxValue {
-			current = current.levels[i].forward
-		}
-	}
-
-	if current != sl.head && current.value == maxValue {
-		right++
-	}
-
+	left := sl.rankWhile(func(node *skipListNode) bool { return !r.aboveMin(node.value) })
+	right := sl.rankWhile(func(node *skipListNode) bool { return r.belowMax(node.value) })
 	return right - left
 }
 
@@ -514,35 +490,39 @@ func (sl *skipList) popMax(count int) []*skipListNode {
 	return result
 }
 
-func (sl *skipList) getRevRangeByLex(maxValue, minValue string) []*skipListNode {
-	current := sl.head
-	for i := sl.level - 1; i >= 0; i-- {
-		for current.levels[i].forward != nil && current.levels[i].forward.value <= maxValue {
-			current = current.levels[i].forward
-		}
+// Returns up to count nodes whose values are within the lex range r, from its maximum down,
+// skipping the first offset of them. A negative count returns every node after offset.
+func (sl *skipList) getRevRangeByLex(r ZLexRange, offset, count int) []*skipListNode {
+	result := make([]*skipListNode, 0)
+	if r.empty() || offset < 0 {
+		return result
 	}
 
-	result := make([]*skipListNode, 0)
-	for current != nil && current.value >= minValue {
+	last := sl.rankWhile(func(node *skipListNode) bool { return r.belowMax(node.value) }) - 1
+	current := sl.nodeAtRank(last - offset)
+	for current != nil && count != 0 && r.aboveMin(current.value) {
 		result = append(result, current)
 		current = current.backward
+		count--
 	}
 
 	return result
 }
 
-func (sl *skipList) getRevRangeByScore(maxScore, minScore float64) []*skipListNode {
-	current := sl.head
-	for i := sl.level - 1; i >= 0; i-- {
-		for current.levels[i].forward != nil && current.levels[i].forward.score <= maxScore {
-			current = current.levels[i].forward
-		}
+// Returns up to count nodes within the score range r, from its maximum down, skipping the
+// first offset of them. A negative count returns every node after offset.
+func (sl *skipList) getRevRangeByScore(r ZScoreRange, offset, count int) []*skipListNode {
+	result := make([]*skipListNode, 0)
+	if r.empty() || offset < 0 {
+		return result
 	}
 
-	result := make([]*skipListNode, 0)
-	for current != nil && current.score >= minScore {
+	last := sl.rankWhile(func(node *skipListNode) bool { return r.belowMax(node.score) }) - 1
+	current := sl.nodeAtRank(last - offset)
+	for current != nil && count != 0 && r.aboveMin(current.score) {
 		result = append(result, current)
 		current = current.backward
+		count--
 	}
 
 	return result
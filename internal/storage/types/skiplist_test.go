@@ -1,6 +1,7 @@
 package types
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -192,10 +193,10 @@ func TestSkipList_GetRangeByScore(t *testing.T) {
 		struct{ v string; s float64 }{"d", 4},
 	)
 
-	assertOrder(t, &skipList{head: &skipListNode{levels: []skipListLevel{{forward: sl.getRangeByScore(2, 3)[0]}}}},
+	assertOrder(t, &skipList{head: &skipListNode{levels: []skipListLevel{{forward: sl.getRangeByScore(ZScoreRange{Min: 2, Max: 3}, 0, -1)[0]}}}},
 		"b", "c")
 
-	assert.Len(t, sl.getRangeByScore(10, 20), 0)
+	assert.Len(t, sl.getRangeByScore(ZScoreRange{Min: 10, Max: 20}, 0, -1), 0)
 }
 
 func TestSkipList_GetRangeByScore_SeekAndStop(t *testing.T) {
@@ -205,7 +206,7 @@ func TestSkipList_GetRangeByScore_SeekAndStop(t *testing.T) {
 		sl.insert(string(rune('a'+i)), float64(i))
 	}
 
-	nodes := sl.getRangeByScore(3.5, 6.2)
+	nodes := sl.getRangeByScore(ZScoreRange{Min: 3.5, Max: 6.2}, 0, -1)
 
 	require.Len(t, nodes, 3)
 	assert.Equal(t, "e", nodes[0].value)
@@ -220,7 +221,7 @@ func TestSkipList_GetRangeByLex(t *testing.T) {
 	sl.insert("cherry", 3)
 	sl.insert("date", 4)
 
-	nodes := sl.getRangeByLex("banana", "date")
+	nodes := sl.getRangeByLex(lexRangeOf("banana", "date"), 0, -1)
 
 	require.Len(t, nodes, 3)
 	assert.Equal(t, "banana", nodes[0].value)
@@ -231,12 +232,12 @@ func TestSkipList_GetRangeByLex(t *testing.T) {
 func TestSkipList_GetRangeByLex_EmptyAndBounds(t *testing.T) {
 	sl := newSkipList()
 
-	assert.Len(t, sl.getRangeByLex("a", "z"), 0)
+	assert.Len(t, sl.getRangeByLex(lexRangeOf("a", "z"), 0, -1), 0)
 
 	sl.insert("mango", 1)
 
-	assert.Len(t, sl.getRangeByLex("z", "zz"), 0)
-	assert.Len(t, sl.getRangeByLex("a", "a"), 0)
+	assert.Len(t, sl.getRangeByLex(lexRangeOf("z", "zz"), 0, -1), 0)
+	assert.Len(t, sl.getRangeByLex(lexRangeOf("a", "a"), 0, -1), 0)
 }
 
 func TestSkipList_GetRangeByRank(t *testing.T) {
@@ -474,7 +475,7 @@ func TestSkipList_CountByScore(t *testing.T) {
 		assert.Equal(
 			t,
 			tt.expected,
-			sl.countByScore(tt.min, tt.max),
+			sl.countByScore(ZScoreRange{Min: tt.min, Max: tt.max}),
 			"countByScore(%v, %v)", tt.min, tt.max,
 		)
 	}
@@ -484,7 +485,7 @@ func TestSkipList_CountByScore_Empty(t *testing.T) {
 	sl := newSkipList()
 
 	assert.Equal(t, 0, sl.rankByScore(1))
-	assert.Equal(t, 0, sl.countByScore(0, 10))
+	assert.Equal(t, 0, sl.countByScore(ZScoreRange{Min: 0, Max: 10}))
 }
 
 func TestSkipList_CountByScore_Large(t *testing.T) {
@@ -495,10 +496,10 @@ func TestSkipList_CountByScore_Large(t *testing.T) {
 	}
 
 	assert.Equal(t, 1000, sl.rankByScore(1000))
-	assert.Equal(t, 500, sl.countByScore(250, 749))
+	assert.Equal(t, 500, sl.countByScore(ZScoreRange{Min: 250, Max: 749}))
 }
 
-func TestSkipList_rankByLex_Basic(t *testing.T) {
+func TestSkipList_countByLex_BelowValue(t *testing.T) {
 	sl := newSkipList()
 
 	// All scores are the same (REQUIRED)
@@ -518,11 +519,11 @@ func TestSkipList_rankByLex_Basic(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		assert.Equal(t, tt.expected, sl.rankByLex(tt.value), "rankByLex(%q)", tt.value)
+		assert.Equal(t, tt.expected, sl.countByLex(ZLexRange{Min: ZLexBound{Inf: -1}, Max: ZLexBound{Value: tt.value, Exclusive: true}}), "countByLex(-, (%q)", tt.value)
 	}
 }
 
-func TestSkipList_rankByLex_NonExisting(t *testing.T) {
+func TestSkipList_countByLex_BelowMissingValue(t *testing.T) {
 	sl := newSkipList()
 
 	sl.insert("apple", 0)
@@ -540,13 +541,13 @@ func TestSkipList_rankByLex_NonExisting(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		assert.Equal(t, tt.expected, sl.rankByLex(tt.value), "rankByLex(%q)", tt.value)
+		assert.Equal(t, tt.expected, sl.countByLex(ZLexRange{Min: ZLexBound{Inf: -1}, Max: ZLexBound{Value: tt.value, Exclusive: true}}), "countByLex(-, (%q)", tt.value)
 	}
 }
 
-func TestSkipList_rankByLex_Empty(t *testing.T) {
+func TestSkipList_countByLex_BelowValueEmpty(t *testing.T) {
 	sl := newSkipList()
-	assert.Equal(t, 0, sl.rankByLex("anything"))
+	assert.Equal(t, 0, sl.countByLex(ZLexRange{Min: ZLexBound{Inf: -1}, Max: ZLexBound{Value: "anything", Exclusive: true}}))
 }
 
 func TestSkipList_countByLex_Basic(t *testing.T) {
@@ -569,7 +570,7 @@ func TestSkipList_countByLex_Basic(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		assert.Equal(t, tt.expected, sl.countByLex(tt.min, tt.max), "countByLex(%q, %q)", tt.min, tt.max)
+		assert.Equal(t, tt.expected, sl.countByLex(lexRangeOf(tt.min, tt.max)), "countByLex(%q, %q)", tt.min, tt.max)
 	}
 }
 
@@ -589,13 +590,13 @@ func TestSkipList_countByLex_EmptyAndInvalidRanges(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		assert.Equal(t, tt.expected, sl.countByLex(tt.min, tt.max), "countByLex(%q, %q)", tt.min, tt.max)
+		assert.Equal(t, tt.expected, sl.countByLex(lexRangeOf(tt.min, tt.max)), "countByLex(%q, %q)", tt.min, tt.max)
 	}
 }
 
 func TestSkipList_countByLex_EmptySet(t *testing.T) {
 	sl := newSkipList()
-	assert.Equal(t, 0, sl.countByLex("a", "z"))
+	assert.Equal(t, 0, sl.countByLex(lexRangeOf("a", "z")))
 }
 
 func TestSkipList_countByLex_MatchesRangeByLex(t *testing.T) {
@@ -611,8 +612,8 @@ func TestSkipList_countByLex_MatchesRangeByLex(t *testing.T) {
 
 	min, max := "banana", "fig"
 
-	rangeNodes := sl.getRangeByLex(min, max)
-	count := sl.countByLex(min, max)
+	rangeNodes := sl.getRangeByLex(lexRangeOf(min, max), 0, -1)
+	count := sl.countByLex(lexRangeOf(min, max))
 
 	assert.Equal(t, len(rangeNodes), count)
 }
@@ -794,15 +795,15 @@ func TestSkipList_GetRevRangeByScore(t *testing.T) {
 
 	t.Run("empty skiplist", func(t *testing.T) {
 		empty := newSkipList()
-		assert.Len(t, empty.getRevRangeByScore(5, 1), 0)
+		assert.Len(t, empty.getRevRangeByScore(ZScoreRange{Min: 1, Max: 5}, 0, -1), 0)
 	})
 
 	t.Run("no elements in range", func(t *testing.T) {
-		assert.Len(t, sl.getRevRangeByScore(10, 6), 0)
+		assert.Len(t, sl.getRevRangeByScore(ZScoreRange{Min: 6, Max: 10}, 0, -1), 0)
 	})
 
 	t.Run("partial range reversed", func(t *testing.T) {
-		nodes := sl.getRevRangeByScore(3, 2)
+		nodes := sl.getRevRangeByScore(ZScoreRange{Min: 2, Max: 3}, 0, -1)
 
 		require.Len(t, nodes, 2)
 		assert.Equal(t, "c", nodes[0].value)
@@ -810,7 +811,7 @@ func TestSkipList_GetRevRangeByScore(t *testing.T) {
 	})
 
 	t.Run("full range reversed", func(t *testing.T) {
-		nodes := sl.getRevRangeByScore(4, 1)
+		nodes := sl.getRevRangeByScore(ZScoreRange{Min: 1, Max: 4}, 0, -1)
 
 		require.Len(t, nodes, 4)
 		assert.Equal(t, []string{"d", "c", "b", "a"},
@@ -818,14 +819,14 @@ func TestSkipList_GetRevRangeByScore(t *testing.T) {
 	})
 
 	t.Run("single element range", func(t *testing.T) {
-		nodes := sl.getRevRangeByScore(2, 2)
+		nodes := sl.getRevRangeByScore(ZScoreRange{Min: 2, Max: 2}, 0, -1)
 
 		require.Len(t, nodes, 1)
 		assert.Equal(t, "b", nodes[0].value)
 	})
 
 	t.Run("inclusive boundaries", func(t *testing.T) {
-		nodes := sl.getRevRangeByScore(4, 4)
+		nodes := sl.getRevRangeByScore(ZScoreRange{Min: 4, Max: 4}, 0, -1)
 
 		require.Len(t, nodes, 1)
 		assert.Equal(t, "d", nodes[0].value)
@@ -843,15 +844,15 @@ func TestSkipList_GetRevRangeByLex(t *testing.T) {
 
 	t.Run("empty skiplist", func(t *testing.T) {
 		empty := newSkipList()
-		assert.Len(t, empty.getRevRangeByLex("z", "a"), 0)
+		assert.Len(t, empty.getRevRangeByLex(lexRangeOf("a", "z"), 0, -1), 0)
 	})
 
 	t.Run("no elements in range", func(t *testing.T) {
-		assert.Len(t, sl.getRevRangeByLex("zzz", "yyy"), 0)
+		assert.Len(t, sl.getRevRangeByLex(lexRangeOf("yyy", "zzz"), 0, -1), 0)
 	})
 
 	t.Run("partial range reversed", func(t *testing.T) {
-		nodes := sl.getRevRangeByLex("date", "banana")
+		nodes := sl.getRevRangeByLex(lexRangeOf("banana", "date"), 0, -1)
 
 		require.Len(t, nodes, 3)
 		assert.Equal(t, "date", nodes[0].value)
@@ -860,7 +861,7 @@ func TestSkipList_GetRevRangeByLex(t *testing.T) {
 	})
 
 	t.Run("full range reversed", func(t *testing.T) {
-		nodes := sl.getRevRangeByLex("date", "apple")
+		nodes := sl.getRevRangeByLex(lexRangeOf("apple", "date"), 0, -1)
 
 		require.Len(t, nodes, 4)
 		assert.Equal(t, []string{"date", "cherry", "banana", "apple"},
@@ -868,16 +869,60 @@ func TestSkipList_GetRevRangeByLex(t *testing.T) {
 	})
 
 	t.Run("single element range", func(t *testing.T) {
-		nodes := sl.getRevRangeByLex("banana", "banana")
+		nodes := sl.getRevRangeByLex(lexRangeOf("banana", "banana"), 0, -1)
 
 		require.Len(t, nodes, 1)
 		assert.Equal(t, "banana", nodes[0].value)
 	})
 
 	t.Run("inclusive boundaries", func(t *testing.T) {
-		nodes := sl.getRevRangeByLex("apple", "apple")
+		nodes := sl.getRevRangeByLex(lexRangeOf("apple", "apple"), 0, -1)
 
 		require.Len(t, nodes, 1)
 		assert.Equal(t, "apple", nodes[0].value)
 	})
-}
\ No newline at end of file
+}
+func TestSkipList_GetRangeByScore_Limit(t *testing.T) {
+	sl := newSkipList()
+	for i := 0; i < 100; i++ {
+		sl.insert(strconv.Itoa(1000+i), float64(i))
+	}
+
+	nodes := sl.getRangeByScore(ZScoreRange{Min: 10, Max: 50, MinEx: true}, 5, 3)
+	require.Len(t, nodes, 3)
+	assert.Equal(t, []float64{16, 17, 18}, []float64{nodes[0].score, nodes[1].score, nodes[2].score})
+
+	nodes = sl.getRevRangeByScore(ZScoreRange{Min: 10, Max: 50, MaxEx: true}, 0, 2)
+	require.Len(t, nodes, 2)
+	assert.Equal(t, []float64{49, 48}, []float64{nodes[0].score, nodes[1].score})
+
+	assert.Len(t, sl.getRangeByScore(ZScoreRange{Min: 10, Max: 50}, 41, -1), 0)
+	assert.Len(t, sl.getRangeByScore(ZScoreRange{Min: 10, Max: 50}, 40, -1), 1)
+	assert.Equal(t, 39, sl.countByScore(ZScoreRange{Min: 10, Max: 50, MinEx: true, MaxEx: true}))
+}
+
+func TestSkipList_GetRangeByLex_ExclusiveAndInfinite(t *testing.T) {
+	sl := newSkipList()
+	for _, value := range []string{"a", "b", "c", "d"} {
+		sl.insert(value, 0)
+	}
+	values := func(nodes []*skipListNode) []string {
+		result := make([]string, len(nodes))
+		for i, node := range nodes {
+			result[i] = node.value
+		}
+		return result
+	}
+
+	r := ZLexRange{Min: ZLexBound{Value: "a", Exclusive: true}, Max: ZLexBound{Value: "d", Exclusive: true}}
+	assert.Equal(t, []string{"b", "c"}, values(sl.getRangeByLex(r, 0, -1)))
+	assert.Equal(t, []string{"c", "b"}, values(sl.getRevRangeByLex(r, 0, -1)))
+	assert.Equal(t, 2, sl.countByLex(r))
+
+	r = ZLexRange{Min: ZLexBound{Inf: -1}, Max: ZLexBound{Inf: 1}}
+	assert.Equal(t, []string{"b", "c"}, values(sl.getRangeByLex(r, 1, 2)))
+	assert.Equal(t, []string{"d"}, values(sl.getRevRangeByLex(r, 0, 1)))
+
+	assert.Len(t, sl.getRangeByLex(ZLexRange{Min: ZLexBound{Inf: 1}, Max: ZLexBound{Inf: 1}}, 0, -1), 0)
+	assert.Equal(t, 0, sl.countByLex(ZLexRange{Min: ZLexBound{Value: "b", Exclusive: true}, Max: ZLexBound{Value: "b"}}))
+}
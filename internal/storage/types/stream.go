@@ -0,0 +1,537 @@
+package types
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Maximum number of entries appended to a stream node before a new node is started,
+// matching the stream-node-max-entries default of Redis
+const streamNodeMaxEntries = 100
+
+var (
+	ErrInvalidStreamID   = errors.New("ERR Invalid stream ID specified as stream command argument")
+	ErrStreamIDZero      = errors.New("ERR The ID specified in XADD must be greater than 0-0")
+	ErrStreamIDTooSmall  = errors.New("ERR The ID specified in XADD is equal or smaller than the target stream top item")
+	ErrStreamIDExhausted = errors.New("ERR The stream has exhausted the last possible ID, unable to add more items")
+)
+
+// StreamID identifies a stream entry. Ms is usually the time the entry was added at and
+// Seq orders the entries added within the same millisecond.
+type StreamID struct {
+	Ms  uint64
+	Seq uint64
+}
+
+var (
+	MinStreamID = StreamID{0, 0}
+	MaxStreamID = StreamID{math.MaxUint64, math.MaxUint64}
+)
+
+// ParseStreamID parses `<ms>-<seq>`, or `<ms>` alone in which case Seq is missingSeq.
+func ParseStreamID(str string, missingSeq uint64) (StreamID, error) {
+	msPart, seqPart, hasSeq := strings.Cut(str, "-")
+
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return StreamID{}, ErrInvalidStreamID
+	}
+
+	if !hasSeq {
+		return StreamID{ms, missingSeq}, nil
+	}
+
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return StreamID{}, ErrInvalidStreamID
+	}
+
+	return StreamID{ms, seq}, nil
+}
+
+func (id StreamID) String() string {
+	return strconv.FormatUint(id.Ms, 10) + "-" + strconv.FormatUint(id.Seq, 10)
+}
+
+func (id StreamID) Compare(other StreamID) int {
+	if c := cmp.Compare(id.Ms, other.Ms); c != 0 {
+		return c
+	}
+	return cmp.Compare(id.Seq, other.Seq)
+}
+
+// Incr returns the smallest ID greater than id, false when id is the last possible one.
+func (id StreamID) Incr() (StreamID, bool) {
+	switch {
+	case id.Seq < math.MaxUint64:
+		return StreamID{id.Ms, id.Seq + 1}, true
+	case id.Ms < math.MaxUint64:
+		return StreamID{id.Ms + 1, 0}, true
+	default:
+		return id, false
+	}
+}
+
+// Decr returns the largest ID smaller than id, false when id is 0-0.
+func (id StreamID) Decr() (StreamID, bool) {
+	switch {
+	case id.Seq > 0:
+		return StreamID{id.Ms, id.Seq - 1}, true
+	case id.Ms > 0:
+		return StreamID{id.Ms - 1, math.MaxUint64}, true
+	default:
+		return id, false
+	}
+}
+
+type StreamEntry struct {
+	ID     StreamID
+	Fields []string // field and value pairs
+}
+
+type StreamTrimStrategy uint8
+
+const (
+	StreamTrimNone StreamTrimStrategy = iota
+	StreamTrimMaxLen
+	StreamTrimMinID
+)
+
+// StreamTrimOptions describes the MAXLEN and MINID arguments of XADD and XTRIM.
+type StreamTrimOptions struct {
+	Strategy StreamTrimStrategy
+	MaxLen   uint64
+	MinID    StreamID
+	Approx   bool   // `~`, only whole nodes are removed
+	Limit    uint64 // with Approx, maximum number of entries removed, 0 for the default
+}
+
+// StreamAddOptions describes the ID and the options of XADD.
+type StreamAddOptions struct {
+	ID         StreamID
+	AutoID     bool // `*`, the whole ID is generated
+	AutoSeq    bool // `<ms>-*`, only the sequence number is generated
+	NoMkStream bool
+	Trim       StreamTrimOptions
+}
+
+type Stream interface {
+	NextID(options StreamAddOptions, nowMs uint64) (StreamID, error)
+	Add(id StreamID, fields []string) int64
+	Len() uint32
+	LastID() StreamID
+	Range(start, end StreamID, count int, rev bool) []StreamEntry
+	Delete(ids []StreamID) (int64, int64)
+	Trim(options StreamTrimOptions) (int64, int64)
+	EntriesAdded() uint64
+	MaxDeletedID() StreamID
+	NodeCount() int
+	CreateGroup(name string, lastID StreamID, entriesRead int64) (*StreamGroup, int64)
+	Group(name string) *StreamGroup
+	Groups() []*StreamGroup
+	DestroyGroup(name string) (bool, int64)
+	ReadGroup(g *StreamGroup, consumer *StreamConsumer, count int, noAck bool, nowMs int64) ([]StreamEntry, int64)
+	ReadPending(consumer *StreamConsumer, after StreamID, count int, nowMs int64) []StreamEntry
+	Claim(g *StreamGroup, consumer *StreamConsumer, ids []StreamID, options StreamClaimOptions, nowMs int64) ([]StreamEntry, int64)
+	AutoClaim(g *StreamGroup, consumer *StreamConsumer, start StreamID, count int, options StreamClaimOptions,
+		nowMs int64) (StreamID, []StreamEntry, []StreamID, int64)
+	Lag(g *StreamGroup) (int64, bool)
+	MemoryUsage() int64
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary(data []byte) error
+}
+
+// streamNode holds consecutive entries like a listpack of a Redis stream. Deleted entries
+// still count towards the node capacity, so a node only grows while it is the last one.
+type streamNode struct {
+	entries []StreamEntry
+	deleted int
+}
+
+func (n *streamNode) full() bool {
+	return len(n.entries)+n.deleted >= streamNodeMaxEntries
+}
+
+func (n *streamNode) lastID() StreamID {
+	return n.entries[len(n.entries)-1].ID
+}
+
+type stream struct {
+	nodes  []*streamNode // ordered by ID, never empty nodes
+	length uint32
+	lastID StreamID // greatest ID ever added, even if it was deleted since
+
+	entriesAdded uint64   // number of entries ever added
+	maxDeletedID StreamID // greatest ID removed by Delete
+	groups       map[string]*StreamGroup
+}
+
+func NewStream() Stream {
+	return &stream{}
+}
+
+// NextID returns the ID of the next entry, which must be greater than every ID added before.
+func (s *stream) NextID(options StreamAddOptions, nowMs uint64) (StreamID, error) {
+	switch {
+	case options.AutoID:
+		if nowMs > s.lastID.Ms {
+			return StreamID{nowMs, 0}, nil
+		}
+		next, ok := s.lastID.Incr()
+		if !ok {
+			return StreamID{}, ErrStreamIDExhausted
+		}
+		return next, nil
+
+	case options.AutoSeq:
+		ms := options.ID.Ms
+		switch {
+		case ms > s.lastID.Ms:
+			return StreamID{ms, 0}, nil
+		case ms < s.lastID.Ms || s.lastID.Seq == math.MaxUint64:
+			return StreamID{}, ErrStreamIDTooSmall
+		default:
+			return StreamID{ms, s.lastID.Seq + 1}, nil
+		}
+
+	default:
+		if options.ID == MinStreamID {
+			return StreamID{}, ErrStreamIDZero
+		}
+		if options.ID.Compare(s.lastID) <= 0 {
+			return StreamID{}, ErrStreamIDTooSmall
+		}
+		return options.ID, nil
+	}
+}
+
+// Add appends an entry, id must come from NextID.
+func (s *stream) Add(id StreamID, fields []string) int64 {
+	var delta int64
+	if len(s.nodes) == 0 || s.nodes[len(s.nodes)-1].full() {
+		s.nodes = append(s.nodes, &streamNode{})
+		delta += PointerSize + streamNodeSize()
+	}
+
+	entry := StreamEntry{ID: id, Fields: fields}
+	node := s.nodes[len(s.nodes)-1]
+	node.entries = append(node.entries, entry)
+	s.length++
+	s.lastID = id
+	s.entriesAdded++
+
+	return delta + streamEntrySize(entry)
+}
+
+func (s *stream) Len() uint32 {
+	return s.length
+}
+
+func (s *stream) LastID() StreamID {
+	return s.lastID
+}
+
+func (s *stream) EntriesAdded() uint64 {
+	return s.entriesAdded
+}
+
+func (s *stream) MaxDeletedID() StreamID {
+	return s.maxDeletedID
+}
+
+func (s *stream) NodeCount() int {
+	return len(s.nodes)
+}
+
+// seek returns the position of the first entry whose ID is not smaller than id,
+// len(s.nodes) when there is none.
+func (s *stream) seek(id StreamID) (int, int) {
+	nodeIdx := sort.Search(len(s.nodes), func(i int) bool {
+		return s.nodes[i].lastID().Compare(id) >= 0
+	})
+	if nodeIdx == len(s.nodes) {
+		return nodeIdx, 0
+	}
+
+	entries := s.nodes[nodeIdx].entries
+	entryIdx := sort.Search(len(entries), func(i int) bool {
+		return entries[i].ID.Compare(id) >= 0
+	})
+	return nodeIdx, entryIdx
+}
+
+// Range returns the entries with IDs between start and end inclusive, from end to start
+// when rev is set. A count of zero or less returns every such entry.
+func (s *stream) Range(start, end StreamID, count int, rev bool) []StreamEntry {
+	result := make([]StreamEntry, 0)
+	if start.Compare(end) > 0 {
+		return result
+	}
+
+	if !rev {
+		nodeIdx, entryIdx := s.seek(start)
+		for ; nodeIdx < len(s.nodes); nodeIdx, entryIdx = nodeIdx+1, 0 {
+			for _, entry := range s.nodes[nodeIdx].entries[entryIdx:] {
+				if entry.ID.Compare(end) > 0 || (count > 0 && len(result) == count) {
+					return result
+				}
+				result = append(result, entry)
+			}
+		}
+		return result
+	}
+
+	// Start right before the first entry greater than end
+	nodeIdx, entryIdx := len(s.nodes), 0
+	if next, ok := end.Incr(); ok {
+		nodeIdx, entryIdx = s.seek(next)
+	}
+	for {
+		if entryIdx == 0 {
+			if nodeIdx == 0 {
+				return result
+			}
+			nodeIdx--
+			entryIdx = len(s.nodes[nodeIdx].entries)
+		}
+		entryIdx--
+
+		entry := s.nodes[nodeIdx].entries[entryIdx]
+		if entry.ID.Compare(start) < 0 || (count > 0 && len(result) == count) {
+			return result
+		}
+		result = append(result, entry)
+	}
+}
+
+// Delete removes the entries with the given IDs and returns how many existed.
+func (s *stream) Delete(ids []StreamID) (int64, int64) {
+	var deleted, delta int64
+	for _, id := range ids {
+		nodeIdx, entryIdx := s.seek(id)
+		if nodeIdx == len(s.nodes) || s.nodes[nodeIdx].entries[entryIdx].ID != id {
+			continue
+		}
+
+		delta += s.removeEntry(nodeIdx, entryIdx)
+		deleted++
+		if id.Compare(s.maxDeletedID) > 0 {
+			s.maxDeletedID = id
+		}
+	}
+	return deleted, delta
+}
+
+func (s *stream) removeEntry(nodeIdx, entryIdx int) int64 {
+	node := s.nodes[nodeIdx]
+	delta := -streamEntrySize(node.entries[entryIdx])
+
+	node.entries = append(node.entries[:entryIdx], node.entries[entryIdx+1:]...)
+	node.deleted++
+	s.length--
+
+	if len(node.entries) == 0 {
+		s.nodes = append(s.nodes[:nodeIdx], s.nodes[nodeIdx+1:]...)
+		delta -= PointerSize + streamNodeSize()
+	}
+	return delta
+}
+
+// Trim evicts the oldest entries according to options and returns how many were removed.
+// Approximate trimming only removes whole nodes, so it may keep a few entries more than asked.
+func (s *stream) Trim(options StreamTrimOptions) (int64, int64) {
+	var removed, delta int64
+
+	// Whether the oldest entry, or with Approx the oldest node, has to go
+	mustTrim := func(node *streamNode) bool {
+		switch options.Strategy {
+		case StreamTrimMaxLen:
+			if options.Approx {
+				return uint64(s.length)-uint64(len(node.entries)) >= options.MaxLen
+			}
+			return uint64(s.length) > options.MaxLen
+		case StreamTrimMinID:
+			if options.Approx {
+				return node.lastID().Compare(options.MinID) < 0
+			}
+			return node.entries[0].ID.Compare(options.MinID) < 0
+		default:
+			return false
+		}
+	}
+
+	limit := options.Limit
+	if options.Approx && limit == 0 {
+		limit = 100 * streamNodeMaxEntries
+	}
+
+	for len(s.nodes) > 0 && mustTrim(s.nodes[0]) {
+		if !options.Approx {
+			delta += s.removeEntry(0, 0)
+			removed++
+			continue
+		}
+
+		node := s.nodes[0]
+		if uint64(removed)+uint64(len(node.entries)) > limit {
+			break
+		}
+		for _, entry := range node.entries {
+			delta -= streamEntrySize(entry)
+		}
+		removed += int64(len(node.entries))
+		s.length -= uint32(len(node.entries))
+		s.nodes = s.nodes[1:]
+		delta -= PointerSize + streamNodeSize()
+	}
+
+	return removed, delta
+}
+
+func (s *stream) MemoryUsage() int64 {
+	usage := SliceHeaderSize + Uint32Size + 5*Uint64Size + PointerSize
+	for _, node := range s.nodes {
+		usage += PointerSize + streamNodeSize()
+		for _, entry := range node.entries {
+			usage += streamEntrySize(entry)
+		}
+	}
+	for _, g := range s.groups {
+		usage += streamGroupMemoryUsage(g)
+	}
+	return usage
+}
+
+// MarshalBinary encodes the last ID, the live entries and the consumer groups. Node
+// boundaries are not kept, entries are packed into full nodes again when decoded.
+func (s *stream) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, 64)
+	buf = appendUvarint(buf, s.lastID.Ms)
+	buf = appendUvarint(buf, s.lastID.Seq)
+	buf = appendUvarint(buf, uint64(s.length))
+
+	for _, node := range s.nodes {
+		for _, entry := range node.entries {
+			buf = appendUvarint(buf, entry.ID.Ms)
+			buf = appendUvarint(buf, entry.ID.Seq)
+			buf = appendUvarint(buf, uint64(len(entry.Fields)))
+			for _, field := range entry.Fields {
+				buf = appendString(buf, field)
+			}
+		}
+	}
+
+	buf = appendUvarint(buf, s.entriesAdded)
+	buf = appendUvarint(buf, s.maxDeletedID.Ms)
+	buf = appendUvarint(buf, s.maxDeletedID.Seq)
+
+	// Signed counters and times are stored as their two's complement, -1 included
+	buf = appendUvarint(buf, uint64(len(s.groups)))
+	for _, g := range s.Groups() {
+		buf = appendString(buf, g.Name)
+		buf = appendUvarint(buf, g.LastID.Ms)
+		buf = appendUvarint(buf, g.LastID.Seq)
+		buf = appendUvarint(buf, uint64(g.EntriesRead))
+
+		buf = appendUvarint(buf, uint64(len(g.consumers)))
+		for _, c := range g.Consumers() {
+			buf = appendString(buf, c.Name)
+			buf = appendUvarint(buf, uint64(c.SeenTime))
+			buf = appendUvarint(buf, uint64(c.ActiveTime))
+		}
+
+		buf = appendUvarint(buf, uint64(len(g.pending)))
+		for _, pe := range g.pending {
+			buf = appendUvarint(buf, pe.ID.Ms)
+			buf = appendUvarint(buf, pe.ID.Seq)
+			buf = appendString(buf, pe.Consumer.Name)
+			buf = appendUvarint(buf, uint64(pe.DeliveryTime))
+			buf = appendUvarint(buf, pe.DeliveryCount)
+		}
+	}
+
+	return buf, nil
+}
+
+func (s *stream) UnmarshalBinary(data []byte) error {
+	r := &binaryReader{data: data}
+	lastID := StreamID{r.uvarint(), r.uvarint()}
+	length := r.length(3)
+
+	decoded := &stream{}
+	for i := 0; i < length && r.err == nil; i++ {
+		id := StreamID{r.uvarint(), r.uvarint()}
+		fields := make([]string, r.length(1))
+		for j := range fields {
+			fields[j] = r.string()
+		}
+
+		if r.err == nil && (id.Compare(decoded.lastID) <= 0 || id.Compare(lastID) > 0 || len(fields)%2 != 0) {
+			return ErrCorruptEncoding
+		}
+		decoded.Add(id, fields)
+	}
+
+	decoded.entriesAdded = r.uvarint()
+	decoded.maxDeletedID = StreamID{r.uvarint(), r.uvarint()}
+
+	groupCount := r.length(1)
+	for i := 0; i < groupCount && r.err == nil; i++ {
+		name := r.string()
+		g, _ := decoded.CreateGroup(name, StreamID{r.uvarint(), r.uvarint()}, int64(r.uvarint()))
+		if g == nil {
+			return ErrCorruptEncoding
+		}
+
+		consumerCount := r.length(1)
+		for j := 0; j < consumerCount && r.err == nil; j++ {
+			c, created, _ := g.CreateConsumer(r.string(), 0)
+			if !created {
+				return ErrCorruptEncoding
+			}
+			c.SeenTime = int64(r.uvarint())
+			c.ActiveTime = int64(r.uvarint())
+		}
+
+		pendingCount := r.length(5)
+		for j := 0; j < pendingCount && r.err == nil; j++ {
+			pe := &StreamPendingEntry{ID: StreamID{r.uvarint(), r.uvarint()}}
+			pe.Consumer = g.consumers[r.string()]
+			pe.DeliveryTime = int64(r.uvarint())
+			pe.DeliveryCount = r.uvarint()
+
+			if r.err != nil {
+				break
+			}
+			if pe.Consumer == nil || (len(g.pending) > 0 && pe.ID.Compare(g.pending[len(g.pending)-1].ID) <= 0) {
+				return ErrCorruptEncoding
+			}
+			g.pending = append(g.pending, pe)
+			pe.Consumer.pending = append(pe.Consumer.pending, pe)
+		}
+	}
+
+	if err := r.finish(); err != nil {
+		return err
+	}
+
+	decoded.lastID = lastID
+	*s = *decoded
+	return nil
+}
+
+func streamNodeSize() int64 {
+	return SliceHeaderSize + Int64Size
+}
+
+func streamEntrySize(entry StreamEntry) int64 {
+	size := 2*Uint64Size + SliceHeaderSize
+	for _, field := range entry.Fields {
+		size += StringSize(field)
+	}
+	return size
+}
@@ -0,0 +1,516 @@
+package types
+
+import (
+	"slices"
+	"sort"
+)
+
+// StreamPendingEntry is an entry delivered to a consumer of a group and not acknowledged yet.
+type StreamPendingEntry struct {
+	ID            StreamID
+	Consumer      *StreamConsumer
+	DeliveryTime  int64 // unix time in milliseconds of the last delivery
+	DeliveryCount uint64
+}
+
+// Idle returns the milliseconds elapsed since the last delivery.
+func (pe *StreamPendingEntry) Idle(nowMs int64) int64 {
+	return max(nowMs-pe.DeliveryTime, 0)
+}
+
+// StreamConsumer is a member of a consumer group, it owns the entries delivered to it.
+type StreamConsumer struct {
+	Name       string
+	SeenTime   int64 // unix time in milliseconds of the last attempted interaction
+	ActiveTime int64 // unix time in milliseconds of the last successful interaction, -1 if none
+	pending    pendingList
+}
+
+// Pending returns the entries of the consumer that are not acknowledged, ordered by ID.
+func (c *StreamConsumer) Pending() []*StreamPendingEntry {
+	return c.pending
+}
+
+// StreamGroup is a consumer group of a stream. Every entry added after LastID is
+// delivered to exactly one of its consumers.
+type StreamGroup struct {
+	Name        string
+	LastID      StreamID // ID of the last entry delivered to the group
+	EntriesRead int64    // logical position of LastID in the stream, -1 when unknown
+	pending     pendingList
+	consumers   map[string]*StreamConsumer
+}
+
+func newStreamGroup(name string, lastID StreamID, entriesRead int64) *StreamGroup {
+	return &StreamGroup{
+		Name:        name,
+		LastID:      lastID,
+		EntriesRead: entriesRead,
+		consumers:   make(map[string]*StreamConsumer),
+	}
+}
+
+func (g *StreamGroup) Consumer(name string) *StreamConsumer {
+	return g.consumers[name]
+}
+
+// CreateConsumer returns the consumer called name, creating it when it does not exist.
+func (g *StreamGroup) CreateConsumer(name string, nowMs int64) (*StreamConsumer, bool, int64) {
+	if c, ok := g.consumers[name]; ok {
+		return c, false, 0
+	}
+
+	c := &StreamConsumer{Name: name, SeenTime: nowMs, ActiveTime: -1}
+	g.consumers[name] = c
+	return c, true, streamConsumerSize(name)
+}
+
+// DeleteConsumer removes a consumer with its pending entries and returns how many it had.
+func (g *StreamGroup) DeleteConsumer(name string) (int64, bool, int64) {
+	c, ok := g.consumers[name]
+	if !ok {
+		return 0, false, 0
+	}
+
+	delta := -streamConsumerSize(name)
+	for _, pe := range c.pending {
+		g.pending.remove(pe.ID)
+		delta -= streamPendingEntrySize()
+	}
+
+	delete(g.consumers, name)
+	return int64(len(c.pending)), true, delta
+}
+
+// Consumers returns the consumers ordered by name.
+func (g *StreamGroup) Consumers() []*StreamConsumer {
+	consumers := make([]*StreamConsumer, 0, len(g.consumers))
+	for _, c := range g.consumers {
+		consumers = append(consumers, c)
+	}
+	sort.Slice(consumers, func(i, j int) bool {
+		return consumers[i].Name < consumers[j].Name
+	})
+	return consumers
+}
+
+// PendingCount returns the number of entries delivered to the group and not acknowledged yet.
+func (g *StreamGroup) PendingCount() int {
+	return len(g.pending)
+}
+
+// Pending returns up to count pending entries with IDs between start and end inclusive,
+// only those of consumer unless it is nil, and only those idle for at least minIdle
+// milliseconds. A count of zero or less returns every such entry.
+func (g *StreamGroup) Pending(start, end StreamID, count int, consumer *StreamConsumer, minIdle, nowMs int64) []*StreamPendingEntry {
+	list := g.pending
+	if consumer != nil {
+		list = consumer.pending
+	}
+
+	result := make([]*StreamPendingEntry, 0)
+	for _, pe := range list[list.seek(start):] {
+		if pe.ID.Compare(end) > 0 || (count > 0 && len(result) == count) {
+			break
+		}
+		if pe.Idle(nowMs) >= minIdle {
+			result = append(result, pe)
+		}
+	}
+	return result
+}
+
+// Ack acknowledges the pending entries with the given IDs and returns how many there were.
+func (g *StreamGroup) Ack(ids []StreamID) (int64, int64) {
+	var acked, delta int64
+	for _, id := range ids {
+		pe := g.pending.get(id)
+		if pe == nil {
+			continue
+		}
+
+		delta += g.removePending(pe)
+		acked++
+	}
+	return acked, delta
+}
+
+// deliver makes consumer the owner of id, which becomes pending if it was not already.
+func (g *StreamGroup) deliver(id StreamID, consumer *StreamConsumer, nowMs int64) int64 {
+	if pe := g.pending.get(id); pe != nil {
+		g.transfer(pe, consumer)
+		pe.DeliveryTime = nowMs
+		pe.DeliveryCount = 1
+		return 0
+	}
+
+	pe := &StreamPendingEntry{ID: id, Consumer: consumer, DeliveryTime: nowMs, DeliveryCount: 1}
+	g.pending.insert(pe)
+	consumer.pending.insert(pe)
+	return streamPendingEntrySize()
+}
+
+// transfer moves a pending entry to the list of another consumer.
+func (g *StreamGroup) transfer(pe *StreamPendingEntry, consumer *StreamConsumer) {
+	if pe.Consumer == consumer {
+		return
+	}
+
+	pe.Consumer.pending.remove(pe.ID)
+	pe.Consumer = consumer
+	consumer.pending.insert(pe)
+}
+
+func (g *StreamGroup) removePending(pe *StreamPendingEntry) int64 {
+	g.pending.remove(pe.ID)
+	pe.Consumer.pending.remove(pe.ID)
+	return -streamPendingEntrySize()
+}
+
+// pendingList is a list of pending entries ordered by ID.
+type pendingList []*StreamPendingEntry
+
+// seek returns the index of the first entry whose ID is not smaller than id.
+func (l pendingList) seek(id StreamID) int {
+	return sort.Search(len(l), func(i int) bool {
+		return l[i].ID.Compare(id) >= 0
+	})
+}
+
+func (l pendingList) get(id StreamID) *StreamPendingEntry {
+	if i := l.seek(id); i < len(l) && l[i].ID == id {
+		return l[i]
+	}
+	return nil
+}
+
+// insert adds an entry, entries are mostly delivered in ID order so this usually appends.
+func (l *pendingList) insert(pe *StreamPendingEntry) {
+	if n := len(*l); n == 0 || (*l)[n-1].ID.Compare(pe.ID) < 0 {
+		*l = append(*l, pe)
+		return
+	}
+	*l = slices.Insert(*l, l.seek(pe.ID), pe)
+}
+
+func (l *pendingList) remove(id StreamID) {
+	if i := l.seek(id); i < len(*l) && (*l)[i].ID == id {
+		*l = slices.Delete(*l, i, i+1)
+	}
+}
+
+// StreamClaimOptions describes the options of XCLAIM and XAUTOCLAIM.
+type StreamClaimOptions struct {
+	MinIdle      int64  // only entries idle for at least this many milliseconds are claimed
+	DeliveryTime int64  // new delivery time of the claimed entries, in unix milliseconds
+	RetryCount   *int64 // new delivery count, incremented unless JustID when nil
+	Force        bool   // IDs that are not pending are created, if they exist in the stream
+	JustID       bool
+	LastID       *StreamID // new last delivered ID of the group, if greater
+}
+
+// CreateGroup adds a consumer group, it returns nil when the name is already taken.
+func (s *stream) CreateGroup(name string, lastID StreamID, entriesRead int64) (*StreamGroup, int64) {
+	if _, ok := s.groups[name]; ok {
+		return nil, 0
+	}
+
+	if s.groups == nil {
+		s.groups = make(map[string]*StreamGroup)
+	}
+
+	g := newStreamGroup(name, lastID, entriesRead)
+	s.groups[name] = g
+	return g, streamGroupSize(name)
+}
+
+func (s *stream) Group(name string) *StreamGroup {
+	return s.groups[name]
+}
+
+// Groups returns the consumer groups ordered by name.
+func (s *stream) Groups() []*StreamGroup {
+	groups := make([]*StreamGroup, 0, len(s.groups))
+	for _, g := range s.groups {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+func (s *stream) DestroyGroup(name string) (bool, int64) {
+	g, ok := s.groups[name]
+	if !ok {
+		return false, 0
+	}
+
+	delete(s.groups, name)
+	return true, -streamGroupMemoryUsage(g)
+}
+
+// ReadGroup delivers up to count entries added after the last delivered ID of the group
+// to consumer. Unless noAck is set, they stay pending until acknowledged.
+func (s *stream) ReadGroup(g *StreamGroup, consumer *StreamConsumer, count int, noAck bool, nowMs int64) ([]StreamEntry, int64) {
+	consumer.SeenTime = nowMs
+	start, ok := g.LastID.Incr()
+	if !ok {
+		return []StreamEntry{}, 0
+	}
+
+	var delta int64
+	entries := s.Range(start, MaxStreamID, count, false)
+	for _, entry := range entries {
+		if g.EntriesRead != -1 && !s.hasTombstones(entry.ID) {
+			g.EntriesRead++
+		} else if s.entriesAdded > 0 {
+			g.EntriesRead = s.estimateEntriesRead(entry.ID)
+		}
+		g.LastID = entry.ID
+
+		if !noAck {
+			delta += g.deliver(entry.ID, consumer, nowMs)
+		}
+	}
+
+	if len(entries) > 0 {
+		consumer.ActiveTime = nowMs
+	}
+	return entries, delta
+}
+
+// ReadPending delivers again up to count pending entries of consumer with IDs greater than
+// after. Entries deleted from the stream since are returned with nil fields.
+func (s *stream) ReadPending(consumer *StreamConsumer, after StreamID, count int, nowMs int64) []StreamEntry {
+	consumer.SeenTime = nowMs
+	entries := make([]StreamEntry, 0)
+	start, ok := after.Incr()
+	if !ok {
+		return entries
+	}
+
+	for _, pe := range consumer.pending[consumer.pending.seek(start):] {
+		if count > 0 && len(entries) == count {
+			break
+		}
+
+		pe.DeliveryTime = nowMs
+		pe.DeliveryCount++
+
+		entry, _ := s.entry(pe.ID)
+		entry.ID = pe.ID
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Claim transfers the pending entries with the given IDs to consumer. Pending entries
+// that were deleted from the stream are acknowledged instead of claimed.
+func (s *stream) Claim(g *StreamGroup, consumer *StreamConsumer, ids []StreamID, options StreamClaimOptions, nowMs int64) ([]StreamEntry, int64) {
+	consumer.SeenTime = nowMs
+	if options.LastID != nil && options.LastID.Compare(g.LastID) > 0 {
+		g.LastID = *options.LastID
+	}
+
+	var delta int64
+	claimed := make([]StreamEntry, 0)
+	for _, id := range ids {
+		pe := g.pending.get(id)
+		if pe == nil {
+			if !options.Force {
+				continue
+			}
+			if _, exists := s.entry(id); !exists {
+				continue
+			}
+			delta += g.deliver(id, consumer, nowMs)
+			pe = g.pending.get(id)
+		}
+
+		if options.MinIdle > 0 && pe.Idle(nowMs) < options.MinIdle {
+			continue
+		}
+
+		entry, exists := s.entry(id)
+		if !exists {
+			delta += g.removePending(pe)
+			continue
+		}
+
+		s.claim(g, pe, consumer, options.DeliveryTime, options.RetryCount, options.JustID)
+		claimed = append(claimed, entry)
+	}
+
+	if len(claimed) > 0 {
+		consumer.ActiveTime = nowMs
+	}
+	return claimed, delta
+}
+
+// AutoClaim scans the pending entries of the group from start, and transfers to consumer
+// up to count of them that were idle for at least MinIdle. At most ten times count entries
+// are scanned, the returned cursor is where the next call should resume, 0-0 at the end.
+// It also returns the IDs of the pending entries that were deleted from the stream, which
+// are dropped from the group and count towards the limit like the claimed ones.
+func (s *stream) AutoClaim(g *StreamGroup, consumer *StreamConsumer, start StreamID, count int, options StreamClaimOptions,
+	nowMs int64) (StreamID, []StreamEntry, []StreamID, int64) {
+	consumer.SeenTime = nowMs
+
+	var delta int64
+	claimed := make([]StreamEntry, 0)
+	deleted := make([]StreamID, 0)
+	attempts := 10 * count
+
+	i := g.pending.seek(start)
+	for ; i < len(g.pending) && attempts > 0 && len(claimed)+len(deleted) < count; attempts-- {
+		pe := g.pending[i]
+		if pe.Idle(nowMs) < options.MinIdle {
+			i++
+			continue
+		}
+
+		entry, exists := s.entry(pe.ID)
+		if !exists {
+			deleted = append(deleted, pe.ID)
+			delta += g.removePending(pe)
+			continue
+		}
+
+		s.claim(g, pe, consumer, options.DeliveryTime, nil, options.JustID)
+		claimed = append(claimed, entry)
+		i++
+	}
+
+	next := MinStreamID
+	if i < len(g.pending) {
+		next = g.pending[i].ID
+	}
+
+	if len(claimed) > 0 {
+		consumer.ActiveTime = nowMs
+	}
+	return next, claimed, deleted, delta
+}
+
+func (s *stream) claim(g *StreamGroup, pe *StreamPendingEntry, consumer *StreamConsumer, deliveryTime int64, retryCount *int64, justID bool) {
+	g.transfer(pe, consumer)
+	pe.DeliveryTime = deliveryTime
+	switch {
+	case retryCount != nil:
+		pe.DeliveryCount = uint64(*retryCount)
+	case !justID:
+		pe.DeliveryCount++
+	}
+}
+
+// Lag returns the number of entries in the stream that were not delivered to the group yet,
+// false when it cannot be computed because of deleted entries.
+func (s *stream) Lag(g *StreamGroup) (int64, bool) {
+	if s.entriesAdded == 0 {
+		return 0, true
+	}
+
+	if g.EntriesRead != -1 && !s.hasTombstones(g.LastID) {
+		return int64(s.entriesAdded) - g.EntriesRead, true
+	}
+
+	if entriesRead := s.estimateEntriesRead(g.LastID); entriesRead != -1 {
+		return int64(s.entriesAdded) - entriesRead, true
+	}
+	return 0, false
+}
+
+// hasTombstones reports whether entries between start and the last ID may have been deleted,
+// which makes counting entries from start unreliable.
+func (s *stream) hasTombstones(start StreamID) bool {
+	if s.length == 0 || s.maxDeletedID == MinStreamID {
+		return false
+	}
+	return start.Compare(s.maxDeletedID) <= 0 && s.maxDeletedID.Compare(s.lastID) <= 0
+}
+
+// estimateEntriesRead returns how many entries were added to the stream up to id included,
+// -1 when deleted entries make it unknown.
+func (s *stream) estimateEntriesRead(id StreamID) int64 {
+	if s.entriesAdded == 0 {
+		return 0
+	}
+
+	if s.length == 0 && id.Compare(s.lastID) <= 0 {
+		return int64(s.entriesAdded)
+	}
+
+	switch c := id.Compare(s.lastID); {
+	case c == 0:
+		return int64(s.entriesAdded)
+	case c > 0:
+		return -1
+	}
+
+	firstID := s.nodes[0].entries[0].ID
+	if s.maxDeletedID == MinStreamID || s.maxDeletedID.Compare(firstID) < 0 {
+		switch c := id.Compare(firstID); {
+		case c < 0:
+			return int64(s.entriesAdded) - int64(s.length)
+		case c == 0:
+			return int64(s.entriesAdded) - int64(s.length) + 1
+		}
+	}
+	return -1
+}
+
+// entry returns the entry with the given ID, if it was not deleted.
+func (s *stream) entry(id StreamID) (StreamEntry, bool) {
+	nodeIdx, entryIdx := s.seek(id)
+	if nodeIdx == len(s.nodes) || s.nodes[nodeIdx].entries[entryIdx].ID != id {
+		return StreamEntry{}, false
+	}
+	return s.nodes[nodeIdx].entries[entryIdx], true
+}
+
+func streamGroupMemoryUsage(g *StreamGroup) int64 {
+	usage := streamGroupSize(g.Name) + int64(len(g.pending))*streamPendingEntrySize()
+	for name := range g.consumers {
+		usage += streamConsumerSize(name)
+	}
+	return usage
+}
+
+func streamGroupSize(name string) int64 {
+	return StringSize(name) + MapOverheadPerKey + PointerSize + 2*Uint64Size + Int64Size + SliceHeaderSize
+}
+
+func streamConsumerSize(name string) int64 {
+	return StringSize(name) + MapOverheadPerKey + PointerSize + 2*Int64Size + SliceHeaderSize
+}
+
+// A pending entry is referenced by both the group and its consumer
+func streamPendingEntrySize() int64 {
+	return 2*PointerSize + 2*Uint64Size + PointerSize + Int64Size + Uint64Size
+}
+
+// StreamGroupInfo describes a consumer group for XINFO.
+type StreamGroupInfo struct {
+	*StreamGroup
+	Lag      int64
+	LagKnown bool
+}
+
+// StreamInfo describes a stream for XINFO STREAM.
+type StreamInfo struct {
+	Length       uint32
+	Nodes        int
+	LastID       StreamID
+	MaxDeletedID StreamID
+	EntriesAdded uint64
+	FirstID      StreamID // ID of the first entry, 0-0 when the stream is empty
+	FirstEntry   *StreamEntry
+	LastEntry    *StreamEntry
+	Groups       []StreamGroupInfo
+}
+
+// ConsumerCount returns the number of consumers of the group.
+func (g *StreamGroup) ConsumerCount() int {
+	return len(g.consumers)
+}
@@ -0,0 +1,282 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pendingIDs(pending []*StreamPendingEntry) []string {
+	result := make([]string, len(pending))
+	for i, pe := range pending {
+		result[i] = pe.ID.String()
+	}
+	return result
+}
+
+func newTestGroup(t *testing.T, entries int) (Stream, *StreamGroup) {
+	s := NewStream()
+	addEntries(t, s, entries)
+	g, _ := s.CreateGroup("g", MinStreamID, 0)
+	require.NotNil(t, g)
+	return s, g
+}
+
+func TestStream_CreateGroup(t *testing.T) {
+	s, g := newTestGroup(t, 0)
+
+	dup, delta := s.CreateGroup("g", MinStreamID, 0)
+	assert.Nil(t, dup)
+	assert.Zero(t, delta)
+	assert.Same(t, g, s.Group("g"))
+
+	s.CreateGroup("a", MinStreamID, -1)
+	groups := s.Groups()
+	require.Len(t, groups, 2)
+	assert.Equal(t, "a", groups[0].Name)
+
+	destroyed, _ := s.DestroyGroup("g")
+	assert.True(t, destroyed)
+	assert.Nil(t, s.Group("g"))
+	destroyed, _ = s.DestroyGroup("g")
+	assert.False(t, destroyed)
+}
+
+func TestStream_ReadGroupDeliversNewEntries(t *testing.T) {
+	s, g := newTestGroup(t, 3)
+	alice, _, _ := g.CreateConsumer("alice", 100)
+	bob, _, _ := g.CreateConsumer("bob", 100)
+
+	entries, _ := s.ReadGroup(g, alice, 2, false, 200)
+	assert.Equal(t, []string{"1-0", "1-1"}, ids(entries))
+	entries, _ = s.ReadGroup(g, bob, 0, false, 300)
+	assert.Equal(t, []string{"1-2"}, ids(entries))
+	entries, _ = s.ReadGroup(g, bob, 0, false, 300)
+	assert.Empty(t, entries)
+
+	assert.Equal(t, StreamID{1, 2}, g.LastID)
+	assert.Equal(t, int64(3), g.EntriesRead)
+	assert.Equal(t, 3, g.PendingCount())
+	assert.Equal(t, []string{"1-0", "1-1"}, pendingIDs(alice.Pending()))
+	assert.Equal(t, int64(200), alice.ActiveTime)
+
+	pe := g.Pending(MinStreamID, MaxStreamID, 0, bob, 0, 350)
+	require.Len(t, pe, 1)
+	assert.Equal(t, int64(50), pe[0].Idle(350))
+	assert.Equal(t, uint64(1), pe[0].DeliveryCount)
+
+	acked, _ := g.Ack([]StreamID{{1, 0}, {1, 2}, {9, 9}})
+	assert.Equal(t, int64(2), acked)
+	assert.Equal(t, []string{"1-1"}, pendingIDs(g.Pending(MinStreamID, MaxStreamID, 0, nil, 0, 0)))
+	assert.Empty(t, bob.Pending())
+}
+
+func TestStream_ReadGroupNoAck(t *testing.T) {
+	s, g := newTestGroup(t, 2)
+	c, _, _ := g.CreateConsumer("c", 0)
+
+	entries, _ := s.ReadGroup(g, c, 0, true, 0)
+	assert.Len(t, entries, 2)
+	assert.Zero(t, g.PendingCount())
+	assert.Equal(t, StreamID{1, 1}, g.LastID)
+}
+
+func TestStream_ReadPending(t *testing.T) {
+	s, g := newTestGroup(t, 3)
+	c, _, _ := g.CreateConsumer("c", 0)
+	s.ReadGroup(g, c, 0, false, 0)
+	s.Delete([]StreamID{{1, 1}})
+
+	entries := s.ReadPending(c, MinStreamID, 0, 10)
+	require.Len(t, entries, 3)
+	assert.Equal(t, StreamID{1, 1}, entries[1].ID)
+	assert.Nil(t, entries[1].Fields)
+	assert.Equal(t, []string{"f", "v"}, entries[2].Fields)
+
+	entries = s.ReadPending(c, StreamID{1, 0}, 1, 10)
+	assert.Equal(t, []string{"1-1"}, ids(entries))
+
+	pe := c.Pending()[1]
+	assert.Equal(t, uint64(3), pe.DeliveryCount)
+	assert.Equal(t, int64(10), pe.DeliveryTime)
+}
+
+func TestStream_Claim(t *testing.T) {
+	s, g := newTestGroup(t, 3)
+	alice, _, _ := g.CreateConsumer("alice", 0)
+	bob, _, _ := g.CreateConsumer("bob", 0)
+	s.ReadGroup(g, alice, 2, false, 0)
+
+	// Not idle for long enough
+	claimed, _ := s.Claim(g, bob, []StreamID{{1, 0}}, StreamClaimOptions{MinIdle: 100, DeliveryTime: 50}, 50)
+	assert.Empty(t, claimed)
+
+	claimed, _ = s.Claim(g, bob, []StreamID{{1, 0}, {1, 2}}, StreamClaimOptions{MinIdle: 100, DeliveryTime: 150}, 150)
+	assert.Equal(t, []string{"1-0"}, ids(claimed))
+	assert.Equal(t, []string{"1-1"}, pendingIDs(alice.Pending()))
+	pe := bob.Pending()[0]
+	assert.Equal(t, uint64(2), pe.DeliveryCount)
+	assert.Equal(t, int64(150), pe.DeliveryTime)
+
+	justID := StreamClaimOptions{JustID: true, DeliveryTime: 200}
+	s.Claim(g, alice, []StreamID{{1, 0}}, justID, 200)
+	assert.Equal(t, uint64(2), alice.Pending()[0].DeliveryCount)
+
+	retryCount := int64(7)
+	s.Claim(g, alice, []StreamID{{1, 0}}, StreamClaimOptions{RetryCount: &retryCount}, 200)
+	assert.Equal(t, uint64(7), alice.Pending()[0].DeliveryCount)
+
+	// FORCE creates pending entries for IDs that exist in the stream only
+	lastID := StreamID{5, 0}
+	claimed, _ = s.Claim(g, bob, []StreamID{{1, 2}, {3, 0}}, StreamClaimOptions{Force: true, LastID: &lastID}, 300)
+	assert.Equal(t, []string{"1-2"}, ids(claimed))
+	assert.Equal(t, 3, g.PendingCount())
+	assert.Equal(t, lastID, g.LastID)
+
+	// Deleted entries are acknowledged instead
+	s.Delete([]StreamID{{1, 2}})
+	claimed, _ = s.Claim(g, alice, []StreamID{{1, 2}}, StreamClaimOptions{}, 300)
+	assert.Empty(t, claimed)
+	assert.Equal(t, 2, g.PendingCount())
+	assert.Empty(t, bob.Pending())
+}
+
+func TestStream_AutoClaim(t *testing.T) {
+	s, g := newTestGroup(t, 5)
+	alice, _, _ := g.CreateConsumer("alice", 0)
+	bob, _, _ := g.CreateConsumer("bob", 0)
+	s.ReadGroup(g, alice, 0, false, 0)
+	s.Delete([]StreamID{{1, 1}})
+
+	options := StreamClaimOptions{MinIdle: 10, DeliveryTime: 100}
+	// Deleted entries count towards the limit as well
+	next, claimed, deleted, _ := s.AutoClaim(g, bob, MinStreamID, 3, options, 100)
+	assert.Equal(t, StreamID{1, 3}, next)
+	assert.Equal(t, []string{"1-0", "1-2"}, ids(claimed))
+	assert.Equal(t, []StreamID{{1, 1}}, deleted)
+	assert.Equal(t, []string{"1-0", "1-2"}, pendingIDs(bob.Pending()))
+
+	next, claimed, _, _ = s.AutoClaim(g, bob, next, 10, options, 100)
+	assert.Equal(t, MinStreamID, next)
+	assert.Equal(t, []string{"1-3", "1-4"}, ids(claimed))
+	assert.Empty(t, alice.Pending())
+
+	// Entries claimed just now are not idle anymore
+	_, claimed, _, _ = s.AutoClaim(g, alice, MinStreamID, 10, options, 105)
+	assert.Empty(t, claimed)
+}
+
+func TestStream_Lag(t *testing.T) {
+	s, g := newTestGroup(t, 4)
+	c, _, _ := g.CreateConsumer("c", 0)
+
+	lag, known := s.Lag(g)
+	assert.True(t, known)
+	assert.Equal(t, int64(4), lag)
+
+	s.ReadGroup(g, c, 1, false, 0)
+	lag, _ = s.Lag(g)
+	assert.Equal(t, int64(3), lag)
+
+	// A deletion after the last delivered ID makes the lag unknown
+	s.Delete([]StreamID{{1, 2}})
+	_, known = s.Lag(g)
+	assert.False(t, known)
+
+	s.ReadGroup(g, c, 0, false, 0)
+	lag, known = s.Lag(g)
+	assert.True(t, known)
+	assert.Zero(t, lag)
+	assert.Equal(t, int64(4), g.EntriesRead)
+}
+
+func TestStreamGroup_DeleteConsumer(t *testing.T) {
+	s, g := newTestGroup(t, 3)
+	alice, created, _ := g.CreateConsumer("alice", 0)
+	assert.True(t, created)
+	_, created, _ = g.CreateConsumer("alice", 0)
+	assert.False(t, created)
+	bob, _, _ := g.CreateConsumer("bob", 0)
+
+	s.ReadGroup(g, alice, 2, false, 0)
+	s.ReadGroup(g, bob, 0, false, 0)
+
+	pending, deleted, _ := g.DeleteConsumer("alice")
+	assert.True(t, deleted)
+	assert.Equal(t, int64(2), pending)
+	assert.Equal(t, []string{"1-2"}, pendingIDs(g.Pending(MinStreamID, MaxStreamID, 0, nil, 0, 0)))
+	assert.Equal(t, 1, g.ConsumerCount())
+	assert.Equal(t, "bob", g.Consumers()[0].Name)
+
+	_, deleted, _ = g.DeleteConsumer("alice")
+	assert.False(t, deleted)
+}
+
+func TestStreamGroup_MemoryUsageTracksDeltas(t *testing.T) {
+	s := NewStream()
+	addEntries(t, s, 10)
+	usage := s.MemoryUsage()
+
+	g, delta := s.CreateGroup("g", MinStreamID, 0)
+	usage += delta
+	alice, _, delta := g.CreateConsumer("alice", 0)
+	usage += delta
+	bob, _, delta := g.CreateConsumer("bob", 0)
+	usage += delta
+	_, delta = s.ReadGroup(g, alice, 6, false, 0)
+	usage += delta
+	_, delta = s.ReadGroup(g, bob, 0, false, 0)
+	usage += delta
+	assert.Equal(t, s.MemoryUsage(), usage)
+
+	_, delta = g.Ack([]StreamID{{1, 0}, {1, 7}})
+	usage += delta
+	_, _, delta = g.DeleteConsumer("bob")
+	usage += delta
+	_, delta = s.Delete([]StreamID{{1, 1}})
+	usage += delta
+	_, delta = s.Claim(g, alice, []StreamID{{1, 1}}, StreamClaimOptions{}, 0)
+	usage += delta
+	assert.Equal(t, s.MemoryUsage(), usage)
+
+	_, delta = s.DestroyGroup("g")
+	usage += delta
+	assert.Equal(t, s.MemoryUsage(), usage)
+}
+
+func TestStreamGroup_MarshalBinary(t *testing.T) {
+	s, g := newTestGroup(t, 5)
+	alice, _, _ := g.CreateConsumer("alice", 10)
+	g.CreateConsumer("idle", 20)
+	s.ReadGroup(g, alice, 3, false, 30)
+	s.Delete([]StreamID{{1, 4}})
+	s.CreateGroup("unread", StreamID{1, 1}, -1)
+
+	data, err := s.MarshalBinary()
+	require.NoError(t, err)
+
+	decoded := NewStream()
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, uint64(5), decoded.EntriesAdded())
+	assert.Equal(t, StreamID{1, 4}, decoded.MaxDeletedID())
+
+	groups := decoded.Groups()
+	require.Len(t, groups, 2)
+	assert.Equal(t, int64(-1), groups[1].EntriesRead)
+	assert.Equal(t, StreamID{1, 1}, groups[1].LastID)
+
+	dg := groups[0]
+	assert.Equal(t, g.LastID, dg.LastID)
+	assert.Equal(t, int64(3), dg.EntriesRead)
+	assert.Equal(t, []string{"1-0", "1-1", "1-2"}, pendingIDs(dg.Pending(MinStreamID, MaxStreamID, 0, nil, 0, 0)))
+
+	consumers := dg.Consumers()
+	require.Len(t, consumers, 2)
+	assert.Equal(t, "alice", consumers[0].Name)
+	assert.Equal(t, int64(30), consumers[0].SeenTime)
+	assert.Equal(t, int64(-1), consumers[1].ActiveTime)
+	assert.Len(t, consumers[0].Pending(), 3)
+	assert.Same(t, consumers[0], consumers[0].Pending()[0].Consumer)
+	assert.Equal(t, s.MemoryUsage(), decoded.MemoryUsage())
+}
@@ -0,0 +1,196 @@
+package types
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addEntries(t *testing.T, s Stream, n int) {
+	for i := 0; i < n; i++ {
+		id, err := s.NextID(StreamAddOptions{AutoSeq: true, ID: StreamID{Ms: 1}}, 0)
+		require.NoError(t, err)
+		s.Add(id, []string{"f", "v"})
+	}
+}
+
+func ids(entries []StreamEntry) []string {
+	result := make([]string, len(entries))
+	for i, entry := range entries {
+		result[i] = entry.ID.String()
+	}
+	return result
+}
+
+func TestParseStreamID(t *testing.T) {
+	id, err := ParseStreamID("5-3", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, StreamID{5, 3}, id)
+
+	id, err = ParseStreamID("5", math.MaxUint64)
+	assert.NoError(t, err)
+	assert.Equal(t, StreamID{5, math.MaxUint64}, id)
+
+	for _, invalid := range []string{"", "-", "a-1", "1-a", "1-", "-1", "1-2-3"} {
+		_, err = ParseStreamID(invalid, 0)
+		assert.ErrorIs(t, err, ErrInvalidStreamID, invalid)
+	}
+}
+
+func TestStreamID_IncrDecr(t *testing.T) {
+	next, ok := StreamID{1, math.MaxUint64}.Incr()
+	assert.True(t, ok)
+	assert.Equal(t, StreamID{2, 0}, next)
+
+	_, ok = MaxStreamID.Incr()
+	assert.False(t, ok)
+
+	prev, ok := StreamID{2, 0}.Decr()
+	assert.True(t, ok)
+	assert.Equal(t, StreamID{1, math.MaxUint64}, prev)
+
+	_, ok = MinStreamID.Decr()
+	assert.False(t, ok)
+}
+
+func TestStream_NextID(t *testing.T) {
+	s := NewStream()
+
+	_, err := s.NextID(StreamAddOptions{ID: MinStreamID}, 0)
+	assert.ErrorIs(t, err, ErrStreamIDZero)
+
+	// 0-* never generates 0-0
+	id, err := s.NextID(StreamAddOptions{AutoSeq: true}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, StreamID{0, 1}, id)
+
+	id, err = s.NextID(StreamAddOptions{AutoID: true}, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, StreamID{1000, 0}, id)
+	s.Add(id, []string{"f", "v"})
+
+	// A clock that went backwards keeps IDs increasing
+	id, err = s.NextID(StreamAddOptions{AutoID: true}, 900)
+	assert.NoError(t, err)
+	assert.Equal(t, StreamID{1000, 1}, id)
+
+	id, err = s.NextID(StreamAddOptions{AutoSeq: true, ID: StreamID{Ms: 1000}}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, StreamID{1000, 1}, id)
+
+	_, err = s.NextID(StreamAddOptions{AutoSeq: true, ID: StreamID{Ms: 999}}, 0)
+	assert.ErrorIs(t, err, ErrStreamIDTooSmall)
+
+	_, err = s.NextID(StreamAddOptions{ID: StreamID{1000, 0}}, 0)
+	assert.ErrorIs(t, err, ErrStreamIDTooSmall)
+
+	s.Add(MaxStreamID, []string{"f", "v"})
+	_, err = s.NextID(StreamAddOptions{AutoID: true}, 0)
+	assert.ErrorIs(t, err, ErrStreamIDExhausted)
+}
+
+func TestStream_Range(t *testing.T) {
+	s := NewStream()
+	addEntries(t, s, 250)
+	assert.Equal(t, uint32(250), s.Len())
+
+	all := s.Range(MinStreamID, MaxStreamID, 0, false)
+	require.Len(t, all, 250)
+	assert.Equal(t, "1-0", all[0].ID.String())
+	assert.Equal(t, "1-249", all[249].ID.String())
+
+	assert.Equal(t, []string{"1-99", "1-100", "1-101"}, ids(s.Range(StreamID{1, 99}, StreamID{1, 101}, 0, false)))
+	assert.Equal(t, []string{"1-101", "1-100"}, ids(s.Range(StreamID{1, 99}, StreamID{1, 101}, 2, true)))
+	assert.Equal(t, []string{"1-249", "1-248"}, ids(s.Range(MinStreamID, MaxStreamID, 2, true)))
+	assert.Empty(t, s.Range(StreamID{1, 5}, StreamID{1, 4}, 0, false))
+	assert.Empty(t, s.Range(StreamID{2, 0}, MaxStreamID, 0, false))
+	assert.Empty(t, s.Range(MinStreamID, StreamID{0, 5}, 0, true))
+}
+
+func TestStream_Delete(t *testing.T) {
+	s := NewStream()
+	addEntries(t, s, 3)
+
+	deleted, delta := s.Delete([]StreamID{{1, 1}, {1, 1}, {7, 0}})
+	assert.Equal(t, int64(1), deleted)
+	assert.Negative(t, delta)
+	assert.Equal(t, []string{"1-0", "1-2"}, ids(s.Range(MinStreamID, MaxStreamID, 0, false)))
+
+	// The last ID is kept, even once the entry holding it is gone
+	s.Delete([]StreamID{{1, 0}, {1, 2}})
+	assert.Equal(t, uint32(0), s.Len())
+	assert.Equal(t, StreamID{1, 2}, s.LastID())
+	_, err := s.NextID(StreamAddOptions{ID: StreamID{1, 2}}, 0)
+	assert.ErrorIs(t, err, ErrStreamIDTooSmall)
+}
+
+func TestStream_TrimMaxLen(t *testing.T) {
+	s := NewStream()
+	addEntries(t, s, 250)
+
+	// Approximate trimming keeps the node that would have to be split
+	removed, _ := s.Trim(StreamTrimOptions{Strategy: StreamTrimMaxLen, MaxLen: 120, Approx: true})
+	assert.Equal(t, int64(100), removed)
+	assert.Equal(t, uint32(150), s.Len())
+
+	removed, _ = s.Trim(StreamTrimOptions{Strategy: StreamTrimMaxLen, MaxLen: 120})
+	assert.Equal(t, int64(30), removed)
+	assert.Equal(t, uint32(120), s.Len())
+	assert.Equal(t, "1-130", s.Range(MinStreamID, MaxStreamID, 1, false)[0].ID.String())
+}
+
+func TestStream_TrimMinID(t *testing.T) {
+	s := NewStream()
+	addEntries(t, s, 250)
+
+	removed, _ := s.Trim(StreamTrimOptions{Strategy: StreamTrimMinID, MinID: StreamID{1, 150}, Approx: true})
+	assert.Equal(t, int64(100), removed)
+
+	removed, _ = s.Trim(StreamTrimOptions{Strategy: StreamTrimMinID, MinID: StreamID{1, 150}})
+	assert.Equal(t, int64(50), removed)
+	assert.Equal(t, "1-150", s.Range(MinStreamID, MaxStreamID, 1, false)[0].ID.String())
+}
+
+func TestStream_TrimApproxLimit(t *testing.T) {
+	s := NewStream()
+	addEntries(t, s, 250)
+
+	removed, _ := s.Trim(StreamTrimOptions{Strategy: StreamTrimMaxLen, MaxLen: 0, Approx: true, Limit: 150})
+	assert.Equal(t, int64(100), removed)
+	assert.Equal(t, uint32(150), s.Len())
+}
+
+func TestStream_MemoryUsageTracksDeltas(t *testing.T) {
+	s := NewStream()
+	usage := s.MemoryUsage()
+
+	for i := 0; i < 150; i++ {
+		id, _ := s.NextID(StreamAddOptions{AutoID: true}, 1)
+		usage += s.Add(id, []string{"field", "value"})
+	}
+	assert.Equal(t, s.MemoryUsage(), usage)
+
+	_, delta := s.Delete([]StreamID{{1, 3}})
+	usage += delta
+	_, delta = s.Trim(StreamTrimOptions{Strategy: StreamTrimMaxLen, MaxLen: 20})
+	usage += delta
+	assert.Equal(t, s.MemoryUsage(), usage)
+}
+
+func TestStream_MarshalBinary(t *testing.T) {
+	s := NewStream()
+	addEntries(t, s, 120)
+	s.Delete([]StreamID{{1, 119}, {1, 5}})
+
+	data, err := s.MarshalBinary()
+	require.NoError(t, err)
+
+	decoded := NewStream()
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, s.Range(MinStreamID, MaxStreamID, 0, false), decoded.Range(MinStreamID, MaxStreamID, 0, false))
+	assert.Equal(t, StreamID{1, 119}, decoded.LastID())
+
+	assert.ErrorIs(t, decoded.UnmarshalBinary(data[:len(data)-1]), ErrCorruptEncoding)
+}
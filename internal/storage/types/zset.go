@@ -11,23 +11,27 @@ import (
 type ZSet interface {
 	ZAdd(scoreMember map[string]float64, options ZAddOptions) (*uint32, int64)
 	ZCard() uint32
-	ZCount(minScore, maxScore float64) uint32
+	ZCount(r ZScoreRange) uint32
 	ZIncrBy(member string, increment float64) (float64, bool, int64)
-	ZLexCount(minValue, maxValue string) uint32
+	ZLexCount(r ZLexRange) uint32
 	ZMScore(members []string) []*float64
 	ZPopMax(count int) ([]string, int64)
 	ZPopMin(count int) ([]string, int64)
 	ZRandMember(count int, withScores bool) []string
 	ZRangeByRank(start, stop int, withScores bool) []string
-	ZRangeByLex(start, stop string, withScores bool) []string
-	ZRangeByScore(start, stop float64, withScores bool) []string
+	ZRangeByLex(r ZLexRange, offset, count int, withScores bool) []string
+	ZRangeByScore(r ZScoreRange, offset, count int, withScores bool) []string
 	ZRevRangeByRank(start, stop int, withScores bool) []string
-	ZRevRangeByLex(start, stop string, withScores bool) []string
-	ZRevRangeByScore(start, stop float64, withScores bool) []string
+	ZRevRangeByLex(r ZLexRange, offset, count int, withScores bool) []string
+	ZRevRangeByScore(r ZScoreRange, offset, count int, withScores bool) []string
 	ZRank(member string, withScore bool) []any
 	ZRem(members []string) (int, int64)
+	ZRemRangeByRank(start, stop int) (int, int64)
+	ZRemRangeByScore(r ZScoreRange) (int, int64)
+	ZRemRangeByLex(r ZLexRange) (int, int64)
 	ZRevRank(member string, withScore bool) []any
 	ZScore(member string) *float64
+	ZScan(cursor uint64, count int) (uint64, []string)
 
 	// Geo commands (stored as ZSet with geohash as score)
 	GeoAdd(items []GeoPoint, options ZAddOptions) (*uint32, int64)
@@ -42,6 +46,7 @@ type ZSet interface {
 type zSet struct {
 	skipList *skipList
 	data     map[string]float64
+	order    scanIndex
 }
 
 type ZAddOptions struct {
@@ -52,16 +57,11 @@ type ZAddOptions struct {
 	CH bool
 }
 
-func NewZSet() ZSet {
-	return &zSet{
-		skipList: newSkipList(),
-		data:     make(map[string]float64),
-	}
-}
-
-func (zset *zSet) ZAdd(scoreMember map[string]float64, options ZAddOptions) (*uint32, int64) {
+// valid reports whether the options can be combined: NX excludes XX, GT and LT, which
+// exclude each other.
+func (options ZAddOptions) valid() bool {
 	if options.NX && options.XX || options.GT && options.LT {
-		return nil, 0
+		return false
 	}
 
 	count := 0
@@ -75,7 +75,93 @@ func (zset *zSet) ZAdd(scoreMember map[string]float64, options ZAddOptions) (*ui
 		count++
 	}
 
-	if count > 1 {
+	return count <= 1
+}
+
+// ZScoreRange is a range of scores between Min and Max, each bound excluded when MinEx or
+// MaxEx is set.
+type ZScoreRange struct {
+	Min   float64
+	Max   float64
+	MinEx bool
+	MaxEx bool
+}
+
+func (r ZScoreRange) aboveMin(score float64) bool {
+	if r.MinEx {
+		return score > r.Min
+	}
+	return score >= r.Min
+}
+
+func (r ZScoreRange) belowMax(score float64) bool {
+	if r.MaxEx {
+		return score < r.Max
+	}
+	return score <= r.Max
+}
+
+// empty reports whether no score can be within the range.
+func (r ZScoreRange) empty() bool {
+	return r.Min > r.Max || r.Min == r.Max && (r.MinEx || r.MaxEx)
+}
+
+// ZLexBound is a bound of a ZLexRange. Inf is -1 for the "-" bound below every member, 1
+// for the "+" bound above every member, and 0 for a bound at Value, excluded when Exclusive.
+type ZLexBound struct {
+	Value     string
+	Exclusive bool
+	Inf       int
+}
+
+// ZLexRange is a range of members between Min and Max in lexicographical order.
+type ZLexRange struct {
+	Min ZLexBound
+	Max ZLexBound
+}
+
+func (r ZLexRange) aboveMin(member string) bool {
+	switch {
+	case r.Min.Inf != 0:
+		return r.Min.Inf < 0
+	case r.Min.Exclusive:
+		return member > r.Min.Value
+	}
+	return member >= r.Min.Value
+}
+
+func (r ZLexRange) belowMax(member string) bool {
+	switch {
+	case r.Max.Inf != 0:
+		return r.Max.Inf > 0
+	case r.Max.Exclusive:
+		return member < r.Max.Value
+	}
+	return member <= r.Max.Value
+}
+
+// empty reports whether no member can be within the range.
+func (r ZLexRange) empty() bool {
+	if r.Min.Inf > 0 || r.Max.Inf < 0 {
+		return true
+	}
+	if r.Min.Inf < 0 || r.Max.Inf > 0 {
+		return false
+	}
+	return r.Min.Value > r.Max.Value ||
+		r.Min.Value == r.Max.Value && (r.Min.Exclusive || r.Max.Exclusive)
+}
+
+func NewZSet() ZSet {
+	return &zSet{
+		skipList: newSkipList(),
+		data:     make(map[string]float64),
+		order:    newScanIndex(),
+	}
+}
+
+func (zset *zSet) ZAdd(scoreMember map[string]float64, options ZAddOptions) (*uint32, int64) {
+	if !options.valid() {
 		return nil, 0
 	}
 
@@ -112,7 +198,7 @@ func (zset *zSet) ZAdd(scoreMember map[string]float64, options ZAddOptions) (*ui
 		zset.skipList.insert(member, newScore)
 		zset.data[member] = newScore
 		// New member: add skip list node + map entry
-		delta += SkipListNodeSizeAvg(member) + StringFloat64MapEntrySize(member)
+		delta += SkipListNodeSizeAvg(member) + StringFloat64MapEntrySize(member) + zset.order.add(member)
 		result++
 	}
 
@@ -123,8 +209,8 @@ func (zset *zSet) ZCard() uint32 {
 	return uint32(len(zset.data))
 }
 
-func (zset *zSet) ZCount(minScore, maxScore float64) uint32 {
-	return uint32(zset.skipList.countByScore(minScore, maxScore))
+func (zset *zSet) ZCount(r ZScoreRange) uint32 {
+	return uint32(zset.skipList.countByScore(r))
 }
 
 func (zset *zSet) ZIncrBy(member string, increment float64) (float64, bool, int64) {
@@ -133,7 +219,7 @@ func (zset *zSet) ZIncrBy(member string, increment float64) (float64, bool, int6
 		zset.skipList.insert(member, increment)
 		zset.data[member] = increment
 		// New member: add skip list node + map entry
-		delta := SkipListNodeSizeAvg(member) + StringFloat64MapEntrySize(member)
+		delta := SkipListNodeSizeAvg(member) + StringFloat64MapEntrySize(member) + zset.order.add(member)
 		return increment, true, delta
 	}
 
@@ -148,8 +234,8 @@ func (zset *zSet) ZIncrBy(member string, increment float64) (float64, bool, int6
 	return newScore, true, 0
 }
 
-func (zset *zSet) ZLexCount(minValue, maxValue string) uint32 {
-	return uint32(zset.skipList.countByLex(minValue, maxValue))
+func (zset *zSet) ZLexCount(r ZLexRange) uint32 {
+	return uint32(zset.skipList.countByLex(r))
 }
 
 func (zset *zSet) ZMScore(members []string) []*float64 {
@@ -176,6 +262,7 @@ func (zset *zSet) ZPopMax(count int) ([]string, int64) {
 	for i := range poppedNodes {
 		member := poppedNodes[i].value
 		delete(zset.data, member)
+		delta += zset.order.remove(member)
 		result = append(result, member)
 		result = append(result, formatFloat(poppedNodes[i].score))
 		// Removed member: subtract skip list node + map entry
@@ -196,6 +283,7 @@ func (zset *zSet) ZPopMin(count int) ([]string, int64) {
 	for i := range poppedNodes {
 		member := poppedNodes[i].value
 		delete(zset.data, member)
+		delta += zset.order.remove(member)
 		result = append(result, member)
 		result = append(result, formatFloat(poppedNodes[i].score))
 		// Removed member: subtract skip list node + map entry
@@ -269,13 +357,13 @@ func (zset *zSet) ZRangeByRank(start, stop int, withScores bool) []string {
 	return zset.nodesToStringSlice(nodes, withScores)
 }
 
-func (zset *zSet) ZRangeByLex(start, stop string, withScores bool) []string {
-	nodes := zset.skipList.getRangeByLex(start, stop)
+func (zset *zSet) ZRangeByLex(r ZLexRange, offset, count int, withScores bool) []string {
+	nodes := zset.skipList.getRangeByLex(r, offset, count)
 	return zset.nodesToStringSlice(nodes, withScores)
 }
 
-func (zset *zSet) ZRangeByScore(start, stop float64, withScores bool) []string {
-	nodes := zset.skipList.getRangeByScore(start, stop)
+func (zset *zSet) ZRangeByScore(r ZScoreRange, offset, count int, withScores bool) []string {
+	nodes := zset.skipList.getRangeByScore(r, offset, count)
 	return zset.nodesToStringSlice(nodes, withScores)
 }
 
@@ -284,13 +372,13 @@ func (zset *zSet) ZRevRangeByRank(start, stop int, withScores bool) []string {
 	return zset.nodesToStringSlice(nodes, withScores)
 }
 
-func (zset *zSet) ZRevRangeByLex(start, stop string, withScores bool) []string {
-	nodes := zset.skipList.getRevRangeByLex(start, stop)
+func (zset *zSet) ZRevRangeByLex(r ZLexRange, offset, count int, withScores bool) []string {
+	nodes := zset.skipList.getRevRangeByLex(r, offset, count)
 	return zset.nodesToStringSlice(nodes, withScores)
 }
 
-func (zset *zSet) ZRevRangeByScore(start, stop float64, withScores bool) []string {
-	nodes := zset.skipList.getRevRangeByScore(start, stop)
+func (zset *zSet) ZRevRangeByScore(r ZScoreRange, offset, count int, withScores bool) []string {
+	nodes := zset.skipList.getRevRangeByScore(r, offset, count)
 	return zset.nodesToStringSlice(nodes, withScores)
 }
 
@@ -320,6 +408,7 @@ func (zset *zSet) ZRem(members []string) (int, int64) {
 			// Delete from skipList first - if this fails, data is unchanged
 			if zset.skipList.delete(member, score) {
 				delete(zset.data, member)
+				delta += zset.order.remove(member)
 				removed++
 				// Removed member: subtract skip list node + map entry
 				delta -= SkipListNodeSizeAvg(member) + StringFloat64MapEntrySize(member)
@@ -330,6 +419,22 @@ func (zset *zSet) ZRem(members []string) (int, int64) {
 	return removed, delta
 }
 
+// ZRemRangeByRank removes the members ranked between start and stop, both included and
+// negative from the end, and returns their number.
+func (zset *zSet) ZRemRangeByRank(start, stop int) (int, int64) {
+	return zset.ZRem(zset.ZRangeByRank(start, stop, false))
+}
+
+// ZRemRangeByScore removes the members within the score range r and returns their number.
+func (zset *zSet) ZRemRangeByScore(r ZScoreRange) (int, int64) {
+	return zset.ZRem(zset.ZRangeByScore(r, 0, -1, false))
+}
+
+// ZRemRangeByLex removes the members within the lex range r and returns their number.
+func (zset *zSet) ZRemRangeByLex(r ZLexRange) (int, int64) {
+	return zset.ZRem(zset.ZRangeByLex(r, 0, -1, false))
+}
+
 func (zset *zSet) ZRevRank(member string, withScore bool) []any {
 	score, exists := zset.data[member]
 	if !exists {
@@ -358,10 +463,26 @@ func (zset *zSet) ZScore(member string) *float64 {
 	return &score
 }
 
+// ZScan returns up to count member-score pairs and the next cursor.
+func (zset *zSet) ZScan(cursor uint64, count int) (uint64, []string) {
+	next, members := zset.order.scan(cursor, count)
+	result := make([]string, 0, len(members)*2)
+	for _, member := range members {
+		result = append(result, member, formatFloat(zset.data[member]))
+	}
+	return next, result
+}
+
 func (zset *zSet) MemoryUsage() int64 {
 	return int64(size.Of(zset))
 }
 
+func (zset *zSet) forEach(fn func(member string, score float64)) {
+	for member, score := range zset.data {
+		fn(member, score)
+	}
+}
+
 func (zset *zSet) nodesToStringSlice(nodes []*skipListNode, withScores bool) []string {
 	nodeCount := len(nodes)
 	if nodeCount == 0 {
@@ -385,6 +506,191 @@ func (zset *zSet) nodesToStringSlice(nodes []*skipListNode, withScores bool) []s
 	return result
 }
 
+// ZAggregate is how ZSetUnion and ZSetIntersection combine the scores of a member found
+// in several operands.
+type ZAggregate int
+
+const (
+	ZAggregateSum ZAggregate = iota
+	ZAggregateMin
+	ZAggregateMax
+)
+
+func (aggregate ZAggregate) apply(a, b float64) float64 {
+	switch aggregate {
+	case ZAggregateMin:
+		return min(a, b)
+	case ZAggregateMax:
+		return max(a, b)
+	}
+	return nanToZero(a + b)
+}
+
+// ZSetOperand is an input of the sorted set algebra: a sorted set, or a set whose members
+// all score 1, with its scores multiplied by Weight. An operand without either is empty.
+type ZSetOperand struct {
+	ZSet   ZSet
+	Set    Set
+	Weight float64
+}
+
+func (operand ZSetOperand) size() int {
+	switch {
+	case operand.ZSet != nil:
+		return int(operand.ZSet.ZCard())
+	case operand.Set != nil:
+		return int(operand.Set.Size())
+	}
+	return 0
+}
+
+// score returns the weighted score of member, and whether the operand holds it.
+func (operand ZSetOperand) score(member string) (float64, bool) {
+	switch {
+	case operand.ZSet != nil:
+		if score := operand.ZSet.ZScore(member); score != nil {
+			return operand.weigh(*score), true
+		}
+	case operand.Set != nil:
+		if operand.Set.IsMember(member) {
+			return operand.weigh(1), true
+		}
+	}
+	return 0, false
+}
+
+// forEach calls fn with every member of the operand and its weighted score.
+func (operand ZSetOperand) forEach(fn func(member string, score float64)) {
+	switch {
+	case operand.ZSet != nil:
+		operand.ZSet.(geoSet).forEach(func(member string, score float64) {
+			fn(member, operand.weigh(score))
+		})
+	case operand.Set != nil:
+		for _, member := range operand.Set.Members() {
+			fn(member, operand.weigh(1))
+		}
+	}
+}
+
+func (operand ZSetOperand) weigh(score float64) float64 {
+	// An infinite score with a weight of 0 scores 0
+	return nanToZero(score * operand.Weight)
+}
+
+// ZSetUnion returns a sorted set of the members found in any operand, scored by combining
+// their weighted scores with aggregate.
+func ZSetUnion(operands []ZSetOperand, aggregate ZAggregate) ZSet {
+	scoreMember := make(map[string]float64)
+	for _, operand := range operands {
+		operand.forEach(func(member string, score float64) {
+			if old, exists := scoreMember[member]; exists {
+				score = aggregate.apply(old, score)
+			}
+			scoreMember[member] = score
+		})
+	}
+
+	zset := NewZSet()
+	zset.ZAdd(scoreMember, ZAddOptions{})
+	return zset
+}
+
+// ZSetIntersection returns a sorted set of the members found in every operand, scored by
+// combining their weighted scores with aggregate. The members of the smallest operand are
+// checked against the others.
+func ZSetIntersection(operands []ZSetOperand, aggregate ZAggregate) ZSet {
+	scoreMember := make(map[string]float64)
+	zset := NewZSet()
+	if len(operands) == 0 {
+		return zset
+	}
+
+	smallest := 0
+	for i, operand := range operands {
+		if operand.size() < operands[smallest].size() {
+			smallest = i
+		}
+	}
+
+	operands[smallest].forEach(func(member string, _ float64) {
+		var result float64
+		for i, operand := range operands {
+			score, exists := operand.score(member)
+			if !exists {
+				return
+			}
+			if i == 0 {
+				result = score
+			} else {
+				result = aggregate.apply(result, score)
+			}
+		}
+		scoreMember[member] = result
+	})
+
+	zset.ZAdd(scoreMember, ZAddOptions{})
+	return zset
+}
+
+// ZSetIntersectionCard returns the number of members found in every operand, counting at
+// most limit members when limit is positive.
+func ZSetIntersectionCard(operands []ZSetOperand, limit int) int {
+	if len(operands) == 0 {
+		return 0
+	}
+
+	smallest := 0
+	for i, operand := range operands {
+		if operand.size() < operands[smallest].size() {
+			smallest = i
+		}
+	}
+
+	count := 0
+	operands[smallest].forEach(func(member string, _ float64) {
+		if limit > 0 && count >= limit {
+			return
+		}
+		for i, operand := range operands {
+			if _, exists := operand.score(member); i != smallest && !exists {
+				return
+			}
+		}
+		count++
+	})
+	return count
+}
+
+// ZSetDifference returns a sorted set of the members of the first operand found in none of
+// the others, with their scores in the first operand.
+func ZSetDifference(operands []ZSetOperand) ZSet {
+	scoreMember := make(map[string]float64)
+	zset := NewZSet()
+	if len(operands) == 0 {
+		return zset
+	}
+
+	operands[0].forEach(func(member string, score float64) {
+		for _, operand := range operands[1:] {
+			if _, exists := operand.score(member); exists {
+				return
+			}
+		}
+		scoreMember[member] = score
+	})
+
+	zset.ZAdd(scoreMember, ZAddOptions{})
+	return zset
+}
+
+func nanToZero(num float64) float64 {
+	if math.IsNaN(num) {
+		return 0
+	}
+	return num
+}
+
 func formatFloat(num float64) string {
 	return strconv.FormatFloat(num, 'g', -1, 64)
 }
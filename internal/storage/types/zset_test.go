@@ -178,13 +178,13 @@ func TestZSet_ZCount(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		assert.Equal(t, tt.expected, z.ZCount(tt.min, tt.max))
+		assert.Equal(t, tt.expected, z.ZCount(ZScoreRange{Min: tt.min, Max: tt.max}))
 	}
 }
 
 func TestZSet_ZCount_Empty(t *testing.T) {
 	z := NewZSet()
-	assert.Equal(t, uint32(0), z.ZCount(-100, 100))
+	assert.Equal(t, uint32(0), z.ZCount(ZScoreRange{Min: -100, Max: 100}))
 }
 
 func TestZSet_ZCount_Boundaries(t *testing.T) {
@@ -195,9 +195,9 @@ func TestZSet_ZCount_Boundaries(t *testing.T) {
 	z.ZIncrBy("c", 2.0)
 	z.ZIncrBy("d", 3.0)
 
-	assert.Equal(t, uint32(2), z.ZCount(2.0, 2.0))
-	assert.Equal(t, uint32(1), z.ZCount(1.0, 1.0))
-	assert.Equal(t, uint32(4), z.ZCount(1.0, 3.0))
+	assert.Equal(t, uint32(2), z.ZCount(ZScoreRange{Min: 2.0, Max: 2.0}))
+	assert.Equal(t, uint32(1), z.ZCount(ZScoreRange{Min: 1.0, Max: 1.0}))
+	assert.Equal(t, uint32(4), z.ZCount(ZScoreRange{Min: 1.0, Max: 3.0}))
 }
 
 func TestZSet_ZCount_NegativeScores(t *testing.T) {
@@ -207,8 +207,8 @@ func TestZSet_ZCount_NegativeScores(t *testing.T) {
 	z.ZIncrBy("b", -1)
 	z.ZIncrBy("c", 0)
 
-	assert.Equal(t, uint32(2), z.ZCount(-5, -1))
-	assert.Equal(t, uint32(3), z.ZCount(-10, 0))
+	assert.Equal(t, uint32(2), z.ZCount(ZScoreRange{Min: -5, Max: -1}))
+	assert.Equal(t, uint32(3), z.ZCount(ZScoreRange{Min: -10, Max: 0}))
 }
 
 func TestZSet_ZIncrBy(t *testing.T) {
@@ -261,7 +261,7 @@ func TestZSet_ZIncrBy_RepeatedUpdates(t *testing.T) {
 		assert.Equal(t, float64(i+1), score)
 	}
 
-	assert.Equal(t, uint32(1), z.ZCount(10, 10))
+	assert.Equal(t, uint32(1), z.ZCount(ZScoreRange{Min: 10, Max: 10}))
 }
 
 func TestZSet_ZIncrBy_DecreaseBelowZero(t *testing.T) {
@@ -282,14 +282,14 @@ func TestZSet_ZLexCount(t *testing.T) {
 	z.ZIncrBy("cherry", 0)
 	z.ZIncrBy("date", 0)
 
-	assert.Equal(t, uint32(4), z.ZLexCount("apple", "date"))
-	assert.Equal(t, uint32(2), z.ZLexCount("banana", "cherry"))
-	assert.Equal(t, uint32(0), z.ZLexCount("x", "z"))
+	assert.Equal(t, uint32(4), z.ZLexCount(lexRangeOf("apple", "date")))
+	assert.Equal(t, uint32(2), z.ZLexCount(lexRangeOf("banana", "cherry")))
+	assert.Equal(t, uint32(0), z.ZLexCount(lexRangeOf("x", "z")))
 }
 
 func TestZSet_ZLexCount_Empty(t *testing.T) {
 	z := NewZSet()
-	assert.Equal(t, uint32(0), z.ZLexCount("a", "z"))
+	assert.Equal(t, uint32(0), z.ZLexCount(lexRangeOf("a", "z")))
 }
 
 func TestZSet_ZLexCount_ExactMiss(t *testing.T) {
@@ -298,7 +298,7 @@ func TestZSet_ZLexCount_ExactMiss(t *testing.T) {
 	z.ZIncrBy("apple", 0)
 	z.ZIncrBy("banana", 0)
 
-	assert.Equal(t, uint32(0), z.ZLexCount("apricot", "apricot"))
+	assert.Equal(t, uint32(0), z.ZLexCount(lexRangeOf("apricot", "apricot")))
 }
 
 func TestZSet_ZLexCount_PrefixOverlap(t *testing.T) {
@@ -309,7 +309,7 @@ func TestZSet_ZLexCount_PrefixOverlap(t *testing.T) {
 	z.ZIncrBy("aaa", 0)
 	z.ZIncrBy("b", 0)
 
-	assert.Equal(t, uint32(3), z.ZLexCount("a", "aaa"))
+	assert.Equal(t, uint32(3), z.ZLexCount(lexRangeOf("a", "aaa")))
 }
 
 func TestZSet_ZMScore(t *testing.T) {
@@ -369,7 +369,7 @@ func TestZSet_ZPopMax(t *testing.T) {
 	res, _ := z.ZPopMax(2)
 
 	require.Equal(t, []string{"c", "3", "b", "2"}, res)
-	assert.Equal(t, uint32(1), z.ZCount(-100, 100))
+	assert.Equal(t, uint32(1), z.ZCount(ZScoreRange{Min: -100, Max: 100}))
 }
 
 func TestZSet_ZPopMin(t *testing.T) {
@@ -382,7 +382,7 @@ func TestZSet_ZPopMin(t *testing.T) {
 	res, _ := z.ZPopMin(2)
 
 	require.Equal(t, []string{"a", "1", "b", "2"}, res)
-	assert.Equal(t, uint32(1), z.ZCount(-100, 100))
+	assert.Equal(t, uint32(1), z.ZCount(ZScoreRange{Min: -100, Max: 100}))
 }
 
 func TestZSet_ZPop_OverCount(t *testing.T) {
@@ -416,7 +416,7 @@ func TestZSet_ZPop_ExactSize(t *testing.T) {
 	res, _ := z.ZPopMin(2)
 	assert.Equal(t, []string{"a", "1", "b", "2"}, res)
 
-	assert.Equal(t, uint32(0), z.ZCount(-100, 100))
+	assert.Equal(t, uint32(0), z.ZCount(ZScoreRange{Min: -100, Max: 100}))
 }
 
 func TestZSet_ZPop_OrderAfterPartialPop(t *testing.T) {
@@ -430,9 +430,9 @@ func TestZSet_ZPop_OrderAfterPartialPop(t *testing.T) {
 	z.ZPopMax(1) // removes d
 	z.ZPopMin(1) // removes a
 
-	assert.Equal(t, uint32(2), z.ZCount(-100, 100))
-	assert.Equal(t, uint32(1), z.ZCount(2, 2))
-	assert.Equal(t, uint32(1), z.ZCount(3, 3))
+	assert.Equal(t, uint32(2), z.ZCount(ZScoreRange{Min: -100, Max: 100}))
+	assert.Equal(t, uint32(1), z.ZCount(ZScoreRange{Min: 2, Max: 2}))
+	assert.Equal(t, uint32(1), z.ZCount(ZScoreRange{Min: 3, Max: 3}))
 }
 
 func TestZSet_ZPop_ReducesCountCorrectly(t *testing.T) {
@@ -443,10 +443,10 @@ func TestZSet_ZPop_ReducesCountCorrectly(t *testing.T) {
 	}
 
 	z.ZPopMin(3)
-	assert.Equal(t, uint32(7), z.ZCount(-100, 100))
+	assert.Equal(t, uint32(7), z.ZCount(ZScoreRange{Min: -100, Max: 100}))
 
 	z.ZPopMax(4)
-	assert.Equal(t, uint32(3), z.ZCount(-100, 100))
+	assert.Equal(t, uint32(3), z.ZCount(ZScoreRange{Min: -100, Max: 100}))
 }
 
 func TestZRandMember_ZeroAndEmpty(t *testing.T) {
@@ -648,18 +648,18 @@ func TestZSet_ZRangeByScore(t *testing.T) {
 
 	assert.Equal(t,
 		[]string{"b", "c"},
-		z.ZRangeByScore(2, 3, false),
+		z.ZRangeByScore(ZScoreRange{Min: 2, Max: 3}, 0, -1, false),
 	)
 
 	assert.Equal(t,
 		[]string{"c", "3"},
-		z.ZRangeByScore(3, 3, true),
+		z.ZRangeByScore(ZScoreRange{Min: 3, Max: 3}, 0, -1, true),
 	)
 }
 
 func TestZSet_ZRangeByScore_Empty(t *testing.T) {
 	z := NewZSet()
-	assert.Empty(t, z.ZRangeByScore(0, 100, false))
+	assert.Empty(t, z.ZRangeByScore(ZScoreRange{Min: 0, Max: 100}, 0, -1, false))
 }
 
 func TestZSet_ZRevRangeByScore(t *testing.T) {
@@ -673,12 +673,12 @@ func TestZSet_ZRevRangeByScore(t *testing.T) {
 
 	assert.Equal(t,
 		[]string{"d", "c"},
-		z.ZRevRangeByScore(4, 3, false),
+		z.ZRevRangeByScore(ZScoreRange{Min: 3, Max: 4}, 0, -1, false),
 	)
 
 	assert.Equal(t,
 		[]string{"b", "2", "a", "1"},
-		z.ZRevRangeByScore(2, 1, true),
+		z.ZRevRangeByScore(ZScoreRange{Min: 1, Max: 2}, 0, -1, true),
 	)
 }
 
@@ -692,18 +692,18 @@ func TestZSet_ZRangeByLex(t *testing.T) {
 
 	assert.Equal(t,
 		[]string{"banana", "cherry"},
-		z.ZRangeByLex("banana", "cherry", false),
+		z.ZRangeByLex(lexRangeOf("banana", "cherry"), 0, -1, false),
 	)
 
 	assert.Equal(t,
 		[]string{"apple", "0", "banana", "0"},
-		z.ZRangeByLex("apple", "banana", true),
+		z.ZRangeByLex(lexRangeOf("apple", "banana"), 0, -1, true),
 	)
 }
 
 func TestZSet_ZRangeByLex_Empty(t *testing.T) {
 	z := NewZSet()
-	assert.Empty(t, z.ZRangeByLex("a", "z", false))
+	assert.Empty(t, z.ZRangeByLex(lexRangeOf("a", "z"), 0, -1, false))
 }
 
 func TestZSet_ZRevRangeByLex(t *testing.T) {
@@ -716,12 +716,12 @@ func TestZSet_ZRevRangeByLex(t *testing.T) {
 
 	assert.Equal(t,
 		[]string{"d", "c"},
-		z.ZRevRangeByLex("d", "c", false),
+		z.ZRevRangeByLex(lexRangeOf("c", "d"), 0, -1, false),
 	)
 
 	assert.Equal(t,
 		[]string{"b", "0", "a", "0"},
-		z.ZRevRangeByLex("b", "a", true),
+		z.ZRevRangeByLex(lexRangeOf("a", "b"), 0, -1, true),
 	)
 }
 
@@ -873,3 +873,81 @@ func TestZSet_ZScore_AfterRemove(t *testing.T) {
 
 	assert.Nil(t, z.ZScore("a"))
 }
+
+// lexRangeOf returns the lex range between min and max, both included.
+func lexRangeOf(min, max string) ZLexRange {
+	return ZLexRange{Min: ZLexBound{Value: min}, Max: ZLexBound{Value: max}}
+}
+
+func zsetOf(scoreMember map[string]float64, weight float64) ZSetOperand {
+	z := NewZSet()
+	z.ZAdd(scoreMember, ZAddOptions{})
+	return ZSetOperand{ZSet: z, Weight: weight}
+}
+
+func TestZSetUnion(t *testing.T) {
+	set := NewSimpleSet()
+	set.Add("a", "d")
+	operands := []ZSetOperand{
+		zsetOf(map[string]float64{"a": 1, "b": 2}, 1),
+		zsetOf(map[string]float64{"b": 3, "c": 4}, 2),
+		{Set: set, Weight: 1},
+		{Weight: 1},
+	}
+
+	union := ZSetUnion(operands, ZAggregateSum)
+	assert.Equal(t, []string{"d", "1", "a", "2", "b", "8", "c", "8"}, union.ZRangeByRank(0, -1, true))
+
+	union = ZSetUnion(operands, ZAggregateMin)
+	assert.Equal(t, []string{"a", "1", "d", "1", "b", "2", "c", "8"}, union.ZRangeByRank(0, -1, true))
+
+	union = ZSetUnion(operands, ZAggregateMax)
+	assert.Equal(t, []string{"a", "1", "d", "1", "b", "6", "c", "8"}, union.ZRangeByRank(0, -1, true))
+}
+
+func TestZSetUnion_InfinityAndZeroWeight(t *testing.T) {
+	operands := []ZSetOperand{
+		zsetOf(map[string]float64{"a": math.Inf(1), "b": math.Inf(1)}, 1),
+		zsetOf(map[string]float64{"a": math.Inf(-1)}, 1),
+		zsetOf(map[string]float64{"b": 1}, 0),
+	}
+
+	union := ZSetUnion(operands, ZAggregateSum)
+	assert.Equal(t, []string{"a", "0", "b", "+Inf"}, union.ZRangeByRank(0, -1, true))
+}
+
+func TestZSetIntersection(t *testing.T) {
+	set := NewSimpleSet()
+	set.Add("a", "b", "x")
+	operands := []ZSetOperand{
+		zsetOf(map[string]float64{"a": 1, "b": 2, "c": 3}, 1),
+		zsetOf(map[string]float64{"a": 5, "b": 1}, 10),
+		{Set: set, Weight: 1},
+	}
+
+	inter := ZSetIntersection(operands, ZAggregateSum)
+	assert.Equal(t, []string{"b", "13", "a", "52"}, inter.ZRangeByRank(0, -1, true))
+
+	inter = ZSetIntersection(operands, ZAggregateMax)
+	assert.Equal(t, []string{"b", "10", "a", "50"}, inter.ZRangeByRank(0, -1, true))
+
+	assert.Equal(t, 2, ZSetIntersectionCard(operands, 0))
+	assert.Equal(t, 1, ZSetIntersectionCard(operands, 1))
+
+	operands = append(operands, ZSetOperand{Weight: 1})
+	assert.Equal(t, uint32(0), ZSetIntersection(operands, ZAggregateSum).ZCard())
+	assert.Equal(t, 0, ZSetIntersectionCard(operands, 0))
+}
+
+func TestZSetDifference(t *testing.T) {
+	set := NewSimpleSet()
+	set.Add("c")
+	operands := []ZSetOperand{
+		zsetOf(map[string]float64{"a": 3, "b": 2, "c": 1}, 1),
+		zsetOf(map[string]float64{"b": 3}, 1),
+		{Set: set, Weight: 1},
+	}
+
+	assert.Equal(t, []string{"a", "3"}, ZSetDifference(operands).ZRangeByRank(0, -1, true))
+	assert.Equal(t, uint32(0), ZSetDifference([]ZSetOperand{{Weight: 1}}).ZCard())
+}
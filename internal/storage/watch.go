@@ -0,0 +1,45 @@
+package storage
+
+// watchedKey counts the modifications of a key that at least one client is watching.
+type watchedKey struct {
+	version  uint64
+	watchers int
+}
+
+// Watch registers a watcher of key and returns its current version. A key that has
+// expired is deleted first, so its expiration is not mistaken for a later modification.
+func (s *store) Watch(key string) uint64 {
+	s.access(key, ObjAny, false)
+
+	w, ok := s.watched[key]
+	if !ok {
+		w = &watchedKey{}
+		s.watched[key] = w
+	}
+	w.watchers++
+	return w.version
+}
+
+// Unwatch releases a watcher registered by Watch.
+func (s *store) Unwatch(key string) {
+	w, ok := s.watched[key]
+	if !ok {
+		return
+	}
+
+	w.watchers--
+	if w.watchers == 0 {
+		delete(s.watched, key)
+	}
+}
+
+// WatchedVersion returns the version of a watched key. It changes whenever the key is
+// written, deleted, evicted or found to have expired since Watch was called.
+func (s *store) WatchedVersion(key string) uint64 {
+	s.access(key, ObjAny, false)
+
+	if w, ok := s.watched[key]; ok {
+		return w.version
+	}
+	return 0
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStoreWatch() *store {
+	return NewStore(config.NewConfig()).(*store)
+}
+
+func TestWatch_ReadsKeepVersion(t *testing.T) {
+	s := newTestStoreWatch()
+	s.Set("key", "value")
+	version := s.Watch("key")
+
+	s.Get("key")
+	s.Exists("key")
+	s.TTL("key")
+
+	assert.Equal(t, version, s.WatchedVersion("key"))
+}
+
+func TestWatch_WritesChangeVersion(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(s *store)
+	}{
+		{"set", func(s *store) { s.Set("key", "other") }},
+		{"create", func(s *store) { s.Set("missing", "value") }},
+		{"incr", func(s *store) { s.IncrBy("key", 1) }},
+		{"delete", func(s *store) { s.Del("key") }},
+		{"expire", func(s *store) { s.Expire("key", 100, ExpireOptions{}) }},
+		{"push", func(s *store) { s.LPush("list", "a") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStoreWatch()
+			s.Set("key", "1")
+			versions := map[string]uint64{
+				"key":     s.Watch("key"),
+				"missing": s.Watch("missing"),
+				"list":    s.Watch("list"),
+			}
+
+			tt.write(s)
+
+			changed := 0
+			for key, version := range versions {
+				if s.WatchedVersion(key) != version {
+					changed++
+				}
+			}
+			assert.Equal(t, 1, changed)
+		})
+	}
+}
+
+func TestWatch_FailedWriteKeepsVersion(t *testing.T) {
+	s := newTestStoreWatch()
+	s.Set("key", "value")
+	version := s.Watch("key")
+
+	_, err := s.LPush("key", "a")
+
+	assert.ErrorIs(t, err, ErrWrongTypeError)
+	assert.Equal(t, version, s.WatchedVersion("key"))
+}
+
+func TestWatch_ExpirationChangesVersion(t *testing.T) {
+	s := newTestStoreWatch()
+	s.SetEx("key", "value", 1)
+	version := s.Watch("key")
+
+	s.expires.Set("key", uint64(time.Now().UnixMilli())-1)
+
+	assert.NotEqual(t, version, s.WatchedVersion("key"))
+}
+
+func TestUnwatch_ReleasesKey(t *testing.T) {
+	s := newTestStoreWatch()
+	s.Watch("key")
+	s.Watch("key")
+
+	s.Unwatch("key")
+	assert.Contains(t, s.watched, "key")
+
+	s.Unwatch("key")
+	assert.NotContains(t, s.watched, "key")
+}
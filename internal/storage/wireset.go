@@ -3,5 +3,5 @@ package storage
 import "github.com/google/wire"
 
 var WireSet = wire.NewSet(
-	NewStore,
+	NewDatabases,
 )
\ No newline at end of file
@@ -1,6 +1,9 @@
 package storage
 
 import (
+	"strconv"
+
+	"github.com/manhhung2111/go-redis/internal/config"
 	"github.com/manhhung2111/go-redis/internal/storage/types"
 )
 
@@ -10,19 +13,12 @@ func (s *store) ZAdd(key string, scoreMember map[string]float64, options types.Z
 		return nil, result.err
 	}
 
-	if !result.exists {
-		zset := types.NewZSet()
-		added, _ := zset.ZAdd(scoreMember, options)
-		delta := s.data.Set(key, &RObj{
-			objType:  ObjZSet,
-			encoding: EncSortedSet,
-			value:    zset,
-		})
-		s.usedMemory += delta
-		return added, nil
+	members := make([]string, 0, len(scoreMember))
+	for member := range scoreMember {
+		members = append(members, member)
 	}
 
-	zset := result.object.value.(types.ZSet)
+	zset := s.zsetForWrite(key, result, members)
 	added, delta := zset.ZAdd(scoreMember, options)
 	s.usedMemory += delta
 	return added, nil
@@ -41,7 +37,7 @@ func (s *store) ZCard(key string) (uint32, error) {
 	return zset.ZCard(), nil
 }
 
-func (s *store) ZCount(key string, minScore float64, maxScore float64) (uint32, error) {
+func (s *store) ZCount(key string, r types.ZScoreRange) (uint32, error) {
 	zset, err := s.getZSet(key, false)
 	if err != nil {
 		return 0, err
@@ -51,7 +47,7 @@ func (s *store) ZCount(key string, minScore float64, maxScore float64) (uint32,
 		return 0, nil
 	}
 
-	return zset.ZCount(minScore, maxScore), nil
+	return zset.ZCount(r), nil
 }
 
 func (s *store) ZIncrBy(key string, member string, increment float64) (float64, error) {
@@ -60,25 +56,7 @@ func (s *store) ZIncrBy(key string, member string, increment float64) (float64,
 		return 0, result.err
 	}
 
-	if !result.exists {
-		zset := types.NewZSet()
-		res, succeeded, _ := zset.ZIncrBy(member, increment)
-
-		if !succeeded {
-			return 0, ErrValueIsNotValidFloatError
-		}
-
-		delta := s.data.Set(key, &RObj{
-			objType:  ObjZSet,
-			encoding: EncSortedSet,
-			value:    zset,
-		})
-		s.usedMemory += delta
-
-		return res, nil
-	}
-
-	zset := result.object.value.(types.ZSet)
+	zset := s.zsetForWrite(key, result, []string{member})
 	res, succeeded, delta := zset.ZIncrBy(member, increment)
 
 	if !succeeded {
@@ -89,7 +67,7 @@ func (s *store) ZIncrBy(key string, member string, increment float64) (float64,
 	return res, nil
 }
 
-func (s *store) ZLexCount(key string, minValue string, maxValue string) (uint32, error) {
+func (s *store) ZLexCount(key string, r types.ZLexRange) (uint32, error) {
 	zset, err := s.getZSet(key, false)
 	if err != nil {
 		return 0, err
@@ -99,7 +77,7 @@ func (s *store) ZLexCount(key string, minValue string, maxValue string) (uint32,
 		return 0, nil
 	}
 
-	return zset.ZLexCount(minValue, maxValue), nil
+	return zset.ZLexCount(r), nil
 }
 
 func (s *store) ZMScore(key string, members []string) ([]*float64, error) {
@@ -127,6 +105,9 @@ func (s *store) ZPopMax(key string, count int) ([]string, error) {
 
 	res, delta := zset.ZPopMax(count)
 	s.usedMemory += delta
+	if zset.ZCard() == 0 {
+		s.delete(key)
+	}
 	return res, nil
 }
 
@@ -142,9 +123,37 @@ func (s *store) ZPopMin(key string, count int) ([]string, error) {
 
 	res, delta := zset.ZPopMin(count)
 	s.usedMemory += delta
+	if zset.ZCard() == 0 {
+		s.delete(key)
+	}
 	return res, nil
 }
 
+// ZMPop pops up to count members with the lowest or highest scores from the first
+// non-empty sorted set among keys, and returns its key with the member and score pairs.
+// The key is empty when every sorted set is empty.
+func (s *store) ZMPop(keys []string, fromMin bool, count int) (string, []string, error) {
+	for _, key := range keys {
+		size, err := s.ZCard(key)
+		if err != nil {
+			return "", nil, err
+		}
+		if size == 0 {
+			continue
+		}
+
+		var popped []string
+		if fromMin {
+			popped, err = s.ZPopMin(key, count)
+		} else {
+			popped, err = s.ZPopMax(key, count)
+		}
+		return key, popped, err
+	}
+
+	return "", nil, nil
+}
+
 func (s *store) ZRandMember(key string, count int, withScores bool) ([]string, error) {
 	zset, err := s.getZSet(key, false)
 	if err != nil {
@@ -158,7 +167,7 @@ func (s *store) ZRandMember(key string, count int, withScores bool) ([]string, e
 	return zset.ZRandMember(count, withScores), nil
 }
 
-func (s *store) ZRangeByLex(key string, start string, stop string) ([]string, error) {
+func (s *store) ZRangeByLex(key string, r types.ZLexRange, offset, count int) ([]string, error) {
 	zset, err := s.getZSet(key, false)
 	if err != nil {
 		return nil, err
@@ -168,7 +177,7 @@ func (s *store) ZRangeByLex(key string, start string, stop string) ([]string, er
 		return []string{}, nil
 	}
 
-	return zset.ZRangeByLex(start, stop, false), nil
+	return zset.ZRangeByLex(r, offset, count, false), nil
 }
 
 func (s *store) ZRangeByRank(key string, start int, stop int, withScores bool) ([]string, error) {
@@ -184,7 +193,7 @@ func (s *store) ZRangeByRank(key string, start int, stop int, withScores bool) (
 	return zset.ZRangeByRank(start, stop, withScores), nil
 }
 
-func (s *store) ZRangeByScore(key string, start float64, stop float64, withScores bool) ([]string, error) {
+func (s *store) ZRangeByScore(key string, r types.ZScoreRange, offset, count int, withScores bool) ([]string, error) {
 	zset, err := s.getZSet(key, false)
 	if err != nil {
 		return nil, err
@@ -194,7 +203,7 @@ func (s *store) ZRangeByScore(key string, start float64, stop float64, withScore
 		return []string{}, nil
 	}
 
-	return zset.ZRangeByScore(start, stop, withScores), nil
+	return zset.ZRangeByScore(r, offset, count, withScores), nil
 }
 
 func (s *store) ZRank(key string, member string, withScore bool) ([]any, error) {
@@ -222,10 +231,105 @@ func (s *store) ZRem(key string, members []string) (uint32, error) {
 
 	res, delta := zset.ZRem(members)
 	s.usedMemory += delta
+	if zset.ZCard() == 0 {
+		s.delete(key)
+	}
 	return uint32(res), nil
 }
 
-func (s *store) ZRevRangeByLex(key string, start string, stop string) ([]string, error) {
+// ZRangeBy is the order a ZRangeSpec selects members by.
+type ZRangeBy int
+
+const (
+	ZRangeRank ZRangeBy = iota
+	ZRangeScore
+	ZRangeLex
+)
+
+// ZRangeSpec selects members of a sorted set like the arguments of ZRANGE. Only the bounds
+// of its By order are used, and Offset and Count only apply by score or lex.
+type ZRangeSpec struct {
+	By     ZRangeBy
+	Rev    bool
+	Start  int // by rank, negative from the end
+	Stop   int
+	Score  types.ZScoreRange
+	Lex    types.ZLexRange
+	Offset int
+	Count  int // negative for no limit
+}
+
+// ZRange returns the members of the sorted set at key selected by spec, in the order of
+// spec.
+func (s *store) ZRange(key string, spec ZRangeSpec, withScores bool) ([]string, error) {
+	zset, err := s.getZSet(key, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if zset == nil {
+		return []string{}, nil
+	}
+
+	return zsetRange(zset, spec, withScores), nil
+}
+
+// ZRangeStore stores the members of the sorted set at src selected by spec at dst, see
+// storeZSet.
+func (s *store) ZRangeStore(dst, src string, spec ZRangeSpec) (uint32, error) {
+	memberScores, err := s.ZRange(src, spec, true)
+	if err != nil {
+		return 0, err
+	}
+
+	zset := types.NewZSet()
+	zset.ZAdd(pairsScoreMember(memberScores), types.ZAddOptions{})
+	return s.storeZSet(dst, zset)
+}
+
+// ZRemRangeByRank removes the members ranked between start and stop, both included and
+// negative from the end, and returns their number.
+func (s *store) ZRemRangeByRank(key string, start, stop int) (uint32, error) {
+	return s.zremRange(key, func(zset types.ZSet) (int, int64) {
+		return zset.ZRemRangeByRank(start, stop)
+	})
+}
+
+// ZRemRangeByScore removes the members within the score range r and returns their number.
+func (s *store) ZRemRangeByScore(key string, r types.ZScoreRange) (uint32, error) {
+	return s.zremRange(key, func(zset types.ZSet) (int, int64) {
+		return zset.ZRemRangeByScore(r)
+	})
+}
+
+// ZRemRangeByLex removes the members within the lex range r and returns their number.
+func (s *store) ZRemRangeByLex(key string, r types.ZLexRange) (uint32, error) {
+	return s.zremRange(key, func(zset types.ZSet) (int, int64) {
+		return zset.ZRemRangeByLex(r)
+	})
+}
+
+// zremRange removes members of the sorted set at key with remove, and deletes the key once
+// it is empty.
+func (s *store) zremRange(key string, remove func(zset types.ZSet) (int, int64)) (uint32, error) {
+	zset, err := s.getZSet(key, true)
+	if err != nil {
+		return 0, err
+	}
+
+	if zset == nil {
+		return 0, nil
+	}
+
+	removed, delta := remove(zset)
+	s.usedMemory += delta
+	if zset.ZCard() == 0 {
+		s.delete(key)
+	}
+	return uint32(removed), nil
+}
+
+func (s *store) ZRevRangeByLex(key string, r types.ZLexRange, offset, count int) ([]string, error) {
 	zset, err := s.getZSet(key, false)
 	if err != nil {
 		return nil, err
@@ -235,7 +339,7 @@ func (s *store) ZRevRangeByLex(key string, start string, stop string) ([]string,
 		return []string{}, nil
 	}
 
-	return zset.ZRevRangeByLex(start, stop, false), nil
+	return zset.ZRevRangeByLex(r, offset, count, false), nil
 }
 
 func (s *store) ZRevRangeByRank(key string, start int, stop int, withScores bool) ([]string, error) {
@@ -251,7 +355,7 @@ func (s *store) ZRevRangeByRank(key string, start int, stop int, withScores bool
 	return zset.ZRevRangeByRank(start, stop, withScores), nil
 }
 
-func (s *store) ZRevRangeByScore(key string, start float64, stop float64, withScores bool) ([]string, error) {
+func (s *store) ZRevRangeByScore(key string, r types.ZScoreRange, offset, count int, withScores bool) ([]string, error) {
 	zset, err := s.getZSet(key, false)
 	if err != nil {
 		return nil, err
@@ -261,7 +365,7 @@ func (s *store) ZRevRangeByScore(key string, start float64, stop float64, withSc
 		return []string{}, nil
 	}
 
-	return zset.ZRevRangeByScore(start, stop, withScores), nil
+	return zset.ZRevRangeByScore(r, offset, count, withScores), nil
 }
 
 func (s *store) ZRevRank(key string, member string, withScore bool) ([]any, error) {
@@ -290,6 +394,148 @@ func (s *store) ZScore(key string, member string) (*float64, error) {
 	return zset.ZScore(member), nil
 }
 
+// ZScan returns the next cursor and up to count member-score pairs whose member matches
+// pattern, see Scan.
+func (s *store) ZScan(key string, cursor uint64, pattern string, count int) (uint64, []string, error) {
+	zset, err := s.getZSet(key, false)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if zset == nil {
+		return 0, []string{}, nil
+	}
+
+	next, pairs := zset.ZScan(cursor, count)
+	return next, scanMatchPairs(pattern, pairs, true), nil
+}
+
+// ZUnion returns the members of the union of the sorted sets or sets at keys, ordered by
+// score, see types.ZSetUnion. A nil weights weighs every key 1.
+func (s *store) ZUnion(keys []string, weights []float64, aggregate types.ZAggregate, withScores bool) ([]string, error) {
+	operands, err := s.zsetOperands(keys, weights)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.ZSetUnion(operands, aggregate).ZRangeByRank(0, -1, withScores), nil
+}
+
+// ZInter returns the members of the intersection of the sorted sets or sets at keys,
+// ordered by score, see types.ZSetIntersection. A nil weights weighs every key 1.
+func (s *store) ZInter(keys []string, weights []float64, aggregate types.ZAggregate, withScores bool) ([]string, error) {
+	operands, err := s.zsetOperands(keys, weights)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.ZSetIntersection(operands, aggregate).ZRangeByRank(0, -1, withScores), nil
+}
+
+// ZInterCard returns the size of the intersection of the sorted sets or sets at keys,
+// counting at most limit members when limit is positive.
+func (s *store) ZInterCard(limit int, keys ...string) (uint32, error) {
+	operands, err := s.zsetOperands(keys, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(types.ZSetIntersectionCard(operands, limit)), nil
+}
+
+// ZDiff returns the members of the first sorted set or set at keys that are in none of the
+// others, ordered by score.
+func (s *store) ZDiff(keys []string, withScores bool) ([]string, error) {
+	operands, err := s.zsetOperands(keys, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.ZSetDifference(operands).ZRangeByRank(0, -1, withScores), nil
+}
+
+// ZUnionStore stores the union of the sorted sets or sets at keys at dst, see storeZSet.
+func (s *store) ZUnionStore(dst string, keys []string, weights []float64, aggregate types.ZAggregate) (uint32, error) {
+	operands, err := s.zsetOperands(keys, weights)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.storeZSet(dst, types.ZSetUnion(operands, aggregate))
+}
+
+// ZInterStore stores the intersection of the sorted sets or sets at keys at dst, see
+// storeZSet.
+func (s *store) ZInterStore(dst string, keys []string, weights []float64, aggregate types.ZAggregate) (uint32, error) {
+	operands, err := s.zsetOperands(keys, weights)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.storeZSet(dst, types.ZSetIntersection(operands, aggregate))
+}
+
+// ZDiffStore stores the difference of the sorted sets or sets at keys at dst, see
+// storeZSet.
+func (s *store) ZDiffStore(dst string, keys []string) (uint32, error) {
+	operands, err := s.zsetOperands(keys, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.storeZSet(dst, types.ZSetDifference(operands))
+}
+
+// storeZSet replaces dst, whatever its type, with zset, converted to a listpack when it
+// fits one, and returns the size of the sorted set. An empty sorted set deletes dst.
+func (s *store) storeZSet(dst string, zset types.ZSet) (uint32, error) {
+	if result := s.access(dst, ObjAny, true); result.err != nil {
+		return 0, result.err
+	}
+
+	if zset.ZCard() == 0 {
+		s.delete(dst)
+		return 0, nil
+	}
+
+	rObj := &RObj{objType: ObjZSet, encoding: EncSortedSet, value: zset}
+	members := zset.ZRangeByRank(0, -1, false)
+	if listPackFits(len(members), s.config.ZSetMaxListpackEntries, s.config.ZSetMaxListpackValue, members...) {
+		rObj = newZSetObjectOf(s.config, zsetScoreMember(zset))
+	}
+
+	s.setObject(dst, rObj, 0, false)
+	return zset.ZCard(), nil
+}
+
+// zsetOperands returns the sorted sets or sets at keys, each weighted by the weight at the
+// same position, or 1 when weights is nil. Keys that do not exist are empty operands.
+func (s *store) zsetOperands(keys []string, weights []float64) ([]types.ZSetOperand, error) {
+	operands := make([]types.ZSetOperand, len(keys))
+	for i, key := range keys {
+		operands[i].Weight = 1
+		if weights != nil {
+			operands[i].Weight = weights[i]
+		}
+
+		result := s.access(key, ObjAny, false)
+		if !result.exists {
+			continue
+		}
+
+		switch result.object.objType {
+		case ObjZSet:
+			operands[i].ZSet = result.object.value.(types.ZSet)
+		case ObjSet:
+			operands[i].Set = result.object.value.(types.Set)
+		default:
+			return nil, ErrWrongTypeError
+		}
+	}
+
+	return operands, nil
+}
+
 // getZSet is a helper that uses centralized access for expiration and type checking
 func (s *store) getZSet(key string, isWrite bool) (types.ZSet, error) {
 	result := s.access(key, ObjZSet, isWrite)
@@ -304,3 +550,80 @@ func (s *store) getZSet(key string, isWrite bool) (types.ZSet, error) {
 	zset := result.object.value.(types.ZSet)
 	return zset, nil
 }
+
+// newZSetObject returns an empty sorted set in the encoding that fits members.
+func newZSetObject(cfg *config.Config, members []string) *RObj {
+	if listPackFits(len(members), cfg.ZSetMaxListpackEntries, cfg.ZSetMaxListpackValue, members...) {
+		return &RObj{objType: ObjZSet, encoding: EncListPack, value: types.NewListPackZSet()}
+	}
+	return &RObj{objType: ObjZSet, encoding: EncSortedSet, value: types.NewZSet()}
+}
+
+// newZSetObjectOf returns a sorted set of the members of scoreMember in the encoding that
+// fits them.
+func newZSetObjectOf(cfg *config.Config, scoreMember map[string]float64) *RObj {
+	members := make([]string, 0, len(scoreMember))
+	for member := range scoreMember {
+		members = append(members, member)
+	}
+
+	rObj := newZSetObject(cfg, members)
+	rObj.value.(types.ZSet).ZAdd(scoreMember, types.ZAddOptions{})
+	return rObj
+}
+
+// zsetForWrite returns the sorted set at key to add members to. The sorted set is created
+// when key does not exist, and converted to a skiplist first when the members would not fit
+// its listpack.
+func (s *store) zsetForWrite(key string, result storageAccessResult, members []string) types.ZSet {
+	if !result.exists {
+		rObj := newZSetObject(s.config, members)
+		s.usedMemory += s.data.Set(key, rObj)
+		return rObj.value.(types.ZSet)
+	}
+
+	rObj := result.object
+	zset := rObj.value.(types.ZSet)
+	if rObj.encoding == EncListPack &&
+		!listPackFits(int(zset.ZCard())+len(members), s.config.ZSetMaxListpackEntries, s.config.ZSetMaxListpackValue, members...) {
+		converted := types.NewZSet()
+		converted.ZAdd(zsetScoreMember(zset), types.ZAddOptions{})
+		s.convertObject(rObj, EncSortedSet, converted)
+		return converted
+	}
+
+	return zset
+}
+
+// zsetScoreMember returns the scores of the members of zset.
+func zsetScoreMember(zset types.ZSet) map[string]float64 {
+	return pairsScoreMember(zset.ZRangeByRank(0, -1, true))
+}
+
+// pairsScoreMember returns the scores of memberScores, members each followed by its score
+// as the range commands reply them.
+func pairsScoreMember(memberScores []string) map[string]float64 {
+	scoreMember := make(map[string]float64, len(memberScores)/2)
+	for i := 0; i < len(memberScores); i += 2 {
+		score, _ := strconv.ParseFloat(memberScores[i+1], 64)
+		scoreMember[memberScores[i]] = score
+	}
+	return scoreMember
+}
+
+// zsetRange returns the members of zset selected by spec.
+func zsetRange(zset types.ZSet, spec ZRangeSpec, withScores bool) []string {
+	switch {
+	case spec.By == ZRangeScore && spec.Rev:
+		return zset.ZRevRangeByScore(spec.Score, spec.Offset, spec.Count, withScores)
+	case spec.By == ZRangeScore:
+		return zset.ZRangeByScore(spec.Score, spec.Offset, spec.Count, withScores)
+	case spec.By == ZRangeLex && spec.Rev:
+		return zset.ZRevRangeByLex(spec.Lex, spec.Offset, spec.Count, withScores)
+	case spec.By == ZRangeLex:
+		return zset.ZRangeByLex(spec.Lex, spec.Offset, spec.Count, withScores)
+	case spec.Rev:
+		return zset.ZRevRangeByRank(spec.Start, spec.Stop, withScores)
+	}
+	return zset.ZRangeByRank(spec.Start, spec.Stop, withScores)
+}
@@ -13,6 +13,14 @@ func newTestStoreZSet() Store {
 	return NewStore(config.NewConfig())
 }
 
+// allLex is the lex range from "-" to "+".
+var allLex = types.ZLexRange{Min: types.ZLexBound{Inf: -1}, Max: types.ZLexBound{Inf: 1}}
+
+// zsetLexRange returns the lex range between min and max, both included.
+func zsetLexRange(min, max string) types.ZLexRange {
+	return types.ZLexRange{Min: types.ZLexBound{Value: min}, Max: types.ZLexBound{Value: max}}
+}
+
 // TestZAdd
 func TestZAdd_NewKey(t *testing.T) {
 	s := newTestStoreZSet()
@@ -153,7 +161,7 @@ func TestZCount_InRange(t *testing.T) {
 	s := newTestStoreZSet()
 	s.ZAdd("z", map[string]float64{"a": 1, "b": 2, "c": 3, "d": 4}, types.ZAddOptions{})
 
-	count, err := s.ZCount("z", 2, 3)
+	count, err := s.ZCount("z", types.ZScoreRange{Min: 2, Max: 3})
 	assert.NoError(t, err)
 	assert.Equal(t, uint32(2), count)
 }
@@ -162,7 +170,7 @@ func TestZCount_NoMembersInRange(t *testing.T) {
 	s := newTestStoreZSet()
 	s.ZAdd("z", map[string]float64{"a": 1, "b": 2}, types.ZAddOptions{})
 
-	count, err := s.ZCount("z", 5, 10)
+	count, err := s.ZCount("z", types.ZScoreRange{Min: 5, Max: 10})
 	assert.NoError(t, err)
 	assert.Equal(t, uint32(0), count)
 }
@@ -170,7 +178,7 @@ func TestZCount_NoMembersInRange(t *testing.T) {
 func TestZCount_NonExistentKey(t *testing.T) {
 	s := newTestStoreZSet()
 
-	count, err := s.ZCount("nonexistent", 0, 10)
+	count, err := s.ZCount("nonexistent", types.ZScoreRange{Min: 0, Max: 10})
 	assert.NoError(t, err)
 	assert.Equal(t, uint32(0), count)
 }
@@ -179,7 +187,7 @@ func TestZCount_WrongType(t *testing.T) {
 	s := newTestStoreZSet().(*store)
 	s.data.Set("key1", &RObj{objType: ObjString, encoding: EncRaw, value: "string"})
 
-	count, err := s.ZCount("key1", 0, 10)
+	count, err := s.ZCount("key1", types.ZScoreRange{Min: 0, Max: 10})
 	assert.Error(t, err)
 	assert.Equal(t, uint32(0), count)
 }
@@ -340,6 +348,16 @@ func TestZRem_NonExistentMembers(t *testing.T) {
 	assert.Equal(t, uint32(0), removed)
 }
 
+func TestZRem_AllMembersDeletesKey(t *testing.T) {
+	s := newTestStoreZSet()
+	s.ZAdd("z", map[string]float64{"a": 1, "b": 2}, types.ZAddOptions{})
+
+	removed, err := s.ZRem("z", []string{"a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), removed)
+	assert.False(t, s.Exists("z"))
+}
+
 func TestZRem_NonExistentKey(t *testing.T) {
 	s := newTestStoreZSet()
 
@@ -501,7 +519,7 @@ func TestZRangeByScore_WithoutScores(t *testing.T) {
 	s := newTestStoreZSet()
 	s.ZAdd("z", map[string]float64{"a": 1, "b": 2, "c": 3}, types.ZAddOptions{})
 
-	result, err := s.ZRangeByScore("z", 1.5, 3.0, false)
+	result, err := s.ZRangeByScore("z", types.ZScoreRange{Min: 1.5, Max: 3.0}, 0, -1, false)
 	assert.NoError(t, err)
 	assert.Equal(t, []string{"b", "c"}, result)
 }
@@ -510,7 +528,7 @@ func TestZRangeByScore_WithScores(t *testing.T) {
 	s := newTestStoreZSet()
 	s.ZAdd("z", map[string]float64{"a": 1, "b": 2}, types.ZAddOptions{})
 
-	result, err := s.ZRangeByScore("z", 0, 10, true)
+	result, err := s.ZRangeByScore("z", types.ZScoreRange{Min: 0, Max: 10}, 0, -1, true)
 	assert.NoError(t, err)
 	assert.Equal(t, []string{"a", "1", "b", "2"}, result)
 }
@@ -519,7 +537,7 @@ func TestZRangeByScore_NoMembersInRange(t *testing.T) {
 	s := newTestStoreZSet()
 	s.ZAdd("z", map[string]float64{"a": 1, "b": 2}, types.ZAddOptions{})
 
-	result, err := s.ZRangeByScore("z", 5, 10, false)
+	result, err := s.ZRangeByScore("z", types.ZScoreRange{Min: 5, Max: 10}, 0, -1, false)
 	assert.NoError(t, err)
 	assert.Empty(t, result)
 }
@@ -527,7 +545,7 @@ func TestZRangeByScore_NoMembersInRange(t *testing.T) {
 func TestZRangeByScore_NonExistentKey(t *testing.T) {
 	s := newTestStoreZSet()
 
-	result, err := s.ZRangeByScore("nonexistent", 0, 10, false)
+	result, err := s.ZRangeByScore("nonexistent", types.ZScoreRange{Min: 0, Max: 10}, 0, -1, false)
 	assert.NoError(t, err)
 	assert.Empty(t, result)
 }
@@ -536,7 +554,7 @@ func TestZRangeByScore_WrongType(t *testing.T) {
 	s := newTestStoreZSet().(*store)
 	s.data.Set("key1", &RObj{objType: ObjString, encoding: EncRaw, value: "string"})
 
-	result, err := s.ZRangeByScore("key1", 0, 10, false)
+	result, err := s.ZRangeByScore("key1", types.ZScoreRange{Min: 0, Max: 10}, 0, -1, false)
 	assert.Error(t, err)
 	assert.Nil(t, result)
 }
@@ -582,7 +600,7 @@ func TestZRevRangeByScore_WithoutScores(t *testing.T) {
 	s := newTestStoreZSet()
 	s.ZAdd("z", map[string]float64{"a": 1, "b": 2, "c": 3}, types.ZAddOptions{})
 
-	result, err := s.ZRevRangeByScore("z", 3, 1.5, false)
+	result, err := s.ZRevRangeByScore("z", types.ZScoreRange{Min: 1.5, Max: 3}, 0, -1, false)
 	assert.NoError(t, err)
 	assert.Equal(t, []string{"c", "b"}, result)
 }
@@ -591,7 +609,7 @@ func TestZRevRangeByScore_WithScores(t *testing.T) {
 	s := newTestStoreZSet()
 	s.ZAdd("z", map[string]float64{"a": 1, "b": 2}, types.ZAddOptions{})
 
-	result, err := s.ZRevRangeByScore("z", 10, 0, true)
+	result, err := s.ZRevRangeByScore("z", types.ZScoreRange{Min: 0, Max: 10}, 0, -1, true)
 	assert.NoError(t, err)
 	assert.Equal(t, []string{"b", "2", "a", "1"}, result)
 }
@@ -599,7 +617,7 @@ func TestZRevRangeByScore_WithScores(t *testing.T) {
 func TestZRevRangeByScore_NonExistentKey(t *testing.T) {
 	s := newTestStoreZSet()
 
-	result, err := s.ZRevRangeByScore("nonexistent", 10, 0, false)
+	result, err := s.ZRevRangeByScore("nonexistent", types.ZScoreRange{Min: 0, Max: 10}, 0, -1, false)
 	assert.NoError(t, err)
 	assert.Empty(t, result)
 }
@@ -608,7 +626,7 @@ func TestZRevRangeByScore_WrongType(t *testing.T) {
 	s := newTestStoreZSet().(*store)
 	s.data.Set("key1", &RObj{objType: ObjString, encoding: EncRaw, value: "string"})
 
-	result, err := s.ZRevRangeByScore("key1", 10, 0, false)
+	result, err := s.ZRevRangeByScore("key1", types.ZScoreRange{Min: 0, Max: 10}, 0, -1, false)
 	assert.Error(t, err)
 	assert.Nil(t, result)
 }
@@ -645,6 +663,7 @@ func TestZPopMin_MoreThanAvailable(t *testing.T) {
 	result, err := s.ZPopMin("z", 5)
 	assert.NoError(t, err)
 	assert.Len(t, result, 4)
+	assert.False(t, s.Exists("z"), "emptied sorted set must be deleted")
 }
 
 func TestZPopMin_NonExistentKey(t *testing.T) {
@@ -756,7 +775,7 @@ func TestZLexCount_InRange(t *testing.T) {
 	s := newTestStoreZSet()
 	s.ZAdd("z", map[string]float64{"apple": 0, "banana": 0, "cherry": 0, "date": 0}, types.ZAddOptions{})
 
-	count, err := s.ZLexCount("z", "a", "c")
+	count, err := s.ZLexCount("z", zsetLexRange("a", "c"))
 	assert.NoError(t, err)
 	assert.Equal(t, uint32(2), count)
 }
@@ -765,7 +784,7 @@ func TestZLexCount_AllMembers(t *testing.T) {
 	s := newTestStoreZSet()
 	s.ZAdd("z", map[string]float64{"a": 0, "b": 0, "c": 0}, types.ZAddOptions{})
 
-	count, err := s.ZLexCount("z", "", "~")
+	count, err := s.ZLexCount("z", allLex)
 	assert.NoError(t, err)
 	assert.Equal(t, uint32(3), count)
 }
@@ -773,7 +792,7 @@ func TestZLexCount_AllMembers(t *testing.T) {
 func TestZLexCount_NonExistentKey(t *testing.T) {
 	s := newTestStoreZSet()
 
-	count, err := s.ZLexCount("nonexistent", "a", "z")
+	count, err := s.ZLexCount("nonexistent", zsetLexRange("a", "z"))
 	assert.NoError(t, err)
 	assert.Equal(t, uint32(0), count)
 }
@@ -782,7 +801,7 @@ func TestZLexCount_WrongType(t *testing.T) {
 	s := newTestStoreZSet().(*store)
 	s.data.Set("key1", &RObj{objType: ObjString, encoding: EncRaw, value: "string"})
 
-	count, err := s.ZLexCount("key1", "a", "z")
+	count, err := s.ZLexCount("key1", zsetLexRange("a", "z"))
 	assert.Error(t, err)
 	assert.Equal(t, uint32(0), count)
 }
@@ -792,7 +811,7 @@ func TestZRangeByLex_InRange(t *testing.T) {
 	s := newTestStoreZSet()
 	s.ZAdd("z", map[string]float64{"apple": 0, "banana": 0, "cherry": 0, "date": 0}, types.ZAddOptions{})
 
-	result, err := s.ZRangeByLex("z", "b", "d")
+	result, err := s.ZRangeByLex("z", zsetLexRange("b", "d"), 0, -1)
 	assert.NoError(t, err)
 	assert.Equal(t, []string{"banana", "cherry"}, result)
 }
@@ -801,7 +820,7 @@ func TestZRangeByLex_AllMembers(t *testing.T) {
 	s := newTestStoreZSet()
 	s.ZAdd("z", map[string]float64{"a": 0, "b": 0, "c": 0}, types.ZAddOptions{})
 
-	result, err := s.ZRangeByLex("z", "", "~")
+	result, err := s.ZRangeByLex("z", allLex, 0, -1)
 	assert.NoError(t, err)
 	assert.Equal(t, []string{"a", "b", "c"}, result)
 }
@@ -809,7 +828,7 @@ func TestZRangeByLex_AllMembers(t *testing.T) {
 func TestZRangeByLex_NonExistentKey(t *testing.T) {
 	s := newTestStoreZSet()
 
-	result, err := s.ZRangeByLex("nonexistent", "a", "z")
+	result, err := s.ZRangeByLex("nonexistent", zsetLexRange("a", "z"), 0, -1)
 	assert.NoError(t, err)
 	assert.Empty(t, result)
 }
@@ -818,7 +837,7 @@ func TestZRangeByLex_WrongType(t *testing.T) {
 	s := newTestStoreZSet().(*store)
 	s.data.Set("key1", &RObj{objType: ObjString, encoding: EncRaw, value: "string"})
 
-	result, err := s.ZRangeByLex("key1", "a", "z")
+	result, err := s.ZRangeByLex("key1", zsetLexRange("a", "z"), 0, -1)
 	assert.Error(t, err)
 	assert.Nil(t, result)
 }
@@ -828,7 +847,7 @@ func TestZRevRangeByLex_InRange(t *testing.T) {
 	s := newTestStoreZSet()
 	s.ZAdd("z", map[string]float64{"apple": 0, "banana": 0, "cherry": 0, "date": 0}, types.ZAddOptions{})
 
-	result, err := s.ZRevRangeByLex("z", "d", "b")
+	result, err := s.ZRevRangeByLex("z", zsetLexRange("b", "d"), 0, -1)
 	assert.NoError(t, err)
 	assert.Equal(t, []string{"cherry", "banana"}, result)
 }
@@ -837,7 +856,7 @@ func TestZRevRangeByLex_AllMembersReversed(t *testing.T) {
 	s := newTestStoreZSet()
 	s.ZAdd("z", map[string]float64{"a": 0, "b": 0, "c": 0}, types.ZAddOptions{})
 
-	result, err := s.ZRevRangeByLex("z", "~", "")
+	result, err := s.ZRevRangeByLex("z", allLex, 0, -1)
 	assert.NoError(t, err)
 	assert.Equal(t, []string{"c", "b", "a"}, result)
 }
@@ -845,7 +864,7 @@ func TestZRevRangeByLex_AllMembersReversed(t *testing.T) {
 func TestZRevRangeByLex_NonExistentKey(t *testing.T) {
 	s := newTestStoreZSet()
 
-	result, err := s.ZRevRangeByLex("nonexistent", "z", "a")
+	result, err := s.ZRevRangeByLex("nonexistent", zsetLexRange("a", "z"), 0, -1)
 	assert.NoError(t, err)
 	assert.Empty(t, result)
 }
@@ -854,7 +873,7 @@ func TestZRevRangeByLex_WrongType(t *testing.T) {
 	s := newTestStoreZSet().(*store)
 	s.data.Set("key1", &RObj{objType: ObjString, encoding: EncRaw, value: "string"})
 
-	result, err := s.ZRevRangeByLex("key1", "z", "a")
+	result, err := s.ZRevRangeByLex("key1", zsetLexRange("a", "z"), 0, -1)
 	assert.Error(t, err)
 	assert.Nil(t, result)
 }
@@ -868,7 +887,7 @@ func TestZSetOperationsIntegration(t *testing.T) {
 	card, _ := s.ZCard("z")
 	assert.Equal(t, uint32(5), card)
 
-	count, _ := s.ZCount("z", 2, 4)
+	count, _ := s.ZCount("z", types.ZScoreRange{Min: 2, Max: 4})
 	assert.Equal(t, uint32(3), count)
 
 	s.ZIncrBy("z", "a", 10)
@@ -885,3 +904,197 @@ func TestZSetOperationsIntegration(t *testing.T) {
 	card, _ = s.ZCard("z")
 	assert.Equal(t, uint32(4), card)
 }
+
+// TestZUnion
+func TestZUnion_MixesSortedSetsAndSets(t *testing.T) {
+	s := newTestStoreZSet()
+	s.ZAdd("z1", map[string]float64{"a": 1, "b": 2}, types.ZAddOptions{})
+	s.ZAdd("z2", map[string]float64{"b": 3}, types.ZAddOptions{})
+	s.SAdd("set", "a", "c")
+
+	result, err := s.ZUnion([]string{"z1", "z2", "set", "missing"}, nil, types.ZAggregateSum, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "1", "a", "2", "b", "5"}, result)
+
+	result, err = s.ZUnion([]string{"z1", "z2"}, []float64{2, 1}, types.ZAggregateMax, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, result)
+}
+
+func TestZUnion_WrongType(t *testing.T) {
+	s := newTestStoreZSet()
+	s.ZAdd("z", map[string]float64{"a": 1}, types.ZAddOptions{})
+	s.Set("str", "value")
+
+	_, err := s.ZUnion([]string{"z", "str"}, nil, types.ZAggregateSum, false)
+	assert.Equal(t, ErrWrongTypeError, err)
+	_, err = s.ZUnionStore("dst", []string{"z", "str"}, nil, types.ZAggregateSum)
+	assert.Equal(t, ErrWrongTypeError, err)
+}
+
+// TestZInter
+func TestZInter(t *testing.T) {
+	s := newTestStoreZSet()
+	s.ZAdd("z1", map[string]float64{"a": 1, "b": 2, "c": 3}, types.ZAddOptions{})
+	s.ZAdd("z2", map[string]float64{"b": 4, "c": 1}, types.ZAddOptions{})
+
+	result, err := s.ZInter([]string{"z1", "z2"}, []float64{1, 2}, types.ZAggregateSum, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "5", "b", "10"}, result)
+
+	result, err = s.ZInter([]string{"z1", "missing"}, nil, types.ZAggregateSum, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, result)
+
+	card, err := s.ZInterCard(1, "z1", "z2")
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), card)
+}
+
+// TestZDiff
+func TestZDiff(t *testing.T) {
+	s := newTestStoreZSet()
+	s.ZAdd("z1", map[string]float64{"a": 1, "b": 2, "c": 3}, types.ZAddOptions{})
+	s.SAdd("set", "b")
+
+	result, err := s.ZDiff([]string{"z1", "set", "missing"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "1", "c", "3"}, result)
+
+	result, err = s.ZDiff([]string{"missing", "z1"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, result)
+}
+
+// TestZUnionStore
+func TestZUnionStore_ReplacesDestination(t *testing.T) {
+	s := newTestStoreZSet().(*store)
+	s.ZAdd("z1", map[string]float64{"a": 1}, types.ZAddOptions{})
+	s.ZAdd("z2", map[string]float64{"a": 2, "b": 3}, types.ZAddOptions{})
+	s.Set("dst", "value")
+
+	size, err := s.ZUnionStore("dst", []string{"z1", "z2"}, nil, types.ZAggregateSum)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), size)
+	assertEncoding(t, s, "dst", EncListPack)
+
+	result, _ := s.ZRangeByRank("dst", 0, -1, true)
+	assert.Equal(t, []string{"a", "3", "b", "3"}, result)
+}
+
+func TestZInterStore_EmptyResultDeletesDestination(t *testing.T) {
+	s := newTestStoreZSet()
+	s.ZAdd("z1", map[string]float64{"a": 1}, types.ZAddOptions{})
+	s.ZAdd("z2", map[string]float64{"b": 1}, types.ZAddOptions{})
+	s.ZAdd("dst", map[string]float64{"x": 1}, types.ZAddOptions{})
+
+	size, err := s.ZInterStore("dst", []string{"z1", "z2"}, nil, types.ZAggregateSum)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), size)
+	assert.False(t, s.Exists("dst"))
+}
+
+func TestZDiffStore_LargeResultKeepsSkipList(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.ZSetMaxListpackEntries = 2
+	s := NewStore(cfg).(*store)
+	s.ZAdd("z1", map[string]float64{"a": 1, "b": 2, "c": 3, "d": 4}, types.ZAddOptions{})
+	s.ZAdd("z2", map[string]float64{"d": 1}, types.ZAddOptions{})
+
+	size, err := s.ZDiffStore("z1", []string{"z1", "z2"})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), size)
+	assertEncoding(t, s, "z1", EncSortedSet)
+}
+
+// TestZRange
+func TestZRange_Spec(t *testing.T) {
+	s := newTestStoreZSet()
+	s.ZAdd("z", map[string]float64{"a": 1, "b": 2, "c": 3, "d": 4}, types.ZAddOptions{})
+
+	result, err := s.ZRange("z", ZRangeSpec{By: ZRangeRank, Rev: true, Start: 0, Stop: 1}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d", "c"}, result)
+
+	score := types.ZScoreRange{Min: 1, Max: 4, MinEx: true}
+	result, _ = s.ZRange("z", ZRangeSpec{By: ZRangeScore, Score: score, Offset: 1, Count: 1}, true)
+	assert.Equal(t, []string{"c", "3"}, result)
+
+	result, _ = s.ZRange("z", ZRangeSpec{By: ZRangeScore, Rev: true, Score: score, Count: -1}, false)
+	assert.Equal(t, []string{"d", "c", "b"}, result)
+
+	result, _ = s.ZRange("z", ZRangeSpec{By: ZRangeLex, Lex: zsetLexRange("b", "c"), Count: -1}, false)
+	assert.Equal(t, []string{"b", "c"}, result)
+}
+
+// TestZRangeStore
+func TestZRangeStore(t *testing.T) {
+	s := newTestStoreZSet().(*store)
+	s.ZAdd("z", map[string]float64{"a": 1, "b": 2, "c": 3}, types.ZAddOptions{})
+	s.Set("dst", "value")
+
+	size, err := s.ZRangeStore("dst", "z", ZRangeSpec{Start: 1, Stop: -1})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), size)
+	assertEncoding(t, s, "dst", EncListPack)
+	result, _ := s.ZRangeByRank("dst", 0, -1, true)
+	assert.Equal(t, []string{"b", "2", "c", "3"}, result)
+
+	size, err = s.ZRangeStore("dst", "missing", ZRangeSpec{Start: 0, Stop: -1})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), size)
+	assert.False(t, s.Exists("dst"))
+}
+
+// TestZRemRange
+func TestZRemRange(t *testing.T) {
+	s := newTestStoreZSet()
+	s.ZAdd("z", map[string]float64{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5}, types.ZAddOptions{})
+
+	removed, err := s.ZRemRangeByRank("z", -2, -1)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), removed)
+
+	removed, _ = s.ZRemRangeByScore("z", types.ZScoreRange{Min: 1, Max: 3, MaxEx: true})
+	assert.Equal(t, uint32(2), removed)
+
+	removed, _ = s.ZRemRangeByLex("missing", zsetLexRange("a", "z"))
+	assert.Equal(t, uint32(0), removed)
+
+	removed, _ = s.ZRemRangeByLex("z", types.ZLexRange{Min: types.ZLexBound{Value: "a", Exclusive: true}, Max: types.ZLexBound{Inf: 1}})
+	assert.Equal(t, uint32(1), removed)
+	assert.False(t, s.Exists("z"))
+}
+
+func TestZRemRange_WrongType(t *testing.T) {
+	s := newTestStoreZSet()
+	s.Set("key1", "value")
+
+	_, err := s.ZRemRangeByRank("key1", 0, -1)
+	assert.Equal(t, ErrWrongTypeError, err)
+}
+
+// TestZMPop
+func TestZMPop(t *testing.T) {
+	s := newTestStoreZSet()
+	s.ZAdd("z2", map[string]float64{"a": 1, "b": 2, "c": 3}, types.ZAddOptions{})
+
+	key, popped, err := s.ZMPop([]string{"z1", "z2"}, true, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "z2", key)
+	assert.Equal(t, []string{"a", "1", "b", "2"}, popped)
+
+	key, popped, _ = s.ZMPop([]string{"z1", "z2"}, false, 10)
+	assert.Equal(t, "z2", key)
+	assert.Equal(t, []string{"c", "3"}, popped)
+	assert.False(t, s.Exists("z2"))
+
+	key, popped, _ = s.ZMPop([]string{"z1", "z2"}, true, 1)
+	assert.Equal(t, "", key)
+	assert.Nil(t, popped)
+
+	s.Set("str", "value")
+	s.ZAdd("z3", map[string]float64{"a": 1}, types.ZAddOptions{})
+	_, _, err = s.ZMPop([]string{"str", "z3"}, true, 1)
+	assert.ErrorIs(t, err, ErrWrongTypeError)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/google/wire"
 	"github.com/manhhung2111/go-redis/internal/command"
 	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/persistence"
 	"github.com/manhhung2111/go-redis/internal/server"
 	"github.com/manhhung2111/go-redis/internal/storage"
 )
@@ -17,12 +18,14 @@ import (
 // Injectors from wire.go:
 
 func InitializeServer(cfg *config.Config) (*server.Server, error) {
-	store := storage.NewStore(cfg)
-	redis := command.NewRedis(store)
-	serverServer := server.NewServer(cfg, redis)
+	databases := storage.NewDatabases(cfg)
+	rdb := persistence.NewRDB(cfg, databases)
+	aof := persistence.NewAOF(cfg, databases)
+	redis := command.NewRedis(databases, rdb, aof)
+	serverServer := server.NewServer(cfg, redis, rdb, aof)
 	return serverServer, nil
 }
 
 // wire.go:
 
-var WireSet = wire.NewSet(storage.WireSet, command.WireSet, server.WireSet)
+var WireSet = wire.NewSet(storage.WireSet, persistence.WireSet, command.WireSet, server.WireSet)
@@ -0,0 +1,232 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/manhhung2111/go-redis/internal/errors"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+)
+
+func TestSetBitGetBit(t *testing.T) {
+	r := newTestRedis()
+
+	resp := r.SetBit(cmd("SETBIT", "mykey", "7", "1"))
+	assert.Equal(t, protocol.EncodeResp(0, false), resp)
+
+	resp = r.SetBit(cmd("SETBIT", "mykey", "7", "0"))
+	assert.Equal(t, protocol.EncodeResp(1, false), resp)
+
+	resp = r.Get(cmd("GET", "mykey"))
+	assert.Equal(t, protocol.EncodeResp("\x00", false), resp)
+
+	r.SetBit(cmd("SETBIT", "mykey", "1", "1"))
+	resp = r.GetBit(cmd("GETBIT", "mykey", "1"))
+	assert.Equal(t, protocol.EncodeResp(1, false), resp)
+
+	resp = r.GetBit(cmd("GETBIT", "mykey", "100"))
+	assert.Equal(t, protocol.EncodeResp(0, false), resp)
+
+	resp = r.GetBit(cmd("GETBIT", "missing", "0"))
+	assert.Equal(t, protocol.EncodeResp(0, false), resp)
+}
+
+func TestSetBit_Errors(t *testing.T) {
+	r := newTestRedis()
+
+	resp := r.SetBit(cmd("SETBIT", "mykey", "1"))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidNumberOfArgs("SETBIT"), false), resp)
+
+	resp = r.SetBit(cmd("SETBIT", "mykey", "-1", "1"))
+	assert.Equal(t, protocol.RespBitOffsetOutOfRange, resp)
+
+	resp = r.SetBit(cmd("SETBIT", "mykey", "4294967296", "1"))
+	assert.Equal(t, protocol.RespBitOffsetOutOfRange, resp)
+
+	resp = r.SetBit(cmd("SETBIT", "mykey", "0", "2"))
+	assert.Equal(t, protocol.RespBitNotIntegerOrOutOfRange, resp)
+
+	r.LPush(cmd("LPUSH", "list", "a"))
+	resp = r.SetBit(cmd("SETBIT", "list", "0", "1"))
+	assert.Equal(t, protocol.RespWrongTypeOperation, resp)
+
+	resp = r.GetBit(cmd("GETBIT", "mykey", "abc"))
+	assert.Equal(t, protocol.RespBitOffsetOutOfRange, resp)
+}
+
+func TestBitCount(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "mykey", "foobar"))
+
+	tests := []struct {
+		args     []string
+		expected int64
+	}{
+		{[]string{"mykey"}, 26},
+		{[]string{"mykey", "0", "0"}, 4},
+		{[]string{"mykey", "1", "1"}, 6},
+		{[]string{"mykey", "1", "1", "BYTE"}, 6},
+		{[]string{"mykey", "5", "30", "BIT"}, 17},
+		{[]string{"missing"}, 0},
+	}
+
+	for _, tt := range tests {
+		resp := r.BitCount(cmd("BITCOUNT", tt.args...))
+		assert.Equal(t, protocol.EncodeResp(tt.expected, false), resp, "%v", tt.args)
+	}
+}
+
+func TestBitCount_Errors(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "mykey", "foobar"))
+
+	resp := r.BitCount(cmd("BITCOUNT"))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidNumberOfArgs("BITCOUNT"), false), resp)
+
+	resp = r.BitCount(cmd("BITCOUNT", "mykey", "0"))
+	assert.Equal(t, protocol.RespSyntaxError, resp)
+
+	resp = r.BitCount(cmd("BITCOUNT", "mykey", "0", "1", "WORD"))
+	assert.Equal(t, protocol.RespSyntaxError, resp)
+
+	resp = r.BitCount(cmd("BITCOUNT", "mykey", "a", "1"))
+	assert.Equal(t, protocol.RespValueNotIntegerOrOutOfRange, resp)
+}
+
+func TestBitPos(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "mykey", "\xff\xf0\x00"))
+
+	resp := r.BitPos(cmd("BITPOS", "mykey", "0"))
+	assert.Equal(t, protocol.EncodeResp(int64(12), false), resp)
+
+	r.Set(cmd("SET", "mykey", "\x00\xff\xf0"))
+	resp = r.BitPos(cmd("BITPOS", "mykey", "1", "0"))
+	assert.Equal(t, protocol.EncodeResp(int64(8), false), resp)
+
+	resp = r.BitPos(cmd("BITPOS", "mykey", "1", "2"))
+	assert.Equal(t, protocol.EncodeResp(int64(16), false), resp)
+
+	resp = r.BitPos(cmd("BITPOS", "mykey", "1", "2", "-1", "BYTE"))
+	assert.Equal(t, protocol.EncodeResp(int64(16), false), resp)
+
+	resp = r.BitPos(cmd("BITPOS", "mykey", "1", "7", "15", "BIT"))
+	assert.Equal(t, protocol.EncodeResp(int64(8), false), resp)
+
+	r.Set(cmd("SET", "mykey", "\x00\x00\x00"))
+	resp = r.BitPos(cmd("BITPOS", "mykey", "1"))
+	assert.Equal(t, protocol.EncodeResp(int64(-1), false), resp)
+
+	r.Set(cmd("SET", "mykey", "\xff\xff"))
+	resp = r.BitPos(cmd("BITPOS", "mykey", "0"))
+	assert.Equal(t, protocol.EncodeResp(int64(16), false), resp)
+
+	resp = r.BitPos(cmd("BITPOS", "mykey", "0", "0", "-1"))
+	assert.Equal(t, protocol.EncodeResp(int64(-1), false), resp)
+
+	resp = r.BitPos(cmd("BITPOS", "mykey", "2"))
+	assert.Equal(t, protocol.RespBitArgumentNotBinary, resp)
+}
+
+func TestBitOp(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "key1", "foobar"))
+	r.Set(cmd("SET", "key2", "abcdef"))
+
+	resp := r.BitOp(cmd("BITOP", "AND", "dest", "key1", "key2"))
+	assert.Equal(t, protocol.EncodeResp(int64(6), false), resp)
+	resp = r.Get(cmd("GET", "dest"))
+	assert.Equal(t, protocol.EncodeResp("`bc`ab", false), resp)
+
+	resp = r.BitOp(cmd("BITOP", "or", "dest", "key1", "missing"))
+	assert.Equal(t, protocol.EncodeResp(int64(6), false), resp)
+	resp = r.Get(cmd("GET", "dest"))
+	assert.Equal(t, protocol.EncodeResp("foobar", false), resp)
+
+	r.Set(cmd("SET", "key3", "\x0f"))
+	r.BitOp(cmd("BITOP", "NOT", "dest", "key3"))
+	resp = r.Get(cmd("GET", "dest"))
+	assert.Equal(t, protocol.EncodeResp("\xf0", false), resp)
+
+	resp = r.BitOp(cmd("BITOP", "NOT", "dest", "key1", "key2"))
+	assert.Equal(t, protocol.RespBitOpNotSingleSource, resp)
+
+	resp = r.BitOp(cmd("BITOP", "NAND", "dest", "key1"))
+	assert.Equal(t, protocol.RespSyntaxError, resp)
+
+	// An empty result deletes the destination
+	resp = r.BitOp(cmd("BITOP", "XOR", "dest", "missing"))
+	assert.Equal(t, protocol.EncodeResp(int64(0), false), resp)
+	resp = r.Exists(cmd("EXISTS", "dest"))
+	assert.Equal(t, protocol.EncodeResp(int64(0), false), resp)
+}
+
+func TestBitField(t *testing.T) {
+	r := newTestRedis()
+
+	resp := r.BitField(cmd("BITFIELD", "mykey", "INCRBY", "i5", "100", "1", "GET", "u4", "0"))
+	assert.Equal(t, protocol.EncodeResp([]any{int64(1), int64(0)}, false), resp)
+
+	resp = r.BitField(cmd("BITFIELD", "mykey", "SET", "u8", "#0", "255", "GET", "u8", "0"))
+	assert.Equal(t, protocol.EncodeResp([]any{int64(0), int64(255)}, false), resp)
+
+	resp = r.BitField(cmd("BITFIELD", "mykey", "INCRBY", "u2", "100", "1", "OVERFLOW", "SAT", "INCRBY", "u2", "102", "1"))
+	assert.Equal(t, protocol.EncodeResp([]any{int64(1), int64(1)}, false), resp)
+
+	resp = r.BitField(cmd("BITFIELD", "mykey", "INCRBY", "u2", "100", "1", "OVERFLOW", "SAT", "INCRBY", "u2", "102", "1"))
+	assert.Equal(t, protocol.EncodeResp([]any{int64(2), int64(2)}, false), resp)
+
+	resp = r.BitField(cmd("BITFIELD", "mykey", "INCRBY", "u2", "100", "1", "OVERFLOW", "SAT", "INCRBY", "u2", "102", "1"))
+	assert.Equal(t, protocol.EncodeResp([]any{int64(3), int64(3)}, false), resp)
+
+	resp = r.BitField(cmd("BITFIELD", "mykey", "INCRBY", "u2", "100", "1", "OVERFLOW", "SAT", "INCRBY", "u2", "102", "1"))
+	assert.Equal(t, protocol.EncodeResp([]any{int64(0), int64(3)}, false), resp)
+
+	resp = r.BitField(cmd("BITFIELD", "mykey", "OVERFLOW", "FAIL", "INCRBY", "u2", "102", "1"))
+	assert.Equal(t, protocol.EncodeResp([]any{nil}, false), resp)
+
+	resp = r.BitField(cmd("BITFIELD", "missing", "GET", "i8", "0"))
+	assert.Equal(t, protocol.EncodeResp([]any{int64(0)}, false), resp)
+	resp = r.Exists(cmd("EXISTS", "missing"))
+	assert.Equal(t, protocol.EncodeResp(int64(0), false), resp)
+}
+
+func TestBitField_Errors(t *testing.T) {
+	r := newTestRedis()
+
+	resp := r.BitField(cmd("BITFIELD"))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidNumberOfArgs("BITFIELD"), false), resp)
+
+	resp = r.BitField(cmd("BITFIELD", "mykey", "GET", "u64", "0"))
+	assert.Equal(t, protocol.RespInvalidBitFieldType, resp)
+
+	resp = r.BitField(cmd("BITFIELD", "mykey", "GET", "x8", "0"))
+	assert.Equal(t, protocol.RespInvalidBitFieldType, resp)
+
+	resp = r.BitField(cmd("BITFIELD", "mykey", "GET", "u8", "-1"))
+	assert.Equal(t, protocol.RespBitOffsetOutOfRange, resp)
+
+	resp = r.BitField(cmd("BITFIELD", "mykey", "OVERFLOW", "NONE", "SET", "u8", "0", "1"))
+	assert.Equal(t, protocol.RespInvalidOverflowType, resp)
+
+	resp = r.BitField(cmd("BITFIELD", "mykey", "SET", "u8", "0"))
+	assert.Equal(t, protocol.RespSyntaxError, resp)
+
+	resp = r.BitField(cmd("BITFIELD", "mykey", "DEL", "u8", "0"))
+	assert.Equal(t, protocol.RespSyntaxError, resp)
+}
+
+func TestBitFieldRO(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "mykey", "\xff"))
+
+	resp := r.BitFieldRO(cmd("BITFIELD_RO", "mykey", "GET", "u4", "0", "GET", "i4", "4"))
+	assert.Equal(t, protocol.EncodeResp([]any{int64(15), int64(-1)}, false), resp)
+
+	resp = r.BitFieldRO(cmd("BITFIELD_RO", "mykey", "SET", "u4", "0", "1"))
+	assert.Equal(t, protocol.RespBitFieldROOnlyGet, resp)
+
+	resp = r.BitFieldRO(cmd("BITFIELD_RO", "mykey", "OVERFLOW", "SAT"))
+	assert.Equal(t, protocol.RespBitFieldROOnlyGet, resp)
+}
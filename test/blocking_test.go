@@ -0,0 +1,214 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manhhung2111/go-redis/internal/command"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+)
+
+func TestBLPop_ServesImmediately(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+
+	r.RPush(cmd("RPUSH", "b", "x", "y"))
+
+	resp := r.HandleCommand(c, cmd("BLPOP", "a", "b", "0"))
+	assert.Equal(t, protocol.EncodeResp([]string{"b", "x"}, false), resp)
+	assert.False(t, c.IsBlocked())
+
+	resp = r.HandleCommand(c, cmd("BRPOP", "b", "0"))
+	assert.Equal(t, protocol.EncodeResp([]string{"b", "y"}, false), resp)
+}
+
+func TestBLPop_BlocksUntilPush(t *testing.T) {
+	r := newTestRedis()
+	waiter := command.NewClient()
+	pusher := command.NewClient()
+
+	assert.Nil(t, r.HandleCommand(waiter, cmd("BLPOP", "a", "b", "0")))
+	assert.True(t, waiter.IsBlocked())
+	assert.Empty(t, r.UnblockedClients())
+
+	assert.Equal(t, []byte(":2\r\n"), r.HandleCommand(pusher, cmd("RPUSH", "b", "x", "y")))
+	assert.False(t, waiter.IsBlocked())
+	assert.Equal(t, []command.UnblockedClient{
+		{Client: waiter, Reply: protocol.EncodeResp([]string{"b", "x"}, false)},
+	}, r.UnblockedClients())
+	assert.Empty(t, r.UnblockedClients())
+
+	assert.Equal(t, []byte(":1\r\n"), r.LLen(cmd("LLEN", "b")))
+
+	// Served clients are no longer waiting on their other keys
+	r.HandleCommand(pusher, cmd("RPUSH", "a", "z"))
+	assert.Empty(t, r.UnblockedClients())
+}
+
+func TestBLPop_ServesClientsInBlockingOrder(t *testing.T) {
+	r := newTestRedis()
+	first := command.NewClient()
+	second := command.NewClient()
+	pusher := command.NewClient()
+
+	r.HandleCommand(first, cmd("BLPOP", "q", "0"))
+	r.HandleCommand(second, cmd("BRPOP", "q", "0"))
+
+	r.HandleCommand(pusher, cmd("RPUSH", "q", "job1"))
+	assert.Equal(t, []command.UnblockedClient{
+		{Client: first, Reply: protocol.EncodeResp([]string{"q", "job1"}, false)},
+	}, r.UnblockedClients())
+	assert.True(t, second.IsBlocked())
+
+	r.HandleCommand(pusher, cmd("RPUSH", "q", "job2", "job3"))
+	assert.Equal(t, []command.UnblockedClient{
+		{Client: second, Reply: protocol.EncodeResp([]string{"q", "job3"}, false)},
+	}, r.UnblockedClients())
+	assert.Equal(t, []byte(":1\r\n"), r.LLen(cmd("LLEN", "q")))
+}
+
+func TestBLPop_Timeout(t *testing.T) {
+	r := newTestRedis()
+	short := command.NewClient()
+	forever := command.NewClient()
+
+	r.HandleCommand(short, cmd("BLPOP", "q", "0.01"))
+	r.HandleCommand(forever, cmd("BLPOP", "q", "0"))
+
+	r.TimeoutBlockedClients()
+	assert.Empty(t, r.UnblockedClients())
+
+	time.Sleep(20 * time.Millisecond)
+	r.TimeoutBlockedClients()
+	assert.Equal(t, []command.UnblockedClient{
+		{Client: short, Reply: protocol.RespNilArray},
+	}, r.UnblockedClients())
+	assert.False(t, short.IsBlocked())
+	assert.True(t, forever.IsBlocked())
+}
+
+func TestBLPop_Errors(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+
+	r.Set(cmd("SET", "str", "v"))
+
+	assert.Equal(t, protocol.RespTimeoutNotFloat, r.HandleCommand(c, cmd("BLPOP", "q", "abc")))
+	assert.Equal(t, protocol.RespTimeoutNotFloat, r.HandleCommand(c, cmd("BLPOP", "q", "inf")))
+	assert.Equal(t, protocol.RespTimeoutNegative, r.HandleCommand(c, cmd("BLPOP", "q", "-1")))
+	assert.Equal(t, protocol.RespWrongTypeOperation, r.HandleCommand(c, cmd("BLPOP", "str", "q", "0")))
+	assert.False(t, c.IsBlocked())
+}
+
+func TestBLPop_SkipsWrongTypeOnceBlocked(t *testing.T) {
+	r := newTestRedis()
+	waiter := command.NewClient()
+	other := command.NewClient()
+
+	r.HandleCommand(waiter, cmd("BLPOP", "a", "b", "0"))
+	r.HandleCommand(other, cmd("SET", "a", "v"))
+	assert.True(t, waiter.IsBlocked())
+	assert.Empty(t, r.UnblockedClients())
+
+	r.HandleCommand(other, cmd("LPUSH", "b", "x"))
+	assert.Equal(t, []command.UnblockedClient{
+		{Client: waiter, Reply: protocol.EncodeResp([]string{"b", "x"}, false)},
+	}, r.UnblockedClients())
+}
+
+func TestBLPop_DoesNotBlockInsideTransaction(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+
+	r.HandleCommand(c, cmd("MULTI"))
+	r.HandleCommand(c, cmd("BLPOP", "q", "0"))
+	r.HandleCommand(c, cmd("BLMOVE", "q", "dst", "LEFT", "RIGHT", "0"))
+	resp := r.HandleCommand(c, cmd("EXEC"))
+
+	assert.Equal(t, "*2\r\n"+string(protocol.RespNilArray)+string(protocol.RespNilBulkString), string(resp))
+	assert.False(t, c.IsBlocked())
+}
+
+func TestBLPop_FreeClient(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+
+	r.HandleCommand(c, cmd("BLPOP", "q", "0"))
+	r.FreeClient(c)
+	assert.False(t, c.IsBlocked())
+
+	r.HandleCommand(command.NewClient(), cmd("LPUSH", "q", "x"))
+	assert.Empty(t, r.UnblockedClients())
+	assert.Equal(t, []byte(":1\r\n"), r.LLen(cmd("LLEN", "q")))
+}
+
+func TestBLMove(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+
+	r.RPush(cmd("RPUSH", "src", "a", "b"))
+	resp := r.HandleCommand(c, cmd("BLMOVE", "src", "dst", "right", "LEFT", "0"))
+	assert.Equal(t, []byte("$1\r\nb\r\n"), resp)
+	assert.Equal(t, protocol.EncodeResp([]string{"b"}, false), r.LRange(cmd("LRANGE", "dst", "0", "-1")))
+
+	assert.Equal(t, protocol.RespSyntaxError, r.HandleCommand(c, cmd("BLMOVE", "src", "dst", "UP", "LEFT", "0")))
+}
+
+func TestBLMove_WakesClientsBlockedOnDestination(t *testing.T) {
+	r := newTestRedis()
+	mover := command.NewClient()
+	popper := command.NewClient()
+
+	r.HandleCommand(mover, cmd("BLMOVE", "src", "dst", "LEFT", "RIGHT", "0"))
+	r.HandleCommand(popper, cmd("BLPOP", "dst", "0"))
+
+	r.HandleCommand(command.NewClient(), cmd("LPUSH", "src", "x"))
+	assert.Equal(t, []command.UnblockedClient{
+		{Client: mover, Reply: []byte("$1\r\nx\r\n")},
+		{Client: popper, Reply: protocol.EncodeResp([]string{"dst", "x"}, false)},
+	}, r.UnblockedClients())
+	assert.Equal(t, []byte(":0\r\n"), r.LLen(cmd("LLEN", "dst")))
+}
+
+func TestBZPopMin_BlocksUntilZAdd(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+
+	r.ZAdd(cmd("ZADD", "z", "2", "b", "1", "a"))
+	resp := r.HandleCommand(c, cmd("BZPOPMAX", "z", "0"))
+	assert.Equal(t, protocol.EncodeResp([]string{"z", "b", "2"}, false), resp)
+
+	r.HandleCommand(c, cmd("ZPOPMIN", "z"))
+	assert.Nil(t, r.HandleCommand(c, cmd("BZPOPMIN", "z", "0")))
+
+	r.HandleCommand(command.NewClient(), cmd("ZADD", "z", "5", "e", "3", "c"))
+	assert.Equal(t, []command.UnblockedClient{
+		{Client: c, Reply: protocol.EncodeResp([]string{"z", "c", "3"}, false)},
+	}, r.UnblockedClients())
+}
+
+func TestAOFLogsServedBlockingCommands(t *testing.T) {
+	dir := t.TempDir()
+	r, aof := newTestRedisWithAOF(t, dir)
+	popper := command.NewClient()
+	mover := command.NewClient()
+
+	r.HandleCommand(popper, cmd("BRPOP", "a", "q", "0"))
+	r.HandleCommand(mover, cmd("BLMOVE", "src", "dst", "LEFT", "RIGHT", "1"))
+	r.HandleCommand(command.NewClient(), cmd("RPUSH", "q", "x"))
+	r.HandleCommand(command.NewClient(), cmd("RPUSH", "src", "y"))
+	require.Len(t, r.UnblockedClients(), 2)
+
+	assert.Equal(t, []protocol.RedisCmd{
+		cmd("RPUSH", "q", "x"),
+		cmd("RPOP", "q"),
+		cmd("RPUSH", "src", "y"),
+		{Cmd: "MULTI", Args: []string{}},
+		cmd("LPOP", "src"),
+		cmd("RPUSH", "dst", "y"),
+		{Cmd: "EXEC", Args: []string{}},
+	}, loggedCommands(t, aof, dir))
+}
@@ -0,0 +1,261 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manhhung2111/go-redis/internal/command"
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/persistence"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+	"github.com/manhhung2111/go-redis/internal/storage"
+)
+
+func TestSelect(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+	other := command.NewClient()
+
+	r.HandleCommand(c, cmd("SET", "key", "zero"))
+	assert.Equal(t, protocol.RespOK, r.HandleCommand(c, cmd("SELECT", "1")))
+	assert.Equal(t, protocol.RespNilBulkString, r.HandleCommand(c, cmd("GET", "key")))
+	r.HandleCommand(c, cmd("SET", "key", "one"))
+
+	// Every client has its own selected database
+	assert.Equal(t, []byte("$4\r\nzero\r\n"), r.HandleCommand(other, cmd("GET", "key")))
+	assert.Equal(t, []byte("$3\r\none\r\n"), r.HandleCommand(c, cmd("GET", "key")))
+	assert.Equal(t, []byte(":1\r\n"), r.HandleCommand(c, cmd("DBSIZE")))
+}
+
+func TestSelect_Errors(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+
+	assert.Equal(t, protocol.RespValueNotIntegerOrOutOfRange, r.HandleCommand(c, cmd("SELECT", "one")))
+	assert.Equal(t, protocol.RespDBIndexOutOfRange, r.HandleCommand(c, cmd("SELECT", "16")))
+	assert.Equal(t, protocol.RespDBIndexOutOfRange, r.HandleCommand(c, cmd("SELECT", "-1")))
+}
+
+func TestSelect_InsideTransaction(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+
+	r.HandleCommand(c, cmd("MULTI"))
+	assert.Equal(t, protocol.RespQueued, r.HandleCommand(c, cmd("SELECT", "2")))
+	r.HandleCommand(c, cmd("SET", "key", "two"))
+	assert.Equal(t, []byte("*2\r\n+OK\r\n+OK\r\n"), r.HandleCommand(c, cmd("EXEC")))
+
+	assert.Equal(t, []byte("$3\r\ntwo\r\n"), r.HandleCommand(c, cmd("GET", "key")))
+	assert.Equal(t, protocol.RespNilBulkString, r.HandleCommand(command.NewClient(), cmd("GET", "key")))
+}
+
+func TestMove(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+	r.HandleCommand(c, cmd("SET", "key", "v"))
+	r.HandleCommand(c, cmd("SET", "taken", "zero"))
+
+	assert.Equal(t, []byte(":1\r\n"), r.HandleCommand(c, cmd("MOVE", "key", "3")))
+	assert.Equal(t, []byte(":0\r\n"), r.HandleCommand(c, cmd("EXISTS", "key")))
+	assert.Equal(t, []byte(":0\r\n"), r.HandleCommand(c, cmd("MOVE", "missing", "3")))
+
+	r.HandleCommand(c, cmd("SELECT", "3"))
+	assert.Equal(t, []byte("$1\r\nv\r\n"), r.HandleCommand(c, cmd("GET", "key")))
+	r.HandleCommand(c, cmd("SET", "taken", "three"))
+	assert.Equal(t, []byte(":0\r\n"), r.HandleCommand(c, cmd("MOVE", "taken", "0")))
+
+	assert.Equal(t, protocol.RespSameObject, r.HandleCommand(c, cmd("MOVE", "key", "3")))
+	assert.Equal(t, protocol.RespDBIndexOutOfRange, r.HandleCommand(c, cmd("MOVE", "key", "16")))
+	assert.Equal(t, protocol.RespValueNotIntegerOrOutOfRange, r.HandleCommand(c, cmd("MOVE", "key", "x")))
+}
+
+func TestCopy_ToAnotherDatabase(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+	r.HandleCommand(c, cmd("RPUSH", "list", "a", "b"))
+
+	assert.Equal(t, []byte(":1\r\n"), r.HandleCommand(c, cmd("COPY", "list", "list", "DB", "1")))
+	assert.Equal(t, []byte(":0\r\n"), r.HandleCommand(c, cmd("COPY", "list", "list", "db", "1")))
+	assert.Equal(t, []byte(":1\r\n"), r.HandleCommand(c, cmd("COPY", "list", "list", "DB", "1", "REPLACE")))
+	assert.Equal(t, protocol.RespSameObject, r.HandleCommand(c, cmd("COPY", "list", "list", "DB", "0")))
+	assert.Equal(t, protocol.RespDBIndexOutOfRange, r.HandleCommand(c, cmd("COPY", "list", "x", "DB", "99")))
+	assert.Equal(t, protocol.RespSyntaxError, r.HandleCommand(c, cmd("COPY", "list", "x", "DB")))
+
+	r.HandleCommand(c, cmd("SELECT", "1"))
+	assert.Equal(t, protocol.EncodeResp([]string{"a", "b"}, false), r.HandleCommand(c, cmd("LRANGE", "list", "0", "-1")))
+}
+
+func TestSwapDB(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+	other := command.NewClient()
+	r.HandleCommand(c, cmd("SET", "key", "zero"))
+	r.HandleCommand(other, cmd("SELECT", "1"))
+	r.HandleCommand(other, cmd("SET", "key", "one"))
+
+	assert.Equal(t, protocol.RespOK, r.HandleCommand(c, cmd("SWAPDB", "0", "1")))
+	assert.Equal(t, []byte("$3\r\none\r\n"), r.HandleCommand(c, cmd("GET", "key")))
+	assert.Equal(t, []byte("$4\r\nzero\r\n"), r.HandleCommand(other, cmd("GET", "key")))
+	assert.Equal(t, protocol.RespOK, r.HandleCommand(c, cmd("SWAPDB", "2", "2")))
+
+	assert.Equal(t, protocol.RespInvalidFirstDBIndex, r.HandleCommand(c, cmd("SWAPDB", "a", "1")))
+	assert.Equal(t, protocol.RespInvalidSecondDBIndex, r.HandleCommand(c, cmd("SWAPDB", "0", "b")))
+	assert.Equal(t, protocol.RespDBIndexOutOfRange, r.HandleCommand(c, cmd("SWAPDB", "0", "16")))
+}
+
+func TestSwapDB_AbortsWatchingTransactions(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+	r.HandleCommand(command.NewClient(), cmd("SET", "key", "v"))
+
+	r.HandleCommand(c, cmd("WATCH", "key"))
+	r.HandleCommand(command.NewClient(), cmd("SWAPDB", "0", "5"))
+	r.HandleCommand(c, cmd("MULTI"))
+	r.HandleCommand(c, cmd("GET", "key"))
+	assert.Equal(t, protocol.RespNilArray, r.HandleCommand(c, cmd("EXEC")))
+}
+
+func TestSwapDB_ServesBlockedClients(t *testing.T) {
+	r := newTestRedis()
+	popper := command.NewClient()
+	writer := command.NewClient()
+
+	assert.Nil(t, r.HandleCommand(popper, cmd("BLPOP", "q", "0")))
+	r.HandleCommand(writer, cmd("SELECT", "1"))
+	r.HandleCommand(writer, cmd("RPUSH", "q", "x"))
+	assert.Empty(t, r.UnblockedClients())
+
+	r.HandleCommand(writer, cmd("SWAPDB", "0", "1"))
+	assert.Equal(t, []command.UnblockedClient{
+		{Client: popper, Reply: protocol.EncodeResp([]string{"q", "x"}, false)},
+	}, r.UnblockedClients())
+}
+
+func TestWatch_KeysOfOtherDatabases(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+	writer := command.NewClient()
+	r.HandleCommand(writer, cmd("SELECT", "1"))
+
+	r.HandleCommand(c, cmd("WATCH", "key"))
+	r.HandleCommand(writer, cmd("SET", "key", "v"))
+	r.HandleCommand(c, cmd("SELECT", "1"))
+	r.HandleCommand(c, cmd("MULTI"))
+	r.HandleCommand(c, cmd("GET", "key"))
+	assert.Equal(t, []byte("*1\r\n$1\r\nv\r\n"), r.HandleCommand(c, cmd("EXEC")))
+}
+
+func TestBlocking_KeysOfOtherDatabases(t *testing.T) {
+	r := newTestRedis()
+	popper := command.NewClient()
+	r.HandleCommand(popper, cmd("SELECT", "2"))
+
+	assert.Nil(t, r.HandleCommand(popper, cmd("BLPOP", "q", "0")))
+	r.HandleCommand(command.NewClient(), cmd("RPUSH", "q", "zero"))
+	assert.Empty(t, r.UnblockedClients())
+
+	writer := command.NewClient()
+	r.HandleCommand(writer, cmd("SELECT", "2"))
+	r.HandleCommand(writer, cmd("RPUSH", "q", "two"))
+	assert.Equal(t, []command.UnblockedClient{
+		{Client: popper, Reply: protocol.EncodeResp([]string{"q", "two"}, false)},
+	}, r.UnblockedClients())
+	assert.Equal(t, []byte(":1\r\n"), r.HandleCommand(command.NewClient(), cmd("LLEN", "q")))
+}
+
+func TestFlushDBAndFlushAll(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+	r.HandleCommand(c, cmd("SET", "a", "v"))
+	r.HandleCommand(c, cmd("SELECT", "1"))
+	r.HandleCommand(c, cmd("SET", "b", "v"))
+
+	assert.Equal(t, protocol.RespOK, r.HandleCommand(c, cmd("FLUSHDB", "ASYNC")))
+	assert.Equal(t, []byte(":0\r\n"), r.HandleCommand(c, cmd("DBSIZE")))
+	assert.Equal(t, []byte(":1\r\n"), r.HandleCommand(command.NewClient(), cmd("DBSIZE")))
+
+	r.HandleCommand(c, cmd("SET", "b", "v"))
+	assert.Equal(t, protocol.RespOK, r.HandleCommand(c, cmd("FLUSHALL", "sync")))
+	assert.Equal(t, []byte(":0\r\n"), r.HandleCommand(c, cmd("DBSIZE")))
+	assert.Equal(t, []byte(":0\r\n"), r.HandleCommand(command.NewClient(), cmd("DBSIZE")))
+
+	assert.Equal(t, protocol.RespSyntaxError, r.HandleCommand(c, cmd("FLUSHDB", "LAZY")))
+	assert.Equal(t, protocol.RespSyntaxError, r.HandleCommand(c, cmd("FLUSHALL", "ASYNC", "SYNC")))
+}
+
+func TestAOFLogsSelectedDatabase(t *testing.T) {
+	dir := t.TempDir()
+	r, aof := newTestRedisWithAOF(t, dir)
+	c := command.NewClient()
+	popper := command.NewClient()
+
+	r.HandleCommand(c, cmd("SET", "a", "zero"))
+	r.HandleCommand(c, cmd("SELECT", "3"))
+	r.HandleCommand(c, cmd("GET", "a"))
+	r.HandleCommand(c, cmd("SET", "a", "three"))
+	r.HandleCommand(c, cmd("SET", "b", "three"))
+
+	// Served blocked clients are logged in their own database
+	r.HandleCommand(popper, cmd("SELECT", "5"))
+	r.HandleCommand(popper, cmd("BLPOP", "q", "0"))
+	r.HandleCommand(c, cmd("MULTI"))
+	r.HandleCommand(c, cmd("SELECT", "5"))
+	r.HandleCommand(c, cmd("RPUSH", "q", "x"))
+	r.HandleCommand(c, cmd("SELECT", "0"))
+	r.HandleCommand(c, cmd("SET", "c", "zero"))
+	r.HandleCommand(c, cmd("EXEC"))
+	require.Len(t, r.UnblockedClients(), 1)
+
+	assert.Equal(t, []protocol.RedisCmd{
+		cmd("SET", "a", "zero"),
+		cmd("SELECT", "3"),
+		cmd("SET", "a", "three"),
+		cmd("SET", "b", "three"),
+		cmd("SELECT", "5"),
+		{Cmd: "MULTI", Args: []string{}},
+		cmd("RPUSH", "q", "x"),
+		cmd("SELECT", "0"),
+		cmd("SET", "c", "zero"),
+		{Cmd: "EXEC", Args: []string{}},
+		cmd("SELECT", "5"),
+		cmd("LPOP", "q"),
+	}, loggedCommands(t, aof, dir))
+	require.NoError(t, aof.Shutdown())
+
+	cfg := config.NewConfig()
+	cfg.Dir = dir
+	cfg.AppendOnly = true
+	databases := storage.NewDatabases(cfg)
+	replayed := command.NewRedis(databases, persistence.NewRDB(cfg, databases), persistence.NewAOF(cfg, databases))
+	_, err := persistence.NewAOF(cfg, databases).Load(replayed.ReplayCommand)
+	require.NoError(t, err)
+
+	replayer := command.NewClient()
+	assert.Equal(t, []byte("$4\r\nzero\r\n"), replayed.HandleCommand(replayer, cmd("GET", "c")))
+	replayed.HandleCommand(replayer, cmd("SELECT", "5"))
+	assert.Equal(t, []byte(":0\r\n"), replayed.HandleCommand(replayer, cmd("LLEN", "q")))
+	replayed.HandleCommand(replayer, cmd("SELECT", "3"))
+	assert.Equal(t, []byte("$5\r\nthree\r\n"), replayed.HandleCommand(replayer, cmd("GET", "a")))
+	assert.Equal(t, []byte(":2\r\n"), replayed.HandleCommand(replayer, cmd("DBSIZE")))
+}
+
+func TestSaveKeepsEveryDatabase(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.NewConfig()
+	cfg.Dir = dir
+	databases := storage.NewDatabases(cfg)
+	r := command.NewRedis(databases, persistence.NewRDB(cfg, databases), persistence.NewAOF(cfg, databases))
+	c := command.NewClient()
+	r.HandleCommand(c, cmd("SELECT", "7"))
+	r.HandleCommand(c, cmd("SET", "key", "seven"))
+	require.Equal(t, protocol.RespOK, r.HandleCommand(c, cmd("SAVE")))
+
+	loaded := storage.NewDatabases(cfg)
+	require.NoError(t, persistence.NewRDB(cfg, loaded).Load())
+	value, err := loaded.DB(7).Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, "seven", *value)
+	assert.Equal(t, int64(0), loaded.DB(0).DBSize())
+}
@@ -1,7 +1,9 @@
 package test
 
 import (
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -61,29 +63,32 @@ func TestExpire_InvalidTTL_NonNumeric(t *testing.T) {
 	r.Set(cmd("SET", "k", "v"))
 
 	resp := r.Expire(cmd("EXPIRE", "k", "abc"))
-	expected := protocol.EncodeResp(errors.InvalidExpireTime("EXPIRE"), false)
-
-	assert.Equal(t, expected, resp)
+	assert.Equal(t, protocol.RespValueNotIntegerOrOutOfRange, resp)
 }
 
-func TestExpire_InvalidTTL_Zero(t *testing.T) {
+func TestExpire_ZeroTTLDeletesKey(t *testing.T) {
 	r := newTestRedis()
 	r.Set(cmd("SET", "k", "v"))
 
 	resp := r.Expire(cmd("EXPIRE", "k", "0"))
-	expected := protocol.EncodeResp(errors.InvalidExpireTime("EXPIRE"), false)
+	assert.Equal(t, protocol.RespExpireTimeoutSet, resp)
 
-	assert.Equal(t, expected, resp)
+	resp = r.Get(cmd("GET", "k"))
+	assert.Equal(t, protocol.RespNilBulkString, resp)
 }
 
-func TestExpire_InvalidTTL_Negative(t *testing.T) {
+func TestExpire_NegativeTTLDeletesKey(t *testing.T) {
 	r := newTestRedis()
 	r.Set(cmd("SET", "k", "v"))
 
 	resp := r.Expire(cmd("EXPIRE", "k", "-10"))
-	expected := protocol.EncodeResp(errors.InvalidExpireTime("EXPIRE"), false)
+	assert.Equal(t, protocol.RespExpireTimeoutSet, resp)
 
-	assert.Equal(t, expected, resp)
+	resp = r.TTL(cmd("TTL", "k"))
+	assert.Equal(t, protocol.RespTTLKeyNotExist, resp)
+
+	resp = r.Expire(cmd("EXPIRE", "k", "-10"))
+	assert.Equal(t, protocol.RespExpireTimeoutNotSet, resp)
 }
 
 func TestExpire_InvalidOption(t *testing.T) {
@@ -195,4 +200,189 @@ func TestExpire_LT_AcceptSmallerTTL(t *testing.T) {
 
 	resp := r.Expire(cmd("EXPIRE", "k", "5", "LT"))
 	assert.Equal(t, protocol.RespExpireTimeoutSet, resp)
-}
\ No newline at end of file
+}
+// PEXPIREAT tests
+
+func TestPExpireAt_SetsAbsoluteExpiration(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v"))
+
+	expireAt := time.Now().Add(10 * time.Second).UnixMilli()
+	resp := r.PExpireAt(cmd("PEXPIREAT", "k", strconv.FormatInt(expireAt, 10)))
+	assert.Equal(t, protocol.RespExpireTimeoutSet, resp)
+
+	resp = r.TTL(cmd("TTL", "k"))
+	assert.Contains(t, [][]byte{[]byte(":9\r\n"), []byte(":10\r\n")}, resp)
+}
+
+func TestPExpireAt_PastTimeDeletesKey(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v"))
+
+	resp := r.PExpireAt(cmd("PEXPIREAT", "k", "1000"))
+	assert.Equal(t, protocol.RespExpireTimeoutSet, resp)
+
+	resp = r.Get(cmd("GET", "k"))
+	assert.Equal(t, protocol.RespNilBulkString, resp)
+}
+
+func TestPExpireAt_KeyNotExist(t *testing.T) {
+	r := newTestRedis()
+
+	resp := r.PExpireAt(cmd("PEXPIREAT", "k", "1000"))
+	assert.Equal(t, protocol.RespExpireTimeoutNotSet, resp)
+}
+
+func TestPExpireAt_InvalidTimestamp(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v"))
+
+	resp := r.PExpireAt(cmd("PEXPIREAT", "k", "soon"))
+	assert.Equal(t, protocol.RespValueNotIntegerOrOutOfRange, resp)
+}
+
+func TestPExpireAt_Options(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v"))
+	later := strconv.FormatInt(time.Now().Add(time.Hour).UnixMilli(), 10)
+	sooner := strconv.FormatInt(time.Now().Add(time.Minute).UnixMilli(), 10)
+
+	assert.Equal(t, protocol.RespExpireTimeoutNotSet, r.PExpireAt(cmd("PEXPIREAT", "k", later, "XX")))
+	assert.Equal(t, protocol.RespExpireTimeoutSet, r.PExpireAt(cmd("PEXPIREAT", "k", later, "NX")))
+	assert.Equal(t, protocol.RespExpireTimeoutNotSet, r.PExpireAt(cmd("PEXPIREAT", "k", sooner, "GT")))
+	assert.Equal(t, protocol.RespExpireTimeoutSet, r.PExpireAt(cmd("PEXPIREAT", "k", sooner, "LT")))
+	assert.Equal(t, protocol.RespExpireOptionsNotCompatible, r.PExpireAt(cmd("PEXPIREAT", "k", sooner, "NX", "GT")))
+}
+
+// PEXPIRE, EXPIREAT, PTTL, EXPIRETIME, PEXPIRETIME and PERSIST tests
+
+func TestPExpire_SetsExpirationInMilliseconds(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v"))
+
+	assert.Equal(t, protocol.RespExpireTimeoutSet, r.PExpire(cmd("PEXPIRE", "k", "1500")))
+	assert.Equal(t, []byte(":2\r\n"), r.TTL(cmd("TTL", "k")))
+
+	pttl, _, _ := protocol.DecodeResp(r.PTTL(cmd("PTTL", "k")))
+	assert.InDelta(t, 1500, pttl, 100)
+}
+
+func TestPExpire_InvalidTTL(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v"))
+
+	expected := protocol.EncodeResp(errors.InvalidExpireTime("PEXPIRE"), false)
+	assert.Equal(t, protocol.RespValueNotIntegerOrOutOfRange, r.PExpire(cmd("PEXPIRE", "k", "ms")))
+	assert.Equal(t, expected, r.PExpire(cmd("PEXPIRE", "k", "9223372036854775807")))
+	assert.Equal(t, expected, r.PExpire(cmd("PEXPIRE", "k", "-9223372036854775808")))
+}
+
+func TestExpire_GTAndLTWithoutTTL(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v"))
+	past := strconv.FormatInt(time.Now().Add(-time.Hour).UnixMilli(), 10)
+	future := strconv.FormatInt(time.Now().Add(time.Hour).UnixMilli(), 10)
+
+	// A key without a time to live never expires, so GT never applies to it
+	assert.Equal(t, protocol.RespExpireTimeoutNotSet, r.Expire(cmd("EXPIRE", "k", "-1", "GT")))
+	assert.Equal(t, protocol.RespExpireTimeoutNotSet, r.PExpireAt(cmd("PEXPIREAT", "k", past, "GT")))
+	assert.Equal(t, protocol.RespExpireTimeoutNotSet, r.PExpireAt(cmd("PEXPIREAT", "k", future, "GT")))
+	assert.Equal(t, protocol.RespTTLKeyExistNoExpire, r.TTL(cmd("TTL", "k")))
+
+	// and LT always does
+	assert.Equal(t, protocol.RespExpireTimeoutSet, r.PExpireAt(cmd("PEXPIREAT", "k", future, "LT")))
+	assert.Contains(t, [][]byte{[]byte(":3599\r\n"), []byte(":3600\r\n")}, r.TTL(cmd("TTL", "k")))
+
+	r.Set(cmd("SET", "k2", "v"))
+	assert.Equal(t, protocol.RespExpireTimeoutSet, r.PExpireAt(cmd("PEXPIREAT", "k2", past, "LT")))
+	assert.Equal(t, protocol.RespTTLKeyNotExist, r.TTL(cmd("TTL", "k2")))
+}
+
+func TestPExpire_NonPositiveTTLDeletesKey(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v"))
+	r.Set(cmd("SET", "k2", "v"))
+
+	assert.Equal(t, protocol.RespExpireTimeoutSet, r.PExpire(cmd("PEXPIRE", "k", "0")))
+	assert.Equal(t, protocol.RespNilBulkString, r.Get(cmd("GET", "k")))
+
+	assert.Equal(t, protocol.RespExpireTimeoutNotSet, r.PExpire(cmd("PEXPIRE", "k2", "-1", "XX")))
+	assert.Equal(t, protocol.RespExpireTimeoutSet, r.PExpire(cmd("PEXPIRE", "k2", "-1")))
+	assert.Equal(t, protocol.RespTTLKeyNotExist, r.PTTL(cmd("PTTL", "k2")))
+}
+
+func TestPExpire_Options(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v"))
+
+	assert.Equal(t, protocol.RespExpireTimeoutNotSet, r.PExpire(cmd("PEXPIRE", "k", "5000", "XX")))
+	assert.Equal(t, protocol.RespExpireTimeoutSet, r.PExpire(cmd("PEXPIRE", "k", "5000", "NX")))
+	assert.Equal(t, protocol.RespExpireTimeoutNotSet, r.PExpire(cmd("PEXPIRE", "k", "1000", "GT")))
+	assert.Equal(t, protocol.RespExpireTimeoutSet, r.PExpire(cmd("PEXPIRE", "k", "1000", "LT")))
+}
+
+func TestExpire_Overflow(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v"))
+
+	resp := r.Expire(cmd("EXPIRE", "k", "9223372036854775"))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidExpireTime("EXPIRE"), false), resp)
+}
+
+func TestExpireAt_SetsAbsoluteExpiration(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v"))
+
+	expireAt := time.Now().Add(time.Hour).Unix()
+	resp := r.ExpireAt(cmd("EXPIREAT", "k", strconv.FormatInt(expireAt, 10)))
+	assert.Equal(t, protocol.RespExpireTimeoutSet, resp)
+
+	assert.Equal(t, protocol.EncodeResp(expireAt, false), r.ExpireTime(cmd("EXPIRETIME", "k")))
+	assert.Equal(t, protocol.EncodeResp(expireAt*1000, false), r.PExpireTime(cmd("PEXPIRETIME", "k")))
+}
+
+func TestExpireAt_PastTimeDeletesKey(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v"))
+
+	assert.Equal(t, protocol.RespExpireTimeoutSet, r.ExpireAt(cmd("EXPIREAT", "k", "-1")))
+	assert.Equal(t, protocol.RespTTLKeyNotExist, r.TTL(cmd("TTL", "k")))
+}
+
+func TestExpireAt_InvalidTimestamp(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v"))
+
+	assert.Equal(t, protocol.RespValueNotIntegerOrOutOfRange, r.ExpireAt(cmd("EXPIREAT", "k", "soon")))
+
+	resp := r.ExpireAt(cmd("EXPIREAT", "k", "9223372036854776"))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidExpireTime("EXPIREAT"), false), resp)
+}
+
+func TestTTLFamily_MissingKeyAndNoExpire(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v"))
+
+	for _, handler := range []func(protocol.RedisCmd) []byte{r.TTL, r.PTTL, r.ExpireTime, r.PExpireTime} {
+		assert.Equal(t, protocol.RespTTLKeyNotExist, handler(cmd("TTL", "missing")))
+		assert.Equal(t, protocol.RespTTLKeyExistNoExpire, handler(cmd("TTL", "k")))
+	}
+}
+
+func TestPersist_RemovesExpiration(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v"))
+	r.Expire(cmd("EXPIRE", "k", "100"))
+
+	assert.Equal(t, []byte(":1\r\n"), r.Persist(cmd("PERSIST", "k")))
+	assert.Equal(t, protocol.RespTTLKeyExistNoExpire, r.TTL(cmd("TTL", "k")))
+	assert.Equal(t, []byte(":0\r\n"), r.Persist(cmd("PERSIST", "k")))
+	assert.Equal(t, []byte(":0\r\n"), r.Persist(cmd("PERSIST", "missing")))
+}
+
+func TestPersist_InvalidNumberOfArgs(t *testing.T) {
+	r := newTestRedis()
+
+	resp := r.Persist(cmd("PERSIST"))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidNumberOfArgs("PERSIST"), false), resp)
+}
@@ -1,7 +1,9 @@
 package test
 
 import (
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -557,4 +559,256 @@ func TestHExists_FieldNotExist(t *testing.T) {
 	val, _, err := protocol.DecodeResp(resp)
 	require.NoError(t, err)
 	assert.Equal(t, int64(0), val)
-}
\ No newline at end of file
+}
+
+// HINCRBYFLOAT Tests
+func TestHIncrByFloat_InvalidArity(t *testing.T) {
+	r := newTestRedis()
+	resp := r.HIncrByFloat(cmd("HINCRBYFLOAT", "key", "field"))
+	expected := protocol.EncodeResp(errors.InvalidNumberOfArgs("HINCRBYFLOAT"), false)
+	assert.Equal(t, expected, resp)
+}
+
+func TestHIncrByFloat_InvalidIncrement(t *testing.T) {
+	r := newTestRedis()
+	resp := r.HIncrByFloat(cmd("HINCRBYFLOAT", "key", "field", "abc"))
+	assert.Equal(t, protocol.RespValueNotValidFloat, resp)
+}
+
+func TestHIncrByFloat_Success(t *testing.T) {
+	r := newTestRedis()
+	assert.Equal(t, []byte("$3\r\n0.5\r\n"), r.HIncrByFloat(cmd("HINCRBYFLOAT", "flags", "weight", "0.5")))
+	assert.Equal(t, []byte("$4\r\n0.75\r\n"), r.HIncrByFloat(cmd("HINCRBYFLOAT", "flags", "weight", "0.25")))
+	assert.Equal(t, []byte("$4\r\n5.75\r\n"), r.HIncrByFloat(cmd("HINCRBYFLOAT", "flags", "weight", "5e0")))
+}
+
+func TestHIncrByFloat_NotAFloat(t *testing.T) {
+	r := newTestRedis()
+	r.HSet(cmd("HSET", "key", "field", "value"))
+	resp := r.HIncrByFloat(cmd("HINCRBYFLOAT", "key", "field", "1"))
+	assert.Equal(t, []byte("-ERR hash value is not a float\r\n"), resp)
+}
+
+func TestHIncrByFloat_LongDoubleRange(t *testing.T) {
+	r := newTestRedis()
+
+	// The increment, the stored value and the result must all fit a long double
+	assert.Equal(t, protocol.RespValueNotValidFloat, r.HIncrByFloat(cmd("HINCRBYFLOAT", "key", "field", "1e10000000")))
+	assert.Equal(t, protocol.RespNilBulkString, r.HGet(cmd("HGET", "key", "field")))
+
+	r.HSet(cmd("HSET", "key", "field", "1e5000"))
+	assert.Equal(t, []byte("-ERR hash value is not a float\r\n"), r.HIncrByFloat(cmd("HINCRBYFLOAT", "key", "field", "1")))
+
+	r.HSet(cmd("HSET", "key", "field", "1e4932"))
+	assert.Equal(t, []byte("-ERR increment would produce NaN or Infinity\r\n"), r.HIncrByFloat(cmd("HINCRBYFLOAT", "key", "field", "1e4932")))
+	assert.Equal(t, protocol.EncodeResp("1e4932", false), r.HGet(cmd("HGET", "key", "field")))
+}
+
+// HSTRLEN Tests
+func TestHStrLen_InvalidArity(t *testing.T) {
+	r := newTestRedis()
+	resp := r.HStrLen(cmd("HSTRLEN", "key"))
+	expected := protocol.EncodeResp(errors.InvalidNumberOfArgs("HSTRLEN"), false)
+	assert.Equal(t, expected, resp)
+}
+
+func TestHStrLen_Success(t *testing.T) {
+	r := newTestRedis()
+	r.HSet(cmd("HSET", "key", "field", "value"))
+	assert.Equal(t, []byte(":5\r\n"), r.HStrLen(cmd("HSTRLEN", "key", "field")))
+	assert.Equal(t, []byte(":0\r\n"), r.HStrLen(cmd("HSTRLEN", "key", "missing")))
+	assert.Equal(t, []byte(":0\r\n"), r.HStrLen(cmd("HSTRLEN", "missing", "field")))
+}
+
+// HRANDFIELD Tests
+func TestHRandField_InvalidArgs(t *testing.T) {
+	r := newTestRedis()
+	expected := protocol.EncodeResp(errors.InvalidNumberOfArgs("HRANDFIELD"), false)
+	assert.Equal(t, expected, r.HRandField(cmd("HRANDFIELD", "key", "1", "WITHVALUES", "x")))
+	assert.Equal(t, protocol.RespValueNotIntegerOrOutOfRange, r.HRandField(cmd("HRANDFIELD", "key", "x")))
+	assert.Equal(t, protocol.RespSyntaxError, r.HRandField(cmd("HRANDFIELD", "key", "1", "WITHSCORES")))
+
+	r.HSet(cmd("HSET", "key", "a", "1"))
+	assert.Equal(t, protocol.RespValueOutOfRange, r.HRandField(cmd("HRANDFIELD", "key", "-9223372036854775808")))
+	assert.Equal(t, protocol.RespValueOutOfRange, r.HRandField(cmd("HRANDFIELD", "key", "-4611686018427387904", "WITHVALUES")))
+}
+
+func TestHRandField_MissingKey(t *testing.T) {
+	r := newTestRedis()
+	assert.Equal(t, protocol.RespNilBulkString, r.HRandField(cmd("HRANDFIELD", "missing")))
+	assert.Equal(t, []byte("*0\r\n"), r.HRandField(cmd("HRANDFIELD", "missing", "3")))
+}
+
+func TestHRandField_Success(t *testing.T) {
+	r := newTestRedis()
+	r.HSet(cmd("HSET", "flags", "a", "1", "b", "2", "c", "3"))
+
+	val, _, err := protocol.DecodeResp(r.HRandField(cmd("HRANDFIELD", "flags")))
+	require.NoError(t, err)
+	assert.Contains(t, []string{"a", "b", "c"}, val)
+
+	val, _, err = protocol.DecodeResp(r.HRandField(cmd("HRANDFIELD", "flags", "2")))
+	require.NoError(t, err)
+	fields := val.([]interface{})
+	assert.Len(t, fields, 2)
+	assert.NotEqual(t, fields[0], fields[1])
+
+	val, _, err = protocol.DecodeResp(r.HRandField(cmd("HRANDFIELD", "flags", "-6", "WITHVALUES")))
+	require.NoError(t, err)
+	pairs := val.([]interface{})
+	require.Len(t, pairs, 12)
+	for i := 0; i < len(pairs); i += 2 {
+		assert.Equal(t, map[string]string{"a": "1", "b": "2", "c": "3"}[pairs[i].(string)], pairs[i+1])
+	}
+}
+
+// HGETDEL Tests
+func TestHGetDel_InvalidFields(t *testing.T) {
+	r := newTestRedis()
+	assert.Equal(t, protocol.RespHashFieldsMissing, r.HGetDel(cmd("HGETDEL", "key", "FIELD", "1", "a")))
+	assert.Equal(t, protocol.RespHashNumFieldsNotPositive, r.HGetDel(cmd("HGETDEL", "key", "FIELDS", "0", "a")))
+	assert.Equal(t, protocol.RespHashNumFieldsMismatch, r.HGetDel(cmd("HGETDEL", "key", "FIELDS", "2", "a")))
+}
+
+func TestHGetDel_Success(t *testing.T) {
+	r := newTestRedis()
+	r.HSet(cmd("HSET", "key", "a", "1", "b", "2"))
+
+	resp := r.HGetDel(cmd("HGETDEL", "key", "FIELDS", "2", "a", "missing"))
+	assert.Equal(t, []byte("*2\r\n$1\r\n1\r\n$-1\r\n"), resp)
+	assert.Equal(t, []byte(":1\r\n"), r.HLen(cmd("HLEN", "key")))
+
+	r.HGetDel(cmd("HGETDEL", "key", "fields", "1", "b"))
+	assert.Equal(t, []byte(":0\r\n"), r.Exists(cmd("EXISTS", "key")))
+}
+
+// HGETEX Tests
+func TestHGetEx_InvalidArgs(t *testing.T) {
+	r := newTestRedis()
+	assert.Equal(t, protocol.RespSyntaxError, r.HGetEx(cmd("HGETEX", "key", "EX", "10", "PERSIST", "FIELDS", "1", "a")))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidExpireTime("HGETEX"), false), r.HGetEx(cmd("HGETEX", "key", "EX", "0", "FIELDS", "1", "a")))
+	assert.Equal(t, protocol.RespHashFieldsMissing, r.HGetEx(cmd("HGETEX", "key", "EX", "10", "FIELDS")))
+	assert.Equal(t, protocol.RespHashNumFieldsMismatch, r.HGetEx(cmd("HGETEX", "key", "FIELDS", "1", "a", "b")))
+}
+
+func TestHGetEx_Success(t *testing.T) {
+	r := newTestRedis()
+	r.HSet(cmd("HSET", "key", "a", "1", "b", "2"))
+
+	resp := r.HGetEx(cmd("HGETEX", "key", "EX", "100", "FIELDS", "2", "a", "missing"))
+	assert.Equal(t, []byte("*2\r\n$1\r\n1\r\n$-1\r\n"), resp)
+
+	resp = r.HGetEx(cmd("HGETEX", "key", "PX", "1", "FIELDS", "1", "b"))
+	assert.Equal(t, []byte("*1\r\n$1\r\n2\r\n"), resp)
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, []byte(":1\r\n"), r.HLen(cmd("HLEN", "key")))
+	assert.Equal(t, protocol.RespNilBulkString, r.HGet(cmd("HGET", "key", "b")))
+
+	r.HGetEx(cmd("HGETEX", "key", "PERSIST", "FIELDS", "1", "a"))
+	r.HGetEx(cmd("HGETEX", "key", "PXAT", "1", "FIELDS", "1", "a"))
+	assert.Equal(t, []byte(":0\r\n"), r.Exists(cmd("EXISTS", "key")))
+}
+
+// HSETEX Tests
+func TestHSetEx_InvalidArgs(t *testing.T) {
+	r := newTestRedis()
+	assert.Equal(t, protocol.RespSyntaxError, r.HSetEx(cmd("HSETEX", "key", "FNX", "FXX", "FIELDS", "1", "a", "1")))
+	assert.Equal(t, protocol.RespSyntaxError, r.HSetEx(cmd("HSETEX", "key", "KEEPTTL", "EX", "10", "FIELDS", "1", "a", "1")))
+	assert.Equal(t, protocol.RespHashNumFieldsMismatch, r.HSetEx(cmd("HSETEX", "key", "FIELDS", "2", "a", "1", "b")))
+	assert.Equal(t, protocol.RespHashNumFieldsMismatch, r.HSetEx(cmd("HSETEX", "key", "FIELDS", "2", "a", "1")))
+	expected := protocol.EncodeResp(errors.InvalidCommandOption("NX", "HSETEX"), false)
+	assert.Equal(t, expected, r.HSetEx(cmd("HSETEX", "key", "NX", "FIELDS", "1", "a", "1")))
+}
+
+func TestHSetEx_Success(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, []byte(":0\r\n"), r.HSetEx(cmd("HSETEX", "key", "FXX", "FIELDS", "1", "a", "1")))
+	assert.Equal(t, []byte(":1\r\n"), r.HSetEx(cmd("HSETEX", "key", "FNX", "EX", "100", "FIELDS", "2", "a", "1", "b", "2")))
+	assert.Equal(t, []byte(":0\r\n"), r.HSetEx(cmd("HSETEX", "key", "FNX", "FIELDS", "1", "a", "x")))
+	assert.Equal(t, []byte(":1\r\n"), r.HSetEx(cmd("HSETEX", "key", "FXX", "KEEPTTL", "FIELDS", "1", "a", "x")))
+	assert.Equal(t, []byte("$1\r\nx\r\n"), r.HGet(cmd("HGET", "key", "a")))
+
+	r.HSetEx(cmd("HSETEX", "key", "PX", "1", "FIELDS", "1", "b", "y"))
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, []byte("*2\r\n$1\r\na\r\n$1\r\nx\r\n"), r.HGetAll(cmd("HGETALL", "key")))
+}
+
+// HEXPIRE Tests
+func TestHExpire_InvalidArgs(t *testing.T) {
+	r := newTestRedis()
+	assert.Equal(t, protocol.RespValueNotIntegerOrOutOfRange, r.HExpire(cmd("HEXPIRE", "key", "ten", "FIELDS", "1", "a")))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidExpireTime("HEXPIRE"), false), r.HExpire(cmd("HEXPIRE", "key", "-1", "FIELDS", "1", "a")))
+	assert.Equal(t, protocol.RespHashFieldsMissing, r.HExpire(cmd("HEXPIRE", "key", "10", "XX", "GT", "FIELDS")))
+	assert.Equal(t, protocol.RespHashNumFieldsMismatch, r.HExpire(cmd("HEXPIRE", "key", "10", "FIELDS", "2", "a")))
+	assert.Equal(t, []byte("-ERR Number of fields must be a positive integer\r\n"), r.HExpire(cmd("HEXPIRE", "key", "10", "FIELDS", "0", "a")))
+	assert.Equal(t, protocol.RespHashNumFieldsNotPositive, r.HPersist(cmd("HPERSIST", "key", "FIELDS", "x", "a")))
+	assert.Equal(t, protocol.RespExpireOptionsNotCompatible, r.HExpire(cmd("HEXPIRE", "key", "10", "NX", "XX", "FIELDS", "1", "a")))
+}
+
+func TestHExpire_Success(t *testing.T) {
+	r := newTestRedis()
+	assert.Equal(t, []byte("*2\r\n:-2\r\n:-2\r\n"), r.HExpire(cmd("HEXPIRE", "key", "100", "FIELDS", "2", "a", "b")))
+
+	r.HSet(cmd("HSET", "key", "a", "1", "b", "2", "c", "3"))
+	assert.Equal(t, []byte("*2\r\n:1\r\n:-2\r\n"), r.HExpire(cmd("HEXPIRE", "key", "100", "FIELDS", "2", "a", "missing")))
+	assert.Equal(t, []byte("*2\r\n:0\r\n:1\r\n"), r.HExpire(cmd("HEXPIRE", "key", "200", "NX", "FIELDS", "2", "a", "b")))
+	assert.Equal(t, []byte("*1\r\n:2\r\n"), r.HExpire(cmd("HEXPIRE", "key", "0", "FIELDS", "1", "c")))
+	assert.Equal(t, []byte(":2\r\n"), r.HLen(cmd("HLEN", "key")))
+
+	r.HPExpire(cmd("HPEXPIRE", "key", "1", "FIELDS", "2", "a", "b"))
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, []byte(":0\r\n"), r.HLen(cmd("HLEN", "key")))
+	assert.Equal(t, []byte(":0\r\n"), r.Exists(cmd("EXISTS", "key")))
+}
+
+func TestHExpireAt_Success(t *testing.T) {
+	r := newTestRedis()
+	r.HSet(cmd("HSET", "key", "a", "1", "b", "2"))
+
+	expireAt := time.Now().Add(time.Hour).Unix()
+	when := strconv.FormatInt(expireAt, 10)
+	assert.Equal(t, []byte("*1\r\n:1\r\n"), r.HExpireAt(cmd("HEXPIREAT", "key", when, "FIELDS", "1", "a")))
+	assert.Equal(t, []byte("*2\r\n:"+when+"\r\n:-1\r\n"), r.HExpireTime(cmd("HEXPIRETIME", "key", "FIELDS", "2", "a", "b")))
+	assert.Equal(t, []byte("*1\r\n:"+when+"000\r\n"), r.HPExpireTime(cmd("HPEXPIRETIME", "key", "FIELDS", "1", "a")))
+
+	assert.Equal(t, []byte("*1\r\n:2\r\n"), r.HPExpireAt(cmd("HPEXPIREAT", "key", "1", "FIELDS", "1", "b")))
+	assert.Equal(t, protocol.RespNilBulkString, r.HGet(cmd("HGET", "key", "b")))
+}
+
+func TestHExpireAt_MaxExpireTime(t *testing.T) {
+	r := newTestRedis()
+	r.HSet(cmd("HSET", "key", "a", "1"))
+
+	assert.Equal(t, []byte("*1\r\n:1\r\n"), r.HPExpireAt(cmd("HPEXPIREAT", "key", "281474976710655", "FIELDS", "1", "a")))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidExpireTime("HPEXPIREAT"), false), r.HPExpireAt(cmd("HPEXPIREAT", "key", "281474976710656", "FIELDS", "1", "a")))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidExpireTime("HPEXPIREAT"), false), r.HPExpireAt(cmd("HPEXPIREAT", "key", "9223372036854775807", "FIELDS", "1", "a")))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidExpireTime("HEXPIRE"), false), r.HExpire(cmd("HEXPIRE", "key", "281474976710", "FIELDS", "1", "a")))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidExpireTime("HGETEX"), false), r.HGetEx(cmd("HGETEX", "key", "PXAT", "281474976710656", "FIELDS", "1", "a")))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidExpireTime("HSETEX"), false), r.HSetEx(cmd("HSETEX", "key", "EXAT", "281474976711", "FIELDS", "1", "a", "2")))
+	assert.Equal(t, []byte("*1\r\n:281474976710655\r\n"), r.HPExpireTime(cmd("HPEXPIRETIME", "key", "FIELDS", "1", "a")))
+}
+
+// HTTL Tests
+func TestHTTL_Success(t *testing.T) {
+	r := newTestRedis()
+	assert.Equal(t, []byte("*1\r\n:-2\r\n"), r.HTTL(cmd("HTTL", "key", "FIELDS", "1", "a")))
+
+	r.HSet(cmd("HSET", "key", "a", "1", "b", "2"))
+	r.HExpire(cmd("HEXPIRE", "key", "100", "FIELDS", "1", "a"))
+	assert.Equal(t, []byte("*3\r\n:100\r\n:-1\r\n:-2\r\n"), r.HTTL(cmd("HTTL", "key", "FIELDS", "3", "a", "b", "c")))
+
+	decoded, _, err := protocol.DecodeResp(r.HPTTL(cmd("HPTTL", "key", "FIELDS", "1", "a")))
+	require.NoError(t, err)
+	assert.InDelta(t, 100_000, decoded.([]interface{})[0], 1000)
+}
+
+// HPERSIST Tests
+func TestHPersist_Success(t *testing.T) {
+	r := newTestRedis()
+	r.HSet(cmd("HSET", "key", "a", "1", "b", "2"))
+	r.HExpire(cmd("HEXPIRE", "key", "100", "FIELDS", "1", "a"))
+
+	assert.Equal(t, []byte("*3\r\n:1\r\n:-1\r\n:-2\r\n"), r.HPersist(cmd("HPERSIST", "key", "FIELDS", "3", "a", "b", "c")))
+	assert.Equal(t, []byte("*1\r\n:-1\r\n"), r.HTTL(cmd("HTTL", "key", "FIELDS", "1", "a")))
+}
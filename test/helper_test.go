@@ -1,15 +1,23 @@
 package test
 
 import (
+	"os"
+
 	"github.com/manhhung2111/go-redis/internal/command"
 	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/persistence"
 	"github.com/manhhung2111/go-redis/internal/protocol"
 	"github.com/manhhung2111/go-redis/internal/storage"
 )
 
 func newTestRedis() command.Redis {
+	cfg := config.NewConfig()
+	cfg.Dir = os.TempDir()
+	databases := storage.NewDatabases(cfg)
 	return command.NewRedis(
-		storage.NewStore(config.NewConfig()),
+		databases,
+		persistence.NewRDB(cfg, databases),
+		persistence.NewAOF(cfg, databases),
 	)
 }
 
@@ -0,0 +1,139 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/manhhung2111/go-redis/internal/command"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+)
+
+func TestDelAndUnlink(t *testing.T) {
+	r := newTestRedis()
+	r.MSet(cmd("MSET", "a", "1", "b", "2", "c", "3"))
+
+	assert.Equal(t, []byte(":2\r\n"), r.Del(cmd("DEL", "a", "b", "missing")))
+	assert.Equal(t, []byte(":1\r\n"), r.Unlink(cmd("UNLINK", "c", "c")))
+	assert.Equal(t, []byte(":0\r\n"), r.DBSize(cmd("DBSIZE")))
+}
+
+func TestExistsAndTouch(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "a", "1"))
+
+	assert.Equal(t, []byte(":2\r\n"), r.Exists(cmd("EXISTS", "a", "missing", "a")))
+	assert.Equal(t, []byte(":1\r\n"), r.Touch(cmd("TOUCH", "a", "missing")))
+}
+
+func TestKeys(t *testing.T) {
+	r := newTestRedis()
+	r.MSet(cmd("MSET", "user:1", "a", "user:2", "b", "order:1", "c"))
+
+	assert.Equal(t, []byte("*0\r\n"), r.Keys(cmd("KEYS", "nothing*")))
+	assert.Equal(t, protocol.EncodeResp([]string{"order:1"}, false), r.Keys(cmd("KEYS", "o*")))
+	assert.Equal(t, []byte("*3\r\n"), r.Keys(cmd("KEYS", "*"))[:4])
+	assert.Equal(t, []byte("*2\r\n"), r.Keys(cmd("KEYS", "user:[12]"))[:4])
+}
+
+func TestType(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "s", "v"))
+	r.ZAdd(cmd("ZADD", "z", "1", "m"))
+	r.XAdd(cmd("XADD", "x", "*", "f", "v"))
+
+	assert.Equal(t, []byte("+string\r\n"), r.Type(cmd("TYPE", "s")))
+	assert.Equal(t, []byte("+zset\r\n"), r.Type(cmd("TYPE", "z")))
+	assert.Equal(t, []byte("+stream\r\n"), r.Type(cmd("TYPE", "x")))
+	assert.Equal(t, []byte("+none\r\n"), r.Type(cmd("TYPE", "missing")))
+}
+
+func TestRename(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "a", "1"))
+	r.Expire(cmd("EXPIRE", "a", "100"))
+	r.Set(cmd("SET", "b", "2"))
+
+	assert.Equal(t, protocol.RespOK, r.Rename(cmd("RENAME", "a", "b")))
+	assert.Equal(t, protocol.RespNilBulkString, r.Get(cmd("GET", "a")))
+	assert.Equal(t, []byte("$1\r\n1\r\n"), r.Get(cmd("GET", "b")))
+	assert.Equal(t, []byte(":100\r\n"), r.TTL(cmd("TTL", "b")))
+
+	assert.Equal(t, []byte("-ERR no such key\r\n"), r.Rename(cmd("RENAME", "a", "b")))
+
+	r.Set(cmd("SET", "c", "3"))
+	assert.Equal(t, []byte(":0\r\n"), r.RenameNX(cmd("RENAMENX", "b", "c")))
+	assert.Equal(t, []byte(":1\r\n"), r.RenameNX(cmd("RENAMENX", "b", "d")))
+	assert.Equal(t, []byte(":0\r\n"), r.RenameNX(cmd("RENAMENX", "d", "d")))
+}
+
+func TestRename_ServesBlockedClients(t *testing.T) {
+	r := newTestRedis()
+	waiter := command.NewClient()
+	r.HandleCommand(waiter, cmd("BLPOP", "q", "0"))
+
+	r.RPush(cmd("RPUSH", "tmp", "job"))
+	r.HandleCommand(command.NewClient(), cmd("RENAME", "tmp", "q"))
+	assert.Equal(t, []command.UnblockedClient{
+		{Client: waiter, Reply: protocol.EncodeResp([]string{"q", "job"}, false)},
+	}, r.UnblockedClients())
+}
+
+func TestCopy(t *testing.T) {
+	r := newTestRedis()
+	r.SAdd(cmd("SADD", "src", "a", "b"))
+	r.Set(cmd("SET", "dst", "v"))
+
+	assert.Equal(t, []byte(":0\r\n"), r.Copy(cmd("COPY", "src", "dst")))
+	assert.Equal(t, []byte(":1\r\n"), r.Copy(cmd("COPY", "src", "dst", "REPLACE")))
+	assert.Equal(t, []byte(":0\r\n"), r.Copy(cmd("COPY", "missing", "dst", "REPLACE")))
+
+	r.SRem(cmd("SREM", "dst", "a"))
+	assert.Equal(t, []byte(":2\r\n"), r.SCard(cmd("SCARD", "src")))
+	assert.Equal(t, []byte(":1\r\n"), r.SCard(cmd("SCARD", "dst")))
+
+	assert.Equal(t, protocol.RespSameObject, r.Copy(cmd("COPY", "src", "src")))
+	assert.Equal(t, protocol.RespSyntaxError, r.Copy(cmd("COPY", "src", "dst", "NOPE")))
+}
+
+func TestRandomKeyAndDBSize(t *testing.T) {
+	r := newTestRedis()
+	assert.Equal(t, protocol.RespNilBulkString, r.RandomKey(cmd("RANDOMKEY")))
+	assert.Equal(t, []byte(":0\r\n"), r.DBSize(cmd("DBSIZE")))
+
+	r.Set(cmd("SET", "only", "v"))
+	assert.Equal(t, []byte("$4\r\nonly\r\n"), r.RandomKey(cmd("RANDOMKEY")))
+	assert.Equal(t, []byte(":1\r\n"), r.DBSize(cmd("DBSIZE")))
+}
+
+func TestObjectEncoding(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "str", "hello"))
+	r.SAdd(cmd("SADD", "ints", "1", "2"))
+	r.SAdd(cmd("SADD", "set", "a", "b"))
+	r.HSet(cmd("HSET", "hash", "field", "value"))
+	r.ZAdd(cmd("ZADD", "zset", "1", "a"))
+
+	assert.Equal(t, []byte("$6\r\nembstr\r\n"), r.Object(cmd("OBJECT", "ENCODING", "str")))
+	assert.Equal(t, []byte("$6\r\nintset\r\n"), r.Object(cmd("OBJECT", "ENCODING", "ints")))
+	assert.Equal(t, []byte("$8\r\nlistpack\r\n"), r.Object(cmd("OBJECT", "encoding", "set")))
+	assert.Equal(t, []byte("$8\r\nlistpack\r\n"), r.Object(cmd("OBJECT", "ENCODING", "hash")))
+	assert.Equal(t, []byte("$8\r\nlistpack\r\n"), r.Object(cmd("OBJECT", "ENCODING", "zset")))
+	assert.Equal(t, protocol.RespNilBulkString, r.Object(cmd("OBJECT", "ENCODING", "missing")))
+
+	r.HSet(cmd("HSET", "hash", "long", strings.Repeat("x", 65)))
+	assert.Equal(t, []byte("$9\r\nhashtable\r\n"), r.Object(cmd("OBJECT", "ENCODING", "hash")))
+
+	resp := r.Object(cmd("OBJECT", "FREQ", "str"))
+	assert.Equal(t, "-ERR unknown subcommand 'FREQ'. Try OBJECT HELP.\r\n", string(resp))
+	resp = r.Object(cmd("OBJECT", "ENCODING"))
+	assert.Equal(t, byte('-'), resp[0])
+
+	help, _, err := protocol.DecodeResp(r.Object(cmd("OBJECT", "help")))
+	assert.NoError(t, err)
+	assert.Contains(t, help, "ENCODING <key>")
+	assert.Contains(t, help, "HELP")
+	resp = r.Object(cmd("OBJECT", "HELP", "extra"))
+	assert.Equal(t, byte('-'), resp[0])
+}
@@ -920,4 +920,191 @@ func TestRPushX_AfterLPush(t *testing.T) {
 	result := rangeVal.([]interface{})
 	assert.Equal(t, "head", result[0])
 	assert.Equal(t, "tail", result[1])
-}
\ No newline at end of file
+}
+func TestLInsert(t *testing.T) {
+	r := newTestRedis()
+	r.RPush(cmd("RPUSH", "mylist", "Hello", "World"))
+
+	resp := r.LInsert(cmd("LINSERT", "mylist", "BEFORE", "World", "There"))
+	assert.Equal(t, protocol.EncodeResp(int64(3), false), resp)
+
+	resp = r.LInsert(cmd("LINSERT", "mylist", "after", "World", "!"))
+	assert.Equal(t, protocol.EncodeResp(int64(4), false), resp)
+
+	resp = r.LRange(cmd("LRANGE", "mylist", "0", "-1"))
+	assert.Equal(t, protocol.EncodeResp([]string{"Hello", "There", "World", "!"}, false), resp)
+
+	resp = r.LInsert(cmd("LINSERT", "mylist", "BEFORE", "missing", "x"))
+	assert.Equal(t, protocol.EncodeResp(int64(-1), false), resp)
+
+	resp = r.LInsert(cmd("LINSERT", "nokey", "BEFORE", "World", "x"))
+	assert.Equal(t, protocol.EncodeResp(int64(0), false), resp)
+
+	resp = r.LInsert(cmd("LINSERT", "mylist", "MIDDLE", "World", "x"))
+	assert.Equal(t, protocol.RespSyntaxError, resp)
+
+	resp = r.LInsert(cmd("LINSERT", "mylist", "BEFORE", "World"))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidNumberOfArgs("LINSERT"), false), resp)
+}
+
+func TestLPos(t *testing.T) {
+	r := newTestRedis()
+	r.RPush(cmd("RPUSH", "mylist", "a", "b", "c", "d", "1", "2", "3", "4", "3", "3", "3"))
+
+	resp := r.LPos(cmd("LPOS", "mylist", "3"))
+	assert.Equal(t, protocol.EncodeResp(int64(6), false), resp)
+
+	resp = r.LPos(cmd("LPOS", "mylist", "3", "COUNT", "0", "MAXLEN", "1", "RANK", "-1"))
+	assert.Equal(t, protocol.EncodeResp([]int64{10}, false), resp)
+
+	resp = r.LPos(cmd("LPOS", "mylist", "3", "COUNT", "2"))
+	assert.Equal(t, protocol.EncodeResp([]int64{6, 8}, false), resp)
+
+	resp = r.LPos(cmd("LPOS", "mylist", "3", "RANK", "2"))
+	assert.Equal(t, protocol.EncodeResp(int64(8), false), resp)
+
+	resp = r.LPos(cmd("LPOS", "mylist", "3", "COUNT", "0"))
+	assert.Equal(t, protocol.EncodeResp([]int64{6, 8, 9, 10}, false), resp)
+
+	resp = r.LPos(cmd("LPOS", "mylist", "x"))
+	assert.Equal(t, protocol.RespNilBulkString, resp)
+
+	resp = r.LPos(cmd("LPOS", "mylist", "x", "COUNT", "1"))
+	assert.Equal(t, protocol.EncodeResp([]int64{}, false), resp)
+
+	resp = r.LPos(cmd("LPOS", "nokey", "x"))
+	assert.Equal(t, protocol.RespNilBulkString, resp)
+}
+
+func TestLPos_Errors(t *testing.T) {
+	r := newTestRedis()
+	r.RPush(cmd("RPUSH", "mylist", "a"))
+
+	resp := r.LPos(cmd("LPOS", "mylist", "a", "RANK", "0"))
+	assert.Equal(t, protocol.RespLPosRankZero, resp)
+
+	resp = r.LPos(cmd("LPOS", "mylist", "a", "COUNT", "-1"))
+	assert.Equal(t, protocol.RespLPosCountNegative, resp)
+
+	resp = r.LPos(cmd("LPOS", "mylist", "a", "MAXLEN", "-1"))
+	assert.Equal(t, protocol.RespLPosMaxLenNegative, resp)
+
+	resp = r.LPos(cmd("LPOS", "mylist", "a", "RANK", "abc"))
+	assert.Equal(t, protocol.RespValueNotIntegerOrOutOfRange, resp)
+
+	resp = r.LPos(cmd("LPOS", "mylist", "a", "RANK"))
+	assert.Equal(t, protocol.RespSyntaxError, resp)
+
+	resp = r.LPos(cmd("LPOS", "mylist", "a", "LIMIT", "1"))
+	assert.Equal(t, protocol.RespSyntaxError, resp)
+}
+
+func TestLMove(t *testing.T) {
+	r := newTestRedis()
+	r.RPush(cmd("RPUSH", "mylist", "one", "two", "three"))
+
+	resp := r.LMove(cmd("LMOVE", "mylist", "myotherlist", "RIGHT", "LEFT"))
+	assert.Equal(t, protocol.EncodeResp("three", false), resp)
+
+	resp = r.LMove(cmd("LMOVE", "mylist", "myotherlist", "LEFT", "RIGHT"))
+	assert.Equal(t, protocol.EncodeResp("one", false), resp)
+
+	resp = r.LRange(cmd("LRANGE", "mylist", "0", "-1"))
+	assert.Equal(t, protocol.EncodeResp([]string{"two"}, false), resp)
+	resp = r.LRange(cmd("LRANGE", "myotherlist", "0", "-1"))
+	assert.Equal(t, protocol.EncodeResp([]string{"three", "one"}, false), resp)
+
+	resp = r.LMove(cmd("LMOVE", "missing", "myotherlist", "LEFT", "RIGHT"))
+	assert.Equal(t, protocol.RespNilBulkString, resp)
+
+	resp = r.LMove(cmd("LMOVE", "mylist", "myotherlist", "UP", "RIGHT"))
+	assert.Equal(t, protocol.RespSyntaxError, resp)
+
+	r.Set(cmd("SET", "str", "value"))
+	resp = r.LMove(cmd("LMOVE", "mylist", "str", "LEFT", "RIGHT"))
+	assert.Equal(t, protocol.RespWrongTypeOperation, resp)
+	resp = r.LLen(cmd("LLEN", "mylist"))
+	assert.Equal(t, protocol.EncodeResp(uint32(1), false), resp)
+}
+
+func TestLMove_Rotation(t *testing.T) {
+	r := newTestRedis()
+	r.RPush(cmd("RPUSH", "mylist", "one"))
+	r.Expire(cmd("EXPIRE", "mylist", "100"))
+
+	resp := r.LMove(cmd("LMOVE", "mylist", "mylist", "LEFT", "RIGHT"))
+	assert.Equal(t, protocol.EncodeResp("one", false), resp)
+
+	resp = r.TTL(cmd("TTL", "mylist"))
+	assert.Equal(t, protocol.EncodeResp(int64(100), false), resp)
+}
+
+func TestRPopLPush(t *testing.T) {
+	r := newTestRedis()
+	r.RPush(cmd("RPUSH", "mylist", "one", "two", "three"))
+
+	resp := r.RPopLPush(cmd("RPOPLPUSH", "mylist", "myotherlist"))
+	assert.Equal(t, protocol.EncodeResp("three", false), resp)
+
+	resp = r.RPopLPush(cmd("RPOPLPUSH", "mylist", "mylist"))
+	assert.Equal(t, protocol.EncodeResp("two", false), resp)
+
+	resp = r.LRange(cmd("LRANGE", "mylist", "0", "-1"))
+	assert.Equal(t, protocol.EncodeResp([]string{"two", "one"}, false), resp)
+	resp = r.LRange(cmd("LRANGE", "myotherlist", "0", "-1"))
+	assert.Equal(t, protocol.EncodeResp([]string{"three"}, false), resp)
+
+	resp = r.RPopLPush(cmd("RPOPLPUSH", "mylist"))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidNumberOfArgs("RPOPLPUSH"), false), resp)
+}
+
+func TestLMPop(t *testing.T) {
+	r := newTestRedis()
+
+	resp := r.LMPop(cmd("LMPOP", "2", "non1", "non2", "LEFT", "COUNT", "10"))
+	assert.Equal(t, protocol.RespNilArray, resp)
+
+	r.LPush(cmd("LPUSH", "mylist", "one", "two", "three", "four", "five"))
+	resp = r.LMPop(cmd("LMPOP", "1", "mylist", "LEFT"))
+	assert.Equal(t, protocol.EncodeResp([]any{"mylist", []string{"five"}}, false), resp)
+
+	resp = r.LMPop(cmd("LMPOP", "1", "mylist", "RIGHT", "COUNT", "10"))
+	assert.Equal(t, protocol.EncodeResp([]any{"mylist", []string{"one", "two", "three", "four"}}, false), resp)
+
+	r.LPush(cmd("LPUSH", "mylist", "one", "two", "three", "four", "five"))
+	r.LPush(cmd("LPUSH", "mylist2", "a", "b", "c", "d", "e"))
+	resp = r.LMPop(cmd("LMPOP", "2", "mylist", "mylist2", "right", "count", "3"))
+	assert.Equal(t, protocol.EncodeResp([]any{"mylist", []string{"one", "two", "three"}}, false), resp)
+
+	resp = r.LMPop(cmd("LMPOP", "2", "mylist", "mylist2", "RIGHT", "COUNT", "5"))
+	assert.Equal(t, protocol.EncodeResp([]any{"mylist", []string{"four", "five"}}, false), resp)
+
+	resp = r.LMPop(cmd("LMPOP", "2", "mylist", "mylist2", "RIGHT", "COUNT", "10"))
+	assert.Equal(t, protocol.EncodeResp([]any{"mylist2", []string{"a", "b", "c", "d", "e"}}, false), resp)
+
+	resp = r.Exists(cmd("EXISTS", "mylist", "mylist2"))
+	assert.Equal(t, protocol.EncodeResp(int64(0), false), resp)
+}
+
+func TestLMPop_Errors(t *testing.T) {
+	r := newTestRedis()
+
+	resp := r.LMPop(cmd("LMPOP", "0", "mylist", "LEFT"))
+	assert.Equal(t, protocol.RespNumKeysNotPositive, resp)
+
+	resp = r.LMPop(cmd("LMPOP", "3", "mylist", "LEFT"))
+	assert.Equal(t, protocol.RespNumKeysExceedArgs, resp)
+
+	resp = r.LMPop(cmd("LMPOP", "1", "mylist", "UP"))
+	assert.Equal(t, protocol.RespSyntaxError, resp)
+
+	resp = r.LMPop(cmd("LMPOP", "1", "mylist", "LEFT", "COUNT", "0"))
+	assert.Equal(t, protocol.RespCountNotPositive, resp)
+
+	resp = r.LMPop(cmd("LMPOP", "1", "mylist", "LEFT", "COUNT"))
+	assert.Equal(t, protocol.RespSyntaxError, resp)
+
+	r.Set(cmd("SET", "str", "value"))
+	resp = r.LMPop(cmd("LMPOP", "1", "str", "LEFT"))
+	assert.Equal(t, protocol.RespWrongTypeOperation, resp)
+}
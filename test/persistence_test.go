@@ -0,0 +1,345 @@
+package test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manhhung2111/go-redis/internal/command"
+	"github.com/manhhung2111/go-redis/internal/config"
+	"github.com/manhhung2111/go-redis/internal/persistence"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+	"github.com/manhhung2111/go-redis/internal/storage"
+)
+
+// newTestRedisWithAOF returns a server logging to an append-only file in dir.
+func newTestRedisWithAOF(t *testing.T, dir string) (command.Redis, *persistence.AOF) {
+	cfg := config.NewConfig()
+	cfg.Dir = dir
+	cfg.AppendOnly = true
+	databases := storage.NewDatabases(cfg)
+	aof := persistence.NewAOF(cfg, databases)
+	require.NoError(t, aof.Open())
+	t.Cleanup(func() { aof.Shutdown() })
+
+	return command.NewRedis(databases, persistence.NewRDB(cfg, databases), aof), aof
+}
+
+// loggedCommands flushes the append-only file in dir and returns the commands it holds,
+// except for the SELECT of the first database that the first logged write starts with.
+func loggedCommands(t *testing.T, aof *persistence.AOF, dir string) []protocol.RedisCmd {
+	require.NoError(t, aof.Flush())
+
+	cfg := config.NewConfig()
+	cfg.Dir = dir
+	var cmds []protocol.RedisCmd
+	_, err := persistence.NewAOF(cfg, storage.NewDatabases(cfg)).Load(func(c protocol.RedisCmd) error {
+		cmds = append(cmds, c)
+		return nil
+	})
+	require.NoError(t, err)
+	if len(cmds) == 0 {
+		return cmds
+	}
+
+	require.Equal(t, cmd("SELECT", "0"), cmds[0])
+	return cmds[1:]
+}
+
+// SAVE tests
+
+func TestSave(t *testing.T) {
+	r := newTestRedis()
+	r.HandleCommand(command.NewClient(), cmd("SET", "key", "value"))
+
+	resp := r.HandleCommand(command.NewClient(), cmd("SAVE"))
+	assert.Equal(t, protocol.RespOK, resp)
+}
+
+func TestSaveWrongArgs(t *testing.T) {
+	r := newTestRedis()
+
+	resp := r.HandleCommand(command.NewClient(), cmd("SAVE", "extra"))
+	assert.Equal(t, []byte("-ERR wrong number of arguments for 'SAVE' command\r\n"), resp)
+}
+
+// BGSAVE tests
+
+func TestBGSave(t *testing.T) {
+	r := newTestRedis()
+
+	resp := r.HandleCommand(command.NewClient(), cmd("BGSAVE"))
+	assert.Equal(t, protocol.RespBgsaveStarted, resp)
+
+	resp = r.HandleCommand(command.NewClient(), cmd("BGSAVE"))
+	assert.Equal(t, []byte("-ERR Background save already in progress\r\n"), resp)
+
+	resp = r.HandleCommand(command.NewClient(), cmd("SAVE"))
+	assert.Equal(t, []byte("-ERR Background save already in progress\r\n"), resp)
+}
+
+// LASTSAVE tests
+
+func TestLastSave(t *testing.T) {
+	r := newTestRedis()
+	now := time.Now().Unix()
+
+	resp := r.HandleCommand(command.NewClient(), cmd("LASTSAVE"))
+	lastSave, err := strconv.ParseInt(string(resp[1:len(resp)-2]), 10, 64)
+	assert.NoError(t, err)
+	assert.InDelta(t, now, lastSave, 1)
+}
+
+func TestLastSaveWrongArgs(t *testing.T) {
+	r := newTestRedis()
+
+	resp := r.HandleCommand(command.NewClient(), cmd("LASTSAVE", "extra"))
+	assert.Equal(t, []byte("-ERR wrong number of arguments for 'LASTSAVE' command\r\n"), resp)
+}
+
+// BGREWRITEAOF tests
+
+func TestBGRewriteAOF(t *testing.T) {
+	r := newTestRedis()
+
+	resp := r.HandleCommand(command.NewClient(), cmd("BGREWRITEAOF"))
+	assert.Equal(t, protocol.RespBgrewriteaofStarted, resp)
+
+	resp = r.HandleCommand(command.NewClient(), cmd("BGREWRITEAOF"))
+	assert.Equal(t, []byte("-ERR Background append only file rewriting already in progress\r\n"), resp)
+}
+
+// AOF propagation tests
+
+func TestAOFLogsSuccessfulWritesOnly(t *testing.T) {
+	dir := t.TempDir()
+	r, aof := newTestRedisWithAOF(t, dir)
+
+	r.HandleCommand(command.NewClient(), cmd("SET", "k", "v"))
+	r.HandleCommand(command.NewClient(), cmd("GET", "k"))
+	r.HandleCommand(command.NewClient(), cmd("LPUSH", "k", "x"))
+	r.HandleCommand(command.NewClient(), cmd("SET", "k", "other", "NX"))
+	r.HandleCommand(command.NewClient(), cmd("RPUSH", "list", "a"))
+
+	assert.Equal(t, []protocol.RedisCmd{
+		cmd("SET", "k", "v"),
+		cmd("RPUSH", "list", "a"),
+	}, loggedCommands(t, aof, dir))
+}
+
+func TestAOFLogsAbsoluteExpireTimes(t *testing.T) {
+	dir := t.TempDir()
+	r, aof := newTestRedisWithAOF(t, dir)
+
+	before := time.Now().UnixMilli()
+	r.HandleCommand(command.NewClient(), cmd("SET", "a", "v", "EX", "100"))
+	r.HandleCommand(command.NewClient(), cmd("SET", "b", "v"))
+	r.HandleCommand(command.NewClient(), cmd("EXPIRE", "b", "50"))
+	r.HandleCommand(command.NewClient(), cmd("EXPIRE", "missing", "50"))
+	r.HandleCommand(command.NewClient(), cmd("SET", "c", "v"))
+	r.HandleCommand(command.NewClient(), cmd("PEXPIRE", "c", "30000"))
+	r.HandleCommand(command.NewClient(), cmd("SET", "d", "v"))
+	r.HandleCommand(command.NewClient(), cmd("EXPIREAT", "d", "1000"))
+	after := time.Now().UnixMilli()
+
+	cmds := loggedCommands(t, aof, dir)
+	require.Len(t, cmds, 8)
+	assert.Equal(t, cmd("SET", "a", "v"), cmds[0])
+	assert.Equal(t, cmd("SET", "b", "v"), cmds[2])
+	assert.Equal(t, cmd("SET", "c", "v"), cmds[4])
+	assert.Equal(t, cmd("DEL", "d"), cmds[7])
+
+	for i, ttl := range map[int]int64{1: 100_000, 3: 50_000, 5: 30_000} {
+		assert.Equal(t, "PEXPIREAT", cmds[i].Cmd)
+		expireAt, err := strconv.ParseInt(cmds[i].Args[1], 10, 64)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, expireAt, before+ttl)
+		assert.LessOrEqual(t, expireAt, after+ttl)
+	}
+}
+
+func TestAOFLogsStringWritesThatHappened(t *testing.T) {
+	dir := t.TempDir()
+	r, aof := newTestRedisWithAOF(t, dir)
+	c := command.NewClient()
+
+	r.HandleCommand(c, cmd("SET", "a", "v1", "NX", "GET"))
+	r.HandleCommand(c, cmd("SET", "a", "v2", "NX", "GET"))
+	r.HandleCommand(c, cmd("SET", "a", "v3", "XX", "GET"))
+	r.HandleCommand(c, cmd("SET", "missing", "v", "XX", "GET"))
+	r.HandleCommand(c, cmd("SETEX", "b", "100", "v"))
+	r.HandleCommand(c, cmd("GETEX", "b"))
+	r.HandleCommand(c, cmd("GETEX", "b", "PERSIST"))
+	r.HandleCommand(c, cmd("GETEX", "a", "PX", "5000"))
+	r.HandleCommand(c, cmd("GETEX", "missing", "EX", "10"))
+
+	cmds := loggedCommands(t, aof, dir)
+	require.Len(t, cmds, 6)
+	assert.Equal(t, cmd("SET", "a", "v1"), cmds[0])
+	assert.Equal(t, cmd("SET", "a", "v3"), cmds[1])
+	assert.Equal(t, cmd("SET", "b", "v"), cmds[2])
+	assert.Equal(t, "PEXPIREAT", cmds[3].Cmd)
+	assert.Equal(t, cmd("PERSIST", "b"), cmds[4])
+	assert.Equal(t, "PEXPIREAT", cmds[5].Cmd)
+	assert.Equal(t, "a", cmds[5].Args[0])
+}
+
+func TestAOFLogsIncrByFloatResult(t *testing.T) {
+	dir := t.TempDir()
+	r, aof := newTestRedisWithAOF(t, dir)
+
+	r.HandleCommand(command.NewClient(), cmd("INCRBYFLOAT", "k", "0.1"))
+	r.HandleCommand(command.NewClient(), cmd("INCRBYFLOAT", "k", "0.2"))
+	r.HandleCommand(command.NewClient(), cmd("INCRBYFLOAT", "k", "x"))
+
+	assert.Equal(t, []protocol.RedisCmd{
+		cmd("SET", "k", "0.1", "KEEPTTL"),
+		cmd("SET", "k", "0.3", "KEEPTTL"),
+	}, loggedCommands(t, aof, dir))
+}
+
+func TestAOFLogsPoppedMembers(t *testing.T) {
+	dir := t.TempDir()
+	r, aof := newTestRedisWithAOF(t, dir)
+
+	r.HandleCommand(command.NewClient(), cmd("SADD", "s", "a", "b", "c"))
+	r.HandleCommand(command.NewClient(), cmd("SPOP", "s"))
+	r.HandleCommand(command.NewClient(), cmd("SPOP", "s", "2"))
+	r.HandleCommand(command.NewClient(), cmd("SPOP", "s"))
+
+	cmds := loggedCommands(t, aof, dir)
+	require.Len(t, cmds, 3)
+	assert.Equal(t, "SREM", cmds[1].Cmd)
+	assert.Len(t, cmds[1].Args, 2)
+	assert.Equal(t, "SREM", cmds[2].Cmd)
+	assert.Len(t, cmds[2].Args, 3)
+
+	assert.Equal(t, []byte("*0\r\n"), r.HandleCommand(command.NewClient(), cmd("SMEMBERS", "s")))
+}
+
+func TestAOFReplayRebuildsDataset(t *testing.T) {
+	dir := t.TempDir()
+	r, aof := newTestRedisWithAOF(t, dir)
+	r.HandleCommand(command.NewClient(), cmd("SET", "k", "v", "EX", "100"))
+	r.HandleCommand(command.NewClient(), cmd("SADD", "s", "a", "b", "c"))
+	r.HandleCommand(command.NewClient(), cmd("SPOP", "s", "2"))
+	r.HandleCommand(command.NewClient(), cmd("INCRBY", "n", "5"))
+	require.NoError(t, aof.Shutdown())
+
+	cfg := config.NewConfig()
+	cfg.Dir = dir
+	cfg.AppendOnly = true
+	databases := storage.NewDatabases(cfg)
+	replayed := command.NewRedis(databases, persistence.NewRDB(cfg, databases), persistence.NewAOF(cfg, databases))
+	loaded, err := persistence.NewAOF(cfg, databases).Load(replayed.ReplayCommand)
+	require.NoError(t, err)
+	assert.True(t, loaded)
+
+	assert.Equal(t, r.HandleCommand(command.NewClient(), cmd("SMEMBERS", "s")), replayed.HandleCommand(command.NewClient(), cmd("SMEMBERS", "s")))
+	assert.Equal(t, []byte("$1\r\n5\r\n"), replayed.HandleCommand(command.NewClient(), cmd("GET", "n")))
+	assert.Equal(t, r.HandleCommand(command.NewClient(), cmd("TTL", "k")), replayed.HandleCommand(command.NewClient(), cmd("TTL", "k")))
+}
+
+func TestAOFWrapsTransactionWrites(t *testing.T) {
+	dir := t.TempDir()
+	r, aof := newTestRedisWithAOF(t, dir)
+	c := command.NewClient()
+
+	r.HandleCommand(c, cmd("MULTI"))
+	r.HandleCommand(c, cmd("GET", "k"))
+	r.HandleCommand(c, cmd("EXEC"))
+
+	r.HandleCommand(c, cmd("MULTI"))
+	r.HandleCommand(c, cmd("SET", "k", "v"))
+	r.HandleCommand(c, cmd("GET", "k"))
+	r.HandleCommand(c, cmd("RPUSH", "list", "a"))
+	r.HandleCommand(c, cmd("EXEC"))
+
+	assert.Equal(t, []protocol.RedisCmd{
+		{Cmd: "MULTI", Args: []string{}},
+		cmd("SET", "k", "v"),
+		cmd("RPUSH", "list", "a"),
+		{Cmd: "EXEC", Args: []string{}},
+	}, loggedCommands(t, aof, dir))
+}
+
+func TestAOFLogsStreamWritesDeterministically(t *testing.T) {
+	dir := t.TempDir()
+	r, aof := newTestRedisWithAOF(t, dir)
+	c := command.NewClient()
+
+	r.HandleCommand(c, cmd("XADD", "s", "1-1", "f", "v"))
+	r.HandleCommand(c, cmd("XADD", "s", "MAXLEN", "~", "1", "1-*", "f", "w"))
+	r.HandleCommand(c, cmd("XADD", "s", "NOMKSTREAM", "MINID", "1-2", "*", "f", "x"))
+	r.HandleCommand(c, cmd("XADD", "missing", "NOMKSTREAM", "*", "f", "v"))
+	r.HandleCommand(c, cmd("XTRIM", "s", "MAXLEN", "5"))
+	r.HandleCommand(c, cmd("XTRIM", "s", "MAXLEN", "~", "0"))
+
+	cmds := loggedCommands(t, aof, dir)
+	require.Len(t, cmds, 4)
+	assert.Equal(t, cmd("XADD", "s", "1-1", "f", "v"), cmds[0])
+	assert.Equal(t, cmd("XADD", "s", "MAXLEN", "=", "2", "1-2", "f", "w"), cmds[1])
+	assert.Equal(t, "XADD", cmds[2].Cmd)
+	assert.Equal(t, []string{"s", "MAXLEN", "=", "2"}, cmds[2].Args[:4])
+	assert.NotContains(t, cmds[2].Args[4], "*")
+	assert.Equal(t, cmd("XTRIM", "s", "MAXLEN", "=", "0"), cmds[3])
+}
+
+func TestAOFLogsHashFieldWritesDeterministically(t *testing.T) {
+	dir := t.TempDir()
+	r, aof := newTestRedisWithAOF(t, dir)
+	c := command.NewClient()
+
+	before := time.Now().UnixMilli()
+	r.HandleCommand(c, cmd("HINCRBYFLOAT", "h", "w", "0.1"))
+	r.HandleCommand(c, cmd("HSETEX", "h", "FNX", "FIELDS", "1", "w", "1"))
+	r.HandleCommand(c, cmd("HSETEX", "h", "EX", "100", "FIELDS", "1", "a", "1"))
+	r.HandleCommand(c, cmd("HGETEX", "h", "FIELDS", "1", "a"))
+	r.HandleCommand(c, cmd("HGETEX", "h", "PERSIST", "FIELDS", "1", "a"))
+	r.HandleCommand(c, cmd("HGETEX", "h", "PX", "5000", "FIELDS", "2", "a", "w"))
+	r.HandleCommand(c, cmd("HGETEX", "h", "PXAT", "1", "FIELDS", "1", "w"))
+	after := time.Now().UnixMilli()
+
+	cmds := loggedCommands(t, aof, dir)
+	require.Len(t, cmds, 5)
+	assert.Equal(t, cmd("HSETEX", "h", "KEEPTTL", "FIELDS", "1", "w", "0.1"), cmds[0])
+	assert.Equal(t, cmd("HGETEX", "h", "PERSIST", "FIELDS", "1", "a"), cmds[2])
+	assert.Equal(t, cmd("HGETEX", "h", "PXAT", "1", "FIELDS", "1", "w"), cmds[4])
+
+	for i, ttl := range map[int]int64{1: 100_000, 3: 5000} {
+		assert.Equal(t, "PXAT", cmds[i].Args[1])
+		expireAt, err := strconv.ParseInt(cmds[i].Args[2], 10, 64)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, expireAt, before+ttl)
+		assert.LessOrEqual(t, expireAt, after+ttl)
+	}
+}
+
+func TestAOFLogsHashFieldExpireTimes(t *testing.T) {
+	dir := t.TempDir()
+	r, aof := newTestRedisWithAOF(t, dir)
+	c := command.NewClient()
+
+	r.HandleCommand(c, cmd("HSET", "h", "a", "1", "b", "2", "c", "3"))
+	before := time.Now().UnixMilli()
+	r.HandleCommand(c, cmd("HEXPIRE", "h", "100", "FIELDS", "2", "a", "missing"))
+	after := time.Now().UnixMilli()
+	r.HandleCommand(c, cmd("HPEXPIRE", "h", "0", "FIELDS", "1", "b"))
+	r.HandleCommand(c, cmd("HEXPIRE", "h", "100", "NX", "FIELDS", "1", "a"))
+	r.HandleCommand(c, cmd("HPERSIST", "h", "FIELDS", "1", "a"))
+
+	cmds := loggedCommands(t, aof, dir)
+	require.Len(t, cmds, 4)
+	assert.Equal(t, "HPEXPIREAT", cmds[1].Cmd)
+	assert.Equal(t, []string{"FIELDS", "1", "a"}, cmds[1].Args[2:])
+	expireAt, err := strconv.ParseInt(cmds[1].Args[1], 10, 64)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, expireAt, before+100_000)
+	assert.LessOrEqual(t, expireAt, after+100_000)
+
+	assert.Equal(t, cmd("HDEL", "h", "b"), cmds[2])
+	assert.Equal(t, cmd("HPERSIST", "h", "FIELDS", "1", "a"), cmds[3])
+}
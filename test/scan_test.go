@@ -0,0 +1,103 @@
+package test
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manhhung2111/go-redis/internal/command"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+)
+
+// scanAll runs a whole scan with handler, the cursor being at index cursorArg of args.
+func scanAll(t *testing.T, handler func(protocol.RedisCmd) []byte, name string, cursorArg int, args ...string) []string {
+	var all []string
+	cursor := "0"
+	for {
+		args[cursorArg] = cursor
+		decoded, _, err := protocol.DecodeResp(handler(cmd(name, args...)))
+		require.NoError(t, err)
+		reply := decoded.([]any)
+		require.Len(t, reply, 2)
+
+		for _, element := range reply[1].([]any) {
+			all = append(all, element.(string))
+		}
+		if cursor = reply[0].(string); cursor == "0" {
+			return all
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	r := newTestRedis()
+	for i := 0; i < 30; i++ {
+		r.Set(cmd("SET", "user:"+strconv.Itoa(i), "v"))
+	}
+	r.SAdd(cmd("SADD", "tags", "a"))
+	r.RPush(cmd("RPUSH", "queue", "x"))
+
+	assert.Len(t, scanAll(t, r.Scan, "SCAN", 0, "0"), 32)
+	assert.Len(t, scanAll(t, r.Scan, "SCAN", 0, "0", "MATCH", "user:*", "COUNT", "4"), 30)
+	assert.Equal(t, []string{"queue"}, scanAll(t, r.Scan, "SCAN", 0, "0", "TYPE", "list"))
+	assert.Equal(t, []string{"user:7"}, scanAll(t, r.Scan, "SCAN", 0, "0", "MATCH", "user:7", "TYPE", "string"))
+
+	// A scan is split in calls returning at most COUNT keys
+	assert.True(t, bytes.HasPrefix(r.Scan(cmd("SCAN", "0", "COUNT", "1")), []byte("*2\r\n$2\r\n31\r\n*1\r\n")))
+
+	assert.Equal(t, []byte("*2\r\n$1\r\n0\r\n*0\r\n"), newTestRedis().Scan(cmd("SCAN", "0")))
+}
+
+func TestScanErrors(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, protocol.RespInvalidCursor, r.Scan(cmd("SCAN", "abc")))
+	assert.Equal(t, protocol.RespInvalidCursor, r.Scan(cmd("SCAN", "-1")))
+	assert.Equal(t, protocol.RespSyntaxError, r.Scan(cmd("SCAN", "0", "COUNT", "0")))
+	assert.Equal(t, protocol.RespValueNotIntegerOrOutOfRange, r.Scan(cmd("SCAN", "0", "COUNT", "x")))
+	assert.Equal(t, protocol.RespSyntaxError, r.Scan(cmd("SCAN", "0", "MATCH")))
+	assert.Equal(t, protocol.RespSyntaxError, r.Scan(cmd("SCAN", "0", "NOVALUES")))
+	assert.Equal(t, protocol.RespSyntaxError, r.SScan(cmd("SSCAN", "s", "0", "TYPE", "set")))
+	assert.Equal(t, []byte("-ERR wrong number of arguments for 'SCAN' command\r\n"),
+		r.HandleCommand(command.NewClient(), cmd("SCAN")))
+}
+
+func TestSScan(t *testing.T) {
+	r := newTestRedis()
+	for i := 0; i < 50; i++ {
+		r.SAdd(cmd("SADD", "s", "member:"+strconv.Itoa(i)))
+	}
+
+	assert.Len(t, scanAll(t, r.SScan, "SSCAN", 1, "s", "0", "COUNT", "7"), 50)
+	assert.ElementsMatch(t, []string{"member:4", "member:40", "member:41", "member:42", "member:43", "member:44", "member:45", "member:46", "member:47", "member:48", "member:49"},
+		scanAll(t, r.SScan, "SSCAN", 1, "s", "0", "MATCH", "member:4*"))
+
+	r.SAdd(cmd("SADD", "ints", "3", "1", "2"))
+	assert.Equal(t, protocol.EncodeResp([]any{"0", []string{"1", "2", "3"}}, false), r.SScan(cmd("SSCAN", "ints", "0", "COUNT", "1")))
+
+	assert.Equal(t, []byte("*2\r\n$1\r\n0\r\n*0\r\n"), r.SScan(cmd("SSCAN", "missing", "0")))
+	r.Set(cmd("SET", "str", "v"))
+	assert.Equal(t, protocol.RespWrongTypeOperation, r.SScan(cmd("SSCAN", "str", "0")))
+}
+
+func TestHScan(t *testing.T) {
+	r := newTestRedis()
+	r.HSet(cmd("HSET", "h", "name", "alice", "age", "30"))
+
+	pairs := scanAll(t, r.HScan, "HSCAN", 1, "h", "0")
+	assert.ElementsMatch(t, []string{"name", "alice", "age", "30"}, pairs)
+	assert.Equal(t, []string{"name", "alice"}, scanAll(t, r.HScan, "HSCAN", 1, "h", "0", "MATCH", "n*"))
+	assert.ElementsMatch(t, []string{"name", "age"}, scanAll(t, r.HScan, "HSCAN", 1, "h", "0", "NOVALUES"))
+}
+
+func TestZScan(t *testing.T) {
+	r := newTestRedis()
+	r.ZAdd(cmd("ZADD", "z", "1", "one", "2.5", "two"))
+
+	assert.ElementsMatch(t, []string{"one", "1", "two", "2.5"}, scanAll(t, r.ZScan, "ZSCAN", 1, "z", "0"))
+	assert.Equal(t, []string{"two", "2.5"}, scanAll(t, r.ZScan, "ZSCAN", 1, "z", "0", "MATCH", "t*"))
+	assert.Equal(t, protocol.RespSyntaxError, r.ZScan(cmd("ZSCAN", "z", "0", "NOVALUES")))
+}
@@ -275,4 +275,91 @@ func TestSetWrongType(t *testing.T) {
 
 	resp := r.SAdd(cmd("SADD", "k", "a"))
 	assert.Equal(t, protocol.RespWrongTypeOperation, resp)
-}
\ No newline at end of file
+}
+func TestSMove(t *testing.T) {
+	r := newTestRedis()
+	r.SAdd(cmd("SADD", "myset", "one", "two"))
+	r.SAdd(cmd("SADD", "myotherset", "three"))
+
+	resp := r.SMove(cmd("SMOVE", "myset", "myotherset", "two"))
+	assert.Equal(t, []byte(":1\r\n"), resp)
+
+	resp = r.SMove(cmd("SMOVE", "myset", "myotherset", "four"))
+	assert.Equal(t, []byte(":0\r\n"), resp)
+
+	resp = r.SIsMember(cmd("SISMEMBER", "myotherset", "two"))
+	assert.Equal(t, []byte(":1\r\n"), resp)
+
+	resp = r.SMove(cmd("SMOVE", "myset", "myotherset"))
+	assert.Equal(t, byte('-'), resp[0])
+}
+
+func TestSInterSUnionSDiff(t *testing.T) {
+	r := newTestRedis()
+	r.SAdd(cmd("SADD", "key1", "a", "b", "c"))
+	r.SAdd(cmd("SADD", "key2", "c", "d", "e"))
+
+	resp := r.SInter(cmd("SINTER", "key1", "key2"))
+	assert.Equal(t, protocol.EncodeResp([]string{"c"}, false), resp)
+
+	resp = r.SDiff(cmd("SDIFF", "key1", "key2"))
+	assert.Contains(t, []string{"*2\r\n$1\r\na\r\n$1\r\nb\r\n", "*2\r\n$1\r\nb\r\n$1\r\na\r\n"}, string(resp))
+
+	resp = r.SUnion(cmd("SUNION", "key1", "key2", "missing"))
+	assert.Equal(t, []byte("*5\r\n"), resp[:4])
+
+	resp = r.SInter(cmd("SINTER", "key1", "missing"))
+	assert.Equal(t, []byte("*0\r\n"), resp)
+
+	r.Set(cmd("SET", "str", "v"))
+	resp = r.SUnion(cmd("SUNION", "key1", "str"))
+	assert.Equal(t, protocol.RespWrongTypeOperation, resp)
+}
+
+func TestSetAlgebraStore(t *testing.T) {
+	r := newTestRedis()
+	r.SAdd(cmd("SADD", "key1", "a", "b", "c"))
+	r.SAdd(cmd("SADD", "key2", "c", "d", "e"))
+
+	resp := r.SInterStore(cmd("SINTERSTORE", "key", "key1", "key2"))
+	assert.Equal(t, []byte(":1\r\n"), resp)
+
+	resp = r.SUnionStore(cmd("SUNIONSTORE", "key", "key1", "key2"))
+	assert.Equal(t, []byte(":5\r\n"), resp)
+
+	resp = r.SDiffStore(cmd("SDIFFSTORE", "key", "key1", "key2"))
+	assert.Equal(t, []byte(":2\r\n"), resp)
+
+	resp = r.SCard(cmd("SCARD", "key"))
+	assert.Equal(t, []byte(":2\r\n"), resp)
+
+	resp = r.SInterStore(cmd("SINTERSTORE", "key", "key1", "missing"))
+	assert.Equal(t, []byte(":0\r\n"), resp)
+
+	resp = r.Exists(cmd("EXISTS", "key"))
+	assert.Equal(t, []byte(":0\r\n"), resp)
+}
+
+func TestSInterCard(t *testing.T) {
+	r := newTestRedis()
+	r.SAdd(cmd("SADD", "key1", "a", "b", "c", "d"))
+	r.SAdd(cmd("SADD", "key2", "c", "d", "e"))
+
+	resp := r.SInterCard(cmd("SINTERCARD", "2", "key1", "key2"))
+	assert.Equal(t, []byte(":2\r\n"), resp)
+
+	resp = r.SInterCard(cmd("SINTERCARD", "2", "key1", "key2", "LIMIT", "1"))
+	assert.Equal(t, []byte(":1\r\n"), resp)
+
+	resp = r.SInterCard(cmd("SINTERCARD", "2", "key1", "key2", "LIMIT", "-1"))
+	assert.Equal(t, protocol.RespLimitNegative, resp)
+
+	resp = r.SInterCard(cmd("SINTERCARD", "2", "key1", "key2", "COUNT", "1"))
+	assert.Equal(t, protocol.RespSyntaxError, resp)
+
+	resp = r.SInterCard(cmd("SINTERCARD", "0", "key1"))
+	assert.Equal(t, protocol.RespNumKeysNotPositive, resp)
+
+	resp = r.SInterCard(cmd("SINTERCARD", "3", "key1", "key2"))
+	assert.Equal(t, protocol.RespNumKeysExceedArgs, resp)
+}
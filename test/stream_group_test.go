@@ -0,0 +1,282 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manhhung2111/go-redis/internal/command"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+)
+
+func read(key string, entries ...any) []any {
+	return []any{key, entries}
+}
+
+func TestXGroup(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, []byte("-ERR The XGROUP subcommand requires the key to exist. Note that for CREATE you may want to use the MKSTREAM option to create an empty stream automatically.\r\n"),
+		r.XGroup(cmd("XGROUP", "CREATE", "s", "g", "$")))
+	assert.Equal(t, protocol.RespOK, r.XGroup(cmd("XGROUP", "CREATE", "s", "g", "$", "MKSTREAM")))
+	assert.Equal(t, []byte("-BUSYGROUP Consumer Group name already exists\r\n"), r.XGroup(cmd("XGROUP", "CREATE", "s", "g", "0")))
+	assert.Equal(t, []byte(":0\r\n"), r.XLen(cmd("XLEN", "s")))
+
+	assert.Equal(t, []byte(":1\r\n"), r.XGroup(cmd("XGROUP", "CREATECONSUMER", "s", "g", "alice")))
+	assert.Equal(t, []byte(":0\r\n"), r.XGroup(cmd("XGROUP", "CREATECONSUMER", "s", "g", "alice")))
+	assert.Equal(t, []byte(":0\r\n"), r.XGroup(cmd("XGROUP", "DELCONSUMER", "s", "g", "alice")))
+	assert.Equal(t, protocol.RespOK, r.XGroup(cmd("XGROUP", "SETID", "s", "g", "0", "ENTRIESREAD", "0")))
+
+	assert.Equal(t, []byte("-NOGROUP No such consumer group 'other' for key name 's'\r\n"),
+		r.XGroup(cmd("XGROUP", "CREATECONSUMER", "s", "other", "alice")))
+
+	assert.Equal(t, []byte(":1\r\n"), r.XGroup(cmd("XGROUP", "DESTROY", "s", "g")))
+	assert.Equal(t, []byte(":0\r\n"), r.XGroup(cmd("XGROUP", "DESTROY", "s", "g")))
+
+	assert.Equal(t, []byte("-ERR unknown subcommand 'NOPE'. Try XGROUP HELP.\r\n"), r.XGroup(cmd("XGROUP", "NOPE")))
+	assert.Equal(t, []byte("-ERR wrong number of arguments for 'XGROUP|CREATE' command\r\n"), r.XGroup(cmd("XGROUP", "CREATE", "s")))
+}
+
+func TestXReadGroup(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+	r.XAdd(cmd("XADD", "s", "1-0", "a", "1"))
+	r.XAdd(cmd("XADD", "s", "2-0", "b", "2"))
+	r.XGroup(cmd("XGROUP", "CREATE", "s", "g", "0"))
+
+	assert.Equal(t, protocol.EncodeResp([]any{read("s", entry("1-0", "a", "1"))}, false),
+		r.HandleCommand(c, cmd("XREADGROUP", "GROUP", "g", "alice", "COUNT", "1", "STREAMS", "s", ">")))
+	assert.Equal(t, protocol.EncodeResp([]any{read("s", entry("2-0", "b", "2"))}, false),
+		r.HandleCommand(c, cmd("XREADGROUP", "GROUP", "g", "bob", "STREAMS", "s", ">")))
+	assert.Equal(t, protocol.RespNilArray,
+		r.HandleCommand(c, cmd("XREADGROUP", "GROUP", "g", "bob", "STREAMS", "s", ">")))
+
+	// Reading the history returns the pending entries of the consumer only
+	assert.Equal(t, protocol.EncodeResp([]any{read("s", entry("2-0", "b", "2"))}, false),
+		r.HandleCommand(c, cmd("XREADGROUP", "GROUP", "g", "bob", "STREAMS", "s", "0")))
+
+	// Deleted entries stay pending with a nil body
+	r.XDel(cmd("XDEL", "s", "1-0"))
+	assert.Equal(t, []byte("*1\r\n*2\r\n$1\r\ns\r\n*1\r\n*2\r\n$3\r\n1-0\r\n*-1\r\n"),
+		r.HandleCommand(c, cmd("XREADGROUP", "GROUP", "g", "alice", "STREAMS", "s", "0")))
+
+	assert.Equal(t, []byte(":2\r\n"), r.XAck(cmd("XACK", "s", "g", "1-0", "2-0", "3-0")))
+	assert.Equal(t, protocol.EncodeResp([]any{read("s")}, false),
+		r.HandleCommand(c, cmd("XREADGROUP", "GROUP", "g", "bob", "STREAMS", "s", "0")))
+}
+
+func TestXReadGroupNoAck(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+	r.XAdd(cmd("XADD", "s", "1-0", "a", "1"))
+	r.XGroup(cmd("XGROUP", "CREATE", "s", "g", "0"))
+
+	r.HandleCommand(c, cmd("XREADGROUP", "GROUP", "g", "alice", "NOACK", "STREAMS", "s", ">"))
+	assert.Equal(t, protocol.RespStreamEmptyPending, r.XPending(cmd("XPENDING", "s", "g")))
+}
+
+func TestXReadGroupErrors(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+	r.XAdd(cmd("XADD", "s", "1-0", "a", "1"))
+
+	assert.Equal(t, []byte("-NOGROUP No such key 's' or consumer group 'g' in XREADGROUP with GROUP option\r\n"),
+		r.HandleCommand(c, cmd("XREADGROUP", "GROUP", "g", "alice", "STREAMS", "s", ">")))
+	assert.Equal(t, []byte("-ERR Unbalanced 'xreadgroup' list of streams: for each stream key an ID or '>' must be specified.\r\n"),
+		r.HandleCommand(c, cmd("XREADGROUP", "GROUP", "g", "alice", "STREAMS", "s", "t", ">")))
+	assert.Equal(t, protocol.RespStreamDollarInGroup,
+		r.HandleCommand(c, cmd("XREADGROUP", "GROUP", "g", "alice", "STREAMS", "s", "$")))
+	assert.Equal(t, protocol.RespStreamGreaterNeedsGroup,
+		r.HandleCommand(c, cmd("XREAD", "STREAMS", "s", ">")))
+	assert.False(t, c.IsBlocked())
+}
+
+func TestXRead(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+	r.XAdd(cmd("XADD", "s", "1-0", "a", "1"))
+	r.XAdd(cmd("XADD", "s", "2-0", "b", "2"))
+	r.XAdd(cmd("XADD", "t", "1-0", "c", "3"))
+
+	assert.Equal(t, protocol.EncodeResp([]any{read("s", entry("2-0", "b", "2")), read("t", entry("1-0", "c", "3"))}, false),
+		r.HandleCommand(c, cmd("XREAD", "STREAMS", "s", "t", "1-0", "0")))
+	assert.Equal(t, protocol.EncodeResp([]any{read("s", entry("1-0", "a", "1"))}, false),
+		r.HandleCommand(c, cmd("XREAD", "COUNT", "1", "STREAMS", "s", "missing", "0", "0")))
+	assert.Equal(t, protocol.RespNilArray, r.HandleCommand(c, cmd("XREAD", "STREAMS", "s", "$")))
+}
+
+func TestXRead_BlocksUntilXAdd(t *testing.T) {
+	r := newTestRedis()
+	reader := command.NewClient()
+	groupReader := command.NewClient()
+	r.XGroup(cmd("XGROUP", "CREATE", "s", "g", "$", "MKSTREAM"))
+
+	assert.Nil(t, r.HandleCommand(reader, cmd("XREAD", "BLOCK", "0", "STREAMS", "s", "$")))
+	assert.Nil(t, r.HandleCommand(groupReader, cmd("XREADGROUP", "GROUP", "g", "alice", "BLOCK", "0", "STREAMS", "s", ">")))
+	assert.True(t, reader.IsBlocked())
+	assert.True(t, groupReader.IsBlocked())
+
+	r.HandleCommand(command.NewClient(), cmd("XADD", "s", "1-0", "a", "1"))
+	reply := protocol.EncodeResp([]any{read("s", entry("1-0", "a", "1"))}, false)
+	assert.ElementsMatch(t, []command.UnblockedClient{
+		{Client: reader, Reply: reply},
+		{Client: groupReader, Reply: reply},
+	}, r.UnblockedClients())
+
+	assert.Equal(t, []byte("*4\r\n:1\r\n$3\r\n1-0\r\n$3\r\n1-0\r\n*1\r\n*2\r\n$5\r\nalice\r\n$1\r\n1\r\n"),
+		r.XPending(cmd("XPENDING", "s", "g")))
+}
+
+func TestXReadGroup_BlockedClientGetsNoGroupOnDestroy(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+	r.XGroup(cmd("XGROUP", "CREATE", "s", "g", "$", "MKSTREAM"))
+
+	assert.Nil(t, r.HandleCommand(c, cmd("XREADGROUP", "GROUP", "g", "alice", "BLOCK", "0", "STREAMS", "s", ">")))
+	r.HandleCommand(command.NewClient(), cmd("XGROUP", "DESTROY", "s", "g"))
+	r.HandleCommand(command.NewClient(), cmd("XADD", "s", "1-0", "a", "1"))
+
+	unblocked := r.UnblockedClients()
+	require.Len(t, unblocked, 1)
+	assert.Equal(t, byte('-'), unblocked[0].Reply[0])
+	assert.False(t, c.IsBlocked())
+}
+
+func TestXPending(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+	r.XAdd(cmd("XADD", "s", "1-0", "a", "1"))
+	r.XAdd(cmd("XADD", "s", "2-0", "b", "2"))
+	r.XAdd(cmd("XADD", "s", "3-0", "c", "3"))
+	r.XGroup(cmd("XGROUP", "CREATE", "s", "g", "0"))
+	r.HandleCommand(c, cmd("XREADGROUP", "GROUP", "g", "alice", "COUNT", "2", "STREAMS", "s", ">"))
+	r.HandleCommand(c, cmd("XREADGROUP", "GROUP", "g", "bob", "STREAMS", "s", ">"))
+
+	assert.Equal(t, []byte("*4\r\n:3\r\n$3\r\n1-0\r\n$3\r\n3-0\r\n*2\r\n*2\r\n$5\r\nalice\r\n$1\r\n2\r\n*2\r\n$3\r\nbob\r\n$1\r\n1\r\n"),
+		r.XPending(cmd("XPENDING", "s", "g")))
+
+	decoded, _, err := protocol.DecodeResp(r.XPending(cmd("XPENDING", "s", "g", "-", "+", "10", "bob")))
+	require.NoError(t, err)
+	require.Len(t, decoded, 1)
+	pending := decoded.([]any)[0].([]any)
+	assert.Equal(t, "3-0", pending[0])
+	assert.Equal(t, "bob", pending[1])
+	assert.Equal(t, int64(1), pending[3])
+
+	assert.Equal(t, []byte("*0\r\n"), r.XPending(cmd("XPENDING", "s", "g", "IDLE", "100000", "-", "+", "10")))
+	assert.Equal(t, protocol.RespSyntaxError, r.XPending(cmd("XPENDING", "s", "g", "-", "+")))
+	assert.Equal(t, []byte("-NOGROUP No such key 's' or consumer group 'nope'\r\n"), r.XPending(cmd("XPENDING", "s", "nope")))
+}
+
+func TestXClaim(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+	r.XAdd(cmd("XADD", "s", "1-0", "a", "1"))
+	r.XAdd(cmd("XADD", "s", "2-0", "b", "2"))
+	r.XGroup(cmd("XGROUP", "CREATE", "s", "g", "0"))
+	r.HandleCommand(c, cmd("XREADGROUP", "GROUP", "g", "alice", "STREAMS", "s", ">"))
+
+	// Entries are not idle for long enough yet
+	assert.Equal(t, []byte("*0\r\n"), r.XClaim(cmd("XCLAIM", "s", "g", "bob", "100000", "1-0")))
+
+	assert.Equal(t, protocol.EncodeResp([]any{entry("1-0", "a", "1")}, false),
+		r.XClaim(cmd("XCLAIM", "s", "g", "bob", "0", "1-0", "9-0")))
+	assert.Equal(t, protocol.EncodeResp([]string{"2-0"}, false),
+		r.XClaim(cmd("XCLAIM", "s", "g", "bob", "0", "2-0", "JUSTID")))
+	assert.Equal(t, []byte("*4\r\n:2\r\n$3\r\n1-0\r\n$3\r\n2-0\r\n*1\r\n*2\r\n$3\r\nbob\r\n$1\r\n2\r\n"),
+		r.XPending(cmd("XPENDING", "s", "g")))
+
+	// FORCE creates pending entries for IDs that exist in the stream only
+	r.XAdd(cmd("XADD", "s", "3-0", "c", "3"))
+	assert.Equal(t, protocol.EncodeResp([]string{"3-0"}, false),
+		r.XClaim(cmd("XCLAIM", "s", "g", "carol", "0", "3-0", "4-0", "FORCE", "JUSTID")))
+
+	assert.Equal(t, []byte("-ERR Unrecognized XCLAIM option 'RETRYCOUNT'\r\n"), r.XClaim(cmd("XCLAIM", "s", "g", "bob", "0", "1-0", "RETRYCOUNT")))
+	assert.Equal(t, []byte("-ERR Unrecognized XCLAIM option 'NOPE'\r\n"), r.XClaim(cmd("XCLAIM", "s", "g", "bob", "0", "1-0", "NOPE")))
+}
+
+func TestXAutoClaim(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+	for _, id := range []string{"1-0", "2-0", "3-0"} {
+		r.XAdd(cmd("XADD", "s", id, "f", "v"))
+	}
+	r.XGroup(cmd("XGROUP", "CREATE", "s", "g", "0"))
+	r.HandleCommand(c, cmd("XREADGROUP", "GROUP", "g", "alice", "STREAMS", "s", ">"))
+	r.XDel(cmd("XDEL", "s", "2-0"))
+
+	assert.Equal(t, protocol.EncodeResp([]any{"3-0", []any{entry("1-0", "f", "v")}, []any{"2-0"}}, false),
+		r.XAutoClaim(cmd("XAUTOCLAIM", "s", "g", "bob", "0", "0", "COUNT", "2")))
+	assert.Equal(t, protocol.EncodeResp([]any{"0-0", []string{"3-0"}, []any{}}, false),
+		r.XAutoClaim(cmd("XAUTOCLAIM", "s", "g", "bob", "0", "3-0", "JUSTID")))
+
+	assert.Equal(t, []byte("*4\r\n:2\r\n$3\r\n1-0\r\n$3\r\n3-0\r\n*1\r\n*2\r\n$3\r\nbob\r\n$1\r\n2\r\n"),
+		r.XPending(cmd("XPENDING", "s", "g")))
+}
+
+func TestXInfo(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+	r.XAdd(cmd("XADD", "s", "1-0", "a", "1"))
+	r.XAdd(cmd("XADD", "s", "2-0", "b", "2"))
+	r.XGroup(cmd("XGROUP", "CREATE", "s", "g", "0"))
+	r.HandleCommand(c, cmd("XREADGROUP", "GROUP", "g", "alice", "COUNT", "1", "STREAMS", "s", ">"))
+
+	decode := func(resp []byte) map[string]any {
+		decoded, _, err := protocol.DecodeResp(resp)
+		require.NoError(t, err)
+		fields := decoded.([]any)
+		info := make(map[string]any)
+		for i := 0; i+1 < len(fields); i += 2 {
+			info[fields[i].(string)] = fields[i+1]
+		}
+		return info
+	}
+
+	stream := decode(r.XInfo(cmd("XINFO", "STREAM", "s")))
+	assert.Equal(t, int64(2), stream["length"])
+	assert.Equal(t, "2-0", stream["last-generated-id"])
+	assert.Equal(t, int64(2), stream["entries-added"])
+	assert.Equal(t, int64(1), stream["groups"])
+
+	decoded, _, err := protocol.DecodeResp(r.XInfo(cmd("XINFO", "GROUPS", "s")))
+	require.NoError(t, err)
+	require.Len(t, decoded, 1)
+	group := decode(protocol.EncodeResp(decoded.([]any)[0], false))
+	assert.Equal(t, "g", group["name"])
+	assert.Equal(t, int64(1), group["consumers"])
+	assert.Equal(t, int64(1), group["pending"])
+	assert.Equal(t, "1-0", group["last-delivered-id"])
+	assert.Equal(t, int64(1), group["entries-read"])
+	assert.Equal(t, int64(1), group["lag"])
+
+	decoded, _, err = protocol.DecodeResp(r.XInfo(cmd("XINFO", "CONSUMERS", "s", "g")))
+	require.NoError(t, err)
+	require.Len(t, decoded, 1)
+	consumer := decode(protocol.EncodeResp(decoded.([]any)[0], false))
+	assert.Equal(t, "alice", consumer["name"])
+	assert.Equal(t, int64(1), consumer["pending"])
+
+	assert.Equal(t, []byte("-ERR no such key\r\n"), r.XInfo(cmd("XINFO", "STREAM", "missing")))
+	assert.Equal(t, []byte("-NOGROUP No such consumer group 'nope' for key name 's'\r\n"), r.XInfo(cmd("XINFO", "CONSUMERS", "s", "nope")))
+}
+
+func TestAOFLogsConsumerGroupCommands(t *testing.T) {
+	dir := t.TempDir()
+	r, aof := newTestRedisWithAOF(t, dir)
+	c := command.NewClient()
+
+	r.HandleCommand(c, cmd("XGROUP", "CREATE", "s", "g", "$", "MKSTREAM"))
+	r.HandleCommand(c, cmd("XREADGROUP", "GROUP", "g", "alice", "BLOCK", "0", "STREAMS", "s", ">"))
+	r.HandleCommand(command.NewClient(), cmd("XADD", "s", "1-0", "a", "1"))
+	require.Len(t, r.UnblockedClients(), 1)
+	r.HandleCommand(c, cmd("XACK", "s", "g", "1-0"))
+
+	logged := loggedCommands(t, aof, dir)
+	require.Len(t, logged, 4)
+	assert.Equal(t, cmd("XGROUP", "CREATE", "s", "g", "$", "MKSTREAM"), logged[0])
+	assert.Equal(t, cmd("XADD", "s", "1-0", "a", "1"), logged[1])
+	assert.Equal(t, cmd("XREADGROUP", "GROUP", "g", "alice", "STREAMS", "s", ">"), logged[2])
+	assert.Equal(t, cmd("XACK", "s", "g", "1-0"), logged[3])
+}
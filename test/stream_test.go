@@ -0,0 +1,150 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manhhung2111/go-redis/internal/protocol"
+	"github.com/manhhung2111/go-redis/internal/storage/types"
+)
+
+func entry(id string, fields ...string) []any {
+	return []any{id, fields}
+}
+
+func TestXAddExplicitIDs(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, []byte("$3\r\n1-1\r\n"), r.XAdd(cmd("XADD", "s", "1-1", "f", "v")))
+	assert.Equal(t, []byte("$3\r\n1-2\r\n"), r.XAdd(cmd("XADD", "s", "1-*", "f", "v")))
+	assert.Equal(t, []byte("$3\r\n5-0\r\n"), r.XAdd(cmd("XADD", "s", "5", "f", "v")))
+	assert.Equal(t, []byte(":3\r\n"), r.XLen(cmd("XLEN", "s")))
+
+	assert.Equal(t, protocol.EncodeResp(types.ErrStreamIDTooSmall, false), r.XAdd(cmd("XADD", "s", "5-0", "f", "v")))
+	assert.Equal(t, protocol.EncodeResp(types.ErrStreamIDTooSmall, false), r.XAdd(cmd("XADD", "s", "4-*", "f", "v")))
+	assert.Equal(t, protocol.EncodeResp(types.ErrStreamIDZero, false), r.XAdd(cmd("XADD", "other", "0-0", "f", "v")))
+	assert.Equal(t, protocol.EncodeResp(types.ErrInvalidStreamID, false), r.XAdd(cmd("XADD", "s", "abc", "f", "v")))
+}
+
+func TestXAddAutoID(t *testing.T) {
+	r := newTestRedis()
+
+	parseReply := func(resp []byte) types.StreamID {
+		decoded, _, err := protocol.DecodeResp(resp)
+		require.NoError(t, err)
+		id, err := types.ParseStreamID(decoded.(string), 0)
+		require.NoError(t, err)
+		return id
+	}
+
+	first := parseReply(r.XAdd(cmd("XADD", "s", "*", "f", "v")))
+	second := parseReply(r.XAdd(cmd("XADD", "s", "*", "f", "v")))
+	assert.Equal(t, 1, second.Compare(first))
+	assert.Equal(t, []byte(":2\r\n"), r.XLen(cmd("XLEN", "s")))
+}
+
+func TestXAddOptions(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, protocol.RespNilBulkString, r.XAdd(cmd("XADD", "s", "NOMKSTREAM", "*", "f", "v")))
+	assert.Equal(t, []byte(":0\r\n"), r.XLen(cmd("XLEN", "s")))
+
+	for i := 1; i <= 5; i++ {
+		r.XAdd(cmd("XADD", "s", "MAXLEN", "=", "3", "*", "f", "v"))
+	}
+	assert.Equal(t, []byte(":3\r\n"), r.XLen(cmd("XLEN", "s")))
+
+	r.XAdd(cmd("XADD", "m", "1-0", "f", "v"))
+	r.XAdd(cmd("XADD", "m", "2-0", "f", "v"))
+	r.XAdd(cmd("XADD", "m", "MINID", "2", "3-0", "f", "v"))
+	assert.Equal(t, []byte(":2\r\n"), r.XLen(cmd("XLEN", "m")))
+}
+
+func TestXAddErrors(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, []byte("-ERR wrong number of arguments for 'XADD' command\r\n"), r.XAdd(cmd("XADD", "s", "*", "f")))
+	assert.Equal(t, []byte("-ERR wrong number of arguments for 'XADD' command\r\n"), r.XAdd(cmd("XADD", "s", "*", "f", "v", "g")))
+	assert.Equal(t, protocol.RespStreamMaxLenNegative, r.XAdd(cmd("XADD", "s", "MAXLEN", "-1", "*", "f", "v")))
+	assert.Equal(t, protocol.RespValueNotIntegerOrOutOfRange, r.XAdd(cmd("XADD", "s", "MAXLEN", "x", "*", "f", "v")))
+	assert.Equal(t, protocol.RespStreamLimitWithoutApprox, r.XAdd(cmd("XADD", "s", "MAXLEN", "1", "LIMIT", "5", "*", "f", "v")))
+	assert.Equal(t, protocol.RespStreamLimitNegative, r.XAdd(cmd("XADD", "s", "MAXLEN", "~", "1", "LIMIT", "-5", "*", "f", "v")))
+
+	r.Set(cmd("SET", "str", "v"))
+	assert.Equal(t, protocol.RespWrongTypeOperation, r.XAdd(cmd("XADD", "str", "*", "f", "v")))
+}
+
+func TestXRange(t *testing.T) {
+	r := newTestRedis()
+	r.XAdd(cmd("XADD", "s", "1-1", "a", "1"))
+	r.XAdd(cmd("XADD", "s", "1-2", "b", "2", "c", "3"))
+	r.XAdd(cmd("XADD", "s", "2-0", "d", "4"))
+
+	all := protocol.EncodeResp([]any{entry("1-1", "a", "1"), entry("1-2", "b", "2", "c", "3"), entry("2-0", "d", "4")}, false)
+	assert.Equal(t, all, r.XRange(cmd("XRANGE", "s", "-", "+")))
+
+	// An ID without sequence number covers the whole millisecond
+	assert.Equal(t, protocol.EncodeResp([]any{entry("1-1", "a", "1"), entry("1-2", "b", "2", "c", "3")}, false),
+		r.XRange(cmd("XRANGE", "s", "1", "1")))
+
+	assert.Equal(t, protocol.EncodeResp([]any{entry("1-2", "b", "2", "c", "3")}, false),
+		r.XRange(cmd("XRANGE", "s", "(1-1", "(2-0")))
+
+	assert.Equal(t, protocol.EncodeResp([]any{entry("2-0", "d", "4"), entry("1-2", "b", "2", "c", "3")}, false),
+		r.XRevRange(cmd("XREVRANGE", "s", "+", "-", "COUNT", "2")))
+
+	assert.Equal(t, []byte("*0\r\n"), r.XRange(cmd("XRANGE", "s", "-", "+", "COUNT", "0")))
+	assert.Equal(t, []byte("*0\r\n"), r.XRange(cmd("XRANGE", "missing", "-", "+")))
+}
+
+func TestXRangeErrors(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, protocol.RespSyntaxError, r.XRange(cmd("XRANGE", "s", "-", "+", "COUNT")))
+	assert.Equal(t, protocol.RespSyntaxError, r.XRange(cmd("XRANGE", "s", "-", "+", "LIMIT", "1")))
+	assert.Equal(t, protocol.EncodeResp(types.ErrInvalidStreamID, false), r.XRange(cmd("XRANGE", "s", "x", "+")))
+	assert.Equal(t, protocol.RespStreamInvalidStartID, r.XRange(cmd("XRANGE", "s", "(18446744073709551615-18446744073709551615", "+")))
+	assert.Equal(t, protocol.RespStreamInvalidEndID, r.XRange(cmd("XRANGE", "s", "-", "(0-0")))
+}
+
+func TestXDel(t *testing.T) {
+	r := newTestRedis()
+	r.XAdd(cmd("XADD", "s", "1-1", "a", "1"))
+	r.XAdd(cmd("XADD", "s", "1-2", "b", "2"))
+
+	assert.Equal(t, []byte(":1\r\n"), r.XDel(cmd("XDEL", "s", "1-2", "9-9")))
+	assert.Equal(t, protocol.EncodeResp(types.ErrInvalidStreamID, false), r.XDel(cmd("XDEL", "s", "bad")))
+	assert.Equal(t, []byte(":1\r\n"), r.XLen(cmd("XLEN", "s")))
+
+	// The deleted ID still bounds the next ones
+	assert.Equal(t, protocol.EncodeResp(types.ErrStreamIDTooSmall, false), r.XAdd(cmd("XADD", "s", "1-2", "f", "v")))
+}
+
+func TestXTrim(t *testing.T) {
+	r := newTestRedis()
+	for i := 1; i <= 5; i++ {
+		r.XAdd(cmd("XADD", "s", "*", "f", "v"))
+	}
+
+	assert.Equal(t, []byte(":2\r\n"), r.XTrim(cmd("XTRIM", "s", "MAXLEN", "3")))
+	assert.Equal(t, []byte(":0\r\n"), r.XTrim(cmd("XTRIM", "s", "MAXLEN", "~", "1")))
+	assert.Equal(t, []byte(":3\r\n"), r.XTrim(cmd("XTRIM", "s", "MINID", "18446744073709551615")))
+	assert.Equal(t, []byte(":0\r\n"), r.XLen(cmd("XLEN", "s")))
+
+	assert.Equal(t, protocol.RespSyntaxError, r.XTrim(cmd("XTRIM", "s", "COUNT", "3")))
+	assert.Equal(t, protocol.RespSyntaxError, r.XTrim(cmd("XTRIM", "s", "MAXLEN", "3", "extra")))
+}
+
+func TestXTrimApproxRemovesWholeNodes(t *testing.T) {
+	r := newTestRedis()
+	for i := 0; i < 250; i++ {
+		r.XAdd(cmd("XADD", "s", "*", "f", "v"))
+	}
+
+	assert.Equal(t, []byte(":200\r\n"), r.XTrim(cmd("XTRIM", "s", "MAXLEN", "~", "10")))
+	resp := r.XTrim(cmd("XTRIM", "s", "MAXLEN", "~", "0", "LIMIT", "10"))
+	require.Equal(t, []byte(":0\r\n"), resp)
+	assert.Equal(t, []byte(":50\r\n"), r.XLen(cmd("XLEN", "s")))
+}
@@ -4,6 +4,7 @@ import (
 	"math"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -334,3 +335,262 @@ func TestMSet_Overwrite(t *testing.T) {
 
 	assert.Equal(t, expected, resp)
 }
+
+func TestSet_ExpireOptions(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, protocol.RespOK, r.Set(cmd("SET", "ex", "v", "EX", "100")))
+	assert.Equal(t, []byte(":100\r\n"), r.TTL(cmd("TTL", "ex")))
+
+	assert.Equal(t, protocol.RespOK, r.Set(cmd("SET", "px", "v", "PX", "100000")))
+	assert.Equal(t, []byte(":100\r\n"), r.TTL(cmd("TTL", "px")))
+
+	expireAt := time.Now().Add(time.Hour).Unix()
+	assert.Equal(t, protocol.RespOK, r.Set(cmd("SET", "exat", "v", "EXAT", strconv.FormatInt(expireAt, 10))))
+	assert.Equal(t, protocol.EncodeResp(expireAt, false), r.ExpireTime(cmd("EXPIRETIME", "exat")))
+
+	expireAtMs := time.Now().Add(time.Hour).UnixMilli()
+	assert.Equal(t, protocol.RespOK, r.Set(cmd("SET", "pxat", "v", "PXAT", strconv.FormatInt(expireAtMs, 10))))
+	assert.Equal(t, protocol.EncodeResp(expireAtMs, false), r.PExpireTime(cmd("PEXPIRETIME", "pxat")))
+}
+
+func TestSet_PastAbsoluteTimeDeletesKey(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "old"))
+
+	assert.Equal(t, protocol.RespOK, r.Set(cmd("SET", "k", "v", "PXAT", "1000")))
+	assert.Equal(t, protocol.RespNilBulkString, r.Get(cmd("GET", "k")))
+}
+
+func TestSet_KeepTTL(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v1", "EX", "100"))
+
+	assert.Equal(t, protocol.RespOK, r.Set(cmd("SET", "k", "v2", "KEEPTTL")))
+	assert.Equal(t, []byte(":100\r\n"), r.TTL(cmd("TTL", "k")))
+
+	r.Set(cmd("SET", "k", "v3"))
+	assert.Equal(t, protocol.RespTTLKeyExistNoExpire, r.TTL(cmd("TTL", "k")))
+}
+
+func TestSet_NXWithPXForLocks(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, protocol.RespOK, r.Set(cmd("SET", "lock", "owner1", "NX", "PX", "30000")))
+	assert.Equal(t, protocol.RespNilBulkString, r.Set(cmd("SET", "lock", "owner2", "NX", "PX", "30000")))
+	assert.Equal(t, protocol.EncodeResp("owner1", false), r.Get(cmd("GET", "lock")))
+}
+
+func TestSet_Get(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, protocol.RespNilBulkString, r.Set(cmd("SET", "k", "v1", "GET")))
+	assert.Equal(t, protocol.EncodeResp("v1", false), r.Set(cmd("SET", "k", "v2", "GET")))
+	assert.Equal(t, protocol.EncodeResp("v2", false), r.Get(cmd("GET", "k")))
+
+	// The old value is replied even when NX or XX prevent the write
+	assert.Equal(t, protocol.EncodeResp("v2", false), r.Set(cmd("SET", "k", "v3", "NX", "GET")))
+	assert.Equal(t, protocol.RespNilBulkString, r.Set(cmd("SET", "missing", "v", "XX", "GET")))
+	assert.Equal(t, protocol.EncodeResp("v2", false), r.Get(cmd("GET", "k")))
+	assert.Equal(t, protocol.RespNilBulkString, r.Get(cmd("GET", "missing")))
+
+	r.SAdd(cmd("SADD", "set", "a"))
+	assert.Equal(t, protocol.RespWrongTypeOperation, r.Set(cmd("SET", "set", "v", "GET")))
+	assert.Equal(t, []byte("+set\r\n"), r.Type(cmd("TYPE", "set")))
+}
+
+func TestSet_InvalidOptions(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, protocol.RespSyntaxError, r.Set(cmd("SET", "k", "v", "EX", "10", "PX", "100")))
+	assert.Equal(t, protocol.RespSyntaxError, r.Set(cmd("SET", "k", "v", "KEEPTTL", "EX", "10")))
+	assert.Equal(t, protocol.RespSyntaxError, r.Set(cmd("SET", "k", "v", "PXAT", "10", "KEEPTTL")))
+
+	expected := protocol.EncodeResp(errors.InvalidExpireTime("SET"), false)
+	assert.Equal(t, expected, r.Set(cmd("SET", "k", "v", "PX", "0")))
+	assert.Equal(t, expected, r.Set(cmd("SET", "k", "v", "EXAT", "-1")))
+	assert.Equal(t, expected, r.Set(cmd("SET", "k", "v", "EX", "9223372036854775")))
+
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidNumberOfArgs("SET"), false), r.Set(cmd("SET", "k", "v", "PX")))
+	assert.Equal(t, protocol.RespNilBulkString, r.Get(cmd("GET", "k")))
+}
+
+func TestGetEx(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v"))
+
+	assert.Equal(t, protocol.EncodeResp("v", false), r.GetEx(cmd("GETEX", "k")))
+	assert.Equal(t, protocol.RespTTLKeyExistNoExpire, r.TTL(cmd("TTL", "k")))
+
+	assert.Equal(t, protocol.EncodeResp("v", false), r.GetEx(cmd("GETEX", "k", "EX", "100")))
+	assert.Equal(t, []byte(":100\r\n"), r.TTL(cmd("TTL", "k")))
+
+	assert.Equal(t, protocol.EncodeResp("v", false), r.GetEx(cmd("GETEX", "k", "PERSIST")))
+	assert.Equal(t, protocol.RespTTLKeyExistNoExpire, r.TTL(cmd("TTL", "k")))
+
+	assert.Equal(t, protocol.EncodeResp("v", false), r.GetEx(cmd("GETEX", "k", "PXAT", "1000")))
+	assert.Equal(t, protocol.RespTTLKeyNotExist, r.TTL(cmd("TTL", "k")))
+
+	assert.Equal(t, protocol.RespNilBulkString, r.GetEx(cmd("GETEX", "k", "EX", "100")))
+}
+
+func TestGetEx_InvalidOptions(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v"))
+
+	assert.Equal(t, protocol.RespSyntaxError, r.GetEx(cmd("GETEX", "k", "EX", "10", "PERSIST")))
+	assert.Equal(t, protocol.RespSyntaxError, r.GetEx(cmd("GETEX", "k", "PERSIST", "PX", "10")))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidExpireTime("GETEX"), false), r.GetEx(cmd("GETEX", "k", "EX", "0")))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidCommandOption("KEEPTTL", "GETEX"), false), r.GetEx(cmd("GETEX", "k", "KEEPTTL")))
+	assert.Equal(t, protocol.RespTTLKeyExistNoExpire, r.TTL(cmd("TTL", "k")))
+}
+
+func TestGetDel(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "v"))
+
+	assert.Equal(t, protocol.EncodeResp("v", false), r.GetDel(cmd("GETDEL", "k")))
+	assert.Equal(t, protocol.RespNilBulkString, r.GetDel(cmd("GETDEL", "k")))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidNumberOfArgs("GETDEL"), false), r.GetDel(cmd("GETDEL")))
+}
+
+func TestGetSet(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, protocol.RespNilBulkString, r.GetSet(cmd("GETSET", "k", "v1")))
+	r.Expire(cmd("EXPIRE", "k", "100"))
+	assert.Equal(t, protocol.EncodeResp("v1", false), r.GetSet(cmd("GETSET", "k", "v2")))
+	assert.Equal(t, protocol.EncodeResp("v2", false), r.Get(cmd("GET", "k")))
+	assert.Equal(t, protocol.RespTTLKeyExistNoExpire, r.TTL(cmd("TTL", "k")))
+}
+
+func TestSetNX(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, []byte(":1\r\n"), r.SetNX(cmd("SETNX", "k", "v1")))
+	assert.Equal(t, []byte(":0\r\n"), r.SetNX(cmd("SETNX", "k", "v2")))
+	assert.Equal(t, protocol.EncodeResp("v1", false), r.Get(cmd("GET", "k")))
+}
+
+func TestSetEx(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, protocol.RespOK, r.SetEx(cmd("SETEX", "k", "100", "v")))
+	assert.Equal(t, protocol.EncodeResp("v", false), r.Get(cmd("GET", "k")))
+	assert.Equal(t, []byte(":100\r\n"), r.TTL(cmd("TTL", "k")))
+
+	assert.Equal(t, protocol.RespOK, r.PSetEx(cmd("PSETEX", "p", "100000", "v")))
+	assert.Equal(t, []byte(":100\r\n"), r.TTL(cmd("TTL", "p")))
+
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidExpireTime("SETEX"), false), r.SetEx(cmd("SETEX", "k", "0", "v")))
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidExpireTime("PSETEX"), false), r.PSetEx(cmd("PSETEX", "k", "abc", "v")))
+}
+
+func TestMSetNX(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, []byte(":1\r\n"), r.MSetNX(cmd("MSETNX", "a", "1", "b", "2")))
+	assert.Equal(t, []byte(":0\r\n"), r.MSetNX(cmd("MSETNX", "c", "3", "a", "10")))
+
+	one, two := "1", "2"
+	resp := r.MGet(cmd("MGET", "a", "b", "c"))
+	assert.Equal(t, protocol.EncodeResp([]*string{&one, &two, nil}, false), resp)
+
+	assert.Equal(t, protocol.EncodeResp(errors.InvalidNumberOfArgs("MSETNX"), false), r.MSetNX(cmd("MSETNX", "a")))
+}
+
+func TestAppend(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, []byte(":5\r\n"), r.Append(cmd("APPEND", "k", "Hello")))
+	assert.Equal(t, []byte(":11\r\n"), r.Append(cmd("APPEND", "k", " World")))
+	assert.Equal(t, protocol.EncodeResp("Hello World", false), r.Get(cmd("GET", "k")))
+	assert.Equal(t, []byte(":11\r\n"), r.StrLen(cmd("STRLEN", "k")))
+	assert.Equal(t, []byte(":0\r\n"), r.StrLen(cmd("STRLEN", "missing")))
+
+	r.Set(cmd("SET", "n", "10"))
+	r.Append(cmd("APPEND", "n", "5"))
+	assert.Equal(t, []byte(":106\r\n"), r.Incr(cmd("INCR", "n")))
+	r.Append(cmd("APPEND", "n", "x"))
+	assert.Equal(t, protocol.RespValueNotIntegerOrOutOfRange, r.Incr(cmd("INCR", "n")))
+}
+
+func TestGetRangeSetRange(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "Hello World"))
+
+	assert.Equal(t, protocol.EncodeResp("World", false), r.GetRange(cmd("GETRANGE", "k", "-5", "-1")))
+	assert.Equal(t, protocol.EncodeResp("", false), r.GetRange(cmd("GETRANGE", "missing", "0", "-1")))
+	assert.Equal(t, protocol.RespValueNotIntegerOrOutOfRange, r.GetRange(cmd("GETRANGE", "k", "a", "1")))
+
+	assert.Equal(t, []byte(":11\r\n"), r.SetRange(cmd("SETRANGE", "k", "6", "Redis")))
+	assert.Equal(t, protocol.EncodeResp("Hello Redis", false), r.Get(cmd("GET", "k")))
+	assert.Equal(t, []byte(":3\r\n"), r.SetRange(cmd("SETRANGE", "pad", "1", "ab")))
+	assert.Equal(t, protocol.EncodeResp("\x00ab", false), r.Get(cmd("GET", "pad")))
+
+	assert.Equal(t, protocol.RespOffsetOutOfRange, r.SetRange(cmd("SETRANGE", "k", "-1", "x")))
+	assert.Equal(t, protocol.RespValueNotIntegerOrOutOfRange, r.SetRange(cmd("SETRANGE", "k", "x", "x")))
+	assert.Equal(t, []byte("-ERR string exceeds maximum allowed size (proto-max-bulk-len)\r\n"), r.SetRange(cmd("SETRANGE", "k", "536870911", "ab")))
+	assert.Equal(t, []byte("-ERR string exceeds maximum allowed size (proto-max-bulk-len)\r\n"), r.SetRange(cmd("SETRANGE", "k", "9223372036854775807", "a")))
+}
+
+func TestIncrByFloat(t *testing.T) {
+	r := newTestRedis()
+	r.Set(cmd("SET", "k", "10.50"))
+
+	assert.Equal(t, protocol.EncodeResp("10.6", false), r.IncrByFloat(cmd("INCRBYFLOAT", "k", "0.1")))
+	assert.Equal(t, protocol.EncodeResp("5.6", false), r.IncrByFloat(cmd("INCRBYFLOAT", "k", "-5")))
+	assert.Equal(t, protocol.EncodeResp("3", false), r.IncrByFloat(cmd("INCRBYFLOAT", "new", "3.0")))
+
+	assert.Equal(t, protocol.RespValueNotValidFloat, r.IncrByFloat(cmd("INCRBYFLOAT", "k", "abc")))
+	r.Set(cmd("SET", "s", "abc"))
+	assert.Equal(t, protocol.RespValueNotValidFloat, r.IncrByFloat(cmd("INCRBYFLOAT", "s", "1")))
+	assert.Equal(t, []byte("-ERR increment would produce NaN or Infinity\r\n"), r.IncrByFloat(cmd("INCRBYFLOAT", "k", "inf")))
+}
+
+func TestIncrByFloat_LongDoubleRange(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, protocol.RespValueNotValidFloat, r.IncrByFloat(cmd("INCRBYFLOAT", "k", "1e5000")))
+	assert.Equal(t, protocol.RespValueNotValidFloat, r.IncrByFloat(cmd("INCRBYFLOAT", "k", "1e10000000")))
+	assert.Equal(t, protocol.RespNilBulkString, r.Get(cmd("GET", "k")))
+
+	r.Set(cmd("SET", "k", "1e4932"))
+	assert.Equal(t, []byte("-ERR increment would produce NaN or Infinity\r\n"), r.IncrByFloat(cmd("INCRBYFLOAT", "k", "1e4932")))
+	assert.Equal(t, protocol.EncodeResp("1e4932", false), r.Get(cmd("GET", "k")))
+}
+
+func TestLCS(t *testing.T) {
+	r := newTestRedis()
+	r.MSet(cmd("MSET", "key1", "ohmytext", "key2", "mynewtext"))
+
+	assert.Equal(t, protocol.EncodeResp("mytext", false), r.LCS(cmd("LCS", "key1", "key2")))
+	assert.Equal(t, []byte(":6\r\n"), r.LCS(cmd("LCS", "key1", "key2", "LEN")))
+
+	expected := protocol.EncodeResp([]any{
+		"matches", []any{
+			[]any{[]any{4, 7}, []any{5, 8}},
+			[]any{[]any{2, 3}, []any{0, 1}},
+		},
+		"len", 6,
+	}, false)
+	assert.Equal(t, expected, r.LCS(cmd("LCS", "key1", "key2", "IDX")))
+
+	expected = protocol.EncodeResp([]any{
+		"matches", []any{
+			[]any{[]any{4, 7}, []any{5, 8}, 4},
+		},
+		"len", 6,
+	}, false)
+	assert.Equal(t, expected, r.LCS(cmd("LCS", "key1", "key2", "IDX", "MINMATCHLEN", "4", "WITHMATCHLEN")))
+}
+
+func TestLCS_Errors(t *testing.T) {
+	r := newTestRedis()
+	r.RPush(cmd("RPUSH", "list", "a"))
+
+	assert.Equal(t, protocol.RespLCSLenAndIdx, r.LCS(cmd("LCS", "a", "b", "LEN", "IDX")))
+	assert.Equal(t, protocol.RespSyntaxError, r.LCS(cmd("LCS", "a", "b", "BAD")))
+	assert.Equal(t, protocol.RespSyntaxError, r.LCS(cmd("LCS", "a", "b", "MINMATCHLEN")))
+	assert.Equal(t, []byte("-ERR The specified keys must contain string values\r\n"), r.LCS(cmd("LCS", "a", "list")))
+}
@@ -0,0 +1,177 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/manhhung2111/go-redis/internal/command"
+	"github.com/manhhung2111/go-redis/internal/errors"
+	"github.com/manhhung2111/go-redis/internal/protocol"
+)
+
+func TestMultiExec(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+
+	assert.Equal(t, protocol.RespOK, r.HandleCommand(c, cmd("MULTI")))
+	assert.Equal(t, protocol.RespQueued, r.HandleCommand(c, cmd("SET", "a", "1")))
+	assert.Equal(t, protocol.RespQueued, r.HandleCommand(c, cmd("INCR", "a")))
+	assert.Equal(t, protocol.RespQueued, r.HandleCommand(c, cmd("LPUSH", "a", "x")))
+	assert.Equal(t, protocol.RespQueued, r.HandleCommand(c, cmd("GET", "a")))
+
+	// Nothing runs before EXEC
+	assert.Equal(t, protocol.RespNilBulkString, r.Get(cmd("GET", "a")))
+
+	resp := r.HandleCommand(c, cmd("EXEC"))
+	expected := "*4\r\n+OK\r\n:2\r\n" + string(protocol.RespWrongTypeOperation) + "$1\r\n2\r\n"
+	assert.Equal(t, expected, string(resp))
+
+	// The client is back to running commands right away
+	assert.Equal(t, []byte("$1\r\n2\r\n"), r.HandleCommand(c, cmd("GET", "a")))
+}
+
+func TestMultiExec_Empty(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+
+	r.HandleCommand(c, cmd("MULTI"))
+	assert.Equal(t, []byte("*0\r\n"), r.HandleCommand(c, cmd("EXEC")))
+}
+
+func TestMulti_Nested(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+
+	r.HandleCommand(c, cmd("MULTI"))
+	assert.Equal(t, protocol.RespMultiNested, r.HandleCommand(c, cmd("MULTI")))
+	assert.Equal(t, []byte("*0\r\n"), r.HandleCommand(c, cmd("EXEC")))
+}
+
+func TestExec_WithoutMulti(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+
+	assert.Equal(t, protocol.RespExecWithoutMulti, r.HandleCommand(c, cmd("EXEC")))
+	assert.Equal(t, protocol.RespDiscardWithoutMulti, r.HandleCommand(c, cmd("DISCARD")))
+}
+
+func TestExec_AbortsAfterQueueErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      protocol.RedisCmd
+		expected []byte
+	}{
+		{"unknown command", cmd("NOPE", "a"), protocol.EncodeResp(errors.InvalidCommand("NOPE"), false)},
+		{"too few arguments", cmd("GET"), protocol.EncodeResp(errors.InvalidNumberOfArgs("GET"), false)},
+		{"too many arguments", cmd("LLEN", "a", "b"), protocol.EncodeResp(errors.InvalidNumberOfArgs("LLEN"), false)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRedis()
+			c := command.NewClient()
+
+			r.HandleCommand(c, cmd("MULTI"))
+			r.HandleCommand(c, cmd("SET", "a", "1"))
+			assert.Equal(t, tt.expected, r.HandleCommand(c, tt.cmd))
+
+			assert.Equal(t, protocol.RespExecAbort, r.HandleCommand(c, cmd("EXEC")))
+			assert.Equal(t, protocol.RespNilBulkString, r.Get(cmd("GET", "a")))
+		})
+	}
+}
+
+func TestDiscard(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+
+	r.HandleCommand(c, cmd("MULTI"))
+	r.HandleCommand(c, cmd("SET", "a", "1"))
+	assert.Equal(t, protocol.RespOK, r.HandleCommand(c, cmd("DISCARD")))
+
+	assert.Equal(t, protocol.RespNilBulkString, r.Get(cmd("GET", "a")))
+	assert.Equal(t, protocol.RespExecWithoutMulti, r.HandleCommand(c, cmd("EXEC")))
+}
+
+func TestWatch_UnmodifiedKey(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+	r.Set(cmd("SET", "a", "1"))
+
+	assert.Equal(t, protocol.RespOK, r.HandleCommand(c, cmd("WATCH", "a", "b")))
+	r.Get(cmd("GET", "a"))
+	r.HandleCommand(c, cmd("MULTI"))
+	r.HandleCommand(c, cmd("INCR", "a"))
+
+	assert.Equal(t, []byte("*1\r\n:2\r\n"), r.HandleCommand(c, cmd("EXEC")))
+}
+
+func TestWatch_ModifiedKeyAbortsExec(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+	other := command.NewClient()
+	r.Set(cmd("SET", "a", "1"))
+
+	r.HandleCommand(c, cmd("WATCH", "a"))
+	r.HandleCommand(other, cmd("SET", "a", "2"))
+	r.HandleCommand(c, cmd("MULTI"))
+	r.HandleCommand(c, cmd("INCR", "a"))
+
+	assert.Equal(t, protocol.RespNilArray, r.HandleCommand(c, cmd("EXEC")))
+	assert.Equal(t, []byte("$1\r\n2\r\n"), r.Get(cmd("GET", "a")))
+
+	// EXEC forgets the watched keys
+	r.HandleCommand(other, cmd("SET", "a", "3"))
+	r.HandleCommand(c, cmd("MULTI"))
+	r.HandleCommand(c, cmd("INCR", "a"))
+	assert.Equal(t, []byte("*1\r\n:4\r\n"), r.HandleCommand(c, cmd("EXEC")))
+}
+
+func TestWatch_CreatedKeyAbortsExec(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+
+	r.HandleCommand(c, cmd("WATCH", "a"))
+	r.HandleCommand(command.NewClient(), cmd("SADD", "a", "x"))
+	r.HandleCommand(c, cmd("MULTI"))
+	r.HandleCommand(c, cmd("SET", "a", "1"))
+
+	assert.Equal(t, protocol.RespNilArray, r.HandleCommand(c, cmd("EXEC")))
+}
+
+func TestWatch_InsideMulti(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+
+	r.HandleCommand(c, cmd("MULTI"))
+	assert.Equal(t, protocol.RespWatchInsideMulti, r.HandleCommand(c, cmd("WATCH", "a")))
+	assert.Equal(t, []byte("*0\r\n"), r.HandleCommand(c, cmd("EXEC")))
+}
+
+func TestUnwatch(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+
+	r.HandleCommand(c, cmd("WATCH", "a"))
+	assert.Equal(t, protocol.RespOK, r.HandleCommand(c, cmd("UNWATCH")))
+	r.HandleCommand(command.NewClient(), cmd("SET", "a", "1"))
+	r.HandleCommand(c, cmd("MULTI"))
+	r.HandleCommand(c, cmd("GET", "a"))
+
+	assert.Equal(t, []byte("*1\r\n$1\r\n1\r\n"), r.HandleCommand(c, cmd("EXEC")))
+}
+
+func TestDiscard_Unwatches(t *testing.T) {
+	r := newTestRedis()
+	c := command.NewClient()
+
+	r.HandleCommand(c, cmd("WATCH", "a"))
+	r.HandleCommand(c, cmd("MULTI"))
+	r.HandleCommand(c, cmd("DISCARD"))
+	r.HandleCommand(command.NewClient(), cmd("SET", "a", "1"))
+	r.HandleCommand(c, cmd("MULTI"))
+	r.HandleCommand(c, cmd("GET", "a"))
+
+	assert.Equal(t, []byte("*1\r\n$1\r\n1\r\n"), r.HandleCommand(c, cmd("EXEC")))
+}
@@ -595,8 +595,7 @@ func TestZLexCountInvalidRange(t *testing.T) {
 	r := newTestRedis()
 
 	resp := r.ZLexCount(cmd("ZLEXCOUNT", "k", "[a", "z"))
-	require.NotEmpty(t, resp)
-	assert.Equal(t, byte('-'), resp[0])
+	assert.Equal(t, protocol.RespMinOrMaxNotValidStringRange, resp)
 }
 
 func TestZLexCountWrongArgs(t *testing.T) {
@@ -614,3 +613,160 @@ func TestZLexCountWrongType(t *testing.T) {
 	resp := r.ZLexCount(cmd("ZLEXCOUNT", "k", "-", "+"))
 	assert.Equal(t, protocol.RespWrongTypeOperation, resp)
 }
+
+func TestZUnionAndZInter(t *testing.T) {
+	r := newTestRedis()
+	r.ZAdd(cmd("ZADD", "z1", "1", "a", "2", "b"))
+	r.ZAdd(cmd("ZADD", "z2", "3", "b", "4", "c"))
+	r.SAdd(cmd("SADD", "set", "a"))
+
+	resp := r.ZUnion(cmd("ZUNION", "3", "z1", "z2", "set", "WITHSCORES"))
+	assert.Equal(t, []byte("*6\r\n$1\r\na\r\n$1\r\n2\r\n$1\r\nc\r\n$1\r\n4\r\n$1\r\nb\r\n$1\r\n5\r\n"), resp)
+
+	resp = r.ZInter(cmd("ZINTER", "2", "z1", "z2", "WEIGHTS", "2", "0.5", "AGGREGATE", "MIN", "WITHSCORES"))
+	assert.Equal(t, []byte("*2\r\n$1\r\nb\r\n$3\r\n1.5\r\n"), resp)
+
+	resp = r.ZDiff(cmd("ZDIFF", "2", "z1", "z2"))
+	assert.Equal(t, []byte("*1\r\n$1\r\na\r\n"), resp)
+
+	assert.Equal(t, []byte(":1\r\n"), r.ZInterCard(cmd("ZINTERCARD", "2", "z1", "z2")))
+	assert.Equal(t, []byte(":0\r\n"), r.ZInterCard(cmd("ZINTERCARD", "2", "z1", "missing", "LIMIT", "1")))
+}
+
+func TestZUnionInvalidArgs(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, []byte("-ERR at least 1 input key is needed for 'ZUNION' command\r\n"), r.ZUnion(cmd("ZUNION", "0", "z")))
+	assert.Equal(t, []byte("-ERR at least 1 input key is needed for 'ZINTERSTORE' command\r\n"), r.ZInterStore(cmd("ZINTERSTORE", "dst", "-1", "z")))
+	assert.Equal(t, []byte("-ERR at least 1 input key is needed for 'ZDIFF' command\r\n"), r.ZDiff(cmd("ZDIFF", "0", "z")))
+	assert.Equal(t, protocol.RespNumKeysExceedArgs, r.ZUnion(cmd("ZUNION", "3", "z1", "z2")))
+	assert.Equal(t, protocol.RespWeightNotFloat, r.ZUnion(cmd("ZUNION", "2", "z1", "z2", "WEIGHTS", "1", "x")))
+	assert.Equal(t, protocol.RespWeightNotFloat, r.ZUnion(cmd("ZUNION", "2", "z1", "z2", "WEIGHTS", "1", "nan")))
+	assert.Equal(t, protocol.RespSyntaxError, r.ZUnion(cmd("ZUNION", "2", "z1", "z2", "WEIGHTS", "1")))
+	assert.Equal(t, protocol.RespSyntaxError, r.ZUnion(cmd("ZUNION", "1", "z1", "AGGREGATE", "AVG")))
+	assert.Equal(t, protocol.RespSyntaxError, r.ZDiff(cmd("ZDIFF", "1", "z1", "WEIGHTS", "1")))
+	assert.Equal(t, protocol.RespSyntaxError, r.ZUnionStore(cmd("ZUNIONSTORE", "dst", "1", "z1", "WITHSCORES")))
+	assert.Equal(t, protocol.RespLimitNegative, r.ZInterCard(cmd("ZINTERCARD", "1", "z1", "LIMIT", "-1")))
+
+	r.Set(cmd("SET", "str", "v"))
+	assert.Equal(t, protocol.RespWrongTypeOperation, r.ZInter(cmd("ZINTER", "2", "z1", "str")))
+}
+
+func TestZUnionStoreAndZInterStore(t *testing.T) {
+	r := newTestRedis()
+	r.ZAdd(cmd("ZADD", "z1", "1", "a", "2", "b"))
+	r.ZAdd(cmd("ZADD", "z2", "3", "b", "4", "c"))
+
+	assert.Equal(t, []byte(":3\r\n"), r.ZUnionStore(cmd("ZUNIONSTORE", "dst", "2", "z1", "z2", "AGGREGATE", "MAX")))
+	assert.Equal(t, []byte("*6\r\n$1\r\na\r\n$1\r\n1\r\n$1\r\nb\r\n$1\r\n3\r\n$1\r\nc\r\n$1\r\n4\r\n"),
+		r.ZRange(cmd("ZRANGE", "dst", "0", "-1", "WITHSCORES")))
+
+	assert.Equal(t, []byte(":1\r\n"), r.ZInterStore(cmd("ZINTERSTORE", "z1", "2", "z1", "z2")))
+	assert.Equal(t, []byte("*2\r\n$1\r\nb\r\n$1\r\n5\r\n"), r.ZRange(cmd("ZRANGE", "z1", "0", "-1", "WITHSCORES")))
+
+	assert.Equal(t, []byte(":0\r\n"), r.ZDiffStore(cmd("ZDIFFSTORE", "dst", "2", "z1", "z2")))
+	assert.Equal(t, []byte(":0\r\n"), r.Exists(cmd("EXISTS", "dst")))
+}
+
+func TestZRangeLimitAndExclusiveBounds(t *testing.T) {
+	r := newTestRedis()
+	r.ZAdd(cmd("ZADD", "k", "1", "a", "2", "b", "3", "c", "4", "d"))
+
+	assert.Equal(t, []byte("*2\r\n$1\r\nc\r\n$1\r\nd\r\n"), r.ZRange(cmd("ZRANGE", "k", "(1", "+inf", "BYSCORE", "LIMIT", "1", "5")))
+	assert.Equal(t, []byte("*1\r\n$1\r\nc\r\n"), r.ZRange(cmd("ZRANGE", "k", "(4", "-inf", "BYSCORE", "REV", "LIMIT", "0", "1")))
+	assert.Equal(t, []byte("*2\r\n$1\r\na\r\n$1\r\nb\r\n"), r.ZRange(cmd("ZRANGE", "k", "-", "+", "BYLEX", "LIMIT", "0", "2")))
+	assert.Equal(t, []byte(":2\r\n"), r.ZCount(cmd("ZCOUNT", "k", "(1", "(4")))
+
+	assert.Equal(t, protocol.RespLimitNotSupportedByRank, r.ZRange(cmd("ZRANGE", "k", "0", "-1", "LIMIT", "0", "1")))
+	assert.Equal(t, protocol.RespMinOrMaxNotFloat, r.ZRange(cmd("ZRANGE", "k", "(x", "2", "BYSCORE")))
+}
+
+func TestZRangeByScoreAndZRevRange(t *testing.T) {
+	r := newTestRedis()
+	r.ZAdd(cmd("ZADD", "k", "1", "a", "2", "b", "3", "c"))
+
+	assert.Equal(t, []byte("*2\r\n$1\r\nb\r\n$1\r\n2\r\n"), r.ZRangeByScore(cmd("ZRANGEBYSCORE", "k", "-inf", "+inf", "WITHSCORES", "LIMIT", "1", "1")))
+	assert.Equal(t, []byte("*2\r\n$1\r\nc\r\n$1\r\nb\r\n"), r.ZRevRangeByScore(cmd("ZREVRANGEBYSCORE", "k", "+inf", "(1")))
+	assert.Equal(t, []byte("*2\r\n$1\r\nc\r\n$1\r\nb\r\n"), r.ZRevRange(cmd("ZREVRANGE", "k", "0", "1")))
+
+	assert.Equal(t, protocol.RespSyntaxError, r.ZRangeByScore(cmd("ZRANGEBYSCORE", "k", "0", "1", "REV")))
+	assert.Equal(t, protocol.RespSyntaxError, r.ZRevRange(cmd("ZREVRANGE", "k", "0", "-1", "LIMIT", "0", "1")))
+	assert.Equal(t, protocol.RespSyntaxError, r.ZRevRange(cmd("ZREVRANGE", "k", "0", "-1", "WITHSCORES", "LIMIT", "0", "1")))
+}
+
+func TestZRangeStore(t *testing.T) {
+	r := newTestRedis()
+	r.ZAdd(cmd("ZADD", "src", "1", "a", "2", "b", "3", "c"))
+
+	assert.Equal(t, []byte(":2\r\n"), r.ZRangeStore(cmd("ZRANGESTORE", "dst", "src", "2", "+inf", "BYSCORE")))
+	assert.Equal(t, []byte("*4\r\n$1\r\nb\r\n$1\r\n2\r\n$1\r\nc\r\n$1\r\n3\r\n"), r.ZRange(cmd("ZRANGE", "dst", "0", "-1", "WITHSCORES")))
+
+	assert.Equal(t, protocol.RespSyntaxError, r.ZRangeStore(cmd("ZRANGESTORE", "dst", "src", "0", "-1", "WITHSCORES")))
+
+	assert.Equal(t, []byte(":0\r\n"), r.ZRangeStore(cmd("ZRANGESTORE", "dst", "missing", "0", "-1")))
+	assert.Equal(t, []byte(":0\r\n"), r.Exists(cmd("EXISTS", "dst")))
+}
+
+func TestZRemRange(t *testing.T) {
+	r := newTestRedis()
+	r.ZAdd(cmd("ZADD", "k", "1", "a", "2", "b", "3", "c", "4", "d", "5", "e"))
+
+	assert.Equal(t, []byte(":1\r\n"), r.ZRemRangeByRank(cmd("ZREMRANGEBYRANK", "k", "-1", "-1")))
+	assert.Equal(t, []byte(":2\r\n"), r.ZRemRangeByScore(cmd("ZREMRANGEBYSCORE", "k", "(1", "3")))
+	assert.Equal(t, []byte(":0\r\n"), r.ZRemRangeByScore(cmd("ZREMRANGEBYSCORE", "missing", "0", "1")))
+	assert.Equal(t, []byte(":2\r\n"), r.ZRemRangeByLex(cmd("ZREMRANGEBYLEX", "k", "-", "+")))
+	assert.Equal(t, []byte(":0\r\n"), r.Exists(cmd("EXISTS", "k")))
+
+	assert.Equal(t, protocol.RespValueNotIntegerOrOutOfRange, r.ZRemRangeByRank(cmd("ZREMRANGEBYRANK", "k", "a", "1")))
+	assert.Equal(t, protocol.RespMinOrMaxNotFloat, r.ZRemRangeByScore(cmd("ZREMRANGEBYSCORE", "k", "a", "1")))
+}
+
+func TestZMPop(t *testing.T) {
+	r := newTestRedis()
+
+	resp := r.ZMPop(cmd("ZMPOP", "2", "z1", "z2", "MIN"))
+	assert.Equal(t, protocol.RespNilArray, resp)
+
+	r.ZAdd(cmd("ZADD", "z2", "1", "a", "2", "b", "3", "c"))
+	r.ZAdd(cmd("ZADD", "z3", "5", "x"))
+	resp = r.ZMPop(cmd("ZMPOP", "3", "z1", "z2", "z3", "MIN"))
+	assert.Equal(t, protocol.EncodeResp([]any{"z2", []any{[]string{"a", "1"}}}, false), resp)
+
+	resp = r.ZMPop(cmd("ZMPOP", "3", "z1", "z2", "z3", "max", "count", "5"))
+	assert.Equal(t, protocol.EncodeResp([]any{"z2", []any{[]string{"c", "3"}, []string{"b", "2"}}}, false), resp)
+	assert.Equal(t, []byte(":0\r\n"), r.Exists(cmd("EXISTS", "z2")))
+
+	resp = r.ZMPop(cmd("ZMPOP", "3", "z1", "z2", "z3", "MAX"))
+	assert.Equal(t, protocol.EncodeResp([]any{"z3", []any{[]string{"x", "5"}}}, false), resp)
+}
+
+func TestZMPop_Errors(t *testing.T) {
+	r := newTestRedis()
+
+	assert.Equal(t, protocol.RespNumKeysNotPositive, r.ZMPop(cmd("ZMPOP", "0", "z", "MIN")))
+	assert.Equal(t, protocol.RespNumKeysExceedArgs, r.ZMPop(cmd("ZMPOP", "3", "z", "MIN")))
+	assert.Equal(t, protocol.RespSyntaxError, r.ZMPop(cmd("ZMPOP", "1", "z", "LEFT")))
+	assert.Equal(t, protocol.RespCountNotPositive, r.ZMPop(cmd("ZMPOP", "1", "z", "MIN", "COUNT", "0")))
+	assert.Equal(t, protocol.RespSyntaxError, r.ZMPop(cmd("ZMPOP", "1", "z", "MIN", "COUNT")))
+
+	r.Set(cmd("SET", "str", "value"))
+	assert.Equal(t, protocol.RespWrongTypeOperation, r.ZMPop(cmd("ZMPOP", "1", "str", "MIN")))
+}
+
+func TestZRangeByLexBounds(t *testing.T) {
+	r := newTestRedis()
+	r.ZAdd(cmd("ZADD", "k", "0", "a", "0", "b", "0", "c", "0", "d"))
+
+	assert.Equal(t, []byte("*2\r\n$1\r\nb\r\n$1\r\nc\r\n"), r.ZRange(cmd("ZRANGE", "k", "(a", "[c", "BYLEX")))
+	assert.Equal(t, []byte("*1\r\n$1\r\na\r\n"), r.ZRange(cmd("ZRANGE", "k", "[a", "+", "BYLEX", "LIMIT", "0", "1")))
+	assert.Equal(t, []byte("*2\r\n$1\r\nc\r\n$1\r\nb\r\n"), r.ZRange(cmd("ZRANGE", "k", "(d", "[b", "BYLEX", "REV")))
+	assert.Equal(t, []byte("*0\r\n"), r.ZRange(cmd("ZRANGE", "k", "+", "-", "BYLEX")))
+	assert.Equal(t, []byte(":2\r\n"), r.ZLexCount(cmd("ZLEXCOUNT", "k", "[b", "(d")))
+
+	assert.Equal(t, []byte(":2\r\n"), r.ZRemRangeByLex(cmd("ZREMRANGEBYLEX", "k", "[a", "(c")))
+	assert.Equal(t, []byte("*2\r\n$1\r\nc\r\n$1\r\nd\r\n"), r.ZRange(cmd("ZRANGE", "k", "0", "-1")))
+
+	assert.Equal(t, protocol.RespMinOrMaxNotValidStringRange, r.ZRemRangeByLex(cmd("ZREMRANGEBYLEX", "k", "a", "b")))
+	assert.Equal(t, protocol.RespMinOrMaxNotValidStringRange, r.ZRange(cmd("ZRANGE", "k", "[a", "c", "BYLEX")))
+	assert.Equal(t, protocol.RespMinOrMaxNotValidStringRange, r.ZLexCount(cmd("ZLEXCOUNT", "k", "", "+")))
+}